@@ -0,0 +1,33 @@
+package chunker
+
+import "math/bits"
+
+// buzTable maps each byte to a random 64-bit value. It is generated from a
+// fixed seed so chunk boundaries are stable across processes and releases.
+var buzTable = func() [256]uint64 {
+	var t [256]uint64
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range t {
+		// splitmix64
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		t[i] = z ^ (z >> 31)
+	}
+	return t
+}()
+
+// buzhash returns the hash of window.
+func buzhash(window []byte) uint64 {
+	var h uint64
+	for _, b := range window {
+		h = bits.RotateLeft64(h, 1) ^ buzTable[b]
+	}
+	return h
+}
+
+// roll slides the window of h by one byte: out leaves, in enters.
+func roll(h uint64, out, in byte) uint64 {
+	return bits.RotateLeft64(h, 1) ^ bits.RotateLeft64(buzTable[out], windowSize) ^ buzTable[in]
+}
@@ -0,0 +1,160 @@
+// Package chunker implements content-defined chunking with a Buzhash
+// rolling hash. Boundaries depend only on the bytes around them, so an
+// insertion early in a stream shifts only the chunks it touches and the rest
+// keep their content hash, which is what dedup and backup pipelines key on.
+package chunker
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/bits"
+)
+
+// maxEmptyReads bounds consecutive (0, nil) reads before giving up,
+// like bufio.
+const maxEmptyReads = 100
+
+// Chunk is one content-defined piece of the stream.
+type Chunk struct {
+	Offset int64             // position of the first byte in the stream
+	Length int               // size in bytes
+	Sum    [sha256.Size]byte // SHA-256 of the content
+	Data   []byte            // content; valid until the next call to Next
+}
+
+// Chunker splits a stream into content-defined chunks.
+// It is not safe for concurrent use.
+type Chunker struct {
+	r      io.Reader
+	config Config
+	mask   uint64
+
+	buf    []byte
+	lo, hi int   // unconsumed data is buf[lo:hi]
+	offset int64 // stream offset of buf[lo]
+	eof    bool
+	err    error
+}
+
+// New creates a chunker reading from r. Any io.Reader works, including a
+// *buffer.LinkedListBuffer or *buffer.RingBuffer.
+func New(r io.Reader, opts ...Option) *Chunker {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return &Chunker{
+		r:      r,
+		config: cfg,
+		mask:   boundaryMask(cfg.AvgSize),
+		buf:    make([]byte, 2*cfg.MaxSize),
+	}
+}
+
+// boundaryMask returns the mask whose all-zero match cuts a chunk on average
+// every avg bytes (avg rounded down to a power of two).
+func boundaryMask(avg int) uint64 {
+	return 1<<(bits.Len(uint(avg))-1) - 1
+}
+
+// Next returns the next chunk, or io.EOF once the stream is exhausted.
+func (c *Chunker) Next() (Chunk, error) {
+	if err := c.fill(); err != nil {
+		return Chunk{}, err
+	}
+	if c.lo == c.hi {
+		return Chunk{}, io.EOF
+	}
+
+	n := c.boundary(c.buf[c.lo:c.hi])
+	data := c.buf[c.lo : c.lo+n]
+	chunk := Chunk{
+		Offset: c.offset,
+		Length: n,
+		Sum:    sha256.Sum256(data),
+		Data:   data,
+	}
+	c.lo += n
+	c.offset += int64(n)
+	return chunk, nil
+}
+
+// fill tops up the buffer until MaxSize bytes are available or the reader
+// is exhausted.
+func (c *Chunker) fill() error {
+	if c.err != nil {
+		return c.err
+	}
+	if c.hi-c.lo >= c.config.MaxSize || c.eof {
+		return nil
+	}
+
+	// Compact so there is room for a full chunk after the pending bytes.
+	if c.lo > 0 {
+		c.hi = copy(c.buf, c.buf[c.lo:c.hi])
+		c.lo = 0
+	}
+
+	for empty := 0; c.hi < c.config.MaxSize; {
+		n, err := c.r.Read(c.buf[c.hi:])
+		c.hi += n
+		if errors.Is(err, io.EOF) {
+			c.eof = true
+			return nil
+		}
+		if err != nil {
+			c.err = err
+			return err
+		}
+		if n > 0 {
+			empty = 0
+		} else if empty++; empty >= maxEmptyReads {
+			c.err = io.ErrNoProgress
+			return c.err
+		}
+	}
+	return nil
+}
+
+// boundary returns the length of the first chunk of data.
+func (c *Chunker) boundary(data []byte) int {
+	limit := min(len(data), c.config.MaxSize)
+	if limit <= c.config.MinSize {
+		return limit
+	}
+
+	h := buzhash(data[c.config.MinSize-windowSize : c.config.MinSize])
+	for i := c.config.MinSize; i < limit; i++ {
+		if h&c.mask == 0 {
+			return i
+		}
+		h = roll(h, data[i-windowSize], data[i])
+	}
+	return limit
+}
+
+// Split chunks an in-memory payload. Chunk.Data aliases p.
+func Split(p []byte, opts ...Option) []Chunk {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	c := &Chunker{config: cfg, mask: boundaryMask(cfg.AvgSize)}
+
+	var chunks []Chunk
+	var off int64
+	for len(p) > 0 {
+		n := c.boundary(p)
+		chunks = append(chunks, Chunk{
+			Offset: off,
+			Length: n,
+			Sum:    sha256.Sum256(p[:n]),
+			Data:   p[:n],
+		})
+		p = p[n:]
+		off += int64(n)
+	}
+	return chunks
+}
@@ -0,0 +1,136 @@
+package chunker
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+	"github.com/huynhanx03/go-common/pkg/testutil/iotest"
+)
+
+func randomData(n int, seed uint64) []byte {
+	r := rand.New(rand.NewPCG(seed, seed))
+	p := make([]byte, n)
+	for i := range p {
+		p[i] = byte(r.Uint32())
+	}
+	return p
+}
+
+func testOpts() []Option {
+	return []Option{WithSizes(256, 1024, 4096)}
+}
+
+func collect(t *testing.T, c *Chunker) []Chunk {
+	t.Helper()
+	var chunks []Chunk
+	for {
+		ch, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		ch.Data = bytes.Clone(ch.Data)
+		chunks = append(chunks, ch)
+	}
+}
+
+// =============================================================================
+// Chunking Tests
+// =============================================================================
+
+func TestChunkSizesAndReassembly(t *testing.T) {
+	data := randomData(200_000, 1)
+	chunks := collect(t, New(bytes.NewReader(data), testOpts()...))
+
+	var joined []byte
+	var off int64
+	for i, ch := range chunks {
+		if ch.Offset != off {
+			t.Fatalf("chunk %d offset = %d, want %d", i, ch.Offset, off)
+		}
+		if ch.Length > 4096 || (ch.Length < 256 && i != len(chunks)-1) {
+			t.Errorf("chunk %d length %d out of [256, 4096]", i, ch.Length)
+		}
+		joined = append(joined, ch.Data...)
+		off += int64(ch.Length)
+	}
+	if !bytes.Equal(joined, data) {
+		t.Fatal("reassembled chunks differ from input")
+	}
+
+	avg := len(data) / len(chunks)
+	if avg < 512 || avg > 3000 {
+		t.Errorf("average chunk size = %d, want around 1024", avg)
+	}
+}
+
+func TestSplitMatchesStreaming(t *testing.T) {
+	data := randomData(50_000, 2)
+	split := Split(data, testOpts()...)
+	// A reader returning tiny reads must not change the boundaries.
+	stream := collect(t, New(iotest.NewReader(bytes.NewReader(data), iotest.WithMaxChunk(7)), testOpts()...))
+
+	if len(split) != len(stream) {
+		t.Fatalf("Split = %d chunks, streaming = %d", len(split), len(stream))
+	}
+	for i := range split {
+		if split[i].Sum != stream[i].Sum || split[i].Offset != stream[i].Offset {
+			t.Fatalf("chunk %d differs between Split and streaming", i)
+		}
+	}
+}
+
+func TestBoundariesSurviveInsertion(t *testing.T) {
+	data := randomData(100_000, 3)
+	edited := append([]byte("a few inserted bytes"), data...)
+
+	before := map[[32]byte]bool{}
+	for _, ch := range Split(data, testOpts()...) {
+		before[ch.Sum] = true
+	}
+	after := Split(edited, testOpts()...)
+
+	shared := 0
+	for _, ch := range after {
+		if before[ch.Sum] {
+			shared++
+		}
+	}
+	if shared < len(after)-3 {
+		t.Errorf("only %d of %d chunks survived a prefix insertion", shared, len(after))
+	}
+}
+
+func TestFromLinkedListBuffer(t *testing.T) {
+	data := randomData(20_000, 4)
+	var ll buffer.LinkedListBuffer
+	ll.PushBack(data[:7000])
+	ll.PushBack(data[7000:])
+
+	chunks := collect(t, New(&ll, testOpts()...))
+	want := Split(data, testOpts()...)
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d", len(chunks), len(want))
+	}
+	if ll.Buffered() != 0 {
+		t.Errorf("buffer not drained: %d bytes left", ll.Buffered())
+	}
+}
+
+func TestEmptyAndErrors(t *testing.T) {
+	if _, err := New(bytes.NewReader(nil)).Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("empty input err = %v, want io.EOF", err)
+	}
+
+	boom := errors.New("boom")
+	c := New(io.MultiReader(bytes.NewReader([]byte("abc")), iotest.ErrReader(boom)))
+	if _, err := c.Next(); !errors.Is(err, boom) {
+		t.Errorf("Next err = %v, want boom", err)
+	}
+}
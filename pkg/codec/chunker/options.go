@@ -0,0 +1,40 @@
+package chunker
+
+// Defaults.
+const (
+	DefaultMinSize = 16 << 10  // 16 KB
+	DefaultAvgSize = 64 << 10  // 64 KB
+	DefaultMaxSize = 256 << 10 // 256 KB
+
+	// windowSize is the number of trailing bytes the rolling hash covers.
+	windowSize = 64
+)
+
+// Config holds all configuration for the chunker.
+type Config struct {
+	MinSize int // no boundary is cut before this many bytes
+	AvgSize int // expected chunk size, rounded down to a power of two
+	MaxSize int // a boundary is forced at this many bytes
+}
+
+// Option configures the chunker.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{
+		MinSize: DefaultMinSize,
+		AvgSize: DefaultAvgSize,
+		MaxSize: DefaultMaxSize,
+	}
+}
+
+// WithSizes sets the min, average and max chunk sizes.
+// Invalid combinations (min < 64 bytes, min > avg or avg > max) are ignored.
+func WithSizes(min, avg, max int) Option {
+	return func(c *Config) {
+		if min < windowSize || min > avg || avg > max {
+			return
+		}
+		c.MinSize, c.AvgSize, c.MaxSize = min, avg, max
+	}
+}
@@ -0,0 +1,135 @@
+// Package http1 is a minimal HTTP/1.1 codec for event-loop servers: an
+// incremental request parser reading straight out of a segmented buffer such
+// as a *buffer.ElasticBuffer, and a response serializer appending to a
+// *buffer.LinkedListBuffer. Parsed fields alias the buffer's memory.
+//
+// It implements the message framing of RFC 9112 (request line, header
+// fields, Content-Length and chunked bodies, keep-alive) and nothing above
+// it: no routing, no content negotiation, no HTTP/2 upgrade.
+package http1
+
+import (
+	"errors"
+
+	"github.com/huynhanx03/go-common/pkg/utils"
+	"github.com/huynhanx03/go-common/pkg/utils/bytesx"
+)
+
+var (
+	// ErrIncomplete is returned by Parser.Next when the buffer does not yet
+	// hold a whole request. Nothing is consumed; read more and call again.
+	ErrIncomplete = errors.New("http1: incomplete request")
+
+	// ErrProtocol is returned for a malformed request (400 Bad Request).
+	// The connection should be closed after responding.
+	ErrProtocol = errors.New("http1: malformed request")
+
+	// ErrTooLarge is returned when the head or body exceeds a configured
+	// limit (431 or 413).
+	ErrTooLarge = errors.New("http1: request too large")
+
+	// ErrUnsupported is returned for a transfer coding other than chunked or
+	// a protocol version other than 1.x (501 Not Implemented).
+	ErrUnsupported = errors.New("http1: unsupported transfer coding or version")
+)
+
+// Header is a header or trailer field. Names keep the case they were
+// received or given in.
+type Header struct {
+	Name  []byte
+	Value []byte
+}
+
+// Request is a parsed request. Its byte slices point into the parser's
+// source buffer and stay valid until the next call to Next or Release.
+type Request struct {
+	Method []byte
+	Target []byte // request-target as sent, e.g. "/path?q=1"
+
+	ProtoMajor, ProtoMinor int
+
+	Headers  []Header
+	Trailers []Header // chunked bodies only
+
+	// ContentLength is the body size; -1 when the body is chunked.
+	ContentLength int64
+	Chunked       bool
+
+	// KeepAlive reports whether the connection may carry another request:
+	// the HTTP/1.1 default unless "Connection: close", or HTTP/1.0 with
+	// "Connection: keep-alive".
+	KeepAlive bool
+
+	// Body is the whole body, chunks joined.
+	Body []byte
+}
+
+// Header returns the value of the first header named name, compared
+// case-insensitively, or nil.
+func (r *Request) Header(name string) []byte {
+	return lookup(r.Headers, name)
+}
+
+func lookup(headers []Header, name string) []byte {
+	for _, h := range headers {
+		if bytesx.EqualFoldASCII(h.Name, utils.StringToBytes(name)) {
+			return h.Value
+		}
+	}
+	return nil
+}
+
+// hasToken reports whether the comma-separated list v contains token,
+// compared case-insensitively.
+func hasToken(v []byte, token string) bool {
+	for len(v) > 0 {
+		var item []byte
+		if i := bytesx.IndexByte(v, ','); i >= 0 {
+			item, v = v[:i], v[i+1:]
+		} else {
+			item, v = v, nil
+		}
+		if bytesx.EqualFoldASCII(trimOWS(item), utils.StringToBytes(token)) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimOWS trims optional whitespace (SP and HTAB).
+func trimOWS(b []byte) []byte {
+	for len(b) > 0 && (b[0] == ' ' || b[0] == '\t') {
+		b = b[1:]
+	}
+	for len(b) > 0 && (b[len(b)-1] == ' ' || b[len(b)-1] == '\t') {
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+// isToken reports whether b is a non-empty RFC 9110 token.
+func isToken(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if c >= 0x80 || !tokenChars[c] {
+			return false
+		}
+	}
+	return true
+}
+
+var tokenChars = func() (t [128]bool) {
+	for c := '0'; c <= '9'; c++ {
+		t[c] = true
+	}
+	for c := 'a'; c <= 'z'; c++ {
+		t[c] = true
+		t[c-'a'+'A'] = true
+	}
+	for _, c := range "!#$%&'*+-.^_`|~" {
+		t[c] = true
+	}
+	return t
+}()
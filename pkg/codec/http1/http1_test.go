@@ -0,0 +1,229 @@
+package http1
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+// listOf returns a LinkedListBuffer holding parts as separate segments.
+func listOf(parts ...string) *buffer.LinkedListBuffer {
+	var ll buffer.LinkedListBuffer
+	for _, p := range parts {
+		ll.PushBack([]byte(p))
+	}
+	return &ll
+}
+
+func parseOne(t *testing.T, parts ...string) *Request {
+	t.Helper()
+	req, err := NewParser(listOf(parts...)).Next()
+	if err != nil {
+		t.Fatalf("Next(%q) error: %v", parts, err)
+	}
+	return req
+}
+
+const chunkedReq = "POST /upload?x=1 HTTP/1.1\r\n" +
+	"Host: example.com\r\n" +
+	"Transfer-Encoding: chunked\r\n" +
+	"\r\n" +
+	"5;ext=1\r\nhello\r\n" +
+	"7\r\n, world\r\n" +
+	"0\r\n" +
+	"X-Checksum: abc\r\n" +
+	"\r\n"
+
+// =============================================================================
+// Parser
+// =============================================================================
+
+func TestParser_ContentLength(t *testing.T) {
+	req := parseOne(t, "\r\nPUT /k HTTP/1.1\r\nHost: h\r\nContent-Length: 3\r\nX-A:  v1 \r\n\r\nabc")
+
+	if string(req.Method) != "PUT" || string(req.Target) != "/k" || req.ProtoMinor != 1 {
+		t.Errorf("request line = %s %s 1.%d", req.Method, req.Target, req.ProtoMinor)
+	}
+	if string(req.Header("x-a")) != "v1" {
+		t.Errorf("X-A = %q, want v1", req.Header("x-a"))
+	}
+	if req.ContentLength != 3 || string(req.Body) != "abc" || !req.KeepAlive {
+		t.Errorf("ContentLength=%d Body=%q KeepAlive=%v", req.ContentLength, req.Body, req.KeepAlive)
+	}
+}
+
+func TestParser_Chunked(t *testing.T) {
+	req := parseOne(t, chunkedReq)
+	if !req.Chunked || req.ContentLength != -1 {
+		t.Errorf("Chunked=%v ContentLength=%d", req.Chunked, req.ContentLength)
+	}
+	if string(req.Body) != "hello, world" {
+		t.Errorf("Body = %q", req.Body)
+	}
+	if len(req.Trailers) != 1 || string(req.Trailers[0].Value) != "abc" {
+		t.Errorf("Trailers = %q", req.Trailers)
+	}
+}
+
+func TestParser_EverySplit(t *testing.T) {
+	for i := 1; i < len(chunkedReq); i++ {
+		req := parseOne(t, chunkedReq[:i], chunkedReq[i:])
+		if string(req.Body) != "hello, world" || string(req.Target) != "/upload?x=1" {
+			t.Fatalf("split %d: target %q body %q", i, req.Target, req.Body)
+		}
+	}
+}
+
+func TestParser_IncrementalPipelined(t *testing.T) {
+	in := "GET /a HTTP/1.1\r\nHost: h\r\n\r\n" + chunkedReq + "GET /c HTTP/1.0\r\n\r\n"
+	eb, _ := buffer.NewElastic(16)
+	defer eb.Release()
+	p := NewParser(eb)
+
+	var got []string
+	for i := 0; i < len(in); i++ {
+		eb.Write([]byte{in[i]})
+		req, err := p.Next()
+		if errors.Is(err, ErrIncomplete) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("byte %d: %v", i, err)
+		}
+		got = append(got, string(req.Target)+" "+string(req.Body))
+		if string(req.Target) == "/c" && req.KeepAlive {
+			t.Error("HTTP/1.0 request without keep-alive reported KeepAlive")
+		}
+	}
+	want := "/a |/upload?x=1 hello, world|/c "
+	if strings.Join(got, "|") != want {
+		t.Errorf("requests = %q, want %q", strings.Join(got, "|"), want)
+	}
+}
+
+func TestParser_KeepAlive(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"GET / HTTP/1.1\r\nHost: h\r\nConnection: Close\r\n\r\n", false},
+		{"GET / HTTP/1.1\r\nHost: h\r\nConnection: upgrade, close\r\n\r\n", false},
+		{"GET / HTTP/1.0\r\nConnection: keep-alive\r\n\r\n", true},
+		{"GET / HTTP/1.0\r\n\r\n", false},
+	}
+	for _, tc := range tests {
+		if got := parseOne(t, tc.in).KeepAlive; got != tc.want {
+			t.Errorf("%q: KeepAlive = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParser_Errors(t *testing.T) {
+	tests := []struct {
+		in   string
+		opts []Option
+		want error
+	}{
+		{"GET / HTTP/1.1\r\nHost: h\r\n", nil, ErrIncomplete},
+		{"POST / HTTP/1.1\r\nHost: h\r\nContent-Length: 5\r\n\r\nab", nil, ErrIncomplete},
+		{"GET /\r\n\r\n", nil, ErrProtocol},
+		{"GET / HTTP/1.1\n\n", nil, ErrProtocol},
+		{"G@T / HTTP/1.1\r\nHost: h\r\n\r\n", nil, ErrProtocol},
+		{"GET / HTTP/1.1\r\n\r\n", nil, ErrProtocol},                       // no Host
+		{"GET / HTTP/1.1\r\nHost : h\r\n\r\n", nil, ErrProtocol},           // space before colon
+		{"GET / HTTP/1.1\r\nHost: h\r\n folded\r\n\r\n", nil, ErrProtocol}, // obs-fold
+		{"POST / HTTP/1.1\r\nHost: h\r\nContent-Length: 1\r\nContent-Length: 2\r\n\r\n", nil, ErrProtocol},
+		{"POST / HTTP/1.1\r\nHost: h\r\nContent-Length: 1\r\nTransfer-Encoding: chunked\r\n\r\n", nil, ErrProtocol},
+		{"POST / HTTP/1.1\r\nHost: h\r\nContent-Length: -1\r\n\r\n", nil, ErrProtocol},
+		{"POST / HTTP/1.1\r\nHost: h\r\nTransfer-Encoding: chunked\r\n\r\nzz\r\n", nil, ErrProtocol},
+		{"POST / HTTP/1.1\r\nHost: h\r\nTransfer-Encoding: gzip\r\n\r\n", nil, ErrUnsupported},
+		{"GET / HTTP/2.0\r\n\r\n", nil, ErrUnsupported},
+		{"GET / HTTP/1.1\r\nHost: h\r\nA: " + strings.Repeat("x", 100) + "\r\n\r\n", []Option{WithMaxHeaderBytes(64)}, ErrTooLarge},
+		{"GET / HTTP/1.1\r\nHost: h\r\nA: 1\r\nB: 2\r\n\r\n", []Option{WithMaxHeaders(2)}, ErrTooLarge},
+		{"POST / HTTP/1.1\r\nHost: h\r\nContent-Length: 100\r\n\r\n", []Option{WithMaxBodyBytes(10)}, ErrTooLarge},
+		{"POST / HTTP/1.1\r\nHost: h\r\nTransfer-Encoding: chunked\r\n\r\n64\r\n", []Option{WithMaxBodyBytes(10)}, ErrTooLarge},
+	}
+	for _, tc := range tests {
+		_, err := NewParser(listOf(tc.in), tc.opts...).Next()
+		if !errors.Is(err, tc.want) {
+			t.Errorf("parse %q = %v, want %v", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestParser_ZeroCopy(t *testing.T) {
+	ll := listOf("POST / HTTP/1.1\r\nHost: h\r\nContent-Length: 2\r\n\r\nhi")
+	segs, _ := ll.Peek(0)
+	req, err := NewParser(ll).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &req.Body[0] != &segs[0][len(segs[0])-2] {
+		t.Error("body within one segment was copied")
+	}
+}
+
+// =============================================================================
+// ResponseWriter
+// =============================================================================
+
+func readAll(ll *buffer.LinkedListBuffer) string {
+	b, _ := io.ReadAll(ll)
+	return string(b)
+}
+
+func TestResponseWriter_ContentLength(t *testing.T) {
+	var out buffer.LinkedListBuffer
+	w := NewResponseWriter(&out)
+	w.WriteResponse(200, []Header{{Name: []byte("X-Id"), Value: []byte("a\r\nInjected: 1")}}, []byte("ok"))
+	w.WriteHeader(204, nil, 0)
+
+	want := "HTTP/1.1 200 OK\r\nX-Id: a  Injected: 1\r\nContent-Length: 2\r\n\r\nok" +
+		"HTTP/1.1 204 No Content\r\n\r\n"
+	if got := readAll(&out); got != want {
+		t.Errorf("wrote\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestResponseWriter_Chunked(t *testing.T) {
+	var out buffer.LinkedListBuffer
+	w := NewResponseWriter(&out)
+	w.WriteHeader(200, nil, -1)
+	w.WriteBody([]byte("hello"))
+	w.WriteBody(nil)
+	w.WriteBody([]byte(", world!!!!!!"))
+	w.Finish(Header{Name: []byte("X-Sum"), Value: []byte("1")})
+
+	want := "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n" +
+		"5\r\nhello\r\nd\r\n, world!!!!!!\r\n0\r\nX-Sum: 1\r\n\r\n"
+	if got := readAll(&out); got != want {
+		t.Errorf("wrote\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestResponseWriter_ReadableByNetHTTP(t *testing.T) {
+	var out buffer.LinkedListBuffer
+	w := NewResponseWriter(&out)
+	w.WriteHeader(201, []Header{{Name: []byte("Content-Type"), Value: []byte("text/plain")}}, -1)
+	w.WriteBody([]byte("streamed "))
+	w.WriteBody([]byte("body"))
+	w.Finish()
+
+	resp, err := http.ReadResponse(bufio.NewReader(&out), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 201 || string(body) != "streamed body" || resp.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("status %d, body %q, headers %v", resp.StatusCode, body, resp.Header)
+	}
+}
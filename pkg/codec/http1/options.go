@@ -0,0 +1,53 @@
+package http1
+
+// Defaults.
+const (
+	DefaultMaxHeaderBytes = 1 << 20  // 1 MB, as net/http
+	DefaultMaxHeaders     = 100      // header fields, trailers included
+	DefaultMaxBodyBytes   = 10 << 20 // 10 MB
+)
+
+// Config holds the Parser limits. Exceeding one fails with ErrTooLarge.
+type Config struct {
+	MaxHeaderBytes int   // request line and header section
+	MaxHeaders     int   // header and trailer fields
+	MaxBodyBytes   int64 // body, chunked framing excluded
+}
+
+// Option configures a Parser.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{
+		MaxHeaderBytes: DefaultMaxHeaderBytes,
+		MaxHeaders:     DefaultMaxHeaders,
+		MaxBodyBytes:   DefaultMaxBodyBytes,
+	}
+}
+
+// WithMaxHeaderBytes sets Config.MaxHeaderBytes.
+func WithMaxHeaderBytes(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxHeaderBytes = n
+		}
+	}
+}
+
+// WithMaxHeaders sets Config.MaxHeaders.
+func WithMaxHeaders(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxHeaders = n
+		}
+	}
+}
+
+// WithMaxBodyBytes sets Config.MaxBodyBytes.
+func WithMaxBodyBytes(n int64) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxBodyBytes = n
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package http1
+
+import (
+	"bytes"
+
+	"github.com/huynhanx03/go-common/pkg/codec/internal/segment"
+	"github.com/huynhanx03/go-common/pkg/utils/bytesx"
+)
+
+// maxChunkLine bounds a chunk-size line, extensions included.
+const maxChunkLine = 4096
+
+// Source is a segmented buffer the Parser reads from without copying.
+// *buffer.ElasticBuffer and *buffer.LinkedListBuffer implement it.
+type Source = segment.Source
+
+// Parser parses pipelined requests from a Source.
+// It is not safe for concurrent use.
+type Parser struct {
+	src    Source
+	config Config
+
+	req     Request
+	pending int           // size of the last request, consumed on the next call
+	arena   segment.Arena // copies of fields that straddle segments, joined chunks
+}
+
+// NewParser creates a parser reading from src.
+func NewParser(src Source, opts ...Option) *Parser {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &Parser{src: src, config: cfg}
+}
+
+// Next parses the next request. The returned Request is reused: it and its
+// fields stay valid until the next call to Next or Release, which consume it.
+//
+// Next returns ErrIncomplete, consuming nothing, until the request and its
+// whole body are buffered; a partial request is parsed again from its start
+// on the next call. Any other error means the connection must be closed.
+func (p *Parser) Next() (*Request, error) {
+	if err := p.Release(); err != nil {
+		return nil, err
+	}
+	p.arena.Reset()
+
+	segs, err := p.src.Peek(0)
+	if err != nil {
+		return nil, err
+	}
+	c := cursor{p: p}
+	c.Reset(segs, &p.arena)
+	if c.Avail() == 0 {
+		return nil, ErrIncomplete
+	}
+
+	if err := c.request(&p.req); err != nil {
+		return nil, err
+	}
+	p.pending = c.Consumed()
+	return &p.req, nil
+}
+
+// Release consumes the request returned by the last Next now, handing its
+// memory back to the source, instead of on the next call.
+func (p *Parser) Release() error {
+	if p.pending == 0 {
+		return nil
+	}
+	n := p.pending
+	p.pending = 0
+	_, err := p.src.Discard(n)
+	return err
+}
+
+// cursor walks the peeked segments.
+type cursor struct {
+	segment.Cursor
+	p *Parser
+}
+
+// span returns the next n bytes and advances past them.
+func (c *cursor) span(n int) ([]byte, error) {
+	b, ok := c.Span(n)
+	if !ok {
+		return nil, ErrIncomplete
+	}
+	return b, nil
+}
+
+// line returns the next CRLF-terminated line without its terminator. A line
+// longer than limit fails with ErrTooLarge.
+func (c *cursor) line(limit int) ([]byte, error) {
+	i, ok := c.Index(limit, lineEnd)
+	if !ok {
+		if i > limit {
+			return nil, ErrTooLarge
+		}
+		return nil, ErrIncomplete
+	}
+	if i+1 > limit {
+		return nil, ErrTooLarge
+	}
+
+	raw, _ := c.span(i + 1)
+	if len(raw) < 2 || raw[len(raw)-2] != '\r' {
+		return nil, ErrProtocol
+	}
+	return raw[:len(raw)-2], nil
+}
+
+// lineEnd finds the LF ending a line.
+func lineEnd(b []byte) int {
+	return bytes.IndexByte(b, '\n')
+}
+
+// headBudget is what is left of MaxHeaderBytes, counted from start.
+func (c *cursor) headBudget(start int) int {
+	return c.p.config.MaxHeaderBytes - (c.Consumed() - start)
+}
+
+// request parses a whole request into req.
+func (c *cursor) request(req *Request) error {
+	*req = Request{Headers: req.Headers[:0], Trailers: req.Trailers[:0]}
+
+	// RFC 9112 §2.2: ignore empty lines before the request line.
+	var line []byte
+	for {
+		var err error
+		if line, err = c.line(c.headBudget(0)); err != nil {
+			return err
+		}
+		if len(line) > 0 {
+			break
+		}
+	}
+	if err := parseRequestLine(req, line); err != nil {
+		return err
+	}
+
+	headers, err := c.fields(req.Headers, 0)
+	if err != nil {
+		return err
+	}
+	req.Headers = headers
+
+	if err := frame(req); err != nil {
+		return err
+	}
+	return c.body(req)
+}
+
+// parseRequestLine parses "METHOD SP target SP HTTP/x.y".
+func parseRequestLine(req *Request, line []byte) error {
+	sp1 := bytesx.IndexByte(line, ' ')
+	if sp1 < 0 {
+		return ErrProtocol
+	}
+	rest := line[sp1+1:]
+	sp2 := bytesx.IndexByte(rest, ' ')
+	if sp2 < 0 {
+		return ErrProtocol
+	}
+	req.Method, req.Target = line[:sp1], rest[:sp2]
+	proto := rest[sp2+1:]
+
+	if !isToken(req.Method) || len(req.Target) == 0 {
+		return ErrProtocol
+	}
+	for _, b := range req.Target {
+		if b <= ' ' || b == 0x7f {
+			return ErrProtocol
+		}
+	}
+	if len(proto) != 8 || string(proto[:5]) != "HTTP/" || proto[6] != '.' ||
+		!isDigit(proto[5]) || !isDigit(proto[7]) {
+		return ErrProtocol
+	}
+	req.ProtoMajor, req.ProtoMinor = int(proto[5]-'0'), int(proto[7]-'0')
+	if req.ProtoMajor != 1 {
+		return ErrUnsupported
+	}
+	return nil
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+// fields parses header or trailer fields up to the empty line, appending to
+// dst. start is the offset the header budget is counted from.
+func (c *cursor) fields(dst []Header, start int) ([]Header, error) {
+	for {
+		line, err := c.line(c.headBudget(start))
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			return dst, nil
+		}
+		if len(dst) >= c.p.config.MaxHeaders {
+			return nil, ErrTooLarge
+		}
+
+		colon := bytesx.IndexByte(line, ':')
+		// A name must be a token: no whitespace before the colon, and no
+		// obsolete line folding (a line starting with whitespace).
+		if colon <= 0 || !isToken(line[:colon]) {
+			return nil, ErrProtocol
+		}
+		value := trimOWS(line[colon+1:])
+		for _, b := range value {
+			if b == '\r' || b == 0 {
+				return nil, ErrProtocol
+			}
+		}
+		dst = append(dst, Header{Name: line[:colon], Value: value})
+	}
+}
+
+// frame determines the body framing and connection persistence.
+func frame(req *Request) error {
+	var (
+		te, cl   []byte
+		teFields int
+		host     bool
+	)
+	for _, h := range req.Headers {
+		switch {
+		case bytesx.EqualFoldASCII(h.Name, []byte("Transfer-Encoding")):
+			te = h.Value
+			teFields++
+		case bytesx.EqualFoldASCII(h.Name, []byte("Content-Length")):
+			// Repeated Content-Length fields must agree (RFC 9112 §6.3).
+			if cl != nil && !bytes.Equal(cl, h.Value) {
+				return ErrProtocol
+			}
+			cl = h.Value
+		case bytesx.EqualFoldASCII(h.Name, []byte("Host")):
+			host = true
+		}
+	}
+	if req.ProtoMinor >= 1 && !host {
+		return ErrProtocol
+	}
+
+	switch {
+	case te != nil:
+		// Both framings at once is a request smuggling vector.
+		if cl != nil {
+			return ErrProtocol
+		}
+		if teFields > 1 || !bytesx.EqualFoldASCII(te, []byte("chunked")) {
+			return ErrUnsupported
+		}
+		req.Chunked = true
+		req.ContentLength = -1
+	case cl != nil:
+		n, ok := parseDecimal(cl)
+		if !ok {
+			return ErrProtocol
+		}
+		req.ContentLength = n
+	}
+
+	conn := req.Header("Connection")
+	if req.ProtoMinor >= 1 {
+		req.KeepAlive = !hasToken(conn, "close")
+	} else {
+		req.KeepAlive = hasToken(conn, "keep-alive")
+	}
+	return nil
+}
+
+// parseDecimal parses a non-negative decimal that fits in int63.
+func parseDecimal(b []byte) (int64, bool) {
+	if len(b) == 0 || len(b) > 18 {
+		return 0, false
+	}
+	var n int64
+	for _, c := range b {
+		if !isDigit(c) {
+			return 0, false
+		}
+		n = n*10 + int64(c-'0')
+	}
+	return n, true
+}
+
+// body reads the body announced by req's framing.
+func (c *cursor) body(req *Request) error {
+	if !req.Chunked {
+		if req.ContentLength > c.p.config.MaxBodyBytes {
+			return ErrTooLarge
+		}
+		body, err := c.span(int(req.ContentLength))
+		req.Body = body
+		return err
+	}
+
+	var chunks [][]byte
+	var total int64
+	for {
+		line, err := c.line(maxChunkLine)
+		if err != nil {
+			return err
+		}
+		if i := bytesx.IndexByte(line, ';'); i >= 0 {
+			line = line[:i] // chunk extensions are ignored
+		}
+		n, ok := parseHex(trimOWS(line))
+		if !ok {
+			return ErrProtocol
+		}
+		if n == 0 {
+			break
+		}
+		if total += n; total > c.p.config.MaxBodyBytes {
+			return ErrTooLarge
+		}
+
+		raw, err := c.span(int(n) + 2)
+		if err != nil {
+			return err
+		}
+		if raw[n] != '\r' || raw[n+1] != '\n' {
+			return ErrProtocol
+		}
+		chunks = append(chunks, raw[:n])
+	}
+
+	trailers, err := c.fields(req.Trailers, c.Consumed())
+	if err != nil {
+		return err
+	}
+	req.Trailers = trailers
+
+	switch len(chunks) {
+	case 0:
+	case 1:
+		req.Body = chunks[0]
+	default:
+		body := c.p.arena.Alloc(int(total))[:0]
+		for _, chunk := range chunks {
+			body = append(body, chunk...)
+		}
+		req.Body = body
+	}
+	return nil
+}
+
+// parseHex parses a chunk size.
+func parseHex(b []byte) (int64, bool) {
+	if len(b) == 0 || len(b) > 15 {
+		return 0, false
+	}
+	var n int64
+	for _, c := range b {
+		switch {
+		case isDigit(c):
+			n = n<<4 | int64(c-'0')
+		case c >= 'a' && c <= 'f':
+			n = n<<4 | int64(c-'a'+10)
+		case c >= 'A' && c <= 'F':
+			n = n<<4 | int64(c-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	return n, true
+}
@@ -0,0 +1,117 @@
+package http1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+// ResponseWriter serializes responses into a *buffer.LinkedListBuffer,
+// typically a connection's outbound buffer drained by a FlushPump or the
+// event loop. The head is formatted into a reused scratch slice and copied
+// into the buffer once.
+// It is not safe for concurrent use.
+type ResponseWriter struct {
+	out     *buffer.LinkedListBuffer
+	scratch []byte
+	chunked bool
+}
+
+// NewResponseWriter creates a writer appending to out.
+func NewResponseWriter(out *buffer.LinkedListBuffer) *ResponseWriter {
+	return &ResponseWriter{out: out}
+}
+
+// WriteResponse writes a complete response with a Content-Length body.
+func (w *ResponseWriter) WriteResponse(status int, headers []Header, body []byte) {
+	w.WriteHeader(status, headers, int64(len(body)))
+	w.out.PushBack(body)
+}
+
+// WriteHeader writes the status line and headers. contentLength announces
+// the body size; -1 selects chunked encoding, in which case every WriteBody
+// is sent as one chunk and Finish ends the body. headers must not carry
+// their own Content-Length or Transfer-Encoding.
+func (w *ResponseWriter) WriteHeader(status int, headers []Header, contentLength int64) {
+	b := w.scratch[:0]
+	b = append(b, "HTTP/1.1 "...)
+	b = strconv.AppendInt(b, int64(status), 10)
+	b = append(b, ' ')
+	b = append(b, http.StatusText(status)...)
+	b = append(b, "\r\n"...)
+
+	for _, h := range headers {
+		b = appendField(b, h)
+	}
+	w.chunked = contentLength < 0
+	if w.chunked {
+		b = append(b, "Transfer-Encoding: chunked\r\n"...)
+	} else if bodyAllowed(status) {
+		b = append(b, "Content-Length: "...)
+		b = strconv.AppendInt(b, contentLength, 10)
+		b = append(b, "\r\n"...)
+	}
+	b = append(b, "\r\n"...)
+
+	w.out.PushBack(b)
+	w.scratch = b
+}
+
+// WriteBody writes p as body bytes, or as one chunk in chunked mode.
+// An empty p writes nothing; it would end a chunked body.
+func (w *ResponseWriter) WriteBody(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	if !w.chunked {
+		w.out.PushBack(p)
+		return
+	}
+
+	b := strconv.AppendInt(w.scratch[:0], int64(len(p)), 16)
+	b = append(b, "\r\n"...)
+	w.out.PushBack(b)
+	w.out.PushBack(p)
+	w.out.PushBack(crlf)
+	w.scratch = b
+}
+
+// Finish ends a chunked body with the given trailers. It is a no-op for a
+// Content-Length response.
+func (w *ResponseWriter) Finish(trailers ...Header) {
+	if !w.chunked {
+		return
+	}
+	w.chunked = false
+
+	b := append(w.scratch[:0], "0\r\n"...)
+	for _, h := range trailers {
+		b = appendField(b, h)
+	}
+	b = append(b, "\r\n"...)
+	w.out.PushBack(b)
+	w.scratch = b
+}
+
+var crlf = []byte("\r\n")
+
+// appendField appends "Name: Value\r\n". CR and LF in the value are
+// replaced by spaces so a value cannot inject header lines.
+func appendField(b []byte, h Header) []byte {
+	b = append(b, h.Name...)
+	b = append(b, ": "...)
+	for _, c := range h.Value {
+		if c == '\r' || c == '\n' {
+			c = ' '
+		}
+		b = append(b, c)
+	}
+	return append(b, "\r\n"...)
+}
+
+// bodyAllowed reports whether status may carry a body and hence a
+// Content-Length (RFC 9110 §8.6).
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,137 @@
+// Package segment walks the data peeked from a segmented buffer for the
+// codecs that parse in place. Views it hands out alias the buffer, unless
+// they straddle two segments and are copied into an arena.
+package segment
+
+// Source is a segmented buffer read without copying.
+// *buffer.LinkedListBuffer and *buffer.ElasticBuffer implement it.
+type Source interface {
+	// Peek returns the buffered data, all of it when n <= 0, without
+	// consuming it.
+	Peek(n int) ([][]byte, error)
+	// Discard consumes n bytes.
+	Discard(n int) (int, error)
+}
+
+// Arena holds copies of data that straddles segments.
+type Arena struct {
+	buf []byte
+}
+
+// Reset drops every allocation. Slices handed out before must no longer
+// be used.
+func (a *Arena) Reset() {
+	a.buf = a.buf[:0]
+}
+
+// Alloc returns n bytes of arena. Earlier allocations are never moved, so
+// slices handed out before stay valid until Reset.
+func (a *Arena) Alloc(n int) []byte {
+	if cap(a.buf)-len(a.buf) < n {
+		a.buf = make([]byte, 0, max(n, 2*cap(a.buf), 512))
+	}
+	start := len(a.buf)
+	a.buf = a.buf[:start+n]
+	return a.buf[start : start+n : start+n]
+}
+
+// Cursor walks peeked segments from their start.
+type Cursor struct {
+	segs  [][]byte
+	arena *Arena
+	seg   int // current segment
+	off   int // offset in segs[seg]
+	n     int // bytes consumed
+	avail int // bytes left
+}
+
+// Reset points the cursor at the start of segs. Spans that straddle
+// segments are copied into arena.
+func (c *Cursor) Reset(segs [][]byte, arena *Arena) {
+	*c = Cursor{segs: segs, arena: arena}
+	for _, s := range segs {
+		c.avail += len(s)
+	}
+}
+
+// Consumed returns how many bytes the cursor has moved past.
+func (c *Cursor) Consumed() int {
+	return c.n
+}
+
+// Avail returns how many bytes are left after the cursor.
+func (c *Cursor) Avail() int {
+	return c.avail
+}
+
+// Span returns the next n bytes and advances past them, or false, without
+// moving, if fewer are buffered. The bytes alias the segment holding them,
+// or an arena copy when they straddle segments.
+func (c *Cursor) Span(n int) ([]byte, bool) {
+	if n > c.avail {
+		return nil, false
+	}
+	c.skipEmpty()
+	if n == 0 {
+		return nil, true
+	}
+
+	if s := c.segs[c.seg][c.off:]; len(s) >= n {
+		c.advance(n)
+		return s[:n:n], true
+	}
+
+	out := c.arena.Alloc(n)
+	for copied := 0; copied < n; {
+		c.skipEmpty()
+		k := copy(out[copied:], c.segs[c.seg][c.off:])
+		copied += k
+		c.advance(k)
+	}
+	return out, true
+}
+
+// PeekByte returns the next byte without consuming it.
+func (c *Cursor) PeekByte() (byte, bool) {
+	c.skipEmpty()
+	if c.seg == len(c.segs) {
+		return 0, false
+	}
+	return c.segs[c.seg][c.off], true
+}
+
+// Index returns the offset from the cursor of the first match of find,
+// which reports the index of a match within one segment or -1. Matches
+// that straddle segments are not found. Without a match it returns false
+// and the number of bytes looked at, giving up once that exceeds limit.
+func (c *Cursor) Index(limit int, find func([]byte) int) (int, bool) {
+	c.skipEmpty()
+	scanned := 0
+	for i := c.seg; i < len(c.segs); i++ {
+		s := c.segs[i]
+		if i == c.seg {
+			s = s[c.off:]
+		}
+		if j := find(s); j >= 0 {
+			return scanned + j, true
+		}
+		if scanned += len(s); scanned > limit {
+			break
+		}
+	}
+	return scanned, false
+}
+
+// advance moves k bytes forward within the current segment.
+func (c *Cursor) advance(k int) {
+	c.off += k
+	c.n += k
+	c.avail -= k
+}
+
+func (c *Cursor) skipEmpty() {
+	for c.seg < len(c.segs) && c.off == len(c.segs[c.seg]) {
+		c.seg++
+		c.off = 0
+	}
+}
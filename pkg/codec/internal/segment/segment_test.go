@@ -0,0 +1,66 @@
+package segment
+
+import (
+	"bytes"
+	"testing"
+)
+
+func lf(b []byte) int { return bytes.IndexByte(b, '\n') }
+
+func TestCursor_Span(t *testing.T) {
+	var (
+		arena Arena
+		c     Cursor
+	)
+	segs := [][]byte{[]byte("ab"), nil, []byte("cdef")}
+	c.Reset(segs, &arena)
+	if c.Avail() != 6 {
+		t.Fatalf("Avail = %d, want 6", c.Avail())
+	}
+
+	b, ok := c.Span(1)
+	if !ok || string(b) != "a" || &b[0] != &segs[0][0] {
+		t.Fatalf("Span(1) = %q, %v; want an alias of \"a\"", b, ok)
+	}
+	b, ok = c.Span(3) // straddles the empty segment
+	if !ok || string(b) != "bcd" {
+		t.Fatalf("Span(3) = %q, %v; want \"bcd\"", b, ok)
+	}
+	if _, ok := c.Span(3); ok {
+		t.Fatal("Span past the buffered data succeeded")
+	}
+	if c.Consumed() != 4 || c.Avail() != 2 {
+		t.Errorf("Consumed, Avail = %d, %d; want 4, 2", c.Consumed(), c.Avail())
+	}
+	if p, ok := c.PeekByte(); !ok || p != 'e' {
+		t.Errorf("PeekByte = %q, %v; want 'e'", p, ok)
+	}
+}
+
+func TestCursor_Index(t *testing.T) {
+	var c Cursor
+	c.Reset([][]byte{[]byte("abc"), []byte("de\nf")}, &Arena{})
+	c.Span(1)
+
+	if i, ok := c.Index(100, lf); !ok || i != 4 {
+		t.Errorf("Index = %d, %v; want 4, true", i, ok)
+	}
+	if n, ok := c.Index(1, lf); ok || n != 2 {
+		t.Errorf("Index with limit 1 = %d, %v; want 2 scanned, false", n, ok)
+	}
+
+	c.Reset([][]byte{[]byte("abc")}, &Arena{})
+	if n, ok := c.Index(100, lf); ok || n != 3 {
+		t.Errorf("Index without a match = %d, %v; want 3 scanned, false", n, ok)
+	}
+}
+
+func TestArena_AllocStable(t *testing.T) {
+	var a Arena
+	first := a.Alloc(500)
+	copy(first, "x")
+	a.Alloc(1000) // grows into a new block
+	if first[0] != 'x' || len(first) != 500 || cap(first) != 500 {
+		t.Error("earlier allocation moved or changed")
+	}
+}
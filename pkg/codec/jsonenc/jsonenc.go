@@ -0,0 +1,246 @@
+// Package jsonenc writes JSON straight into a *buffer.Buffer, one token at
+// a time, for hot paths such as log lines and admin responses where
+// encoding/json's reflection and intermediate allocations show up. Decoding
+// stays with encoding/json.
+package jsonenc
+
+import (
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+	"github.com/huynhanx03/go-common/pkg/utils"
+)
+
+const hex = "0123456789abcdef"
+
+// Encoder appends JSON to a *buffer.Buffer. Objects and arrays are streamed
+// as begin, elements, end; commas and colons are inserted for the caller:
+//
+//	enc.BeginObject()
+//	enc.Key("level")
+//	enc.String("info")
+//	enc.Key("took_ms")
+//	enc.Int(12)
+//	enc.EndObject()
+//
+// It writes what it is told and does not check that the document is well
+// formed: a key outside an object or an unbalanced End is the caller's bug.
+// Appending never allocates beyond the buffer's own growth.
+//
+// It is not safe for concurrent use.
+type Encoder struct {
+	buf *buffer.Buffer
+
+	comma    bool // a value was written at this level; the next needs a comma
+	afterKey bool // a key was written; the next value follows its colon
+}
+
+// NewEncoder creates an encoder appending to buf.
+func NewEncoder(buf *buffer.Buffer) *Encoder {
+	return &Encoder{buf: buf}
+}
+
+// Buffer returns the buffer the encoder appends to.
+func (e *Encoder) Buffer() *buffer.Buffer {
+	return e.buf
+}
+
+// Reset makes the encoder append a new document to buf.
+func (e *Encoder) Reset(buf *buffer.Buffer) {
+	*e = Encoder{buf: buf}
+}
+
+// sep writes the comma a value or key needs before it, if any.
+func (e *Encoder) sep() {
+	if e.afterKey {
+		e.afterKey = false
+		return
+	}
+	if e.comma {
+		e.buf.WriteByte(',')
+	}
+}
+
+// BeginObject starts an object.
+func (e *Encoder) BeginObject() {
+	e.sep()
+	e.buf.WriteByte('{')
+	e.comma = false
+}
+
+// EndObject ends the current object.
+func (e *Encoder) EndObject() {
+	e.buf.WriteByte('}')
+	e.comma = true
+}
+
+// BeginArray starts an array.
+func (e *Encoder) BeginArray() {
+	e.sep()
+	e.buf.WriteByte('[')
+	e.comma = false
+}
+
+// EndArray ends the current array.
+func (e *Encoder) EndArray() {
+	e.buf.WriteByte(']')
+	e.comma = true
+}
+
+// Key writes an object key; the next value written is its value.
+func (e *Encoder) Key(k string) {
+	e.sep()
+	e.quote(k)
+	e.buf.WriteByte(':')
+	e.afterKey = true
+}
+
+// String writes s as a JSON string, escaped as encoding/json does without
+// HTML escaping. Invalid UTF-8 is replaced by U+FFFD.
+func (e *Encoder) String(s string) {
+	e.sep()
+	e.quote(s)
+	e.comma = true
+}
+
+// StringBytes writes p as a JSON string, like String, without converting
+// it to a string first.
+func (e *Encoder) StringBytes(p []byte) {
+	e.String(utils.BytesToString(p))
+}
+
+// Int writes i.
+func (e *Encoder) Int(i int64) {
+	e.sep()
+	e.buf.AppendInt(i, 10)
+	e.comma = true
+}
+
+// Uint writes u.
+func (e *Encoder) Uint(u uint64) {
+	e.sep()
+	e.buf.AppendUint(u, 10)
+	e.comma = true
+}
+
+// Float writes f in the shortest form that reads back as the same
+// float64, as encoding/json does. JSON has no NaN or infinities; they are
+// written as null.
+func (e *Encoder) Float(f float64) {
+	e.sep()
+	e.comma = true
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		e.buf.WriteString("null")
+		return
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	var tmp [32]byte
+	b := strconv.AppendFloat(tmp[:0], f, format, -1, 64)
+	if format == 'e' {
+		// Trim a leading zero off a two-digit exponent: 1e-07 to 1e-7.
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	e.buf.Write(b)
+}
+
+// Bool writes b.
+func (e *Encoder) Bool(b bool) {
+	e.sep()
+	if b {
+		e.buf.WriteString("true")
+	} else {
+		e.buf.WriteString("false")
+	}
+	e.comma = true
+}
+
+// Null writes null.
+func (e *Encoder) Null() {
+	e.sep()
+	e.buf.WriteString("null")
+	e.comma = true
+}
+
+// Time writes t as an RFC 3339 string with nanoseconds, the form
+// encoding/json gives time.Time.
+func (e *Encoder) Time(t time.Time) {
+	e.sep()
+	var tmp [64]byte
+	e.buf.WriteByte('"')
+	e.buf.Write(t.AppendFormat(tmp[:0], time.RFC3339Nano))
+	e.buf.WriteByte('"')
+	e.comma = true
+}
+
+// Raw writes p, which must be a complete JSON value, as is: a document
+// encoded elsewhere or cached.
+func (e *Encoder) Raw(p []byte) {
+	e.sep()
+	e.buf.Write(p)
+	e.comma = true
+}
+
+// quote writes s quoted and escaped. Runs of bytes that need no escaping
+// are copied at once.
+func (e *Encoder) quote(s string) {
+	e.buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			e.buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				e.buf.Write([]byte{'\\', c})
+			case '\n':
+				e.buf.WriteString(`\n`)
+			case '\r':
+				e.buf.WriteString(`\r`)
+			case '\t':
+				e.buf.WriteString(`\t`)
+			case '\b':
+				e.buf.WriteString(`\b`)
+			case '\f':
+				e.buf.WriteString(`\f`)
+			default:
+				e.buf.Write([]byte{'\\', 'u', '0', '0', hex[c>>4], hex[c&0xF]})
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			e.buf.WriteString(s[start:i])
+			e.buf.WriteString("\ufffd")
+		case r == '\u2028' || r == '\u2029':
+			// Valid JSON, but line terminators to JavaScript.
+			e.buf.WriteString(s[start:i])
+			e.buf.WriteString(`\u202`)
+			e.buf.WriteByte(hex[r&0xF])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	e.buf.WriteString(s[start:])
+	e.buf.WriteByte('"')
+}
@@ -0,0 +1,186 @@
+package jsonenc
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+// stdlib encodes v with encoding/json, HTML escaping off.
+func stdlib(t *testing.T, v any) string {
+	t.Helper()
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	return string(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+}
+
+// =============================================================================
+// Structure Tests
+// =============================================================================
+
+func TestEncoder_Document(t *testing.T) {
+	buf := buffer.New(0)
+	enc := NewEncoder(buf)
+
+	enc.BeginObject()
+	enc.Key("level")
+	enc.String("info")
+	enc.Key("n")
+	enc.Int(-12)
+	enc.Key("tags")
+	enc.BeginArray()
+	enc.String("a")
+	enc.Uint(math.MaxUint64)
+	enc.BeginObject()
+	enc.EndObject()
+	enc.BeginArray()
+	enc.EndArray()
+	enc.Null()
+	enc.EndArray()
+	enc.Key("ok")
+	enc.Bool(true)
+	enc.Key("raw")
+	enc.Raw([]byte(`{"x":1}`))
+	enc.Key("f")
+	enc.Float(0.5)
+	enc.EndObject()
+
+	want := `{"level":"info","n":-12,"tags":["a",18446744073709551615,{},[],null],"ok":true,"raw":{"x":1},"f":0.5}`
+	if got := string(buf.Bytes()); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Error("output is not valid JSON")
+	}
+
+	// Reset starts a new document with no leading comma.
+	next := buffer.New(0)
+	enc.Reset(next)
+	enc.Int(1)
+	if got := string(next.Bytes()); got != "1" || enc.Buffer() != next {
+		t.Errorf("after Reset got %q", got)
+	}
+}
+
+// =============================================================================
+// Value Tests
+// =============================================================================
+
+func TestEncoder_String(t *testing.T) {
+	tests := []string{
+		"",
+		"plain ascii",
+		`quote " and \ backslash`,
+		"ctl \n\r\t\b\f \x00 \x1f \x7f",
+		"<html> & 'q'",
+		"héllo, 世界 🙂",
+		"bad \xff utf8 \xe2\x82",
+		"js \u2028 \u2029 terminators",
+	}
+	for _, s := range tests {
+		buf := buffer.New(0)
+		enc := NewEncoder(buf)
+		enc.String(s)
+		if got, want := string(buf.Bytes()), stdlib(t, s); got != want {
+			t.Errorf("String(%q) = %s, want %s", s, got, want)
+		}
+
+		buf.Reset()
+		enc.Reset(buf)
+		enc.StringBytes([]byte(s))
+		if got, want := string(buf.Bytes()), stdlib(t, s); got != want {
+			t.Errorf("StringBytes(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
+
+func TestEncoder_Float(t *testing.T) {
+	tests := []float64{0, -0.0, 1, -1.5, 3.14159, 1e20, 1e21, 1e-6, 1e-7, 123456789e-30, math.MaxFloat64, math.SmallestNonzeroFloat64}
+	for _, f := range tests {
+		buf := buffer.New(0)
+		NewEncoder(buf).Float(f)
+		if got, want := string(buf.Bytes()), stdlib(t, f); got != want {
+			t.Errorf("Float(%v) = %s, want %s", f, got, want)
+		}
+	}
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		buf := buffer.New(0)
+		NewEncoder(buf).Float(f)
+		if got := string(buf.Bytes()); got != "null" {
+			t.Errorf("Float(%v) = %s, want null", f, got)
+		}
+	}
+}
+
+func TestEncoder_Time(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.FixedZone("X", 3600))
+	buf := buffer.New(0)
+	NewEncoder(buf).Time(ts)
+	if got, want := string(buf.Bytes()), stdlib(t, ts); got != want {
+		t.Errorf("Time = %s, want %s", got, want)
+	}
+}
+
+// =============================================================================
+// Allocation Tests
+// =============================================================================
+
+func TestEncoder_ZeroAlloc(t *testing.T) {
+	buf := buffer.New(1 << 16)
+	enc := NewEncoder(buf)
+	now := time.Now()
+	msg := []byte("escaped \"message\"\n with ünïcode")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		enc.Reset(buf)
+		writeLine(enc, now, msg)
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per document = %v, want 0", allocs)
+	}
+}
+
+func writeLine(enc *Encoder, now time.Time, msg []byte) {
+	enc.BeginObject()
+	enc.Key("ts")
+	enc.Time(now)
+	enc.Key("msg")
+	enc.StringBytes(msg)
+	enc.Key("status")
+	enc.Int(200)
+	enc.Key("latency")
+	enc.Float(0.0123)
+	enc.Key("tags")
+	enc.BeginArray()
+	enc.String("a")
+	enc.Bool(false)
+	enc.EndArray()
+	enc.EndObject()
+}
+
+// =============================================================================
+// Benchmarks
+// =============================================================================
+
+func BenchmarkEncoder_Line(b *testing.B) {
+	buf := buffer.New(1 << 16)
+	enc := NewEncoder(buf)
+	now := time.Now()
+	msg := []byte("request served")
+	b.ReportAllocs()
+	for b.Loop() {
+		buf.Reset()
+		enc.Reset(buf)
+		writeLine(enc, now, msg)
+	}
+}
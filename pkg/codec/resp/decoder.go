@@ -0,0 +1,316 @@
+package resp
+
+import (
+	"bytes"
+
+	"github.com/huynhanx03/go-common/pkg/codec/internal/segment"
+)
+
+// Source is a segmented buffer the Decoder reads from without copying.
+// *buffer.LinkedListBuffer and *buffer.ElasticBuffer implement it.
+type Source = segment.Source
+
+// Decoder parses RESP values from a Source.
+// It is not safe for concurrent use.
+type Decoder struct {
+	src    Source
+	config Config
+
+	pending int           // size of the last value, consumed on the next call
+	arena   segment.Arena // copies of strings that straddle segments
+}
+
+// NewDecoder creates a decoder reading from src.
+func NewDecoder(src Source, opts ...Option) *Decoder {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &Decoder{src: src, config: cfg}
+}
+
+// Next parses the next value. Its strings point into the source buffer and
+// stay valid until the next call to Next or Release, which consume it.
+//
+// Next returns ErrIncomplete, consuming nothing, until a whole value is
+// buffered; a partially received value is parsed again from its start on
+// the next call. Any other error means the stream is corrupt.
+func (d *Decoder) Next() (Value, error) {
+	if err := d.Release(); err != nil {
+		return Value{}, err
+	}
+	d.arena.Reset()
+
+	segs, err := d.src.Peek(0)
+	if err != nil {
+		return Value{}, err
+	}
+	c := cursor{d: d}
+	c.Reset(segs, &d.arena)
+	if c.Avail() == 0 {
+		return Value{}, ErrIncomplete
+	}
+
+	v, err := c.value(0)
+	if err != nil {
+		return Value{}, err
+	}
+	d.pending = c.Consumed()
+	return v, nil
+}
+
+// Release consumes the value returned by the last Next now, handing its
+// memory back to the source, instead of on the next call.
+func (d *Decoder) Release() error {
+	if d.pending == 0 {
+		return nil
+	}
+	n := d.pending
+	d.pending = 0
+	_, err := d.src.Discard(n)
+	return err
+}
+
+// cursor walks the peeked segments.
+type cursor struct {
+	segment.Cursor
+	d *Decoder
+}
+
+// span returns the next n bytes and advances past them.
+func (c *cursor) span(n int) ([]byte, error) {
+	b, ok := c.Span(n)
+	if !ok {
+		return nil, ErrIncomplete
+	}
+	return b, nil
+}
+
+// line returns the next CRLF-terminated line without its terminator.
+func (c *cursor) line() ([]byte, error) {
+	i, ok := c.Index(c.d.config.MaxLineLen, lineEnd)
+	if !ok {
+		if i > c.d.config.MaxLineLen {
+			return nil, ErrTooLarge
+		}
+		return nil, ErrIncomplete
+	}
+
+	raw, _ := c.span(i + 1)
+	if len(raw) < 2 || raw[len(raw)-2] != '\r' {
+		return nil, ErrProtocol
+	}
+	if len(raw)-2 > c.d.config.MaxLineLen {
+		return nil, ErrTooLarge
+	}
+	return raw[:len(raw)-2], nil
+}
+
+// lineEnd finds the LF ending a line.
+func lineEnd(b []byte) int {
+	return bytes.IndexByte(b, '\n')
+}
+
+// bulk returns the next n bytes followed by CRLF.
+func (c *cursor) bulk(n int) ([]byte, error) {
+	raw, err := c.span(n + 2)
+	if err != nil {
+		return nil, err
+	}
+	if raw[n] != '\r' || raw[n+1] != '\n' {
+		return nil, ErrProtocol
+	}
+	return raw[:n], nil
+}
+
+// length parses an aggregate or bulk header length. -1 means null and is
+// only valid where nullOK.
+func (c *cursor) length(b []byte, limit int, nullOK bool) (int, error) {
+	n, err := parseInt(b)
+	if err != nil {
+		return 0, err
+	}
+	if n == -1 && nullOK {
+		return -1, nil
+	}
+	if n < 0 {
+		return 0, ErrProtocol
+	}
+	if n > int64(limit) {
+		return 0, ErrTooLarge
+	}
+	return int(n), nil
+}
+
+// value parses one value at the cursor.
+func (c *cursor) value(depth int) (Value, error) {
+	if depth > c.d.config.MaxDepth {
+		return Value{}, ErrTooLarge
+	}
+	line, err := c.line()
+	if err != nil {
+		return Value{}, err
+	}
+	if len(line) == 0 {
+		return Value{}, ErrProtocol
+	}
+
+	v := Value{Type: Type(line[0])}
+	body := line[1:]
+	switch v.Type {
+	case SimpleString, SimpleError, BigNumber:
+		v.Str = body
+
+	case Integer:
+		v.Int, err = parseInt(body)
+
+	case Null:
+		if len(body) != 0 {
+			return Value{}, ErrProtocol
+		}
+		v.Null = true
+
+	case Boolean:
+		switch string(body) {
+		case "t":
+			v.Int = 1
+		case "f":
+		default:
+			return Value{}, ErrProtocol
+		}
+
+	case Double:
+		v.Str = body
+		v.Float, err = parseFloat(body)
+
+	case BulkString, BulkError, VerbatimString:
+		if v.Type == BulkString && string(body) == "?" {
+			v.Str, err = c.streamedString()
+			break
+		}
+		var n int
+		if n, err = c.length(body, c.d.config.MaxBulkLen, v.Type == BulkString); err != nil {
+			break
+		}
+		if n < 0 {
+			v.Null = true
+			break
+		}
+		v.Str, err = c.bulk(n)
+
+	case Array, Set, Push, Map, Attribute:
+		if string(body) == "?" {
+			v.Elems, err = c.streamedElems(v.Type, depth)
+			break
+		}
+		var n int
+		if n, err = c.length(body, c.d.config.MaxArrayLen, v.Type == Array); err != nil {
+			break
+		}
+		if n < 0 {
+			v.Null = true
+			break
+		}
+		v.Elems, err = c.elems(v.Type, n, depth)
+
+	default:
+		return Value{}, ErrProtocol
+	}
+	if err != nil {
+		return Value{}, err
+	}
+	return v, nil
+}
+
+// elems parses the n entries of an aggregate (pairs for maps).
+func (c *cursor) elems(t Type, n, depth int) ([]Value, error) {
+	if t == Map || t == Attribute {
+		n *= 2
+	}
+	// Every element takes at least 3 bytes ("_\r\n"). Bail out before
+	// allocating for a header whose elements have not arrived.
+	if n > c.Avail()/3 {
+		return nil, ErrIncomplete
+	}
+
+	elems := make([]Value, n)
+	for i := range elems {
+		e, err := c.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = e
+	}
+	return elems, nil
+}
+
+// streamedElems parses the elements of a streamed aggregate up to its ".".
+func (c *cursor) streamedElems(t Type, depth int) ([]Value, error) {
+	var elems []Value
+	for {
+		b, ok := c.PeekByte()
+		if !ok {
+			return nil, ErrIncomplete
+		}
+		if b == streamEnd {
+			line, err := c.line()
+			if err != nil {
+				return nil, err
+			}
+			if len(line) != 1 {
+				return nil, ErrProtocol
+			}
+			break
+		}
+		if len(elems) >= c.d.config.MaxArrayLen*2 {
+			return nil, ErrTooLarge
+		}
+		e, err := c.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, e)
+	}
+	if (t == Map || t == Attribute) && len(elems)%2 != 0 {
+		return nil, ErrProtocol
+	}
+	return elems, nil
+}
+
+// streamedString parses the ";n" chunks of a streamed string up to ";0"
+// and joins them.
+func (c *cursor) streamedString() ([]byte, error) {
+	var chunks [][]byte
+	total := 0
+	for {
+		line, err := c.line()
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != ';' {
+			return nil, ErrProtocol
+		}
+		n, err := c.length(line[1:], c.d.config.MaxBulkLen-total, false)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			break
+		}
+		chunk, err := c.bulk(n)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+		total += n
+	}
+
+	if len(chunks) == 1 {
+		return chunks[0], nil
+	}
+	out := c.d.arena.Alloc(total)[:0]
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	return out, nil
+}
@@ -0,0 +1,221 @@
+package resp
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+var crlf = []byte("\r\n")
+
+// Encoder appends RESP values to a *buffer.Buffer. Aggregates are written
+// as a header followed by their elements, so arrays can be streamed without
+// building a Value first:
+//
+//	enc.WriteArrayHeader(2)
+//	enc.WriteBulkString("GET")
+//	enc.WriteBulkString(key)
+//
+// It is not safe for concurrent use.
+type Encoder struct {
+	buf *buffer.Buffer
+}
+
+// NewEncoder creates an encoder appending to buf.
+func NewEncoder(buf *buffer.Buffer) *Encoder {
+	return &Encoder{buf: buf}
+}
+
+// Buffer returns the buffer the encoder appends to.
+func (e *Encoder) Buffer() *buffer.Buffer {
+	return e.buf
+}
+
+// Reset makes the encoder append to buf.
+func (e *Encoder) Reset(buf *buffer.Buffer) {
+	e.buf = buf
+}
+
+// line writes t, s and CRLF. s must not contain CR or LF.
+func (e *Encoder) line(t Type, s string) {
+	e.buf.WriteByte(byte(t))
+	e.buf.WriteString(s)
+	e.buf.Write(crlf)
+}
+
+// header writes t, n and CRLF.
+func (e *Encoder) header(t Type, n int64) {
+	e.buf.WriteByte(byte(t))
+	e.buf.AppendInt(n, 10)
+	e.buf.Write(crlf)
+}
+
+// WriteSimpleString writes a simple string. s must not contain CR or LF.
+func (e *Encoder) WriteSimpleString(s string) {
+	e.line(SimpleString, s)
+}
+
+// WriteError writes a simple error such as "ERR unknown command".
+// msg must not contain CR or LF.
+func (e *Encoder) WriteError(msg string) {
+	e.line(SimpleError, msg)
+}
+
+// WriteInt writes an integer.
+func (e *Encoder) WriteInt(i int64) {
+	e.header(Integer, i)
+}
+
+// WriteBulk writes p as a bulk string.
+func (e *Encoder) WriteBulk(p []byte) {
+	e.header(BulkString, int64(len(p)))
+	e.buf.Write(p)
+	e.buf.Write(crlf)
+}
+
+// WriteBulkString writes s as a bulk string.
+func (e *Encoder) WriteBulkString(s string) {
+	e.header(BulkString, int64(len(s)))
+	e.buf.WriteString(s)
+	e.buf.Write(crlf)
+}
+
+// WriteBulkInt writes i as a bulk string, the form commands take numbers in.
+func (e *Encoder) WriteBulkInt(i int64) {
+	var tmp [20]byte
+	digits := strconv.AppendInt(tmp[:0], i, 10)
+	e.header(BulkString, int64(len(digits)))
+	e.buf.Write(digits)
+	e.buf.Write(crlf)
+}
+
+// WriteNull writes the RESP3 null.
+func (e *Encoder) WriteNull() {
+	e.buf.WriteString("_\r\n")
+}
+
+// WriteNullBulk writes the RESP2 null bulk string.
+func (e *Encoder) WriteNullBulk() {
+	e.buf.WriteString("$-1\r\n")
+}
+
+// WriteNullArray writes the RESP2 null array.
+func (e *Encoder) WriteNullArray() {
+	e.buf.WriteString("*-1\r\n")
+}
+
+// WriteBool writes a RESP3 boolean.
+func (e *Encoder) WriteBool(b bool) {
+	if b {
+		e.buf.WriteString("#t\r\n")
+	} else {
+		e.buf.WriteString("#f\r\n")
+	}
+}
+
+// WriteDouble writes a RESP3 double.
+func (e *Encoder) WriteDouble(f float64) {
+	e.buf.WriteByte(byte(Double))
+	switch {
+	case math.IsInf(f, 1):
+		e.buf.WriteString("inf")
+	case math.IsInf(f, -1):
+		e.buf.WriteString("-inf")
+	case math.IsNaN(f):
+		e.buf.WriteString("nan")
+	default:
+		e.buf.AppendFloat(f, 'g', -1, 64)
+	}
+	e.buf.Write(crlf)
+}
+
+// WriteArrayHeader starts an array of n elements.
+func (e *Encoder) WriteArrayHeader(n int) {
+	e.header(Array, int64(n))
+}
+
+// WriteMapHeader starts a RESP3 map of n key-value pairs.
+func (e *Encoder) WriteMapHeader(n int) {
+	e.header(Map, int64(n))
+}
+
+// WriteSetHeader starts a RESP3 set of n elements.
+func (e *Encoder) WriteSetHeader(n int) {
+	e.header(Set, int64(n))
+}
+
+// WritePushHeader starts a RESP3 push of n elements.
+func (e *Encoder) WritePushHeader(n int) {
+	e.header(Push, int64(n))
+}
+
+// WriteStreamHeader starts a RESP3 streamed aggregate of type t (Array,
+// Set, Map, Push), for when the element count is not known upfront.
+// End it with WriteStreamEnd.
+func (e *Encoder) WriteStreamHeader(t Type) {
+	e.line(t, "?")
+}
+
+// WriteStreamEnd ends a streamed aggregate.
+func (e *Encoder) WriteStreamEnd() {
+	e.buf.WriteString(".\r\n")
+}
+
+// WriteCommand writes a command as an array of bulk strings.
+func (e *Encoder) WriteCommand(args ...string) {
+	e.WriteArrayHeader(len(args))
+	for _, a := range args {
+		e.WriteBulkString(a)
+	}
+}
+
+// WriteValue writes v and its elements. A Null value is written in its
+// type's RESP2 form for BulkString and Array, as the RESP3 null otherwise.
+func (e *Encoder) WriteValue(v Value) error {
+	if v.Null {
+		switch v.Type {
+		case BulkString:
+			e.WriteNullBulk()
+		case Array:
+			e.WriteNullArray()
+		default:
+			e.WriteNull()
+		}
+		return nil
+	}
+
+	switch v.Type {
+	case SimpleString, SimpleError, BigNumber:
+		e.buf.WriteByte(byte(v.Type))
+		e.buf.Write(v.Str)
+		e.buf.Write(crlf)
+	case Integer:
+		e.WriteInt(v.Int)
+	case Boolean:
+		e.WriteBool(v.Int != 0)
+	case Double:
+		e.WriteDouble(v.Float)
+	case BulkString, BulkError, VerbatimString:
+		e.header(v.Type, int64(len(v.Str)))
+		e.buf.Write(v.Str)
+		e.buf.Write(crlf)
+	case Array, Set, Push, Map, Attribute:
+		n := len(v.Elems)
+		if v.Type == Map || v.Type == Attribute {
+			if n%2 != 0 {
+				return ErrProtocol
+			}
+			n /= 2
+		}
+		e.header(v.Type, int64(n))
+		for _, el := range v.Elems {
+			if err := e.WriteValue(el); err != nil {
+				return err
+			}
+		}
+	default:
+		return ErrProtocol
+	}
+	return nil
+}
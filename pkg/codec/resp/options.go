@@ -0,0 +1,65 @@
+package resp
+
+// Defaults.
+const (
+	DefaultMaxBulkLen  = 512 << 20 // 512 MB, Redis' proto-max-bulk-len
+	DefaultMaxArrayLen = 1 << 24
+	DefaultMaxLineLen  = 64 << 10 // 64 KB, Redis' inline limit
+	DefaultMaxDepth    = 32
+)
+
+// Config holds the Decoder limits. Exceeding one fails with ErrTooLarge.
+type Config struct {
+	MaxBulkLen  int // bytes in a bulk string, whole streamed string included
+	MaxArrayLen int // elements in an aggregate (pairs for a map)
+	MaxLineLen  int // bytes in a simple string, error or header line
+	MaxDepth    int // aggregate nesting
+}
+
+// Option configures a Decoder.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{
+		MaxBulkLen:  DefaultMaxBulkLen,
+		MaxArrayLen: DefaultMaxArrayLen,
+		MaxLineLen:  DefaultMaxLineLen,
+		MaxDepth:    DefaultMaxDepth,
+	}
+}
+
+// WithMaxBulkLen sets Config.MaxBulkLen.
+func WithMaxBulkLen(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxBulkLen = n
+		}
+	}
+}
+
+// WithMaxArrayLen sets Config.MaxArrayLen.
+func WithMaxArrayLen(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxArrayLen = n
+		}
+	}
+}
+
+// WithMaxLineLen sets Config.MaxLineLen.
+func WithMaxLineLen(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxLineLen = n
+		}
+	}
+}
+
+// WithMaxDepth sets Config.MaxDepth.
+func WithMaxDepth(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxDepth = n
+		}
+	}
+}
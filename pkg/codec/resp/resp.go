@@ -0,0 +1,134 @@
+// Package resp encodes and decodes the Redis serialization protocol, RESP2
+// and RESP3, including RESP3 streamed strings and aggregates.
+//
+// The Decoder parses straight out of a segmented buffer such as a
+// *buffer.LinkedListBuffer or *buffer.ElasticBuffer through Peek/Discard:
+// strings in a decoded Value alias the buffer's memory, and are copied only
+// when they straddle two segments. The Encoder appends to a *buffer.Buffer.
+package resp
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/huynhanx03/go-common/pkg/utils"
+)
+
+// Type is the RESP type marker, the first byte of every value.
+type Type byte
+
+// RESP2 types.
+const (
+	SimpleString Type = '+'
+	SimpleError  Type = '-'
+	Integer      Type = ':'
+	BulkString   Type = '$'
+	Array        Type = '*'
+)
+
+// RESP3 types.
+const (
+	Null           Type = '_'
+	Boolean        Type = '#'
+	Double         Type = ','
+	BigNumber      Type = '('
+	BulkError      Type = '!'
+	VerbatimString Type = '='
+	Map            Type = '%'
+	Attribute      Type = '|'
+	Set            Type = '~'
+	Push           Type = '>'
+)
+
+// streamEnd terminates a streamed aggregate.
+const streamEnd = '.'
+
+var (
+	// ErrIncomplete is returned by Decoder.Next when the buffer does not yet
+	// hold a whole value. Nothing is consumed; read more and call again.
+	ErrIncomplete = errors.New("resp: incomplete value")
+
+	// ErrProtocol is returned for malformed input. The stream cannot be
+	// resynchronized and the connection should be closed.
+	ErrProtocol = errors.New("resp: protocol error")
+
+	// ErrTooLarge is returned when a value exceeds a configured limit.
+	ErrTooLarge = errors.New("resp: value exceeds limit")
+)
+
+// Value is a decoded RESP value.
+type Value struct {
+	Type Type
+
+	// Str holds simple and bulk strings, errors, big numbers and verbatim
+	// strings (with their "fmt:" prefix). It aliases decoder memory.
+	Str []byte
+
+	Int   int64   // Integer; 1 or 0 for Boolean
+	Float float64 // Double
+	Null  bool    // RESP3 null, or RESP2 null bulk string or array
+
+	// Elems holds the elements of an Array, Set or Push, and the keys and
+	// values of a Map or Attribute alternately.
+	Elems []Value
+}
+
+// Text returns a copy of Str as a string.
+func (v Value) Text() string {
+	return string(v.Str)
+}
+
+// Err returns the error message of a SimpleError or BulkError as an Error,
+// nil for other types.
+func (v Value) Err() error {
+	if v.Type != SimpleError && v.Type != BulkError {
+		return nil
+	}
+	return Error(v.Str)
+}
+
+// Error is an error reply sent by the peer, e.g. "ERR unknown command".
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// parseInt parses a signed decimal without allocating.
+func parseInt(b []byte) (int64, error) {
+	if len(b) == 0 {
+		return 0, ErrProtocol
+	}
+	neg := b[0] == '-'
+	if neg || b[0] == '+' {
+		b = b[1:]
+		if len(b) == 0 {
+			return 0, ErrProtocol
+		}
+	}
+
+	var n uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, ErrProtocol
+		}
+		if n > (1<<63)/10 {
+			return 0, ErrProtocol
+		}
+		n = n*10 + uint64(c-'0')
+	}
+	switch {
+	case neg && n <= 1<<63:
+		return -int64(n), nil
+	case !neg && n < 1<<63:
+		return int64(n), nil
+	}
+	return 0, ErrProtocol
+}
+
+// parseFloat parses a RESP3 double, including inf, -inf and nan.
+func parseFloat(b []byte) (float64, error) {
+	f, err := strconv.ParseFloat(utils.BytesToString(b), 64)
+	if err != nil {
+		return 0, ErrProtocol
+	}
+	return f, nil
+}
@@ -0,0 +1,264 @@
+package resp
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+// listOf returns a LinkedListBuffer holding parts as separate segments.
+func listOf(parts ...string) *buffer.LinkedListBuffer {
+	var ll buffer.LinkedListBuffer
+	for _, p := range parts {
+		ll.PushBack([]byte(p))
+	}
+	return &ll
+}
+
+func decodeOne(t *testing.T, parts ...string) Value {
+	t.Helper()
+	v, err := NewDecoder(listOf(parts...)).Next()
+	if err != nil {
+		t.Fatalf("Next(%q) error: %v", parts, err)
+	}
+	return v
+}
+
+// simplify turns a Value into comparable Go values.
+func simplify(v Value) any {
+	if v.Null {
+		return nil
+	}
+	switch v.Type {
+	case Integer, Boolean:
+		return v.Int
+	case Double:
+		return v.Float
+	case Array, Set, Push, Map, Attribute:
+		out := []any{string(v.Type)}
+		for _, e := range v.Elems {
+			out = append(out, simplify(e))
+		}
+		return out
+	}
+	return string(v.Type) + string(v.Str)
+}
+
+// =============================================================================
+// Decoder
+// =============================================================================
+
+func TestDecoder_Types(t *testing.T) {
+	tests := []struct {
+		in   string
+		want any
+	}{
+		{"+OK\r\n", "+OK"},
+		{"-ERR bad\r\n", "-ERR bad"},
+		{":-42\r\n", int64(-42)},
+		{"$5\r\nhello\r\n", "$hello"},
+		{"$0\r\n\r\n", "$"},
+		{"$-1\r\n", nil},
+		{"*-1\r\n", nil},
+		{"_\r\n", nil},
+		{"#t\r\n", int64(1)},
+		{",1.5\r\n", 1.5},
+		{",-inf\r\n", math.Inf(-1)},
+		{"(3492890328409238509324850943850943825024385\r\n", "(3492890328409238509324850943850943825024385"},
+		{"!3\r\nerr\r\n", "!err"},
+		{"=8\r\ntxt:abcd\r\n", "=txt:abcd"},
+		{"*2\r\n$3\r\nGET\r\n$1\r\nk\r\n", []any{"*", "$GET", "$k"}},
+		{"%1\r\n+a\r\n:1\r\n", []any{"%", "+a", int64(1)}},
+		{"~2\r\n:1\r\n*1\r\n_\r\n", []any{"~", int64(1), []any{"*", nil}}},
+		{">1\r\n+msg\r\n", []any{">", "+msg"}},
+		{"$?\r\n;4\r\nHell\r\n;2\r\no!\r\n;0\r\n", "$Hello!"},
+		{"*?\r\n:1\r\n:2\r\n.\r\n", []any{"*", int64(1), int64(2)}},
+		{"%?\r\n+k\r\n+v\r\n.\r\n", []any{"%", "+k", "+v"}},
+	}
+	for _, tc := range tests {
+		if got := simplify(decodeOne(t, tc.in)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("decode %q = %#v, want %#v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDecoder_EverySplit(t *testing.T) {
+	in := "*3\r\n$5\r\nhello\r\n$?\r\n;2\r\nab\r\n;1\r\nc\r\n;0\r\n%1\r\n+k\r\n,2.5\r\n"
+	want := simplify(decodeOne(t, in))
+	for i := 1; i < len(in); i++ {
+		for j := i; j < len(in); j++ {
+			got := simplify(decodeOne(t, in[:i], in[i:j], in[j:]))
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("split %d,%d: %#v, want %#v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestDecoder_Incremental(t *testing.T) {
+	in := "*2\r\n$3\r\nfoo\r\n:7\r\n+next\r\n"
+	var ll buffer.LinkedListBuffer
+	d := NewDecoder(&ll)
+
+	var got []any
+	for i := 0; i < len(in); i++ {
+		ll.PushBack([]byte{in[i]})
+		v, err := d.Next()
+		if errors.Is(err, ErrIncomplete) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("byte %d: %v", i, err)
+		}
+		got = append(got, simplify(v))
+	}
+	want := []any{[]any{"*", "$foo", int64(7)}, "+next"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("values = %#v, want %#v", got, want)
+	}
+	if _, err := d.Next(); !errors.Is(err, ErrIncomplete) || ll.Buffered() != 0 {
+		t.Errorf("after last value: err=%v buffered=%d", err, ll.Buffered())
+	}
+}
+
+func TestDecoder_ZeroCopy(t *testing.T) {
+	ll := listOf("$5\r\nhello\r\n")
+	segs, _ := ll.Peek(0)
+	v, err := NewDecoder(ll).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &v.Str[0] != &segs[0][4] {
+		t.Error("bulk string within one segment was copied")
+	}
+}
+
+func TestDecoder_ElasticBuffer(t *testing.T) {
+	eb, _ := buffer.NewElastic(8) // small ring: the value spills to the list
+	defer eb.Release()
+	eb.Write([]byte("*2\r\n$10\r\n0123456789\r\n:1\r\n"))
+
+	v, err := NewDecoder(eb).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []any{"*", "$0123456789", int64(1)}
+	if got := simplify(v); !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %#v, want %#v", got, want)
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		in   string
+		opts []Option
+		want error
+	}{
+		{"?x\r\n", nil, ErrProtocol},
+		{"+OK\n", nil, ErrProtocol},
+		{":12a\r\n", nil, ErrProtocol},
+		{":99999999999999999999\r\n", nil, ErrProtocol},
+		{"$3\r\nabcXY", nil, ErrProtocol},
+		{"$-2\r\n", nil, ErrProtocol},
+		{"%-1\r\n", nil, ErrProtocol},
+		{"#x\r\n", nil, ErrProtocol},
+		{"%?\r\n+k\r\n.\r\n", nil, ErrProtocol},
+		{"$100\r\n", []Option{WithMaxBulkLen(10)}, ErrTooLarge},
+		{"*100\r\n", []Option{WithMaxArrayLen(10)}, ErrTooLarge},
+		{"+aaaaaaaaaaaaaaaaaaaa", []Option{WithMaxLineLen(8)}, ErrTooLarge},
+		{"*1\r\n*1\r\n*1\r\n:1\r\n", []Option{WithMaxDepth(2)}, ErrTooLarge},
+		{"*1000\r\n:1\r\n", nil, ErrIncomplete},
+		{"$5\r\nhel", nil, ErrIncomplete},
+	}
+	for _, tc := range tests {
+		_, err := NewDecoder(listOf(tc.in), tc.opts...).Next()
+		if !errors.Is(err, tc.want) {
+			t.Errorf("decode %q = %v, want %v", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestDecoder_Release(t *testing.T) {
+	ll := listOf("+a\r\n+b\r\n")
+	d := NewDecoder(ll)
+	if _, err := d.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if ll.Buffered() != 8 {
+		t.Errorf("buffered before Release = %d, want 8", ll.Buffered())
+	}
+	d.Release()
+	if ll.Buffered() != 4 {
+		t.Errorf("buffered after Release = %d, want 4", ll.Buffered())
+	}
+	if v, _ := d.Next(); v.Text() != "b" {
+		t.Errorf("second value = %q, want b", v.Text())
+	}
+}
+
+func TestValue_Err(t *testing.T) {
+	v := decodeOne(t, "-WRONGTYPE bad\r\n")
+	var e Error
+	if !errors.As(v.Err(), &e) || string(e) != "WRONGTYPE bad" {
+		t.Errorf("Err() = %v", v.Err())
+	}
+	if decodeOne(t, "+OK\r\n").Err() != nil {
+		t.Error("Err() on simple string != nil")
+	}
+}
+
+// =============================================================================
+// Encoder
+// =============================================================================
+
+func TestEncoder(t *testing.T) {
+	buf := buffer.New(0)
+	e := NewEncoder(buf)
+	e.WriteCommand("SET", "k", "v")
+	e.WriteSimpleString("OK")
+	e.WriteError("ERR x")
+	e.WriteInt(-3)
+	e.WriteBulk([]byte("ab"))
+	e.WriteBulkInt(120)
+	e.WriteNull()
+	e.WriteNullBulk()
+	e.WriteNullArray()
+	e.WriteBool(true)
+	e.WriteDouble(math.Inf(1))
+	e.WriteDouble(0.25)
+	e.WriteMapHeader(1)
+	e.WriteSetHeader(0)
+	e.WritePushHeader(0)
+	e.WriteStreamHeader(Array)
+	e.WriteStreamEnd()
+
+	want := "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n+OK\r\n-ERR x\r\n:-3\r\n$2\r\nab\r\n$3\r\n120\r\n" +
+		"_\r\n$-1\r\n*-1\r\n#t\r\n,inf\r\n,0.25\r\n%1\r\n~0\r\n>0\r\n*?\r\n.\r\n"
+	if got := string(buf.Bytes()); got != want {
+		t.Errorf("encoded\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	in := "*4\r\n$3\r\nfoo\r\n%1\r\n+k\r\n,1.5\r\n~1\r\n#f\r\n$-1\r\n"
+	v := decodeOne(t, in)
+
+	buf := buffer.New(0)
+	if err := NewEncoder(buf).WriteValue(v); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf.Bytes()); got != in {
+		t.Errorf("round trip = %q, want %q", got, in)
+	}
+
+	if err := NewEncoder(buf).WriteValue(Value{Type: Map, Elems: make([]Value, 1)}); !errors.Is(err, ErrProtocol) {
+		t.Errorf("odd map = %v, want ErrProtocol", err)
+	}
+}
@@ -0,0 +1,23 @@
+package scan
+
+import "errors"
+
+var (
+	// ErrIncomplete is returned when the buffer does not yet hold a whole
+	// line, token or literal. Nothing is consumed; call again once more
+	// data has arrived.
+	ErrIncomplete = errors.New("scan: incomplete token")
+
+	// ErrSyntax is returned for input that does not match what was asked
+	// for: a literal mismatch, a bare LF under WithStrictCRLF, a malformed
+	// integer. Nothing is consumed.
+	ErrSyntax = errors.New("scan: syntax error")
+
+	// ErrRange is returned for integers that overflow their type. Nothing
+	// is consumed.
+	ErrRange = errors.New("scan: integer out of range")
+
+	// ErrTooLarge is returned when a line or token exceeds its configured
+	// limit before its terminator is found.
+	ErrTooLarge = errors.New("scan: token exceeds limit")
+)
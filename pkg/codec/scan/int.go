@@ -0,0 +1,44 @@
+package scan
+
+import "math"
+
+// ParseInt parses a signed decimal with an optional sign, without
+// allocating. ErrSyntax for anything else, ErrRange on overflow.
+func ParseInt(b []byte) (int64, error) {
+	neg := false
+	if len(b) > 0 && (b[0] == '-' || b[0] == '+') {
+		neg = b[0] == '-'
+		b = b[1:]
+	}
+	u, err := ParseUint(b)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case neg && u <= -math.MinInt64:
+		return -int64(u), nil
+	case !neg && u <= math.MaxInt64:
+		return int64(u), nil
+	}
+	return 0, ErrRange
+}
+
+// ParseUint parses an unsigned decimal without allocating. ErrSyntax for
+// anything but digits, ErrRange on overflow.
+func ParseUint(b []byte) (uint64, error) {
+	if len(b) == 0 {
+		return 0, ErrSyntax
+	}
+	var n uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, ErrSyntax
+		}
+		d := uint64(c - '0')
+		if n > (math.MaxUint64-d)/10 {
+			return 0, ErrRange
+		}
+		n = n*10 + d
+	}
+	return n, nil
+}
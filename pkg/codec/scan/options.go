@@ -0,0 +1,48 @@
+package scan
+
+// Defaults.
+const (
+	DefaultMaxLineLen  = 64 << 10
+	DefaultMaxTokenLen = 64 << 10
+)
+
+// Config holds the Scanner limits. Exceeding one fails with ErrTooLarge.
+type Config struct {
+	MaxLineLen  int  // bytes in a line, terminator excluded
+	MaxTokenLen int  // bytes in a token, delimiter excluded
+	StrictCRLF  bool // reject lines ended by a bare LF
+}
+
+// Option configures a Scanner.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{
+		MaxLineLen:  DefaultMaxLineLen,
+		MaxTokenLen: DefaultMaxTokenLen,
+	}
+}
+
+// WithMaxLineLen sets Config.MaxLineLen.
+func WithMaxLineLen(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxLineLen = n
+		}
+	}
+}
+
+// WithMaxTokenLen sets Config.MaxTokenLen.
+func WithMaxTokenLen(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxTokenLen = n
+		}
+	}
+}
+
+// WithStrictCRLF makes ReadLine fail with ErrSyntax on lines ended by a
+// bare LF, as RESP and HTTP/1.1 header parsing require.
+func WithStrictCRLF() Option {
+	return func(c *Config) { c.StrictCRLF = true }
+}
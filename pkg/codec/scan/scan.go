@@ -0,0 +1,221 @@
+// Package scan reads lines, delimited tokens, literals and integers from
+// segmented buffers for text protocols. Results point into the buffer
+// instead of being copied, unless they straddle two segments.
+//
+// Every read returns ErrIncomplete, consuming nothing, until the whole line
+// or token is buffered, so a Scanner can be called again after each network
+// read without tracking partial input.
+//
+// A Scanner consumes each result on the next read, which suits protocols
+// read one line or token at a time. The resp and http1 codecs do not use
+// it: they consume nothing until a whole message is buffered. All three
+// walk the buffer with the same segment cursor and accept the same Source.
+package scan
+
+import (
+	"bytes"
+
+	"github.com/huynhanx03/go-common/pkg/codec/internal/segment"
+)
+
+// Source is a segmented buffer the Scanner reads from without copying.
+// *buffer.LinkedListBuffer and *buffer.ElasticBuffer implement it; wrap a
+// *buffer.RingBuffer or *buffer.ElasticRing with FromRing.
+type Source = segment.Source
+
+// RingSource is a buffer that peeks as two slices, like
+// *buffer.RingBuffer and *buffer.ElasticRing.
+type RingSource interface {
+	Peek(n int) (head, tail []byte)
+	Discard(n int) (int, error)
+}
+
+// FromRing adapts r to Source. Peeking does not allocate.
+func FromRing(r RingSource) Source {
+	return &ringSource{r: r}
+}
+
+type ringSource struct {
+	r    RingSource
+	segs [2][]byte
+}
+
+func (s *ringSource) Peek(n int) ([][]byte, error) {
+	s.segs[0], s.segs[1] = s.r.Peek(n)
+	return s.segs[:], nil
+}
+
+func (s *ringSource) Discard(n int) (int, error) {
+	return s.r.Discard(n)
+}
+
+// Scanner reads protocol text from a Source.
+// It is not safe for concurrent use.
+type Scanner struct {
+	src    Source
+	config Config
+
+	segs    [][]byte       // data peeked by the current call
+	cur     segment.Cursor // walks segs from their start
+	pending int            // bytes of the last result, consumed on the next call
+	arena   segment.Arena  // copies of results that straddle segments
+}
+
+// NewScanner creates a scanner reading from src.
+func NewScanner(src Source, opts ...Option) *Scanner {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &Scanner{src: src, config: cfg}
+}
+
+// ReadLine returns the next line without its LF or CRLF terminator. The
+// result stays valid until the next call or Release.
+func (s *Scanner) ReadLine() ([]byte, error) {
+	if err := s.begin(); err != nil {
+		return nil, err
+	}
+	// One extra byte for the CR of a line exactly MaxLineLen long.
+	i, err := s.index(s.config.MaxLineLen+1, func(b []byte) int {
+		return bytes.IndexByte(b, '\n')
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	line := s.span(i)
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	} else if s.config.StrictCRLF {
+		return nil, ErrSyntax
+	}
+	if len(line) > s.config.MaxLineLen {
+		return nil, ErrTooLarge
+	}
+	s.pending = i + 1
+	return line, nil
+}
+
+// ReadToken returns the bytes up to the first of delims and the delimiter
+// that ended them, consuming both. Consecutive delimiters yield empty
+// tokens. The result stays valid until the next call or Release.
+func (s *Scanner) ReadToken(delims string) ([]byte, byte, error) {
+	if delims == "" {
+		return nil, 0, ErrSyntax
+	}
+	if err := s.begin(); err != nil {
+		return nil, 0, err
+	}
+	i, err := s.index(s.config.MaxTokenLen, func(b []byte) int {
+		if len(delims) == 1 {
+			return bytes.IndexByte(b, delims[0])
+		}
+		return bytes.IndexAny(b, delims)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tok := s.span(i + 1)
+	s.pending = i + 1
+	return tok[:i], tok[i], nil
+}
+
+// ExpectLiteral consumes lit if the buffer starts with it. ErrSyntax,
+// consuming nothing, as soon as a buffered byte differs; ErrIncomplete if
+// the buffer holds a proper prefix of lit.
+func (s *Scanner) ExpectLiteral(lit string) error {
+	if err := s.begin(); err != nil || lit == "" {
+		return err
+	}
+	matched := 0
+	for _, seg := range s.segs {
+		n := min(len(seg), len(lit)-matched)
+		if string(seg[:n]) != lit[matched:matched+n] {
+			return ErrSyntax
+		}
+		matched += n
+		if matched == len(lit) {
+			s.pending = matched
+			return nil
+		}
+	}
+	return ErrIncomplete
+}
+
+// ReadInt reads a token as ReadToken does and parses it as a signed
+// decimal. Nothing is consumed when it is not one.
+func (s *Scanner) ReadInt(delims string) (int64, error) {
+	tok, _, err := s.ReadToken(delims)
+	if err != nil {
+		return 0, err
+	}
+	n, err := ParseInt(tok)
+	if err != nil {
+		s.pending = 0
+	}
+	return n, err
+}
+
+// ReadUint is ReadInt for unsigned decimals.
+func (s *Scanner) ReadUint(delims string) (uint64, error) {
+	tok, _, err := s.ReadToken(delims)
+	if err != nil {
+		return 0, err
+	}
+	n, err := ParseUint(tok)
+	if err != nil {
+		s.pending = 0
+	}
+	return n, err
+}
+
+// Release consumes the last result now, handing its memory back to the
+// source, instead of on the next call.
+func (s *Scanner) Release() error {
+	if s.pending == 0 {
+		return nil
+	}
+	n := s.pending
+	s.pending = 0
+	_, err := s.src.Discard(n)
+	return err
+}
+
+// begin consumes the previous result and peeks the buffered data.
+func (s *Scanner) begin() error {
+	if err := s.Release(); err != nil {
+		return err
+	}
+	s.arena.Reset()
+
+	segs, err := s.src.Peek(0)
+	if err != nil {
+		return err
+	}
+	s.segs = segs
+	s.cur.Reset(segs, &s.arena)
+	return nil
+}
+
+// index returns the offset of the first match of find across the peeked
+// segments. ErrTooLarge once limit bytes precede it, ErrIncomplete if
+// there is none yet.
+func (s *Scanner) index(limit int, find func([]byte) int) (int, error) {
+	i, ok := s.cur.Index(limit, find)
+	switch {
+	case i > limit:
+		return 0, ErrTooLarge
+	case !ok:
+		return 0, ErrIncomplete
+	}
+	return i, nil
+}
+
+// span returns the first n peeked bytes contiguously: aliasing the segment
+// holding them, or an arena copy when they straddle segments.
+func (s *Scanner) span(n int) []byte {
+	b, _ := s.cur.Span(n)
+	return b
+}
@@ -0,0 +1,225 @@
+package scan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+// segmented returns a linked list holding parts as separate nodes, so
+// reads straddle segment boundaries.
+func segmented(parts ...string) *buffer.LinkedListBuffer {
+	ll := &buffer.LinkedListBuffer{}
+	for _, p := range parts {
+		ll.PushBack([]byte(p))
+	}
+	return ll
+}
+
+// ============================================================================
+// ReadLine
+// ============================================================================
+
+func TestReadLine(t *testing.T) {
+	s := NewScanner(segmented("GET / HT", "TP/1.1\r", "\nHost: x\n", "tail"))
+
+	for _, want := range []string{"GET / HTTP/1.1", "Host: x"} {
+		line, err := s.ReadLine()
+		if err != nil {
+			t.Fatalf("ReadLine: %v", err)
+		}
+		if string(line) != want {
+			t.Errorf("ReadLine = %q, want %q", line, want)
+		}
+	}
+	if _, err := s.ReadLine(); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("ReadLine err = %v, want ErrIncomplete", err)
+	}
+}
+
+func TestReadLine_Incremental(t *testing.T) {
+	ll := segmented("PI")
+	s := NewScanner(ll)
+
+	if _, err := s.ReadLine(); !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("ReadLine err = %v, want ErrIncomplete", err)
+	}
+	ll.PushBack([]byte("NG\r\n"))
+	line, err := s.ReadLine()
+	if err != nil || string(line) != "PING" {
+		t.Fatalf("ReadLine = %q, %v, want PING", line, err)
+	}
+	if err := s.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if ll.Buffered() != 0 {
+		t.Errorf("Buffered = %d after Release, want 0", ll.Buffered())
+	}
+}
+
+func TestReadLine_StrictCRLF(t *testing.T) {
+	s := NewScanner(segmented("a\n"), WithStrictCRLF())
+	if _, err := s.ReadLine(); !errors.Is(err, ErrSyntax) {
+		t.Errorf("ReadLine err = %v, want ErrSyntax", err)
+	}
+}
+
+func TestReadLine_TooLarge(t *testing.T) {
+	s := NewScanner(segmented("abcd", "ef"), WithMaxLineLen(4))
+	if _, err := s.ReadLine(); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("unterminated ReadLine err = %v, want ErrTooLarge", err)
+	}
+
+	// Exactly at the limit, CRLF included.
+	s = NewScanner(segmented("abcd\r\n"), WithMaxLineLen(4))
+	if line, err := s.ReadLine(); err != nil || string(line) != "abcd" {
+		t.Errorf("ReadLine = %q, %v, want abcd", line, err)
+	}
+}
+
+// ============================================================================
+// ReadToken and ExpectLiteral
+// ============================================================================
+
+func TestReadToken(t *testing.T) {
+	s := NewScanner(segmented("key=va", "lue;;x"))
+
+	tests := []struct {
+		tok   string
+		delim byte
+	}{
+		{"key", '='},
+		{"value", ';'},
+		{"", ';'},
+	}
+	for _, tt := range tests {
+		tok, delim, err := s.ReadToken("=;")
+		if err != nil {
+			t.Fatalf("ReadToken: %v", err)
+		}
+		if string(tok) != tt.tok || delim != tt.delim {
+			t.Errorf("ReadToken = %q, %q, want %q, %q", tok, delim, tt.tok, tt.delim)
+		}
+	}
+	if _, _, err := s.ReadToken("=;"); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("ReadToken err = %v, want ErrIncomplete", err)
+	}
+	if _, _, err := s.ReadToken(""); !errors.Is(err, ErrSyntax) {
+		t.Errorf("ReadToken(\"\") err = %v, want ErrSyntax", err)
+	}
+}
+
+func TestExpectLiteral(t *testing.T) {
+	ll := segmented("HT", "TP/1.1 200")
+	s := NewScanner(ll)
+
+	if err := s.ExpectLiteral("HTTP/2"); !errors.Is(err, ErrSyntax) {
+		t.Errorf("mismatch err = %v, want ErrSyntax", err)
+	}
+	if err := s.ExpectLiteral("HTTP/1.1 "); err != nil {
+		t.Fatalf("ExpectLiteral: %v", err)
+	}
+	if err := s.ExpectLiteral("2000"); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("prefix err = %v, want ErrIncomplete", err)
+	}
+	if err := s.ExpectLiteral("200"); err != nil {
+		t.Errorf("ExpectLiteral: %v", err)
+	}
+}
+
+// ============================================================================
+// Integers
+// ============================================================================
+
+func TestReadInt(t *testing.T) {
+	s := NewScanner(segmented("-12", "3\r\n+7 x ", "18446744073709551615 "))
+
+	if n, err := s.ReadInt("\r"); err != nil || n != -123 {
+		t.Errorf("ReadInt = %d, %v, want -123", n, err)
+	}
+	if err := s.ExpectLiteral("\n"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.ReadInt(" "); err != nil || n != 7 {
+		t.Errorf("ReadInt = %d, %v, want 7", n, err)
+	}
+
+	// A malformed integer is left in the buffer.
+	if _, err := s.ReadInt(" "); !errors.Is(err, ErrSyntax) {
+		t.Errorf("ReadInt err = %v, want ErrSyntax", err)
+	}
+	if tok, _, _ := s.ReadToken(" "); string(tok) != "x" {
+		t.Errorf("token after failed ReadInt = %q, want x", tok)
+	}
+
+	if n, err := s.ReadUint(" "); err != nil || n != 1<<64-1 {
+		t.Errorf("ReadUint = %d, %v, want MaxUint64", n, err)
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		err  error
+	}{
+		{"0", 0, nil},
+		{"+42", 42, nil},
+		{"-9223372036854775808", -1 << 63, nil},
+		{"9223372036854775807", 1<<63 - 1, nil},
+		{"9223372036854775808", 0, ErrRange},
+		{"99999999999999999999", 0, ErrRange},
+		{"", 0, ErrSyntax},
+		{"-", 0, ErrSyntax},
+		{"1a", 0, ErrSyntax},
+		{"--1", 0, ErrSyntax},
+	}
+	for _, tt := range tests {
+		got, err := ParseInt([]byte(tt.in))
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseInt(%q) = %d, %v, want %d, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+// ============================================================================
+// Ring sources
+// ============================================================================
+
+func TestFromRing(t *testing.T) {
+	// Eight bytes, written past the end so the data wraps.
+	rb := buffer.NewRingFrom(make([]byte, 8), false)
+	_, _ = rb.Write([]byte("xxxxx"))
+	_, _ = rb.Discard(5)
+	_, _ = rb.Write([]byte("12\r\n34 "))
+
+	s := NewScanner(FromRing(rb))
+	if line, err := s.ReadLine(); err != nil || string(line) != "12" {
+		t.Errorf("ReadLine = %q, %v, want 12", line, err)
+	}
+	if n, err := s.ReadInt(" "); err != nil || n != 34 {
+		t.Errorf("ReadInt = %d, %v, want 34", n, err)
+	}
+	_ = s.Release()
+	if rb.Buffered() != 0 {
+		t.Errorf("Buffered = %d, want 0", rb.Buffered())
+	}
+}
+
+func TestReadInt_NoAllocs(t *testing.T) {
+	rb := buffer.NewRing(64)
+	s := NewScanner(FromRing(rb))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = rb.Write([]byte("12345\r\n"))
+		if _, err := s.ReadInt("\r"); err != nil {
+			t.Fatal(err)
+		}
+		_ = s.ExpectLiteral("\n")
+		_ = s.Release()
+	})
+	if allocs != 0 {
+		t.Errorf("allocs = %v, want 0", allocs)
+	}
+}
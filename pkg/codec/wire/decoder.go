@@ -0,0 +1,160 @@
+package wire
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Decoder reads wire values from a byte slice without copying: Bytes
+// returns subslices of the input. The first error sticks: later reads
+// return zero values and Err reports it, so a record can be decoded field
+// by field with a single check at the end:
+//
+//	d := wire.NewDecoder(p)
+//	id := d.Uvarint()
+//	payload := d.Bytes()
+//	if err := d.Err(); err != nil { ... }
+//
+// It is not safe for concurrent use.
+type Decoder struct {
+	data []byte
+	off  int
+	err  error
+}
+
+// NewDecoder creates a decoder reading p.
+func NewDecoder(p []byte) *Decoder {
+	return &Decoder{data: p}
+}
+
+// Reset makes the decoder read p from its start and clears its error.
+func (d *Decoder) Reset(p []byte) {
+	*d = Decoder{data: p}
+}
+
+// Err returns the first error met, wrapping ErrTruncated, ErrOverflow or
+// ErrInvalidTag with the offset it happened at.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Offset returns how many bytes have been read.
+func (d *Decoder) Offset() int {
+	return d.off
+}
+
+// Len returns how many bytes are left.
+func (d *Decoder) Len() int {
+	return len(d.data) - d.off
+}
+
+func (d *Decoder) fail(err error) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w at offset %d", err, d.off)
+	}
+}
+
+// next returns the next n bytes, or nil after failing if fewer are left.
+func (d *Decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > d.Len() {
+		d.fail(ErrTruncated)
+		return nil
+	}
+	p := d.data[d.off : d.off+n : d.off+n]
+	d.off += n
+	return p
+}
+
+// Uvarint reads a varint.
+func (d *Decoder) Uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data[d.off:])
+	switch {
+	case n == 0:
+		d.fail(ErrTruncated)
+		return 0
+	case n < 0:
+		d.fail(ErrOverflow)
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+// Varint reads a zigzag varint.
+func (d *Decoder) Varint() int64 {
+	return UnZigZag(d.Uvarint())
+}
+
+// Fixed32 reads 4 little-endian bytes.
+func (d *Decoder) Fixed32() uint32 {
+	if p := d.next(4); p != nil {
+		return binary.LittleEndian.Uint32(p)
+	}
+	return 0
+}
+
+// Fixed64 reads 8 little-endian bytes.
+func (d *Decoder) Fixed64() uint64 {
+	if p := d.next(8); p != nil {
+		return binary.LittleEndian.Uint64(p)
+	}
+	return 0
+}
+
+// Bytes reads a length-delimited value. The result aliases the input and
+// is capped at its length, so appending to it does not overwrite what
+// follows.
+func (d *Decoder) Bytes() []byte {
+	n := d.Uvarint()
+	if d.err != nil {
+		return nil
+	}
+	if n > uint64(d.Len()) {
+		d.fail(ErrTruncated)
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// Tag reads a field tag. It returns 0 once the decoder has failed.
+func (d *Decoder) Tag() (num int, t Type) {
+	start := d.off
+	v := d.Uvarint()
+	if d.err != nil {
+		return 0, 0
+	}
+	num, t = int(v>>3), Type(v&7)
+	if num < 1 || v>>3 > maxFieldNum || !t.known() {
+		d.off = start
+		d.fail(ErrInvalidTag)
+		return 0, 0
+	}
+	return num, t
+}
+
+// Skip reads past a value of type t, such as a field the reader does not
+// know.
+func (d *Decoder) Skip(t Type) {
+	switch t {
+	case Varint:
+		d.Uvarint()
+	case Fixed64:
+		d.next(8)
+	case Bytes:
+		d.Bytes()
+	case Fixed32:
+		d.next(4)
+	default:
+		d.fail(ErrInvalidTag)
+	}
+}
+
+func (t Type) known() bool {
+	return t == Varint || t == Fixed64 || t == Bytes || t == Fixed32
+}
@@ -0,0 +1,92 @@
+package wire
+
+import (
+	"encoding/binary"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+// Encoder appends wire values to a *buffer.Buffer, encoding each straight
+// into space allocated from the buffer. Fields are a tag followed by a
+// value of the tag's type:
+//
+//	enc.Tag(1, wire.Varint)
+//	enc.Uvarint(id)
+//	enc.Tag(2, wire.Bytes)
+//	enc.Bytes(payload)
+//
+// It is not safe for concurrent use.
+type Encoder struct {
+	buf *buffer.Buffer
+}
+
+// NewEncoder creates an encoder appending to buf.
+func NewEncoder(buf *buffer.Buffer) *Encoder {
+	return &Encoder{buf: buf}
+}
+
+// Buffer returns the buffer the encoder appends to.
+func (e *Encoder) Buffer() *buffer.Buffer {
+	return e.buf
+}
+
+// Reset makes the encoder append to buf.
+func (e *Encoder) Reset(buf *buffer.Buffer) {
+	e.buf = buf
+}
+
+// Uvarint writes v as a varint.
+func (e *Encoder) Uvarint(v uint64) {
+	binary.PutUvarint(e.buf.Allocate(UvarintSize(v)), v)
+}
+
+// Varint writes v as a zigzag varint.
+func (e *Encoder) Varint(v int64) {
+	e.Uvarint(ZigZag(v))
+}
+
+// Fixed32 writes v as 4 little-endian bytes.
+func (e *Encoder) Fixed32(v uint32) {
+	binary.LittleEndian.PutUint32(e.buf.Allocate(4), v)
+}
+
+// Fixed64 writes v as 8 little-endian bytes.
+func (e *Encoder) Fixed64(v uint64) {
+	binary.LittleEndian.PutUint64(e.buf.Allocate(8), v)
+}
+
+// Bytes writes p prefixed with its length.
+func (e *Encoder) Bytes(p []byte) {
+	e.Uvarint(uint64(len(p)))
+	e.buf.Write(p)
+}
+
+// String writes s prefixed with its length.
+func (e *Encoder) String(s string) {
+	e.Uvarint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+// Tag writes the tag of field num with wire type t. num must be in
+// [1, 1<<29-1].
+func (e *Encoder) Tag(num int, t Type) {
+	e.Uvarint(tag(num, t))
+}
+
+// BeginDelimited starts a length-delimited value whose size is not known
+// upfront, such as a nested message, and returns the mark EndDelimited
+// takes. Write the value's contents in between.
+func (e *Encoder) BeginDelimited() int {
+	return e.buf.LenNoPadding()
+}
+
+// EndDelimited ends the value started at mark, inserting its length in
+// front of it. The contents are moved by the length's size, a few bytes.
+func (e *Encoder) EndDelimited(mark int) {
+	n := e.buf.LenNoPadding() - mark
+	size := UvarintSize(uint64(n))
+	e.buf.Allocate(size)
+	b := e.buf.Bytes()
+	copy(b[mark+size:], b[mark:mark+n])
+	binary.PutUvarint(b[mark:], uint64(n))
+}
@@ -0,0 +1,15 @@
+package wire
+
+import "errors"
+
+var (
+	// ErrTruncated is reported when the input ends inside a value.
+	ErrTruncated = errors.New("wire: truncated value")
+
+	// ErrOverflow is reported for a varint longer than 64 bits.
+	ErrOverflow = errors.New("wire: varint overflows 64 bits")
+
+	// ErrInvalidTag is reported for a field tag with field number 0 or
+	// an unknown wire type.
+	ErrInvalidTag = errors.New("wire: invalid field tag")
+)
@@ -0,0 +1,97 @@
+// Package wire encodes and decodes the primitives of protobuf-style binary
+// formats without depending on protobuf: varints, zigzag varints,
+// little-endian fixed32/64, length-delimited bytes and field tags. The
+// encodings match protobuf's, so custom record formats built from them can
+// be read by protobuf tooling when laid out as messages.
+//
+// The Append functions work on byte slices, Encoder appends to a
+// *buffer.Buffer in place and Decoder reads a byte slice without copying.
+package wire
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Type is the wire type of a field, the low three bits of its tag.
+type Type uint8
+
+// Wire types, as protobuf numbers them.
+const (
+	Varint  Type = 0
+	Fixed64 Type = 1
+	Bytes   Type = 2
+	Fixed32 Type = 5
+)
+
+// MaxVarintLen is the longest encoding of a 64-bit varint.
+const MaxVarintLen = binary.MaxVarintLen64
+
+// maxFieldNum is the largest field number a tag can carry.
+const maxFieldNum = 1<<29 - 1
+
+// ZigZag maps signed integers to unsigned ones so that small magnitudes of
+// either sign encode as short varints: 0, -1, 1, -2 become 0, 1, 2, 3.
+func ZigZag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// UnZigZag reverses ZigZag.
+func UnZigZag(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+// UvarintSize returns the encoded length of v as a varint.
+func UvarintSize(v uint64) int {
+	return (bits.Len64(v|1) + 6) / 7
+}
+
+// VarintSize returns the encoded length of v as a zigzag varint.
+func VarintSize(v int64) int {
+	return UvarintSize(ZigZag(v))
+}
+
+// BytesSize returns the encoded length of an n-byte length-delimited value.
+func BytesSize(n int) int {
+	return UvarintSize(uint64(n)) + n
+}
+
+// AppendUvarint appends v as a varint.
+func AppendUvarint(dst []byte, v uint64) []byte {
+	return binary.AppendUvarint(dst, v)
+}
+
+// AppendVarint appends v as a zigzag varint, the encoding of
+// binary.PutVarint and protobuf's sint64.
+func AppendVarint(dst []byte, v int64) []byte {
+	return binary.AppendUvarint(dst, ZigZag(v))
+}
+
+// AppendFixed32 appends v as 4 little-endian bytes.
+func AppendFixed32(dst []byte, v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(dst, v)
+}
+
+// AppendFixed64 appends v as 8 little-endian bytes.
+func AppendFixed64(dst []byte, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(dst, v)
+}
+
+// AppendBytes appends p prefixed with its length as a varint.
+func AppendBytes(dst, p []byte) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(p)))
+	return append(dst, p...)
+}
+
+// AppendTag appends the tag of field num with wire type t. num must be in
+// [1, 1<<29-1].
+func AppendTag(dst []byte, num int, t Type) []byte {
+	return binary.AppendUvarint(dst, tag(num, t))
+}
+
+func tag(num int, t Type) uint64 {
+	if num < 1 || num > maxFieldNum {
+		panic("wire: field number out of range")
+	}
+	return uint64(num)<<3 | uint64(t)
+}
@@ -0,0 +1,247 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+var varintCases = []int64{0, 1, -1, 63, -64, 64, 1 << 20, -1 << 40, math.MaxInt64, math.MinInt64}
+
+// =============================================================================
+// Primitive Tests
+// =============================================================================
+
+func TestZigZag(t *testing.T) {
+	for i, want := range []uint64{0, 1, 2, 3, 4} {
+		if got := ZigZag([]int64{0, -1, 1, -2, 2}[i]); got != want {
+			t.Errorf("ZigZag #%d = %d, want %d", i, got, want)
+		}
+	}
+	for _, v := range varintCases {
+		if got := UnZigZag(ZigZag(v)); got != v {
+			t.Errorf("UnZigZag(ZigZag(%d)) = %d", v, got)
+		}
+	}
+}
+
+func TestSizesMatchEncoding(t *testing.T) {
+	var tmp [MaxVarintLen]byte
+	for _, v := range varintCases {
+		if got, want := VarintSize(v), binary.PutVarint(tmp[:], v); got != want {
+			t.Errorf("VarintSize(%d) = %d, want %d", v, got, want)
+		}
+		u := uint64(v)
+		if got, want := UvarintSize(u), binary.PutUvarint(tmp[:], u); got != want {
+			t.Errorf("UvarintSize(%d) = %d, want %d", u, got, want)
+		}
+	}
+	if got := BytesSize(200); got != 202 {
+		t.Errorf("BytesSize(200) = %d, want 202", got)
+	}
+}
+
+func TestAppendVarintMatchesBinary(t *testing.T) {
+	var tmp [MaxVarintLen]byte
+	for _, v := range varintCases {
+		n := binary.PutVarint(tmp[:], v)
+		if got := AppendVarint(nil, v); !bytes.Equal(got, tmp[:n]) {
+			t.Errorf("AppendVarint(%d) = %x, want %x", v, got, tmp[:n])
+		}
+	}
+}
+
+// =============================================================================
+// Encoder / Decoder Tests
+// =============================================================================
+
+func TestEncoderRoundTrip(t *testing.T) {
+	buf := buffer.New(0)
+	enc := NewEncoder(buf)
+	for _, v := range varintCases {
+		enc.Varint(v)
+		enc.Uvarint(uint64(v))
+	}
+	enc.Fixed32(0xdeadbeef)
+	enc.Fixed64(math.MaxUint64 - 1)
+	enc.Bytes([]byte("payload"))
+	enc.String("")
+	enc.Tag(maxFieldNum, Fixed32)
+
+	// The slice functions produce the same bytes.
+	var want []byte
+	for _, v := range varintCases {
+		want = AppendVarint(want, v)
+		want = AppendUvarint(want, uint64(v))
+	}
+	want = AppendFixed32(want, 0xdeadbeef)
+	want = AppendFixed64(want, math.MaxUint64-1)
+	want = AppendBytes(want, []byte("payload"))
+	want = AppendBytes(want, nil)
+	want = AppendTag(want, maxFieldNum, Fixed32)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("Encoder wrote %x\nAppend* wrote %x", buf.Bytes(), want)
+	}
+
+	d := NewDecoder(buf.Bytes())
+	for _, v := range varintCases {
+		if got := d.Varint(); got != v {
+			t.Errorf("Varint = %d, want %d", got, v)
+		}
+		if got := d.Uvarint(); got != uint64(v) {
+			t.Errorf("Uvarint = %d, want %d", got, uint64(v))
+		}
+	}
+	if got := d.Fixed32(); got != 0xdeadbeef {
+		t.Errorf("Fixed32 = %x", got)
+	}
+	if got := d.Fixed64(); got != math.MaxUint64-1 {
+		t.Errorf("Fixed64 = %x", got)
+	}
+	if got := d.Bytes(); string(got) != "payload" || cap(got) != len(got) {
+		t.Errorf("Bytes = %q (cap %d)", got, cap(got))
+	}
+	if got := d.Bytes(); len(got) != 0 {
+		t.Errorf("empty Bytes = %q", got)
+	}
+	if num, typ := d.Tag(); num != maxFieldNum || typ != Fixed32 {
+		t.Errorf("Tag = %d, %d", num, typ)
+	}
+	if d.Err() != nil || d.Len() != 0 || d.Offset() != len(want) {
+		t.Errorf("Err = %v, Len = %d, Offset = %d", d.Err(), d.Len(), d.Offset())
+	}
+}
+
+func TestEncoderDelimited(t *testing.T) {
+	buf := buffer.New(0)
+	enc := NewEncoder(buf)
+	enc.Tag(1, Bytes)
+	outer := enc.BeginDelimited()
+	enc.Tag(1, Bytes)
+	enc.Bytes(bytes.Repeat([]byte("x"), 200)) // inner length needs two bytes
+	enc.Tag(2, Varint)
+	enc.Uvarint(7)
+	enc.EndDelimited(outer)
+	enc.Tag(2, Varint)
+	enc.Uvarint(9)
+
+	d := NewDecoder(buf.Bytes())
+	d.Tag()
+	inner := NewDecoder(d.Bytes())
+	inner.Tag()
+	if got := inner.Bytes(); len(got) != 200 {
+		t.Errorf("nested Bytes = %d bytes, want 200", len(got))
+	}
+	inner.Tag()
+	if got := inner.Uvarint(); got != 7 || inner.Len() != 0 {
+		t.Errorf("nested Uvarint = %d, %d left", got, inner.Len())
+	}
+	d.Tag()
+	if got := d.Uvarint(); got != 9 || d.Err() != nil {
+		t.Errorf("field after nested = %d, %v", got, d.Err())
+	}
+}
+
+func TestDecoderSkip(t *testing.T) {
+	var p []byte
+	p = AppendTag(p, 1, Varint)
+	p = AppendUvarint(p, 300)
+	p = AppendTag(p, 2, Fixed64)
+	p = AppendFixed64(p, 1)
+	p = AppendTag(p, 3, Bytes)
+	p = AppendBytes(p, []byte("skip me"))
+	p = AppendTag(p, 4, Fixed32)
+	p = AppendFixed32(p, 1)
+	p = AppendTag(p, 5, Varint)
+	p = AppendUvarint(p, 42)
+
+	d := NewDecoder(p)
+	for {
+		num, typ := d.Tag()
+		if d.Err() != nil {
+			t.Fatal(d.Err())
+		}
+		if num == 5 {
+			if got := d.Uvarint(); got != 42 {
+				t.Errorf("field 5 = %d, want 42", got)
+			}
+			break
+		}
+		d.Skip(typ)
+	}
+}
+
+// =============================================================================
+// Error Tests
+// =============================================================================
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		read func(d *Decoder)
+		want error
+	}{
+		{"truncated varint", []byte{0x80}, func(d *Decoder) { d.Uvarint() }, ErrTruncated},
+		{"overflow", bytes.Repeat([]byte{0xff}, 11), func(d *Decoder) { d.Uvarint() }, ErrOverflow},
+		{"truncated fixed64", []byte{1, 2, 3}, func(d *Decoder) { d.Fixed64() }, ErrTruncated},
+		{"truncated bytes", []byte{5, 'a'}, func(d *Decoder) { d.Bytes() }, ErrTruncated},
+		{"huge length", AppendUvarint(nil, math.MaxUint64), func(d *Decoder) { d.Bytes() }, ErrTruncated},
+		{"field zero", []byte{0x00}, func(d *Decoder) { d.Tag() }, ErrInvalidTag},
+		{"unknown type", []byte{0x0b}, func(d *Decoder) { d.Tag() }, ErrInvalidTag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(tt.data)
+			tt.read(d)
+			if !errors.Is(d.Err(), tt.want) {
+				t.Errorf("Err = %v, want %v", d.Err(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoderStickyError(t *testing.T) {
+	d := NewDecoder([]byte{0x80})
+	d.Uvarint()
+	first := d.Err()
+	if v := d.Fixed32(); v != 0 || d.Err() != first {
+		t.Errorf("after failure Fixed32 = %d, Err = %v; want 0, %v", v, d.Err(), first)
+	}
+	d.Reset([]byte{1})
+	if v := d.Uvarint(); v != 1 || d.Err() != nil {
+		t.Errorf("after Reset Uvarint = %d, Err = %v", v, d.Err())
+	}
+}
+
+func TestEncoderZeroAlloc(t *testing.T) {
+	buf := buffer.New(1 << 12)
+	enc := NewEncoder(buf)
+	payload := []byte("payload")
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		enc.Tag(1, Varint)
+		enc.Varint(-12345)
+		enc.Tag(2, Bytes)
+		m := enc.BeginDelimited()
+		enc.Fixed64(1)
+		enc.Bytes(payload)
+		enc.EndDelimited(m)
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per record = %v, want 0", allocs)
+	}
+}
+
+func TestTagPanicsOutOfRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("AppendTag(0) did not panic")
+		}
+	}()
+	AppendTag(nil, 0, Varint)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"bytes"
+	"time"
+)
+
+// BytesAdapter exposes a LocalCache of []byte values through the API of
+// bigcache (Get, Set, Delete, Reset, Len, Close with error results), so
+// code moving off bigcache can swap the cache before its call sites.
+// Values are copied in and out, as bigcache does, so callers may reuse
+// their buffers and mutate what they get.
+type BytesAdapter struct {
+	c   LocalCache[string, []byte]
+	ttl time.Duration
+}
+
+// NewBytesAdapter wraps c. Entries are set with ttl, bigcache's LifeWindow,
+// jittered like the Fetch helpers; ttl <= 0 sets them without expiry.
+func NewBytesAdapter(c LocalCache[string, []byte], ttl time.Duration) *BytesAdapter {
+	return &BytesAdapter{c: c, ttl: ttl}
+}
+
+// Get returns a copy of the value stored under key, or ErrEntryNotFound.
+func (a *BytesAdapter) Get(key string) ([]byte, error) {
+	v, ok := a.c.Get(key)
+	if !ok {
+		return nil, ErrEntryNotFound
+	}
+	return bytes.Clone(v), nil
+}
+
+// Set stores a copy of entry under key. It returns ErrNotStored if the
+// cache dropped or refused the entry.
+func (a *BytesAdapter) Set(key string, entry []byte) error {
+	entry = bytes.Clone(entry)
+	var ok bool
+	if a.ttl > 0 {
+		ok = a.c.SetWithTTL(key, entry, jitterTTL(a.ttl))
+	} else {
+		ok = a.c.Set(key, entry)
+	}
+	if !ok {
+		return ErrNotStored
+	}
+	return nil
+}
+
+// Delete removes key. Unlike bigcache it does not report a missing key:
+// LocalCache cannot tell.
+func (a *BytesAdapter) Delete(key string) error {
+	a.c.Delete(key)
+	return nil
+}
+
+// Reset removes every entry.
+func (a *BytesAdapter) Reset() error {
+	a.c.Clear()
+	return nil
+}
+
+// Len returns the number of entries, as counted by Stats.
+func (a *BytesAdapter) Len() int {
+	return int(a.c.Stats().KeyCount)
+}
+
+// Close closes the cache.
+func (a *BytesAdapter) Close() error {
+	a.c.Close()
+	return nil
+}
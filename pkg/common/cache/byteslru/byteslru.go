@@ -0,0 +1,304 @@
+// Package byteslru is an LRU cache for raw []byte values that keeps keys and
+// values in pooled slabs instead of one heap allocation per entry.
+//
+// Slabs come from the byteslice pool and are cut into fixed-size chunks by
+// size class, as in memcached. The index and the LRU links are pointer-free
+// (hash to entry number, entry numbers for links), so the GC scans a
+// handful of slab headers however many entries the cache holds. Values are
+// copied in on Set and out on Get, into a buffer the caller supplies.
+//
+// Each size class has its own LRU order and keeps the slabs it was given
+// until Clear or Close: a cache whose value sizes shift over time may
+// evict recent entries of a size whose class owns few slabs.
+package byteslru
+
+import (
+	"sync"
+
+	"github.com/huynhanx03/go-common/pkg/common/cache"
+	"github.com/huynhanx03/go-common/pkg/pool/byteslice"
+	"github.com/huynhanx03/go-common/pkg/runtime"
+)
+
+// entry is a cached item: its key hash, where its key and value live and
+// its LRU neighbours within its class.
+type entry struct {
+	hash   uint64
+	chunk  chunkRef
+	class  int32
+	keyLen int32
+	valLen int32
+	prev   int32 // towards the most recently used entry
+	next   int32 // towards the least recently used entry
+}
+
+// Cache is a size-bounded LRU cache of []byte values keyed by string.
+// Keys are identified by a 64-bit hash; storing a key whose hash collides
+// with a resident one replaces it. It is safe for concurrent use.
+type Cache struct {
+	mu  sync.Mutex
+	cfg Config
+
+	classes     []sizeClass
+	slabs       [][]byte
+	index       map[uint64]int32
+	entries     []entry
+	freeEntries []int32
+	closed      bool
+
+	used      int64 // key and value bytes of resident entries
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// New creates a cache with the given options applied to the defaults.
+func New(opts ...Option) *Cache {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cfg.SlabSize = min(cfg.SlabSize, cfg.MaxBytes)
+
+	return &Cache{
+		cfg:     cfg,
+		classes: newClasses(cfg.SlabSize, cfg.GrowthFactor),
+		index:   make(map[uint64]int32),
+	}
+}
+
+func (c *Cache) newSlab() []byte {
+	return byteslice.Get(c.cfg.SlabSize)
+}
+
+// data returns the bytes of e's key followed by its value.
+func (c *Cache) data(e *entry) []byte {
+	off := int(e.chunk.off)
+	return c.slabs[e.chunk.slab][off : off+int(e.keyLen+e.valLen)]
+}
+
+// lookup returns the entry holding key, or nilIndex.
+func (c *Cache) lookup(h uint64, key string) int32 {
+	i, ok := c.index[h]
+	if !ok {
+		return nilIndex
+	}
+	e := &c.entries[i]
+	if string(c.data(e)[:e.keyLen]) != key {
+		return nilIndex
+	}
+	return i
+}
+
+// Get appends the value of key to dst and returns it. The returned slice
+// does not alias the cache's memory.
+func (c *Cache) Get(key string, dst []byte) ([]byte, bool) {
+	h := runtime.MemHashString(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return dst, false
+	}
+
+	i := c.lookup(h, key)
+	if i == nilIndex {
+		c.misses++
+		return dst, false
+	}
+	c.hits++
+	c.touch(i)
+	e := &c.entries[i]
+	return append(dst, c.data(e)[e.keyLen:]...), true
+}
+
+// Set stores a copy of value under key, evicting the least recently used
+// entries of its size class if the memory budget is spent. It returns
+// false if key and value together exceed Config.SlabSize, if no chunk of
+// their size can be found, or once the cache is closed.
+func (c *Cache) Set(key string, value []byte) bool {
+	n := len(key) + len(value)
+	if n > c.cfg.SlabSize {
+		return false
+	}
+	h := runtime.MemHashString(key)
+	ci := c.classFor(n)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+
+	if i, ok := c.index[h]; ok {
+		if c.entries[i].class == int32(ci) {
+			c.used -= int64(c.entries[i].keyLen + c.entries[i].valLen)
+			c.fill(i, key, value)
+			c.touch(i)
+			return true
+		}
+		c.remove(i)
+	}
+
+	ref, ok := c.alloc(ci)
+	if !ok {
+		return false
+	}
+	i := c.newEntry()
+	c.entries[i] = entry{hash: h, chunk: ref, class: int32(ci)}
+	c.fill(i, key, value)
+	c.index[h] = i
+	c.pushFront(i)
+	return true
+}
+
+// fill copies key and value into the chunk of entry i.
+func (c *Cache) fill(i int32, key string, value []byte) {
+	e := &c.entries[i]
+	e.keyLen, e.valLen = int32(len(key)), int32(len(value))
+	buf := c.data(e)
+	copy(buf, key)
+	copy(buf[len(key):], value)
+	c.used += int64(len(buf))
+}
+
+// Delete removes key from the cache.
+func (c *Cache) Delete(key string) {
+	h := runtime.MemHashString(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	if i := c.lookup(h, key); i != nilIndex {
+		c.remove(i)
+	}
+}
+
+// remove drops entry i and frees its chunk.
+func (c *Cache) remove(i int32) {
+	e := &c.entries[i]
+	cl := &c.classes[e.class]
+	cl.free = append(cl.free, e.chunk)
+	c.unlink(i)
+}
+
+// unlink drops entry i from the index and its class's LRU list without
+// freeing its chunk.
+func (c *Cache) unlink(i int32) {
+	e := &c.entries[i]
+	c.detach(i)
+	delete(c.index, e.hash)
+	c.used -= int64(e.keyLen + e.valLen)
+	*e = entry{}
+	c.freeEntries = append(c.freeEntries, i)
+}
+
+// newEntry returns the number of an unused entry.
+func (c *Cache) newEntry() int32 {
+	if n := len(c.freeEntries); n > 0 {
+		i := c.freeEntries[n-1]
+		c.freeEntries = c.freeEntries[:n-1]
+		return i
+	}
+	c.entries = append(c.entries, entry{})
+	return int32(len(c.entries) - 1)
+}
+
+// pushFront makes entry i the most recently used of its class.
+func (c *Cache) pushFront(i int32) {
+	e := &c.entries[i]
+	cl := &c.classes[e.class]
+	e.prev, e.next = nilIndex, cl.head
+	if cl.head != nilIndex {
+		c.entries[cl.head].prev = i
+	} else {
+		cl.tail = i
+	}
+	cl.head = i
+}
+
+// detach removes entry i from its class's LRU list.
+func (c *Cache) detach(i int32) {
+	e := &c.entries[i]
+	cl := &c.classes[e.class]
+	if e.prev != nilIndex {
+		c.entries[e.prev].next = e.next
+	} else {
+		cl.head = e.next
+	}
+	if e.next != nilIndex {
+		c.entries[e.next].prev = e.prev
+	} else {
+		cl.tail = e.prev
+	}
+}
+
+// touch marks entry i as the most recently used of its class.
+func (c *Cache) touch(i int32) {
+	if c.classes[c.entries[i].class].head != i {
+		c.detach(i)
+		c.pushFront(i)
+	}
+}
+
+// Len returns the number of resident entries.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.index)
+}
+
+// SlabBytes returns the slab memory the cache holds.
+func (c *Cache) SlabBytes() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.slabs) * c.cfg.SlabSize
+}
+
+// Stats returns the cache counters. CostUsed is the key and value bytes of
+// resident entries; SlabBytes reports the memory holding them.
+func (c *Cache) Stats() cache.Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return cache.Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		KeyCount:  int64(len(c.index)),
+		CostUsed:  c.used,
+	}
+}
+
+// Clear removes every entry and returns the slabs to the pool.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.reset()
+	}
+}
+
+// Close clears the cache; later Sets fail and Gets miss. Calling it more
+// than once is a no-op.
+func (c *Cache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.reset()
+		c.closed = true
+	}
+}
+
+// reset drops every entry and slab. Caller holds c.mu.
+func (c *Cache) reset() {
+	for _, s := range c.slabs {
+		byteslice.Put(s)
+	}
+	c.slabs = nil
+	c.classes = newClasses(c.cfg.SlabSize, c.cfg.GrowthFactor)
+	clear(c.index)
+	c.entries, c.freeEntries = nil, nil
+	c.used = 0
+}
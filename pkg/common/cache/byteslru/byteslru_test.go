@@ -0,0 +1,172 @@
+package byteslru
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	c := New()
+	defer c.Close()
+
+	if !c.Set("k", []byte("value")) {
+		t.Fatal("Set returned false")
+	}
+	buf := []byte("prefix:")
+	got, ok := c.Get("k", buf)
+	if !ok || string(got) != "prefix:value" {
+		t.Fatalf("Get = %q, %v", got, ok)
+	}
+
+	// Overwrite within the same class and then across classes.
+	c.Set("k", []byte("other"))
+	if got, _ := c.Get("k", nil); string(got) != "other" {
+		t.Errorf("Get after overwrite = %q", got)
+	}
+	big := bytes.Repeat([]byte("x"), 1000)
+	c.Set("k", big)
+	if got, _ := c.Get("k", nil); !bytes.Equal(got, big) {
+		t.Errorf("Get after resize returned %d bytes", len(got))
+	}
+
+	c.Delete("k")
+	if _, ok := c.Get("k", nil); ok {
+		t.Error("Get after Delete hit")
+	}
+	if c.Len() != 0 || c.Stats().CostUsed != 0 {
+		t.Errorf("Len, CostUsed = %d, %d after Delete", c.Len(), c.Stats().CostUsed)
+	}
+}
+
+func TestGetDoesNotAlias(t *testing.T) {
+	c := New()
+	defer c.Close()
+
+	val := []byte("abc")
+	c.Set("k", val)
+	val[0] = 'X'
+
+	got, _ := c.Get("k", nil)
+	got[1] = 'Y'
+	if again, _ := c.Get("k", nil); string(again) != "abc" {
+		t.Errorf("Get = %q, want abc", again)
+	}
+}
+
+func TestTooLarge(t *testing.T) {
+	c := New(WithSlabSize(128), WithMaxBytes(1024))
+	defer c.Close()
+
+	if c.Set("k", make([]byte, 128)) {
+		t.Error("Set accepted key and value larger than a slab")
+	}
+	if !c.Set("k", make([]byte, 127)) {
+		t.Error("Set rejected key and value filling a slab")
+	}
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	// One 256-byte slab of 64-byte chunks: four entries.
+	c := New(WithSlabSize(256), WithMaxBytes(256), WithGrowthFactor(4))
+	defer c.Close()
+
+	for i := range 4 {
+		c.Set("k"+strconv.Itoa(i), []byte("v"))
+	}
+	c.Get("k0", nil) // k1 is now the least recently used
+	c.Set("k4", []byte("v"))
+
+	if _, ok := c.Get("k1", nil); ok {
+		t.Error("least recently used entry survived")
+	}
+	for _, k := range []string{"k0", "k2", "k3", "k4"} {
+		if _, ok := c.Get(k, nil); !ok {
+			t.Errorf("%s evicted", k)
+		}
+	}
+	s := c.Stats()
+	if s.Evictions != 1 || s.KeyCount != 4 {
+		t.Errorf("Stats = %+v", s)
+	}
+	if c.SlabBytes() != 256 {
+		t.Errorf("SlabBytes = %d, want 256", c.SlabBytes())
+	}
+}
+
+func TestDeleteReusesChunk(t *testing.T) {
+	c := New(WithSlabSize(256), WithMaxBytes(256), WithGrowthFactor(4))
+	defer c.Close()
+
+	for i := range 100 {
+		k := "k" + strconv.Itoa(i)
+		c.Set(k, []byte("v"))
+		c.Delete(k)
+	}
+	if s := c.Stats(); s.Evictions != 0 {
+		t.Errorf("Evictions = %d, want freed chunks reused", s.Evictions)
+	}
+}
+
+func TestClearAndClose(t *testing.T) {
+	c := New()
+	c.Set("a", []byte("1"))
+	c.Clear()
+	if _, ok := c.Get("a", nil); ok || c.SlabBytes() != 0 {
+		t.Error("Clear left entries or slabs")
+	}
+	if !c.Set("a", []byte("1")) {
+		t.Error("Set after Clear failed")
+	}
+
+	c.Close()
+	c.Close()
+	if c.Set("a", []byte("1")) {
+		t.Error("Set after Close succeeded")
+	}
+	if _, ok := c.Get("a", nil); ok {
+		t.Error("Get after Close hit")
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	c := New(WithMaxBytes(1<<20), WithSlabSize(64<<10))
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Go(func() {
+			var buf []byte
+			for i := range 2000 {
+				k := strconv.Itoa(g*10_000 + i%500)
+				val := bytes.Repeat([]byte{byte(i)}, i%300)
+				c.Set(k, val)
+				if got, ok := c.Get(k, buf[:0]); ok && len(got) > 0 && got[0] != got[len(got)-1] {
+					t.Errorf("torn value for %s", k)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func BenchmarkSetGet(b *testing.B) {
+	c := New()
+	defer c.Close()
+	val := make([]byte, 100)
+	buf := make([]byte, 0, 100)
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	i := 0
+	for b.Loop() {
+		k := keys[i&1023]
+		c.Set(k, val)
+		buf, _ = c.Get(k, buf[:0])
+		i++
+	}
+}
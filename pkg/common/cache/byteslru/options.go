@@ -0,0 +1,70 @@
+package byteslru
+
+const (
+	// DefaultMaxBytes is the slab memory budget when Config.MaxBytes is unset.
+	DefaultMaxBytes = 64 << 20
+
+	// DefaultSlabSize is the size of each slab when Config.SlabSize is unset.
+	DefaultSlabSize = 1 << 20
+
+	// DefaultGrowthFactor is the ratio between consecutive chunk sizes.
+	DefaultGrowthFactor = 1.25
+
+	// maxSlabSize keeps chunk offsets within an int32.
+	maxSlabSize = 1 << 30
+)
+
+// Config holds the cache settings.
+type Config struct {
+	// MaxBytes bounds the slab memory the cache holds. Entries are evicted
+	// once every slab it may allocate is assigned.
+	MaxBytes int
+
+	// SlabSize is the size of each slab taken from the byteslice pool, and
+	// the largest key plus value the cache stores.
+	SlabSize int
+
+	// GrowthFactor is the ratio between consecutive chunk sizes. Smaller
+	// factors waste less space per entry but make more size classes, each
+	// of which holds at least one slab once used.
+	GrowthFactor float64
+}
+
+// Option configures a Cache.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{
+		MaxBytes:     DefaultMaxBytes,
+		SlabSize:     DefaultSlabSize,
+		GrowthFactor: DefaultGrowthFactor,
+	}
+}
+
+// WithMaxBytes sets Config.MaxBytes. Values <= 0 are ignored.
+func WithMaxBytes(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxBytes = n
+		}
+	}
+}
+
+// WithSlabSize sets Config.SlabSize. Values <= 0 are ignored and values
+// above 1 GiB are capped.
+func WithSlabSize(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.SlabSize = min(n, maxSlabSize)
+		}
+	}
+}
+
+// WithGrowthFactor sets Config.GrowthFactor. Values <= 1 are ignored.
+func WithGrowthFactor(f float64) Option {
+	return func(c *Config) {
+		if f > 1 {
+			c.GrowthFactor = f
+		}
+	}
+}
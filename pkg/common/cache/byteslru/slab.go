@@ -0,0 +1,86 @@
+package byteslru
+
+import "sort"
+
+// minChunkSize is the smallest chunk handed out.
+const minChunkSize = 64
+
+// nilIndex marks the absence of an entry or slab.
+const nilIndex int32 = -1
+
+// chunkRef locates a chunk: the slab holding it and its offset there.
+type chunkRef struct {
+	slab int32
+	off  int32
+}
+
+// sizeClass owns the chunks of one size. Slabs are carved into chunks as
+// they are needed; chunks freed by Delete are reused first. Each class
+// keeps its own LRU list so an eviction always frees a chunk that fits.
+type sizeClass struct {
+	size int
+	free []chunkRef
+
+	slab int32 // slab being carved, nilIndex if none
+	next int32 // offset of its next uncarved chunk
+
+	head int32 // most recently used entry
+	tail int32 // least recently used entry
+}
+
+// newClasses returns chunk sizes from minChunkSize to slabSize, each about
+// factor times the previous and a multiple of 8.
+func newClasses(slabSize int, factor float64) []sizeClass {
+	var classes []sizeClass
+	add := func(size int) {
+		classes = append(classes, sizeClass{size: size, slab: nilIndex, head: nilIndex, tail: nilIndex})
+	}
+	for size := minChunkSize; size < slabSize; {
+		add(size)
+		next := (int(float64(size)*factor) + 7) &^ 7
+		size = max(next, size+8)
+	}
+	add(slabSize)
+	return classes
+}
+
+// classFor returns the index of the smallest class holding n bytes.
+func (c *Cache) classFor(n int) int {
+	return sort.Search(len(c.classes), func(i int) bool { return c.classes[i].size >= n })
+}
+
+// alloc returns a chunk of class ci: a freed one, a new one carved from the
+// class's slab or from a fresh slab within the budget, or the chunk of the
+// class's least recently used entry, which is evicted.
+func (c *Cache) alloc(ci int) (chunkRef, bool) {
+	cl := &c.classes[ci]
+	if n := len(cl.free); n > 0 {
+		ref := cl.free[n-1]
+		cl.free = cl.free[:n-1]
+		return ref, true
+	}
+
+	if cl.slab == nilIndex || int(cl.next)+cl.size > c.cfg.SlabSize {
+		if (len(c.slabs)+1)*c.cfg.SlabSize > c.cfg.MaxBytes {
+			return c.evict(ci)
+		}
+		c.slabs = append(c.slabs, c.newSlab())
+		cl.slab, cl.next = int32(len(c.slabs)-1), 0
+	}
+	ref := chunkRef{slab: cl.slab, off: cl.next}
+	cl.next += int32(cl.size)
+	return ref, true
+}
+
+// evict removes the least recently used entry of class ci and returns its
+// chunk.
+func (c *Cache) evict(ci int) (chunkRef, bool) {
+	i := c.classes[ci].tail
+	if i == nilIndex {
+		return chunkRef{}, false
+	}
+	ref := c.entries[i].chunk
+	c.unlink(i)
+	c.evictions++
+	return ref, true
+}
@@ -11,4 +11,12 @@ var (
 	// fn and the Fetch helpers cache that outcome briefly (negative caching),
 	// so lookups of nonexistent IDs stop hammering the source.
 	ErrNotFound = errors.New("entity not found")
+
+	// ErrEntryNotFound is returned by BytesAdapter.Get on a miss, in place of
+	// bigcache's error of the same name. It is ErrKeyNotFound.
+	ErrEntryNotFound = ErrKeyNotFound
+
+	// ErrNotStored is returned by BytesAdapter.Set when the cache dropped or
+	// refused the entry.
+	ErrNotStored = errors.New("value not stored")
 )
@@ -27,14 +27,26 @@ const negativeSuffix = ":neg"
 // negativeMarker is the value stored under key+negativeSuffix.
 type negativeMarker struct{}
 
+// fetchJitterFraction is the TTL jitter applied by the Fetch helpers.
+const fetchJitterFraction = 0.1
+
 // jitterTTL randomizes a TTL by ±10% so keys written in the same burst don't
 // expire in the same instant (cache avalanche). Applied on every write made
 // by the Fetch helpers; explicit Set/SetRemote calls honor the exact TTL.
 func jitterTTL(ttl time.Duration) time.Duration {
-	if ttl <= 0 {
+	return JitterTTL(ttl, fetchJitterFraction)
+}
+
+// JitterTTL spreads ttl uniformly within ±fraction of its value. Non-positive
+// TTLs and fractions are returned unchanged; fraction is capped at 1.
+func JitterTTL(ttl time.Duration, fraction float64) time.Duration {
+	if ttl <= 0 || fraction <= 0 {
 		return ttl
 	}
-	return time.Duration(float64(ttl) * (0.9 + 0.2*rand.Float64()))
+	if fraction > 1 {
+		fraction = 1
+	}
+	return time.Duration(float64(ttl) * (1 - fraction + 2*fraction*rand.Float64()))
 }
 
 // envelope wraps a cached value with the data the probabilistic early
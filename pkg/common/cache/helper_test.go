@@ -203,6 +203,22 @@ func TestJitterTTL(t *testing.T) {
 	}
 }
 
+func TestJitterTTLFraction(t *testing.T) {
+	ttl := time.Minute
+	for i := 0; i < 1000; i++ {
+		j := JitterTTL(ttl, 0.5)
+		if j < 30*time.Second || j >= 90*time.Second {
+			t.Fatalf("JitterTTL(%v, 0.5) = %v, outside ±50%%", ttl, j)
+		}
+	}
+	if JitterTTL(ttl, 0) != ttl {
+		t.Error("JitterTTL with zero fraction should keep the TTL")
+	}
+	if JitterTTL(-ttl, 0.5) != -ttl {
+		t.Error("JitterTTL should keep negative TTLs")
+	}
+}
+
 func TestFetchNegativeCachingLocal(t *testing.T) {
 	c := newFakeLocal()
 	sf := &singleflight.Group{}
@@ -286,3 +302,123 @@ func TestFetchRemoteBasic(t *testing.T) {
 		t.Fatalf("calls = %d, want 1", calls.Load())
 	}
 }
+
+func TestLoadingCacheGetAll(t *testing.T) {
+	lc := NewLoadingCache[string, any](newFakeLocal(), time.Minute)
+	lc.Set("a", 1)
+
+	var calls atomic.Int32
+	var gotMissing []string
+	loader := func(missing []string) (map[string]any, error) {
+		calls.Add(1)
+		gotMissing = missing
+		out := make(map[string]any)
+		for _, k := range missing {
+			if k != "ghost" {
+				out[k] = k + "!"
+			}
+		}
+		return out, nil
+	}
+
+	got, err := lc.GetAll([]string{"a", "b", "c", "b", "ghost"}, loader)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("loader calls = %d, want 1", calls.Load())
+	}
+	if len(gotMissing) != 3 {
+		t.Errorf("loader missing = %v, want [b c ghost]", gotMissing)
+	}
+	if len(got) != 3 || got["a"] != 1 || got["b"] != "b!" || got["c"] != "c!" {
+		t.Errorf("GetAll = %v", got)
+	}
+
+	// Loaded entries were admitted: a second call never reaches the loader.
+	if _, err := lc.GetAll([]string{"a", "b", "c"}, loader); err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("loader calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestLoadingCacheGetAllError(t *testing.T) {
+	f := newFakeLocal()
+	lc := NewLoadingCache[string, any](f, 0)
+
+	boom := errors.New("boom")
+	got, err := lc.GetAll([]string{"x"}, func([]string) (map[string]any, error) {
+		return map[string]any{"x": 1}, boom
+	})
+	if !errors.Is(err, boom) || got != nil {
+		t.Fatalf("GetAll = %v, %v; want nil, boom", got, err)
+	}
+	if _, ok := f.Get("x"); ok {
+		t.Error("entry admitted despite loader error")
+	}
+}
+
+// bytesLocal is a minimal LocalCache of []byte values for BytesAdapter.
+type bytesLocal struct {
+	m      map[string][]byte
+	refuse bool // Set fails
+}
+
+func (b *bytesLocal) Get(key string) ([]byte, bool) { v, ok := b.m[key]; return v, ok }
+func (b *bytesLocal) Set(key string, value []byte) bool {
+	if b.refuse {
+		return false
+	}
+	b.m[key] = value
+	return true
+}
+func (b *bytesLocal) SetWithTTL(key string, value []byte, _ time.Duration) bool {
+	return b.Set(key, value)
+}
+func (b *bytesLocal) Delete(key string) { delete(b.m, key) }
+func (b *bytesLocal) Clear()            { clear(b.m) }
+func (b *bytesLocal) Close()            {}
+func (b *bytesLocal) Stats() Stats      { return Stats{KeyCount: int64(len(b.m))} }
+
+func TestBytesAdapter(t *testing.T) {
+	local := &bytesLocal{m: make(map[string][]byte)}
+	a := NewBytesAdapter(local, time.Minute)
+
+	buf := []byte("value")
+	if err := a.Set("k", buf); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	buf[0] = 'X' // the caller reuses its buffer
+	got, err := a.Get("k")
+	if err != nil || string(got) != "value" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	got[0] = 'Y' // and mutates what it got
+	if v, _ := a.Get("k"); string(v) != "value" {
+		t.Errorf("stored value changed to %q", v)
+	}
+	if a.Len() != 1 {
+		t.Errorf("Len = %d, want 1", a.Len())
+	}
+
+	if _, err := a.Get("missing"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Get miss = %v, want ErrEntryNotFound", err)
+	}
+	local.refuse = true
+	if err := a.Set("k2", buf); !errors.Is(err, ErrNotStored) {
+		t.Errorf("refused Set = %v, want ErrNotStored", err)
+	}
+
+	if err := a.Delete("k"); err != nil || a.Len() != 0 {
+		t.Errorf("Delete = %v, Len = %d", err, a.Len())
+	}
+	local.m["x"] = nil
+	if err := a.Reset(); err != nil || a.Len() != 0 {
+		t.Errorf("Reset = %v, Len = %d", err, a.Len())
+	}
+	if err := a.Close(); err != nil {
+		t.Errorf("Close = %v", err)
+	}
+}
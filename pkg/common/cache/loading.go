@@ -0,0 +1,66 @@
+package cache
+
+import "time"
+
+// LoadingCache decorates a LocalCache with bulk read-through loading.
+// The embedded LocalCache stays available for direct Get/Set/Delete.
+type LoadingCache[K comparable, V any] struct {
+	LocalCache[K, V]
+
+	ttl time.Duration
+}
+
+// NewLoadingCache wraps c. Loaded entries are admitted with ttl, jittered
+// like the Fetch helpers; ttl <= 0 admits them without expiry.
+func NewLoadingCache[K comparable, V any](c LocalCache[K, V], ttl time.Duration) *LoadingCache[K, V] {
+	return &LoadingCache[K, V]{LocalCache: c, ttl: ttl}
+}
+
+// GetAll returns the entries for keys. Cached entries are served directly;
+// the misses are passed to loader in a single call and whatever it returns is
+// admitted to the cache and merged into the result. Keys the loader does not
+// return are absent from the map. Duplicate keys are looked up once.
+//
+// On a loader error GetAll returns nil and the error; nothing is admitted.
+// Concurrent GetAll calls missing the same keys may each load them.
+func (lc *LoadingCache[K, V]) GetAll(keys []K, loader func(missing []K) (map[K]V, error)) (map[K]V, error) {
+	result := make(map[K]V, len(keys))
+	var missing []K
+	seen := make(map[K]struct{}, len(keys))
+
+	for _, k := range keys {
+		if _, dup := seen[k]; dup {
+			continue
+		}
+		seen[k] = struct{}{}
+
+		if v, ok := lc.Get(k); ok {
+			result[k] = v
+			continue
+		}
+		missing = append(missing, k)
+	}
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	loaded, err := loader(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range missing {
+		v, ok := loaded[k]
+		if !ok {
+			continue
+		}
+		if lc.ttl > 0 {
+			lc.SetWithTTL(k, v, jitterTTL(lc.ttl))
+		} else {
+			lc.Set(k, v)
+		}
+		result[k] = v
+	}
+	return result, nil
+}
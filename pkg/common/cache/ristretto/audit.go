@@ -0,0 +1,171 @@
+package ristretto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/dgraph-io/ristretto"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/shardedmap"
+)
+
+var (
+	// ErrCostAuditDisabled is returned by AuditCost and CheckInvariants on a
+	// cache created without WithCostAudit.
+	ErrCostAuditDisabled = errors.New("ristretto: cost audit not enabled")
+
+	// ErrInvariant wraps every violation reported by CheckInvariants.
+	ErrInvariant = errors.New("ristretto: invariant violated")
+)
+
+// internalCost is what ristretto adds to every cost for its own bookkeeping
+// unless IgnoreInternalCost is set: the size of its store item.
+var internalCost = int64(unsafe.Sizeof(struct {
+	key, conflict uint64
+	value         any
+	expiration    time.Time
+}{}))
+
+// costLedger records the cost ristretto charged for each admitted entry, so
+// the total can be rebuilt independently of the policy's running sum.
+type costLedger struct {
+	costs    *shardedmap.Map[uint64, int64]
+	internal int64 // internalCost, or 0 with IgnoreInternalCost
+}
+
+func newCostLedger(ignoreInternal bool) *costLedger {
+	l := &costLedger{
+		costs:    shardedmap.New[uint64, int64](tagShards, func(h uint64) uint64 { return h }),
+		internal: internalCost,
+	}
+	if ignoreInternal {
+		l.internal = 0
+	}
+	return l
+}
+
+// wrapLedgerCallbacks forgets entries as they leave the cache.
+func wrapLedgerCallbacks(cfg *Config, l *costLedger) {
+	evict := cfg.OnEvict
+	cfg.OnEvict = func(item *ristretto.Item) {
+		l.costs.Del(item.Key)
+		if evict != nil {
+			evict(item)
+		}
+	}
+
+	reject := cfg.OnReject
+	cfg.OnReject = func(item *ristretto.Item) {
+		l.costs.Del(item.Key)
+		if reject != nil {
+			reject(item)
+		}
+	}
+}
+
+// recordCost notes the cost of h after an accepted Set, if the policy
+// admitted it. cost is what was passed to ristretto.
+func (c *Cache[K, V]) recordCost(h uint64, stored any, cost int64) {
+	if c.ledger == nil {
+		return
+	}
+	if _, resident := c.inner.GetTTL(h); !resident {
+		return
+	}
+	if cost == 0 && c.costFn != nil {
+		cost = c.costFn(stored)
+	}
+	c.ledger.costs.Set(h, cost+c.ledger.internal)
+}
+
+// forgetCost drops h from the ledger after a Delete.
+func (c *Cache[K, V]) forgetCost(h uint64) {
+	if c.ledger != nil {
+		c.ledger.costs.Del(h)
+	}
+}
+
+// AuditCost compares the used cost ristretto's metrics report (recorded,
+// the same figure as Stats().CostUsed) with the sum of the costs charged for
+// the entries still in the store (actual), walking the ledger one shard
+// lock at a time. The two drift apart when the policy keeps charging for
+// entries the store no longer holds.
+//
+// With repair set, entries the ledger knows but the store lost are deleted
+// again, which makes the policy release their cost, and are forgotten by the
+// ledger. Counts are exact only while no other operation runs.
+func (c *Cache[K, V]) AuditCost(repair bool) (recorded, actual int64, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return 0, 0, ErrClosed
+	}
+	if c.ledger == nil {
+		return 0, 0, ErrCostAuditDisabled
+	}
+
+	c.inner.Wait()
+	actual, stale := c.walkLedger()
+	if repair && len(stale) > 0 {
+		for _, h := range stale {
+			c.inner.Del(h)
+			c.ledger.costs.Del(h)
+		}
+		c.inner.Wait()
+	}
+	return c.Stats().CostUsed, actual, nil
+}
+
+// walkLedger sums the costs of resident entries and returns the hashes of
+// ledger entries no longer in the store.
+func (c *Cache[K, V]) walkLedger() (actual int64, stale []uint64) {
+	c.ledger.costs.Do(func(h uint64, cost int64) {
+		if _, resident := c.inner.GetTTL(h); resident {
+			actual += cost
+		} else {
+			stale = append(stale, h)
+		}
+	})
+	return actual, stale
+}
+
+// CheckInvariants verifies the cost accounting once pending Sets are
+// applied: every ledger entry is resident with a positive cost, and
+// ristretto's metrics agree with the ledger on key count and used cost.
+// Meant for tests and debug endpoints; it needs metrics and must not race
+// with other operations. Violations wrap ErrInvariant.
+func (c *Cache[K, V]) CheckInvariants() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return ErrClosed
+	}
+	if c.ledger == nil {
+		return ErrCostAuditDisabled
+	}
+
+	c.inner.Wait()
+	var errs []error
+	c.ledger.costs.Do(func(h uint64, cost int64) {
+		if cost <= 0 {
+			errs = append(errs, fmt.Errorf("%w: key %#x costs %d", ErrInvariant, h, cost))
+		}
+	})
+	actual, stale := c.walkLedger()
+	if len(stale) > 0 {
+		errs = append(errs, fmt.Errorf("%w: %d ledger entries not in the store", ErrInvariant, len(stale)))
+	}
+
+	if c.metrics != nil {
+		s := c.Stats()
+		if s.CostUsed != actual {
+			errs = append(errs, fmt.Errorf("%w: used cost %d, entries cost %d", ErrInvariant, s.CostUsed, actual))
+		}
+		if keys := int64(c.ledger.costs.Len() - len(stale)); s.KeyCount != keys {
+			errs = append(errs, fmt.Errorf("%w: key count %d, ledger holds %d", ErrInvariant, s.KeyCount, keys))
+		}
+	}
+	return errors.Join(errs...)
+}
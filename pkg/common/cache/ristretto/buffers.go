@@ -0,0 +1,41 @@
+package ristretto
+
+// BufferStats reports how much traffic the admission policy saw. Gets are
+// recorded in lossy per-stripe buffers of Config.BufferItems keys, and a
+// full buffer is handed to the policy only if it is not busy; otherwise the
+// batch is dropped and those accesses never count towards admission and
+// eviction decisions. A high GetsDropped share means the TinyLFU
+// frequencies lag behind the real access pattern.
+type BufferStats struct {
+	GetsKept     uint64 // accesses delivered to the policy
+	GetsDropped  uint64 // accesses lost because the policy was busy
+	SetsDropped  uint64 // Sets lost because the set buffer was full
+	SetsRejected uint64 // Sets refused by the admission policy
+}
+
+// GetsDropRatio returns GetsDropped / (GetsKept + GetsDropped), or 0 before
+// any buffer has been flushed.
+func (s BufferStats) GetsDropRatio() float64 {
+	total := s.GetsKept + s.GetsDropped
+	if total == 0 {
+		return 0
+	}
+	return float64(s.GetsDropped) / float64(total)
+}
+
+// BufferStats returns the access recording counters. Zero when metrics are
+// disabled. Ristretto's get buffers are lossy by design and cannot be made
+// to block; to measure the impact of drops, compare hit ratios across
+// WithBufferItems sizes.
+func (c *Cache[K, V]) BufferStats() BufferStats {
+	m := c.metrics
+	if m == nil {
+		return BufferStats{}
+	}
+	return BufferStats{
+		GetsKept:     m.GetsKept(),
+		GetsDropped:  m.GetsDropped(),
+		SetsDropped:  m.SetsDropped(),
+		SetsRejected: m.SetsRejected(),
+	}
+}
@@ -0,0 +1,100 @@
+package ristretto
+
+import (
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/cache"
+)
+
+// Compat exposes a Cache through the untyped method set of dgraph's
+// *ristretto.Cache (Get, Set with a cost, SetWithTTL, Del, GetTTL, Wait,
+// Clear, Close, MaxCost), so code moving off a raw ristretto cache can swap
+// the cache before its call sites. Keys that are not a K, and values that
+// are not a V, miss on Get and are not stored by Set.
+type Compat[K any, V any] struct {
+	c *Cache[K, V]
+}
+
+// NewCompat wraps c.
+func NewCompat[K any, V any](c *Cache[K, V]) *Compat[K, V] {
+	return &Compat[K, V]{c: c}
+}
+
+// Cache returns the wrapped cache, for call sites already migrated.
+func (a *Compat[K, V]) Cache() *Cache[K, V] {
+	return a.c
+}
+
+// Get returns the value stored under key.
+func (a *Compat[K, V]) Get(key any) (any, bool) {
+	k, ok := key.(K)
+	if !ok {
+		return nil, false
+	}
+	v, ok := a.c.Get(k)
+	if !ok {
+		return nil, false
+	}
+	return v, true
+}
+
+// Set stores value under key, charging cost; cost 0 lets Config.Cost price
+// the value, as in ristretto.
+func (a *Compat[K, V]) Set(key, value any, cost int64) bool {
+	return a.SetWithTTL(key, value, cost, 0)
+}
+
+// SetWithTTL is Set with a TTL, jittered like Cache.SetWithTTL. A negative
+// ttl discards the value, as in ristretto.
+func (a *Compat[K, V]) SetWithTTL(key, value any, cost int64, ttl time.Duration) bool {
+	k, ok := key.(K)
+	if !ok {
+		return false
+	}
+	v, ok := value.(V)
+	if !ok {
+		return false
+	}
+	ok, _ = a.c.set(k, v, cost, cache.JitterTTL(ttl, a.c.jitter), nil)
+	return ok
+}
+
+// Del removes key.
+func (a *Compat[K, V]) Del(key any) {
+	if k, ok := key.(K); ok {
+		a.c.Delete(k)
+	}
+}
+
+// GetTTL returns the time left before key expires, 0 if it never does,
+// without counting an access.
+func (a *Compat[K, V]) GetTTL(key any) (time.Duration, bool) {
+	k, ok := key.(K)
+	if !ok {
+		return 0, false
+	}
+	a.c.mu.RLock()
+	defer a.c.mu.RUnlock()
+	if a.c.closed {
+		return 0, false
+	}
+	return a.c.inner.GetTTL(hashKey(k))
+}
+
+// Wait returns at once: a Set is applied before it returns.
+func (a *Compat[K, V]) Wait() {}
+
+// Clear removes every entry.
+func (a *Compat[K, V]) Clear() {
+	a.c.Clear()
+}
+
+// Close closes the cache.
+func (a *Compat[K, V]) Close() {
+	a.c.Close()
+}
+
+// MaxCost returns the cost budget.
+func (a *Compat[K, V]) MaxCost() int64 {
+	return a.c.inner.MaxCost()
+}
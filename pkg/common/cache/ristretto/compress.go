@@ -0,0 +1,207 @@
+package ristretto
+
+import (
+	"encoding"
+	"errors"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/dgraph-io/ristretto"
+)
+
+// DefaultCompressThreshold is the smallest value WithCompression compresses
+// when no threshold is given.
+const DefaultCompressThreshold = 1024
+
+// ErrCompressionUnsupported is returned by New when compression is enabled
+// for a value type it cannot round-trip.
+var ErrCompressionUnsupported = errors.New("ristretto: compression needs []byte, any or BinaryMarshaler values")
+
+// Compressor compresses cache values. Its shape matches snappy's
+// Encode/Decode and zstd's EncodeAll/DecodeAll, so either plugs in without a
+// hard dependency. dst may be reused for the result. It must be safe for
+// concurrent use.
+type Compressor interface {
+	Compress(dst, src []byte) []byte
+	Decompress(dst, src []byte) ([]byte, error)
+}
+
+// CompressionStats reports what WithCompression saved.
+type CompressionStats struct {
+	Compressed      uint64 // Sets stored compressed
+	Incompressible  uint64 // eligible Sets stored raw because compression did not shrink them
+	RawBytes        uint64 // size before compression of the values stored compressed
+	CompressedBytes uint64 // size after compression of the same values
+}
+
+// compressed is the stored form of a compressed value.
+type compressed struct {
+	data []byte
+	raw  int // uncompressed size
+}
+
+// compression is the compression layer of a Cache.
+type compression struct {
+	c         Compressor
+	threshold int
+	binary    bool // V round-trips through Binary(Un)Marshaler, else []byte
+
+	compressed     atomic.Uint64
+	incompressible atomic.Uint64
+	rawBytes       atomic.Uint64
+	storedBytes    atomic.Uint64
+}
+
+var (
+	bytesType = reflect.TypeFor[[]byte]()
+	anyType   = reflect.TypeFor[any]()
+)
+
+// newCompression checks that V can round-trip and returns the layer.
+func newCompression[V any](c Compressor, threshold int) (*compression, error) {
+	cp := &compression{c: c, threshold: threshold}
+	if cp.threshold <= 0 {
+		cp.threshold = DefaultCompressThreshold
+	}
+
+	switch t := reflect.TypeFor[V](); {
+	case t == bytesType || t == anyType:
+		// []byte values, or dynamic []byte values of an any cache.
+	case t.Implements(reflect.TypeFor[encoding.BinaryMarshaler]()) && unmarshalable(t):
+		cp.binary = true
+	default:
+		return nil, ErrCompressionUnsupported
+	}
+	return cp, nil
+}
+
+// unmarshalable reports whether a value of type t can be rebuilt with
+// UnmarshalBinary: through &v for a value type, through a new element for a
+// pointer type.
+func unmarshalable(t reflect.Type) bool {
+	u := reflect.TypeFor[encoding.BinaryUnmarshaler]()
+	if t.Kind() == reflect.Pointer {
+		return t.Implements(u)
+	}
+	return reflect.PointerTo(t).Implements(u)
+}
+
+// encode returns the form of value to store: compressed when it is eligible,
+// at least threshold bytes and actually shrinks, value itself otherwise.
+func encode[V any](cp *compression, value V) any {
+	var raw []byte
+	switch v := any(value).(type) {
+	case []byte:
+		raw = v
+	case encoding.BinaryMarshaler:
+		if !cp.binary {
+			return value
+		}
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return value
+		}
+		raw = b
+	default:
+		return value
+	}
+	if len(raw) < cp.threshold {
+		return value
+	}
+
+	out := cp.c.Compress(nil, raw)
+	if len(out) >= len(raw) {
+		cp.incompressible.Add(1)
+		return value
+	}
+	cp.compressed.Add(1)
+	cp.rawBytes.Add(uint64(len(raw)))
+	cp.storedBytes.Add(uint64(len(out)))
+	return compressed{data: out, raw: len(raw)}
+}
+
+// decode turns a stored value back into a V.
+func decode[V any](cp *compression, stored any) (V, bool) {
+	var zero V
+	cv, ok := stored.(compressed)
+	if !ok {
+		v, ok := stored.(V)
+		return v, ok
+	}
+
+	raw, err := cp.c.Decompress(make([]byte, 0, cv.raw), cv.data)
+	if err != nil {
+		return zero, false
+	}
+	if !cp.binary {
+		v, ok := any(raw).(V)
+		return v, ok
+	}
+
+	var v V
+	var u encoding.BinaryUnmarshaler
+	if t := reflect.TypeFor[V](); t.Kind() == reflect.Pointer {
+		v = reflect.New(t.Elem()).Interface().(V)
+		u = any(v).(encoding.BinaryUnmarshaler)
+	} else {
+		u = any(&v).(encoding.BinaryUnmarshaler)
+	}
+	if err := u.UnmarshalBinary(raw); err != nil {
+		return zero, false
+	}
+	return v, true
+}
+
+func (cp *compression) stats() CompressionStats {
+	return CompressionStats{
+		Compressed:      cp.compressed.Load(),
+		Incompressible:  cp.incompressible.Load(),
+		RawBytes:        cp.rawBytes.Load(),
+		CompressedBytes: cp.storedBytes.Load(),
+	}
+}
+
+// wrapCompressionCallbacks decompresses values before user callbacks see
+// them and charges compressed values their stored size.
+func wrapCompressionCallbacks[V any](cfg *Config, cp *compression) {
+	unwrap := func(item *ristretto.Item) *ristretto.Item {
+		if _, ok := item.Value.(compressed); !ok {
+			return item
+		}
+		cpy := *item
+		cpy.Value, _ = decode[V](cp, item.Value)
+		return &cpy
+	}
+
+	if fn := cfg.OnEvict; fn != nil {
+		cfg.OnEvict = func(item *ristretto.Item) { fn(unwrap(item)) }
+	}
+	if fn := cfg.OnReject; fn != nil {
+		cfg.OnReject = func(item *ristretto.Item) { fn(unwrap(item)) }
+	}
+	if fn := cfg.OnExit; fn != nil {
+		cfg.OnExit = func(val any) {
+			if _, ok := val.(compressed); ok {
+				val, _ = decode[V](cp, val)
+			}
+			fn(val)
+		}
+	}
+	if fn := cfg.Cost; fn != nil {
+		cfg.Cost = func(val any) int64 {
+			if cv, ok := val.(compressed); ok {
+				return int64(len(cv.data))
+			}
+			return fn(val)
+		}
+	}
+}
+
+// CompressionStats returns the compression counters; zero without
+// WithCompression.
+func (c *Cache[K, V]) CompressionStats() CompressionStats {
+	if c.comp == nil {
+		return CompressionStats{}
+	}
+	return c.comp.stats()
+}
@@ -1,15 +1,130 @@
 package ristretto
 
 import (
+	"time"
+
 	"github.com/dgraph-io/ristretto"
+
+	"github.com/huynhanx03/go-common/pkg/mq/batcher"
 )
 
-// Option applies a configuration change to a ristretto.Config.
-type Option func(cfg *ristretto.Config)
+// Config is the wrapper configuration: ristretto's own settings plus the
+// callbacks implemented by Cache itself.
+type Config struct {
+	ristretto.Config
+
+	// OnDrop is called with the original key and value of every Set the
+	// cache could not apply: dropped by a full set buffer, or issued after
+	// Close has started.
+	OnDrop func(key, value any)
+
+	// TTLJitterFraction spreads the expiration of every SetWithTTL within
+	// ±fraction of the requested TTL, so keys written in the same burst
+	// don't all expire together. Zero keeps exact TTLs.
+	TTLJitterFraction float64
+
+	// Snapshots keeps the original keys of resident entries and a frequency
+	// sketch of their accesses, which Export needs to enumerate the hottest
+	// entries, and PreviewAdd to estimate admissions. It costs a map entry
+	// per key and a locked sketch update per Get.
+	Snapshots bool
+
+	// Compressor, when set, stores values of at least CompressThreshold
+	// bytes compressed, trading CPU on Set and Get for cost budget. V must be
+	// []byte, any holding []byte, or implement encoding.BinaryMarshaler with
+	// *V implementing encoding.BinaryUnmarshaler. Only a Cost function sees
+	// the saving: without one every entry costs the same.
+	Compressor Compressor
+
+	// CompressThreshold is the smallest encoded value Compressor is tried
+	// on; DefaultCompressThreshold when zero.
+	CompressThreshold int
+
+	// OnEvictBatch receives evicted items in batches on a dedicated
+	// goroutine, so mass evictions (Clear, Close) don't run millions of
+	// callbacks on the caller's goroutine. Items wait in a queue of
+	// EvictBufferSize; while it is full, evicting goroutines block. The
+	// slice is reused after the call returns. Close delivers everything
+	// queued before it returns. The callback must not call Clear or Close:
+	// both wait for evictions that may be blocked on it.
+	OnEvictBatch func(items []*ristretto.Item)
+
+	// EvictBatchSize caps the items per OnEvictBatch call;
+	// DefaultEvictBatchSize when zero.
+	EvictBatchSize int
+
+	// EvictBufferSize bounds the items queued for OnEvictBatch;
+	// DefaultEvictBufferSize when zero.
+	EvictBufferSize int
+
+	// CostAudit keeps a ledger of the cost charged for every resident
+	// entry, which AuditCost and CheckInvariants compare against the
+	// policy's accounting. It costs a map entry per key and a residency
+	// check per Set.
+	CostAudit bool
+
+	// Trace, when set, receives a TraceEvent for every Get, Set and Delete
+	// through a striped batcher, for offline tuning with Replay. Events
+	// pending at Close are flushed to it before Close returns. Consume runs
+	// on the calling goroutines and should hand batches off quickly.
+	Trace batcher.Consumer[TraceEvent]
+
+	// CostFromRaw prices values set with SetFromBytes from the size of the
+	// serialized form they arrived in and the decoded value, for caches fed
+	// from network reads whose decoded values weigh more, or less, than
+	// their wire size. v holds a V; WithCostFromRaw takes a typed function.
+	// A result <= 0 falls back to Cost.
+	CostFromRaw func(rawLen int, v any) int64
+
+	// Synchronous runs the admission policy inline instead of on
+	// ristretto's goroutines: every Get counts towards admission, every
+	// Set is admitted or rejected before it returns, evictions sample keys
+	// with a fixed seed, and expired entries leave on the next Set. The
+	// same sequence of operations then gives the same hits, evictions and
+	// callbacks, which unit tests and hit-ratio simulations need, and the
+	// cache starts no goroutine but Trace's. OnEvictBatch is called before
+	// the call that evicted returns. Throughput under contention is lower.
+	Synchronous bool
+
+	// Shadow, when non-nil, runs a second configuration on the same access
+	// stream for A/B evaluation of a tuning change: the cache's Config with
+	// these options applied, e.g. WithMaxCost or WithNumCounters. The
+	// shadow stores no values, only keys and costs, and runs synchronously;
+	// ShadowStats compares its hit ratio with the cache's. It costs a
+	// locked policy update per Get, Set and Delete. The shadow counts every
+	// Get, while a loaded asynchronous cache drops some, so the comparison
+	// is exact only with Synchronous.
+	Shadow []Option
+
+	// ClockGranularity, when positive, has the expiry checks the wrapper
+	// owns read a coarse clock refreshed every ClockGranularity by one
+	// goroutine instead of calling time.Now: Synchronous Gets, Sets and
+	// Waits, tombstone checks and the shadow. Caches with the same
+	// granularity share the goroutine. Entries may then live up to
+	// ClockGranularity past their TTL or leave that much early.
+	//
+	// Without Synchronous, Get checks expiry inside ristretto's store, which
+	// always calls time.Now; only tombstone checks use the coarse clock
+	// there. Set Synchronous too to take time.Now off the Get path. 0 reads
+	// the exact time everywhere.
+	ClockGranularity time.Duration
+}
+
+// Option applies a configuration change to a Config.
+type Option func(cfg *Config)
+
+// WithRistrettoConfig adapts an option written against ristretto.Config,
+// the type Option applied to before Config wrapped it, so existing custom
+// options keep working: WithRistrettoConfig(myOption).
+func WithRistrettoConfig(fn func(cfg *ristretto.Config)) Option {
+	return func(cfg *Config) {
+		fn(&cfg.Config)
+	}
+}
 
 // WithMaxCost sets the maximum cost of the cache (in bytes by convention).
 func WithMaxCost(maxCost int64) Option {
-	return func(cfg *ristretto.Config) {
+	return func(cfg *Config) {
 		cfg.MaxCost = maxCost
 	}
 }
@@ -17,39 +132,143 @@ func WithMaxCost(maxCost int64) Option {
 // WithNumCounters sets the number of counter rows for the TinyLFU policy.
 // Recommended to be at least 10x the expected number of items.
 func WithNumCounters(counters int64) Option {
-	return func(cfg *ristretto.Config) {
+	return func(cfg *Config) {
 		cfg.NumCounters = counters
 	}
 }
 
-// WithBufferItems sets the number of keys per Get buffer.
+// WithBufferItems sets the number of keys per Get buffer. Each stripe
+// batches this many accesses before handing them to the admission policy,
+// which drops the batch if it is busy; see BufferStats. Larger buffers mean
+// fewer handoffs under load but staler frequencies. 64 suits most loads.
 func WithBufferItems(items int64) Option {
-	return func(cfg *ristretto.Config) {
+	return func(cfg *Config) {
 		cfg.BufferItems = items
 	}
 }
 
 // WithMetrics enables or disables cache metrics collection.
 func WithMetrics(enabled bool) Option {
-	return func(cfg *ristretto.Config) {
+	return func(cfg *Config) {
 		cfg.Metrics = enabled
 	}
 }
 
-// WithCost sets the internal cost function for values.
+// WithCost sets the cost function for values, called once per Set with the
+// stored value (a compressed value is charged its compressed size). Without
+// it every entry costs 1.
 func WithCost(fn func(any) int64) Option {
-	return func(cfg *ristretto.Config) {
+	return func(cfg *Config) {
 		cfg.Cost = fn
 	}
 }
 
-// DefaultConfig returns a ristretto.Config with sensible defaults:
+// WithOnEvict sets the callback invoked for items evicted by the policy,
+// expired, or still resident when the cache is cleared or closed.
+func WithOnEvict(fn func(item *ristretto.Item)) Option {
+	return func(cfg *Config) {
+		cfg.OnEvict = fn
+	}
+}
+
+// WithOnEvictBatch sets Config.OnEvictBatch, Config.EvictBatchSize and
+// Config.EvictBufferSize. Zero sizes keep the defaults.
+func WithOnEvictBatch(fn func(items []*ristretto.Item), batchSize, bufferSize int) Option {
+	return func(cfg *Config) {
+		cfg.OnEvictBatch = fn
+		cfg.EvictBatchSize = batchSize
+		cfg.EvictBufferSize = bufferSize
+	}
+}
+
+// WithOnDrop sets the callback invoked for Sets the cache could not apply.
+func WithOnDrop(fn func(key, value any)) Option {
+	return func(cfg *Config) {
+		cfg.OnDrop = fn
+	}
+}
+
+// WithTTLJitter sets Config.TTLJitterFraction (e.g. 0.1 for ±10%).
+func WithTTLJitter(fraction float64) Option {
+	return func(cfg *Config) {
+		cfg.TTLJitterFraction = fraction
+	}
+}
+
+// WithSnapshots sets Config.Snapshots, enabling Export.
+func WithSnapshots() Option {
+	return func(cfg *Config) {
+		cfg.Snapshots = true
+	}
+}
+
+// WithCostAudit sets Config.CostAudit. It also enables metrics, which
+// AuditCost reads the policy's used cost from.
+func WithCostAudit() Option {
+	return func(cfg *Config) {
+		cfg.CostAudit = true
+		cfg.Metrics = true
+	}
+}
+
+// WithCompression sets Config.Compressor and Config.CompressThreshold.
+func WithCompression(c Compressor, threshold int) Option {
+	return func(cfg *Config) {
+		cfg.Compressor = c
+		cfg.CompressThreshold = threshold
+	}
+}
+
+// WithTrace sets Config.Trace.
+func WithTrace(sink batcher.Consumer[TraceEvent]) Option {
+	return func(cfg *Config) {
+		cfg.Trace = sink
+	}
+}
+
+// WithCostFromRaw sets Config.CostFromRaw to fn, for a cache of V values.
+func WithCostFromRaw[V any](fn func(rawLen int, v V) int64) Option {
+	return func(cfg *Config) {
+		cfg.CostFromRaw = func(rawLen int, v any) int64 {
+			typed, _ := v.(V)
+			return fn(rawLen, typed)
+		}
+	}
+}
+
+// WithSynchronous sets Config.Synchronous.
+func WithSynchronous() Option {
+	return func(cfg *Config) {
+		cfg.Synchronous = true
+	}
+}
+
+// WithShadow sets Config.Shadow to opts. Without options the shadow runs
+// the cache's own configuration, a baseline for the inline policy.
+func WithShadow(opts ...Option) Option {
+	return func(cfg *Config) {
+		cfg.Shadow = append(make([]Option, 0, len(opts)), opts...)
+	}
+}
+
+// WithClockGranularity sets Config.ClockGranularity. It keeps time.Now off
+// the Get path only together with WithSynchronous.
+func WithClockGranularity(d time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.ClockGranularity = d
+	}
+}
+
+// DefaultConfig returns a Config with sensible defaults:
 // MaxCost = 100 MB, NumCounters = 10M, BufferItems = 64, Metrics enabled.
-func DefaultConfig() ristretto.Config {
-	return ristretto.Config{
-		NumCounters: 1e7,             // 10 million counters
-		MaxCost:     100 << 20,       // 100 MB
-		BufferItems: 64,              // number of keys per Get buffer
-		Metrics:     true,            // enable metrics collection
+// Callers that need the plain ristretto.Config use DefaultConfig().Config.
+func DefaultConfig() Config {
+	return Config{
+		Config: ristretto.Config{
+			NumCounters: 1e7,       // 10 million counters
+			MaxCost:     100 << 20, // 100 MB
+			BufferItems: 64,        // number of keys per Get buffer
+			Metrics:     true,      // enable metrics collection
+		},
 	}
 }
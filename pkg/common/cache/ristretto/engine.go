@@ -0,0 +1,68 @@
+package ristretto
+
+import (
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+
+	"github.com/huynhanx03/go-common/pkg/timer"
+)
+
+// engine is the cache core Cache drives: *ristretto.Cache, or syncEngine
+// with Config.Synchronous. Keys are the uint64 hashes of hashKey.
+type engine interface {
+	Get(key any) (any, bool)
+	SetWithTTL(key, value any, cost int64, ttl time.Duration) bool
+	GetTTL(key any) (time.Duration, bool)
+	Del(key any)
+	Wait()
+	Clear()
+	Close()
+	MaxCost() int64
+}
+
+// engineMetrics is the part of *ristretto.Metrics the wrapper reads.
+type engineMetrics interface {
+	Hits() uint64
+	Misses() uint64
+	KeysAdded() uint64
+	KeysEvicted() uint64
+	CostAdded() uint64
+	CostEvicted() uint64
+	GetsKept() uint64
+	GetsDropped() uint64
+	SetsDropped() uint64
+	SetsRejected() uint64
+}
+
+var (
+	_ engine        = (*ristretto.Cache)(nil)
+	_ engine        = (*syncEngine)(nil)
+	_ engineMetrics = (*ristretto.Metrics)(nil)
+	_ engineMetrics = (*syncMetrics)(nil)
+)
+
+// newEngine creates the engine cfg asks for, and its metrics, nil when
+// disabled. settle runs after every synchronous call that evicted items;
+// clock times the synchronous engine's expiry.
+func newEngine(cfg *Config, settle func(), clock timer.Timer) (engine, engineMetrics, error) {
+	if cfg.Synchronous {
+		e, err := newSyncEngine(cfg, settle, clock)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !cfg.Metrics {
+			return e, nil, nil
+		}
+		return e, e.metrics, nil
+	}
+
+	inner, err := ristretto.NewCache(&cfg.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if inner.Metrics == nil {
+		return inner, nil, nil
+	}
+	return inner, inner.Metrics, nil
+}
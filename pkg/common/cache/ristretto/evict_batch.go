@@ -0,0 +1,150 @@
+package ristretto
+
+import (
+	"sync"
+
+	"github.com/dgraph-io/ristretto"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/queue"
+)
+
+const (
+	// DefaultEvictBatchSize is the most items handed to OnEvictBatch at once
+	// when Config.EvictBatchSize is zero.
+	DefaultEvictBatchSize = 256
+
+	// DefaultEvictBufferSize is the number of evicted items queued for
+	// OnEvictBatch when Config.EvictBufferSize is zero.
+	DefaultEvictBufferSize = 4096
+)
+
+// evictBatcher queues evicted items on a bounded MPMC queue and hands them
+// to fn in batches from a single goroutine. Producers block while the queue
+// is full, so a slow callback throttles eviction instead of losing items.
+type evictBatcher struct {
+	q   *queue.MPMC[*ristretto.Item]
+	fn  func([]*ristretto.Item)
+	buf []*ristretto.Item // consumer-owned batch, reused across calls
+
+	mu    sync.Mutex
+	space *sync.Cond // signalled after the consumer frees slots
+
+	wake chan struct{} // cap 1: items are pending
+	stop chan struct{}
+	done chan struct{}
+
+	inline bool // no goroutine: flush delivers, for Config.Synchronous
+}
+
+// newEvictBatcher starts the delivery goroutine unless inline, in which case
+// add delivers when the queue fills and the cache calls flush after every
+// operation that evicted.
+func newEvictBatcher(fn func([]*ristretto.Item), batch, buffer int, inline bool) *evictBatcher {
+	if batch <= 0 {
+		batch = DefaultEvictBatchSize
+	}
+	if buffer <= 0 {
+		buffer = DefaultEvictBufferSize
+	}
+	b := &evictBatcher{
+		q:      queue.NewMPMC[*ristretto.Item](buffer),
+		fn:     fn,
+		buf:    make([]*ristretto.Item, batch),
+		inline: inline,
+	}
+	b.space = sync.NewCond(&b.mu)
+	if inline {
+		return b
+	}
+	b.wake = make(chan struct{}, 1)
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+	go b.run()
+	return b
+}
+
+// add queues item, blocking while the queue is full.
+func (b *evictBatcher) add(item *ristretto.Item) {
+	if b.inline {
+		for !b.q.Enqueue(item) {
+			b.drain()
+		}
+		return
+	}
+	if !b.q.Enqueue(item) {
+		b.mu.Lock()
+		for !b.q.Enqueue(item) {
+			b.notify()
+			b.space.Wait()
+		}
+		b.mu.Unlock()
+	}
+	b.notify()
+}
+
+func (b *evictBatcher) notify() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (b *evictBatcher) run() {
+	defer close(b.done)
+	for {
+		select {
+		case <-b.wake:
+			b.drain()
+		case <-b.stop:
+			b.drain()
+			return
+		}
+	}
+}
+
+// drain delivers batches until the queue is empty.
+func (b *evictBatcher) drain() {
+	for {
+		n := b.q.DequeueBatch(b.buf)
+		if n == 0 {
+			return
+		}
+		b.mu.Lock()
+		b.space.Broadcast()
+		b.mu.Unlock()
+
+		b.fn(b.buf[:n])
+		clear(b.buf[:n])
+	}
+}
+
+// flush delivers the items queued in an inline batcher. The goroutine of
+// the others needs no help.
+func (b *evictBatcher) flush() {
+	if b.inline {
+		b.drain()
+	}
+}
+
+// close delivers everything queued so far and stops the goroutine. No add
+// may run concurrently with or after close.
+func (b *evictBatcher) close() {
+	if b.inline {
+		b.drain()
+		return
+	}
+	close(b.stop)
+	<-b.done
+}
+
+// wrapEvictBatch routes every eviction the user sees through b, after the
+// synchronous OnEvict if there is one.
+func wrapEvictBatch(cfg *Config, b *evictBatcher) {
+	evict := cfg.OnEvict
+	cfg.OnEvict = func(item *ristretto.Item) {
+		if evict != nil {
+			evict(item)
+		}
+		b.add(item)
+	}
+}
@@ -0,0 +1,61 @@
+package ristretto
+
+import "time"
+
+// ItemInfo describes a resident entry as returned by GetWithInfo.
+type ItemInfo struct {
+	// TTL is the time left before the entry expires, 0 if it never does.
+	TTL time.Duration
+
+	// Cost is the cost the entry was charged on Set.
+	Cost int64
+
+	// Frequency is the estimated number of recent accesses, including this
+	// one. It is -1 unless the cache was created WithSnapshots, which keeps
+	// the frequency sketch; ristretto's own admission sketch is not exposed.
+	Frequency int64
+}
+
+// ExpiresWithin reports whether the entry expires in d or less, e.g. to
+// serve a stale value while refreshing it in the background.
+func (i ItemInfo) ExpiresWithin(d time.Duration) bool {
+	return i.TTL > 0 && i.TTL <= d
+}
+
+// GetWithInfo is Get that also reports the entry's remaining TTL, cost and
+// estimated access frequency.
+func (c *Cache[K, V]) GetWithInfo(key K) (V, ItemInfo, bool) {
+	var zero V
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return zero, ItemInfo{}, false
+	}
+
+	// TTL first: an entry that expires between the two lookups then misses
+	// rather than reporting a TTL of 0, which would read as "never expires".
+	h := hashKey(key)
+	ttl, ok := c.inner.GetTTL(h)
+	if !ok {
+		c.inner.Get(h) // count the miss
+		return zero, ItemInfo{}, false
+	}
+	val, ok := c.inner.Get(h)
+	if !ok {
+		return zero, ItemInfo{}, false
+	}
+	typed, ok := c.decode(val)
+	if !ok {
+		return zero, ItemInfo{}, false
+	}
+
+	info := ItemInfo{TTL: ttl, Cost: defaultCost, Frequency: -1}
+	if c.costFn != nil {
+		info.Cost = c.costFn(val)
+	}
+	if c.index != nil {
+		info.Frequency = c.index.touchEstimate(h)
+	}
+	return typed, info, true
+}
@@ -0,0 +1,234 @@
+package ristretto
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+
+	"github.com/huynhanx03/go-common/pkg/common/cache"
+	"github.com/huynhanx03/go-common/pkg/hash"
+)
+
+// nsGeneration is the key space of a namespace between two Clears.
+// Entries point at the generation that wrote them, so a Clear makes them
+// unreachable at once and stops charging them to the namespace budget.
+type nsGeneration struct {
+	seed uint64
+	used atomic.Int64 // cost of resident entries
+	keys atomic.Int64 // resident entries
+}
+
+// nsEntry is the value stored in ristretto for a namespaced Set: the value
+// as a plain Set would store it, compressed if enabled, and its cost.
+type nsEntry struct {
+	gen   *nsGeneration
+	ns    *namespace
+	value any
+	cost  int64
+}
+
+// namespace is the state shared by every view of one namespace name.
+type namespace struct {
+	name    string
+	maxCost atomic.Int64 // 0 = bounded only by the cache
+	gen     atomic.Pointer[nsGeneration]
+	epoch   atomic.Uint64
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+func newNamespace(name string) *namespace {
+	ns := &namespace{name: name}
+	ns.reset()
+	return ns
+}
+
+// reset starts a new, empty generation.
+func (ns *namespace) reset() {
+	g := &nsGeneration{seed: hash.Hash64WithSeed(ns.name, ns.epoch.Add(1))}
+	ns.gen.Store(g)
+}
+
+// NamespaceOption configures a namespace.
+type NamespaceOption func(ns *namespace)
+
+// WithMaxCostShare caps the total cost of entries resident in the namespace,
+// in the units of Config.Cost, or entries without it. Sets that would
+// exceed it are dropped (reported to OnDrop) until evictions, expiry or
+// deletes free room. The cap is approximate under concurrent writers.
+func WithMaxCostShare(maxCost int64) NamespaceOption {
+	return func(ns *namespace) {
+		if maxCost >= 0 {
+			ns.maxCost.Store(maxCost)
+		}
+	}
+}
+
+// Namespace is a view over a Cache with its own key space and, optionally,
+// its own share of the cache's budget. All namespaces of a Cache compete in
+// one admission/eviction policy, so memory flows to whichever is hottest.
+type Namespace[K any, V any] struct {
+	c  *Cache[K, V]
+	ns *namespace
+}
+
+var _ cache.LocalCache[string, any] = (*Namespace[string, any])(nil)
+
+// Namespace returns the view named name. Views with the same name share
+// their entries and counters; options passed later replace earlier ones.
+func (c *Cache[K, V]) Namespace(name string, opts ...NamespaceOption) *Namespace[K, V] {
+	c.nsMu.Lock()
+	ns, ok := c.namespaces[name]
+	if !ok {
+		ns = newNamespace(name)
+		c.namespaces[name] = ns
+	}
+	c.nsMu.Unlock()
+
+	for _, opt := range opts {
+		opt(ns)
+	}
+	return &Namespace[K, V]{c: c, ns: ns}
+}
+
+// Name returns the namespace name.
+func (n *Namespace[K, V]) Name() string {
+	return n.ns.name
+}
+
+// keyHash scopes the key hash to the current generation of the namespace.
+func (n *Namespace[K, V]) keyHash(g *nsGeneration, key K) uint64 {
+	return mix64(hashKey(key) ^ g.seed)
+}
+
+// Get retrieves a value from the namespace.
+func (n *Namespace[K, V]) Get(key K) (V, bool) {
+	var zero V
+
+	n.c.mu.RLock()
+	defer n.c.mu.RUnlock()
+	if n.c.closed {
+		return zero, false
+	}
+
+	g := n.ns.gen.Load()
+	val, ok := n.c.inner.Get(n.keyHash(g, key))
+	if ok {
+		// The generation check also rejects a 64-bit collision with another
+		// namespace's key.
+		if e, isEntry := val.(nsEntry); isEntry && e.gen == g {
+			if typed, isV := n.c.decode(e.value); isV {
+				n.ns.hits.Add(1)
+				return typed, true
+			}
+		}
+	}
+	n.ns.misses.Add(1)
+	return zero, false
+}
+
+// Set adds or updates a value without TTL.
+func (n *Namespace[K, V]) Set(key K, value V) bool {
+	return n.SetWithTTL(key, value, 0)
+}
+
+// SetWithTTL adds or updates a value with a TTL. It takes the same path as
+// Cache.SetWithTTL, compression and cost function included, and is dropped
+// when it would take the namespace past its cost share.
+func (n *Namespace[K, V]) SetWithTTL(key K, value V, ttl time.Duration) bool {
+	g := n.ns.gen.Load()
+	ok, _ := n.c.setHashed(n.keyHash(g, key), n.ns, g, key, value, 0, cache.JitterTTL(ttl, n.c.jitter), nil)
+	return ok
+}
+
+// fitsShare reports whether an entry of cost fits the share of ns in
+// generation g. Replacing a resident entry frees its cost first.
+func (c *Cache[K, V]) fitsShare(ns *namespace, g *nsGeneration, h uint64, cost int64) bool {
+	limit := ns.maxCost.Load()
+	if limit <= 0 {
+		return true
+	}
+	used := g.used.Load()
+	if used+cost <= limit {
+		return true
+	}
+	if e, ok := c.residentEntry(g, h); ok {
+		return used-e.cost+cost <= limit
+	}
+	return false
+}
+
+// residentEntry returns the entry h holds if it belongs to generation g.
+func (c *Cache[K, V]) residentEntry(g *nsGeneration, h uint64) (nsEntry, bool) {
+	val, ok := c.inner.Get(h)
+	if !ok {
+		return nsEntry{}, false
+	}
+	e, isEntry := val.(nsEntry)
+	return e, isEntry && e.gen == g
+}
+
+// Delete removes a value from the namespace.
+func (n *Namespace[K, V]) Delete(key K) {
+	n.c.mu.RLock()
+	defer n.c.mu.RUnlock()
+	if n.c.closed {
+		return
+	}
+
+	g := n.ns.gen.Load()
+	h := n.keyHash(g, key)
+	if _, ok := n.c.residentEntry(g, h); ok {
+		n.c.inner.Del(h)
+		n.c.forgetCost(h)
+	}
+}
+
+// Clear drops every entry of the namespace, leaving other namespaces intact.
+// Old entries become unreachable immediately and are reclaimed by the cache
+// policy over time; they no longer count against the namespace share.
+func (n *Namespace[K, V]) Clear() {
+	n.ns.reset()
+}
+
+// Close is a no-op: the underlying Cache is owned and closed by its creator.
+func (n *Namespace[K, V]) Close() {}
+
+// Stats returns the namespace's own counters. CostUsed and KeyCount are the
+// cost and number of entries resident in the current generation.
+func (n *Namespace[K, V]) Stats() cache.Stats {
+	g := n.ns.gen.Load()
+	return cache.Stats{
+		Hits:      n.ns.hits.Load(),
+		Misses:    n.ns.misses.Load(),
+		Evictions: n.ns.evictions.Load(),
+		KeyCount:  g.keys.Load(),
+		CostUsed:  g.used.Load(),
+	}
+}
+
+// unwrapItem hides nsEntry from user callbacks. It returns the namespace the
+// item belonged to, nil for plain entries.
+func unwrapItem(item *ristretto.Item) (*ristretto.Item, *namespace) {
+	e, ok := item.Value.(nsEntry)
+	if !ok {
+		return item, nil
+	}
+	cp := *item
+	cp.Value = e.value
+	return &cp, e.ns
+}
+
+// mix64 is the splitmix64 finalizer; it spreads namespace seeds across the
+// whole hash so identity-hashed integer keys don't line up between namespaces.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
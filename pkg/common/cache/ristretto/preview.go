@@ -0,0 +1,121 @@
+package ristretto
+
+// lfuSample is how many resident keys ristretto's policy compares when it
+// picks an eviction victim.
+const lfuSample = 5
+
+// PreviewAdd reports what a Set of key would do to a full cache without
+// setting anything: the hashes of the entries it would evict, as OnEvict
+// sees them, and whether the policy would admit key. Callers whose values
+// are expensive to build or serialize can skip building one that would be
+// rejected, or that would push out entries they value more.
+//
+// cost is what the value would be charged, as Config.Cost would price it;
+// cost <= 0 means the default cost of 1. A key already resident is an
+// update, which always succeeds and evicts nothing.
+//
+// The answer is an estimate. It replays the policy's sampled LFU on the
+// frequencies WithSnapshots keeps, which count Gets that hit and Sets, not
+// every access, and ristretto samples its candidates at random, so the
+// actual Set may pick other victims. As in ristretto, an add that is
+// rejected still evicts the victims picked before the rejection. Victim
+// costs come from the WithCostAudit ledger, or are taken as the average
+// entry cost without it when Config.Cost is set. The room left is read
+// from metrics, on by default; without them the value is assumed to fit.
+// Without WithSnapshots the cache knows no frequencies, and an add that
+// needs evictions is reported as rejected.
+func (c *Cache[K, V]) PreviewAdd(key K, cost int64) (victims []uint64, wouldAdmit bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return nil, false
+	}
+
+	if cost <= 0 {
+		cost = defaultCost
+	}
+	cost += c.internal
+	if cost > c.inner.MaxCost() {
+		return nil, false
+	}
+
+	h := hashKey(key)
+	if _, resident := c.inner.GetTTL(h); resident {
+		return nil, true
+	}
+
+	m := c.metrics
+	if m == nil {
+		return nil, true
+	}
+	used := int64(m.CostAdded() - m.CostEvicted())
+	room := c.inner.MaxCost() - used - cost
+	if room >= 0 {
+		return nil, true
+	}
+	if c.index == nil {
+		return nil, false
+	}
+
+	avg := used
+	if keys := int64(m.KeysAdded() - m.KeysEvicted()); keys > 0 {
+		avg = used / keys
+	}
+	return c.index.previewEvict(h, room, func(victim uint64) int64 {
+		if c.ledger != nil {
+			if cost, ok := c.ledger.costs.Get(victim); ok {
+				return cost
+			}
+		}
+		if c.costFn == nil {
+			return defaultCost + c.internal
+		}
+		return avg
+	})
+}
+
+// previewEvict replays the policy's eviction for incoming key h with room
+// left (negative), without evicting anything: while room is short it takes
+// the least frequent of lfuSample resident keys as a victim, unless h is
+// less frequent, which rejects it. costOf returns a victim's cost.
+func (x *keyIndex[K]) previewEvict(h uint64, room int64, costOf func(uint64) int64) ([]uint64, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	incHits := x.freq.Estimate(h)
+	seen := make(map[uint64]struct{})
+	sample := make([]indexedKey[K], 0, lfuSample)
+	var victims []uint64
+	for room < 0 {
+		for kh := range x.entries {
+			if len(sample) >= lfuSample {
+				break
+			}
+			if _, ok := seen[kh]; ok || kh == h {
+				continue
+			}
+			seen[kh] = struct{}{}
+			sample = append(sample, indexedKey[K]{hash: kh, freq: x.freq.Estimate(kh)})
+		}
+		if len(sample) == 0 {
+			return victims, false
+		}
+
+		minIdx := 0
+		for i, k := range sample {
+			if k.freq < sample[minIdx].freq {
+				minIdx = i
+			}
+		}
+		if incHits < sample[minIdx].freq {
+			return victims, false
+		}
+
+		victim := sample[minIdx].hash
+		victims = append(victims, victim)
+		room += costOf(victim)
+		sample[minIdx] = sample[len(sample)-1]
+		sample = sample[:len(sample)-1]
+	}
+	return victims, true
+}
@@ -0,0 +1,22 @@
+package ristretto
+
+// SetFromBytes decodes raw, as read off the network, and sets the value
+// under key without TTL, charging it Config.CostFromRaw when set, so the
+// cost reflects the decoded value rather than the configured default. It
+// returns decode's error, without setting anything, if decode fails.
+//
+// raw is only read during the call: decode must copy whatever the value
+// keeps of it, so callers can reuse their read buffer.
+func (c *Cache[K, V]) SetFromBytes(key K, raw []byte, decode func([]byte) (V, error)) (bool, error) {
+	value, err := decode(raw)
+	if err != nil {
+		return false, err
+	}
+
+	var cost int64
+	if c.rawFn != nil {
+		cost = c.rawFn(len(raw), value)
+	}
+	ok, _ := c.set(key, value, cost, 0, nil)
+	return ok, nil
+}
@@ -1,20 +1,61 @@
 package ristretto
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
 
 	"github.com/huynhanx03/go-common/pkg/common/cache"
 	"github.com/huynhanx03/go-common/pkg/hash"
+	"github.com/huynhanx03/go-common/pkg/mq/batcher"
+	"github.com/huynhanx03/go-common/pkg/timer"
 )
 
-// defaultCost is used for all ristretto Set/SetWithTTL calls.
+// defaultCost is charged for every entry unless Config.Cost is set.
 const defaultCost int64 = 1
 
-// Cache wraps *ristretto.Cache and implements cache.LocalCache[K, V].
+// Cache wraps *ristretto.Cache, or an inline engine running the same policy
+// with Config.Synchronous, and implements cache.LocalCache[K, V].
+//
+// Ordering relative to Close: operations that started before Close complete
+// normally and their Sets are applied before the cache shuts down. Once Close
+// has started, Gets miss, Deletes are no-ops and Sets are reported to OnDrop.
 type Cache[K any, V any] struct {
-	inner *ristretto.Cache
+	inner   engine
+	metrics engineMetrics // nil when Config.Metrics is off
+	onDrop  func(key, value any)
+	jitter  float64
+
+	mu     sync.RWMutex // held shared by operations, exclusively by Close
+	closed bool
+
+	nsMu       sync.Mutex
+	namespaces map[string]*namespace
+
+	index *keyIndex[K] // nil unless Config.Snapshots
+
+	tags *tagIndex
+
+	graves *graveyard
+	clock  timer.Timer // Config.ClockGranularity, else exact time
+
+	comp   *compression         // nil unless Config.Compressor
+	costFn func(any) int64      // Config.Cost, nil to charge defaultCost
+	rawFn  func(int, any) int64 // Config.CostFromRaw
+
+	// internal is what ristretto adds to every cost: internalCost, or 0
+	// with IgnoreInternalCost.
+	internal int64
+
+	evicts *evictBatcher // nil unless Config.OnEvictBatch
+	ledger *costLedger   // nil unless Config.CostAudit
+
+	tracer *batcher.StripedBatcher[TraceEvent] // nil unless Config.Trace
+	shadow *shadow                             // nil unless Config.Shadow
+
+	sync bool // Config.Synchronous
 }
 
 var _ cache.LocalCache[string, any] = (*Cache[string, any])(nil)
@@ -28,72 +69,409 @@ func New[K any, V any](opts ...Option) (*Cache[K, V], error) {
 		opt(&cfg)
 	}
 
-	inner, err := ristretto.NewCache(&cfg)
+	var evicts *evictBatcher
+	if cfg.OnEvictBatch != nil {
+		evicts = newEvictBatcher(cfg.OnEvictBatch, cfg.EvictBatchSize, cfg.EvictBufferSize, cfg.Synchronous)
+		wrapEvictBatch(&cfg, evicts)
+	}
+
+	graves := newGraveyard()
+	wrapTombstoneCallbacks(&cfg, graves)
+
+	var comp *compression
+	if cfg.Compressor != nil {
+		var err error
+		if comp, err = newCompression[V](cfg.Compressor, cfg.CompressThreshold); err != nil {
+			if evicts != nil {
+				evicts.close()
+			}
+			return nil, err
+		}
+		wrapCompressionCallbacks[V](&cfg, comp)
+	}
+	wrapNamespaceCallbacks(&cfg)
+
+	tags := newTagIndex()
+	wrapTagCallbacks(&cfg, tags)
+
+	var ledger *costLedger
+	if cfg.CostAudit {
+		ledger = newCostLedger(cfg.IgnoreInternalCost)
+		wrapLedgerCallbacks(&cfg, ledger)
+	}
+
+	var index *keyIndex[K]
+	if cfg.Snapshots {
+		index = newKeyIndex[K](cfg.NumCounters, cfg.Synchronous)
+		wrapIndexCallbacks(&cfg, index)
+	}
+
+	clock := timer.Timer(timer.SystemTimer{})
+	if cfg.ClockGranularity > 0 {
+		clock = timer.Shared(cfg.ClockGranularity)
+	}
+
+	var shadow *shadow
+	if cfg.Shadow != nil {
+		var err error
+		if shadow, err = newShadow(&cfg, clock); err != nil {
+			clock.Stop()
+			if evicts != nil {
+				evicts.close()
+			}
+			return nil, err
+		}
+	}
+
+	var settle func()
+	if evicts != nil {
+		settle = evicts.flush
+	}
+	inner, metrics, err := newEngine(&cfg, settle, clock)
 	if err != nil {
+		clock.Stop()
+		if evicts != nil {
+			evicts.close()
+		}
 		return nil, err
 	}
 
+	internal := internalCost
+	if cfg.IgnoreInternalCost {
+		internal = 0
+	}
+
 	return &Cache[K, V]{
-		inner: inner,
+		inner:      inner,
+		metrics:    metrics,
+		sync:       cfg.Synchronous,
+		onDrop:     cfg.OnDrop,
+		jitter:     cfg.TTLJitterFraction,
+		namespaces: make(map[string]*namespace),
+		index:      index,
+		tags:       tags,
+		graves:     graves,
+		clock:      clock,
+		comp:       comp,
+		costFn:     cfg.Cost,
+		rawFn:      cfg.CostFromRaw,
+		internal:   internal,
+		evicts:     evicts,
+		ledger:     ledger,
+		tracer:     newTracer(cfg.Trace),
+		shadow:     shadow,
 	}, nil
 }
 
+// wrapIndexCallbacks keeps the snapshot index in step with evictions and
+// rejections.
+func wrapIndexCallbacks[K any](cfg *Config, index *keyIndex[K]) {
+	evict := cfg.OnEvict
+	cfg.OnEvict = func(item *ristretto.Item) {
+		index.remove(item.Key)
+		evict(item)
+	}
+
+	reject := cfg.OnReject
+	cfg.OnReject = func(item *ristretto.Item) {
+		index.remove(item.Key)
+		if reject != nil {
+			reject(item)
+		}
+	}
+}
+
+// wrapNamespaceCallbacks installs the callbacks that account for namespaced
+// entries and unwraps them before user callbacks see them.
+func wrapNamespaceCallbacks(cfg *Config) {
+	userEvict := cfg.OnEvict
+	cfg.OnEvict = func(item *ristretto.Item) {
+		item, ns := unwrapItem(item)
+		if ns != nil {
+			ns.evictions.Add(1)
+		}
+		if userEvict != nil {
+			userEvict(item)
+		}
+	}
+
+	if userReject := cfg.OnReject; userReject != nil {
+		cfg.OnReject = func(item *ristretto.Item) {
+			item, _ = unwrapItem(item)
+			userReject(item)
+		}
+	}
+
+	if fn := cfg.Cost; fn != nil {
+		cfg.Cost = func(val any) int64 {
+			if e, ok := val.(nsEntry); ok {
+				return fn(e.value)
+			}
+			return fn(val)
+		}
+	}
+
+	userExit := cfg.OnExit
+	cfg.OnExit = func(val any) {
+		e, ok := val.(nsEntry)
+		if ok {
+			e.gen.used.Add(-e.cost)
+			e.gen.keys.Add(-1)
+			val = e.value
+		}
+		if userExit != nil {
+			userExit(val)
+		}
+	}
+}
+
 // hashKey converts a generic key to the uint64 that ristretto expects.
 func hashKey[K any](key K) uint64 {
 	h, _ := hash.KeyToHash(key)
 	return h
 }
 
-// Get retrieves a value from the cache.
-func (c *Cache[K, V]) Get(key K) (V, bool) {
-	val, ok := c.inner.Get(hashKey(key))
-	if !ok {
+// decode converts a stored value back to V, decompressing it if needed.
+func (c *Cache[K, V]) decode(val any) (V, bool) {
+	if _, dead := val.(tombstone); dead {
 		var zero V
 		return zero, false
 	}
+	if c.comp == nil {
+		typed, ok := val.(V)
+		return typed, ok
+	}
+	return decode[V](c.comp, val)
+}
+
+// cost is the cost passed to ristretto: 0 lets Config.Cost price the stored
+// form of the value.
+func (c *Cache[K, V]) cost() int64 {
+	if c.costFn != nil {
+		return 0
+	}
+	return defaultCost
+}
 
-	typed, ok := val.(V)
-	if !ok {
+// drop reports a Set that was not applied.
+func (c *Cache[K, V]) drop(key K, value V) {
+	if c.onDrop != nil {
+		c.onDrop(key, value)
+	}
+}
+
+// Get retrieves a value from the cache.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
 		var zero V
 		return zero, false
 	}
-	return typed, true
+
+	h := hashKey(key)
+	val, ok := c.inner.Get(h)
+	c.trace(TraceGet, h, 0)
+	if c.index != nil {
+		// Misses count too, as in the policy's own sketch, so PreviewAdd
+		// sees how often a key that is not cached is asked for.
+		c.index.touch(h)
+	}
+
+	var v V
+	if ok {
+		v, ok = c.decode(val)
+	}
+	if c.shadow != nil {
+		c.shadow.get(h, ok)
+	}
+	return v, ok
 }
 
 // Set adds or updates a value without TTL.
 func (c *Cache[K, V]) Set(key K, value V) bool {
-	ok := c.inner.Set(hashKey(key), value, defaultCost)
-	c.inner.Wait()
-	return ok
+	return c.SetWithTTL(key, value, 0)
 }
 
-// SetWithTTL adds or updates a value with a TTL.
+// SetWithTTL adds or updates a value with a TTL. The effective TTL is
+// jittered when Config.TTLJitterFraction is set.
 func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) bool {
-	ok := c.inner.SetWithTTL(hashKey(key), value, defaultCost, ttl)
-	c.inner.Wait()
+	ok, _ := c.set(key, value, 0, cache.JitterTTL(ttl, c.jitter), nil)
 	return ok
 }
 
+// set applies a Set with the final TTL, charging cost or the configured cost
+// if cost <= 0, and replaces the entry's tags. It returns ErrClosed once Close
+// has started.
+func (c *Cache[K, V]) set(key K, value V, cost int64, ttl time.Duration, tags []string) (bool, error) {
+	return c.setHashed(hashKey(key), nil, nil, key, value, cost, ttl, tags)
+}
+
+// setHashed is set for the key hashed to h. With a namespace it stores the
+// value in generation g, charged against the namespace's cost share, and
+// leaves the entry out of the snapshot index.
+func (c *Cache[K, V]) setHashed(h uint64, ns *namespace, g *nsGeneration, key K, value V, cost int64, ttl time.Duration, tags []string) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		c.drop(key, value)
+		return false, ErrClosed
+	}
+
+	if c.graves.live(h, c.clock.Now()) {
+		c.drop(key, value)
+		return false, nil
+	}
+
+	stored := any(value)
+	if c.comp != nil {
+		stored = encode(c.comp, value)
+	}
+
+	if cost <= 0 {
+		cost = c.cost()
+	}
+	charged := cost
+	if charged == 0 && (c.tracer != nil || c.shadow != nil || ns != nil) {
+		charged = c.costFn(stored)
+	}
+	if ns != nil {
+		if !c.fitsShare(ns, g, h, charged) {
+			c.drop(key, value)
+			return false, nil
+		}
+		stored = nsEntry{gen: g, ns: ns, value: stored, cost: charged}
+		cost = charged
+	}
+	c.trace(TraceSet, h, charged)
+	if c.shadow != nil {
+		c.shadow.set(h, charged, ttl)
+	}
+
+	ok := c.inner.SetWithTTL(h, stored, cost, ttl)
+	if ok && ns != nil {
+		// Released by the OnExit hook when the entry leaves the cache.
+		g.used.Add(charged)
+		g.keys.Add(1)
+	}
+	c.inner.Wait()
+	if !ok && ttl >= 0 {
+		c.drop(key, value)
+	}
+	if ok {
+		c.recordCost(h, stored, cost)
+	}
+	if ok && ((c.index != nil && ns == nil) || len(tags) > 0 || c.tags.used.Load()) {
+		// The policy may have rejected the Set while we waited.
+		if _, resident := c.inner.GetTTL(h); resident {
+			if c.index != nil && ns == nil {
+				c.index.add(h, key, stored)
+			}
+			c.tags.set(h, tags)
+		}
+	}
+	return ok, nil
+}
+
 // Delete removes a value from the cache.
 func (c *Cache[K, V]) Delete(key K) {
-	c.inner.Del(hashKey(key))
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return
+	}
+	h := hashKey(key)
+	c.inner.Del(h)
+	c.trace(TraceDelete, h, 0)
+	if c.shadow != nil {
+		c.shadow.engine.Del(h)
+	}
+	c.forgetCost(h)
+	c.tags.remove(h)
+	if c.index != nil {
+		c.index.remove(h)
+	}
 }
 
 // Clear removes all items from the cache.
 func (c *Cache[K, V]) Clear() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return
+	}
 	c.inner.Clear()
+	if c.shadow != nil {
+		c.shadow.engine.Clear()
+	}
+	if c.ledger != nil {
+		c.ledger.costs.Clear()
+	}
+	c.tags.clear()
+	c.graves.deadlines.Clear()
+	if c.index != nil {
+		c.index.clear()
+	}
 }
 
-// Close gracefully shuts down the cache.
+// Close gracefully shuts down the cache. See CloseContext.
 func (c *Cache[K, V]) Close() {
-	c.inner.Close()
+	_ = c.CloseContext(context.Background())
+}
+
+// CloseContext shuts down the cache. It waits for in-flight operations,
+// flushes the set buffer so every accepted Set is applied, then stops the
+// cache; resident items are reported to OnEvict, and OnEvictBatch has
+// received every eviction once the shutdown completes. If ctx expires before the
+// flush completes, CloseContext returns ctx.Err() and the shutdown finishes
+// in the background. Calling it more than once is a no-op.
+func (c *Cache[K, V]) CloseContext(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	shutdown := func() {
+		c.inner.Wait()
+		c.inner.Close()
+		if c.shadow != nil {
+			c.shadow.engine.Close()
+		}
+		if c.evicts != nil {
+			c.evicts.close()
+		}
+		if c.tracer != nil {
+			c.tracer.Close()
+		}
+		c.clock.Stop()
+	}
+	if c.sync {
+		shutdown()
+		return nil
+	}
+
+	flushed := make(chan struct{})
+	go func() {
+		shutdown()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // Stats returns a snapshot of cache statistics, sourced from ristretto's
 // metrics (enabled by DefaultConfig). Zero when metrics are disabled.
 func (c *Cache[K, V]) Stats() cache.Stats {
 	var s cache.Stats
-	if m := c.inner.Metrics; m != nil {
+	if m := c.metrics; m != nil {
 		s.Hits = int64(m.Hits())
 		s.Misses = int64(m.Misses())
 		s.Evictions = int64(m.KeysEvicted())
@@ -1,9 +1,22 @@
 package ristretto
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/ristretto"
+
 	"github.com/huynhanx03/go-common/pkg/common/cache"
 )
 
@@ -49,6 +62,48 @@ func TestSetWithTTLExpires(t *testing.T) {
 	}
 }
 
+func TestTTLJitterWithinBound(t *testing.T) {
+	const (
+		ttl      = time.Hour
+		fraction = 0.2
+		n        = 200
+	)
+	for _, synchronous := range []bool{false, true} {
+		opts := []Option{WithTTLJitter(fraction)}
+		if synchronous {
+			opts = append(opts, WithSynchronous())
+		}
+		c, err := New[int, int](opts...)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+
+		lo, hi := time.Duration(math.MaxInt64), time.Duration(0)
+		for i := range n {
+			if !c.SetWithTTL(i, i, ttl) {
+				t.Fatalf("SetWithTTL(%d) returned false", i)
+			}
+			_, info, ok := c.GetWithInfo(i)
+			if !ok {
+				t.Fatalf("key %d missing right after SetWithTTL", i)
+			}
+			lo, hi = min(lo, info.TTL), max(hi, info.TTL)
+		}
+		c.Close()
+
+		// Allow a second for the time spent between Set and GetWithInfo.
+		minTTL := time.Duration(float64(ttl)*(1-fraction)) - time.Second
+		maxTTL := time.Duration(float64(ttl) * (1 + fraction))
+		if lo < minTTL || hi > maxTTL {
+			t.Errorf("synchronous=%v: TTLs in [%v, %v], want within [%v, %v]", synchronous, lo, hi, minTTL, maxTTL)
+		}
+		// 200 uniform draws all landing in the middle half is vanishingly rare.
+		if lo > ttl-ttl/20 || hi < ttl+ttl/20 {
+			t.Errorf("synchronous=%v: TTLs in [%v, %v] are not spread around %v", synchronous, lo, hi, ttl)
+		}
+	}
+}
+
 func TestClear(t *testing.T) {
 	c := newTestCache(t)
 
@@ -88,3 +143,1367 @@ func TestStats(t *testing.T) {
 		t.Errorf("Stats = %+v, want hits/misses/keycount >= 1", s)
 	}
 }
+
+func TestCloseEvictsResidentAndDropsLateSets(t *testing.T) {
+	var evicted, dropped atomic.Int64
+	c, err := New[string, any](
+		WithOnEvict(func(*ristretto.Item) { evicted.Add(1) }),
+		WithOnDrop(func(key, _ any) {
+			if key != "late" {
+				t.Errorf("OnDrop key = %v, want late", key)
+			}
+			dropped.Add(1)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	if err := c.CloseContext(context.Background()); err != nil {
+		t.Fatalf("CloseContext: %v", err)
+	}
+	if got := evicted.Load(); got != 2 {
+		t.Errorf("evicted = %d, want 2", got)
+	}
+
+	if c.Set("late", 3) {
+		t.Error("Set after Close returned true")
+	}
+	if got := dropped.Load(); got != 1 {
+		t.Errorf("dropped = %d, want 1", got)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get after Close reported a hit")
+	}
+
+	// Second close is a no-op.
+	if err := c.CloseContext(context.Background()); err != nil {
+		t.Fatalf("second CloseContext: %v", err)
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	c := newTestCache(t)
+	users := c.Namespace("users")
+	orders := c.Namespace("orders")
+
+	users.Set("1", "alice")
+	orders.Set("1", "order-1")
+	c.Set("1", "root")
+
+	if v, ok := users.Get("1"); !ok || v != "alice" {
+		t.Fatalf("users.Get = %v, %v", v, ok)
+	}
+	if v, ok := orders.Get("1"); !ok || v != "order-1" {
+		t.Fatalf("orders.Get = %v, %v", v, ok)
+	}
+	if v, ok := c.Get("1"); !ok || v != "root" {
+		t.Fatalf("root Get = %v, %v", v, ok)
+	}
+
+	// Views of the same name share entries.
+	if v, ok := c.Namespace("users").Get("1"); !ok || v != "alice" {
+		t.Fatalf("second users view Get = %v, %v", v, ok)
+	}
+
+	users.Delete("1")
+	if _, ok := users.Get("1"); ok {
+		t.Fatal("users key survived Delete")
+	}
+	if _, ok := orders.Get("1"); !ok {
+		t.Fatal("Delete leaked into another namespace")
+	}
+}
+
+func TestNamespaceMaxCostShare(t *testing.T) {
+	var dropped atomic.Int64
+	c, err := New[string, any](WithOnDrop(func(_, _ any) { dropped.Add(1) }))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(c.Close)
+
+	ns := c.Namespace("small", WithMaxCostShare(2))
+	ns.Set("a", 1)
+	ns.Set("b", 2)
+	if ns.Set("c", 3) {
+		t.Fatal("Set beyond the share returned true")
+	}
+	if dropped.Load() != 1 {
+		t.Errorf("dropped = %d, want 1", dropped.Load())
+	}
+
+	// Updating a resident key is allowed at the cap.
+	if !ns.Set("a", 10) {
+		t.Fatal("update at the cap returned false")
+	}
+	if got := ns.Stats().CostUsed; got != 2 {
+		t.Errorf("CostUsed = %d, want 2", got)
+	}
+
+	// Deleting frees room.
+	ns.Delete("b")
+	if !ns.Set("c", 3) {
+		t.Fatal("Set after Delete returned false")
+	}
+
+	// Other namespaces are unaffected by the share.
+	other := c.Namespace("big")
+	for i := range 5 {
+		if !other.Set(string(rune('a'+i)), i) {
+			t.Fatalf("other.Set %d returned false", i)
+		}
+	}
+}
+
+func TestNamespaceShareChargesCost(t *testing.T) {
+	c, err := New[string, string](WithCost(func(v any) int64 { return int64(len(v.(string))) }))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(c.Close)
+
+	ns := c.Namespace("ns", WithMaxCostShare(10))
+	if !ns.Set("a", "12345") || !ns.Set("b", "1234") {
+		t.Fatal("Sets within the share returned false")
+	}
+	if ns.Set("c", "12") {
+		t.Fatal("Set beyond the share by cost returned true")
+	}
+	if st := ns.Stats(); st.CostUsed != 9 || st.KeyCount != 2 {
+		t.Errorf("CostUsed, KeyCount = %d, %d; want 9, 2", st.CostUsed, st.KeyCount)
+	}
+
+	// Replacing a resident entry frees its cost first.
+	if !ns.Set("a", "123456") {
+		t.Fatal("growing update within the share returned false")
+	}
+	if ns.Set("a", "1234567") {
+		t.Fatal("growing update beyond the share returned true")
+	}
+	if st := ns.Stats(); st.CostUsed != 10 || st.KeyCount != 2 {
+		t.Errorf("after updates CostUsed, KeyCount = %d, %d; want 10, 2", st.CostUsed, st.KeyCount)
+	}
+}
+
+func TestNamespaceCompression(t *testing.T) {
+	var exits atomic.Int64
+	c, err := New[string, []byte](
+		WithCompression(rle{}, 0),
+		WithCost(func(v any) int64 { return int64(len(v.([]byte))) }),
+		func(cfg *Config) {
+			cfg.OnExit = func(v any) {
+				if len(v.([]byte)) == 4096 {
+					exits.Add(1)
+				}
+			}
+		},
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(c.Close)
+
+	ns := c.Namespace("ns")
+	big := bytes.Repeat([]byte{'a'}, 4096)
+	ns.Set("big", big)
+	if v, ok := ns.Get("big"); !ok || !bytes.Equal(v, big) {
+		t.Fatalf("Get(big) = %d bytes, %v", len(v), ok)
+	}
+	if got := c.CompressionStats(); got.Compressed != 1 {
+		t.Errorf("CompressionStats = %+v, want the namespaced value compressed", got)
+	}
+	if used := ns.Stats().CostUsed; used <= 0 || used >= 4096 {
+		t.Errorf("CostUsed = %d, want the compressed size", used)
+	}
+
+	ns.Set("big", []byte("replaced"))
+	if exits.Load() != 1 {
+		t.Errorf("OnExit saw %d decompressed values, want 1", exits.Load())
+	}
+}
+
+func TestNamespaceClear(t *testing.T) {
+	c := newTestCache(t)
+	a := c.Namespace("a", WithMaxCostShare(1))
+	b := c.Namespace("b")
+
+	a.Set("k", 1)
+	b.Set("k", 2)
+	a.Clear()
+
+	if _, ok := a.Get("k"); ok {
+		t.Fatal("key survived namespace Clear")
+	}
+	if _, ok := b.Get("k"); !ok {
+		t.Fatal("Clear leaked into another namespace")
+	}
+	if !a.Set("k2", 3) {
+		t.Fatal("cleared entries still count against the share")
+	}
+}
+
+func TestNamespaceCallbacksSeeRawValues(t *testing.T) {
+	var evicted atomic.Value
+	c, err := New[string, any](WithOnEvict(func(item *ristretto.Item) { evicted.Store(item.Value) }))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	c.Namespace("ns").Set("k", "v")
+	c.Close()
+
+	if got := evicted.Load(); got != "v" {
+		t.Errorf("OnEvict value = %#v, want \"v\"", got)
+	}
+}
+
+func TestWithRistrettoConfig(t *testing.T) {
+	legacy := func(cfg *ristretto.Config) { cfg.MaxCost = 1234 }
+	c, err := New[string, int](WithRistrettoConfig(legacy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if got := c.inner.MaxCost(); got != 1234 {
+		t.Errorf("MaxCost = %d, want 1234", got)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	src, err := New[string, int](WithSnapshots(), WithNumCounters(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	src.Set("cold", 1)
+	src.Set("hot", 2)
+	src.SetWithTTL("ttl", 3, time.Hour)
+	for range 10 {
+		src.Get("hot")
+	}
+	src.Set("gone", 4)
+	src.Delete("gone")
+
+	var buf bytes.Buffer
+	n, err := src.Export(&buf, 0)
+	if err != nil || n != 3 {
+		t.Fatalf("Export = %d, %v; want 3 entries", n, err)
+	}
+	first, _, _ := strings.Cut(buf.String(), "\n")
+	if !strings.Contains(first, `"hot"`) {
+		t.Errorf("first record %s, want the hottest key", first)
+	}
+
+	dst, err := New[string, int](WithNumCounters(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+
+	if n, err := dst.Import(&buf); err != nil || n != 3 {
+		t.Fatalf("Import = %d, %v; want 3 entries", n, err)
+	}
+	for k, want := range map[string]int{"cold": 1, "hot": 2, "ttl": 3} {
+		if v, ok := dst.Get(k); !ok || v != want {
+			t.Errorf("Get(%q) = %v, %v; want %d", k, v, ok, want)
+		}
+	}
+	if ttl, ok := dst.inner.GetTTL(hashKey("ttl")); !ok || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("imported TTL = %v, want ~1h", ttl)
+	}
+	if _, ok := dst.inner.GetTTL(hashKey("gone")); ok {
+		t.Error("deleted key was exported")
+	}
+}
+
+func TestExportLimitAndDisabled(t *testing.T) {
+	c, err := New[string, int](WithSnapshots())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for i := range 5 {
+		c.Set(strconv.Itoa(i), i)
+	}
+
+	if n, err := c.Export(io.Discard, 2); err != nil || n != 2 {
+		t.Errorf("Export(max 2) = %d, %v", n, err)
+	}
+
+	plain := newTestCache(t)
+	if _, err := plain.Export(io.Discard, 0); !errors.Is(err, ErrSnapshotsDisabled) {
+		t.Errorf("Export without snapshots = %v, want ErrSnapshotsDisabled", err)
+	}
+}
+
+// writerFunc adapts a function to io.Writer.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func TestExportLeavesStatsAndLock(t *testing.T) {
+	c, err := New[string, int](WithSnapshots())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 5 {
+		c.Set(strconv.Itoa(i), i)
+	}
+	c.Get("0")
+
+	before := c.Stats()
+	if n, err := c.Export(io.Discard, 0); err != nil || n != 5 {
+		t.Fatalf("Export = %d, %v; want 5 entries", n, err)
+	}
+	if after := c.Stats(); after.Hits != before.Hits || after.Misses != before.Misses {
+		t.Errorf("Export changed stats: %+v -> %+v", before, after)
+	}
+
+	// Close needs the write lock; it must not wait for a slow writer.
+	closed := make(chan struct{})
+	slow := writerFunc(func(p []byte) (int, error) {
+		go func() {
+			c.Close()
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Error("Close blocked behind Export's writer")
+		}
+		return len(p), nil
+	})
+	if _, err := c.Export(slow, 1); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+}
+
+func TestSnapshotView(t *testing.T) {
+	c, err := New[string, []byte](
+		WithSnapshots(),
+		WithNumCounters(1000),
+		WithCostAudit(),
+		WithCost(func(v any) int64 { return int64(len(v.([]byte))) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.Set("cold", make([]byte, 10))
+	c.SetWithTTL("hot", make([]byte, 20), time.Hour)
+	for range 5 {
+		c.Get("hot")
+	}
+	hits := c.Stats().Hits
+
+	v, err := c.Snapshot(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Entries) != 2 || v.Entries[0].Key != "hot" {
+		t.Fatalf("Entries = %+v, want hot first of 2", v.Entries)
+	}
+	hot := v.Entries[0]
+	if hot.Cost != 20 || hot.TTL <= 0 || hot.TTL > time.Hour || hot.Frequency < 5 {
+		t.Errorf("hot entry = %+v", hot)
+	}
+	if v.Entries[1].Cost != 10 || v.Entries[1].TTL != 0 {
+		t.Errorf("cold entry = %+v", v.Entries[1])
+	}
+	if v.Stats.KeyCount != 2 || c.Stats().Hits != hits {
+		t.Errorf("Stats = %+v, hits %d -> %d; want 2 keys and no hits counted", v.Stats, hits, c.Stats().Hits)
+	}
+
+	if v, _ := c.Snapshot(1); len(v.Entries) != 1 {
+		t.Errorf("Snapshot(1) returned %d entries", len(v.Entries))
+	}
+	if _, err := newTestCache(t).Snapshot(0); !errors.Is(err, ErrSnapshotsDisabled) {
+		t.Errorf("Snapshot without snapshots = %v, want ErrSnapshotsDisabled", err)
+	}
+}
+
+func TestSnapshotViewUnknownCost(t *testing.T) {
+	c, _ := New[string, []byte](
+		WithSnapshots(),
+		WithCost(func(v any) int64 { return int64(len(v.([]byte))) }),
+	)
+	defer c.Close()
+	c.Set("k", []byte("abc"))
+	v, _ := c.Snapshot(0)
+	if len(v.Entries) != 1 || v.Entries[0].Cost != -1 {
+		t.Errorf("Entries = %+v, want cost -1 without a ledger", v.Entries)
+	}
+}
+
+func TestPreviewAdd(t *testing.T) {
+	c, err := New[string, []byte](
+		WithSnapshots(),
+		WithCostAudit(),
+		WithMaxCost(300),
+		WithNumCounters(1000),
+		WithCost(func(v any) int64 { return int64(len(v.([]byte))) }),
+		func(cfg *Config) { cfg.IgnoreInternalCost = true },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if victims, ok := c.PreviewAdd("a", 100); !ok || victims != nil {
+		t.Errorf("PreviewAdd on an empty cache = %v, %v; want nil, true", victims, ok)
+	}
+	for _, k := range []string{"a", "b", "c"} {
+		c.Set(k, make([]byte, 100))
+	}
+	for range 5 {
+		c.Get("a")
+		c.Get("b")
+	}
+
+	if victims, ok := c.PreviewAdd("new", 100); ok || len(victims) != 0 {
+		t.Errorf("PreviewAdd of an unseen key = %v, %v; want rejected", victims, ok)
+	}
+	for range 3 {
+		c.Get("new") // misses raise its frequency above the cold entry's
+	}
+	victims, ok := c.PreviewAdd("new", 100)
+	if !ok || len(victims) != 1 || victims[0] != hashKey("c") {
+		t.Errorf("PreviewAdd = %v, %v; want [hash(c)], true", victims, ok)
+	}
+
+	if victims, ok := c.PreviewAdd("a", 100); !ok || victims != nil {
+		t.Errorf("PreviewAdd of a resident key = %v, %v; want nil, true", victims, ok)
+	}
+	if _, ok := c.PreviewAdd("huge", 301); ok {
+		t.Error("PreviewAdd admitted a value costlier than the cache")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("PreviewAdd evicted an entry")
+	}
+}
+
+func TestPreviewAddWithoutSnapshots(t *testing.T) {
+	c, _ := New[string, int](WithMaxCost(2), func(cfg *Config) { cfg.IgnoreInternalCost = true })
+	defer c.Close()
+	c.Set("a", 1)
+	if _, ok := c.PreviewAdd("b", 1); !ok {
+		t.Error("PreviewAdd with room = false, want true")
+	}
+	c.Set("b", 2)
+	if victims, ok := c.PreviewAdd("c", 1); ok || victims != nil {
+		t.Errorf("PreviewAdd on a full cache = %v, %v; want nil, false", victims, ok)
+	}
+}
+
+func TestGetWithInfo(t *testing.T) {
+	c, err := New[string, string](WithSnapshots())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.SetWithTTL("ttl", "a", time.Hour)
+	c.Set("forever", "b")
+
+	v, info, ok := c.GetWithInfo("ttl")
+	if !ok || v != "a" {
+		t.Fatalf("GetWithInfo(ttl) = %q, %v", v, ok)
+	}
+	if info.TTL <= 59*time.Minute || info.TTL > time.Hour {
+		t.Errorf("TTL = %v, want ~1h", info.TTL)
+	}
+	if info.Cost != defaultCost {
+		t.Errorf("Cost = %d, want %d", info.Cost, defaultCost)
+	}
+	if !info.ExpiresWithin(2*time.Hour) || info.ExpiresWithin(time.Minute) {
+		t.Errorf("ExpiresWithin wrong for TTL %v", info.TTL)
+	}
+
+	c.Get("forever")
+	c.Get("forever")
+	_, info, ok = c.GetWithInfo("forever")
+	if !ok || info.TTL != 0 || info.ExpiresWithin(time.Hour) {
+		t.Errorf("forever: ok=%v info=%+v", ok, info)
+	}
+	// Set, two Gets and this call.
+	if info.Frequency < 4 {
+		t.Errorf("Frequency = %d, want >= 4", info.Frequency)
+	}
+
+	if _, _, ok := c.GetWithInfo("missing"); ok {
+		t.Error("GetWithInfo(missing) = ok")
+	}
+}
+
+func TestGetWithInfoWithoutSnapshots(t *testing.T) {
+	c, err := New[string, int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.Set("k", 1)
+	_, info, ok := c.GetWithInfo("k")
+	if !ok || info.Frequency != -1 {
+		t.Errorf("ok=%v Frequency=%d, want -1", ok, info.Frequency)
+	}
+}
+
+func TestTouchAndPersist(t *testing.T) {
+	c, err := New[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.SetWithTTL("session", "s", 50*time.Millisecond)
+	if !c.Touch("session", time.Hour) {
+		t.Fatal("Touch(session) = false")
+	}
+	if ttl, ok := c.inner.GetTTL(hashKey("session")); !ok || ttl <= 59*time.Minute {
+		t.Errorf("TTL after Touch = %v, %v, want ~1h", ttl, ok)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if v, ok := c.Get("session"); !ok || v != "s" {
+		t.Fatalf("Get after Touch = %q, %v", v, ok)
+	}
+
+	if !c.Persist("session") {
+		t.Fatal("Persist(session) = false")
+	}
+	if ttl, ok := c.inner.GetTTL(hashKey("session")); !ok || ttl != 0 {
+		t.Errorf("TTL after Persist = %v, %v, want 0", ttl, ok)
+	}
+
+	if c.Touch("missing", time.Hour) || c.Persist("missing") {
+		t.Error("Touch/Persist on missing key = true")
+	}
+	if c.Touch("session", 0) {
+		t.Error("Touch with zero TTL = true")
+	}
+}
+
+// rle is a run-length Compressor for tests: (count, byte) pairs.
+type rle struct{}
+
+func (rle) Compress(dst, src []byte) []byte {
+	for i := 0; i < len(src); {
+		j := i + 1
+		for j < len(src) && src[j] == src[i] && j-i < 255 {
+			j++
+		}
+		dst = append(dst, byte(j-i), src[i])
+		i = j
+	}
+	return dst
+}
+
+func (rle) Decompress(dst, src []byte) ([]byte, error) {
+	if len(src)%2 != 0 {
+		return nil, errors.New("rle: odd length")
+	}
+	for i := 0; i < len(src); i += 2 {
+		dst = append(dst, bytes.Repeat(src[i+1:i+2], int(src[i]))...)
+	}
+	return dst, nil
+}
+
+// blob round-trips through BinaryMarshaler.
+type blob struct{ s string }
+
+func (b blob) MarshalBinary() ([]byte, error) { return []byte(b.s), nil }
+
+func (b *blob) UnmarshalBinary(p []byte) error {
+	b.s = string(p)
+	return nil
+}
+
+func TestCompressionBytes(t *testing.T) {
+	var exits atomic.Int64
+	c, err := New[string, []byte](
+		WithCompression(rle{}, 64),
+		WithCost(func(v any) int64 { return int64(len(v.([]byte))) }),
+		func(cfg *Config) {
+			cfg.OnExit = func(v any) {
+				if len(v.([]byte)) == 4096 {
+					exits.Add(1)
+				}
+			}
+		},
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(c.Close)
+
+	big := bytes.Repeat([]byte{'a'}, 4096)
+	noisy := []byte(strings.Repeat("ab", 64))
+	c.Set("big", big)
+	c.Set("small", []byte("tiny"))
+	c.Set("noisy", noisy)
+
+	if v, ok := c.Get("big"); !ok || !bytes.Equal(v, big) {
+		t.Fatalf("Get(big) = %d bytes, %v", len(v), ok)
+	}
+	if v, ok := c.Get("small"); !ok || string(v) != "tiny" {
+		t.Fatalf("Get(small) = %q, %v", v, ok)
+	}
+	if v, ok := c.Get("noisy"); !ok || !bytes.Equal(v, noisy) {
+		t.Fatalf("Get(noisy) = %q, %v", v, ok)
+	}
+
+	_, info, ok := c.GetWithInfo("big")
+	if !ok || info.Cost >= 4096 {
+		t.Errorf("GetWithInfo(big) cost = %d, %v; want compressed size", info.Cost, ok)
+	}
+
+	want := CompressionStats{Compressed: 1, Incompressible: 1, RawBytes: 4096}
+	got := c.CompressionStats()
+	want.CompressedBytes = got.CompressedBytes
+	if got != want || got.CompressedBytes == 0 || got.CompressedBytes >= 4096 {
+		t.Errorf("CompressionStats = %+v", got)
+	}
+
+	// Callbacks see the decompressed value.
+	c.Set("big", []byte("replaced"))
+	if exits.Load() != 1 {
+		t.Errorf("OnExit saw %d decompressed values, want 1", exits.Load())
+	}
+}
+
+func TestCompressionBinaryMarshaler(t *testing.T) {
+	c, err := New[string, blob](WithCompression(rle{}, 0))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(c.Close)
+
+	b := blob{s: strings.Repeat("z", 2*DefaultCompressThreshold)}
+	c.Set("k", b)
+	if v, ok := c.Get("k"); !ok || v != b {
+		t.Fatalf("Get = %d bytes, %v", len(v.s), ok)
+	}
+	if !c.Touch("k", time.Hour) {
+		t.Fatal("Touch on compressed entry = false")
+	}
+	if v, ok := c.Get("k"); !ok || v != b {
+		t.Fatalf("Get after Touch = %d bytes, %v", len(v.s), ok)
+	}
+	if s := c.CompressionStats(); s.Compressed != 1 {
+		t.Errorf("Compressed = %d, want 1", s.Compressed)
+	}
+}
+
+func TestCompressionUnsupportedType(t *testing.T) {
+	if _, err := New[string, string](WithCompression(rle{}, 0)); !errors.Is(err, ErrCompressionUnsupported) {
+		t.Fatalf("New[string] err = %v, want ErrCompressionUnsupported", err)
+	}
+}
+
+func TestSetWithTagsInvalidateTag(t *testing.T) {
+	c := newTestCache(t)
+
+	c.SetWithTags("user:1:profile", "p", 0, 0, "user:1")
+	c.SetWithTags("user:1:orders", "o", 0, time.Hour, "user:1", "orders")
+	c.SetWithTags("user:2:orders", "o", 0, 0, "user:2", "orders")
+	c.Set("plain", "v")
+
+	if n := c.InvalidateTag("user:1"); n != 2 {
+		t.Errorf("InvalidateTag(user:1) = %d, want 2", n)
+	}
+	for _, k := range []string{"user:1:profile", "user:1:orders"} {
+		if _, ok := c.Get(k); ok {
+			t.Errorf("%s still present after InvalidateTag", k)
+		}
+	}
+	if _, ok := c.Get("user:2:orders"); !ok {
+		t.Error("user:2:orders missing")
+	}
+	if _, ok := c.Get("plain"); !ok {
+		t.Error("plain missing")
+	}
+
+	if n := c.InvalidateTag("orders"); n != 1 {
+		t.Errorf("InvalidateTag(orders) = %d, want 1", n)
+	}
+	if n := c.InvalidateTag("orders"); n != 0 {
+		t.Errorf("second InvalidateTag(orders) = %d, want 0", n)
+	}
+}
+
+func TestSetReplacesTags(t *testing.T) {
+	c := newTestCache(t)
+
+	c.SetWithTags("a", 1, 0, 0, "x")
+	c.SetWithTags("b", 2, 0, 0, "x")
+	c.Set("a", 10)                    // drops tag x
+	c.SetWithTags("b", 20, 0, 0, "y") // moves b from x to y
+	c.SetWithTags("gone", 3, 0, 0, "x")
+	c.Delete("gone")
+
+	if n := c.InvalidateTag("x"); n != 0 {
+		t.Errorf("InvalidateTag(x) = %d, want 0", n)
+	}
+	if v, ok := c.Get("a"); !ok || v != 10 {
+		t.Errorf("Get(a) = %v, %v", v, ok)
+	}
+	if n := c.InvalidateTag("y"); n != 1 {
+		t.Errorf("InvalidateTag(y) = %d, want 1", n)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("b still present after InvalidateTag(y)")
+	}
+}
+
+func TestTagsForgottenOnExpiry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for ristretto's expiry sweep")
+	}
+	c := newTestCache(t)
+
+	c.SetWithTags("k", "v", 0, 100*time.Millisecond, "t")
+
+	// Expired items sit in 5s buckets swept every 2.5s, so removal lands
+	// anywhere from 5s to 10s later.
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := c.tags.tags.Get("t"); !ok {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Error("expired entry left in the tag index")
+}
+
+func TestBufferStats(t *testing.T) {
+	// One key per buffer so every Get is handed to the policy; the race
+	// detector randomly discards pooled stripes that would otherwise fill.
+	c, err := New[string, any](WithBufferItems(1))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(c.Close)
+
+	c.Set("k", "v")
+	for range 1_000 {
+		c.Get("k")
+	}
+
+	s := c.BufferStats()
+	if s.GetsKept+s.GetsDropped == 0 {
+		t.Errorf("BufferStats = %+v, want recorded gets", s)
+	}
+	if r := s.GetsDropRatio(); r < 0 || r > 1 {
+		t.Errorf("GetsDropRatio = %v, want in [0, 1]", r)
+	}
+	if r := (BufferStats{}).GetsDropRatio(); r != 0 {
+		t.Errorf("empty GetsDropRatio = %v, want 0", r)
+	}
+}
+
+func TestOnEvictBatch(t *testing.T) {
+	const n = 1000
+	var (
+		mu      sync.Mutex
+		total   int
+		largest int
+		single  atomic.Int64
+	)
+	c, err := New[int, int](
+		WithOnEvict(func(*ristretto.Item) { single.Add(1) }),
+		WithOnEvictBatch(func(items []*ristretto.Item) {
+			time.Sleep(time.Millisecond) // slow consumer: producers must block, not drop
+			mu.Lock()
+			total += len(items)
+			largest = max(largest, len(items))
+			mu.Unlock()
+		}, 16, 32),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for i := range n {
+		c.Set(i, i)
+	}
+	c.Clear()
+	c.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if total != n {
+		t.Errorf("OnEvictBatch received %d items, want %d", total, n)
+	}
+	if largest > 16 {
+		t.Errorf("largest batch = %d, want <= 16", largest)
+	}
+	if got := single.Load(); got != n {
+		t.Errorf("OnEvict called %d times, want %d", got, n)
+	}
+}
+
+func TestOnEvictBatchDecodesValues(t *testing.T) {
+	var got atomic.Value
+	c, err := New[string, any](WithOnEvictBatch(func(items []*ristretto.Item) {
+		for _, item := range items {
+			got.Store(item.Value)
+		}
+	}, 0, 0))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	c.Namespace("ns").Set("k", "v")
+	c.Close()
+
+	if v := got.Load(); v != "v" {
+		t.Errorf("OnEvictBatch value = %#v, want \"v\"", v)
+	}
+}
+
+// checkNoLeak fails t if calling failing n times leaves goroutines behind.
+func checkNoLeak(t *testing.T, failing func() error) {
+	t.Helper()
+	const n = 20
+	before := runtime.NumGoroutine()
+	for range n {
+		if failing() == nil {
+			t.Fatal("New succeeded, want an error")
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after >= before+n {
+		t.Errorf("goroutines: %d before %d failed New calls, %d after", before, n, after)
+	}
+}
+
+func TestOnEvictBatchClosedWhenNewFails(t *testing.T) {
+	onBatch := WithOnEvictBatch(func([]*ristretto.Item) {}, 0, 0)
+	checkNoLeak(t, func() error {
+		_, err := New[string, string](onBatch, WithCompression(rle{}, 0))
+		return err
+	})
+	checkNoLeak(t, func() error {
+		_, err := New[string, string](onBatch, WithShadow(WithNumCounters(0)))
+		return err
+	})
+}
+
+func TestAuditCost(t *testing.T) {
+	c, err := New[int, []byte](
+		WithCostAudit(),
+		WithMaxCost(1<<14),
+		WithNumCounters(1000),
+		WithCost(func(v any) int64 { return int64(len(v.([]byte))) }),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer c.Close()
+
+	// Enough to force evictions, plus updates, deletes and tags.
+	for i := range 500 {
+		c.Set(i%200, make([]byte, 10+i%50))
+	}
+	for i := range 20 {
+		c.Delete(i)
+	}
+	c.SetWithTags(1000, []byte("x"), 0, 0, "t")
+	c.InvalidateTag("t")
+	c.Persist(150)
+
+	if err := c.CheckInvariants(); err != nil {
+		t.Fatalf("CheckInvariants: %v", err)
+	}
+	recorded, actual, err := c.AuditCost(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded != actual || actual == 0 {
+		t.Errorf("AuditCost = %d, %d, want equal and non-zero", recorded, actual)
+	}
+
+	c.Clear()
+	if err := c.CheckInvariants(); err != nil {
+		t.Errorf("CheckInvariants after Clear: %v", err)
+	}
+}
+
+func TestAuditCostRepair(t *testing.T) {
+	c, err := New[string, any](WithCostAudit())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer c.Close()
+
+	c.Set("k", "v")
+	// The entry leaves behind the ledger's back, as with a missed callback.
+	c.inner.Del(hashKey("k"))
+	c.inner.Wait()
+
+	if err := c.CheckInvariants(); !errors.Is(err, ErrInvariant) {
+		t.Fatalf("CheckInvariants = %v, want ErrInvariant", err)
+	}
+	if _, _, err := c.AuditCost(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CheckInvariants(); err != nil {
+		t.Errorf("CheckInvariants after repair: %v", err)
+	}
+}
+
+func TestAuditCostDisabled(t *testing.T) {
+	c := newTestCache(t)
+	if _, _, err := c.AuditCost(false); !errors.Is(err, ErrCostAuditDisabled) {
+		t.Errorf("AuditCost err = %v, want ErrCostAuditDisabled", err)
+	}
+}
+
+func TestDeleteDelayed(t *testing.T) {
+	var dropped atomic.Int64
+	c, err := New[string, any](
+		WithCostAudit(),
+		WithOnDrop(func(key, value any) { dropped.Add(1) }),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(c.Close)
+
+	c.Set("k", "v")
+	c.DeleteDelayed("k", 50*time.Millisecond)
+
+	if v, ok := c.Get("k"); ok {
+		t.Fatalf("Get after DeleteDelayed = %v, want miss", v)
+	}
+	if c.Touch("k", time.Minute) {
+		t.Error("Touch revived a tombstone")
+	}
+	if c.Set("k", "again") {
+		t.Error("Set within grace period accepted")
+	}
+	if dropped.Load() != 1 {
+		t.Errorf("OnDrop calls = %d, want 1", dropped.Load())
+	}
+	if err := c.CheckInvariants(); err != nil {
+		t.Errorf("CheckInvariants: %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if !c.Set("k", "again") {
+		t.Fatal("Set after grace period rejected")
+	}
+	if v, ok := c.Get("k"); !ok || v != "again" {
+		t.Errorf("Get = %v, %v, want again", v, ok)
+	}
+}
+
+func TestDeleteDelayedZeroGrace(t *testing.T) {
+	c := newTestCache(t)
+
+	c.Set("k", "v")
+	c.DeleteDelayed("k", 0)
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("Get after DeleteDelayed hit")
+	}
+	if !c.Set("k", "v") {
+		t.Error("Set after zero-grace DeleteDelayed rejected")
+	}
+}
+
+func TestDeleteDelayedCallbacksAndPurge(t *testing.T) {
+	var exits atomic.Int64
+	c, err := New[string, any](func(cfg *Config) {
+		cfg.OnExit = func(val any) {
+			if _, ok := val.(tombstone); ok {
+				t.Error("OnExit saw a tombstone")
+			}
+			exits.Add(1)
+		}
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(c.Close)
+
+	c.Set("k", "v")
+	c.DeleteDelayed("k", time.Millisecond)
+	c.DeleteDelayed("absent", time.Millisecond)
+	if exits.Load() != 1 {
+		t.Errorf("OnExit calls = %d, want 1 for the replaced value", exits.Load())
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if n := c.PurgeTombstones(); n != 2 {
+		t.Errorf("PurgeTombstones = %d, want 2", n)
+	}
+	if n := c.graves.deadlines.Len(); n != 0 {
+		t.Errorf("graves left = %d", n)
+	}
+}
+
+func TestTraceCapture(t *testing.T) {
+	var out bytes.Buffer
+	sink := NewTraceWriter(&out)
+	c, err := New[string, any](WithTrace(sink))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	c.Set("a", "v")
+	c.Get("a")
+	c.Get("missing")
+	c.Delete("a")
+	c.DeleteDelayed("b", time.Minute)
+	c.Close()
+
+	if err := sink.Err(); err != nil {
+		t.Fatal(err)
+	}
+	events, err := ReadTrace(&out)
+	if err != nil {
+		t.Fatalf("ReadTrace: %v", err)
+	}
+	var ops []TraceOp
+	for _, e := range events {
+		ops = append(ops, e.Op)
+	}
+	want := []TraceOp{TraceSet, TraceGet, TraceGet, TraceDelete, TraceDelete}
+	if !slices.Equal(ops, want) {
+		t.Fatalf("ops = %v, want %v", ops, want)
+	}
+	if events[0].KeyHash != hashKey("a") || events[0].Cost != defaultCost {
+		t.Errorf("Set event = %+v", events[0])
+	}
+	if events[0].Time == 0 || events[4].Time < events[0].Time {
+		t.Errorf("timestamps %d .. %d", events[0].Time, events[4].Time)
+	}
+}
+
+func TestReadTraceMalformed(t *testing.T) {
+	var out bytes.Buffer
+	NewTraceWriter(&out).Consume([]TraceEvent{{Op: TraceGet, KeyHash: 1}})
+
+	if _, err := ReadTrace(bytes.NewReader(out.Bytes()[:out.Len()-1])); !errors.Is(err, ErrBadTrace) {
+		t.Errorf("truncated trace: err = %v, want ErrBadTrace", err)
+	}
+	bad := bytes.Clone(out.Bytes())
+	bad[0] = 99
+	if _, err := ReadTrace(bytes.NewReader(bad)); !errors.Is(err, ErrBadTrace) {
+		t.Errorf("unknown op: err = %v, want ErrBadTrace", err)
+	}
+}
+
+func TestReplayComparesCapacities(t *testing.T) {
+	// Cache-aside over 1000 keys read in rounds: a miss is followed by a
+	// Set, as the application would do.
+	var events []TraceEvent
+	for round := range 5 {
+		for k := range uint64(1000) {
+			events = append(events,
+				TraceEvent{Op: TraceGet, KeyHash: k + 1, Time: int64(round)},
+				TraceEvent{Op: TraceSet, KeyHash: k + 1, Cost: 1, Time: int64(round)},
+			)
+		}
+	}
+
+	// Ristretto charges its per-item overhead on top of the cost of 1.
+	small, err := Replay(events, WithMaxCost(5_000), WithNumCounters(10_000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	large, err := Replay(events, WithMaxCost(1<<20), WithNumCounters(10_000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if small.Gets != 5000 || large.Gets != 5000 {
+		t.Fatalf("Gets = %d, %d, want 5000", small.Gets, large.Gets)
+	}
+	if large.HitRatio() < 0.75 || large.HitRatio() <= small.HitRatio() {
+		t.Errorf("hit ratios small=%.2f large=%.2f", small.HitRatio(), large.HitRatio())
+	}
+}
+
+func TestSetFromBytes(t *testing.T) {
+	type user struct{ name string }
+	c, err := New[string, *user](
+		WithCostAudit(),
+		WithCostFromRaw(func(rawLen int, u *user) int64 { return int64(rawLen + 3*len(u.name)) }),
+		func(cfg *Config) { cfg.IgnoreInternalCost = true },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	raw := []byte(`"ada"`)
+	decode := func(p []byte) (*user, error) {
+		name, err := strconv.Unquote(string(p))
+		return &user{name: name}, err
+	}
+	if ok, err := c.SetFromBytes("u1", raw, decode); !ok || err != nil {
+		t.Fatalf("SetFromBytes = %v, %v", ok, err)
+	}
+	raw[1] = 'x' // the caller reuses its read buffer
+	if u, ok := c.Get("u1"); !ok || u.name != "ada" {
+		t.Fatalf("Get = %+v, %v; want ada", u, ok)
+	}
+	if got := c.chargedCost(hashKey("u1")); got != 5+9 {
+		t.Errorf("charged %d, want 14", got)
+	}
+
+	if ok, err := c.SetFromBytes("u2", []byte("not quoted"), decode); ok || err == nil {
+		t.Errorf("SetFromBytes of bad input = %v, %v; want false, error", ok, err)
+	}
+	if _, ok := c.Get("u2"); ok {
+		t.Error("a failed decode set a value")
+	}
+}
+
+func TestSynchronousDeterministic(t *testing.T) {
+	run := func() (hits []int, evicted []uint64, stats BufferStats) {
+		c, err := New[int, int](
+			WithSynchronous(),
+			WithMaxCost(64),
+			WithNumCounters(1024),
+			WithOnEvict(func(item *ristretto.Item) { evicted = append(evicted, item.Key) }),
+			func(cfg *Config) { cfg.IgnoreInternalCost = true },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		// A skewed workload over four times more keys than fit.
+		x := uint32(1)
+		for i := range 5000 {
+			x = x*1664525 + 1013904223
+			k := int(x >> 24)
+			if i%3 == 0 {
+				k %= 16
+			}
+			if _, ok := c.Get(k); ok {
+				hits = append(hits, i)
+			} else {
+				c.Set(k, i)
+			}
+		}
+		return hits, evicted, c.BufferStats()
+	}
+
+	hits1, evicted1, stats1 := run()
+	hits2, evicted2, stats2 := run()
+	if len(hits1) == 0 || len(evicted1) == 0 || stats1.SetsRejected == 0 {
+		t.Fatalf("workload too easy: %d hits, %d evictions, %+v", len(hits1), len(evicted1), stats1)
+	}
+	if !slices.Equal(hits1, hits2) || !slices.Equal(evicted1, evicted2) || stats1 != stats2 {
+		t.Errorf("runs differ: %d vs %d hits, %d vs %d evictions, %+v vs %+v",
+			len(hits1), len(hits2), len(evicted1), len(evicted2), stats1, stats2)
+	}
+	if stats1.GetsDropped != 0 || stats1.GetsKept != 5000 {
+		t.Errorf("BufferStats = %+v, want every Get kept", stats1)
+	}
+}
+
+func TestSynchronousInline(t *testing.T) {
+	var batched []uint64
+	c, err := New[string, int](
+		WithSynchronous(),
+		WithMaxCost(2),
+		WithCostAudit(),
+		WithOnEvictBatch(func(items []*ristretto.Item) {
+			for _, item := range items {
+				batched = append(batched, item.Key)
+			}
+		}, 0, 0),
+		func(cfg *Config) { cfg.IgnoreInternalCost = true },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without Wait or sleeps, a Set is visible and counted at once.
+	c.Set("a", 1)
+	c.Get("a")
+	c.Set("b", 2)
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Fatalf("Get(b) = %v, %v", v, ok)
+	}
+	if s := c.Stats(); s.KeyCount != 2 || s.CostUsed != 2 {
+		t.Fatalf("Stats = %+v, want 2 keys of cost 2", s)
+	}
+
+	// The sketch already knows a is hot: a new key evicts b, the cold one,
+	// and OnEvictBatch has it before Set returns.
+	c.Get("a")
+	c.Get("c")
+	c.Get("c")
+	c.Get("c")
+	c.Set("c", 3)
+	if !slices.Equal(batched, []uint64{hashKey("b")}) {
+		t.Fatalf("evicted %v, want b", batched)
+	}
+	if err := c.CheckInvariants(); err != nil {
+		t.Error(err)
+	}
+
+	if err := c.CloseContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(batched) != 3 {
+		t.Errorf("OnEvictBatch saw %d items after Close, want 3", len(batched))
+	}
+}
+
+func TestCompat(t *testing.T) {
+	c, err := New[string, int](WithSynchronous(), WithCostAudit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	shim := NewCompat(c)
+	defer shim.Close()
+
+	if !shim.Set("a", 1, 5) {
+		t.Fatal("Set returned false")
+	}
+	shim.Wait()
+	if v, ok := shim.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get = %v, %v", v, ok)
+	}
+	if got := c.chargedCost(hashKey("a")); got != 5 {
+		t.Errorf("charged %d, want 5", got)
+	}
+
+	// Wrongly typed keys and values miss rather than panic.
+	if shim.Set(1, 1, 1) || shim.Set("b", "one", 1) {
+		t.Error("Set stored a key or value of the wrong type")
+	}
+	if _, ok := shim.Get(1); ok {
+		t.Error("Get of a wrongly typed key hit")
+	}
+
+	if !shim.SetWithTTL("t", 2, 0, time.Hour) {
+		t.Fatal("SetWithTTL returned false")
+	}
+	if ttl, ok := shim.GetTTL("t"); !ok || ttl <= 59*time.Minute {
+		t.Errorf("GetTTL = %v, %v", ttl, ok)
+	}
+	if shim.SetWithTTL("neg", 3, 0, -time.Second) {
+		t.Error("SetWithTTL with a negative TTL stored the value")
+	}
+
+	shim.Del("a")
+	if _, ok := shim.Get("a"); ok {
+		t.Error("key present after Del")
+	}
+	if shim.MaxCost() != DefaultConfig().MaxCost || shim.Cache() != c {
+		t.Error("MaxCost or Cache do not reflect the wrapped cache")
+	}
+}
+
+func TestShadow(t *testing.T) {
+	run := func(shadow ...Option) ShadowStats {
+		c, err := New[int, int](
+			WithSynchronous(),
+			WithMaxCost(64),
+			WithNumCounters(1024),
+			WithShadow(shadow...),
+			func(cfg *Config) { cfg.IgnoreInternalCost = true },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		x := uint32(1)
+		for i := range 5000 {
+			x = x*1664525 + 1013904223
+			k := int(x >> 24)
+			if i%3 == 0 {
+				k %= 16
+			}
+			if _, ok := c.Get(k); !ok {
+				c.Set(k, i)
+			}
+		}
+		c.Delete(1)
+		s, ok := c.ShadowStats()
+		if !ok {
+			t.Fatal("ShadowStats() = false with a shadow")
+		}
+		return s
+	}
+
+	// The same settings run inline see the same hits.
+	same := run()
+	if same.Primary.Gets != 5000 || same.Primary != same.Shadow || same.Gain() != 0 {
+		t.Errorf("identical shadow: %+v", same)
+	}
+
+	larger := run(WithMaxCost(256))
+	if larger.Primary != same.Primary {
+		t.Errorf("shadow changed the cache: %+v vs %+v", larger.Primary, same.Primary)
+	}
+	if larger.Gain() <= 0 {
+		t.Errorf("4x MaxCost shadow: primary %.3f, shadow %.3f", larger.Primary.HitRatio(), larger.Shadow.HitRatio())
+	}
+
+	c, _ := New[int, int]()
+	defer c.Close()
+	if _, ok := c.ShadowStats(); ok {
+		t.Error("ShadowStats() = true without a shadow")
+	}
+}
+
+func TestClockGranularity(t *testing.T) {
+	newCache := func(granularity time.Duration) *Cache[string, int] {
+		c, err := New[string, int](WithSynchronous(), WithClockGranularity(granularity))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	// An hour-long tick never refreshes during the test: expiry sees the
+	// time the shared clock started.
+	a := newCache(time.Hour)
+	time.Sleep(5 * time.Millisecond)
+	b := newCache(time.Hour)
+	if a.clock.Now() != b.clock.Now() {
+		t.Error("caches with the same granularity read different clocks")
+	}
+
+	b.SetWithTTL("k", 1, 10*time.Millisecond)
+	exact := newCache(0)
+	defer exact.Close()
+	exact.SetWithTTL("k", 1, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := b.Get("k"); !ok {
+		t.Error("entry expired before the coarse clock advanced")
+	}
+	if ttl, ok := b.inner.GetTTL(hashKey("k")); !ok || ttl <= 0 {
+		t.Errorf("GetTTL = %v, %v; want the TTL left on the coarse clock", ttl, ok)
+	}
+	if _, ok := exact.Get("k"); ok {
+		t.Error("entry outlived its TTL with the exact clock")
+	}
+
+	// The clock stops with its last cache; the next one starts afresh.
+	started := a.clock.Now()
+	a.Close()
+	b.Close()
+	c := newCache(time.Hour)
+	defer c.Close()
+	if c.clock.Now() <= started {
+		t.Error("a new cache reused the stopped clock")
+	}
+}
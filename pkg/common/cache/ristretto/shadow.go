@@ -0,0 +1,79 @@
+package ristretto
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/timer"
+)
+
+// ShadowStats compares the hit ratio of the cache with that of its shadow
+// over the same Gets, counted since the cache was created.
+type ShadowStats struct {
+	Primary ReplayStats
+	Shadow  ReplayStats
+}
+
+// Gain returns the shadow's hit ratio minus the cache's: 0.05 means the
+// shadow configuration would have hit 5 more Gets in 100. It is negative
+// when the shadow does worse.
+func (s ShadowStats) Gain() float64 {
+	return s.Shadow.HitRatio() - s.Primary.HitRatio()
+}
+
+// shadow runs an alternative configuration on the cache's access stream. It
+// holds no values, only the policy's view of which keys would be resident.
+type shadow struct {
+	engine *syncEngine
+
+	gets        atomic.Uint64
+	primaryHits atomic.Uint64
+	shadowHits  atomic.Uint64
+}
+
+// newShadow builds the shadow for cfg: the cache's own sizes with opts
+// applied on top, run inline without callbacks on the cache's clock.
+func newShadow(cfg *Config, clock timer.Timer) (*shadow, error) {
+	scfg := Config{Config: cfg.Config}
+	scfg.OnEvict, scfg.OnReject, scfg.OnExit = nil, nil, nil
+	scfg.Cost = nil // Set passes the charged cost
+	scfg.KeyToHash = nil
+	for _, opt := range cfg.Shadow {
+		opt(&scfg)
+	}
+	e, err := newSyncEngine(&scfg, nil, clock)
+	if err != nil {
+		return nil, err
+	}
+	return &shadow{engine: e}, nil
+}
+
+// get counts a Get of h, which hit the cache if hit.
+func (s *shadow) get(h uint64, hit bool) {
+	s.gets.Add(1)
+	if hit {
+		s.primaryHits.Add(1)
+	}
+	if _, ok := s.engine.Get(h); ok {
+		s.shadowHits.Add(1)
+	}
+}
+
+// set offers h to the shadow's policy at the cost the cache charged.
+func (s *shadow) set(h uint64, cost int64, ttl time.Duration) {
+	s.engine.SetWithTTL(h, struct{}{}, cost, ttl)
+}
+
+// ShadowStats reports the hit ratios of the cache and of the configuration
+// given to WithShadow over the Gets seen so far; false without a shadow.
+func (c *Cache[K, V]) ShadowStats() (ShadowStats, bool) {
+	s := c.shadow
+	if s == nil {
+		return ShadowStats{}, false
+	}
+	gets := s.gets.Load()
+	return ShadowStats{
+		Primary: ReplayStats{Gets: gets, Hits: s.primaryHits.Load()},
+		Shadow:  ReplayStats{Gets: gets, Hits: s.shadowHits.Load()},
+	}, true
+}
@@ -0,0 +1,203 @@
+package ristretto
+
+import (
+	"cmp"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/sketch"
+	"github.com/huynhanx03/go-common/pkg/encoding/json"
+)
+
+var (
+	// ErrSnapshotsDisabled is returned by Export on a cache created without
+	// WithSnapshots.
+	ErrSnapshotsDisabled = errors.New("ristretto: snapshots not enabled")
+
+	// ErrClosed is returned by Export and Import on a closed cache.
+	ErrClosed = errors.New("ristretto: cache closed")
+)
+
+// keyIndex remembers the original key of every resident entry, which
+// ristretto only stores as a hash, with the value it was stored as, and
+// estimates access frequencies so Export can pick the hottest entries.
+// Holding the value lets Export read it without a Get, which would count a
+// hit and feed the access to the admission policy.
+type keyIndex[K any] struct {
+	mu      sync.Mutex
+	entries map[uint64]indexEntry[K]
+	freq    *sketch.Sketch
+}
+
+// indexEntry is the key and stored value of one indexed entry.
+type indexEntry[K any] struct {
+	key   K
+	value any
+}
+
+// newKeyIndex creates an index whose sketch is seeded like a synchronous
+// cache's when seeded is set.
+func newKeyIndex[K any](numCounters int64, seeded bool) *keyIndex[K] {
+	// NumCounters is sized at ~10x the entries, the TinyLFU sample size.
+	opts := []sketch.Option{sketch.WithHalvingInterval(uint64(max(numCounters, 1)))}
+	if seeded {
+		opts = append(opts, sketch.WithSeed(syncSeed))
+	}
+	return &keyIndex[K]{
+		entries: make(map[uint64]indexEntry[K]),
+		freq:    sketch.New(numCounters, opts...),
+	}
+}
+
+func (x *keyIndex[K]) add(h uint64, key K, stored any) {
+	x.mu.Lock()
+	x.entries[h] = indexEntry[K]{key: key, value: stored}
+	x.freq.Increment(h)
+	x.mu.Unlock()
+}
+
+func (x *keyIndex[K]) touch(h uint64) {
+	x.mu.Lock()
+	x.freq.Increment(h)
+	x.mu.Unlock()
+}
+
+// touchEstimate records an access and returns the updated estimate.
+func (x *keyIndex[K]) touchEstimate(h uint64) int64 {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.freq.Increment(h)
+	return x.freq.Estimate(h)
+}
+
+func (x *keyIndex[K]) remove(h uint64) {
+	x.mu.Lock()
+	delete(x.entries, h)
+	x.mu.Unlock()
+}
+
+func (x *keyIndex[K]) clear() {
+	x.mu.Lock()
+	clear(x.entries)
+	x.freq.Clear()
+	x.mu.Unlock()
+}
+
+// indexedKey is a key with its hash, stored value and frequency estimate.
+type indexedKey[K any] struct {
+	hash  uint64
+	key   K
+	value any
+	freq  int64
+}
+
+// hottest returns the indexed keys by descending frequency estimate.
+func (x *keyIndex[K]) hottest() []indexedKey[K] {
+	x.mu.Lock()
+	keys := make([]indexedKey[K], 0, len(x.entries))
+	for h, e := range x.entries {
+		keys = append(keys, indexedKey[K]{hash: h, key: e.key, value: e.value, freq: x.freq.Estimate(h)})
+	}
+	x.mu.Unlock()
+
+	slices.SortFunc(keys, func(a, b indexedKey[K]) int {
+		return cmp.Compare(b.freq, a.freq)
+	})
+	return keys
+}
+
+// snapshotRecord is one exported entry: one JSON document per line.
+type snapshotRecord[K any, V any] struct {
+	Key   K     `json:"k"`
+	Value V     `json:"v"`
+	TTL   int64 `json:"ttl,omitempty"` // remaining nanoseconds, 0 = no expiry
+}
+
+// Export writes up to maxEntries of the hottest entries to w, ordered by
+// estimated access frequency, for a new process to Import during a
+// blue-green deploy. maxEntries <= 0 exports every entry. Each record carries
+// the remaining TTL, so entries expire in the new cache when they would have
+// in the old one. Keys and values are JSON encoded; interface-typed values
+// come back as their JSON shape (map[string]any, float64, ...).
+//
+// Like Snapshot, Export reads the key index rather than the entries: it
+// counts no hits and feeds no access to the admission policy. The entries
+// are collected under the cache's read lock and written to w after it is
+// released, so a slow writer does not hold up Close, or the Sets queued
+// behind it.
+//
+// Requires WithSnapshots. Namespaced entries are not exported.
+func (c *Cache[K, V]) Export(w io.Writer, maxEntries int) (int, error) {
+	if c.index == nil {
+		return 0, ErrSnapshotsDisabled
+	}
+
+	recs, err := c.exportRecords(maxEntries)
+	if err != nil {
+		return 0, err
+	}
+
+	enc := json.NewEncoder(w)
+	for n := range recs {
+		if err := enc.Encode(&recs[n]); err != nil {
+			return n, err
+		}
+	}
+	return len(recs), nil
+}
+
+// exportRecords collects up to maxEntries of the hottest resident entries.
+func (c *Cache[K, V]) exportRecords(maxEntries int) ([]snapshotRecord[K, V], error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+
+	var recs []snapshotRecord[K, V]
+	for _, k := range c.index.hottest() {
+		if maxEntries > 0 && len(recs) >= maxEntries {
+			break
+		}
+
+		ttl, ok := c.inner.GetTTL(k.hash)
+		if !ok {
+			c.index.remove(k.hash) // left the cache without a callback
+			continue
+		}
+		typed, ok := c.decode(k.value)
+		if !ok {
+			continue
+		}
+		recs = append(recs, snapshotRecord[K, V]{Key: k.key, Value: typed, TTL: int64(ttl)})
+	}
+	return recs, nil
+}
+
+// Import reads entries written by Export and sets them with their remaining
+// TTL, unjittered. It returns the number of entries the cache admitted;
+// entries the policy rejects are not an error.
+func (c *Cache[K, V]) Import(r io.Reader) (int, error) {
+	dec := json.NewDecoder(r)
+	n := 0
+	for {
+		var rec snapshotRecord[K, V]
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+
+		ok, err := c.set(rec.Key, rec.Value, 0, time.Duration(rec.TTL), nil)
+		if err != nil {
+			return n, err
+		}
+		if ok {
+			n++
+		}
+	}
+}
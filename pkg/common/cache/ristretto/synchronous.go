@@ -0,0 +1,478 @@
+package ristretto
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/dgraph-io/ristretto/z"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/sketch"
+	"github.com/huynhanx03/go-common/pkg/timer"
+)
+
+// syncSeed seeds the frequency sketch and the eviction sampler of a
+// synchronous cache, so that runs over the same operations agree.
+const syncSeed = 1
+
+// doorkeeperFP is the false-positive rate of the TinyLFU doorkeeper, as in
+// ristretto.
+const doorkeeperFP = 0.01
+
+// syncEngine runs ristretto's policy inline: every Get counts towards
+// TinyLFU admission right away instead of through lossy buffers, every Set
+// is admitted or rejected before it returns, and evictions sample resident
+// keys with a seeded generator instead of map order. Expired entries are
+// removed by the next Set or Wait rather than by a ticker. No goroutines
+// are started; a mutex makes it safe for concurrent use all the same.
+type syncEngine struct {
+	mu      sync.Mutex
+	items   map[uint64]*syncItem
+	keys    []uint64 // resident keys, indexed by syncItem.pos for sampling
+	expiry  expiryHeap
+	freq    *sketch.Sketch
+	rng     *rand.Rand
+	maxCost int64
+	used    int64
+	closed  bool
+
+	hash     func(any) (uint64, uint64)
+	costFn   func(any) int64
+	internal int64
+
+	onEvict  func(*ristretto.Item)
+	onReject func(*ristretto.Item)
+	onExit   func(any)
+	settle   func()
+	clock    timer.Timer
+	events   []syncEvent // callbacks due once mu is released
+
+	metrics *syncMetrics // counted always, exposed only with Config.Metrics
+}
+
+// syncItem is a resident entry.
+type syncItem struct {
+	conflict   uint64
+	value      any
+	cost       int64
+	expiration time.Time
+	pos        int // index in syncEngine.keys
+}
+
+// syncEvent is a callback recorded under the lock and run after it.
+type syncEvent struct {
+	kind  uint8
+	item  *ristretto.Item
+	value any // eventExit
+}
+
+const (
+	eventEvict uint8 = iota
+	eventReject
+	eventExit
+)
+
+func newSyncEngine(cfg *Config, settle func(), clock timer.Timer) (*syncEngine, error) {
+	switch {
+	case cfg.NumCounters == 0:
+		return nil, errors.New("NumCounters can't be zero")
+	case cfg.MaxCost == 0:
+		return nil, errors.New("MaxCost can't be zero")
+	case cfg.BufferItems == 0:
+		return nil, errors.New("BufferItems can't be zero")
+	}
+
+	e := &syncEngine{
+		items: make(map[uint64]*syncItem),
+		freq: sketch.New(cfg.NumCounters,
+			sketch.WithHalvingInterval(uint64(cfg.NumCounters)),
+			sketch.WithDoorkeeper(doorkeeperFP),
+			sketch.WithSeed(syncSeed)),
+		rng:      rand.New(rand.NewPCG(syncSeed, syncSeed)),
+		metrics:  &syncMetrics{},
+		maxCost:  cfg.MaxCost,
+		hash:     cfg.KeyToHash,
+		costFn:   cfg.Cost,
+		internal: internalCost,
+		onEvict:  cfg.OnEvict,
+		onReject: cfg.OnReject,
+		onExit:   cfg.OnExit,
+		settle:   settle,
+		clock:    clock,
+	}
+	if e.hash == nil {
+		e.hash = z.KeyToHash
+	}
+	if cfg.IgnoreInternalCost {
+		e.internal = 0
+	}
+	return e, nil
+}
+
+// unlock releases mu, then runs the callbacks recorded under it, in order,
+// as ristretto would: OnEvict or OnReject, then OnExit with the value.
+func (e *syncEngine) unlock() {
+	events := e.events
+	e.events = nil
+	e.mu.Unlock()
+
+	evicted := false
+	for _, ev := range events {
+		switch ev.kind {
+		case eventEvict:
+			evicted = true
+			if e.onEvict != nil {
+				e.onEvict(ev.item)
+			}
+			e.exit(ev.item.Value)
+		case eventReject:
+			if e.onReject != nil {
+				e.onReject(ev.item)
+			}
+			e.exit(ev.item.Value)
+		case eventExit:
+			e.exit(ev.value)
+		}
+	}
+	if evicted && e.settle != nil {
+		e.settle()
+	}
+}
+
+// now reads the engine's clock.
+func (e *syncEngine) now() time.Time {
+	return time.Unix(0, e.clock.Now())
+}
+
+func (e *syncEngine) exit(value any) {
+	if e.onExit != nil && value != nil {
+		e.onExit(value)
+	}
+}
+
+// Get returns the value stored under key, counting the access.
+func (e *syncEngine) Get(key any) (any, bool) {
+	if key == nil {
+		return nil, false
+	}
+	h, conflict := e.hash(key)
+
+	e.mu.Lock()
+	defer e.unlock()
+	if e.closed {
+		return nil, false
+	}
+
+	e.freq.Increment(h)
+	e.metrics.getsKept.Add(1)
+	it, ok := e.items[h]
+	if !ok || (conflict != 0 && conflict != it.conflict) || it.expired(e.now()) {
+		e.metrics.misses.Add(1)
+		return nil, false
+	}
+	e.metrics.hits.Add(1)
+	return it.value, true
+}
+
+// SetWithTTL stores value under key, admitting or rejecting it on the spot.
+// As with ristretto, it returns true unless ttl is negative or the engine
+// is closed: a rejection is reported to OnReject.
+func (e *syncEngine) SetWithTTL(key, value any, cost int64, ttl time.Duration) bool {
+	if key == nil || ttl < 0 {
+		return false
+	}
+	h, conflict := e.hash(key)
+
+	now := e.now()
+	var expiration time.Time
+	if ttl > 0 {
+		expiration = now.Add(ttl)
+	}
+
+	e.mu.Lock()
+	defer e.unlock()
+	if e.closed {
+		return false
+	}
+	e.expire(now)
+
+	if cost == 0 && e.costFn != nil {
+		cost = e.costFn(value)
+	}
+	cost += e.internal
+
+	if it, ok := e.items[h]; ok && (conflict == 0 || conflict == it.conflict) {
+		e.events = append(e.events, syncEvent{kind: eventExit, value: it.value})
+		it.value, it.expiration = value, expiration
+		e.schedule(h, expiration)
+		e.metrics.costAdded.Add(uint64(cost - it.cost))
+		e.used += cost - it.cost
+		it.cost = cost
+		return true
+	}
+
+	item := &ristretto.Item{Key: h, Conflict: conflict, Value: value, Cost: cost, Expiration: expiration}
+	if !e.admit(h, cost) {
+		e.events = append(e.events, syncEvent{kind: eventReject, item: item})
+		return true
+	}
+	e.items[h] = &syncItem{conflict: conflict, value: value, cost: cost, expiration: expiration, pos: len(e.keys)}
+	e.keys = append(e.keys, h)
+	e.used += cost
+	e.schedule(h, expiration)
+	e.metrics.keysAdded.Add(1)
+	e.metrics.costAdded.Add(uint64(cost))
+	return true
+}
+
+// admit makes room for a new entry of cost as ristretto's policy does:
+// while the cache is over budget, the least frequent of lfuSample sampled
+// keys is evicted, unless it is more frequent than h, which rejects h.
+// Victims evicted before a rejection stay evicted, as in ristretto.
+func (e *syncEngine) admit(h uint64, cost int64) bool {
+	if cost > e.maxCost {
+		return false
+	}
+	incHits := e.freq.Estimate(h)
+	sample := make([]uint64, 0, lfuSample)
+	for e.maxCost-e.used < cost {
+		sample = e.fillSample(sample)
+		if len(sample) == 0 {
+			return false
+		}
+
+		minIdx, minHits := 0, int64(math.MaxInt64)
+		for i, k := range sample {
+			if hits := e.freq.Estimate(k); hits < minHits {
+				minIdx, minHits = i, hits
+			}
+		}
+		if incHits < minHits {
+			e.metrics.setsRejected.Add(1)
+			return false
+		}
+
+		victim := sample[minIdx]
+		sample[minIdx] = sample[len(sample)-1]
+		sample = sample[:len(sample)-1]
+		e.evict(victim)
+	}
+	return true
+}
+
+// fillSample tops sample up to lfuSample distinct resident keys, picked
+// with the seeded generator.
+func (e *syncEngine) fillSample(sample []uint64) []uint64 {
+	for len(sample) < min(lfuSample, len(e.keys)) {
+		k := e.keys[e.rng.IntN(len(e.keys))]
+		if !slices.Contains(sample, k) {
+			sample = append(sample, k)
+		}
+	}
+	return sample
+}
+
+// evict removes the resident entry h and records its eviction.
+func (e *syncEngine) evict(h uint64) {
+	it := e.remove(h)
+	e.events = append(e.events, syncEvent{kind: eventEvict, item: &ristretto.Item{
+		Key: h, Conflict: it.conflict, Value: it.value, Cost: it.cost, Expiration: it.expiration,
+	}})
+}
+
+// remove deletes the resident entry h, counting it as evicted as
+// ristretto's policy does for deletes too.
+func (e *syncEngine) remove(h uint64) *syncItem {
+	it := e.items[h]
+	delete(e.items, h)
+	last := len(e.keys) - 1
+	if it.pos != last {
+		moved := e.keys[last]
+		e.keys[it.pos] = moved
+		e.items[moved].pos = it.pos
+	}
+	e.keys = e.keys[:last]
+	e.used -= it.cost
+	e.metrics.keysEvicted.Add(1)
+	e.metrics.costEvicted.Add(uint64(it.cost))
+	return it
+}
+
+// schedule queues h for removal at expiration, if it has one.
+func (e *syncEngine) schedule(h uint64, expiration time.Time) {
+	if !expiration.IsZero() {
+		heap.Push(&e.expiry, expiryEntry{key: h, at: expiration})
+	}
+}
+
+// expire evicts the entries whose expiration has passed by now. Heap
+// entries left behind by an update or a delete are skipped.
+func (e *syncEngine) expire(now time.Time) {
+	for len(e.expiry) > 0 && !e.expiry[0].at.After(now) {
+		next := heap.Pop(&e.expiry).(expiryEntry)
+		if it, ok := e.items[next.key]; ok && it.expiration.Equal(next.at) {
+			e.evict(next.key)
+		}
+	}
+}
+
+// GetTTL returns the time left before key expires, 0 if it does not.
+func (e *syncEngine) GetTTL(key any) (time.Duration, bool) {
+	if key == nil {
+		return 0, false
+	}
+	h, conflict := e.hash(key)
+
+	e.mu.Lock()
+	defer e.unlock()
+	it, ok := e.items[h]
+	if !ok || (conflict != 0 && conflict != it.conflict) {
+		return 0, false
+	}
+	if it.expiration.IsZero() {
+		return 0, true
+	}
+	// Same clock as expiry, so a live entry never reports a spent TTL.
+	left := it.expiration.Sub(e.now())
+	if left <= 0 {
+		return 0, false
+	}
+	return left, true
+}
+
+// Del removes key.
+func (e *syncEngine) Del(key any) {
+	if key == nil {
+		return
+	}
+	h, conflict := e.hash(key)
+
+	e.mu.Lock()
+	defer e.unlock()
+	if e.closed {
+		return
+	}
+	if it, ok := e.items[h]; ok && (conflict == 0 || conflict == it.conflict) {
+		e.remove(h)
+		e.events = append(e.events, syncEvent{kind: eventExit, value: it.value})
+	}
+}
+
+// Wait removes expired entries; there is nothing else to wait for.
+func (e *syncEngine) Wait() {
+	e.mu.Lock()
+	defer e.unlock()
+	if !e.closed {
+		e.expire(e.now())
+	}
+}
+
+// Clear evicts every entry, in insertion order but for the keys moved by
+// removals, and zeroes the frequencies and metrics.
+func (e *syncEngine) Clear() {
+	e.mu.Lock()
+	defer e.unlock()
+	if !e.closed {
+		e.clear()
+	}
+}
+
+func (e *syncEngine) clear() {
+	for _, h := range e.keys {
+		it := e.items[h]
+		e.events = append(e.events, syncEvent{kind: eventEvict, item: &ristretto.Item{
+			Key: h, Conflict: it.conflict, Value: it.value, Cost: it.cost, Expiration: it.expiration,
+		}})
+	}
+	clear(e.items)
+	e.keys = e.keys[:0]
+	e.expiry = e.expiry[:0]
+	e.used = 0
+	e.freq.Clear()
+	e.metrics.reset()
+}
+
+// Close evicts every entry and stops the engine.
+func (e *syncEngine) Close() {
+	e.mu.Lock()
+	defer e.unlock()
+	if !e.closed {
+		e.clear()
+		e.closed = true
+	}
+}
+
+// MaxCost returns the cost budget.
+func (e *syncEngine) MaxCost() int64 {
+	return e.maxCost
+}
+
+func (it *syncItem) expired(now time.Time) bool {
+	return !it.expiration.IsZero() && now.After(it.expiration)
+}
+
+// expiryEntry is a pending expiration.
+type expiryEntry struct {
+	key uint64
+	at  time.Time
+}
+
+// expiryHeap orders pending expirations, earliest first; ties by key keep
+// the order of removals deterministic.
+type expiryHeap []expiryEntry
+
+func (h expiryHeap) Len() int { return len(h) }
+func (h expiryHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].key < h[j].key
+	}
+	return h[i].at.Before(h[j].at)
+}
+func (h expiryHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x any)   { *h = append(*h, x.(expiryEntry)) }
+func (h *expiryHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// syncMetrics counts what ristretto's Metrics does for a syncEngine. Cost
+// decreases are added as two's complement, as ristretto does.
+type syncMetrics struct {
+	hits, misses           atomic.Uint64
+	keysAdded, keysEvicted atomic.Uint64
+	costAdded, costEvicted atomic.Uint64
+	getsKept, setsRejected atomic.Uint64
+}
+
+// reset zeroes every counter, as ristretto does on Clear.
+func (m *syncMetrics) reset() {
+	for _, c := range []*atomic.Uint64{
+		&m.hits, &m.misses, &m.keysAdded, &m.keysEvicted,
+		&m.costAdded, &m.costEvicted, &m.getsKept, &m.setsRejected,
+	} {
+		c.Store(0)
+	}
+}
+
+func (m *syncMetrics) Hits() uint64         { return m.hits.Load() }
+func (m *syncMetrics) Misses() uint64       { return m.misses.Load() }
+func (m *syncMetrics) KeysAdded() uint64    { return m.keysAdded.Load() }
+func (m *syncMetrics) KeysEvicted() uint64  { return m.keysEvicted.Load() }
+func (m *syncMetrics) CostAdded() uint64    { return m.costAdded.Load() }
+func (m *syncMetrics) CostEvicted() uint64  { return m.costEvicted.Load() }
+func (m *syncMetrics) GetsKept() uint64     { return m.getsKept.Load() }
+func (m *syncMetrics) SetsRejected() uint64 { return m.setsRejected.Load() }
+
+// GetsDropped is always 0: every Get reaches the policy.
+func (m *syncMetrics) GetsDropped() uint64 { return 0 }
+
+// SetsDropped is always 0: there is no set buffer to overflow.
+func (m *syncMetrics) SetsDropped() uint64 { return 0 }
@@ -0,0 +1,148 @@
+package ristretto
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+
+	"github.com/huynhanx03/go-common/pkg/common/cache"
+	"github.com/huynhanx03/go-common/pkg/datastructs/shardedmap"
+)
+
+// tagShards is the shard count of both tag index maps.
+const tagShards = 64
+
+// tagIndex maps each tag to the keys carrying it and each tagged key to its
+// tags. A tag's key set may briefly hold keys that were since re-set without
+// the tag; InvalidateTag checks the key's current tags before deleting it.
+type tagIndex struct {
+	used atomic.Bool // set by the first SetWithTags; until then Sets skip the index
+	tags *shardedmap.Map[string, map[uint64]struct{}]
+	keys *shardedmap.Map[uint64, []string]
+}
+
+func newTagIndex() *tagIndex {
+	return &tagIndex{
+		tags: shardedmap.New[string, map[uint64]struct{}](tagShards, hashKey[string]),
+		keys: shardedmap.New[uint64, []string](tagShards, func(h uint64) uint64 { return h }),
+	}
+}
+
+// set replaces the tags of the entry h.
+func (x *tagIndex) set(h uint64, tags []string) {
+	if len(tags) > 0 {
+		x.used.Store(true)
+	} else if !x.used.Load() {
+		return
+	}
+
+	var old []string
+	x.keys.Compute(h, func(prev []string, _ bool) ([]string, bool) {
+		old = prev
+		return tags, len(tags) > 0
+	})
+	for _, tag := range old {
+		if !slices.Contains(tags, tag) {
+			x.unlink(tag, h)
+		}
+	}
+	for _, tag := range tags {
+		x.tags.Compute(tag, func(set map[uint64]struct{}, _ bool) (map[uint64]struct{}, bool) {
+			if set == nil {
+				set = make(map[uint64]struct{})
+			}
+			set[h] = struct{}{}
+			return set, true
+		})
+	}
+}
+
+// remove forgets the tags of the entry h.
+func (x *tagIndex) remove(h uint64) {
+	if x.used.Load() {
+		x.set(h, nil)
+	}
+}
+
+// unlink removes h from the key set of tag, dropping the set once empty.
+func (x *tagIndex) unlink(tag string, h uint64) {
+	x.tags.Compute(tag, func(set map[uint64]struct{}, _ bool) (map[uint64]struct{}, bool) {
+		delete(set, h)
+		return set, len(set) > 0
+	})
+}
+
+// take removes tag and returns the keys it was attached to.
+func (x *tagIndex) take(tag string) map[uint64]struct{} {
+	var keys map[uint64]struct{}
+	x.tags.Compute(tag, func(set map[uint64]struct{}, _ bool) (map[uint64]struct{}, bool) {
+		keys = set
+		return nil, false
+	})
+	return keys
+}
+
+// has reports whether the entry h currently carries tag.
+func (x *tagIndex) has(h uint64, tag string) bool {
+	tags, _ := x.keys.Get(h)
+	return slices.Contains(tags, tag)
+}
+
+func (x *tagIndex) clear() {
+	x.tags.Clear()
+	x.keys.Clear()
+}
+
+// wrapTagCallbacks drops evicted and rejected entries from the tag index.
+func wrapTagCallbacks(cfg *Config, tags *tagIndex) {
+	evict := cfg.OnEvict
+	cfg.OnEvict = func(item *ristretto.Item) {
+		tags.remove(item.Key)
+		evict(item)
+	}
+
+	reject := cfg.OnReject
+	cfg.OnReject = func(item *ristretto.Item) {
+		tags.remove(item.Key)
+		if reject != nil {
+			reject(item)
+		}
+	}
+}
+
+// SetWithTags is SetWithTTL that also attaches tags to the entry, so related
+// entries (e.g. every key of one user) can be dropped together with
+// InvalidateTag. A later Set of the same key replaces its tags. cost <= 0
+// charges the configured cost; ItemInfo.Cost still reports the configured
+// cost. Tags are not exported by Export.
+func (c *Cache[K, V]) SetWithTags(key K, value V, cost int64, ttl time.Duration, tags ...string) bool {
+	ok, _ := c.set(key, value, cost, cache.JitterTTL(ttl, c.jitter), tags)
+	return ok
+}
+
+// InvalidateTag deletes every entry carrying tag and returns how many were
+// deleted. An entry set concurrently with the call may survive it.
+func (c *Cache[K, V]) InvalidateTag(tag string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return 0
+	}
+
+	n := 0
+	for h := range c.tags.take(tag) {
+		if !c.tags.has(h, tag) {
+			continue // re-set without the tag since
+		}
+		c.inner.Del(h)
+		c.forgetCost(h)
+		c.tags.remove(h)
+		if c.index != nil {
+			c.index.remove(h)
+		}
+		n++
+	}
+	return n
+}
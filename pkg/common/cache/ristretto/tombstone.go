@@ -0,0 +1,160 @@
+package ristretto
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/shardedmap"
+)
+
+// tombstone is stored in place of a value deleted with DeleteDelayed. It is
+// never decoded or handed to user callbacks; its TTL is the grace period, so
+// ristretto's expiry sweep removes it and purges the grave.
+type tombstone struct{}
+
+// graveyard holds the grace deadline, in Unix nanoseconds, of every
+// tombstoned key. It is authoritative: the stored tombstone only drives the
+// purge and may be rejected or evicted by the policy before its deadline.
+type graveyard struct {
+	used      atomic.Bool // set by the first DeleteDelayed; until then Sets skip the lookup
+	deadlines *shardedmap.Map[uint64, int64]
+}
+
+func newGraveyard() *graveyard {
+	return &graveyard{
+		deadlines: shardedmap.New[uint64, int64](tagShards, func(h uint64) uint64 { return h }),
+	}
+}
+
+// bury records a tombstone for h until deadline.
+func (g *graveyard) bury(h uint64, deadline int64) {
+	g.used.Store(true)
+	g.deadlines.Set(h, deadline)
+}
+
+// live reports whether h has a tombstone whose grace period has not ended,
+// purging it if it has.
+func (g *graveyard) live(h uint64, now int64) bool {
+	if !g.used.Load() {
+		return false
+	}
+	alive := false
+	g.deadlines.Compute(h, func(deadline int64, loaded bool) (int64, bool) {
+		alive = loaded && deadline > now
+		return deadline, alive
+	})
+	return alive
+}
+
+// sweep purges every tombstone whose grace period ended by now and returns
+// how many it purged.
+func (g *graveyard) sweep(now int64) int {
+	if !g.used.Load() {
+		return 0
+	}
+	var expired []uint64
+	g.deadlines.Do(func(h uint64, deadline int64) {
+		if deadline <= now {
+			expired = append(expired, h)
+		}
+	})
+	n := 0
+	for _, h := range expired {
+		if !g.live(h, now) {
+			n++
+		}
+	}
+	return n
+}
+
+// wrapTombstoneCallbacks purges graves as ristretto expires their tombstones
+// and keeps tombstones away from user callbacks.
+func wrapTombstoneCallbacks(cfg *Config, g *graveyard) {
+	evict := cfg.OnEvict
+	cfg.OnEvict = func(item *ristretto.Item) {
+		if _, ok := item.Value.(tombstone); ok {
+			g.live(item.Key, time.Now().UnixNano())
+			return
+		}
+		if evict != nil {
+			evict(item)
+		}
+	}
+
+	if reject := cfg.OnReject; reject != nil {
+		cfg.OnReject = func(item *ristretto.Item) {
+			if _, ok := item.Value.(tombstone); !ok {
+				reject(item)
+			}
+		}
+	}
+
+	if exit := cfg.OnExit; exit != nil {
+		cfg.OnExit = func(val any) {
+			if _, ok := val.(tombstone); !ok {
+				exit(val)
+			}
+		}
+	}
+}
+
+// DeleteDelayed removes key like Delete but leaves a tombstone for
+// gracePeriod: Gets miss, and Sets of key are rejected (and reported to
+// OnDrop) until the grace period ends, so an intentionally invalidated key
+// is not repopulated by every caller that just missed it. gracePeriod <= 0
+// is a plain Delete.
+//
+// The tombstone is stored under the key with gracePeriod as its TTL, costs
+// one unit and counts in Stats like an entry; ristretto's expiry sweep
+// removes it. A tombstone the policy declines to keep is still honoured and
+// is purged on the next Set of its key or by PurgeTombstones. Namespaced
+// keys are not affected.
+func (c *Cache[K, V]) DeleteDelayed(key K, gracePeriod time.Duration) {
+	if gracePeriod <= 0 {
+		c.Delete(key)
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return
+	}
+
+	h := hashKey(key)
+	c.trace(TraceDelete, h, 0)
+	if c.shadow != nil {
+		c.shadow.engine.Del(h)
+	}
+	c.graves.bury(h, c.clock.Now()+int64(gracePeriod))
+
+	// Storing over a resident value is an update, which ristretto applies
+	// immediately; a key that is not resident needs no value removed.
+	ok := c.inner.SetWithTTL(h, tombstone{}, defaultCost, gracePeriod)
+	c.inner.Wait()
+	if !ok {
+		c.inner.Del(h)
+	}
+	c.forgetCost(h)
+	if ok {
+		c.recordCost(h, tombstone{}, defaultCost)
+	}
+	c.tags.remove(h)
+	if c.index != nil {
+		c.index.remove(h)
+	}
+}
+
+// PurgeTombstones drops every tombstone whose grace period has ended and
+// returns how many it dropped. Expired tombstones are purged on their own;
+// this only reclaims those the policy evicted or rejected early.
+func (c *Cache[K, V]) PurgeTombstones() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return 0
+	}
+	return c.graves.sweep(c.clock.Now())
+}
@@ -0,0 +1,173 @@
+package ristretto
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/mq/batcher"
+)
+
+// traceFlushInterval is how long a trace stripe may sit idle before its
+// events are handed to the sink.
+const traceFlushInterval = time.Second
+
+// traceRecordSize is the encoded size of a TraceEvent: op, key hash, cost
+// and timestamp.
+const traceRecordSize = 1 + 8 + 8 + 8
+
+// ErrBadTrace is returned by ReadTrace for a malformed trace.
+var ErrBadTrace = errors.New("ristretto: malformed trace")
+
+// TraceOp is the operation a TraceEvent records.
+type TraceOp uint8
+
+const (
+	TraceGet    TraceOp = iota + 1 // Get, hit or miss
+	TraceSet                       // Set, applied or not
+	TraceDelete                    // Delete or DeleteDelayed
+)
+
+// TraceEvent is one cache operation captured by Config.Trace. Keys are
+// recorded by hash only, so traces can leave the process without leaking
+// them.
+type TraceEvent struct {
+	Op      TraceOp
+	KeyHash uint64
+	Cost    int64 // charged cost for TraceSet, 0 otherwise
+	Time    int64 // Unix nanoseconds
+}
+
+// newTracer returns the batcher feeding sink, or nil without one.
+func newTracer(sink batcher.Consumer[TraceEvent]) *batcher.StripedBatcher[TraceEvent] {
+	if sink == nil {
+		return nil
+	}
+	return batcher.New(sink, batcher.Config{IdleTimeout: traceFlushInterval})
+}
+
+// trace records op on h if tracing is enabled.
+func (c *Cache[K, V]) trace(op TraceOp, h uint64, cost int64) {
+	if c.tracer != nil {
+		c.tracer.Push(TraceEvent{Op: op, KeyHash: h, Cost: cost, Time: time.Now().UnixNano()})
+	}
+}
+
+// TraceWriter is a Config.Trace sink that appends events to a writer in a
+// compact binary form ReadTrace understands. It is safe for concurrent use.
+type TraceWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+// NewTraceWriter returns a sink writing to w.
+func NewTraceWriter(w io.Writer) *TraceWriter {
+	return &TraceWriter{w: w}
+}
+
+// Consume implements batcher.Consumer. After a write error every batch is
+// dropped and Err reports it.
+func (t *TraceWriter) Consume(batch []TraceEvent) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return t.err
+	}
+
+	t.buf = t.buf[:0]
+	for _, e := range batch {
+		t.buf = append(t.buf, byte(e.Op))
+		t.buf = binary.LittleEndian.AppendUint64(t.buf, e.KeyHash)
+		t.buf = binary.LittleEndian.AppendUint64(t.buf, uint64(e.Cost))
+		t.buf = binary.LittleEndian.AppendUint64(t.buf, uint64(e.Time))
+	}
+	_, t.err = t.w.Write(t.buf)
+	return t.err
+}
+
+// Err returns the first write error.
+func (t *TraceWriter) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// ReadTrace reads every event written by a TraceWriter, ordered by Time.
+// Batches reach the writer as stripes fill, so the file itself groups
+// events by stripe rather than in the order they happened.
+func ReadTrace(r io.Reader) ([]TraceEvent, error) {
+	br := bufio.NewReader(r)
+	var events []TraceEvent
+	var rec [traceRecordSize]byte
+	for {
+		if _, err := io.ReadFull(br, rec[:]); err != nil {
+			if err == io.EOF {
+				slices.SortStableFunc(events, func(a, b TraceEvent) int { return cmp.Compare(a.Time, b.Time) })
+				return events, nil
+			}
+			return events, fmt.Errorf("%w: %w", ErrBadTrace, err)
+		}
+		e := TraceEvent{
+			Op:      TraceOp(rec[0]),
+			KeyHash: binary.LittleEndian.Uint64(rec[1:]),
+			Cost:    int64(binary.LittleEndian.Uint64(rec[9:])),
+			Time:    int64(binary.LittleEndian.Uint64(rec[17:])),
+		}
+		if e.Op < TraceGet || e.Op > TraceDelete {
+			return events, fmt.Errorf("%w: op %d at event %d", ErrBadTrace, e.Op, len(events))
+		}
+		events = append(events, e)
+	}
+}
+
+// ReplayStats is the outcome of replaying a trace.
+type ReplayStats struct {
+	Gets uint64
+	Hits uint64
+}
+
+// HitRatio returns Hits/Gets, or 0 without Gets.
+func (s ReplayStats) HitRatio() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Gets)
+}
+
+// Replay runs events in order against a fresh cache built with opts, e.g.
+// WithMaxCost and WithNumCounters to compare capacities, and reports its
+// hit ratio. Sets are replayed with their recorded cost and no TTL.
+// Replay is sequential, so admission is as the policy decides without the
+// buffer drops of a loaded cache; compare ratios between settings rather
+// than against production.
+func Replay(events []TraceEvent, opts ...Option) (ReplayStats, error) {
+	c, err := New[uint64, struct{}](opts...)
+	if err != nil {
+		return ReplayStats{}, err
+	}
+	defer c.Close()
+
+	var s ReplayStats
+	for _, e := range events {
+		switch e.Op {
+		case TraceGet:
+			s.Gets++
+			if _, ok := c.Get(e.KeyHash); ok {
+				s.Hits++
+			}
+		case TraceSet:
+			c.set(e.KeyHash, struct{}{}, e.Cost, 0, nil)
+		case TraceDelete:
+			c.Delete(e.KeyHash)
+		}
+	}
+	return s, nil
+}
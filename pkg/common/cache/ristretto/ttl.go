@@ -0,0 +1,60 @@
+package ristretto
+
+import (
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/cache"
+)
+
+// Touch sets the TTL of a resident entry to newTTL without re-encoding or
+// re-admitting the value, e.g. to extend a session on access. newTTL is
+// jittered like SetWithTTL and must be positive; use Persist to remove the
+// expiry. Returns false if key is not resident.
+//
+// Ristretto has no TTL-only update, so the resident value is stored again
+// under its key: the expiry changes immediately and the policy sees an
+// update, not a new item. A Set racing with Touch may be overwritten by the
+// value Touch read. OnExit is called with the value as for any update, so
+// caches whose OnExit recycles values should not use Touch.
+func (c *Cache[K, V]) Touch(key K, newTTL time.Duration) bool {
+	if newTTL <= 0 {
+		return false
+	}
+	return c.retime(key, cache.JitterTTL(newTTL, c.jitter))
+}
+
+// Persist removes the TTL of a resident entry so it only leaves the cache on
+// eviction or Delete. Returns false if key is not resident. The caveats of
+// Touch apply.
+func (c *Cache[K, V]) Persist(key K) bool {
+	return c.retime(key, 0)
+}
+
+// retime stores the resident value of key again with ttl.
+func (c *Cache[K, V]) retime(key K, ttl time.Duration) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return false
+	}
+
+	h := hashKey(key)
+	val, ok := c.inner.Get(h)
+	if !ok {
+		return false
+	}
+	if _, dead := val.(tombstone); dead {
+		return false
+	}
+	if _, isC := val.(compressed); !isC {
+		if _, isV := val.(V); !isV {
+			return false
+		}
+	}
+	ok = c.inner.SetWithTTL(h, val, c.cost(), ttl)
+	c.inner.Wait()
+	if ok {
+		c.recordCost(h, val, c.cost())
+	}
+	return ok
+}
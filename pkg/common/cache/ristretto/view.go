@@ -0,0 +1,84 @@
+package ristretto
+
+import (
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/cache"
+)
+
+// EntryView describes a resident entry in a View. Values are left out, so a
+// View can be served by debug endpoints without exposing cached data.
+type EntryView[K any] struct {
+	Key K
+
+	// TTL is the time left before the entry expires, 0 if it never does.
+	TTL time.Duration
+
+	// Cost is the cost the entry was charged on Set, as in ItemInfo. It is
+	// -1 when a Config.Cost function priced the entry and the cache has no
+	// WithCostAudit ledger to recall the price from.
+	Cost int64
+
+	// Frequency is the estimated number of recent accesses.
+	Frequency int64
+}
+
+// View is a read-only picture of the cache's composition at one moment.
+type View[K any] struct {
+	Taken   time.Time
+	Stats   cache.Stats
+	Entries []EntryView[K] // hottest first
+}
+
+// Snapshot returns a View of up to maxEntries of the hottest resident
+// entries, all of them if maxEntries <= 0, for admin and /debug handlers.
+// It reads the key index without touching the entries: it counts no hits,
+// feeds no access to the admission policy and takes no lock that blocks
+// Gets or Sets for more than one index lookup. Entries are described as the
+// view is built, so under load it is a near-snapshot rather than an atomic
+// one.
+//
+// Requires WithSnapshots. Namespaced entries are not included.
+func (c *Cache[K, V]) Snapshot(maxEntries int) (View[K], error) {
+	if c.index == nil {
+		return View[K]{}, ErrSnapshotsDisabled
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return View[K]{}, ErrClosed
+	}
+
+	v := View[K]{Taken: time.Now(), Stats: c.Stats()}
+	for _, k := range c.index.hottest() {
+		if maxEntries > 0 && len(v.Entries) >= maxEntries {
+			break
+		}
+		ttl, ok := c.inner.GetTTL(k.hash)
+		if !ok {
+			continue
+		}
+		v.Entries = append(v.Entries, EntryView[K]{
+			Key:       k.key,
+			TTL:       ttl,
+			Cost:      c.chargedCost(k.hash),
+			Frequency: k.freq,
+		})
+	}
+	return v, nil
+}
+
+// chargedCost returns the cost h was charged on Set, without reading its
+// value, or -1 if only the value could tell.
+func (c *Cache[K, V]) chargedCost(h uint64) int64 {
+	if c.ledger != nil {
+		if cost, ok := c.ledger.costs.Get(h); ok {
+			return cost - c.ledger.internal
+		}
+	}
+	if c.costFn == nil {
+		return defaultCost
+	}
+	return -1
+}
@@ -0,0 +1,199 @@
+// Package debughttp serves the stats of go-common components as JSON for
+// admin and /debug endpoints: register each cache, queue, batcher or pool
+// under a name and mount the Registry's Handler.
+//
+//	dbg := debughttp.New(debughttp.WithPprof())
+//	_ = dbg.Register("users-cache", debughttp.Stats(usersCache.Stats))
+//	_ = dbg.Register("events", debughttp.Stats(eventBatcher.Stats))
+//	mux.Handle("/debug/", dbg.Handler())
+package debughttp
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/huynhanx03/go-common/pkg/encoding/json"
+)
+
+// StatsProvider is a component whose stats can be served. DebugStats
+// returns a JSON-encodable value and is called on every request, so it
+// should be as cheap as the component's own Stats method.
+type StatsProvider interface {
+	DebugStats() any
+}
+
+// statsFunc adapts a typed Stats method to StatsProvider.
+type statsFunc[S any] func() S
+
+func (f statsFunc[S]) DebugStats() any { return f() }
+
+// Stats adapts a component's Stats method, whatever its return type, to a
+// StatsProvider: debughttp.Stats(cache.Stats), debughttp.Stats(tree.Stats).
+func Stats[S any](fn func() S) StatsProvider {
+	return statsFunc[S](fn)
+}
+
+// profiles are the pprof endpoints linked from the index when Pprof is on.
+var profiles = []string{
+	"allocs", "block", "cmdline", "goroutine", "heap", "mutex",
+	"profile", "threadcreate", "trace",
+}
+
+// Registry holds named components and serves their stats.
+// It is safe for concurrent use.
+type Registry struct {
+	config Config
+
+	mu        sync.RWMutex
+	providers map[string]StatsProvider
+}
+
+// New creates an empty registry.
+func New(opts ...Option) *Registry {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &Registry{
+		config:    cfg,
+		providers: make(map[string]StatsProvider),
+	}
+}
+
+// Register adds a component under name, which becomes its path segment.
+func (r *Registry) Register(name string, p StatsProvider) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.providers[name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateName, name)
+	}
+	r.providers[name] = p
+	return nil
+}
+
+// Unregister removes the component named name, e.g. when it is closed.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	delete(r.providers, name)
+	r.mu.Unlock()
+}
+
+// Names returns the registered component names, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	slices.Sort(names)
+	return names
+}
+
+// Collect returns the stats of every component by name. A provider that
+// panics is reported as {"error": ...} instead of failing the rest.
+func (r *Registry) Collect() map[string]any {
+	r.mu.RLock()
+	providers := make(map[string]StatsProvider, len(r.providers))
+	for name, p := range r.providers {
+		providers[name] = p
+	}
+	r.mu.RUnlock()
+
+	out := make(map[string]any, len(providers))
+	for name, p := range providers {
+		out[name] = collect(p)
+	}
+	return out
+}
+
+// collect calls p, turning a panic into an error entry.
+func collect(p StatsProvider) (stats any) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			stats = map[string]string{"error": fmt.Sprint(rec)}
+		}
+	}()
+	return p.DebugStats()
+}
+
+// index is the document served at the handler's root.
+type index struct {
+	Components map[string]string `json:"components"` // name -> stats URL
+	Stats      string            `json:"stats"`
+	Pprof      map[string]string `json:"pprof,omitempty"` // profile -> URL
+}
+
+// Handler serves, under any prefix:
+//
+//	stats          every component's stats
+//	stats/<name>   one component's stats
+//	pprof/<name>   a runtime profile, with WithPprof
+//
+// and an index linking them at every other path.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path := strings.TrimSuffix(req.URL.Path, "/")
+		dir, last := splitLast(path)
+		_, parent := splitLast(dir)
+
+		r.mu.RLock()
+		p, ok := r.providers[last]
+		r.mu.RUnlock()
+
+		switch {
+		case parent == "stats" && ok:
+			writeJSON(w, http.StatusOK, collect(p))
+		case last == "stats":
+			writeJSON(w, http.StatusOK, r.Collect())
+		case parent == "stats":
+			http.NotFound(w, req)
+		case parent == "pprof" && r.config.Pprof:
+			servePprof(w, req, last)
+		default:
+			r.serveIndex(w, path)
+		}
+	})
+}
+
+// serveIndex links every endpoint relative to base, the path of the index.
+func (r *Registry) serveIndex(w http.ResponseWriter, base string) {
+	base += "/"
+	idx := index{
+		Components: make(map[string]string),
+		Stats:      base + "stats",
+	}
+	for _, name := range r.Names() {
+		idx.Components[name] = base + "stats/" + name
+	}
+	if r.config.Pprof {
+		idx.Pprof = make(map[string]string, len(profiles))
+		for _, p := range profiles {
+			idx.Pprof[p] = base + "pprof/" + p
+		}
+	}
+	writeJSON(w, http.StatusOK, idx)
+}
+
+// splitLast splits path at its last '/'.
+func splitLast(path string) (dir, last string) {
+	i := strings.LastIndexByte(path, '/')
+	if i < 0 {
+		return "", path
+	}
+	return path[:i], path[i+1:]
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
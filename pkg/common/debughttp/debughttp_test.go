@@ -0,0 +1,102 @@
+package debughttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/encoding/json"
+)
+
+type queueStats struct {
+	Depth    int64
+	Dequeued uint64
+}
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestRegister(t *testing.T) {
+	r := New()
+	p := Stats(func() int { return 1 })
+	if err := r.Register("q", p); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("q", p); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("duplicate err = %v, want ErrDuplicateName", err)
+	}
+	for _, name := range []string{"", "a/b"} {
+		if err := r.Register(name, p); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Register(%q) err = %v, want ErrInvalidName", name, err)
+		}
+	}
+	r.Register("a", p)
+	if got := strings.Join(r.Names(), ","); got != "a,q" {
+		t.Errorf("Names = %s", got)
+	}
+	r.Unregister("a")
+	if len(r.Names()) != 1 {
+		t.Errorf("Names after Unregister = %v", r.Names())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := New()
+	r.Register("jobs", Stats(func() queueStats { return queueStats{Depth: 3, Dequeued: 7} }))
+	r.Register("broken", Stats(func() int { panic("boom") }))
+	h := r.Handler()
+
+	rec := get(t, h, "/debug/stats/jobs")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("one component: code %d, type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var qs queueStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &qs); err != nil || qs.Depth != 3 || qs.Dequeued != 7 {
+		t.Errorf("body %s decoded to %+v, %v", rec.Body, qs, err)
+	}
+
+	rec = get(t, h, "/debug/stats")
+	var all map[string]map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &all); err != nil {
+		t.Fatalf("all stats %s: %v", rec.Body, err)
+	}
+	if all["jobs"]["Depth"] != float64(3) || all["broken"]["error"] != "boom" {
+		t.Errorf("all stats = %v", all)
+	}
+
+	if rec := get(t, h, "/debug/stats/missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown component: code %d, want 404", rec.Code)
+	}
+	if rec := get(t, h, "/debug/pprof/heap"); strings.Contains(rec.Body.String(), "heap profile") {
+		t.Error("pprof served without WithPprof")
+	}
+}
+
+func TestHandlerIndex(t *testing.T) {
+	r := New(WithPprof())
+	r.Register("jobs", Stats(func() int { return 0 }))
+	h := r.Handler()
+
+	rec := get(t, h, "/admin/debug/")
+	var idx index
+	if err := json.Unmarshal(rec.Body.Bytes(), &idx); err != nil {
+		t.Fatalf("index %s: %v", rec.Body, err)
+	}
+	if idx.Stats != "/admin/debug/stats" || idx.Components["jobs"] != "/admin/debug/stats/jobs" {
+		t.Errorf("index links = %+v", idx)
+	}
+	if idx.Pprof["heap"] != "/admin/debug/pprof/heap" {
+		t.Errorf("pprof links = %v", idx.Pprof)
+	}
+
+	rec = get(t, h, "/admin/debug/pprof/goroutine?debug=1")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine profile") {
+		t.Errorf("goroutine profile: code %d, body %.60q", rec.Code, rec.Body)
+	}
+}
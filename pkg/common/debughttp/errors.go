@@ -0,0 +1,12 @@
+package debughttp
+
+import "errors"
+
+var (
+	// ErrDuplicateName is returned when a component name is registered twice.
+	ErrDuplicateName = errors.New("debughttp: duplicate component name")
+
+	// ErrInvalidName is returned for an empty name or one containing '/',
+	// which could not be addressed as a path segment.
+	ErrInvalidName = errors.New("debughttp: invalid component name")
+)
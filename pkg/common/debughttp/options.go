@@ -0,0 +1,22 @@
+package debughttp
+
+// Config holds the handler settings.
+type Config struct {
+	// Pprof serves the runtime profiles under pprof/ next to the stats,
+	// e.g. /debug/pprof/heap when the handler is mounted at /debug/.
+	// Profiles reveal code paths and memory contents, so it is off by
+	// default; only enable it behind an admin-only listener or auth.
+	Pprof bool
+}
+
+// Option configures a Registry.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{}
+}
+
+// WithPprof sets Config.Pprof.
+func WithPprof() Option {
+	return func(c *Config) { c.Pprof = true }
+}
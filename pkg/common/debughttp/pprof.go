@@ -0,0 +1,74 @@
+package debughttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default durations of the sampled profiles, as in net/http/pprof.
+const (
+	defaultProfileSeconds = 30
+	defaultTraceSeconds   = 1
+)
+
+// servePprof serves the named profile through runtime/pprof. It does not
+// use net/http/pprof, whose import registers handlers on
+// http.DefaultServeMux, which would expose profiles even with Pprof off.
+// Query parameters follow net/http/pprof: seconds for profile and trace,
+// debug for text output of the other profiles.
+func servePprof(w http.ResponseWriter, req *http.Request, name string) {
+	switch name {
+	case "cmdline":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, strings.Join(os.Args, "\x00"))
+	case "profile":
+		sampled(w, req, defaultProfileSeconds, pprof.StartCPUProfile, pprof.StopCPUProfile)
+	case "trace":
+		sampled(w, req, defaultTraceSeconds, trace.Start, trace.Stop)
+	default:
+		p := pprof.Lookup(name)
+		if p == nil {
+			http.NotFound(w, req)
+			return
+		}
+		debug, _ := strconv.Atoi(req.FormValue("debug"))
+		if debug != 0 {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		}
+		_ = p.WriteTo(w, debug)
+	}
+}
+
+// sampled records a profile into w for the requested number of seconds,
+// stopping early if the client goes away.
+func sampled(w http.ResponseWriter, req *http.Request, defaultSeconds int, start func(io.Writer) error, stop func()) {
+	seconds, err := strconv.Atoi(req.FormValue("seconds"))
+	if err != nil || seconds <= 0 {
+		seconds = defaultSeconds
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if err := start(w); err != nil {
+		// Only one CPU profile or trace can run at a time.
+		w.Header().Del("Content-Type")
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	defer stop()
+
+	timer := time.NewTimer(time.Duration(seconds) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-req.Context().Done():
+	}
+}
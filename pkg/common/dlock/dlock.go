@@ -0,0 +1,74 @@
+// Package dlock provides named locks with time-bounded leases and fencing
+// tokens behind one Locker interface. Local locks within a process;
+// Remote coordinates processes through a pluggable Backend (Redis, etcd,
+// a database row) and queues contenders of the same process on a Local
+// first, so only one goroutine per key polls the backend.
+//
+// A lease ends when it is unlocked or its TTL passes, whichever is first.
+// A holder that stalls past its TTL (GC pause, network partition) may
+// still believe it owns the lock, so every lease carries a fencing token:
+// tokens issued for a key only ever increase, and the protected resource
+// should reject writes carrying a token lower than the last one it saw.
+package dlock
+
+import (
+	"context"
+	"time"
+)
+
+// Locker acquires leases on named locks.
+type Locker interface {
+	// Lock blocks until key is free, then holds it for ttl. It returns
+	// ctx.Err() if ctx ends first.
+	Lock(ctx context.Context, key string, ttl time.Duration) (*Lease, error)
+
+	// TryLock is Lock without waiting: ErrLocked if key is held.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (*Lease, error)
+}
+
+// Backend is the store a Remote locker coordinates through. Implementations
+// must be safe for concurrent use; Local is one.
+type Backend interface {
+	// Acquire takes key for ttl if it is free or its lease has expired,
+	// returning a token greater than any issued for key before. ok is
+	// false if another owner holds key.
+	Acquire(ctx context.Context, key string, ttl time.Duration) (token uint64, ok bool, err error)
+
+	// Refresh extends the lease identified by token to ttl from now.
+	// ErrNotHeld if it has expired or was released.
+	Refresh(ctx context.Context, key string, token uint64, ttl time.Duration) error
+
+	// Release frees the lease identified by token. ErrNotHeld if it has
+	// expired or was already released.
+	Release(ctx context.Context, key string, token uint64) error
+}
+
+// Lease is a held lock. It is safe for concurrent use.
+type Lease struct {
+	key     string
+	token   uint64
+	refresh func(ctx context.Context, ttl time.Duration) error
+	release func(ctx context.Context) error
+}
+
+// Key returns the locked key.
+func (l *Lease) Key() string { return l.key }
+
+// Token returns the fencing token: pass it along with every write made
+// under the lock so the resource can reject writes from stale holders.
+func (l *Lease) Token() uint64 { return l.token }
+
+// Refresh extends the lease to ttl from now. ErrNotHeld if it has already
+// expired or been unlocked.
+func (l *Lease) Refresh(ctx context.Context, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	return l.refresh(ctx, ttl)
+}
+
+// Unlock releases the lease. ErrNotHeld if it has already expired or been
+// unlocked; the lock is free either way.
+func (l *Lease) Unlock(ctx context.Context) error {
+	return l.release(ctx)
+}
@@ -0,0 +1,10 @@
+package dlock
+
+import "errors"
+
+// Sentinel errors for locks and backends.
+var (
+	ErrLocked     = errors.New("dlock: lock held by another owner")
+	ErrNotHeld    = errors.New("dlock: lock not held")
+	ErrInvalidTTL = errors.New("dlock: ttl must be positive")
+)
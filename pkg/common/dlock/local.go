@@ -0,0 +1,174 @@
+package dlock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	_ Locker  = (*Local)(nil)
+	_ Backend = (*Local)(nil)
+)
+
+// holder is the current lease on one key.
+type holder struct {
+	token    uint64
+	deadline time.Time
+	released chan struct{} // closed when the lease is unlocked
+}
+
+// Local is an in-process Locker: a keyed mutex whose holders lose the key
+// once their lease expires. Expiry is checked lazily, so it costs no
+// goroutine or timer per lease, and an expired key keeps its entry until it
+// is next used. Tokens come from one counter shared by all keys and
+// increase across Local's lifetime. It also implements Backend, which makes
+// it a stand-in for a remote store in tests.
+//
+// The zero value is not usable; call NewLocal.
+type Local struct {
+	mu      sync.Mutex
+	holders map[string]*holder
+	next    uint64 // last token issued
+}
+
+// NewLocal creates an empty Local.
+func NewLocal() *Local {
+	return &Local{holders: make(map[string]*holder)}
+}
+
+// Lock implements Locker.
+func (l *Local) Lock(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+	for {
+		token, h, err := l.acquire(ctx, key, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if h == nil {
+			return l.lease(key, token), nil
+		}
+
+		t := time.NewTimer(time.Until(h.deadline))
+		select {
+		case <-h.released:
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+		t.Stop()
+	}
+}
+
+// TryLock implements Locker.
+func (l *Local) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	token, ok, err := l.Acquire(ctx, key, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLocked
+	}
+	return l.lease(key, token), nil
+}
+
+// Acquire implements Backend.
+func (l *Local) Acquire(ctx context.Context, key string, ttl time.Duration) (uint64, bool, error) {
+	if ttl <= 0 {
+		return 0, false, ErrInvalidTTL
+	}
+	token, h, err := l.acquire(ctx, key, ttl)
+	return token, h == nil && err == nil, err
+}
+
+// Refresh implements Backend.
+func (l *Local) Refresh(_ context.Context, key string, token uint64, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h := l.held(key, token, time.Now())
+	if h == nil {
+		return ErrNotHeld
+	}
+	h.deadline = time.Now().Add(ttl)
+	return nil
+}
+
+// Release implements Backend.
+func (l *Local) Release(_ context.Context, key string, token uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h := l.held(key, token, time.Now())
+	if h == nil {
+		return ErrNotHeld
+	}
+	l.drop(key, h)
+	return nil
+}
+
+// acquire takes key if it is free or expired, returning the new token.
+// Otherwise it returns the current holder to wait on.
+func (l *Local) acquire(ctx context.Context, key string, ttl time.Duration) (uint64, *holder, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if h, ok := l.holders[key]; ok {
+		if now.Before(h.deadline) {
+			return 0, h, nil
+		}
+		l.drop(key, h) // expired: wake its waiters along with taking over
+	}
+
+	l.next++
+	l.holders[key] = &holder{
+		token:    l.next,
+		deadline: now.Add(ttl),
+		released: make(chan struct{}),
+	}
+	return l.next, nil, nil
+}
+
+// held returns the live holder of key with token, dropping it if it has
+// expired. Caller holds l.mu.
+func (l *Local) held(key string, token uint64, now time.Time) *holder {
+	h, ok := l.holders[key]
+	if !ok || h.token != token {
+		return nil
+	}
+	if !now.Before(h.deadline) {
+		l.drop(key, h)
+		return nil
+	}
+	return h
+}
+
+// drop frees key and wakes its waiters. Caller holds l.mu.
+func (l *Local) drop(key string, h *holder) {
+	delete(l.holders, key)
+	close(h.released)
+}
+
+func (l *Local) lease(key string, token uint64) *Lease {
+	return &Lease{
+		key:   key,
+		token: token,
+		refresh: func(ctx context.Context, ttl time.Duration) error {
+			return l.Refresh(ctx, key, token, ttl)
+		},
+		release: func(ctx context.Context) error {
+			return l.Release(ctx, key, token)
+		},
+	}
+}
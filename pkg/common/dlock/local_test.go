@@ -0,0 +1,164 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Locking
+// ============================================================================
+
+func TestLocal_MutualExclusion(t *testing.T) {
+	l := NewLocal()
+	ctx := context.Background()
+
+	var (
+		wg     sync.WaitGroup
+		inside atomic.Int32
+	)
+	for range 16 {
+		wg.Go(func() {
+			for range 50 {
+				lease, err := l.Lock(ctx, "k", time.Minute)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if n := inside.Add(1); n != 1 {
+					t.Errorf("%d holders at once", n)
+				}
+				inside.Add(-1)
+				if err := lease.Unlock(ctx); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestLocal_TryLock(t *testing.T) {
+	l := NewLocal()
+	ctx := context.Background()
+
+	lease, err := l.TryLock(ctx, "k", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.TryLock(ctx, "k", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Errorf("second TryLock err = %v, want ErrLocked", err)
+	}
+	if _, err := l.TryLock(ctx, "other", time.Minute); err != nil {
+		t.Errorf("TryLock on another key: %v", err)
+	}
+
+	if err := lease.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := lease.Unlock(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("second Unlock err = %v, want ErrNotHeld", err)
+	}
+	if _, err := l.TryLock(ctx, "k", time.Minute); err != nil {
+		t.Errorf("TryLock after Unlock: %v", err)
+	}
+}
+
+func TestLocal_InvalidTTL(t *testing.T) {
+	l := NewLocal()
+	if _, err := l.Lock(context.Background(), "k", 0); !errors.Is(err, ErrInvalidTTL) {
+		t.Errorf("Lock err = %v, want ErrInvalidTTL", err)
+	}
+}
+
+func TestLocal_ContextCancel(t *testing.T) {
+	l := NewLocal()
+	if _, err := l.Lock(context.Background(), "k", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Lock(ctx, "k", time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Lock err = %v, want DeadlineExceeded", err)
+	}
+}
+
+// ============================================================================
+// Leases and fencing
+// ============================================================================
+
+func TestLocal_LeaseExpires(t *testing.T) {
+	l := NewLocal()
+	ctx := context.Background()
+
+	stale, err := l.Lock(ctx, "k", 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocks until the stale lease lapses.
+	fresh, err := l.Lock(ctx, "k", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.Token() <= stale.Token() {
+		t.Errorf("fresh token %d not above stale %d", fresh.Token(), stale.Token())
+	}
+
+	if err := stale.Refresh(ctx, time.Minute); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("stale Refresh err = %v, want ErrNotHeld", err)
+	}
+	if err := stale.Unlock(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("stale Unlock err = %v, want ErrNotHeld", err)
+	}
+	if _, err := l.TryLock(ctx, "k", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Errorf("stale Unlock freed the fresh lease: TryLock err = %v", err)
+	}
+}
+
+func TestLocal_Refresh(t *testing.T) {
+	l := NewLocal()
+	ctx := context.Background()
+
+	lease, err := l.Lock(ctx, "k", 30*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lease.Refresh(ctx, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := l.TryLock(ctx, "k", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Errorf("TryLock after Refresh err = %v, want ErrLocked", err)
+	}
+	if err := lease.Refresh(ctx, -1); !errors.Is(err, ErrInvalidTTL) {
+		t.Errorf("Refresh(-1) err = %v, want ErrInvalidTTL", err)
+	}
+}
+
+func TestLocal_TokensIncrease(t *testing.T) {
+	l := NewLocal()
+	ctx := context.Background()
+
+	var last uint64
+	for _, key := range []string{"a", "b", "a", "c", "a"} {
+		lease, err := l.Lock(ctx, key, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lease.Token() <= last {
+			t.Fatalf("token %d not above %d", lease.Token(), last)
+		}
+		last = lease.Token()
+		if lease.Key() != key {
+			t.Errorf("Key = %q, want %q", lease.Key(), key)
+		}
+		_ = lease.Unlock(ctx)
+	}
+}
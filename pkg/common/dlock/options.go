@@ -0,0 +1,29 @@
+package dlock
+
+import "time"
+
+// DefaultRetryInterval is how often Remote.Lock polls the backend while
+// another process holds the key.
+const DefaultRetryInterval = 50 * time.Millisecond
+
+// Config holds the settings of a Remote locker.
+type Config struct {
+	RetryInterval time.Duration // backend poll period while the key is held
+}
+
+// Option configures a Remote locker.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{RetryInterval: DefaultRetryInterval}
+}
+
+// WithRetryInterval sets how often Lock polls the backend. Non-positive
+// values are ignored.
+func WithRetryInterval(d time.Duration) Option {
+	return func(c *Config) {
+		if d > 0 {
+			c.RetryInterval = d
+		}
+	}
+}
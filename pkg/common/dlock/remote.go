@@ -0,0 +1,107 @@
+package dlock
+
+import (
+	"context"
+	"time"
+)
+
+var _ Locker = (*Remote)(nil)
+
+// Remote is a Locker that coordinates processes through a Backend. Within
+// the process, contenders for a key first queue on a Local, so the backend
+// sees one poller per key and process however many goroutines wait.
+// Fencing tokens come from the backend.
+type Remote struct {
+	backend Backend
+	local   *Local
+	config  Config
+}
+
+// NewRemote creates a Remote locker over b.
+func NewRemote(b Backend, opts ...Option) *Remote {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &Remote{backend: b, local: NewLocal(), config: cfg}
+}
+
+// Lock implements Locker. It polls the backend every RetryInterval while
+// another process holds key.
+func (r *Remote) Lock(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	return r.lock(ctx, key, ttl, true)
+}
+
+// TryLock implements Locker.
+func (r *Remote) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	return r.lock(ctx, key, ttl, false)
+}
+
+func (r *Remote) lock(ctx context.Context, key string, ttl time.Duration, wait bool) (*Lease, error) {
+	var (
+		gate *Lease
+		err  error
+	)
+	if wait {
+		gate, err = r.local.Lock(ctx, key, ttl)
+	} else {
+		gate, err = r.local.TryLock(ctx, key, ttl)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := r.acquire(ctx, key, ttl, wait)
+	if err != nil {
+		_ = gate.Unlock(context.Background())
+		return nil, err
+	}
+
+	return &Lease{
+		key:   key,
+		token: token,
+		refresh: func(ctx context.Context, ttl time.Duration) error {
+			if err := r.backend.Refresh(ctx, key, token, ttl); err != nil {
+				return err
+			}
+			// The gate may have lapsed while the backend lease is still
+			// good; only the backend decides ownership.
+			_ = gate.Refresh(ctx, ttl)
+			return nil
+		},
+		release: func(ctx context.Context) error {
+			err := r.backend.Release(ctx, key, token)
+			_ = gate.Unlock(ctx) // ErrNotHeld once the gate has lapsed
+			return err
+		},
+	}, nil
+}
+
+// acquire takes key on the backend, polling while it is held if wait is set.
+func (r *Remote) acquire(ctx context.Context, key string, ttl time.Duration, wait bool) (uint64, error) {
+	var t *time.Timer
+	for {
+		token, ok, err := r.backend.Acquire(ctx, key, ttl)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return token, nil
+		}
+		if !wait {
+			return 0, ErrLocked
+		}
+
+		if t == nil {
+			t = time.NewTimer(r.config.RetryInterval)
+			defer t.Stop()
+		} else {
+			t.Reset(r.config.RetryInterval)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingBackend records backend traffic on top of a Local.
+type countingBackend struct {
+	*Local
+	acquires atomic.Int64
+	fail     error
+}
+
+func (b *countingBackend) Acquire(ctx context.Context, key string, ttl time.Duration) (uint64, bool, error) {
+	b.acquires.Add(1)
+	if b.fail != nil {
+		return 0, false, b.fail
+	}
+	return b.Local.Acquire(ctx, key, ttl)
+}
+
+func TestRemote_ExcludesAcrossProcesses(t *testing.T) {
+	backend := NewLocal()
+	a := NewRemote(backend, WithRetryInterval(time.Millisecond))
+	b := NewRemote(backend, WithRetryInterval(time.Millisecond))
+	ctx := context.Background()
+
+	var (
+		wg     sync.WaitGroup
+		inside atomic.Int32
+	)
+	for i := range 8 {
+		r := a
+		if i%2 == 1 {
+			r = b
+		}
+		wg.Go(func() {
+			for range 20 {
+				lease, err := r.Lock(ctx, "k", time.Minute)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if n := inside.Add(1); n != 1 {
+					t.Errorf("%d holders at once", n)
+				}
+				inside.Add(-1)
+				if err := lease.Unlock(ctx); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestRemote_LocalGateLimitsPolling(t *testing.T) {
+	backend := &countingBackend{Local: NewLocal()}
+	r := NewRemote(backend)
+	ctx := context.Background()
+
+	lease, err := r.Lock(ctx, "k", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Local contenders queue on the gate without reaching the backend.
+	for range 4 {
+		if _, err := r.TryLock(ctx, "k", time.Minute); !errors.Is(err, ErrLocked) {
+			t.Fatalf("TryLock err = %v, want ErrLocked", err)
+		}
+	}
+	if n := backend.acquires.Load(); n != 1 {
+		t.Errorf("backend Acquire calls = %d, want 1", n)
+	}
+
+	if err := lease.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.TryLock(ctx, "k", time.Minute); err != nil {
+		t.Errorf("TryLock after Unlock: %v", err)
+	}
+}
+
+func TestRemote_TokensFromBackend(t *testing.T) {
+	backend := NewLocal()
+	r := NewRemote(backend)
+	ctx := context.Background()
+
+	lease, err := r.Lock(ctx, "k", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Release(ctx, "k", lease.Token()); err != nil {
+		t.Errorf("backend does not know token %d: %v", lease.Token(), err)
+	}
+	if err := lease.Unlock(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Unlock err = %v, want ErrNotHeld", err)
+	}
+}
+
+func TestRemote_BackendError(t *testing.T) {
+	boom := errors.New("boom")
+	backend := &countingBackend{Local: NewLocal(), fail: boom}
+	r := NewRemote(backend)
+	ctx := context.Background()
+
+	if _, err := r.Lock(ctx, "k", time.Minute); !errors.Is(err, boom) {
+		t.Fatalf("Lock err = %v, want boom", err)
+	}
+
+	// The failed attempt released the local gate.
+	backend.fail = nil
+	if _, err := r.TryLock(ctx, "k", time.Minute); err != nil {
+		t.Errorf("TryLock after failure: %v", err)
+	}
+}
+
+func TestRemote_WaitsForOtherProcess(t *testing.T) {
+	backend := NewLocal()
+	other, err := backend.TryLock(context.Background(), "k", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRemote(backend, WithRetryInterval(5*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := r.Lock(ctx, "k", time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Lock err = %v, want DeadlineExceeded", err)
+	}
+
+	time.AfterFunc(10*time.Millisecond, func() { _ = other.Unlock(context.Background()) })
+	lease, err := r.Lock(context.Background(), "k", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lease.Token() <= other.Token() {
+		t.Errorf("token %d not above previous holder's %d", lease.Token(), other.Token())
+	}
+}
@@ -0,0 +1,249 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// parser is a recursive-descent parser that builds the predicate closures
+// directly, without an intermediate tree.
+type parser[T any] struct {
+	expr   string
+	toks   []token
+	pos    int
+	lookup func(name string) (func(T) any, bool)
+}
+
+func compile[T any](expr string, lookup func(string) (func(T) any, bool)) (Predicate[T], error) {
+	toks, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser[T]{expr: expr, toks: toks, lookup: lookup}
+	pred, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return pred, nil
+}
+
+func (p *parser[T]) peek() token { return p.toks[p.pos] }
+
+func (p *parser[T]) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser[T]) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.errorf(t, "expected %s", what)
+	}
+	return t, nil
+}
+
+func (p *parser[T]) errorf(t token, format string, args ...any) error {
+	return syntaxError(p.expr, t.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser[T]) or() (Predicate[T], error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item T) bool { return l(item) || right(item) }
+	}
+	return left, nil
+}
+
+func (p *parser[T]) and() (Predicate[T], error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item T) bool { return l(item) && right(item) }
+	}
+	return left, nil
+}
+
+func (p *parser[T]) unary() (Predicate[T], error) {
+	switch t := p.next(); t.kind {
+	case tokNot:
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(item T) bool { return !inner(item) }, nil
+	case tokLParen:
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case tokIdent:
+		return p.comparison(t)
+	default:
+		return nil, p.errorf(t, "expected field, \"!\" or \"(\"")
+	}
+}
+
+func (p *parser[T]) comparison(field token) (Predicate[T], error) {
+	get, ok := p.lookup(field.text)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q in %q", ErrUnknownField, field.text, p.expr)
+	}
+
+	t := p.peek()
+	switch {
+	case t.kind == tokOp:
+		p.next()
+		lit, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		return p.compareOp(get, t, lit)
+	case t.kind == tokIdent && t.text == "in":
+		p.next()
+		return p.in(get)
+	default:
+		// A bare field tests for true.
+		return func(item T) bool {
+			v := valueOf(get(item))
+			return v.kind == kindBool && v.b
+		}, nil
+	}
+}
+
+func (p *parser[T]) compareOp(get func(T) any, op token, lit value) (Predicate[T], error) {
+	if op.text != "==" && op.text != "!=" && !lit.kind.ordered() {
+		return nil, p.errorf(op, "%s needs a number or string operand", op.text)
+	}
+
+	var test func(c int) bool
+	switch op.text {
+	case "==":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		return func(item T) bool {
+			c, ok := compare(valueOf(get(item)), lit)
+			return !ok || c != 0
+		}, nil
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	}
+
+	// Fast path for the most common rule shape: a string field against a
+	// string literal.
+	if op.text == "==" && lit.kind == kindString {
+		return func(item T) bool {
+			if s, ok := get(item).(string); ok {
+				return s == lit.s
+			}
+			c, ok := compare(valueOf(get(item)), lit)
+			return ok && c == 0
+		}, nil
+	}
+	return func(item T) bool {
+		c, ok := compare(valueOf(get(item)), lit)
+		return ok && test(c)
+	}, nil
+}
+
+func (p *parser[T]) in(get func(T) any) (Predicate[T], error) {
+	if _, err := p.expect(tokLBrack, `"["`); err != nil {
+		return nil, err
+	}
+
+	var lits []value
+	strs := make(map[string]struct{})
+	for {
+		lit, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		lits = append(lits, lit)
+		if lit.kind == kindString {
+			strs[lit.s] = struct{}{}
+		}
+		if t := p.next(); t.kind == tokRBrack {
+			break
+		} else if t.kind != tokComma {
+			return nil, p.errorf(t, `expected "," or "]"`)
+		}
+	}
+
+	if len(strs) == len(lits) {
+		return func(item T) bool {
+			v := valueOf(get(item))
+			if v.kind != kindString {
+				return false
+			}
+			_, ok := strs[v.s]
+			return ok
+		}, nil
+	}
+	return func(item T) bool {
+		v := valueOf(get(item))
+		for _, lit := range lits {
+			if c, ok := compare(v, lit); ok && c == 0 {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (p *parser[T]) literal() (value, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return value{kind: kindString, s: t.text}, nil
+	case tokNumber:
+		if i, err := strconv.ParseInt(t.text, 0, 64); err == nil {
+			return value{kind: kindInt, i: i}, nil
+		}
+		if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return value{kind: kindFloat, f: f}, nil
+		}
+		return value{}, p.errorf(t, "invalid number %q", t.text)
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return value{kind: kindBool, b: true}, nil
+		case "false":
+			return value{kind: kindBool}, nil
+		case "null":
+			return value{}, nil
+		}
+	}
+	return value{}, p.errorf(t, "expected literal")
+}
@@ -0,0 +1,9 @@
+package filter
+
+import "errors"
+
+// Sentinel errors for the filter compiler.
+var (
+	ErrSyntax       = errors.New("filter: syntax error")
+	ErrUnknownField = errors.New("filter: unknown field")
+)
@@ -0,0 +1,72 @@
+// Package filter compiles small predicate expressions over items into
+// closures, so routing rules (which queue, which subscriber) can be
+// configured as strings instead of written as code:
+//
+//	tenant == "acme" && (priority >= 5 || kind in ["alert", "page"])
+//
+// Fields are resolved once at compile time, either through accessor funcs
+// registered per field (Compile) or as keys of a map[string]any, with dotted
+// paths into nested maps (CompileMap). The grammar is:
+//
+//	expr       = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" expr ")" | comparison
+//	comparison = field [ op literal | "in" "[" literal { "," literal } "]" ]
+//	op         = "==" | "!=" | "<" | "<=" | ">" | ">="
+//	literal    = string | number | "true" | "false" | "null"
+//
+// Strings are double-quoted with Go escapes or single-quoted without. A bare
+// field matches when its value is true. Numbers of any Go numeric type
+// compare by value; a missing map key or nil field equals null. Comparisons
+// between values of different kinds (a string and a number, say) are false,
+// and so is any ordering involving null or a bool.
+package filter
+
+import "strings"
+
+// Predicate reports whether item matches the expression it was compiled from.
+// It is safe for concurrent use if the field accessors are.
+type Predicate[T any] func(item T) bool
+
+// Fields maps field names to accessors for items of type T. An accessor may
+// return any Go scalar (or a named type over one); other values compare
+// unequal to every literal.
+type Fields[T any] map[string]func(item T) any
+
+// Compile compiles expr for items of type T with the given field accessors.
+// A field missing from fields is an ErrUnknownField error.
+func Compile[T any](expr string, fields Fields[T]) (Predicate[T], error) {
+	return compile(expr, func(name string) (func(T) any, bool) {
+		get, ok := fields[name]
+		return get, ok
+	})
+}
+
+// CompileMap compiles expr for map items. A field "a.b" reads m["a"]["b"]
+// when m["a"] is a map[string]any; keys missing at match time are null.
+func CompileMap(expr string) (Predicate[map[string]any], error) {
+	return compile(expr, func(name string) (func(map[string]any) any, bool) {
+		path := strings.Split(name, ".")
+		return func(m map[string]any) any {
+			var v any = m
+			for _, key := range path {
+				inner, ok := v.(map[string]any)
+				if !ok {
+					return nil
+				}
+				v = inner[key]
+			}
+			return v
+		}, true
+	})
+}
+
+// MustCompile is Compile that panics on error, for expressions fixed at
+// build time.
+func MustCompile[T any](expr string, fields Fields[T]) Predicate[T] {
+	p, err := Compile(expr, fields)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
@@ -0,0 +1,157 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+)
+
+type status string
+
+type message struct {
+	Tenant   string
+	Priority int
+	Kind     status
+	Size     uint64
+	Urgent   bool
+	Score    float32
+}
+
+var messageFields = Fields[message]{
+	"tenant":   func(m message) any { return m.Tenant },
+	"priority": func(m message) any { return m.Priority },
+	"kind":     func(m message) any { return m.Kind },
+	"size":     func(m message) any { return m.Size },
+	"urgent":   func(m message) any { return m.Urgent },
+	"score":    func(m message) any { return m.Score },
+}
+
+// =============================================================================
+// Compile Tests
+// =============================================================================
+
+func TestCompile(t *testing.T) {
+	m := message{Tenant: "acme", Priority: 7, Kind: "alert", Size: 1 << 40, Urgent: true, Score: 0.5}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`tenant == "acme"`, true},
+		{`tenant == 'other'`, false},
+		{`tenant != "other"`, true},
+		{`priority >= 5`, true},
+		{`priority > 7`, false},
+		{`priority <= 7.0`, true},
+		{`priority < -1`, false},
+		{`size == 1099511627776`, true},
+		{`score < 1e0`, true},
+		{`kind in ["page", "alert"]`, true},
+		{`kind in ["page"]`, false},
+		{`priority in [1, 7, "x"]`, true},
+		{`urgent`, true},
+		{`!urgent`, false},
+		{`urgent == true`, true},
+		{`tenant == "acme" && (priority > 10 || kind == "alert")`, true},
+		{`tenant == "acme" && priority > 10 || kind == "page"`, false},
+		{`!(tenant == "acme")`, false},
+		{`tenant == 5`, false}, // different kinds never match
+		{`tenant != 5`, true},  // ... so they always differ
+		{`tenant == null`, false},
+		{`tenant > "abc"`, true},
+	}
+	for _, tt := range tests {
+		pred, err := Compile(tt.expr, messageFields)
+		if err != nil {
+			t.Errorf("Compile(%s): %v", tt.expr, err)
+			continue
+		}
+		if got := pred(m); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  error
+	}{
+		{`tenant ==`, ErrSyntax},
+		{`tenant == "acme`, ErrSyntax},
+		{`(tenant == "a"`, ErrSyntax},
+		{`tenant == "a" extra`, ErrSyntax},
+		{`tenant in "a"`, ErrSyntax},
+		{`tenant in ["a" "b"]`, ErrSyntax},
+		{`urgent < true`, ErrSyntax},
+		{`tenant = "a"`, ErrSyntax},
+		{`&& tenant`, ErrSyntax},
+		{`missing == 1`, ErrUnknownField},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.expr, messageFields); !errors.Is(err, tt.err) {
+			t.Errorf("Compile(%s) error = %v, want %v", tt.expr, err, tt.err)
+		}
+	}
+}
+
+func TestMustCompile_Panics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompile did not panic on an invalid expression")
+		}
+	}()
+	MustCompile(`priority >`, messageFields)
+}
+
+// =============================================================================
+// CompileMap Tests
+// =============================================================================
+
+func TestCompileMap(t *testing.T) {
+	item := map[string]any{
+		"tenant": "acme",
+		"count":  int32(3),
+		"meta":   map[string]any{"region": "eu", "tier": 2},
+		"tags":   []string{"a"},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`tenant == "acme" && count == 3`, true},
+		{`meta.region == "eu"`, true},
+		{`meta.tier > 1.5`, true},
+		{`meta.zone == null`, true},
+		{`missing == null`, true},
+		{`missing != "x"`, true},
+		{`missing > 1`, false},
+		{`tenant.region == null`, true}, // not a map: path yields null
+		{`tags == "a"`, false},
+	}
+	for _, tt := range tests {
+		pred, err := CompileMap(tt.expr)
+		if err != nil {
+			t.Errorf("CompileMap(%s): %v", tt.expr, err)
+			continue
+		}
+		if got := pred(item); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// =============================================================================
+// Benchmarks
+// =============================================================================
+
+func BenchmarkPredicate(b *testing.B) {
+	pred := MustCompile(`tenant == "acme" && (priority >= 5 || kind in ["alert", "page"])`, messageFields)
+	m := message{Tenant: "acme", Priority: 3, Kind: "page"}
+	b.ReportAllocs()
+	for b.Loop() {
+		if !pred(m) {
+			b.Fatal("no match")
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind uint8
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp // == != < <= > >=
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+	tokLBrack
+	tokRBrack
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string // source text; the unquoted value for tokString
+	pos  int
+}
+
+// lex splits expr into tokens, ending with tokEOF.
+func lex(expr string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(expr) && (isIdentStart(expr[j]) || isDigit(expr[j]) || expr[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{tokIdent, expr[i:j], i})
+			i = j
+			continue
+		case isDigit(c) || (c == '-' || c == '.') && i+1 < len(expr) && isDigit(expr[i+1]):
+			j := i + 1
+			for j < len(expr) && (isDigit(expr[j]) || strings.IndexByte(".eE_+-", expr[j]) >= 0) {
+				// Signs only follow an exponent marker.
+				if (expr[j] == '+' || expr[j] == '-') && expr[j-1] != 'e' && expr[j-1] != 'E' {
+					break
+				}
+				j++
+			}
+			toks = append(toks, token{tokNumber, expr[i:j], i})
+			i = j
+			continue
+		case c == '"':
+			j := i + 1
+			for j < len(expr) && expr[j] != '"' {
+				if expr[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(expr) {
+				return nil, syntaxError(expr, i, "unterminated string")
+			}
+			s, err := strconv.Unquote(expr[i : j+1])
+			if err != nil {
+				return nil, syntaxError(expr, i, "invalid string")
+			}
+			toks = append(toks, token{tokString, s, i})
+			i = j + 1
+			continue
+		case c == '\'':
+			j := strings.IndexByte(expr[i+1:], '\'')
+			if j < 0 {
+				return nil, syntaxError(expr, i, "unterminated string")
+			}
+			toks = append(toks, token{tokString, expr[i+1 : i+1+j], i})
+			i += j + 2
+			continue
+		}
+
+		two := ""
+		if i+1 < len(expr) {
+			two = expr[i : i+2]
+		}
+		switch {
+		case two == "&&":
+			toks = append(toks, token{tokAnd, two, i})
+		case two == "||":
+			toks = append(toks, token{tokOr, two, i})
+		case two == "==" || two == "!=" || two == "<=" || two == ">=":
+			toks = append(toks, token{tokOp, two, i})
+		case c == '<' || c == '>':
+			toks = append(toks, token{tokOp, expr[i : i+1], i})
+			i++
+			continue
+		default:
+			kind, ok := punct[c]
+			if !ok {
+				return nil, syntaxError(expr, i, fmt.Sprintf("unexpected %q", c))
+			}
+			toks = append(toks, token{kind, expr[i : i+1], i})
+			i++
+			continue
+		}
+		i += 2
+	}
+	return append(toks, token{tokEOF, "", len(expr)}), nil
+}
+
+var punct = map[byte]tokenKind{
+	'!': tokNot,
+	'(': tokLParen,
+	')': tokRParen,
+	'[': tokLBrack,
+	']': tokRBrack,
+	',': tokComma,
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func syntaxError(expr string, pos int, msg string) error {
+	return fmt.Errorf("%w: %s at offset %d in %q", ErrSyntax, msg, pos, expr)
+}
@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"cmp"
+	"math"
+	"reflect"
+)
+
+type kind uint8
+
+const (
+	kindNull kind = iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindOther // not comparable to any literal
+)
+
+// value is a field or literal normalized for comparison. Integers stay
+// int64 so large IDs compare exactly; uint64 values above MaxInt64 become
+// float64.
+type value struct {
+	kind kind
+	b    bool
+	i    int64
+	f    float64
+	s    string
+}
+
+// valueOf normalizes v. The common types are switched on directly; named
+// types fall back to reflection.
+func valueOf(v any) value {
+	switch x := v.(type) {
+	case nil:
+		return value{}
+	case string:
+		return value{kind: kindString, s: x}
+	case int:
+		return value{kind: kindInt, i: int64(x)}
+	case int64:
+		return value{kind: kindInt, i: x}
+	case float64:
+		return value{kind: kindFloat, f: x}
+	case bool:
+		return value{kind: kindBool, b: x}
+	case []byte:
+		return value{kind: kindString, s: string(x)}
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return value{kind: kindString, s: rv.String()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value{kind: kindInt, i: rv.Int()}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u > math.MaxInt64 {
+			return value{kind: kindFloat, f: float64(u)}
+		} else {
+			return value{kind: kindInt, i: int64(u)}
+		}
+	case reflect.Float32, reflect.Float64:
+		return value{kind: kindFloat, f: rv.Float()}
+	case reflect.Bool:
+		return value{kind: kindBool, b: rv.Bool()}
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return value{}
+		}
+	}
+	return value{kind: kindOther}
+}
+
+// compare orders a against b. ok is false when they are not comparable:
+// different kinds other than int/float, or kindOther.
+func compare(a, b value) (c int, ok bool) {
+	switch {
+	case a.kind == b.kind:
+		switch a.kind {
+		case kindNull:
+			return 0, true
+		case kindBool:
+			if a.b == b.b {
+				return 0, true
+			}
+			if b.b {
+				return -1, true
+			}
+			return 1, true
+		case kindInt:
+			return cmp.Compare(a.i, b.i), true
+		case kindFloat:
+			return cmp.Compare(a.f, b.f), true
+		case kindString:
+			return cmp.Compare(a.s, b.s), true
+		}
+	case a.kind == kindInt && b.kind == kindFloat:
+		return cmp.Compare(float64(a.i), b.f), true
+	case a.kind == kindFloat && b.kind == kindInt:
+		return cmp.Compare(a.f, float64(b.i)), true
+	}
+	return 0, false
+}
+
+// ordered reports whether values of kind k support < and >.
+func (k kind) ordered() bool {
+	return k == kindInt || k == kindFloat || k == kindString
+}
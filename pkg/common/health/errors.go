@@ -0,0 +1,14 @@
+package health
+
+import "errors"
+
+var (
+	// ErrDuplicateCheck is returned when a check name is registered twice.
+	ErrDuplicateCheck = errors.New("health: duplicate check name")
+
+	// ErrNotChecked is reported for a check that has not completed a probe yet.
+	ErrNotChecked = errors.New("health: not checked yet")
+
+	// ErrTimeout is reported when a probe exceeds its timeout.
+	ErrTimeout = errors.New("health: check timed out")
+)
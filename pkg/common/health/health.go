@@ -0,0 +1,316 @@
+// Package health runs health checks in the background and aggregates their
+// cached results into liveness and readiness reports, served over HTTP as
+// /healthz and /readyz.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/encoding/json"
+)
+
+// CheckFunc probes one dependency. It should honour ctx, which carries the
+// probe timeout.
+type CheckFunc func(ctx context.Context) error
+
+// Status is the state of a check or of an aggregate.
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded" // only optional checks are failing
+	StatusDown     Status = "down"
+)
+
+// CheckResult is the cached outcome of a check's latest probe.
+type CheckResult struct {
+	Status    Status        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Optional  bool          `json:"optional,omitempty"`
+}
+
+// Report is an aggregate over a set of checks.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// check is a registered check and its latest result.
+type check struct {
+	name   string
+	fn     CheckFunc
+	config checkConfig
+
+	mu      sync.Mutex
+	result  CheckResult
+	running bool // a probe is in flight; the next tick is skipped
+}
+
+// Registry holds health checks and probes them periodically.
+// It is safe for concurrent use.
+type Registry struct {
+	config Config
+
+	mu      sync.RWMutex
+	checks  map[string]*check
+	started bool
+	stop    chan struct{}
+	loops   sync.WaitGroup
+}
+
+// New creates an empty registry. Checks are probed once Start is called.
+func New(opts ...Option) *Registry {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &Registry{
+		config: cfg,
+		checks: make(map[string]*check),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Register adds a check. Until its first probe completes it reports down
+// with ErrNotChecked, so a service is not ready before its dependencies
+// have been seen. Checks registered after Start are probed right away.
+func (r *Registry) Register(name string, fn CheckFunc, opts ...CheckOption) error {
+	c := &check{
+		name: name,
+		fn:   fn,
+		config: checkConfig{
+			interval: r.config.Interval,
+			timeout:  r.config.Timeout,
+		},
+	}
+	for _, o := range opts {
+		o(&c.config)
+	}
+	c.result = CheckResult{
+		Status:   StatusDown,
+		Error:    ErrNotChecked.Error(),
+		Optional: c.config.optional,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.checks[name]; ok {
+		return ErrDuplicateCheck
+	}
+	r.checks[name] = c
+	if r.started {
+		r.startLoop(c)
+	}
+	return nil
+}
+
+// Start begins probing. Calling it more than once is a no-op.
+func (r *Registry) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return
+	}
+	r.started = true
+	for _, c := range r.checks {
+		r.startLoop(c)
+	}
+}
+
+// Stop stops probing and waits for the probe loops to exit. Cached results
+// are kept.
+func (r *Registry) Stop() {
+	r.mu.Lock()
+	if !r.started {
+		r.mu.Unlock()
+		return
+	}
+	r.started = false
+	close(r.stop)
+	r.mu.Unlock()
+
+	r.loops.Wait()
+
+	r.mu.Lock()
+	r.stop = make(chan struct{})
+	r.mu.Unlock()
+}
+
+// startLoop probes c now and then every interval. Caller holds r.mu.
+func (r *Registry) startLoop(c *check) {
+	stop := r.stop
+	r.loops.Add(1)
+	go func() {
+		defer r.loops.Done()
+
+		ticker := time.NewTicker(c.config.interval)
+		defer ticker.Stop()
+		for {
+			r.probe(c)
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// probe runs one check and caches its result. A probe that ignores its
+// context is abandoned at the timeout and left to finish in the background;
+// no new probe of that check starts until it does.
+func (r *Registry) probe(c *check) {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return
+	}
+	c.running = true
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.timeout)
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			c.running = false
+			c.mu.Unlock()
+		}()
+		done <- call(ctx, c.fn)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ErrTimeout
+	}
+	cancel()
+
+	res := CheckResult{
+		Status:    StatusUp,
+		Duration:  time.Since(start),
+		CheckedAt: start,
+		Optional:  c.config.optional,
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+	c.mu.Lock()
+	c.result = res
+	c.mu.Unlock()
+}
+
+// call invokes fn, converting a panic into an error.
+func call(ctx context.Context, fn CheckFunc) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("health: check panicked: %v", rec)
+		}
+	}()
+	return fn(ctx)
+}
+
+// Check probes the named check now, bypassing the interval, and returns its
+// fresh result.
+func (r *Registry) Check(name string) (CheckResult, bool) {
+	r.mu.RLock()
+	c, ok := r.checks[name]
+	r.mu.RUnlock()
+	if !ok {
+		return CheckResult{}, false
+	}
+	r.probe(c)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.result, true
+}
+
+// Status returns the readiness report over every check.
+func (r *Registry) Status() Report {
+	return r.report(false)
+}
+
+// Liveness returns the report over checks registered with Liveness. With no
+// such checks the process is considered alive.
+func (r *Registry) Liveness() Report {
+	return r.report(true)
+}
+
+func (r *Registry) report(livenessOnly bool) Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rep := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(r.checks))}
+	for name, c := range r.checks {
+		if livenessOnly && !c.config.liveness {
+			continue
+		}
+		c.mu.Lock()
+		res := c.result
+		c.mu.Unlock()
+		rep.Checks[name] = res
+
+		if res.Status == StatusUp {
+			continue
+		}
+		switch {
+		case !res.Optional:
+			rep.Status = StatusDown
+		case rep.Status == StatusUp:
+			rep.Status = StatusDegraded
+		}
+	}
+	return rep
+}
+
+// Handler serves /healthz (liveness) and /readyz (readiness) under any
+// prefix; other paths get 404.
+func (r *Registry) Handler() http.Handler {
+	live, ready := r.LivenessHandler(), r.ReadinessHandler()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch path := req.URL.Path; {
+		case strings.HasSuffix(path, "/healthz"), strings.HasSuffix(path, "/livez"):
+			live.ServeHTTP(w, req)
+		case strings.HasSuffix(path, "/readyz"):
+			ready.ServeHTTP(w, req)
+		default:
+			http.NotFound(w, req)
+		}
+	})
+}
+
+// LivenessHandler serves the liveness report.
+func (r *Registry) LivenessHandler() http.Handler {
+	return reportHandler(r.Liveness)
+}
+
+// ReadinessHandler serves the readiness report.
+func (r *Registry) ReadinessHandler() http.Handler {
+	return reportHandler(r.Status)
+}
+
+// reportHandler writes the report as JSON: 200 when up or degraded, 503
+// when down.
+func reportHandler(report func() Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		rep := report()
+		code := http.StatusOK
+		if rep.Status == StatusDown {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(rep)
+	})
+}
@@ -0,0 +1,194 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+func ok(context.Context) error { return nil }
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// =============================================================================
+// Method: Register() / Status()
+// =============================================================================
+
+func TestRegister_Duplicate(t *testing.T) {
+	r := New()
+	if err := r.Register("db", ok); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("db", ok); !errors.Is(err, ErrDuplicateCheck) {
+		t.Errorf("Register(dup) = %v, want ErrDuplicateCheck", err)
+	}
+}
+
+func TestStatus_NotReadyBeforeFirstProbe(t *testing.T) {
+	r := New()
+	r.Register("db", ok)
+
+	rep := r.Status()
+	if rep.Status != StatusDown || rep.Checks["db"].Error != ErrNotChecked.Error() {
+		t.Errorf("Status() before Start = %+v, want down / not checked", rep)
+	}
+}
+
+func TestStatus_Aggregation(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name     string
+		cacheErr error
+		dbErr    error
+		want     Status
+	}{
+		{"all_up", nil, nil, StatusUp},
+		{"optional_down", boom, nil, StatusDegraded},
+		{"required_down", nil, boom, StatusDown},
+		{"both_down", boom, boom, StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Register("cache", func(context.Context) error { return tt.cacheErr }, Optional())
+			r.Register("db", func(context.Context) error { return tt.dbErr })
+			r.Check("cache")
+			r.Check("db")
+
+			if got := r.Status().Status; got != tt.want {
+				t.Errorf("Status() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiveness_OnlyLivenessChecks(t *testing.T) {
+	r := New()
+	r.Register("db", func(context.Context) error { return errors.New("down") })
+	r.Check("db")
+
+	if rep := r.Liveness(); rep.Status != StatusUp || len(rep.Checks) != 0 {
+		t.Errorf("Liveness() = %+v, want up with no checks", rep)
+	}
+
+	r.Register("loop", func(context.Context) error { return errors.New("stuck") }, Liveness())
+	r.Check("loop")
+	if rep := r.Liveness(); rep.Status != StatusDown || len(rep.Checks) != 1 {
+		t.Errorf("Liveness() = %+v, want down with one check", rep)
+	}
+}
+
+// =============================================================================
+// Probing: Start() / Stop() / timeouts
+// =============================================================================
+
+func TestStart_ProbesPeriodically(t *testing.T) {
+	var calls atomic.Int32
+	r := New(WithInterval(10 * time.Millisecond))
+	r.Register("db", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	r.Start()
+	waitFor(t, func() bool { return calls.Load() >= 3 })
+	r.Stop()
+
+	if got := r.Status().Status; got != StatusUp {
+		t.Errorf("Status() = %s, want up", got)
+	}
+	n := calls.Load()
+	time.Sleep(30 * time.Millisecond)
+	if calls.Load() != n {
+		t.Error("probes continued after Stop")
+	}
+}
+
+func TestStart_RegisterAfterStart(t *testing.T) {
+	r := New()
+	r.Start()
+	defer r.Stop()
+
+	r.Register("late", ok)
+	waitFor(t, func() bool { return r.Status().Status == StatusUp })
+}
+
+func TestProbe_Timeout(t *testing.T) {
+	r := New()
+	release := make(chan struct{})
+	defer close(release)
+	r.Register("slow", func(context.Context) error {
+		<-release // ignores ctx
+		return nil
+	}, WithCheckTimeout(20*time.Millisecond))
+
+	res, _ := r.Check("slow")
+	if res.Status != StatusDown || res.Error != ErrTimeout.Error() {
+		t.Errorf("Check(slow) = %+v, want down / timed out", res)
+	}
+}
+
+func TestProbe_Panic(t *testing.T) {
+	r := New()
+	r.Register("bad", func(context.Context) error { panic("oops") })
+
+	res, _ := r.Check("bad")
+	if res.Status != StatusDown || !strings.Contains(res.Error, "oops") {
+		t.Errorf("Check(bad) = %+v, want down with the panic value", res)
+	}
+}
+
+// =============================================================================
+// Method: Handler()
+// =============================================================================
+
+func TestHandler(t *testing.T) {
+	r := New()
+	r.Register("db", func(context.Context) error { return errors.New("refused") })
+	r.Check("db")
+	h := r.Handler()
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/healthz", http.StatusOK},
+		{"/internal/livez", http.StatusOK},
+		{"/readyz", http.StatusServiceUnavailable},
+		{"/other", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.code {
+				t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.code)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if body := rec.Body.String(); !strings.Contains(body, `"refused"`) || !strings.Contains(body, `"down"`) {
+		t.Errorf("readyz body = %s", body)
+	}
+}
@@ -0,0 +1,82 @@
+package health
+
+import "time"
+
+// Defaults.
+const (
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = 2 * time.Second
+)
+
+// Config holds the registry-wide probe settings.
+type Config struct {
+	Interval time.Duration // time between probes of a check
+	Timeout  time.Duration // per-probe deadline
+}
+
+// Option configures a Registry.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{Interval: defaultInterval, Timeout: defaultTimeout}
+}
+
+// WithInterval sets the default probe interval.
+func WithInterval(d time.Duration) Option {
+	return func(c *Config) {
+		if d > 0 {
+			c.Interval = d
+		}
+	}
+}
+
+// WithTimeout sets the default probe timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		if d > 0 {
+			c.Timeout = d
+		}
+	}
+}
+
+// checkConfig holds per-check settings.
+type checkConfig struct {
+	interval time.Duration
+	timeout  time.Duration
+	liveness bool // also gates /healthz
+	optional bool // failure degrades instead of failing the aggregate
+}
+
+// CheckOption configures a single check.
+type CheckOption func(*checkConfig)
+
+// WithCheckInterval overrides the probe interval of one check.
+func WithCheckInterval(d time.Duration) CheckOption {
+	return func(c *checkConfig) {
+		if d > 0 {
+			c.interval = d
+		}
+	}
+}
+
+// WithCheckTimeout overrides the probe timeout of one check.
+func WithCheckTimeout(d time.Duration) CheckOption {
+	return func(c *checkConfig) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// Liveness makes the check part of liveness as well as readiness. Keep
+// liveness checks to failures a restart fixes (deadlock, corrupted state);
+// a down dependency should only fail readiness.
+func Liveness() CheckOption {
+	return func(c *checkConfig) { c.liveness = true }
+}
+
+// Optional makes a failing check degrade the aggregate status instead of
+// failing it, e.g. for a cache whose absence only slows the service down.
+func Optional() CheckOption {
+	return func(c *checkConfig) { c.optional = true }
+}
@@ -0,0 +1,12 @@
+package lifecycle
+
+import "errors"
+
+var (
+	// ErrAlreadyRunning is returned by Run on a Manager that has already run.
+	ErrAlreadyRunning = errors.New("lifecycle: already running")
+
+	// ErrHookTimeout is reported when a hook does not return within its
+	// timeout.
+	ErrHookTimeout = errors.New("lifecycle: hook timed out")
+)
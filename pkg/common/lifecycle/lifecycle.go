@@ -0,0 +1,235 @@
+// Package lifecycle starts an application's components in order, waits for
+// a shutdown trigger (signal, context, Shutdown call or a failed background
+// task), then stops them in reverse order with per-hook timeouts.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Hook is a component's start and stop functions. Either may be nil.
+// OnStart must not block: long-running work belongs in Manager.Go.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+
+	StartTimeout time.Duration // 0 = Config.StartTimeout
+	StopTimeout  time.Duration // 0 = Config.StopTimeout
+}
+
+// task is a background function run for the lifetime of the Manager.
+type task struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager coordinates the start and stop of components.
+// It is safe for concurrent use; Run may be called once.
+type Manager struct {
+	config Config
+
+	mu       sync.Mutex
+	hooks    []Hook
+	tasks    []task
+	running  bool
+	shutdown chan struct{}
+	once     sync.Once
+}
+
+// New creates a Manager.
+func New(opts ...Option) *Manager {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &Manager{
+		config:   cfg,
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Append registers a hook. Hooks start in registration order and stop in
+// reverse, so a component is stopped before the ones it depends on.
+func (m *Manager) Append(h Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, h)
+}
+
+// OnStop registers a stop-only hook, e.g. for a cache or batcher that needs
+// no start step: m.OnStop("cache", func(context.Context) error { c.Close(); return nil }).
+func (m *Manager) OnStop(name string, fn func(ctx context.Context) error) {
+	m.Append(Hook{Name: name, OnStop: fn})
+}
+
+// Go registers a background task, such as a network listener, started after
+// every start hook succeeded. Its context is cancelled when shutdown begins,
+// and the stop hooks run once every task has returned. A task returning a
+// non-nil error other than context.Canceled triggers shutdown.
+func (m *Manager) Go(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks = append(m.tasks, task{name: name, fn: fn})
+}
+
+// Shutdown asks a running Manager to stop. It does not wait; Run returns
+// once the stop sequence completes.
+func (m *Manager) Shutdown() {
+	m.once.Do(func() { close(m.shutdown) })
+}
+
+// Run starts every hook, runs the background tasks, and blocks until ctx is
+// done, a configured signal arrives, Shutdown is called or a task fails. It
+// then stops the started hooks in reverse order.
+//
+// If a start hook fails, the hooks already started are stopped and the start
+// error is returned. Otherwise Run returns the first task error joined with
+// any stop errors, or nil.
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return ErrAlreadyRunning
+	}
+	m.running = true
+	hooks := append([]Hook(nil), m.hooks...)
+	tasks := append([]task(nil), m.tasks...)
+	m.mu.Unlock()
+
+	if len(m.config.Signals) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, m.config.Signals...)
+		defer stop()
+	}
+
+	started, err := m.start(ctx, hooks)
+	if err != nil {
+		return errors.Join(err, m.stop(started))
+	}
+
+	taskCtx, cancelTasks := context.WithCancel(context.Background())
+	defer cancelTasks()
+	var (
+		wg       sync.WaitGroup
+		taskErr  error
+		errOnce  sync.Once
+		taskFail = make(chan struct{})
+	)
+	for _, t := range tasks {
+		wg.Go(func() {
+			if err := t.fn(taskCtx); err != nil && !errors.Is(err, context.Canceled) {
+				errOnce.Do(func() {
+					taskErr = fmt.Errorf("lifecycle: task %q: %w", t.name, err)
+					close(taskFail)
+				})
+			}
+		})
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-m.shutdown:
+	case <-taskFail:
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), m.config.ShutdownTimeout)
+	defer cancel()
+
+	cancelTasks()
+	tasksDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(tasksDone)
+	}()
+	select {
+	case <-tasksDone:
+	case <-stopCtx.Done():
+	}
+
+	return errors.Join(taskErr, m.stopWithin(stopCtx, started))
+}
+
+// start runs the start hooks in order. It returns the hooks started so far.
+func (m *Manager) start(ctx context.Context, hooks []Hook) ([]Hook, error) {
+	for i, h := range hooks {
+		if ctx.Err() != nil {
+			return hooks[:i], ctx.Err()
+		}
+		if h.OnStart == nil {
+			continue
+		}
+		timeout := cmpOr(h.StartTimeout, m.config.StartTimeout)
+		if err := m.call(ctx, PhaseStart, h.Name, h.OnStart, timeout); err != nil {
+			return hooks[:i], err
+		}
+	}
+	return hooks, nil
+}
+
+// stop runs the stop hooks of started in reverse within ShutdownTimeout.
+func (m *Manager) stop(started []Hook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.ShutdownTimeout)
+	defer cancel()
+	return m.stopWithin(ctx, started)
+}
+
+func (m *Manager) stopWithin(ctx context.Context, started []Hook) error {
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		h := started[i]
+		if h.OnStop == nil {
+			continue
+		}
+		timeout := cmpOr(h.StopTimeout, m.config.StopTimeout)
+		if err := m.call(ctx, PhaseStop, h.Name, h.OnStop, timeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// call runs fn with timeout under parent. A hook that ignores its context is
+// abandoned when the timeout expires.
+func (m *Manager) call(parent context.Context, phase Phase, name string, fn func(context.Context) error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ErrHookTimeout
+	}
+	if m.config.OnEvent != nil {
+		m.config.OnEvent(phase, name, time.Since(start), err)
+	}
+	if err != nil {
+		return fmt.Errorf("lifecycle: %s %q: %w", phase, name, err)
+	}
+	return nil
+}
+
+// cmpOr returns d, or def when d is not positive.
+func cmpOr(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
@@ -0,0 +1,222 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+// recorder collects hook calls in order.
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) hook(name string) Hook {
+	return Hook{
+		Name:    name,
+		OnStart: func(context.Context) error { r.add("start " + name); return nil },
+		OnStop:  func(context.Context) error { r.add("stop " + name); return nil },
+	}
+}
+
+func (r *recorder) add(s string) {
+	r.mu.Lock()
+	r.calls = append(r.calls, s)
+	r.mu.Unlock()
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+// =============================================================================
+// Method: Run() - Ordering
+// =============================================================================
+
+func TestRun_StartInOrderStopInReverse(t *testing.T) {
+	var r recorder
+	m := New(WithSignals())
+	m.Append(r.hook("cache"))
+	m.Append(r.hook("batcher"))
+	m.Append(r.hook("server"))
+
+	m.Shutdown()
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := []string{
+		"start cache", "start batcher", "start server",
+		"stop server", "stop batcher", "stop cache",
+	}
+	if got := r.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %v, want %v", got, want)
+	}
+}
+
+func TestRun_StartFailureStopsStarted(t *testing.T) {
+	var r recorder
+	m := New(WithSignals())
+	m.Append(r.hook("a"))
+	boom := errors.New("boom")
+	m.Append(Hook{
+		Name:    "b",
+		OnStart: func(context.Context) error { return boom },
+		OnStop:  func(context.Context) error { r.add("stop b"); return nil },
+	})
+	m.Append(r.hook("c"))
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("Run = %v, want boom", err)
+	}
+	want := []string{"start a", "stop a"}
+	if got := r.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %v, want %v", got, want)
+	}
+}
+
+func TestRun_CancelledBeforeStart(t *testing.T) {
+	var r recorder
+	m := New(WithSignals())
+	m.Append(r.hook("a"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	if got := r.get(); len(got) != 0 {
+		t.Errorf("calls = %v, want none", got)
+	}
+}
+
+func TestRun_Twice(t *testing.T) {
+	m := New(WithSignals())
+	m.Shutdown()
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if err := m.Run(context.Background()); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("second Run = %v, want ErrAlreadyRunning", err)
+	}
+}
+
+// =============================================================================
+// Method: Run() - Timeouts
+// =============================================================================
+
+func TestRun_StopTimeout(t *testing.T) {
+	var r recorder
+	m := New(WithSignals(), WithStopTimeout(20*time.Millisecond))
+	m.Append(r.hook("first"))
+	m.OnStop("stuck", func(context.Context) error {
+		time.Sleep(time.Second) // ignores ctx
+		return nil
+	})
+	m.Shutdown()
+
+	start := time.Now()
+	err := m.Run(context.Background())
+	if !errors.Is(err, ErrHookTimeout) {
+		t.Fatalf("Run = %v, want ErrHookTimeout", err)
+	}
+	if took := time.Since(start); took > 500*time.Millisecond {
+		t.Errorf("Run took %v, stuck hook not abandoned", took)
+	}
+	// The hooks after the stuck one still run.
+	if got := r.get(); got[len(got)-1] != "stop first" {
+		t.Errorf("calls = %v, want stop first last", got)
+	}
+}
+
+func TestRun_PerHookTimeout(t *testing.T) {
+	m := New(WithSignals(), WithStopTimeout(time.Second))
+	m.Append(Hook{
+		Name:        "slow",
+		OnStop:      func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() },
+		StopTimeout: 10 * time.Millisecond,
+	})
+	m.Shutdown()
+
+	start := time.Now()
+	if err := m.Run(context.Background()); err == nil {
+		t.Fatal("Run = nil, want timeout error")
+	}
+	if took := time.Since(start); took > 500*time.Millisecond {
+		t.Errorf("Run took %v, per-hook timeout ignored", took)
+	}
+}
+
+// =============================================================================
+// Method: Go() / OnEvent
+// =============================================================================
+
+func TestGo_CancelledOnShutdown(t *testing.T) {
+	var r recorder
+	m := New(WithSignals())
+	m.Append(r.hook("pool"))
+	m.Go("listener", func(ctx context.Context) error {
+		<-ctx.Done()
+		r.add("listener done")
+		return ctx.Err()
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- m.Run(context.Background()) }()
+	time.Sleep(10 * time.Millisecond)
+	m.Shutdown()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := []string{"start pool", "listener done", "stop pool"}
+	if got := r.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %v, want %v", got, want)
+	}
+}
+
+func TestGo_FailureTriggersShutdown(t *testing.T) {
+	var r recorder
+	m := New(WithSignals())
+	m.Append(r.hook("pool"))
+	boom := errors.New("listen failed")
+	m.Go("listener", func(context.Context) error { return boom })
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("Run = %v, want listener error", err)
+	}
+	want := []string{"start pool", "stop pool"}
+	if got := r.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %v, want %v", got, want)
+	}
+}
+
+func TestOnEvent(t *testing.T) {
+	var r recorder
+	m := New(WithSignals(), WithOnEvent(func(p Phase, hook string, _ time.Duration, err error) {
+		r.add(string(p) + " " + hook)
+	}))
+	m.Append(Hook{Name: "x", OnStart: func(context.Context) error { return nil }})
+	m.OnStop("y", func(context.Context) error { return nil })
+	m.Shutdown()
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := []string{"start x", "stop y"}
+	if got := r.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package lifecycle
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// Defaults.
+const (
+	defaultStartTimeout    = 15 * time.Second
+	defaultStopTimeout     = 15 * time.Second
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+// Config holds all configuration for a Manager.
+type Config struct {
+	Signals         []os.Signal   // signals that trigger shutdown; empty disables
+	StartTimeout    time.Duration // default per-hook start timeout
+	StopTimeout     time.Duration // default per-hook stop timeout
+	ShutdownTimeout time.Duration // bound on the whole stop sequence
+
+	// OnEvent, if set, is called as hooks start and stop, e.g. for logging.
+	// err is nil on success.
+	OnEvent func(phase Phase, hook string, took time.Duration, err error)
+}
+
+// Phase identifies a lifecycle step in Config.OnEvent.
+type Phase string
+
+const (
+	PhaseStart Phase = "start"
+	PhaseStop  Phase = "stop"
+)
+
+// Option configures a Manager.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{
+		Signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+		StartTimeout:    defaultStartTimeout,
+		StopTimeout:     defaultStopTimeout,
+		ShutdownTimeout: defaultShutdownTimeout,
+	}
+}
+
+// WithSignals sets the signals that trigger shutdown. No arguments disables
+// signal handling.
+func WithSignals(sigs ...os.Signal) Option {
+	return func(c *Config) { c.Signals = sigs }
+}
+
+// WithStartTimeout sets the default timeout of each start hook.
+func WithStartTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		if d > 0 {
+			c.StartTimeout = d
+		}
+	}
+}
+
+// WithStopTimeout sets the default timeout of each stop hook.
+func WithStopTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		if d > 0 {
+			c.StopTimeout = d
+		}
+	}
+}
+
+// WithShutdownTimeout bounds the whole stop sequence. Hooks still pending
+// when it expires are abandoned.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		if d > 0 {
+			c.ShutdownTimeout = d
+		}
+	}
+}
+
+// WithOnEvent sets Config.OnEvent.
+func WithOnEvent(fn func(phase Phase, hook string, took time.Duration, err error)) Option {
+	return func(c *Config) { c.OnEvent = fn }
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var _ Meter = (*Memory)(nil)
+
+// Memory is a Meter that keeps every value in process, readable by name and
+// labels. Creating an instrument that already exists returns it.
+type Memory struct {
+	mu         sync.Mutex
+	counters   map[string]*memCounter
+	gauges     map[string]*memGauge
+	histograms map[string]*memHistogram
+}
+
+// NewMemory creates an empty in-memory meter.
+func NewMemory() *Memory {
+	return &Memory{
+		counters:   make(map[string]*memCounter),
+		gauges:     make(map[string]*memGauge),
+		histograms: make(map[string]*memHistogram),
+	}
+}
+
+// Counter implements Meter.
+func (m *Memory) Counter(name, _ string, labels ...Label) Counter {
+	return lookup(m, m.counters, seriesKey(name, labels))
+}
+
+// Gauge implements Meter.
+func (m *Memory) Gauge(name, _ string, labels ...Label) Gauge {
+	return lookup(m, m.gauges, seriesKey(name, labels))
+}
+
+// Histogram implements Meter.
+func (m *Memory) Histogram(name, _ string, labels ...Label) Histogram {
+	return lookup(m, m.histograms, seriesKey(name, labels))
+}
+
+// CounterValue returns the counter's sum, 0 if it was never created.
+func (m *Memory) CounterValue(name string, labels ...Label) int64 {
+	return lookup(m, m.counters, seriesKey(name, labels)).n.Load()
+}
+
+// GaugeValue returns the gauge's last value, 0 if it was never set.
+func (m *Memory) GaugeValue(name string, labels ...Label) float64 {
+	return math.Float64frombits(lookup(m, m.gauges, seriesKey(name, labels)).bits.Load())
+}
+
+// HistogramValue returns the histogram's observation count and sum.
+func (m *Memory) HistogramValue(name string, labels ...Label) (count int64, sum float64) {
+	h := lookup(m, m.histograms, seriesKey(name, labels))
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count, h.sum
+}
+
+// lookup returns the instrument stored under key, creating it if needed.
+func lookup[I any](m *Memory, series map[string]*I, key string) *I {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inst, ok := series[key]
+	if !ok {
+		inst = new(I)
+		series[key] = inst
+	}
+	return inst
+}
+
+// seriesKey identifies name with labels, whatever order they come in.
+func seriesKey(name string, labels []Label) string {
+	if len(labels) == 0 {
+		return name
+	}
+	labels = slices.Clone(labels)
+	slices.SortFunc(labels, func(a, b Label) int { return strings.Compare(a.Key, b.Key) })
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	for i, l := range labels {
+		if i == 0 {
+			sb.WriteByte('{')
+		} else {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(l.Key)
+		sb.WriteByte('=')
+		sb.WriteString(l.Value)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+type memCounter struct{ n atomic.Int64 }
+
+func (c *memCounter) Add(n int64) { c.n.Add(n) }
+
+type memGauge struct{ bits atomic.Uint64 }
+
+func (g *memGauge) Set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+type memHistogram struct {
+	mu    sync.Mutex
+	count int64
+	sum   float64
+}
+
+func (h *memHistogram) Record(v float64) {
+	h.mu.Lock()
+	h.count++
+	h.sum += v
+	h.mu.Unlock()
+}
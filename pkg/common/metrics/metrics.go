@@ -0,0 +1,73 @@
+// Package metrics is the instrumentation facade shared by the library's
+// components. A component takes a Meter through its options and creates its
+// instruments once, at construction; updates are then a single interface
+// call, which the default Noop meter turns into nothing.
+//
+// NewOTel reports through an OpenTelemetry meter, and from there to any OTel
+// exporter, Prometheus included. Memory keeps the values in process, for
+// tests and debug endpoints.
+package metrics
+
+// Label is a dimension bound to an instrument when it is created, such as
+// the name of the queue it measures.
+type Label struct {
+	Key   string
+	Value string
+}
+
+// L is shorthand for Label{key, value}.
+func L(key, value string) Label {
+	return Label{Key: key, Value: value}
+}
+
+// Counter is a monotonically increasing sum.
+type Counter interface {
+	// Add increases the counter by n, which must not be negative.
+	Add(n int64)
+}
+
+// Gauge is a value that is set rather than accumulated.
+type Gauge interface {
+	// Set records the current value.
+	Set(v float64)
+}
+
+// Histogram records the distribution of values.
+type Histogram interface {
+	// Record adds one observation.
+	Record(v float64)
+}
+
+// Meter creates instruments. Names are dotted and lower case, prefixed by
+// the component, e.g. "batcher.flushes". Creating the same name and labels
+// twice may return the same instrument. Implementations are safe for
+// concurrent use, and so are the instruments they return.
+type Meter interface {
+	Counter(name, desc string, labels ...Label) Counter
+	Gauge(name, desc string, labels ...Label) Gauge
+	Histogram(name, desc string, labels ...Label) Histogram
+}
+
+// Noop is a Meter whose instruments discard everything. Components use it
+// when no Meter is configured.
+var Noop Meter = noopMeter{}
+
+// OrNoop returns m, or Noop if m is nil.
+func OrNoop(m Meter) Meter {
+	if m == nil {
+		return Noop
+	}
+	return m
+}
+
+type noopMeter struct{}
+
+func (noopMeter) Counter(string, string, ...Label) Counter     { return noopInstrument{} }
+func (noopMeter) Gauge(string, string, ...Label) Gauge         { return noopInstrument{} }
+func (noopMeter) Histogram(string, string, ...Label) Histogram { return noopInstrument{} }
+
+type noopInstrument struct{}
+
+func (noopInstrument) Add(int64)      {}
+func (noopInstrument) Set(float64)    {}
+func (noopInstrument) Record(float64) {}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// =============================================================================
+// Noop Tests
+// =============================================================================
+
+func TestOrNoop(t *testing.T) {
+	if OrNoop(nil) != Noop {
+		t.Error("OrNoop(nil) should return Noop")
+	}
+	m := NewMemory()
+	if OrNoop(m) != Meter(m) {
+		t.Error("OrNoop(m) should return m")
+	}
+
+	// Must not panic.
+	Noop.Counter("c", "").Add(1)
+	Noop.Gauge("g", "").Set(1)
+	Noop.Histogram("h", "").Record(1)
+}
+
+// =============================================================================
+// Memory Tests
+// =============================================================================
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+
+	m.Counter("jobs.done", "", L("queue", "a")).Add(2)
+	m.Counter("jobs.done", "", L("queue", "a")).Add(3)
+	m.Counter("jobs.done", "", L("queue", "b")).Add(7)
+	if got := m.CounterValue("jobs.done", L("queue", "a")); got != 5 {
+		t.Errorf("counter a = %d, want 5", got)
+	}
+	if got := m.CounterValue("jobs.done", L("queue", "b")); got != 7 {
+		t.Errorf("counter b = %d, want 7", got)
+	}
+
+	g := m.Gauge("depth", "", L("a", "1"), L("b", "2"))
+	g.Set(3)
+	g.Set(1.5)
+	if got := m.GaugeValue("depth", L("b", "2"), L("a", "1")); got != 1.5 {
+		t.Errorf("gauge = %v, want 1.5 whatever the label order", got)
+	}
+
+	h := m.Histogram("size", "")
+	h.Record(1)
+	h.Record(4)
+	if n, sum := m.HistogramValue("size"); n != 2 || sum != 5 {
+		t.Errorf("histogram = (%d, %v), want (2, 5)", n, sum)
+	}
+
+	if got := m.CounterValue("missing"); got != 0 {
+		t.Errorf("missing counter = %d, want 0", got)
+	}
+}
+
+func TestMemory_Concurrent(t *testing.T) {
+	m := NewMemory()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			c := m.Counter("n", "")
+			for range 1000 {
+				c.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+	if got := m.CounterValue("n"); got != 8000 {
+		t.Errorf("counter = %d, want 8000", got)
+	}
+}
+
+// =============================================================================
+// OTel Tests
+// =============================================================================
+
+func TestOTel(t *testing.T) {
+	m := NewOTel(noop.NewMeterProvider().Meter("test"))
+
+	// The noop provider accepts everything; this checks the wiring.
+	m.Counter("c", "a counter", L("k", "v")).Add(1)
+	m.Gauge("g", "a gauge").Set(2)
+	m.Histogram("h", "a histogram", L("k", "v")).Record(3)
+}
+
+func BenchmarkOTelCounter(b *testing.B) {
+	c := NewOTel(noop.NewMeterProvider().Meter("bench")).Counter("c", "", L("k", "v"))
+	b.ReportAllocs()
+	for b.Loop() {
+		c.Add(1)
+	}
+}
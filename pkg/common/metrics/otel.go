@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// NewOTel adapts an OpenTelemetry meter. Labels become attributes, bound
+// once per instrument so updates do not allocate. Instruments the meter
+// rejects, e.g. for an invalid name, are replaced by no-ops.
+func NewOTel(m metric.Meter) Meter {
+	return otelMeter{m: m}
+}
+
+type otelMeter struct {
+	m metric.Meter
+}
+
+func (o otelMeter) Counter(name, desc string, labels ...Label) Counter {
+	c, err := o.m.Int64Counter(name, metric.WithDescription(desc))
+	if err != nil {
+		return noopInstrument{}
+	}
+	return otelCounter{c: c, opts: []metric.AddOption{attributes(labels)}}
+}
+
+func (o otelMeter) Gauge(name, desc string, labels ...Label) Gauge {
+	g, err := o.m.Float64Gauge(name, metric.WithDescription(desc))
+	if err != nil {
+		return noopInstrument{}
+	}
+	return otelGauge{g: g, opts: []metric.RecordOption{attributes(labels)}}
+}
+
+func (o otelMeter) Histogram(name, desc string, labels ...Label) Histogram {
+	h, err := o.m.Float64Histogram(name, metric.WithDescription(desc))
+	if err != nil {
+		return noopInstrument{}
+	}
+	return otelHistogram{h: h, opts: []metric.RecordOption{attributes(labels)}}
+}
+
+func attributes(labels []Label) metric.MeasurementOption {
+	kvs := make([]attribute.KeyValue, len(labels))
+	for i, l := range labels {
+		kvs[i] = attribute.String(l.Key, l.Value)
+	}
+	return metric.WithAttributeSet(attribute.NewSet(kvs...))
+}
+
+type otelCounter struct {
+	c    metric.Int64Counter
+	opts []metric.AddOption
+}
+
+func (c otelCounter) Add(n int64) { c.c.Add(context.Background(), n, c.opts...) }
+
+type otelGauge struct {
+	g    metric.Float64Gauge
+	opts []metric.RecordOption
+}
+
+func (g otelGauge) Set(v float64) { g.g.Record(context.Background(), v, g.opts...) }
+
+type otelHistogram struct {
+	h    metric.Float64Histogram
+	opts []metric.RecordOption
+}
+
+func (h otelHistogram) Record(v float64) { h.h.Record(context.Background(), v, h.opts...) }
@@ -0,0 +1,221 @@
+// Package registry wires an application's components together: each is
+// provided under a name with a constructor that resolves its dependencies,
+// built once on first use, and handed to a lifecycle.Manager so components
+// start in dependency order and stop in reverse.
+//
+//	reg := registry.New()
+//	registry.Provide(reg, "cache", func(*registry.Registry) (*ristretto.Cache[string, []byte], error) {
+//		return ristretto.New[string, []byte]()
+//	})
+//	registry.Provide(reg, "server", func(r *registry.Registry) (*Server, error) {
+//		c, err := registry.Resolve[*ristretto.Cache[string, []byte]](r, "cache")
+//		if err != nil {
+//			return nil, err
+//		}
+//		return NewServer(c), nil
+//	})
+//	if err := reg.Attach(manager); err != nil { ... }
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/huynhanx03/go-common/pkg/common/lifecycle"
+)
+
+var (
+	// ErrNotFound is returned when resolving a name nothing was provided under.
+	ErrNotFound = errors.New("registry: not found")
+
+	// ErrDuplicate is returned when providing a name twice.
+	ErrDuplicate = errors.New("registry: already provided")
+
+	// ErrCycle is returned when components depend on each other.
+	ErrCycle = errors.New("registry: dependency cycle")
+
+	// ErrType is returned when a component is resolved as a type it does
+	// not have.
+	ErrType = errors.New("registry: wrong type")
+
+	// ErrAttached is returned by Attach on a registry already attached.
+	ErrAttached = errors.New("registry: already attached")
+)
+
+// Starter is implemented by components with a start step, run by the
+// lifecycle.Manager the registry is attached to.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by components with a stop step. Components with
+// Close() or Close() error instead, such as caches and batchers, are stopped
+// by calling it.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Registry holds the providers of an application's components and the
+// components built so far. It is safe for concurrent use; components are
+// built one at a time.
+//
+// The Registry a constructor receives resolves on behalf of the component
+// being built, which is how cycles are detected; it is valid only until the
+// constructor returns.
+type Registry struct {
+	s    *state
+	path []string // components under construction, outermost first; nil outside constructors
+}
+
+type state struct {
+	mu        sync.Mutex
+	providers map[string]*provider
+	names     []string    // registration order
+	built     []*provider // construction order
+	attached  bool
+}
+
+type provider struct {
+	name  string
+	build func(r *Registry) (any, error)
+	value any
+	done  bool
+}
+
+// New creates an empty Registry.
+func New() *Registry {
+	return &Registry{s: &state{providers: make(map[string]*provider)}}
+}
+
+// lock takes the registry lock, unless r is a constructor's view whose
+// caller already holds it, and returns the matching unlock.
+func (r *Registry) lock() func() {
+	if r.path != nil {
+		return func() {}
+	}
+	r.s.mu.Lock()
+	return r.s.mu.Unlock
+}
+
+// Provide registers ctor as the constructor of the component name. It is
+// called once, by the first Resolve of name or by Attach, with a Registry
+// to resolve the component's dependencies from.
+func Provide[T any](r *Registry, name string, ctor func(r *Registry) (T, error)) error {
+	defer r.lock()()
+	if _, ok := r.s.providers[name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicate, name)
+	}
+	r.s.providers[name] = &provider{
+		name:  name,
+		build: func(r *Registry) (any, error) { return ctor(r) },
+	}
+	r.s.names = append(r.s.names, name)
+	return nil
+}
+
+// Supply registers an already built component under name. It starts and
+// stops like a constructed one.
+func Supply[T any](r *Registry, name string, v T) error {
+	return Provide(r, name, func(*Registry) (T, error) { return v, nil })
+}
+
+// Resolve returns the component provided under name, building it and its
+// dependencies first if needed. A constructor's error is returned, and the
+// constructor called again by the next Resolve.
+func Resolve[T any](r *Registry, name string) (T, error) {
+	var zero T
+	unlock := r.lock()
+	v, err := r.resolve(name)
+	unlock()
+	if err != nil {
+		return zero, err
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: %q is %T, not %v", ErrType, name, v, reflect.TypeFor[T]())
+	}
+	return t, nil
+}
+
+// MustResolve is like Resolve but panics on error, for wiring code where a
+// missing component is a programming error.
+func MustResolve[T any](r *Registry, name string) T {
+	t, err := Resolve[T](r, name)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// resolve builds name if needed. The caller holds the lock.
+func (r *Registry) resolve(name string) (any, error) {
+	p, ok := r.s.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	if p.done {
+		return p.value, nil
+	}
+	if slices.Contains(r.path, name) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrCycle, strings.Join(r.path, " -> "), name)
+	}
+
+	v, err := p.build(&Registry{s: r.s, path: append(slices.Clip(r.path), name)})
+	if err != nil {
+		if errors.Is(err, ErrCycle) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("registry: build %q: %w", name, err)
+	}
+	p.value, p.done = v, true
+	r.s.built = append(r.s.built, p)
+	return v, nil
+}
+
+// Attach builds every component not built yet, in the order they were
+// provided, and appends a hook per component to m in construction order:
+// a component is built after its dependencies, so m starts it after them
+// and stops it before them. Components implementing Starter are started,
+// those implementing Stopper, Close() error or Close() are stopped. Attach
+// may be called once; components provided afterwards are not attached.
+func (r *Registry) Attach(m *lifecycle.Manager) error {
+	defer r.lock()()
+	if r.s.attached {
+		return ErrAttached
+	}
+	for _, name := range r.s.names {
+		if _, err := r.resolve(name); err != nil {
+			return err
+		}
+	}
+	r.s.attached = true
+	for _, p := range r.s.built {
+		if h, ok := hook(p.name, p.value); ok {
+			m.Append(h)
+		}
+	}
+	return nil
+}
+
+// hook returns the lifecycle hook of component v, false if it has neither
+// a start nor a stop step.
+func hook(name string, v any) (lifecycle.Hook, bool) {
+	h := lifecycle.Hook{Name: name}
+	if s, ok := v.(Starter); ok {
+		h.OnStart = s.Start
+	}
+	switch c := v.(type) {
+	case Stopper:
+		h.OnStop = c.Stop
+	case interface{ Close() error }:
+		h.OnStop = func(context.Context) error { return c.Close() }
+	case interface{ Close() }:
+		h.OnStop = func(context.Context) error { c.Close(); return nil }
+	}
+	return h, h.OnStart != nil || h.OnStop != nil
+}
@@ -0,0 +1,179 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/common/lifecycle"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+// recorder collects start and stop calls in order.
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) add(s string) {
+	r.mu.Lock()
+	r.calls = append(r.calls, s)
+	r.mu.Unlock()
+}
+
+// service has a start and a stop step.
+type service struct {
+	name string
+	rec  *recorder
+}
+
+func (s *service) Start(context.Context) error { s.rec.add("start " + s.name); return nil }
+func (s *service) Stop(context.Context) error  { s.rec.add("stop " + s.name); return nil }
+
+// closer only has Close, like a cache.
+type closer struct {
+	name string
+	rec  *recorder
+}
+
+func (c *closer) Close() { c.rec.add("close " + c.name) }
+
+// =============================================================================
+// Function: Resolve()
+// =============================================================================
+
+func TestResolve_BuildsOnceWithDependencies(t *testing.T) {
+	r := New()
+	builds := 0
+	Provide(r, "port", func(*Registry) (int, error) {
+		builds++
+		return 8080, nil
+	})
+	Provide(r, "addr", func(r *Registry) (string, error) {
+		port, err := Resolve[int](r, "port")
+		if err != nil {
+			return "", err
+		}
+		if port != 8080 {
+			t.Errorf("port = %d", port)
+		}
+		return "localhost:8080", nil
+	})
+
+	for range 2 {
+		if addr, err := Resolve[string](r, "addr"); err != nil || addr != "localhost:8080" {
+			t.Fatalf("Resolve(addr) = %q, %v", addr, err)
+		}
+	}
+	if MustResolve[int](r, "port"); builds != 1 {
+		t.Errorf("port built %d times, want 1", builds)
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	r := New()
+	Supply(r, "n", 1)
+	if err := Supply(r, "n", 2); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("second Supply = %v, want ErrDuplicate", err)
+	}
+	if _, err := Resolve[int](r, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve(missing) = %v, want ErrNotFound", err)
+	}
+	if _, err := Resolve[string](r, "n"); !errors.Is(err, ErrType) {
+		t.Errorf("Resolve[string](n) = %v, want ErrType", err)
+	}
+
+	boom := errors.New("boom")
+	calls := 0
+	Provide(r, "flaky", func(*Registry) (int, error) {
+		if calls++; calls == 1 {
+			return 0, boom
+		}
+		return 2, nil
+	})
+	if _, err := Resolve[int](r, "flaky"); !errors.Is(err, boom) {
+		t.Errorf("Resolve(flaky) = %v, want boom", err)
+	}
+	if v, err := Resolve[int](r, "flaky"); err != nil || v != 2 {
+		t.Errorf("Resolve(flaky) retry = %d, %v, want 2", v, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustResolve(missing) did not panic")
+		}
+	}()
+	MustResolve[int](r, "missing")
+}
+
+func TestResolve_Cycle(t *testing.T) {
+	r := New()
+	Provide(r, "a", func(r *Registry) (int, error) { return Resolve[int](r, "b") })
+	Provide(r, "b", func(r *Registry) (int, error) { return Resolve[int](r, "c") })
+	Provide(r, "c", func(r *Registry) (int, error) { return Resolve[int](r, "a") })
+
+	_, err := Resolve[int](r, "a")
+	if !errors.Is(err, ErrCycle) {
+		t.Fatalf("Resolve(a) = %v, want ErrCycle", err)
+	}
+	if want := "registry: dependency cycle: a -> b -> c -> a"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+// =============================================================================
+// Method: Attach()
+// =============================================================================
+
+func TestAttach_DependencyOrder(t *testing.T) {
+	rec := &recorder{}
+	r := New()
+	// Provided before its dependencies, built after them.
+	Provide(r, "server", func(r *Registry) (*service, error) {
+		if _, err := Resolve[*closer](r, "cache"); err != nil {
+			return nil, err
+		}
+		if _, err := Resolve[*service](r, "queue"); err != nil {
+			return nil, err
+		}
+		return &service{name: "server", rec: rec}, nil
+	})
+	Provide(r, "queue", func(r *Registry) (*service, error) {
+		return &service{name: "queue", rec: rec}, nil
+	})
+	Supply(r, "cache", &closer{name: "cache", rec: rec})
+	Supply(r, "config", "no hooks")
+
+	m := lifecycle.New(lifecycle.WithSignals())
+	if err := r.Attach(m); err != nil {
+		t.Fatalf("Attach: %v", err)
+	}
+	if err := r.Attach(m); !errors.Is(err, ErrAttached) {
+		t.Errorf("second Attach = %v, want ErrAttached", err)
+	}
+
+	m.Shutdown()
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := []string{"start queue", "start server", "stop server", "stop queue", "close cache"}
+	if !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("calls = %v, want %v", rec.calls, want)
+	}
+}
+
+func TestAttach_BuildError(t *testing.T) {
+	r := New()
+	boom := errors.New("boom")
+	Provide(r, "bad", func(*Registry) (int, error) { return 0, boom })
+
+	m := lifecycle.New(lifecycle.WithSignals())
+	if err := r.Attach(m); !errors.Is(err, boom) {
+		t.Fatalf("Attach = %v, want boom", err)
+	}
+}
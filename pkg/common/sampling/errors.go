@@ -0,0 +1,7 @@
+package sampling
+
+import "errors"
+
+// ErrInvalidFlag is returned for a flag without a name or with a rollout
+// percentage outside [0, 100].
+var ErrInvalidFlag = errors.New("sampling: invalid flag")
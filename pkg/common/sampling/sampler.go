@@ -0,0 +1,137 @@
+// Package sampling decides which events, keys or users an operation applies
+// to: deterministic hash samplers that pick the same keys on every process,
+// rate-based samplers, and feature flags with percentage rollouts.
+package sampling
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/huynhanx03/go-common/pkg/hash"
+)
+
+// Sampler decides whether to keep an event that has no key of its own.
+type Sampler interface {
+	Sample() bool
+}
+
+// threshold maps a rate in [0, 1] to the hash values below which a key is
+// sampled. all is set for rates that keep every key.
+func threshold(rate float64) (t uint64, all bool) {
+	switch {
+	case rate >= 1:
+		return math.MaxUint64, true
+	case rate <= 0 || math.IsNaN(rate):
+		return 0, false
+	}
+	return uint64(rate * (1 << 64)), false
+}
+
+// keyHash hashes key with seed. Integer keys come back from
+// Hash64WithSeed merely XORed with the seed, so the result goes through the
+// splitmix64 finalizer to spread sequential IDs over the whole range.
+func keyHash[K hash.Key](key K, seed uint64) uint64 {
+	h := hash.Hash64WithSeed(key, seed)
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	return h ^ (h >> 31)
+}
+
+// Hash samples a fixed fraction of keys, the same ones on every call and
+// every process: sampling by user ID keeps or drops all of that user's
+// events together. Samplers with different salts pick independent keys,
+// and raising the rate only adds keys to those already sampled.
+type Hash[K hash.Key] struct {
+	seed      uint64
+	threshold uint64
+	all       bool
+}
+
+// NewHash returns a sampler keeping about rate (clamped to [0, 1]) of keys.
+func NewHash[K hash.Key](rate float64, salt string) *Hash[K] {
+	t, all := threshold(rate)
+	return &Hash[K]{seed: xxhash.Sum64String(salt), threshold: t, all: all}
+}
+
+// Sample reports whether key is in the sample.
+func (s *Hash[K]) Sample(key K) bool {
+	return s.all || keyHash(key, s.seed) < s.threshold
+}
+
+// Random keeps each event independently with a fixed probability.
+type Random struct {
+	rate float64
+}
+
+// NewRandom returns a sampler keeping about rate (clamped to [0, 1]) of
+// events.
+func NewRandom(rate float64) *Random {
+	return &Random{rate: min(max(rate, 0), 1)}
+}
+
+// Sample implements Sampler.
+func (s *Random) Sample() bool {
+	return s.rate > 0 && (s.rate == 1 || rand.Float64() < s.rate)
+}
+
+// EveryN keeps the first event and every nth after it.
+type EveryN struct {
+	n     uint64
+	count atomic.Uint64
+}
+
+// NewEveryN returns a sampler keeping one event in n; n < 1 keeps all.
+func NewEveryN(n int) *EveryN {
+	return &EveryN{n: uint64(max(n, 1))}
+}
+
+// Sample implements Sampler.
+func (s *EveryN) Sample() bool {
+	return (s.count.Add(1)-1)%s.n == 0
+}
+
+// RateLimited keeps up to perSecond events per second with bursts of up to
+// burst, dropping the rest: a token bucket.
+type RateLimited struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewRateLimited returns a sampler keeping up to perSecond events per
+// second, and up to burst (at least 1) at once after a quiet spell.
+func NewRateLimited(perSecond float64, burst int) *RateLimited {
+	b := float64(max(burst, 1))
+	s := &RateLimited{rate: max(perSecond, 0), burst: b, tokens: b, now: time.Now}
+	s.last = s.now()
+	return s
+}
+
+// Sample implements Sampler.
+func (s *RateLimited) Sample() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.tokens = min(s.burst, s.tokens+now.Sub(s.last).Seconds()*s.rate)
+	s.last = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+var (
+	_ Sampler = (*Random)(nil)
+	_ Sampler = (*EveryN)(nil)
+	_ Sampler = (*RateLimited)(nil)
+)
@@ -0,0 +1,225 @@
+package sampling
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// Samplers
+// =============================================================================
+
+func TestHashDeterministic(t *testing.T) {
+	a := NewHash[string](0.3, "logs")
+	b := NewHash[string](0.3, "logs")
+	other := NewHash[string](0.3, "traces")
+
+	kept, differ := 0, 0
+	const n = 20_000
+	for i := range n {
+		key := "user-" + strconv.Itoa(i)
+		got := a.Sample(key)
+		if got != b.Sample(key) || got != a.Sample(key) {
+			t.Fatalf("samplers with the same salt disagree on %s", key)
+		}
+		if got {
+			kept++
+		}
+		if got != other.Sample(key) {
+			differ++
+		}
+	}
+	if r := float64(kept) / n; math.Abs(r-0.3) > 0.02 {
+		t.Errorf("kept %.3f of keys, want ~0.3", r)
+	}
+	if differ == 0 {
+		t.Error("samplers with different salts picked the same keys")
+	}
+}
+
+func TestHashMonotonic(t *testing.T) {
+	low := NewHash[uint64](0.1, "s")
+	high := NewHash[uint64](0.5, "s")
+	kept := 0
+	for i := range uint64(10_000) {
+		if low.Sample(i) && !high.Sample(i) {
+			t.Fatalf("key %d sampled at 10%% but not at 50%%", i)
+		}
+		if high.Sample(i) {
+			kept++
+		}
+	}
+	// Sequential integer IDs must spread like strings do.
+	if r := float64(kept) / 10_000; math.Abs(r-0.5) > 0.03 {
+		t.Errorf("kept %.3f of sequential IDs, want ~0.5", r)
+	}
+}
+
+func TestHashBounds(t *testing.T) {
+	none, all := NewHash[string](0, "s"), NewHash[string](2, "s")
+	for i := range 1000 {
+		k := strconv.Itoa(i)
+		if none.Sample(k) || !all.Sample(k) {
+			t.Fatalf("rate bounds broken for %s", k)
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	if NewRandom(0).Sample() || !NewRandom(1).Sample() {
+		t.Fatal("rate bounds broken")
+	}
+	s := NewRandom(0.25)
+	kept := 0
+	for range 20_000 {
+		if s.Sample() {
+			kept++
+		}
+	}
+	if r := float64(kept) / 20_000; math.Abs(r-0.25) > 0.03 {
+		t.Errorf("kept %.3f, want ~0.25", r)
+	}
+}
+
+func TestEveryN(t *testing.T) {
+	s := NewEveryN(3)
+	var got []bool
+	for range 7 {
+		got = append(got, s.Sample())
+	}
+	want := []bool{true, false, false, true, false, false, true}
+	if !slices.Equal(got, want) {
+		t.Errorf("Sample sequence = %v, want %v", got, want)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	kept := 0
+	s = NewEveryN(10)
+	for range 4 {
+		wg.Go(func() {
+			for range 250 {
+				if s.Sample() {
+					mu.Lock()
+					kept++
+					mu.Unlock()
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if kept != 100 {
+		t.Errorf("kept %d of 1000, want 100", kept)
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewRateLimited(10, 5)
+	s.now = func() time.Time { return now }
+	s.last = now
+
+	kept := 0
+	for range 20 {
+		if s.Sample() {
+			kept++
+		}
+	}
+	if kept != 5 {
+		t.Fatalf("burst kept %d, want 5", kept)
+	}
+
+	now = now.Add(300 * time.Millisecond) // 3 tokens
+	kept = 0
+	for range 20 {
+		if s.Sample() {
+			kept++
+		}
+	}
+	if kept != 3 {
+		t.Errorf("after 300ms kept %d, want 3", kept)
+	}
+}
+
+// =============================================================================
+// Flags
+// =============================================================================
+
+func TestEvaluator(t *testing.T) {
+	e, err := NewEvaluator(
+		Flag{Name: "new-ui", Enabled: true, Percent: 20, Allow: []string{"vip"}, Deny: []string{"bot"}},
+		Flag{Name: "off", Enabled: false, Percent: 100, Allow: []string{"vip"}},
+		Flag{Name: "all", Enabled: true, Percent: 100, Deny: []string{"bot"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !e.Enabled("new-ui", "vip") || e.Enabled("new-ui", "bot") {
+		t.Error("allow/deny lists ignored")
+	}
+	if e.Enabled("off", "vip") {
+		t.Error("disabled flag on for an allowed key")
+	}
+	if !e.Enabled("all", "anyone") || e.Enabled("all", "bot") {
+		t.Error("100% rollout wrong")
+	}
+	if e.Enabled("unknown", "vip") {
+		t.Error("unknown flag on")
+	}
+
+	on := 0
+	for i := range 10_000 {
+		if e.Enabled("new-ui", "user-"+strconv.Itoa(i)) {
+			on++
+		}
+	}
+	if r := float64(on) / 10_000; math.Abs(r-0.2) > 0.02 {
+		t.Errorf("rollout on for %.3f of keys, want ~0.2", r)
+	}
+
+	if got := e.Names(); !slices.Equal(got, []string{"all", "new-ui", "off"}) {
+		t.Errorf("Names = %v", got)
+	}
+	e.Delete("all")
+	if e.Enabled("all", "anyone") {
+		t.Error("deleted flag still on")
+	}
+}
+
+func TestEvaluatorRolloutGrows(t *testing.T) {
+	e, _ := NewEvaluator(Flag{Name: "f", Enabled: true, Percent: 10})
+	var before []string
+	for i := range 5000 {
+		if k := strconv.Itoa(i); e.Enabled("f", k) {
+			before = append(before, k)
+		}
+	}
+
+	if err := e.Set(Flag{Name: "f", Enabled: true, Percent: 60}); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range before {
+		if !e.Enabled("f", k) {
+			t.Fatalf("key %s lost the flag when the rollout grew", k)
+		}
+	}
+}
+
+func TestEvaluatorInvalid(t *testing.T) {
+	for _, f := range []Flag{
+		{Name: "", Enabled: true},
+		{Name: "f", Percent: -1},
+		{Name: "f", Percent: 101},
+		{Name: "f", Percent: math.NaN()},
+	} {
+		if _, err := NewEvaluator(f); !errors.Is(err, ErrInvalidFlag) {
+			t.Errorf("NewEvaluator(%+v) err = %v, want ErrInvalidFlag", f, err)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package scheduler
+
+import "errors"
+
+// Sentinel errors for the scheduler.
+var (
+	ErrStopped     = errors.New("scheduler: scheduler is stopped")
+	ErrInvalidSpec = errors.New("scheduler: invalid schedule spec")
+	ErrJobPanic    = errors.New("scheduler: job panicked")
+)
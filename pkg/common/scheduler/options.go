@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// OverlapPolicy decides what happens when a job is due while its previous
+// run is still in progress.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the new run.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs the new run right after the current one finishes.
+	OverlapQueue
+	// OverlapCancel cancels the current run's context and starts the new run.
+	OverlapCancel
+)
+
+// Submitter dispatches a task, e.g. a *workerpool.Pool or *workerpool.MultiPool.
+type Submitter interface {
+	Submit(task func()) error
+}
+
+// MetricsHook provides observability callbacks for the scheduler.
+// All callbacks are optional — nil means no-op.
+type MetricsHook struct {
+	// OnRun is called after a run finishes with its duration and result.
+	OnRun func(job string, took time.Duration, err error)
+
+	// OnSkip is called when a due run is dropped by OverlapSkip or
+	// rejected by the pool.
+	OnSkip func(job string)
+
+	// OnCancel is called when OverlapCancel cancels an in-flight run.
+	OnCancel func(job string)
+}
+
+func (m *MetricsHook) runHook(job string, took time.Duration, err error) {
+	if m != nil && m.OnRun != nil {
+		m.OnRun(job, took, err)
+	}
+}
+
+func (m *MetricsHook) skipHook(job string) {
+	if m != nil && m.OnSkip != nil {
+		m.OnSkip(job)
+	}
+}
+
+func (m *MetricsHook) cancelHook(job string) {
+	if m != nil && m.OnCancel != nil {
+		m.OnCancel(job)
+	}
+}
+
+// Config holds all configuration for the scheduler.
+type Config struct {
+	Pool     Submitter      // dispatches runs, nil = one goroutine per run
+	Location *time.Location // time zone for cron specs
+	Metrics  *MetricsHook   // optional observability hooks
+}
+
+// Option configures the scheduler.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{Location: time.Local}
+}
+
+// WithPool dispatches runs through p instead of spawning goroutines.
+func WithPool(p Submitter) Option {
+	return func(c *Config) { c.Pool = p }
+}
+
+// WithLocation sets the time zone cron specs are evaluated in.
+func WithLocation(loc *time.Location) Option {
+	return func(c *Config) {
+		if loc != nil {
+			c.Location = loc
+		}
+	}
+}
+
+// WithMetrics attaches observability hooks.
+func WithMetrics(m *MetricsHook) Option {
+	return func(c *Config) { c.Metrics = m }
+}
+
+// jobConfig holds per-job settings.
+type jobConfig struct {
+	name    string
+	jitter  time.Duration
+	overlap OverlapPolicy
+}
+
+// JobOption configures a single scheduled job.
+type JobOption func(*jobConfig)
+
+// WithName sets the name reported to metrics hooks.
+func WithName(name string) JobOption {
+	return func(c *jobConfig) { c.name = name }
+}
+
+// WithJitter delays every run by a random duration in [0, d), spreading
+// jobs that share a schedule across instances.
+func WithJitter(d time.Duration) JobOption {
+	return func(c *jobConfig) {
+		if d > 0 {
+			c.jitter = d
+		}
+	}
+}
+
+// WithOverlap sets the overlapping-run policy (default OverlapSkip).
+func WithOverlap(p OverlapPolicy) JobOption {
+	return func(c *jobConfig) { c.overlap = p }
+}
@@ -0,0 +1,304 @@
+// Package scheduler runs jobs on cron specs or fixed intervals, with
+// optional jitter and a per-job policy for runs that would overlap.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Job is a unit of scheduled work. ctx is cancelled when the run is
+// superseded (OverlapCancel) or the scheduler's shutdown deadline expires.
+type Job func(ctx context.Context) error
+
+// JobID identifies a scheduled job.
+type JobID uint64
+
+// entry is one scheduled job and its run state.
+type entry struct {
+	id       JobID
+	schedule Schedule
+	job      Job
+	config   jobConfig
+	remove   chan struct{}
+
+	mu       sync.Mutex
+	active   int                           // runs in flight or dispatched
+	pending  int                           // runs queued by OverlapQueue
+	runSeq   uint64                        // last run number
+	inflight map[uint64]context.CancelFunc // cancel funcs of runs in flight
+}
+
+// Scheduler fires jobs according to their schedules.
+// It is safe for concurrent use.
+type Scheduler struct {
+	config Config
+
+	ctx    context.Context    // parent of every run context
+	cancel context.CancelFunc // cancels all runs on shutdown deadline
+
+	mu      sync.Mutex
+	entries map[JobID]*entry
+	nextID  JobID
+	started bool
+	stopped bool
+	stop    chan struct{}
+
+	loops sync.WaitGroup // one timer loop per entry
+	runs  sync.WaitGroup // runs in flight or dispatched
+}
+
+// New creates a scheduler. Jobs start firing once Start is called.
+func New(opts ...Option) *Scheduler {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		config:  cfg,
+		ctx:     ctx,
+		cancel:  cancel,
+		entries: make(map[JobID]*entry),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Schedule adds a job fired by spec, a cron expression, a descriptor such
+// as "@hourly", or "@every <duration>". See Parse.
+func (s *Scheduler) Schedule(spec string, job Job, opts ...JobOption) (JobID, error) {
+	sched, err := Parse(spec, s.config.Location)
+	if err != nil {
+		return 0, err
+	}
+	return s.ScheduleFunc(sched, job, opts...)
+}
+
+// ScheduleEvery adds a job fired every interval.
+func (s *Scheduler) ScheduleEvery(interval time.Duration, job Job, opts ...JobOption) (JobID, error) {
+	return s.ScheduleFunc(Every(interval), job, opts...)
+}
+
+// ScheduleFunc adds a job fired by a custom schedule.
+func (s *Scheduler) ScheduleFunc(sched Schedule, job Job, opts ...JobOption) (JobID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return 0, ErrStopped
+	}
+
+	s.nextID++
+	e := &entry{
+		id:       s.nextID,
+		schedule: sched,
+		job:      job,
+		remove:   make(chan struct{}),
+		inflight: make(map[uint64]context.CancelFunc),
+	}
+	for _, o := range opts {
+		o(&e.config)
+	}
+	if e.config.name == "" {
+		e.config.name = fmt.Sprintf("job-%d", e.id)
+	}
+
+	s.entries[e.id] = e
+	if s.started {
+		s.startLoop(e)
+	}
+	return e.id, nil
+}
+
+// Remove unschedules a job. A run already in progress is not interrupted.
+func (s *Scheduler) Remove(id JobID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return false
+	}
+	delete(s.entries, id)
+	close(e.remove)
+	return true
+}
+
+// Len returns the number of scheduled jobs.
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Start begins firing jobs. Calling Start more than once is a no-op.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+	for _, e := range s.entries {
+		s.startLoop(e)
+	}
+}
+
+// startLoop runs the timer loop of e. Caller holds s.mu.
+func (s *Scheduler) startLoop(e *entry) {
+	s.loops.Add(1)
+	go s.loop(e)
+}
+
+// loop waits for each activation of e and fires it.
+func (s *Scheduler) loop(e *entry) {
+	defer s.loops.Done()
+
+	for {
+		next := e.schedule.Next(time.Now())
+		if next.IsZero() {
+			return // schedule never fires again
+		}
+		if e.config.jitter > 0 {
+			next = next.Add(rand.N(e.config.jitter))
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			s.fire(e)
+		case <-e.remove:
+			timer.Stop()
+			return
+		case <-s.stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// fire applies the overlap policy and dispatches a run.
+func (s *Scheduler) fire(e *entry) {
+	e.mu.Lock()
+	if e.active > 0 {
+		switch e.config.overlap {
+		case OverlapSkip:
+			e.mu.Unlock()
+			s.config.Metrics.skipHook(e.config.name)
+			return
+		case OverlapQueue:
+			e.pending++
+			e.mu.Unlock()
+			return
+		case OverlapCancel:
+			for seq, cancel := range e.inflight {
+				cancel()
+				delete(e.inflight, seq)
+			}
+			s.config.Metrics.cancelHook(e.config.name)
+		}
+	}
+	e.active++
+	e.mu.Unlock()
+
+	s.runs.Add(1)
+	if err := s.dispatch(func() { s.run(e) }); err != nil {
+		e.mu.Lock()
+		e.active--
+		e.mu.Unlock()
+		s.runs.Done()
+		s.config.Metrics.skipHook(e.config.name)
+	}
+}
+
+// dispatch hands task to the pool if configured, otherwise to a goroutine.
+func (s *Scheduler) dispatch(task func()) error {
+	if s.config.Pool != nil {
+		return s.config.Pool.Submit(task)
+	}
+	go task()
+	return nil
+}
+
+// run executes e, then any runs queued behind it.
+func (s *Scheduler) run(e *entry) {
+	defer s.runs.Done()
+
+	for {
+		ctx, cancel := context.WithCancel(s.ctx)
+		e.mu.Lock()
+		e.runSeq++
+		seq := e.runSeq
+		e.inflight[seq] = cancel
+		e.mu.Unlock()
+
+		start := time.Now()
+		err := call(ctx, e.job)
+		cancel()
+		s.config.Metrics.runHook(e.config.name, time.Since(start), err)
+
+		e.mu.Lock()
+		delete(e.inflight, seq)
+		if e.pending > 0 && !s.isStopped() {
+			e.pending--
+			e.mu.Unlock()
+			continue
+		}
+		e.pending = 0
+		e.active--
+		e.mu.Unlock()
+		return
+	}
+}
+
+// call invokes job, converting a panic into ErrJobPanic.
+func call(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrJobPanic, r)
+		}
+	}()
+	return job(ctx)
+}
+
+func (s *Scheduler) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+// Stop stops firing jobs and waits for in-flight runs to finish. Queued
+// runs are dropped. If ctx expires first, the contexts of all in-flight runs
+// are cancelled and ctx.Err() is returned without waiting further.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	close(s.stop)
+	s.mu.Unlock()
+
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
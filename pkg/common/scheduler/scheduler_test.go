@@ -0,0 +1,290 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/workerpool"
+)
+
+// =============================================================================
+// Spec parsing
+// =============================================================================
+
+func TestParseNext(t *testing.T) {
+	base := time.Date(2024, 3, 15, 10, 30, 20, 0, time.UTC) // Friday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17 * * *", time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"5,10 8 * * *", time.Date(2024, 3, 16, 8, 5, 0, 0, time.UTC)},
+		{"0 0 * * 1-5", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2024, 3, 22, 0, 0, 0, 0, time.UTC)}, // dom OR dow
+		{"@hourly", time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"@every 90s", base.Add(90 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec, time.UTC)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := s.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "@every -1s", "@daily extra"} {
+		if _, err := Parse(spec, time.UTC); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("Parse(%q) err = %v, want ErrInvalidSpec", spec, err)
+		}
+	}
+}
+
+func TestParseNeverMatches(t *testing.T) {
+	s, err := Parse("0 0 30 2 *", time.UTC)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next = %v, want zero time", got)
+	}
+}
+
+// =============================================================================
+// Scheduling
+// =============================================================================
+
+func TestScheduleEvery(t *testing.T) {
+	s := New()
+	var n atomic.Int32
+	if _, err := s.ScheduleEvery(5*time.Millisecond, func(context.Context) error {
+		n.Add(1)
+		return nil
+	}); err != nil {
+		t.Fatalf("ScheduleEvery: %v", err)
+	}
+
+	s.Start()
+	time.Sleep(60 * time.Millisecond)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	if got := n.Load(); got < 3 {
+		t.Errorf("runs = %d, want >= 3", got)
+	}
+	if _, err := s.ScheduleEvery(time.Second, func(context.Context) error { return nil }); !errors.Is(err, ErrStopped) {
+		t.Errorf("Schedule after Stop err = %v, want ErrStopped", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	s := New()
+	s.Start()
+	defer s.Stop(context.Background())
+
+	var n atomic.Int32
+	id, _ := s.ScheduleEvery(2*time.Millisecond, func(context.Context) error {
+		n.Add(1)
+		return nil
+	})
+	time.Sleep(20 * time.Millisecond)
+	if !s.Remove(id) {
+		t.Fatal("Remove returned false")
+	}
+	if s.Remove(id) {
+		t.Error("second Remove returned true")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	after := n.Load()
+	time.Sleep(20 * time.Millisecond)
+	if n.Load() != after {
+		t.Errorf("job ran after Remove: %d -> %d", after, n.Load())
+	}
+}
+
+// =============================================================================
+// Overlap policies
+// =============================================================================
+
+// slowJob blocks every run until release is closed.
+func slowJob(runs *atomic.Int32, release <-chan struct{}) Job {
+	return func(ctx context.Context) error {
+		runs.Add(1)
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return ctx.Err()
+	}
+}
+
+func TestOverlapSkip(t *testing.T) {
+	var skips atomic.Int32
+	s := New(WithMetrics(&MetricsHook{OnSkip: func(string) { skips.Add(1) }}))
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	s.ScheduleEvery(2*time.Millisecond, slowJob(&runs, release), WithOverlap(OverlapSkip))
+	s.Start()
+
+	time.Sleep(30 * time.Millisecond)
+	close(release)
+	s.Stop(context.Background())
+
+	if runs.Load() < 1 || skips.Load() == 0 {
+		t.Errorf("runs = %d, skips = %d; want the overlapping runs skipped", runs.Load(), skips.Load())
+	}
+}
+
+func TestOverlapQueue(t *testing.T) {
+	s := New()
+
+	var runs, concurrent, maxConcurrent atomic.Int32
+	s.ScheduleEvery(2*time.Millisecond, func(context.Context) error {
+		c := concurrent.Add(1)
+		defer concurrent.Add(-1)
+		if c > maxConcurrent.Load() {
+			maxConcurrent.Store(c)
+		}
+		runs.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}, WithOverlap(OverlapQueue))
+	s.Start()
+
+	time.Sleep(50 * time.Millisecond)
+	s.Stop(context.Background())
+
+	if maxConcurrent.Load() != 1 {
+		t.Errorf("max concurrent runs = %d, want 1", maxConcurrent.Load())
+	}
+	if runs.Load() < 3 {
+		t.Errorf("runs = %d, want queued runs to execute back-to-back", runs.Load())
+	}
+}
+
+func TestOverlapCancel(t *testing.T) {
+	var cancels atomic.Int32
+	var mu sync.Mutex
+	var errs []error
+	s := New(WithMetrics(&MetricsHook{
+		OnCancel: func(string) { cancels.Add(1) },
+		OnRun: func(_ string, _ time.Duration, err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		},
+	}))
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	s.ScheduleEvery(5*time.Millisecond, slowJob(&runs, release), WithOverlap(OverlapCancel))
+	s.Start()
+
+	time.Sleep(30 * time.Millisecond)
+	close(release)
+	s.Stop(context.Background())
+
+	if cancels.Load() == 0 {
+		t.Fatal("no run was cancelled")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) == 0 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("first run err = %v, want context.Canceled", errs)
+	}
+}
+
+// =============================================================================
+// Shutdown & dispatch
+// =============================================================================
+
+func TestStopDeadlineCancelsRuns(t *testing.T) {
+	s := New()
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	s.ScheduleEvery(time.Millisecond, func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+	s.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop err = %v, want DeadlineExceeded", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("run context was not cancelled")
+	}
+}
+
+func TestPanicReported(t *testing.T) {
+	errc := make(chan error, 1)
+	s := New(WithMetrics(&MetricsHook{OnRun: func(_ string, _ time.Duration, err error) {
+		select {
+		case errc <- err:
+		default:
+		}
+	}}))
+	s.ScheduleEvery(time.Millisecond, func(context.Context) error { panic("boom") })
+	s.Start()
+	defer s.Stop(context.Background())
+
+	if err := <-errc; !errors.Is(err, ErrJobPanic) {
+		t.Errorf("err = %v, want ErrJobPanic", err)
+	}
+}
+
+func TestDispatchViaPool(t *testing.T) {
+	pool, err := workerpool.NewPool(2)
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	defer pool.Release()
+
+	s := New(WithPool(pool))
+	done := make(chan struct{}, 1)
+	s.ScheduleEvery(time.Millisecond, func(context.Context) error {
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	s.Start()
+	defer s.Stop(context.Background())
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job never ran through the pool")
+	}
+}
@@ -0,0 +1,7 @@
+package semaphore
+
+import "errors"
+
+// ErrTooLarge is returned by Acquire for a request larger than the
+// semaphore, which could never be granted.
+var ErrTooLarge = errors.New("semaphore: request exceeds size")
@@ -0,0 +1,35 @@
+package semaphore
+
+import "github.com/huynhanx03/go-common/pkg/common/metrics"
+
+// Config holds the semaphore settings.
+type Config struct {
+	// Fair grants waiters strictly in arrival order: a large request at the
+	// head of the queue holds back smaller ones behind it, and TryAcquire
+	// fails while anyone waits. Without it, any waiter that fits is granted
+	// as tokens free up, which raises throughput but can starve large
+	// requests. Defaults to true.
+	Fair bool
+
+	// Meter receives "semaphore.waiters", the number of blocked Acquires,
+	// "semaphore.wait_seconds", how long granted Acquires blocked, and
+	// "semaphore.cancelled", Acquires abandoned to their context.
+	Meter metrics.Meter
+}
+
+// Option configures a Weighted semaphore.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{Fair: true}
+}
+
+// WithFairness sets Config.Fair.
+func WithFairness(fair bool) Option {
+	return func(c *Config) { c.Fair = fair }
+}
+
+// WithMeter sets Config.Meter.
+func WithMeter(m metrics.Meter) Option {
+	return func(c *Config) { c.Meter = m }
+}
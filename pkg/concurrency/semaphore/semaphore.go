@@ -0,0 +1,191 @@
+// Package semaphore bounds concurrent use of a resource with a weighted
+// semaphore: worker slots, pooled connections, bytes in flight. Each
+// Acquire takes n tokens out of a fixed size and blocks, honouring its
+// context, until they are free; a plain counting semaphore acquires 1.
+package semaphore
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+)
+
+// waiter is a blocked Acquire. ready is closed once its tokens are granted.
+type waiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// Stats reports semaphore metrics.
+type Stats struct {
+	Size      int64
+	InUse     int64  // tokens held
+	Waiters   int    // Acquires blocked now
+	Waited    uint64 // Acquires granted after blocking
+	Cancelled uint64 // Acquires abandoned to their context
+}
+
+// Weighted is a semaphore of size tokens.
+// It is safe for concurrent use.
+type Weighted struct {
+	size int64
+	fair bool
+
+	mu        sync.Mutex
+	cur       int64
+	waiters   list.List // of *waiter, in arrival order
+	waited    uint64
+	cancelled uint64
+
+	waitersGauge     metrics.Gauge
+	waitHist         metrics.Histogram
+	cancelledCounter metrics.Counter
+}
+
+// New returns a semaphore of size tokens, at least 1.
+func New(size int64, opts ...Option) *Weighted {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	m := metrics.OrNoop(cfg.Meter)
+	return &Weighted{
+		size: max(size, 1),
+		fair: cfg.Fair,
+
+		waitersGauge:     m.Gauge("semaphore.waiters", "Acquires blocked waiting for tokens"),
+		waitHist:         m.Histogram("semaphore.wait_seconds", "Time granted Acquires spent blocked"),
+		cancelledCounter: m.Counter("semaphore.cancelled", "Acquires abandoned to their context"),
+	}
+}
+
+// fits reports whether n tokens can be granted now without jumping the
+// queue. Callers hold s.mu.
+func (s *Weighted) fits(n int64) bool {
+	return s.size-s.cur >= n && (!s.fair || s.waiters.Len() == 0)
+}
+
+// Acquire takes n tokens, blocking until they are free or ctx is done. On
+// failure it returns ctx.Err() and takes nothing. n larger than the
+// semaphore fails at once with ErrTooLarge.
+func (s *Weighted) Acquire(ctx context.Context, n int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.fits(n) {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+	if n > s.size {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %d > %d", ErrTooLarge, n, s.size)
+	}
+
+	w := &waiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.waitersGauge.Set(float64(s.waiters.Len()))
+	s.mu.Unlock()
+
+	start := time.Now()
+	select {
+	case <-w.ready:
+		s.observeWait(start)
+		return nil
+
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// Granted as ctx was cancelled: the caller sees the error, so
+			// hand the tokens on.
+			s.cur -= n
+		default:
+			s.waiters.Remove(elem)
+			s.waitersGauge.Set(float64(s.waiters.Len()))
+		}
+		// Leaving may unblock those behind a large request at the head.
+		s.notify()
+		s.cancelled++
+		s.mu.Unlock()
+		s.cancelledCounter.Add(1)
+		return ctx.Err()
+	}
+}
+
+// observeWait records a granted Acquire that blocked since start.
+func (s *Weighted) observeWait(start time.Time) {
+	s.waitHist.Record(time.Since(start).Seconds())
+	s.mu.Lock()
+	s.waited++
+	s.mu.Unlock()
+}
+
+// TryAcquire takes n tokens if they are free now and reports whether it
+// did. A fair semaphore refuses while Acquires are waiting.
+func (s *Weighted) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.fits(n) {
+		return false
+	}
+	s.cur += n
+	return true
+}
+
+// Release returns n tokens and grants the waiters they unblock. Releasing
+// more than is held panics.
+func (s *Weighted) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic("semaphore: released more than held")
+	}
+	s.notify()
+}
+
+// notify grants waiters while tokens last: in order, stopping at the first
+// that does not fit when fair, or every one that fits otherwise. Callers
+// hold s.mu.
+func (s *Weighted) notify() {
+	granted := false
+	for e := s.waiters.Front(); e != nil; {
+		w := e.Value.(*waiter)
+		next := e.Next()
+		if s.size-s.cur < w.n {
+			if s.fair {
+				break
+			}
+			e = next
+			continue
+		}
+		s.cur += w.n
+		s.waiters.Remove(e)
+		close(w.ready)
+		granted = true
+		e = next
+	}
+	if granted {
+		s.waitersGauge.Set(float64(s.waiters.Len()))
+	}
+}
+
+// Stats returns the semaphore's current metrics.
+func (s *Weighted) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{
+		Size:      s.size,
+		InUse:     s.cur,
+		Waiters:   s.waiters.Len(),
+		Waited:    s.waited,
+		Cancelled: s.cancelled,
+	}
+}
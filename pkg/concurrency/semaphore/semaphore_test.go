@@ -0,0 +1,251 @@
+package semaphore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+)
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not reached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// acquireAsync starts Acquire(ctx, n) and returns its result channel.
+func acquireAsync(s *Weighted, ctx context.Context, n int64) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- s.Acquire(ctx, n) }()
+	return done
+}
+
+// =============================================================================
+// Acquire / TryAcquire / Release Tests
+// =============================================================================
+
+func TestAcquireRelease(t *testing.T) {
+	s := New(5)
+	ctx := context.Background()
+	if err := s.Acquire(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !s.TryAcquire(2) || s.TryAcquire(1) {
+		t.Fatal("TryAcquire ignored the tokens held")
+	}
+
+	done := acquireAsync(s, ctx, 4)
+	waitFor(t, func() bool { return s.Stats().Waiters == 1 })
+	s.Release(3)
+	select {
+	case <-done:
+		t.Fatal("Acquire(4) granted with 3 tokens free")
+	case <-time.After(10 * time.Millisecond):
+	}
+	s.Release(2)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	st := s.Stats()
+	if st.Size != 5 || st.InUse != 4 || st.Waiters != 0 || st.Waited != 1 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestAcquireTooLarge(t *testing.T) {
+	s := New(2)
+	if err := s.Acquire(context.Background(), 3); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestReleaseTooMuchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Release of unheld tokens did not panic")
+		}
+	}()
+	New(2).Release(1)
+}
+
+func TestAcquireContext(t *testing.T) {
+	s := New(1)
+	s.TryAcquire(1)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Acquire(cancelled, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("done ctx: err = %v, want Canceled", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Acquire(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+	st := s.Stats()
+	if st.InUse != 1 || st.Waiters != 0 || st.Cancelled != 1 {
+		t.Errorf("Stats after timeout = %+v", st)
+	}
+}
+
+// A large waiter that gives up must not leave the smaller ones behind it
+// stuck.
+func TestCancelUnblocksQueue(t *testing.T) {
+	s := New(4)
+	s.TryAcquire(3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	big := acquireAsync(s, ctx, 4)
+	waitFor(t, func() bool { return s.Stats().Waiters == 1 })
+	small := acquireAsync(s, context.Background(), 1)
+	waitFor(t, func() bool { return s.Stats().Waiters == 2 })
+
+	cancel()
+	if err := <-big; !errors.Is(err, context.Canceled) {
+		t.Fatalf("big err = %v", err)
+	}
+	if err := <-small; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// =============================================================================
+// Fairness Tests
+// =============================================================================
+
+func TestFairOrder(t *testing.T) {
+	s := New(4)
+	s.TryAcquire(4)
+	ctx := context.Background()
+
+	big := acquireAsync(s, ctx, 3)
+	waitFor(t, func() bool { return s.Stats().Waiters == 1 })
+	small := acquireAsync(s, ctx, 1)
+	waitFor(t, func() bool { return s.Stats().Waiters == 2 })
+
+	s.Release(2) // fits small, but big is ahead
+	if s.TryAcquire(1) {
+		t.Error("TryAcquire jumped the queue")
+	}
+	select {
+	case <-small:
+		t.Fatal("small waiter granted ahead of big")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	s.Release(2)
+	if err := <-big; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-small; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnfairGrantsWhatFits(t *testing.T) {
+	s := New(4, WithFairness(false))
+	s.TryAcquire(4)
+	ctx := context.Background()
+
+	big := acquireAsync(s, ctx, 3)
+	waitFor(t, func() bool { return s.Stats().Waiters == 1 })
+	small := acquireAsync(s, ctx, 1)
+	waitFor(t, func() bool { return s.Stats().Waiters == 2 })
+
+	s.Release(1)
+	if err := <-small; err != nil {
+		t.Fatal(err)
+	}
+	s.Release(1)
+	if !s.TryAcquire(1) {
+		t.Error("unfair TryAcquire refused free tokens while waiters queue")
+	}
+	s.Release(3)
+	if err := <-big; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// =============================================================================
+// Concurrency and Metrics Tests
+// =============================================================================
+
+func TestConcurrentLimit(t *testing.T) {
+	const size = 3
+	s := New(size)
+	var cur, peak atomic.Int64
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			for range 50 {
+				if err := s.Acquire(context.Background(), 1); err != nil {
+					t.Error(err)
+					return
+				}
+				n := cur.Add(1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				cur.Add(-1)
+				s.Release(1)
+			}
+		})
+	}
+	wg.Wait()
+	if peak.Load() > size {
+		t.Errorf("peak holders = %d, want <= %d", peak.Load(), size)
+	}
+	if st := s.Stats(); st.InUse != 0 || st.Waiters != 0 {
+		t.Errorf("Stats after run = %+v", st)
+	}
+}
+
+func TestMeter(t *testing.T) {
+	m := metrics.NewMemory()
+	s := New(1, WithMeter(m))
+	s.TryAcquire(1)
+
+	done := acquireAsync(s, context.Background(), 1)
+	waitFor(t, func() bool { return m.GaugeValue("semaphore.waiters") == 1 })
+	s.Release(1)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GaugeValue("semaphore.waiters"); got != 0 {
+		t.Errorf("waiters gauge = %v, want 0", got)
+	}
+	if count, _ := m.HistogramValue("semaphore.wait_seconds"); count != 1 {
+		t.Errorf("wait_seconds count = %d, want 1", count)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_ = s.Acquire(ctx, 1)
+	if got := m.CounterValue("semaphore.cancelled"); got != 1 {
+		t.Errorf("cancelled counter = %d, want 1", got)
+	}
+}
+
+// =============================================================================
+// Benchmarks
+// =============================================================================
+
+func BenchmarkAcquireRelease(b *testing.B) {
+	s := New(1)
+	ctx := context.Background()
+	for b.Loop() {
+		_ = s.Acquire(ctx, 1)
+		s.Release(1)
+	}
+}
@@ -0,0 +1,200 @@
+package bloom
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// agingConfig holds the rotation triggers of an AgingBloom.
+type agingConfig struct {
+	every time.Duration // rotate after this much time, 0 = never
+	after uint64        // rotate after this many Adds, 0 = never
+}
+
+// AgingOption configures an AgingBloom.
+type AgingOption func(*agingConfig)
+
+// WithRotateEvery rotates the generations every d.
+func WithRotateEvery(d time.Duration) AgingOption {
+	return func(c *agingConfig) {
+		if d > 0 {
+			c.every = d
+		}
+	}
+}
+
+// WithRotateAfter rotates the generations after n Adds to the current one.
+func WithRotateAfter(n uint64) AgingOption {
+	return func(c *agingConfig) { c.after = n }
+}
+
+// AgingBloom is a two-generation Bloom filter for sliding-window dedup.
+//
+// Adds go to the current generation; Has checks both the current and the
+// previous one. On rotation the previous generation is discarded and the
+// current one becomes previous, so an element is remembered for at least one
+// and at most two rotation periods, and the filters never saturate.
+// It is safe for concurrent use.
+type AgingBloom struct {
+	mu       sync.Mutex
+	current  *Bloom
+	previous *Bloom
+	config   agingConfig
+
+	inserts  uint64    // Adds to current since the last rotation
+	deadline time.Time // next time-based rotation
+	now      func() time.Time
+}
+
+// NewAging creates an AgingBloom whose generations each hold capacity
+// elements at fpRate. By default it rotates after capacity Adds; rotation
+// triggers passed as options replace that default.
+func NewAging(capacity uint64, fpRate float64, opts ...AgingOption) (*AgingBloom, error) {
+	current, err := New(capacity, fpRate)
+	if err != nil {
+		return nil, err
+	}
+	previous, _ := New(capacity, fpRate)
+	return newAging(current, previous, opts), nil
+}
+
+// NewAgingFrom resumes an AgingBloom from a decoded filter, typically a
+// Snapshot: it becomes the current generation and the previous one starts
+// empty with the same shape. Without rotation options it rotates after
+// b.Capacity() Adds, which fails with ErrInvalidData if b did not record it.
+func NewAgingFrom(b *Bloom, opts ...AgingOption) (*AgingBloom, error) {
+	previous := &Bloom{
+		bitset:   make([]uint64, len(b.bitset)),
+		k:        b.k,
+		m:        b.m,
+		capacity: b.capacity,
+		fpRate:   b.fpRate,
+	}
+	a := newAging(b, previous, opts)
+	if a.config.every == 0 && a.config.after == 0 {
+		return nil, fmt.Errorf("%w: capacity unknown, pass a rotation option", ErrInvalidData)
+	}
+	return a, nil
+}
+
+func newAging(current, previous *Bloom, opts []AgingOption) *AgingBloom {
+	var cfg agingConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.every == 0 && cfg.after == 0 {
+		cfg.after = current.capacity
+	}
+
+	a := &AgingBloom{
+		current:  current,
+		previous: previous,
+		config:   cfg,
+		now:      time.Now,
+	}
+	if cfg.every > 0 {
+		a.deadline = a.now().Add(cfg.every)
+	}
+	return a
+}
+
+// Add inserts a hash into the current generation.
+func (a *AgingBloom) Add(hash uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.maybeRotate()
+	a.current.Add(hash)
+	a.inserts++
+}
+
+// AddIfNotHas inserts a hash unless either generation already holds it.
+// Returns true if it was (probably) present. An element seen only in the
+// previous generation is refreshed into the current one.
+func (a *AgingBloom) AddIfNotHas(hash uint64) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.maybeRotate()
+	if a.current.Has(hash) {
+		return true
+	}
+	a.current.Add(hash)
+	a.inserts++
+	return a.previous.Has(hash)
+}
+
+// Has reports whether hash is (probably) in either generation.
+func (a *AgingBloom) Has(hash uint64) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.maybeRotate()
+	return a.current.Has(hash) || a.previous.Has(hash)
+}
+
+// Snapshot returns a copy of both generations merged into one filter, which
+// answers Has like a and can be persisted and passed to NewAgingFrom.
+func (a *AgingBloom) Snapshot() *Bloom {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	snap := *a.current
+	snap.bitset = make([]uint64, len(a.current.bitset))
+	for i := range snap.bitset {
+		snap.bitset[i] = a.current.bitset[i] | a.previous.bitset[i]
+	}
+	return &snap
+}
+
+// Rotate discards the previous generation and starts a new current one.
+func (a *AgingBloom) Rotate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rotate()
+}
+
+// Clear empties both generations and restarts the rotation period.
+func (a *AgingBloom) Clear() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.current.Clear()
+	a.previous.Clear()
+	a.inserts = 0
+	if a.config.every > 0 {
+		a.deadline = a.now().Add(a.config.every)
+	}
+}
+
+// maybeRotate rotates when a trigger has fired. Caller holds a.mu.
+func (a *AgingBloom) maybeRotate() {
+	if a.config.after > 0 && a.inserts >= a.config.after {
+		a.rotate()
+		return
+	}
+	if a.config.every == 0 {
+		return
+	}
+	if now := a.now(); !now.Before(a.deadline) {
+		// Idle for more than a whole period: the current generation is
+		// stale too and must not survive as previous.
+		stale := !now.Before(a.deadline.Add(a.config.every))
+		a.rotate()
+		if stale {
+			a.previous.Clear()
+		}
+	}
+}
+
+// rotate reuses the previous generation's memory for the new current one.
+// Caller holds a.mu.
+func (a *AgingBloom) rotate() {
+	a.previous, a.current = a.current, a.previous
+	a.current.Clear()
+	a.inserts = 0
+	if a.config.every > 0 {
+		a.deadline = a.now().Add(a.config.every)
+	}
+}
@@ -3,8 +3,6 @@ package bloom
 import (
 	"errors"
 	"math"
-
-	"github.com/huynhanx03/go-common/pkg/encoding/json"
 )
 
 const (
@@ -19,6 +17,9 @@ type Bloom struct {
 	bitset []uint64
 	k      uint64 // Number of hash functions
 	m      uint64 // Size of bitset in bits
+
+	capacity uint64  // as passed to New, 0 if unknown
+	fpRate   float64 // as passed to New, 0 if unknown
 }
 
 // New creates a new Bloom filter.
@@ -41,9 +42,11 @@ func New(capacity uint64, fpRate float64) (*Bloom, error) {
 	k := uint64(math.Ceil(kFloat))
 
 	return &Bloom{
-		bitset: make([]uint64, (m+63)/64),
-		k:      k,
-		m:      m,
+		bitset:   make([]uint64, (m+63)/64),
+		k:        k,
+		m:        m,
+		capacity: capacity,
+		fpRate:   fpRate,
 	}, nil
 }
 
@@ -96,34 +99,6 @@ func (b *Bloom) Clear() {
 	}
 }
 
-// bloomJSON is a helper for JSON marshaling.
-type bloomJSON struct {
-	Bitset []uint64 `json:"bitset"`
-	K      uint64   `json:"k"`
-	M      uint64   `json:"m"`
-}
-
-// MarshalJSON implements json.Marshaler.
-func (b *Bloom) MarshalJSON() ([]byte, error) {
-	return json.Marshal(bloomJSON{
-		Bitset: b.bitset,
-		K:      b.k,
-		M:      b.m,
-	})
-}
-
-// UnmarshalJSON implements json.Unmarshaler.
-func (b *Bloom) UnmarshalJSON(data []byte) error {
-	var temp bloomJSON
-	if err := json.Unmarshal(data, &temp); err != nil {
-		return err
-	}
-	b.bitset = temp.Bitset
-	b.k = temp.K
-	b.m = temp.M
-	return nil
-}
-
 // TotalSize returns the total size of the bloom filter in bits.
 func (b *Bloom) TotalSize() uint64 {
 	return b.m
@@ -133,3 +108,15 @@ func (b *Bloom) TotalSize() uint64 {
 func (b *Bloom) K() uint64 {
 	return b.k
 }
+
+// Capacity returns the capacity the filter was created for, 0 if it was
+// decoded from a format that did not record it.
+func (b *Bloom) Capacity() uint64 {
+	return b.capacity
+}
+
+// FPRate returns the false positive rate the filter was created for, 0 if
+// it was decoded from a format that did not record it.
+func (b *Bloom) FPRate() float64 {
+	return b.fpRate
+}
@@ -1,9 +1,17 @@
 package bloom
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"math"
+	"runtime"
+	"slices"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/hyperloglog"
 )
 
 // Interface Compliance (compile-time check)
@@ -336,6 +344,183 @@ func TestUnmarshalJSON(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Serialization Tests
+// =============================================================================
+
+func TestJSON_PreservesConfig(t *testing.T) {
+	bf, _ := New(1000, 0.01)
+	for i := range uint64(100) {
+		bf.Add(i * 7919)
+	}
+	data, err := json.Marshal(bf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Bloom
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.Capacity() != 1000 || got.FPRate() != 0.01 {
+		t.Errorf("config = %d, %v, want 1000, 0.01", got.Capacity(), got.FPRate())
+	}
+	for i := range uint64(100) {
+		if !got.Has(i * 7919) {
+			t.Fatalf("Has(%d) = false after roundtrip", i*7919)
+		}
+	}
+}
+
+func TestJSON_LegacyFormat(t *testing.T) {
+	bf := &Bloom{}
+	if err := bf.UnmarshalJSON([]byte(`{"bitset":[5,0],"k":2,"m":100}`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if bf.K() != 2 || bf.TotalSize() != 100 || bf.Capacity() != 0 {
+		t.Errorf("got k=%d m=%d capacity=%d", bf.K(), bf.TotalSize(), bf.Capacity())
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	tests := []string{
+		`{"k":2,"m":100}`,                         // no bitset
+		`{"bitset":"AAAA","k":2,"m":100}`,         // 3 bytes, not whole words
+		`{"bitset":[1],"k":2,"m":1000}`,           // too few words
+		`{"bitset":[1,2],"k":0,"m":100}`,          // no hash functions
+		`{"bitset":"!!","k":2,"m":64}`,            // not base64
+		`{"bitset":[1],"k":2,"m":64,"fp_rate":2}`, // impossible rate
+	}
+	for _, in := range tests {
+		if err := (&Bloom{}).UnmarshalJSON([]byte(in)); err == nil {
+			t.Errorf("UnmarshalJSON(%s) = nil, want error", in)
+		}
+	}
+}
+
+func TestBinary_Roundtrip(t *testing.T) {
+	bf, _ := New(5000, 0.001) // bitset larger than one WriteTo chunk
+	for i := range uint64(500) {
+		bf.Add(i)
+	}
+
+	data, err := bf.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromBytes Bloom
+	if err := fromBytes.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+
+	var stream bytes.Buffer
+	n, err := bf.WriteTo(&stream)
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("WriteTo = %d, %v, want %d", n, err, len(data))
+	}
+	if !bytes.Equal(stream.Bytes(), data) {
+		t.Fatal("WriteTo and MarshalBinary disagree")
+	}
+	stream.WriteString("trailing")
+	var fromStream Bloom
+	if _, err := fromStream.ReadFrom(&stream); err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if stream.String() != "trailing" {
+		t.Errorf("ReadFrom consumed past the filter, left %q", stream.String())
+	}
+
+	for _, got := range []*Bloom{&fromBytes, &fromStream} {
+		if got.Capacity() != 5000 || got.FPRate() != 0.001 || got.K() != bf.K() {
+			t.Errorf("config = %d, %v, k=%d", got.Capacity(), got.FPRate(), got.K())
+		}
+		for i := range uint64(500) {
+			if !got.Has(i) {
+				t.Fatalf("Has(%d) = false after roundtrip", i)
+			}
+		}
+	}
+
+	if err := (&Bloom{}).UnmarshalBinary(data[:10]); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("truncated header err = %v, want ErrInvalidData", err)
+	}
+	if _, err := (&Bloom{}).ReadFrom(bytes.NewReader(data[:len(data)-1])); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("truncated stream err = %v, want ErrInvalidData", err)
+	}
+}
+
+func TestBinary_RejectsHostileHeader(t *testing.T) {
+	header := func(k, m uint64) []byte {
+		b := &Bloom{k: k, m: m, capacity: 1, fpRate: 0.01}
+		return b.appendHeader(nil)
+	}
+	tests := []struct {
+		name string
+		k, m uint64
+	}{
+		{"zero_k", 0, 64},
+		{"huge_k", maxHashes + 1, 64},
+		{"zero_m", 3, 0},
+		{"huge_m", 3, maxBits + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append(header(tt.k, tt.m), make([]byte, 8)...)
+			if err := (&Bloom{}).UnmarshalBinary(data); !errors.Is(err, ErrInvalidData) {
+				t.Errorf("UnmarshalBinary err = %v, want ErrInvalidData", err)
+			}
+			if _, err := (&Bloom{}).ReadFrom(bytes.NewReader(data)); !errors.Is(err, ErrInvalidData) {
+				t.Errorf("ReadFrom err = %v, want ErrInvalidData", err)
+			}
+		})
+	}
+
+	// m within the limits, but the payload carries one word of the 2^29
+	// announced: both reject it without allocating the 4 GiB bitset.
+	data := append(header(3, maxBits), make([]byte, 8)...)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := (&Bloom{}).UnmarshalBinary(data); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("UnmarshalBinary of a short payload err = %v, want ErrInvalidData", err)
+	}
+	if _, err := (&Bloom{}).ReadFrom(bytes.NewReader(data)); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("ReadFrom of a short stream err = %v, want ErrInvalidData", err)
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 4<<20 {
+		t.Errorf("decoding a short payload allocated %d bytes", n)
+	}
+}
+
+// =============================================================================
+// Merge Tests
+// =============================================================================
+
+func TestMerge(t *testing.T) {
+	a, _ := New(1000, 0.01)
+	b, _ := New(1000, 0.01)
+	a.Add(1)
+	b.Add(2)
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Has(1) || !a.Has(2) {
+		t.Error("merged filter lost an element")
+	}
+
+	other, _ := New(2000, 0.01)
+	if err := a.Merge(other); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("Merge of different sizes err = %v, want ErrIncompatible", err)
+	}
+
+	// A filter of the same shape with unknown config still merges.
+	legacy := &Bloom{bitset: make([]uint64, len(a.bitset)), k: a.k, m: a.m}
+	if err := a.Merge(legacy); err != nil {
+		t.Errorf("Merge with legacy filter: %v", err)
+	}
+}
+
 // =============================================================================
 // TotalSize Tests
 // =============================================================================
@@ -400,3 +585,256 @@ func TestWorkflow_AddHasClearHas(t *testing.T) {
 		t.Error("Has() should return false after Clear()")
 	}
 }
+
+// =============================================================================
+// AgingBloom Tests
+// =============================================================================
+
+func TestAging_RotateAfterInserts(t *testing.T) {
+	a, err := NewAging(1000, 0.01, WithRotateAfter(2))
+	if err != nil {
+		t.Fatalf("NewAging: %v", err)
+	}
+
+	a.Add(1)
+	a.Add(2)
+	a.Add(3) // rotates: {1,2} become previous
+	if !a.Has(1) || !a.Has(3) {
+		t.Fatal("elements lost after one rotation")
+	}
+
+	a.Add(4)
+	a.Add(5) // rotates again: {1,2} dropped
+	if a.Has(1) || a.Has(2) {
+		t.Error("elements survived two rotations")
+	}
+	if !a.Has(3) || !a.Has(5) {
+		t.Error("recent elements missing")
+	}
+}
+
+func TestAging_RotateEvery(t *testing.T) {
+	a, err := NewAging(1000, 0.01, WithRotateEvery(time.Minute))
+	if err != nil {
+		t.Fatalf("NewAging: %v", err)
+	}
+	now := time.Unix(0, 0)
+	a.now = func() time.Time { return now }
+	a.Clear() // restart the period on the fake clock
+
+	a.Add(1)
+	now = now.Add(time.Minute)
+	a.Add(2)
+	if !a.Has(1) || !a.Has(2) {
+		t.Fatal("elements lost after one period")
+	}
+
+	now = now.Add(time.Minute)
+	if a.Has(1) {
+		t.Error("element survived two periods")
+	}
+	if !a.Has(2) {
+		t.Error("element from the last period missing")
+	}
+
+	// Idle for several periods forgets everything.
+	now = now.Add(10 * time.Minute)
+	if a.Has(2) {
+		t.Error("element survived a long idle gap")
+	}
+}
+
+func TestAging_AddIfNotHas(t *testing.T) {
+	a, _ := NewAging(1000, 0.01, WithRotateAfter(1))
+
+	if a.AddIfNotHas(7) {
+		t.Fatal("first AddIfNotHas reported present")
+	}
+	a.Rotate()
+	// Seen in the previous generation: present, and refreshed into current.
+	if !a.AddIfNotHas(7) {
+		t.Fatal("AddIfNotHas missed previous generation")
+	}
+	a.Rotate()
+	if !a.Has(7) {
+		t.Error("refreshed element lost after rotation")
+	}
+}
+
+func TestAging_InvalidParams(t *testing.T) {
+	if _, err := NewAging(0, 0.01); err == nil {
+		t.Error("NewAging(0, ...) returned nil error")
+	}
+}
+
+func TestAging_SnapshotAndResume(t *testing.T) {
+	a, _ := NewAging(100, 0.01)
+	a.Add(1)
+	a.Rotate()
+	a.Add(2)
+
+	data, err := a.Snapshot().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap Bloom
+	if err := snap.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := NewAgingFrom(&snap)
+	if err != nil {
+		t.Fatalf("NewAgingFrom: %v", err)
+	}
+	if !resumed.Has(1) || !resumed.Has(2) {
+		t.Error("resumed filter lost an element")
+	}
+	resumed.Rotate()
+	resumed.Rotate()
+	if resumed.Has(1) {
+		t.Error("element survived two rotations")
+	}
+
+	legacy := &Bloom{}
+	_ = legacy.UnmarshalJSON([]byte(`{"bitset":[0],"k":2,"m":64}`))
+	if _, err := NewAgingFrom(legacy); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("NewAgingFrom without capacity err = %v, want ErrInvalidData", err)
+	}
+	if _, err := NewAgingFrom(legacy, WithRotateAfter(10)); err != nil {
+		t.Errorf("NewAgingFrom with rotation option: %v", err)
+	}
+}
+
+// =============================================================================
+// Filter Tests
+// =============================================================================
+
+func TestFilter(t *testing.T) {
+	if _, err := NewFilter[string](0, 0.01); err == nil {
+		t.Error("NewFilter with zero capacity should fail")
+	}
+
+	f, err := NewFilter[string](1000, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.AddIfNotHas("alice") {
+		t.Error("AddIfNotHas reported a new key present")
+	}
+	if !f.AddIfNotHas("alice") || !f.Has("alice") {
+		t.Error("added key not found")
+	}
+	f.Add("bob")
+	if !f.Has("bob") {
+		t.Error("Add did not insert")
+	}
+	if f.Hash("alice") != Wrap[string](nil).Hash("alice") {
+		t.Error("filters disagree on the hash of a key")
+	}
+	if !f.Set().Has(f.Hash("alice")) {
+		t.Error("Set does not hold the key's hash")
+	}
+}
+
+func TestFilter_SequentialInts(t *testing.T) {
+	f, _ := NewFilter[int](10_000, 0.01)
+	for i := range 10_000 {
+		f.Add(i)
+	}
+	fp := 0
+	for i := 10_000; i < 20_000; i++ {
+		if f.Has(i) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / 10_000; rate > 0.02 {
+		t.Errorf("false positive rate on sequential IDs = %.3f, want ~0.01", rate)
+	}
+}
+
+func TestFilter_WrapAging(t *testing.T) {
+	a, _ := NewAging(100, 0.01)
+	f := Wrap[string](a)
+	for i := range 50 {
+		f.Add("k" + strconv.Itoa(i))
+	}
+	a.Rotate()
+	a.Rotate()
+	if f.Has("k1") {
+		t.Error("key survived two rotations of the wrapped filter")
+	}
+}
+
+// =============================================================================
+// BuildFrom Tests
+// =============================================================================
+
+// hashes yields the hashes of n string keys and counts its passes.
+func hashes(n int, passes *int) func(yield func(uint64) bool) {
+	f := Wrap[string](nil)
+	return func(yield func(uint64) bool) {
+		*passes++
+		for i := range n {
+			if !yield(f.Hash("key" + strconv.Itoa(i))) {
+				return
+			}
+		}
+	}
+}
+
+func TestBuildFrom_CountsKeys(t *testing.T) {
+	passes := 0
+	var reports []uint64
+	b, err := BuildFrom(hashes(100_000, &passes), 0.01, WithProgress(30_000, func(added uint64) {
+		reports = append(reports, added)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if passes != 2 {
+		t.Errorf("keys iterated %d times, want 2", passes)
+	}
+	if c := b.Capacity(); c < 100_000 || c > 110_000 {
+		t.Errorf("Capacity = %d, want about 100000 plus headroom", c)
+	}
+	hashes(100_000, &passes)(func(h uint64) bool {
+		if !b.Has(h) {
+			t.Fatal("built filter is missing a key")
+		}
+		return true
+	})
+	if want := []uint64{30_000, 60_000, 90_000, 100_000}; !slices.Equal(reports, want) {
+		t.Errorf("progress = %v, want %v", reports, want)
+	}
+}
+
+func TestBuildFrom_Sized(t *testing.T) {
+	passes := 0
+	b, err := BuildFrom(hashes(1000, &passes), 0.01, WithEstimate(5000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes != 1 || b.Capacity() != 5000 {
+		t.Errorf("WithEstimate: %d passes, capacity %d; want 1, 5000", passes, b.Capacity())
+	}
+
+	h := hyperloglog.New()
+	hashes(1000, &passes)(func(x uint64) bool { h.Add(x); return true })
+	passes = 0
+	b, err = BuildFrom(hashes(1000, &passes), 0.01, WithCardinality(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes != 1 || b.Capacity() < 1000 || b.Capacity() > 1100 {
+		t.Errorf("WithCardinality: %d passes, capacity %d; want 1, about 1000", passes, b.Capacity())
+	}
+
+	if _, err := BuildFrom(hashes(10, &passes), 1.5); err == nil {
+		t.Error("BuildFrom with an invalid fpRate should fail")
+	}
+	calls := 0
+	if _, err := BuildFrom(hashes(0, &passes), 0.01, WithProgress(0, func(uint64) { calls++ })); err != nil || calls != 1 {
+		t.Errorf("empty build: err %v, %d progress calls; want nil, 1", err, calls)
+	}
+}
@@ -0,0 +1,91 @@
+package bloom
+
+import (
+	"iter"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/hyperloglog"
+)
+
+// defaultProgressEvery is how many inserts apart BuildFrom reports progress
+// when WithProgress gives no interval.
+const defaultProgressEvery = 1 << 16
+
+// buildConfig holds the options of BuildFrom.
+type buildConfig struct {
+	estimate uint64
+	hll      *hyperloglog.HLL
+	every    uint64
+	progress func(added uint64)
+}
+
+// BuildOption configures BuildFrom.
+type BuildOption func(*buildConfig)
+
+// WithEstimate sizes the filter for n keys, skipping the counting pass,
+// e.g. with the key count of the btree or kvstore being exported.
+func WithEstimate(n uint64) BuildOption {
+	return func(c *buildConfig) { c.estimate = n }
+}
+
+// WithCardinality sizes the filter from a HyperLogLog already fed the
+// same keys, skipping the counting pass.
+func WithCardinality(h *hyperloglog.HLL) BuildOption {
+	return func(c *buildConfig) { c.hll = h }
+}
+
+// WithProgress calls fn with the number of keys inserted so far every
+// every inserts, 65536 if every is 0, and once more when the build ends.
+func WithProgress(every uint64, fn func(added uint64)) BuildOption {
+	return func(c *buildConfig) {
+		if every > 0 {
+			c.every = every
+		}
+		c.progress = fn
+	}
+}
+
+// capacity returns the number of keys to size the filter for. Without an
+// estimate it counts the distinct keys with a HyperLogLog, and pads a
+// HyperLogLog count by 1/32 to cover its error.
+func (c *buildConfig) capacity(keys iter.Seq[uint64]) uint64 {
+	if c.estimate > 0 {
+		return c.estimate
+	}
+	h := c.hll
+	if h == nil {
+		h = hyperloglog.New()
+		for key := range keys {
+			h.Add(key)
+		}
+	}
+	n := uint64(max(h.Count(), 1))
+	return n + n/32
+}
+
+// BuildFrom creates a filter holding every hash keys yields, at fpRate,
+// for building filters over millions of keys exported from a store. It is
+// sized from WithEstimate or WithCardinality when given; otherwise keys is
+// iterated twice, once to count the distinct keys and once to insert them,
+// and must yield the same keys both times.
+func BuildFrom(keys iter.Seq[uint64], fpRate float64, opts ...BuildOption) (*Bloom, error) {
+	cfg := buildConfig{every: defaultProgressEvery}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	b, err := New(cfg.capacity(keys), fpRate)
+	if err != nil {
+		return nil, err
+	}
+	var added uint64
+	for key := range keys {
+		b.Add(key)
+		if added++; cfg.progress != nil && added%cfg.every == 0 {
+			cfg.progress(added)
+		}
+	}
+	if cfg.progress != nil && (added == 0 || added%cfg.every != 0) {
+		cfg.progress(added)
+	}
+	return b, nil
+}
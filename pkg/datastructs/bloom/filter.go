@@ -0,0 +1,87 @@
+package bloom
+
+import "github.com/huynhanx03/go-common/pkg/hash"
+
+// Set is the hash-level filter a Filter wraps. *Bloom and *AgingBloom
+// implement it.
+type Set interface {
+	Add(hash uint64)
+	Has(hash uint64) bool
+	AddIfNotHas(hash uint64) bool
+}
+
+var (
+	_ Set = (*Bloom)(nil)
+	_ Set = (*AgingBloom)(nil)
+)
+
+// Filter is a Bloom filter of typed keys. It hashes keys itself, so call
+// sites pass the key rather than each choosing a hash, and every filter
+// over the same key type agrees on the hash of a key.
+//
+// Strings, byte slices and other key types are hashed with xxhash, which is
+// stable across processes, so a filter can be persisted through its Set and
+// reloaded elsewhere. Integer keys, which the hash package returns as is,
+// are mixed first so sequential IDs spread over the bitset.
+type Filter[K comparable] struct {
+	set Set
+}
+
+// NewFilter returns a filter over a new Bloom sized as New sizes it.
+func NewFilter[K comparable](capacity uint64, fpRate float64) (*Filter[K], error) {
+	b, err := New(capacity, fpRate)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter[K]{set: b}, nil
+}
+
+// Wrap returns a filter over set, e.g. an AgingBloom for sliding-window
+// dedup or a Bloom decoded from storage. The filter is safe for concurrent
+// use when set is.
+func Wrap[K comparable](set Set) *Filter[K] {
+	return &Filter[K]{set: set}
+}
+
+// Hash returns the hash Filter stores for key. Passed as the key function
+// of a batcher.Deduper, with f.Set() as its seen set, it makes the deduper
+// agree with f:
+//
+//	batcher.NewDeduper(cons, func(m Msg) uint64 { return seen.Hash(m.ID) }, seen.Set())
+func (f *Filter[K]) Hash(key K) uint64 {
+	h1, h2 := hash.KeyToHash(key)
+	if h2 != 0 {
+		return h2
+	}
+	// splitmix64 finalizer
+	h1 = (h1 ^ (h1 >> 30)) * 0xbf58476d1ce4e5b9
+	h1 = (h1 ^ (h1 >> 27)) * 0x94d049bb133111eb
+	return h1 ^ (h1 >> 31)
+}
+
+// Add inserts key.
+func (f *Filter[K]) Add(key K) {
+	f.set.Add(f.Hash(key))
+}
+
+// Has reports whether key is (probably) in the filter.
+func (f *Filter[K]) Has(key K) bool {
+	return f.set.Has(f.Hash(key))
+}
+
+// AddIfNotHas inserts key and reports whether it was (probably) present
+// already. As a doorkeeper in front of a cache it admits a key on its
+// second sighting:
+//
+//	if door.AddIfNotHas(key) {
+//		c.Set(key, value)
+//	}
+func (f *Filter[K]) AddIfNotHas(key K) bool {
+	return f.set.AddIfNotHas(f.Hash(key))
+}
+
+// Set returns the underlying hash-level filter, for serializing, merging or
+// clearing it.
+func (f *Filter[K]) Set() Set {
+	return f.set
+}
@@ -0,0 +1,267 @@
+package bloom
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/huynhanx03/go-common/pkg/encoding/json"
+)
+
+var (
+	// ErrInvalidData is returned when decoding a malformed filter.
+	ErrInvalidData = errors.New("bloom: invalid serialized filter")
+
+	// ErrIncompatible is returned by Merge for filters of different shapes.
+	ErrIncompatible = errors.New("bloom: incompatible filters")
+)
+
+// binaryMagic starts the binary encoding; the last byte is the version.
+var binaryMagic = [4]byte{'B', 'L', 'M', 1}
+
+// binaryHeaderSize is the magic plus capacity, fpRate, k and m.
+const binaryHeaderSize = 4 + 4*8
+
+// Decoding rejects filters beyond these limits, so that a corrupt or
+// hostile header can neither claim a huge allocation nor make every lookup
+// hash maxHashes times over. New only reaches them for fpRates below 1e-38
+// or bitsets above 4 GiB.
+const (
+	maxHashes = 128
+	maxBits   = 1 << 35
+)
+
+// readAheadWords caps the bitset ReadFrom allocates before the words
+// arrive; it grows with the data actually read.
+const readAheadWords = 1 << 16
+
+// encodeChunkWords is how many words MarshalJSON base64-encodes at a time.
+// 48 bytes is a multiple of 3, so chunks concatenate without padding.
+const encodeChunkWords = 6
+
+// bloomJSON is a helper for JSON unmarshaling. Bitset is a base64 string
+// of little-endian words, or an array of words in the older format.
+type bloomJSON struct {
+	Capacity uint64          `json:"capacity"`
+	FPRate   float64         `json:"fp_rate"`
+	K        uint64          `json:"k"`
+	M        uint64          `json:"m"`
+	Bitset   json.RawMessage `json:"bitset"`
+}
+
+// MarshalJSON implements json.Marshaler. The bitset is written as base64
+// straight into the output, without an intermediate copy.
+func (b *Bloom) MarshalJSON() ([]byte, error) {
+	enc := base64.StdEncoding
+	out := make([]byte, 0, 96+enc.EncodedLen(len(b.bitset)*8))
+
+	out = append(out, `{"capacity":`...)
+	out = strconv.AppendUint(out, b.capacity, 10)
+	out = append(out, `,"fp_rate":`...)
+	out = strconv.AppendFloat(out, b.fpRate, 'g', -1, 64)
+	out = append(out, `,"k":`...)
+	out = strconv.AppendUint(out, b.k, 10)
+	out = append(out, `,"m":`...)
+	out = strconv.AppendUint(out, b.m, 10)
+	out = append(out, `,"bitset":"`...)
+
+	var chunk [encodeChunkWords * 8]byte
+	for words := b.bitset; len(words) > 0; {
+		n := min(len(words), encodeChunkWords)
+		for i, w := range words[:n] {
+			binary.LittleEndian.PutUint64(chunk[i*8:], w)
+		}
+		out = enc.AppendEncode(out, chunk[:n*8])
+		words = words[n:]
+	}
+	return append(out, `"}`...), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It also reads the older
+// format with the bitset as an array of words, leaving Capacity and FPRate
+// unknown.
+func (b *Bloom) UnmarshalJSON(data []byte) error {
+	var temp bloomJSON
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	var bitset []uint64
+	switch raw := temp.Bitset; {
+	case len(raw) > 0 && raw[0] == '"':
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return err
+		}
+		buf, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(buf)%8 != 0 {
+			return fmt.Errorf("%w: bad bitset encoding", ErrInvalidData)
+		}
+		bitset = make([]uint64, len(buf)/8)
+		for i := range bitset {
+			bitset[i] = binary.LittleEndian.Uint64(buf[i*8:])
+		}
+	case len(raw) > 0 && raw[0] == '[':
+		if err := json.Unmarshal(raw, &bitset); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: missing bitset", ErrInvalidData)
+	}
+
+	return b.restore(temp.Capacity, temp.FPRate, temp.K, temp.M, bitset)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. See WriteTo.
+func (b *Bloom) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, binaryHeaderSize+len(b.bitset)*8)
+	out = b.appendHeader(out)
+	for _, w := range b.bitset {
+		out = binary.LittleEndian.AppendUint64(out, w)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (b *Bloom) UnmarshalBinary(data []byte) error {
+	capacity, fpRate, k, m, err := parseHeader(data)
+	if err != nil {
+		return err
+	}
+	if err := checkShape(k, m); err != nil {
+		return err
+	}
+	data = data[binaryHeaderSize:]
+	if uint64(len(data)) != (m+63)/64*8 {
+		return fmt.Errorf("%w: m=%d with %d bitset bytes", ErrInvalidData, m, len(data))
+	}
+
+	bitset := make([]uint64, len(data)/8)
+	for i := range bitset {
+		bitset[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return b.restore(capacity, fpRate, k, m, bitset)
+}
+
+// WriteTo implements io.WriterTo with the binary encoding: a 4-byte magic
+// and version, capacity, fpRate, k and m as little-endian 64-bit values,
+// then the bitset words. It streams the bitset in small chunks.
+func (b *Bloom) WriteTo(w io.Writer) (int64, error) {
+	var chunk [512]byte
+	buf := b.appendHeader(chunk[:0])
+	var total int64
+	for words := b.bitset; ; {
+		for len(words) > 0 && len(buf)+8 <= len(chunk) {
+			buf = binary.LittleEndian.AppendUint64(buf, words[0])
+			words = words[1:]
+		}
+		n, err := w.Write(buf)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if len(words) == 0 {
+			return total, nil
+		}
+		buf = chunk[:0]
+	}
+}
+
+// ReadFrom implements io.ReaderFrom, reading one filter written by WriteTo
+// and nothing past it.
+func (b *Bloom) ReadFrom(r io.Reader) (int64, error) {
+	var header [binaryHeaderSize]byte
+	n, err := io.ReadFull(r, header[:])
+	total := int64(n)
+	if err != nil {
+		return total, fmt.Errorf("%w: %w", ErrInvalidData, err)
+	}
+	capacity, fpRate, k, m, err := parseHeader(header[:])
+	if err != nil {
+		return total, err
+	}
+	if err := checkShape(k, m); err != nil {
+		return total, err
+	}
+
+	// Grow the bitset as words arrive instead of trusting m up front: a
+	// truncated stream fails after allocating about what it carried.
+	want := int((m + 63) / 64)
+	bitset := make([]uint64, 0, min(want, readAheadWords))
+	var chunk [512]byte
+	for len(bitset) < want {
+		words := min(want-len(bitset), len(chunk)/8)
+		n, err := io.ReadFull(r, chunk[:words*8])
+		total += int64(n)
+		if err != nil {
+			return total, fmt.Errorf("%w: %w", ErrInvalidData, err)
+		}
+		for j := range words {
+			bitset = append(bitset, binary.LittleEndian.Uint64(chunk[j*8:]))
+		}
+	}
+	return total, b.restore(capacity, fpRate, k, m, bitset)
+}
+
+func (b *Bloom) appendHeader(out []byte) []byte {
+	out = append(out, binaryMagic[:]...)
+	out = binary.LittleEndian.AppendUint64(out, b.capacity)
+	out = binary.LittleEndian.AppendUint64(out, math.Float64bits(b.fpRate))
+	out = binary.LittleEndian.AppendUint64(out, b.k)
+	return binary.LittleEndian.AppendUint64(out, b.m)
+}
+
+func parseHeader(data []byte) (capacity uint64, fpRate float64, k, m uint64, err error) {
+	if len(data) < binaryHeaderSize || [4]byte(data[:4]) != binaryMagic {
+		return 0, 0, 0, 0, fmt.Errorf("%w: bad header", ErrInvalidData)
+	}
+	le := binary.LittleEndian
+	return le.Uint64(data[4:]), math.Float64frombits(le.Uint64(data[12:])),
+		le.Uint64(data[20:]), le.Uint64(data[28:]), nil
+}
+
+// checkShape rejects a decoded k or m that is zero or beyond the limits.
+func checkShape(k, m uint64) error {
+	if k == 0 || k > maxHashes || m == 0 || m > maxBits {
+		return fmt.Errorf("%w: k=%d m=%d", ErrInvalidData, k, m)
+	}
+	return nil
+}
+
+// restore validates decoded fields and installs them.
+func (b *Bloom) restore(capacity uint64, fpRate float64, k, m uint64, bitset []uint64) error {
+	if err := checkShape(k, m); err != nil {
+		return err
+	}
+	if uint64(len(bitset)) != (m+63)/64 {
+		return fmt.Errorf("%w: m=%d with %d words", ErrInvalidData, m, len(bitset))
+	}
+	if fpRate < 0 || fpRate >= 1 || math.IsNaN(fpRate) {
+		return fmt.Errorf("%w: fpRate %v", ErrInvalidData, fpRate)
+	}
+	b.bitset, b.k, b.m = bitset, k, m
+	b.capacity, b.fpRate = capacity, fpRate
+	return nil
+}
+
+// Merge ORs other into b, so b holds the elements of both. The filters must
+// have the same size and hash count, and the same capacity and fpRate when
+// both are known.
+func (b *Bloom) Merge(other *Bloom) error {
+	if b.m != other.m || b.k != other.k {
+		return fmt.Errorf("%w: m=%d k=%d vs m=%d k=%d", ErrIncompatible, b.m, b.k, other.m, other.k)
+	}
+	if b.capacity != 0 && other.capacity != 0 &&
+		(b.capacity != other.capacity || b.fpRate != other.fpRate) {
+		return fmt.Errorf("%w: capacity %d at %v vs %d at %v",
+			ErrIncompatible, b.capacity, b.fpRate, other.capacity, other.fpRate)
+	}
+	for i, w := range other.bitset {
+		b.bitset[i] |= w
+	}
+	return nil
+}
@@ -14,30 +14,45 @@ type Tree struct {
 	nextPage uint64
 	freePage uint64
 	stats    TreeStats
+
+	// secondaryBits is the width of the secondary part of composite keys;
+	// 0 outside composite mode. See NewCompositeTree.
+	secondaryBits uint
+
+	// expiry maps keys to their expiry timestamp; nil outside TTL mode.
+	// See NewTTLTree.
+	expiry *Tree
+
+	// shared tracks the pages still read from the arena frozen by the last
+	// Checkpoint; nil once every page has been copied out of it.
+	shared *cow
 }
 
 func (t *Tree) initRootNode() {
 	t.newNode(0)
-	t.Set(absoluteMax, 0)
+	t.setValue(absoluteMax, 0)
 }
 
 // NewTree returns an in-memory B+ tree.
 func NewTree() *Tree {
-	// Use pool for large 1MB buffer allocation
-	buf := bufferpool.GetSize(minSize)
+	t := &Tree{buffer: newBuffer(minSize)}
+	t.Reset()
+	return t
+}
 
-	// Set callback to return to pool on Release
+// newBuffer takes an arena of at least size bytes from the pool. Release
+// returns it.
+func newBuffer(size int) *buffer.Buffer {
+	buf := bufferpool.GetSize(size)
 	buf.ReleaseFn = func() {
 		bufferpool.Put(buf)
 	}
-
-	t := &Tree{buffer: buf}
-	t.Reset()
-	return t
+	return buf
 }
 
 // Reset resets the tree and truncates it to minSize. It ensures the root node is re-initialized.
 func (t *Tree) Reset() {
+	t.unshare()
 	t.buffer.Reset()
 	t.buffer.AllocateOffset(minSize)
 	t.data = t.buffer.Bytes()
@@ -45,6 +60,9 @@ func (t *Tree) Reset() {
 	t.nextPage = 1
 	t.freePage = 0
 	t.initRootNode()
+	if t.expiry != nil {
+		t.expiry.Reset()
+	}
 }
 
 // Close releases the memory used by the tree.
@@ -52,6 +70,10 @@ func (t *Tree) Close() error {
 	if t == nil {
 		return nil
 	}
+	if t.expiry != nil {
+		_ = t.expiry.Close()
+	}
+	t.unshare()
 	return t.buffer.Release()
 }
 
@@ -63,9 +85,24 @@ type TreeStats struct {
 	NumPagesFree int     // Calculated.
 	Occupancy    float64 // Derived.
 	PageSize     int     // Derived.
+
+	// Shape, walked from the root. Fill factors are the percentage of
+	// maxKeys in use, averaged over nodes: a tree filled by sequential
+	// inserts sits near 50, since every split leaves a half-full node
+	// behind.
+	Height       int       // Levels, root and leaves included.
+	LevelFill    []float64 // Fill factor per level, root first.
+	LeafFill     float64   // Fill factor of leaves.
+	InternalFill float64   // Fill factor of internal nodes.
+
+	// MinKey and MaxKey are the smallest and largest keys set by the user,
+	// packed as stored in composite mode; 0 when the tree is empty.
+	MinKey uint64
+	MaxKey uint64
 }
 
-// Stats returns stats about the tree.
+// Stats returns stats about the tree. It walks every node, so it costs a
+// full scan of the tree.
 func (t *Tree) Stats() TreeStats {
 	numPages := int(t.nextPage - 1)
 	out := TreeStats{
@@ -77,13 +114,68 @@ func (t *Tree) Stats() TreeStats {
 		PageSize:     pageSize,
 	}
 	out.Occupancy = 100.0 * float64(out.NumLeafKeys) / float64(maxKeys*numPages)
+	t.shape(&out)
 	return out
 }
 
+// shape fills the fields of out that depend on the layout of the nodes.
+func (t *Tree) shape(out *TreeStats) {
+	type level struct{ nodes, keys int }
+	var levels []level
+	var leaves, leafKeys, internal, internalKeys int
+
+	var walk func(n node, depth int)
+	walk = func(n node, depth int) {
+		if depth == len(levels) {
+			levels = append(levels, level{})
+		}
+		levels[depth].nodes++
+		levels[depth].keys += n.numKeys()
+
+		if !n.isLeaf() {
+			internal++
+			internalKeys += n.numKeys()
+			for i := 0; i < n.numKeys(); i++ {
+				walk(t.node(n.val(i)), depth+1)
+			}
+			return
+		}
+		leaves++
+		leafKeys += n.numKeys()
+		// Leaves are walked in key order. A zero value is a bogus entry,
+		// as for IterateKV, which also skips the sentinel key.
+		for i := 0; i < n.numKeys(); i++ {
+			if n.val(i) == 0 {
+				continue
+			}
+			if out.MinKey == 0 {
+				out.MinKey = n.key(i)
+			}
+			out.MaxKey = n.key(i)
+		}
+	}
+	walk(t.node(1), 0)
+
+	fill := func(keys, nodes int) float64 {
+		if nodes == 0 {
+			return 0
+		}
+		return 100.0 * float64(keys) / float64(maxKeys*nodes)
+	}
+	out.Height = len(levels)
+	out.LevelFill = make([]float64, len(levels))
+	for i, l := range levels {
+		out.LevelFill[i] = fill(l.keys, l.nodes)
+	}
+	out.LeafFill = fill(leafKeys, leaves)
+	out.InternalFill = fill(internalKeys, internal)
+}
+
 func (t *Tree) newNode(bit uint64) node {
 	var pid uint64
 	if t.freePage > 0 {
 		pid = t.freePage
+		t.freePage = t.node(pid).uint64(0)
 		t.stats.NumPagesFree--
 	} else {
 		pid = t.nextPage
@@ -95,10 +187,7 @@ func (t *Tree) newNode(bit uint64) node {
 			t.data = t.buffer.Bytes()
 		}
 	}
-	n := t.node(pid)
-	if t.freePage > 0 {
-		t.freePage = n.uint64(0)
-	}
+	n := t.own(pid, false)
 	zeroOut(n)
 	n.setBit(bit)
 	n.setAt(metaPidIdx, pid)
@@ -115,8 +204,17 @@ func zeroOut(data []uint64) {
 	}
 }
 
-// node returns the node at the given page ID.
+// node returns the node at the given page ID for reading; write through
+// mut or own, which keep pages shared with a Checkpoint intact.
 func (t *Tree) node(pid uint64) node {
+	if s := t.shared; s != nil && s.borrowed(pid) {
+		return s.node(pid)
+	}
+	return t.page(pid)
+}
+
+// page returns the node at the given page ID in the tree's own arena.
+func (t *Tree) page(pid uint64) node {
 	if pid == 0 {
 		return nil
 	}
@@ -124,20 +222,30 @@ func (t *Tree) node(pid uint64) node {
 	return getNode(t.data[start : start+pageSize])
 }
 
-// Set sets the key-value pair in the tree.
+// Set sets the key-value pair in the tree. In TTL mode it also clears the
+// key's expiry.
 func (t *Tree) Set(k, v uint64) {
 	if k == math.MaxUint64 || k == 0 {
 		panic("Error setting zero or MaxUint64")
 	}
+	if t.expiry != nil {
+		t.clearExpiry(k)
+	}
+	t.setValue(k, v)
+}
+
+// setValue inserts or overwrites k without touching its expiry.
+func (t *Tree) setValue(k, v uint64) {
 	root := t.set(1, k, v)
 	if root.isFull() {
 		right := t.split(1)
 		left := t.newNode(root.bits())
 		root = t.node(1)
-		copy(left[:keyOffset(maxKeys)], root)
+		copy(left[keyOffset(0):keyOffset(maxKeys)], root[keyOffset(0):keyOffset(maxKeys)])
+		copy(left[valOffset(0):valOffset(maxKeys)], root[valOffset(0):valOffset(maxKeys)])
 		left.setNumKeys(root.numKeys())
 
-		zeroOut(root)
+		zeroOut(root[keyOffset(0):])
 		root.setNumKeys(0)
 
 		root.set(left.maxKey(), left.pid())
@@ -147,7 +255,7 @@ func (t *Tree) Set(k, v uint64) {
 
 // set recursively inserts the key-value pair and returns the node itself.
 func (t *Tree) set(pid, k, v uint64) node {
-	n := t.node(pid)
+	n := t.mut(pid)
 	if n.isLeaf() {
 		t.stats.NumLeafKeys += n.set(k, v)
 		return n
@@ -251,15 +359,94 @@ func (t *Tree) IterateKV(f func(key, val uint64) (newVal uint64)) {
 
 			newVal := f(key, val)
 			if newVal != 0 {
+				n = t.mut(n.pid())
 				n.setAt(valOffset(i), newVal)
 			}
 		}
 	})
 }
 
+// IterateRange calls fn for every key in [lo, hi] in ascending order until fn
+// returns false. Subtrees entirely outside the range are skipped.
+func (t *Tree) IterateRange(lo, hi uint64, fn func(key, val uint64) bool) {
+	if lo > hi {
+		return
+	}
+	t.iterateRange(t.node(1), lo, hi, fn)
+}
+
+// iterateRange returns false once the scan is done, either because fn asked to
+// stop or because a key above hi was reached.
+func (t *Tree) iterateRange(n node, lo, hi uint64, fn func(key, val uint64) bool) bool {
+	if n.isLeaf() {
+		for i := n.search(lo); i < n.numKeys(); i++ {
+			key := n.key(i)
+			if key > hi {
+				return false
+			}
+			// A zero value here means that this is a bogus entry.
+			if val := n.val(i); val != 0 && !fn(key, val) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Keys of internal nodes are the max key of their child, so the first
+	// child that may hold lo is the one search finds.
+	for i := n.search(lo); i < n.numKeys(); i++ {
+		if child := t.node(n.val(i)); child != nil && !t.iterateRange(child, lo, hi, fn) {
+			return false
+		}
+		if n.key(i) >= hi {
+			return false
+		}
+	}
+	return true
+}
+
+// Partitions splits the key space into at most n contiguous, inclusive
+// [lo, hi] ranges holding roughly equal numbers of keys, so a large scan can
+// run one IterateRange per range on separate goroutines. Boundaries fall on
+// leaf edges, so fewer than n ranges are returned when the tree has fewer
+// leaves. The ranges cover every key; concurrent IterateRange calls are safe
+// only while nothing modifies the tree.
+func (t *Tree) Partitions(n int) [][2]uint64 {
+	type leaf struct {
+		keys   int
+		maxKey uint64
+	}
+	var leaves []leaf
+	total := 0
+	t.Iterate(func(nd node) {
+		if nd.isLeaf() && nd.numKeys() > 0 {
+			leaves = append(leaves, leaf{nd.numKeys(), nd.maxKey()})
+			total += nd.numKeys()
+		}
+	})
+
+	n = min(n, len(leaves))
+	if n <= 1 {
+		return [][2]uint64{{0, math.MaxUint64}}
+	}
+
+	parts := make([][2]uint64, 0, n)
+	var lo uint64
+	seen := 0
+	for _, l := range leaves[:len(leaves)-1] {
+		seen += l.keys
+		// Cut once this leaf reaches the next 1/n share of the keys.
+		if seen*n >= (len(parts)+1)*total && len(parts) < n-1 {
+			parts = append(parts, [2]uint64{lo, l.maxKey})
+			lo = l.maxKey + 1
+		}
+	}
+	return append(parts, [2]uint64{lo, math.MaxUint64})
+}
+
 // split splits a full node into two, returning the new right sibling.
 func (t *Tree) split(pid uint64) node {
-	n := t.node(pid)
+	n := t.mut(pid)
 	if !n.isFull() {
 		panic("split called on non-full node")
 	}
@@ -278,21 +465,27 @@ func (t *Tree) split(pid uint64) node {
 	return nn
 }
 
-// DeleteBelow deletes all keys with value under ts.
+// DeleteBelow deletes all keys with value under ts. In TTL mode it deletes
+// the keys whose expiry is under ts instead, as ExpireBefore does.
 func (t *Tree) DeleteBelow(ts uint64) {
-	root := t.node(1)
-	t.stats.NumLeafKeys = 0
-	t.compact(root, ts)
-	if root.numKeys() < 1 {
-		// Root should have at least 1 key.
+	if t.expiry != nil {
+		t.ExpireBefore(ts)
+		return
 	}
+	t.deleteValuesBelow(ts)
+}
+
+// deleteValuesBelow deletes all keys with value under ts.
+func (t *Tree) deleteValuesBelow(ts uint64) {
+	t.stats.NumLeafKeys = 0
+	t.compact(1, ts)
 }
 
 // recursiveFree reclaims the subtree rooted at n, adding pages to the free list and updating stats.
 func (t *Tree) recursiveFree(n node, pid uint64) {
 	if n.isLeaf() {
 		t.stats.NumLeafKeys -= n.numKeys()
-		n.setAt(0, t.freePage)
+		t.own(pid, false).setAt(0, t.freePage)
 		t.freePage = pid
 		t.stats.NumPagesFree++
 		return
@@ -305,13 +498,15 @@ func (t *Tree) recursiveFree(n node, pid uint64) {
 		t.recursiveFree(child, childID)
 	}
 	// Free the node itself.
-	n.setAt(0, t.freePage)
+	t.own(pid, false).setAt(0, t.freePage)
 	t.freePage = pid
 	t.stats.NumPagesFree++
 }
 
-// compact recursively removes keys with value < ts from the node and its children.
-func (t *Tree) compact(n node, ts uint64) int {
+// compact recursively removes keys with value < ts from the node at pid and
+// its children.
+func (t *Tree) compact(pid, ts uint64) int {
+	n := t.mut(pid)
 	if n.isLeaf() {
 		numKeys := n.compact(ts)
 		t.stats.NumLeafKeys += n.numKeys()
@@ -336,10 +531,10 @@ func (t *Tree) compact(n node, ts uint64) int {
 		}
 
 		childID := n.uint64(valOffset(i))
-		child := t.node(childID)
-		if rem := t.compact(child, ts); rem == 0 && i < N-1 {
+		if rem := t.compact(childID, ts); rem == 0 && i < N-1 {
 			// If no valid key is remaining we can drop this child. However, don't do that if this
 			// is the max key.
+			child := t.node(childID)
 			t.stats.NumLeafKeys -= child.numKeys()
 			child.setAt(0, t.freePage)
 			t.freePage = childID
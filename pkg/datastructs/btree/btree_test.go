@@ -3,6 +3,7 @@ package btree
 import (
 	"io"
 	"math"
+	"sync"
 	"testing"
 )
 
@@ -222,6 +223,60 @@ func TestStats_AfterDeleteBelow(t *testing.T) {
 	}
 }
 
+func TestStats_Shape(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+
+	// A fresh tree is an internal root over one leaf holding the sentinel.
+	stats := tree.Stats()
+	if stats.Height != 2 || stats.MinKey != 0 || stats.MaxKey != 0 {
+		t.Errorf("fresh tree Height, MinKey, MaxKey = %d, %d, %d, want 2, 0, 0",
+			stats.Height, stats.MinKey, stats.MaxKey)
+	}
+
+	// Sequential inserts leave every leaf but the last half full.
+	for i := uint64(1); i <= 1000; i++ {
+		tree.Set(i+10, i)
+	}
+	stats = tree.Stats()
+	if stats.Height != 2 || len(stats.LevelFill) != 2 {
+		t.Fatalf("Height = %d, LevelFill = %v, want 2 levels", stats.Height, stats.LevelFill)
+	}
+	if stats.MinKey != 11 || stats.MaxKey != 1010 {
+		t.Errorf("MinKey, MaxKey = %d, %d, want 11, 1010", stats.MinKey, stats.MaxKey)
+	}
+	if stats.LeafFill < 45 || stats.LeafFill > 65 {
+		t.Errorf("LeafFill = %.1f after sequential inserts, want about 50", stats.LeafFill)
+	}
+	if stats.LevelFill[1] != stats.LeafFill || stats.LevelFill[0] != stats.InternalFill {
+		t.Errorf("LevelFill = %v, want [%.1f %.1f]", stats.LevelFill, stats.InternalFill, stats.LeafFill)
+	}
+
+	// Expired keys no longer count as the minimum.
+	tree.DeleteBelow(500)
+	if stats = tree.Stats(); stats.MinKey != 510 || stats.MaxKey != 1010 {
+		t.Errorf("after DeleteBelow MinKey, MaxKey = %d, %d, want 510, 1010", stats.MinKey, stats.MaxKey)
+	}
+}
+
+func TestStats_Height(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+
+	// Enough sequential keys to split the internal root (see
+	// TestSet_InternalRootSplit).
+	for i := uint64(1); i <= maxKeys*maxKeys; i++ {
+		tree.Set(i, i)
+	}
+	stats := tree.Stats()
+	if stats.Height != 3 || len(stats.LevelFill) != 3 {
+		t.Fatalf("Height = %d, LevelFill = %v, want 3 levels", stats.Height, stats.LevelFill)
+	}
+	if stats.MaxKey != maxKeys*maxKeys {
+		t.Errorf("MaxKey = %d, want %d", stats.MaxKey, maxKeys*maxKeys)
+	}
+}
+
 // =============================================================================
 // Set Tests
 // =============================================================================
@@ -332,6 +387,27 @@ func TestSet_RootSplit(t *testing.T) {
 	}
 }
 
+func TestSet_InternalRootSplit(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+
+	// The root is an internal node; it only splits once it references
+	// maxKeys leaves, i.e. after roughly maxKeys*maxKeys/2 sequential keys.
+	numKeys := uint64(maxKeys * maxKeys)
+	for i := uint64(1); i <= numKeys; i++ {
+		tree.Set(i, i)
+	}
+	if tree.node(1).numKeys() >= maxKeys/2 {
+		t.Fatalf("root has %d children; expected it to have split", tree.node(1).numKeys())
+	}
+
+	for i := uint64(1); i <= numKeys; i++ {
+		if got := tree.Get(i); got != i {
+			t.Fatalf("after internal root split, Get(%d) = %d, want %d", i, got, i)
+		}
+	}
+}
+
 func TestSet_SequentialKeys(t *testing.T) {
 	tree := NewTree()
 	defer tree.Close()
@@ -777,3 +853,460 @@ func TestIntegration_ResetAndReuse(t *testing.T) {
 		}
 	}
 }
+
+// =============================================================================
+// Range Tests: IterateRange()
+// =============================================================================
+
+func TestIterateRange(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+
+	const n = 10000 // enough keys for several levels
+	for i := uint64(1); i <= n; i++ {
+		tree.Set(i*2, i)
+	}
+
+	var got []uint64
+	tree.IterateRange(1001, 1010, func(k, v uint64) bool {
+		got = append(got, k)
+		return true
+	})
+	want := []uint64{1002, 1004, 1006, 1008, 1010}
+	if len(got) != len(want) {
+		t.Fatalf("IterateRange keys = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("IterateRange keys = %v, want %v", got, want)
+		}
+	}
+
+	count := 0
+	tree.IterateRange(2, 2*n, func(k, v uint64) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Errorf("IterateRange did not stop early: %d calls", count)
+	}
+
+	count = 0
+	tree.IterateRange(1, math.MaxUint64, func(k, v uint64) bool {
+		count++
+		return true
+	})
+	if count != n {
+		t.Errorf("IterateRange over everything = %d keys, want %d", count, n)
+	}
+}
+
+// =============================================================================
+// Partition Tests: Partitions()
+// =============================================================================
+
+func TestPartitions(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+
+	const n = 50000
+	for i := uint64(1); i <= n; i++ {
+		tree.Set(i*3, i)
+	}
+
+	parts := tree.Partitions(8)
+	if len(parts) != 8 {
+		t.Fatalf("Partitions(8) = %d ranges", len(parts))
+	}
+	if parts[0][0] != 0 || parts[len(parts)-1][1] != math.MaxUint64 {
+		t.Errorf("ranges do not cover the key space: %v", parts)
+	}
+
+	var wg sync.WaitGroup
+	counts := make([]int, len(parts))
+	for i, p := range parts {
+		if i > 0 && p[0] != parts[i-1][1]+1 {
+			t.Errorf("range %d starts at %d, previous ends at %d", i, p[0], parts[i-1][1])
+		}
+		wg.Go(func() {
+			tree.IterateRange(p[0], p[1], func(k, v uint64) bool {
+				counts[i]++
+				return true
+			})
+		})
+	}
+	wg.Wait()
+
+	total := 0
+	for i, c := range counts {
+		total += c
+		if c < n/16 || c > n/4 {
+			t.Errorf("range %d holds %d keys, want about %d", i, c, n/8)
+		}
+	}
+	if total != n {
+		t.Errorf("ranges hold %d keys, want %d", total, n)
+	}
+}
+
+func TestPartitions_Small(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+	tree.Set(5, 1)
+
+	for _, n := range []int{-1, 0, 1, 4} {
+		parts := tree.Partitions(n)
+		if len(parts) != 1 || parts[0] != [2]uint64{0, math.MaxUint64} {
+			t.Errorf("Partitions(%d) = %v, want one full range", n, parts)
+		}
+	}
+}
+
+// =============================================================================
+// Composite Tests: NewCompositeTree() / Set2() / IteratePrefix()
+// =============================================================================
+
+func TestComposite_SetGet(t *testing.T) {
+	tree := NewCompositeTree(40)
+	defer tree.Close()
+
+	tree.Set2(0, 0, 1)
+	tree.Set2(7, 1<<40-1, 2)
+	tree.Set2(1<<24-2, 5, 3)
+
+	if got := tree.Get2(0, 0); got != 1 {
+		t.Errorf("Get2(0, 0) = %d, want 1", got)
+	}
+	if got := tree.Get2(7, 1<<40-1); got != 2 {
+		t.Errorf("Get2(7, max) = %d, want 2", got)
+	}
+	if got := tree.Get2(1<<24-2, 5); got != 3 {
+		t.Errorf("Get2(maxPrimary, 5) = %d, want 3", got)
+	}
+	if got := tree.Get2(8, 0); got != 0 {
+		t.Errorf("Get2(missing) = %d, want 0", got)
+	}
+}
+
+func TestComposite_IteratePrefix(t *testing.T) {
+	tree := NewCompositeTree(32)
+	defer tree.Close()
+
+	// Interleave series so each is spread across many pages.
+	for ts := uint64(0); ts < 2000; ts++ {
+		for series := uint64(1); series <= 5; series++ {
+			tree.Set2(series, ts, series*100000+ts+1)
+		}
+	}
+
+	var prev uint64
+	count := 0
+	tree.IteratePrefix(3, func(ts, v uint64) bool {
+		if count > 0 && ts <= prev {
+			t.Fatalf("secondary out of order: %d after %d", ts, prev)
+		}
+		if v != 300000+ts+1 {
+			t.Fatalf("IteratePrefix(3) ts=%d val=%d, want %d", ts, v, 300000+ts+1)
+		}
+		prev = ts
+		count++
+		return true
+	})
+	if count != 2000 {
+		t.Errorf("IteratePrefix(3) = %d entries, want 2000", count)
+	}
+
+	count = 0
+	tree.IterateRange2(5, 100, 199, func(ts, v uint64) bool {
+		count++
+		return true
+	})
+	if count != 100 {
+		t.Errorf("IterateRange2(5, 100, 199) = %d entries, want 100", count)
+	}
+
+	tree.IteratePrefix(9, func(ts, v uint64) bool {
+		t.Fatal("IteratePrefix on an absent series yielded entries")
+		return false
+	})
+}
+
+func TestComposite_Panics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"bad_bits", func() { NewCompositeTree(64) }},
+		{"plain_tree", func() {
+			tree := NewTree()
+			defer tree.Close()
+			tree.Set2(1, 1, 1)
+		}},
+		{"secondary_overflow", func() {
+			tree := NewCompositeTree(8)
+			defer tree.Close()
+			tree.Set2(1, 256, 1)
+		}},
+		{"reserved_primary", func() {
+			tree := NewCompositeTree(8)
+			defer tree.Close()
+			tree.Set2(1<<56-1, 0, 1)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// =============================================================================
+// TTL Tests: NewTTLTree() / SetWithExpiry() / ExpireBefore()
+// =============================================================================
+
+func TestTTL_ExpireBefore(t *testing.T) {
+	tree := NewTTLTree()
+	defer tree.Close()
+
+	const n = 2000 // spans several leaves
+	for k := uint64(1); k <= n; k++ {
+		tree.SetWithExpiry(k, k*10, 100+k%10)
+	}
+	tree.Set(n+1, 7) // never expires
+
+	if got := tree.ExpiryOf(5); got != 105 {
+		t.Errorf("ExpiryOf(5) = %d, want 105", got)
+	}
+
+	// Expiries 100..104 go; values are unrelated to the expiry.
+	if got := tree.ExpireBefore(105); got != n/2 {
+		t.Errorf("ExpireBefore = %d, want %d", got, n/2)
+	}
+	for k := uint64(1); k <= n; k++ {
+		got, want := tree.Get(k), k*10
+		if k%10 < 5 {
+			want = 0
+		}
+		if got != want {
+			t.Fatalf("Get(%d) = %d, want %d", k, got, want)
+		}
+	}
+	if tree.ExpiryOf(1) != 0 {
+		t.Error("expired key kept its expiry")
+	}
+
+	tree.DeleteBelow(math.MaxUint64) // reads the expiry column too
+	count := 0
+	tree.IterateKV(func(k, v uint64) uint64 {
+		count++
+		return 0
+	})
+	if count != 1 || tree.Get(n+1) != 7 {
+		t.Errorf("after sweeping everything: %d keys, Get(%d) = %d; want only the key without expiry", count, n+1, tree.Get(n+1))
+	}
+}
+
+func TestTTL_ExpireBeforeUnixTimestamps(t *testing.T) {
+	tree := NewTTLTree()
+	defer tree.Close()
+
+	// Keys sit far below real timestamps, so sweeping by key instead of by
+	// expiry would drop every live expiry in one pass.
+	const now = uint64(1_700_000_000)
+	const n = 2000
+	for k := uint64(1); k <= n; k++ {
+		tree.SetWithExpiry(k, k, now+3600)
+	}
+	tree.SetWithExpiry(n+1, 1, now-1)
+
+	if got := tree.ExpireBefore(now); got != 1 {
+		t.Fatalf("ExpireBefore(now) = %d, want 1", got)
+	}
+	for k := uint64(1); k <= n; k++ {
+		if got := tree.ExpiryOf(k); got != now+3600 {
+			t.Fatalf("ExpiryOf(%d) = %d after first sweep, want %d", k, got, now+3600)
+		}
+	}
+	if got := tree.ExpireBefore(now + 7200); got != n {
+		t.Errorf("ExpireBefore(now+2h) = %d, want %d", got, n)
+	}
+	if got := tree.ExpireBefore(now + 7200); got != 0 {
+		t.Errorf("repeated ExpireBefore = %d, want 0", got)
+	}
+}
+
+func TestTTL_SetClearsExpiry(t *testing.T) {
+	tree := NewTTLTree()
+	defer tree.Close()
+
+	tree.SetWithExpiry(1, 1, 10)
+	tree.Set(1, 2)
+	tree.SetWithExpiry(2, 1, 10)
+	tree.SetWithExpiry(2, 3, 0)
+
+	if n := tree.ExpireBefore(100); n != 0 {
+		t.Errorf("ExpireBefore = %d after expiries were cleared, want 0", n)
+	}
+	if tree.Get(1) != 2 || tree.Get(2) != 3 {
+		t.Errorf("Get = %d, %d; want 2, 3", tree.Get(1), tree.Get(2))
+	}
+}
+
+func TestTTL_Reset(t *testing.T) {
+	tree := NewTTLTree()
+	defer tree.Close()
+
+	tree.SetWithExpiry(1, 1, 10)
+	tree.Reset()
+	if tree.ExpiryOf(1) != 0 {
+		t.Error("Reset kept the expiry column")
+	}
+}
+
+func TestTTL_PanicWithoutTTLMode(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+
+	if n := tree.ExpireBefore(10); n != 0 {
+		t.Errorf("ExpireBefore on a plain tree = %d, want 0", n)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("SetWithExpiry on a plain tree did not panic")
+		}
+	}()
+	tree.SetWithExpiry(1, 1, 1)
+}
+
+// =============================================================================
+// Checkpoint Tests: Checkpoint()
+// =============================================================================
+
+func TestCheckpoint_IsolatedFromWrites(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+
+	const n = 5000
+	for i := uint64(1); i <= n; i++ {
+		tree.Set(i, i)
+	}
+	cp := tree.Checkpoint()
+	defer cp.Release()
+
+	for i := uint64(1); i <= n; i++ {
+		tree.Set(i, i+1)
+		tree.Set(n+i, 1)
+	}
+	tree.DeleteBelow(3000) // frees pages the checkpoint still reads
+
+	for i := uint64(1); i <= n; i += 97 {
+		if got := cp.Get(i); got != i {
+			t.Fatalf("cp.Get(%d) = %d, want %d", i, got, i)
+		}
+		if got := tree.Get(i); i >= 2999 && got != i+1 {
+			t.Fatalf("tree.Get(%d) = %d, want %d", i, got, i+1)
+		}
+	}
+	if cp.Get(n+1) != 0 {
+		t.Error("checkpoint sees a key set after it")
+	}
+
+	count := 0
+	want := uint64(1)
+	cp.IterateRange(1, math.MaxUint64, func(k, v uint64) bool {
+		if k != want || v != want {
+			t.Fatalf("IterateRange visited %d=%d, want %d=%d", k, v, want, want)
+		}
+		want++
+		count++
+		return true
+	})
+	if count != n {
+		t.Errorf("IterateRange over checkpoint = %d keys, want %d", count, n)
+	}
+}
+
+func TestCheckpoint_ConcurrentWithSets(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+
+	const n = 20000
+	for i := uint64(1); i <= n; i++ {
+		tree.Set(i, 1)
+	}
+	cp := tree.Checkpoint()
+
+	var wg sync.WaitGroup
+	var sum uint64
+	wg.Go(func() {
+		defer cp.Release()
+		cp.IterateRange(1, math.MaxUint64, func(k, v uint64) bool {
+			sum += v
+			return true
+		})
+	})
+	for i := uint64(1); i <= 2*n; i++ {
+		tree.Set(i, 2)
+	}
+	wg.Wait()
+
+	if sum != n {
+		t.Errorf("checkpoint sum = %d, want %d", sum, n)
+	}
+	if tree.Get(n) != 2 || tree.Get(2*n) != 2 {
+		t.Errorf("tree.Get = %d, %d; want 2, 2", tree.Get(n), tree.Get(2*n))
+	}
+}
+
+func TestCheckpoint_Repeated(t *testing.T) {
+	tree := NewTree()
+	defer tree.Close()
+
+	var cps []*Checkpoint
+	for gen := uint64(1); gen <= 3; gen++ {
+		for i := uint64(1); i <= 2000; i++ {
+			tree.Set(i, gen)
+		}
+		cps = append(cps, tree.Checkpoint())
+	}
+	tree.Set(1, 9)
+
+	for i, cp := range cps {
+		if got := cp.Get(1000); got != uint64(i+1) {
+			t.Errorf("checkpoint %d: Get = %d, want %d", i, got, i+1)
+		}
+		cp.Release()
+	}
+	if tree.Get(1) != 9 || tree.Get(2000) != 3 {
+		t.Errorf("tree.Get = %d, %d; want 9, 3", tree.Get(1), tree.Get(2000))
+	}
+
+	// A released checkpoint and a reset tree share nothing.
+	tree.Reset()
+	tree.Set(5, 5)
+	if tree.Get(5) != 5 {
+		t.Error("tree unusable after checkpoints and Reset")
+	}
+}
+
+func TestCheckpoint_TTL(t *testing.T) {
+	tree := NewTTLTree()
+	defer tree.Close()
+
+	tree.SetWithExpiry(1, 10, 100)
+	cp := tree.Checkpoint()
+	defer cp.Release()
+
+	tree.SetWithExpiry(1, 20, 200)
+	if cp.Get(1) != 10 || cp.ExpiryOf(1) != 100 {
+		t.Errorf("checkpoint = %d exp %d, want 10 exp 100", cp.Get(1), cp.ExpiryOf(1))
+	}
+	if tree.ExpiryOf(1) != 200 {
+		t.Errorf("tree.ExpiryOf = %d, want 200", tree.ExpiryOf(1))
+	}
+}
@@ -0,0 +1,170 @@
+package btree
+
+import (
+	"math/bits"
+	"sync/atomic"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+// Checkpoint is a read-only view of a tree at the moment Checkpoint was
+// called. It stays valid while the tree keeps changing, so a background
+// goroutine can serialize it, to an sstable or a WAL snapshot, while
+// foreground Sets continue. Its methods are safe for concurrent use, with
+// each other and with the tree's.
+type Checkpoint struct {
+	view   Tree
+	arena  *frozenArena
+	expiry *Checkpoint // checkpoint of the expiry column in TTL mode
+}
+
+// frozenArena is a page arena no one writes to any more. It goes back to
+// the pool when both the checkpoint and the tree it came from let go.
+type frozenArena struct {
+	buffer *buffer.Buffer
+	data   []byte
+	refs   atomic.Int32
+}
+
+func (a *frozenArena) node(pid uint64) node {
+	start := pageSize * int(pid)
+	return getNode(a.data[start : start+pageSize])
+}
+
+func (a *frozenArena) release() {
+	if a.refs.Add(-1) == 0 {
+		_ = a.buffer.Release()
+	}
+}
+
+// cow records which pages of a frozen arena the tree has copied into its
+// own arena. The rest it still reads from the frozen one.
+type cow struct {
+	*frozenArena
+	pages  uint64   // pages below this ID were frozen
+	copied []uint64 // bitmap of the frozen pages copied
+	left   int      // frozen pages not copied yet
+}
+
+func (c *cow) borrowed(pid uint64) bool {
+	return pid < c.pages && pid != 0 && c.copied[pid/64]&(1<<(pid%64)) == 0
+}
+
+// Checkpoint freezes the tree's page arena and returns a view of it. The
+// tree moves to a new arena of the same size and copies a page into it the
+// first time it writes to the page, reading the frozen arena until then, so
+// a checkpoint costs one page copy per page written after it rather than a
+// copy of the whole tree. In TTL mode the expiry column is frozen too.
+//
+// Release the checkpoint once done with it.
+func (t *Tree) Checkpoint() *Checkpoint {
+	t.settle()
+
+	arena := &frozenArena{buffer: t.buffer, data: t.data}
+	arena.refs.Store(2) // the checkpoint and the tree
+
+	t.buffer = newBuffer(len(arena.data))
+	t.buffer.Reset()
+	t.buffer.AllocateOffset(len(arena.data))
+	t.data = t.buffer.Bytes()
+	t.shared = &cow{
+		frozenArena: arena,
+		pages:       t.nextPage,
+		copied:      make([]uint64, (t.nextPage+63)/64),
+		left:        int(t.nextPage - 1),
+	}
+
+	c := &Checkpoint{
+		view: Tree{
+			data:          arena.data,
+			nextPage:      t.nextPage,
+			freePage:      t.freePage,
+			stats:         t.stats,
+			secondaryBits: t.secondaryBits,
+		},
+		arena: arena,
+	}
+	if t.expiry != nil {
+		c.expiry = t.expiry.Checkpoint()
+		c.view.expiry = &c.expiry.view
+	}
+	return c
+}
+
+// mut returns the node at pid for writing.
+func (t *Tree) mut(pid uint64) node {
+	return t.own(pid, true)
+}
+
+// own returns the node at pid in the tree's own arena, for writing. A page
+// still shared with a checkpoint is copied out first if keep is set; when
+// it is not, the caller is about to overwrite the page or free it.
+func (t *Tree) own(pid uint64, keep bool) node {
+	if s := t.shared; s != nil && s.borrowed(pid) {
+		if keep {
+			copy(t.page(pid), s.node(pid))
+		}
+		s.copied[pid/64] |= 1 << (pid % 64)
+		if s.left--; s.left == 0 {
+			t.unshare()
+		}
+	}
+	return t.page(pid)
+}
+
+// settle copies every page the tree still reads from the frozen arena and
+// lets go of it.
+func (t *Tree) settle() {
+	s := t.shared
+	if s == nil {
+		return
+	}
+	for i, word := range s.copied {
+		for free := ^word; free != 0; free &= free - 1 {
+			pid := uint64(i*64 + bits.TrailingZeros64(free))
+			if s.borrowed(pid) {
+				copy(t.page(pid), s.node(pid))
+			}
+		}
+	}
+	t.unshare()
+}
+
+// unshare drops the tree's hold on the frozen arena.
+func (t *Tree) unshare() {
+	if t.shared != nil {
+		t.shared.release()
+		t.shared = nil
+	}
+}
+
+// Get returns the value of key k at the time of the checkpoint, or 0.
+func (c *Checkpoint) Get(k uint64) uint64 {
+	return c.view.Get(k)
+}
+
+// IterateRange calls fn for every key in [lo, hi] at the time of the
+// checkpoint, in ascending order, until fn returns false.
+func (c *Checkpoint) IterateRange(lo, hi uint64, fn func(key, val uint64) bool) {
+	c.view.IterateRange(lo, hi, fn)
+}
+
+// ExpiryOf returns the expiry key k had at the time of the checkpoint, or 0
+// if it had none or the tree is not in TTL mode.
+func (c *Checkpoint) ExpiryOf(k uint64) uint64 {
+	return c.view.ExpiryOf(k)
+}
+
+// Release gives the checkpoint's arena back once the tree no longer needs
+// it either. The checkpoint must not be used afterwards.
+func (c *Checkpoint) Release() {
+	if c.arena == nil {
+		return
+	}
+	if c.expiry != nil {
+		c.expiry.Release()
+	}
+	c.arena.release()
+	c.arena = nil
+	c.view.data = nil
+}
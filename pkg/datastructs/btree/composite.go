@@ -0,0 +1,63 @@
+package btree
+
+import "fmt"
+
+// NewCompositeTree returns a tree in composite-key mode: keys are
+// (primary, secondary) pairs packed into one uint64, secondary in the low
+// secondaryBits bits. Entries sharing a primary are contiguous and ordered by
+// secondary, so a time-series index keyed by (seriesID, timestamp) fits in
+// one tree and each series is a range scan (IteratePrefix).
+//
+// secondaryBits must be in [1, 63]. Primaries must be below
+// 1<<(64-secondaryBits) - 1 and secondaries below 1<<secondaryBits; the
+// all-ones primary is reserved for the tree's sentinel key.
+func NewCompositeTree(secondaryBits uint) *Tree {
+	if secondaryBits < 1 || secondaryBits > 63 {
+		panic(fmt.Sprintf("secondaryBits %d out of range [1, 63]", secondaryBits))
+	}
+	t := NewTree()
+	t.secondaryBits = secondaryBits
+	return t
+}
+
+// compositeKey packs (primary, secondary), shifted by one so that (0, 0) does
+// not collide with the reserved zero key.
+func (t *Tree) compositeKey(primary, secondary uint64) uint64 {
+	if t.secondaryBits == 0 {
+		panic("composite key used on a tree not created by NewCompositeTree")
+	}
+	if secondary > t.maxSecondary() || primary >= 1<<(64-t.secondaryBits)-1 {
+		panic(fmt.Sprintf("composite key (%d, %d) out of range", primary, secondary))
+	}
+	return (primary<<t.secondaryBits | secondary) + 1
+}
+
+func (t *Tree) maxSecondary() uint64 {
+	return 1<<t.secondaryBits - 1
+}
+
+// Set2 sets the value of the composite key (primary, secondary).
+func (t *Tree) Set2(primary, secondary, v uint64) {
+	t.Set(t.compositeKey(primary, secondary), v)
+}
+
+// Get2 returns the value of the composite key (primary, secondary), or 0.
+func (t *Tree) Get2(primary, secondary uint64) uint64 {
+	return t.Get(t.compositeKey(primary, secondary))
+}
+
+// IteratePrefix calls fn for every entry with the given primary, in ascending
+// secondary order, until fn returns false.
+func (t *Tree) IteratePrefix(primary uint64, fn func(secondary, val uint64) bool) {
+	t.IterateRange2(primary, 0, t.maxSecondary(), fn)
+}
+
+// IterateRange2 calls fn for the entries of primary whose secondary is in
+// [lo, hi], in ascending order, until fn returns false.
+func (t *Tree) IterateRange2(primary, lo, hi uint64, fn func(secondary, val uint64) bool) {
+	mask := t.maxSecondary()
+	t.IterateRange(t.compositeKey(primary, lo), t.compositeKey(primary, min(hi, mask)),
+		func(key, val uint64) bool {
+			return fn((key-1)&mask, val)
+		})
+}
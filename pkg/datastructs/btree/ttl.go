@@ -0,0 +1,82 @@
+package btree
+
+import "math"
+
+// NewTTLTree returns a tree in TTL mode: every key may carry an expiry
+// timestamp next to its value, set with SetWithExpiry, so the tree can serve
+// as a TTL index while the value stays free for the caller's data. Expiries
+// live in a companion tree with the same keys, the expiry column, and
+// DeleteBelow and ExpireBefore read that column instead of the values.
+//
+// Expired keys stay readable until a sweep removes them.
+func NewTTLTree() *Tree {
+	t := NewTree()
+	t.expiry = NewTree()
+	return t
+}
+
+// SetWithExpiry sets the key-value pair and the key's expiry. expireAt 0
+// means the key never expires. It panics on a tree not created by
+// NewTTLTree.
+func (t *Tree) SetWithExpiry(k, v, expireAt uint64) {
+	if t.expiry == nil {
+		panic("SetWithExpiry used on a tree not created by NewTTLTree")
+	}
+	if k == math.MaxUint64 || k == 0 {
+		panic("Error setting zero or MaxUint64")
+	}
+	t.setValue(k, v)
+	if expireAt != 0 {
+		t.expiry.Set(k, expireAt)
+	} else {
+		t.clearExpiry(k)
+	}
+}
+
+// ExpiryOf returns the expiry of key k, or 0 if it has none or the tree is
+// not in TTL mode.
+func (t *Tree) ExpiryOf(k uint64) uint64 {
+	if t.expiry == nil {
+		return 0
+	}
+	return t.expiry.Get(k)
+}
+
+// ExpireBefore deletes every key whose expiry is below ts and returns how
+// many it deleted. Keys without an expiry are kept. On a tree not in TTL
+// mode it does nothing.
+func (t *Tree) ExpireBefore(ts uint64) int {
+	if t.expiry == nil || ts == 0 {
+		return 0
+	}
+
+	var expired []uint64
+	t.expiry.IterateKV(func(k, expireAt uint64) uint64 {
+		if expireAt < ts {
+			expired = append(expired, k)
+		}
+		return 0
+	})
+	if len(expired) == 0 {
+		return 0
+	}
+
+	// A zero value marks an entry for compaction in both trees. Compacting
+	// below 1 never takes compact's max-key fast path, so the expiry column
+	// is pruned by value rather than by key.
+	for _, k := range expired {
+		t.setValue(k, 0)
+		t.expiry.Set(k, 0)
+	}
+	t.expiry.deleteValuesBelow(1)
+	t.deleteValuesBelow(1)
+	return len(expired)
+}
+
+// clearExpiry drops the expiry of k, if it has one, when a plain Set
+// overwrites it.
+func (t *Tree) clearExpiry(k uint64) {
+	if t.expiry.Get(k) != 0 {
+		t.expiry.Set(k, 0)
+	}
+}
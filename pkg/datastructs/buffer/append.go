@@ -0,0 +1,83 @@
+package buffer
+
+import "strconv"
+
+// Number formatting goes through a stack scratch array of appendScratch
+// bytes and a single copy, so appending a number allocates nothing.
+
+// WriteString appends s to the buffer.
+func (b *Buffer) WriteString(s string) (int, error) {
+	b.Grow(len(s))
+	n := copy(b.data[b.offset:], s)
+	b.offset += uint64(n)
+	return n, nil
+}
+
+// WriteByte appends c to the buffer.
+func (b *Buffer) WriteByte(c byte) error {
+	b.Grow(1)
+	b.data[b.offset] = c
+	b.offset++
+	return nil
+}
+
+// AppendInt appends the text of i in the given base, as strconv.AppendInt.
+func (b *Buffer) AppendInt(i int64, base int) (int, error) {
+	var tmp [appendScratch]byte
+	return b.Write(strconv.AppendInt(tmp[:0], i, base))
+}
+
+// AppendUint appends the text of u in the given base, as strconv.AppendUint.
+func (b *Buffer) AppendUint(u uint64, base int) (int, error) {
+	var tmp [appendScratch]byte
+	return b.Write(strconv.AppendUint(tmp[:0], u, base))
+}
+
+// AppendFloat appends the text of f, as strconv.AppendFloat.
+func (b *Buffer) AppendFloat(f float64, fmt byte, prec, bitSize int) (int, error) {
+	var tmp [appendScratch]byte
+	return b.Write(strconv.AppendFloat(tmp[:0], f, fmt, prec, bitSize))
+}
+
+// AppendInt appends the text of i in the given base, as strconv.AppendInt.
+// A fixed ring without room for the whole text writes nothing and returns
+// ErrRingFull, so no half-written number is left behind.
+func (rb *RingBuffer) AppendInt(i int64, base int) (int, error) {
+	var tmp [appendScratch]byte
+	return rb.writeWhole(strconv.AppendInt(tmp[:0], i, base))
+}
+
+// AppendUint appends the text of u in the given base, as strconv.AppendUint.
+func (rb *RingBuffer) AppendUint(u uint64, base int) (int, error) {
+	var tmp [appendScratch]byte
+	return rb.writeWhole(strconv.AppendUint(tmp[:0], u, base))
+}
+
+// AppendFloat appends the text of f, as strconv.AppendFloat.
+func (rb *RingBuffer) AppendFloat(f float64, fmt byte, prec, bitSize int) (int, error) {
+	var tmp [appendScratch]byte
+	return rb.writeWhole(strconv.AppendFloat(tmp[:0], f, fmt, prec, bitSize))
+}
+
+// writeWhole writes p, or nothing if a fixed ring cannot hold all of it.
+func (rb *RingBuffer) writeWhole(p []byte) (int, error) {
+	if rb.fixed && len(p) > rb.Available() {
+		return 0, ErrRingFull
+	}
+	return rb.Write(p)
+}
+
+// AppendInt appends the text of i in the given base, as strconv.AppendInt.
+func (er *ElasticRing) AppendInt(i int64, base int) (int, error) {
+	return er.getOrCreate().AppendInt(i, base)
+}
+
+// AppendUint appends the text of u in the given base, as strconv.AppendUint.
+func (er *ElasticRing) AppendUint(u uint64, base int) (int, error) {
+	return er.getOrCreate().AppendUint(u, base)
+}
+
+// AppendFloat appends the text of f, as strconv.AppendFloat.
+func (er *ElasticRing) AppendFloat(f float64, fmt byte, prec, bitSize int) (int, error) {
+	return er.getOrCreate().AppendFloat(f, fmt, prec, bitSize)
+}
@@ -0,0 +1,86 @@
+package buffer
+
+import (
+	"math"
+	"testing"
+)
+
+// =============================================================================
+// Append* Formatting
+// =============================================================================
+
+func TestBuffer_Append(t *testing.T) {
+	b := New(0)
+	b.AppendInt(-42, 10)
+	b.WriteByte(' ')
+	b.AppendUint(math.MaxUint64, 16)
+	b.WriteString(" ")
+	b.AppendFloat(3.25, 'f', -1, 64)
+	b.WriteByte(' ')
+	b.AppendInt(math.MinInt64, 2)
+
+	want := "-42 ffffffffffffffff 3.25 -1" +
+		"000000000000000000000000000000000000000000000000000000000000000"
+	if got := string(b.Bytes()); got != want {
+		t.Errorf("Bytes() = %q, want %q", got, want)
+	}
+}
+
+func TestBuffer_AppendLargeFloat(t *testing.T) {
+	b := New(0)
+	b.AppendFloat(1e300, 'f', 0, 64)
+	if got := b.LenNoPadding(); got != 301 {
+		t.Errorf("len = %d, want 301", got)
+	}
+}
+
+func TestRingBuffer_Append(t *testing.T) {
+	rb := NewRing(4)
+	rb.AppendInt(12345, 10)
+	rb.WriteByte(',')
+	rb.AppendUint(7, 10)
+	rb.WriteByte(',')
+	rb.AppendFloat(0.5, 'g', -1, 32)
+
+	if got := string(rb.Bytes()); got != "12345,7,0.5" {
+		t.Errorf("Bytes() = %q, want %q", got, "12345,7,0.5")
+	}
+}
+
+func TestRingBuffer_AppendFixedFull(t *testing.T) {
+	rb := NewRingFrom(make([]byte, 4), false)
+	n, err := rb.AppendInt(12345, 10)
+	if err != ErrRingFull || n != 0 || rb.Buffered() != 0 {
+		t.Errorf("AppendInt = %d, %v (buffered %d), want 0, ErrRingFull", n, err, rb.Buffered())
+	}
+	if n, err := rb.AppendInt(1234, 10); err != nil || n != 4 {
+		t.Errorf("AppendInt(1234) = %d, %v, want 4, nil", n, err)
+	}
+}
+
+func TestElasticRing_Append(t *testing.T) {
+	var er ElasticRing
+	defer er.Reset()
+	er.AppendInt(-1, 10)
+	er.AppendUint(2, 10)
+	er.AppendFloat(3, 'f', 1, 64)
+
+	if got := string(er.Bytes()); got != "-123.0" {
+		t.Errorf("Bytes() = %q, want %q", got, "-123.0")
+	}
+}
+
+func TestAppend_NoAllocs(t *testing.T) {
+	b := New(1 << 16)
+	rb := NewRing(1 << 16)
+	allocs := testing.AllocsPerRun(100, func() {
+		b.AppendInt(-123456789, 10)
+		b.AppendFloat(math.Pi, 'g', -1, 64)
+		rb.AppendUint(987654321, 10)
+		rb.AppendFloat(math.E, 'e', 6, 64)
+		rb.Discard(rb.Buffered())
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per run = %v, want 0", allocs)
+	}
+}
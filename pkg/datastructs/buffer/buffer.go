@@ -91,6 +91,32 @@ func (b *Buffer) Grow(n int) {
 	b.data = newData
 }
 
+// Reserve makes room for n more bytes with at most one allocation, sized
+// exactly, where Grow would over-allocate. Call it before a
+// latency-sensitive phase whose writes are then known not to grow the
+// buffer.
+func (b *Buffer) Reserve(n int) {
+	if b.data == nil {
+		panic("buffer: uninitialized")
+	}
+	need := int(b.offset) + n
+	if b.max > 0 && need > b.max {
+		panic(fmt.Errorf("buffer: max limit exceeded (limit: %d, current: %d, reserve: %d)", b.max, b.offset, n))
+	}
+	if need <= b.cap {
+		return
+	}
+	newData := make([]byte, need)
+	copy(newData, b.data[:b.offset])
+	b.data = newData
+	b.cap = need
+}
+
+// Reserved returns the bytes that can be written before the buffer grows.
+func (b *Buffer) Reserved() int {
+	return b.cap - int(b.offset)
+}
+
 // Allocate returns a slice of size n from the buffer for direct writing.
 // The returned slice is valid until the next Grow call.
 func (b *Buffer) Allocate(n int) []byte {
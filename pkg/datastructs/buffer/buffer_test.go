@@ -2,10 +2,11 @@ package buffer
 
 import (
 	"bytes"
-	"errors"
 	"io"
 	"strings"
 	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/testutil/iotest"
 )
 
 // Interface compliance checks (compile-time)
@@ -292,6 +293,45 @@ func TestGrow_PanicMaxLimit(t *testing.T) {
 	b.Grow(200) // current + 200 > max
 }
 
+// =============================================================================
+// Method: Reserve() and Reserved()
+// =============================================================================
+
+func TestReserve(t *testing.T) {
+	b := New(100)
+	b.Write([]byte("hello"))
+	b.Reserve(1000)
+	if got := b.Reserved(); got != 1000 {
+		t.Errorf("Reserved = %d, want exactly 1000", got)
+	}
+	if !bytes.Equal(b.Bytes(), []byte("hello")) {
+		t.Error("Reserve should preserve data")
+	}
+
+	data := &b.data[0]
+	b.Write(make([]byte, 1000))
+	if &b.data[0] != data || b.Reserved() != 0 {
+		t.Error("writes within the reservation reallocated")
+	}
+
+	// Already room: no change.
+	b = New(500)
+	before := b.Reserved()
+	b.Reserve(10)
+	if b.Reserved() != before {
+		t.Errorf("Reserve within capacity changed Reserved from %d to %d", before, b.Reserved())
+	}
+}
+
+func TestReserve_PanicMaxLimit(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic on max limit exceeded")
+		}
+	}()
+	New(100).WithMaxLimit(200).Reserve(500)
+}
+
 // =============================================================================
 // Method: Allocate()
 // =============================================================================
@@ -548,16 +588,10 @@ func TestWriteTo_Large(t *testing.T) {
 	}
 }
 
-type errorWriter struct{}
-
-func (w errorWriter) Write(p []byte) (int, error) {
-	return 0, errors.New("write error")
-}
-
 func TestWriteTo_Error(t *testing.T) {
 	b := New(100)
 	b.Write([]byte("data"))
-	_, err := b.WriteTo(errorWriter{})
+	_, err := b.WriteTo(iotest.ErrWriter(nil))
 	if err == nil {
 		t.Error("expected error from WriteTo")
 	}
@@ -607,15 +641,9 @@ func TestReadFrom_Empty(t *testing.T) {
 	}
 }
 
-type errorReader struct{}
-
-func (r errorReader) Read(p []byte) (int, error) {
-	return 0, errors.New("read error")
-}
-
 func TestReadFrom_Error(t *testing.T) {
 	b := New(100)
-	_, err := b.ReadFrom(errorReader{})
+	_, err := b.ReadFrom(iotest.ErrReader(nil))
 	if err == nil {
 		t.Error("expected error from ReadFrom")
 	}
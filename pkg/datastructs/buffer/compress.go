@@ -0,0 +1,141 @@
+package buffer
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+
+	"github.com/huynhanx03/go-common/pkg/pool/byteslice"
+)
+
+// compressScratch is the size of the pooled scratch slice CompressWriter
+// and DecompressReader copy through.
+const compressScratch = 32 << 10
+
+// ErrCodecClosed is returned by writes and reads after Close.
+var ErrCodecClosed = errors.New("buffer: compression stream closed")
+
+// Codec creates streaming compressors and decompressors. Its shape is that
+// of zstd's NewWriter/NewReader and snappy's NewBufferedWriter/NewReader
+// behind a one-line adapter, so either plugs in without this package
+// depending on it. Closing a stream must not close the underlying writer or
+// reader.
+type Codec interface {
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// Gzip returns the standard library's gzip as a Codec, compressing at level
+// (gzip.DefaultCompression, gzip.BestSpeed, ...).
+func Gzip(level int) Codec {
+	return gzipCodec{level: level}
+}
+
+type gzipCodec struct{ level int }
+
+func (c gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, c.level)
+}
+
+func (c gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// CompressWriter compresses everything written to it into an underlying
+// writer. Drain a buffer through it with the buffer's WriteTo, then Close
+// it to flush the compressed stream.
+type CompressWriter struct {
+	enc    io.WriteCloser
+	closed bool
+}
+
+// CompressTo returns a writer that compresses into w with codec, e.g. to
+// spill an ElasticBuffer to disk or frame a diskqueue segment:
+//
+//	cw, err := buffer.CompressTo(f, buffer.Gzip(gzip.BestSpeed))
+//	...
+//	_, err = buf.WriteTo(cw)
+//	err = cw.Close()
+func CompressTo(w io.Writer, codec Codec) (*CompressWriter, error) {
+	enc, err := codec.NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+	return &CompressWriter{enc: enc}, nil
+}
+
+// Write compresses p.
+func (c *CompressWriter) Write(p []byte) (int, error) {
+	if c.closed {
+		return 0, ErrCodecClosed
+	}
+	return c.enc.Write(p)
+}
+
+// ReadFrom implements io.ReaderFrom, compressing r until EOF. Readers
+// without WriteTo are copied through a pooled scratch slice.
+func (c *CompressWriter) ReadFrom(r io.Reader) (int64, error) {
+	if c.closed {
+		return 0, ErrCodecClosed
+	}
+	scratch := byteslice.GetZeroed(compressScratch)
+	defer byteslice.Put(scratch)
+	return io.CopyBuffer(c.enc, r, scratch)
+}
+
+// Close flushes the compressed stream. It does not close the underlying
+// writer. Calling it more than once is a no-op.
+func (c *CompressWriter) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.enc.Close()
+}
+
+// DecompressReader decompresses an underlying reader. Fill a buffer from it
+// with the buffer's ReadFrom, or copy it out with WriteTo.
+type DecompressReader struct {
+	dec    io.ReadCloser
+	closed bool
+}
+
+// DecompressFrom returns a reader of the data codec decompresses from r.
+// Codecs that read a header, like gzip, read it here and report a malformed
+// one as an error.
+func DecompressFrom(r io.Reader, codec Codec) (*DecompressReader, error) {
+	dec, err := codec.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &DecompressReader{dec: dec}, nil
+}
+
+// Read reads decompressed data.
+func (d *DecompressReader) Read(p []byte) (int, error) {
+	if d.closed {
+		return 0, ErrCodecClosed
+	}
+	return d.dec.Read(p)
+}
+
+// WriteTo implements io.WriterTo, decompressing until EOF. Writers
+// without ReadFrom are fed through a pooled scratch slice.
+func (d *DecompressReader) WriteTo(w io.Writer) (int64, error) {
+	if d.closed {
+		return 0, ErrCodecClosed
+	}
+	scratch := byteslice.Get(compressScratch)
+	defer byteslice.Put(scratch)
+	return io.CopyBuffer(w, d.dec, scratch)
+}
+
+// Close releases the decompressor. It does not close the underlying
+// reader. Calling it more than once is a no-op.
+func (d *DecompressReader) Close() error {
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	return d.dec.Close()
+}
@@ -0,0 +1,104 @@
+package buffer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	payload := bytes.Repeat([]byte("spill me to disk "), 10_000)
+
+	src, err := NewElastic(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Release()
+	src.Write(payload)
+
+	var disk bytes.Buffer
+	cw, err := CompressTo(&disk, Gzip(gzip.BestSpeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.WriteTo(cw); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if disk.Len() >= len(payload)/10 {
+		t.Errorf("compressed %d bytes to %d", len(payload), disk.Len())
+	}
+
+	dr, err := DecompressFrom(&disk, Gzip(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dr.Close()
+	dst := New(0)
+	if _, err := dst.ReadFrom(dr); err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if !bytes.Equal(dst.Bytes(), payload) {
+		t.Errorf("round trip returned %d bytes, want %d", dst.LenNoPadding(), len(payload))
+	}
+}
+
+func TestCompressScratchPaths(t *testing.T) {
+	payload := strings.Repeat("0123456789", 5_000)
+
+	// A reader without WriteTo and a writer without ReadFrom go through
+	// the pooled scratch slice.
+	var disk bytes.Buffer
+	cw, _ := CompressTo(&disk, Gzip(gzip.DefaultCompression))
+	n, err := cw.ReadFrom(iotest.HalfReader(strings.NewReader(payload)))
+	if err != nil || n != int64(len(payload)) {
+		t.Fatalf("ReadFrom = %d, %v", n, err)
+	}
+	cw.Close()
+
+	dr, _ := DecompressFrom(&disk, Gzip(0))
+	var out strings.Builder
+	if _, err := dr.WriteTo(iotest.TruncateWriter(&out, 1<<30)); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if out.String() != payload {
+		t.Errorf("round trip returned %d bytes, want %d", out.Len(), len(payload))
+	}
+}
+
+func TestCompressClosed(t *testing.T) {
+	cw, _ := CompressTo(io.Discard, Gzip(gzip.BestSpeed))
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+	if _, err := cw.Write([]byte("x")); !errors.Is(err, ErrCodecClosed) {
+		t.Errorf("Write after Close = %v, want ErrCodecClosed", err)
+	}
+
+	var disk bytes.Buffer
+	cw, _ = CompressTo(&disk, Gzip(gzip.BestSpeed))
+	cw.Close()
+	dr, _ := DecompressFrom(&disk, Gzip(0))
+	dr.Close()
+	if _, err := dr.Read(make([]byte, 1)); !errors.Is(err, ErrCodecClosed) {
+		t.Errorf("Read after Close = %v, want ErrCodecClosed", err)
+	}
+}
+
+func TestDecompressBadHeader(t *testing.T) {
+	if _, err := DecompressFrom(strings.NewReader("not gzip"), Gzip(0)); err == nil {
+		t.Error("DecompressFrom accepted a malformed header")
+	}
+	if _, err := CompressTo(io.Discard, Gzip(42)); err == nil {
+		t.Error("CompressTo accepted an invalid level")
+	}
+}
@@ -7,10 +7,21 @@ const (
 	// headerSize is the number of bytes reserved for the length header of each block.
 	headerSize = 8
 
+	// metaSize is the number of bytes of the optional metadata word stored after the header.
+	metaSize = 8
+
+	// metaFlag is set in a block's length header when a metadata word follows it.
+	metaFlag = uint64(1) << 63
+
 	// sortChunkSize is the size of chunks used during SortSlice (merge sort).
 	// We pick pivots every sortChunkSize items.
 	sortChunkSize = 1024
 
+	// appendScratch is the stack scratch size of the Append* number
+	// formatters. It fits any integer in any base; a float in 'f' format past
+	// ~1e60 overflows it and costs one allocation.
+	appendScratch = 72
+
 	// maxGrowth is the maximum amount of bytes to grow by in a single step (1GB).
 	maxGrowth = 1 << 30
 )
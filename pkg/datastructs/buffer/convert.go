@@ -0,0 +1,38 @@
+package buffer
+
+// ToLinkedList moves the bytes written to b into a new LinkedListBuffer as a
+// single node, without copying, for a stage that goes on with a list. The
+// list takes b over: b must not be used afterwards, and once the node is
+// read, discarded or reset away the list calls b.Release, which returns a
+// pooled Buffer to its pool.
+//
+// The node holds what Bytes returns: the padding is left out, while the
+// length headers of WriteSlice framing stay in.
+func (b *Buffer) ToLinkedList() *LinkedListBuffer {
+	ll := &LinkedListBuffer{}
+	data := b.Bytes()
+	if len(data) == 0 {
+		_ = b.Release()
+		return ll
+	}
+	ll.pushBack(&node{data: data, release: func() { _ = b.Release() }})
+	return ll
+}
+
+// NewElasticFromRing returns an ElasticBuffer whose ring is rb, buffered
+// bytes included, so no copy is made at the switch. The static limit is
+// rb's current size, or the default ring size if smaller, and writes past
+// it overflow to the list as with NewElastic.
+//
+// The ElasticBuffer takes rb over: rb must not be used afterwards. A fixed
+// ring becomes growable, and a ring over caller memory (NewRingFrom) moves
+// to pooled storage if it grows; it is dropped rather than pooled once it
+// drains, so the memory never reaches another buffer.
+func NewElasticFromRing(rb *RingBuffer) *ElasticBuffer {
+	rb.fixed = false
+	rb.lastRead = 0
+	eb := &ElasticBuffer{maxStaticBytes: max(rb.Len(), defaultRingCap)}
+	eb.ring.ring = rb
+	eb.ring.returnIfEmpty()
+	return eb
+}
@@ -0,0 +1,118 @@
+package buffer
+
+import (
+	"io"
+	"testing"
+)
+
+// =============================================================================
+// Buffer.ToLinkedList
+// =============================================================================
+
+func TestBuffer_ToLinkedList(t *testing.T) {
+	b := New(0)
+	b.WriteString("hello world")
+	released := false
+	b.ReleaseFn = func() { released = true }
+	data := b.Bytes()
+
+	ll := b.ToLinkedList()
+	if ll.Buffered() != 11 || ll.Stats().Nodes != 1 {
+		t.Fatalf("Buffered, Nodes = %d, %d, want 11, 1", ll.Buffered(), ll.Stats().Nodes)
+	}
+	bufs, _ := ll.Peek(0)
+	if &bufs[0][0] != &data[0] {
+		t.Error("node does not share the Buffer's slab")
+	}
+
+	// Writes never land in the taken-over slab.
+	ll.Write([]byte("!"))
+	if ll.Stats().Nodes != 2 {
+		t.Errorf("Nodes after Write = %d, want 2", ll.Stats().Nodes)
+	}
+
+	p := make([]byte, 6)
+	ll.Read(p)
+	if released {
+		t.Fatal("Buffer released while the list still reads from it")
+	}
+	got, _ := io.ReadAll(ll)
+	if string(p)+string(got) != "hello world!" {
+		t.Errorf("read %q, want %q", string(p)+string(got), "hello world!")
+	}
+	if !released {
+		t.Error("Buffer not released once its node was read")
+	}
+}
+
+func TestBuffer_ToLinkedList_Pop(t *testing.T) {
+	b := New(0)
+	b.WriteString("abc")
+	released := false
+	b.ReleaseFn = func() { released = true }
+
+	ll := b.ToLinkedList()
+	if got := ll.Pop(); string(got) != "abc" || !released {
+		t.Errorf("Pop = %q, released %v, want %q, true", got, released, "abc")
+	}
+
+	empty := New(0)
+	if ll := empty.ToLinkedList(); !ll.IsEmpty() {
+		t.Error("empty Buffer gave a non-empty list")
+	}
+}
+
+// =============================================================================
+// NewElasticFromRing
+// =============================================================================
+
+func TestNewElasticFromRing(t *testing.T) {
+	rb := NewRing(2048)
+	rb.Write([]byte("buffered"))
+	slab := rb.buf
+
+	eb := NewElasticFromRing(rb)
+	if eb.ring.ring != rb || eb.Buffered() != 8 {
+		t.Fatalf("ring not adopted: Buffered = %d", eb.Buffered())
+	}
+	if eb.maxStaticBytes != 2048 {
+		t.Errorf("maxStaticBytes = %d, want 2048", eb.maxStaticBytes)
+	}
+
+	eb.Write([]byte(" more"))
+	if &eb.ring.ring.buf[0] != &slab[0] {
+		t.Error("ring storage was replaced")
+	}
+	got, _ := io.ReadAll(eb)
+	if string(got) != "buffered more" {
+		t.Errorf("read %q, want %q", got, "buffered more")
+	}
+}
+
+func TestNewElasticFromRing_External(t *testing.T) {
+	mem := make([]byte, 16)
+	rb := NewRingFrom(mem, false)
+	rb.Write([]byte("0123456789"))
+
+	eb := NewElasticFromRing(rb)
+	// A fixed ring becomes growable: the write neither fails nor is lost.
+	if n, err := eb.Write([]byte("abcdefghij")); n != 10 || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	got, _ := io.ReadAll(eb)
+	if string(got) != "0123456789abcdefghij" {
+		t.Errorf("read %q", got)
+	}
+
+	// Drained, a ring over caller memory is dropped, not pooled.
+	rb = NewRingFrom(mem, true)
+	eb = NewElasticFromRing(rb)
+	if eb.ring.ring != nil {
+		t.Fatal("empty adopted ring kept")
+	}
+	for range 8 {
+		if r := ringBufferPool.Get().(*RingBuffer); r.external {
+			t.Fatal("ring over caller memory reached the pool")
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"io"
 	"math"
+
+	"github.com/huynhanx03/go-common/pkg/utils"
 )
 
 // ErrNegativeSize is returned when attempting to create a buffer with invalid size.
@@ -16,6 +18,56 @@ type ElasticBuffer struct {
 	maxStaticBytes int
 	ring           ElasticRing
 	list           LinkedListBuffer
+	marks          watermarks
+}
+
+// watermarks is the flow-control state set by SetWatermarks; high == 0
+// disables it.
+type watermarks struct {
+	high, low     int
+	onHigh, onLow func()
+	above         bool // onHigh fired, onLow not yet
+}
+
+// SetWatermarks calls onHigh when a write brings Buffered() to high or more,
+// and onLow when reads bring it back to low or less, so a connection can
+// stop reading from its peer while its outbound buffer is full and resume
+// once it has drained. Each callback fires once per crossing, from inside
+// the call that crossed. Either may be nil.
+//
+// If the buffer already holds high bytes, onHigh fires right away. high must
+// be positive and low in [0, high); other values disable the watermarks.
+func (eb *ElasticBuffer) SetWatermarks(high, low int, onHigh, onLow func()) {
+	if high <= 0 || low < 0 || low >= high {
+		eb.marks = watermarks{}
+		return
+	}
+	eb.marks = watermarks{high: high, low: low, onHigh: onHigh, onLow: onLow}
+	eb.wrote()
+}
+
+// wrote fires onHigh if a write crossed the high watermark.
+func (eb *ElasticBuffer) wrote() {
+	m := &eb.marks
+	if m.high == 0 || m.above || eb.Buffered() < m.high {
+		return
+	}
+	m.above = true
+	if m.onHigh != nil {
+		m.onHigh()
+	}
+}
+
+// drained fires onLow if a read crossed the low watermark.
+func (eb *ElasticBuffer) drained() {
+	m := &eb.marks
+	if !m.above || eb.Buffered() > m.low {
+		return
+	}
+	m.above = false
+	if m.onLow != nil {
+		m.onLow()
+	}
 }
 
 // NewElastic creates a new ElasticBuffer with the given static byte limit.
@@ -33,6 +85,7 @@ func (eb *ElasticBuffer) Read(p []byte) (int, error) {
 	if len(p) == 0 {
 		return 0, nil
 	}
+	defer eb.drained()
 
 	ringRead, err := eb.ring.Read(p)
 	if ringRead == len(p) {
@@ -43,6 +96,20 @@ func (eb *ElasticBuffer) Read(p []byte) (int, error) {
 	return ringRead + listRead, err
 }
 
+// ReadByte implements io.ByteReader. Returns io.EOF when the buffer is empty.
+func (eb *ElasticBuffer) ReadByte() (byte, error) {
+	defer eb.drained()
+	if eb.ring.Buffered() > 0 {
+		return eb.ring.ReadByte()
+	}
+
+	var b [1]byte
+	if n, _ := eb.list.Read(b[:]); n == 0 {
+		return 0, io.EOF
+	}
+	return b[0], nil
+}
+
 // Peek returns up to n bytes as [][]byte without advancing read pointers.
 // If n <= 0, returns all buffered data.
 func (eb *ElasticBuffer) Peek(n int) ([][]byte, error) {
@@ -69,6 +136,7 @@ func (eb *ElasticBuffer) Discard(n int) (int, error) {
 	if n <= 0 {
 		return 0, nil
 	}
+	defer eb.drained()
 
 	ringDiscarded, err := eb.ring.Discard(n)
 	if ringDiscarded >= n {
@@ -87,6 +155,7 @@ func (eb *ElasticBuffer) Write(p []byte) (int, error) {
 	if dataLen == 0 {
 		return 0, nil
 	}
+	defer eb.wrote()
 
 	// Overflow mode: write directly to list
 	if eb.shouldOverflow() {
@@ -107,12 +176,29 @@ func (eb *ElasticBuffer) Write(p []byte) (int, error) {
 	return eb.ring.Write(p)
 }
 
+// WriteByte implements io.ByteWriter, placing c where Write would.
+func (eb *ElasticBuffer) WriteByte(c byte) error {
+	defer eb.wrote()
+	if eb.shouldOverflow() || eb.ring.Len() >= eb.maxStaticBytes && eb.ring.Available() == 0 {
+		b := [1]byte{c}
+		_, err := eb.list.Write(b[:])
+		return err
+	}
+	return eb.ring.WriteByte(c)
+}
+
+// WriteString implements io.StringWriter.
+func (eb *ElasticBuffer) WriteString(s string) (int, error) {
+	return eb.Write(utils.StringToBytes(s))
+}
+
 // Writev writes multiple byte slices to the buffer.
 // More efficient than multiple Write calls for scattered data.
 func (eb *ElasticBuffer) Writev(slices [][]byte) (int, error) {
 	if len(slices) == 0 {
 		return 0, nil
 	}
+	defer eb.wrote()
 
 	// Overflow mode: write all to list
 	if eb.shouldOverflow() {
@@ -179,15 +265,28 @@ func (eb *ElasticBuffer) shouldOverflow() bool {
 // ReadFrom implements io.ReaderFrom.
 // Reads from r until EOF, directing data to ring or list based on current state.
 func (eb *ElasticBuffer) ReadFrom(r io.Reader) (int64, error) {
+	defer eb.wrote()
 	if eb.shouldOverflow() {
 		return eb.list.ReadFrom(r)
 	}
 	return eb.ring.ReadFrom(r)
 }
 
+// ReadFromOnce calls r.Read exactly once, into the ring's free space
+// while it is under the static limit and into a pooled list node once it
+// overflows, and returns r's n and err unmodified, io.EOF included.
+func (eb *ElasticBuffer) ReadFromOnce(r io.Reader) (int, error) {
+	defer eb.wrote()
+	if eb.shouldOverflow() || eb.ring.Len() >= eb.maxStaticBytes && eb.ring.Available() == 0 {
+		return eb.list.ReadFromOnce(r)
+	}
+	return eb.ring.ReadFromOnce(r)
+}
+
 // WriteTo implements io.WriterTo.
 // Writes all buffered data to w, draining ring first then list.
 func (eb *ElasticBuffer) WriteTo(w io.Writer) (int64, error) {
+	defer eb.drained()
 	ringWritten, err := eb.ring.WriteTo(w)
 	if err != nil {
 		return ringWritten, err
@@ -215,6 +314,7 @@ func (eb *ElasticBuffer) Reset(maxStaticBytes int) {
 	if maxStaticBytes > 0 {
 		eb.maxStaticBytes = maxStaticBytes
 	}
+	eb.drained()
 }
 
 // Release frees all resources held by the buffer.
@@ -17,6 +17,11 @@ var ringBufferPool = sync.Pool{
 // This provides efficient memory reuse for short-lived buffers.
 type ElasticRing struct {
 	ring *RingBuffer
+
+	// lastByte is the final byte of a read that drained the ring and sent
+	// it back to the pool, kept so UnreadByte still works.
+	lastByte byte
+	hasLast  bool
 }
 
 // getOrCreate returns the underlying RingBuffer, creating one from pool if needed.
@@ -30,8 +35,18 @@ func (er *ElasticRing) getOrCreate() *RingBuffer {
 // returnIfEmpty returns the buffer to pool if it's empty.
 func (er *ElasticRing) returnIfEmpty() {
 	if er.ring != nil && er.ring.IsEmpty() {
-		ringBufferPool.Put(er.ring)
-		er.ring = nil
+		er.ring.Reset() // forget what the next owner could unread
+		er.putRing()
+		er.hasLast = false
+	}
+}
+
+// releaseDrained returns the ring to the pool if a read emptied it,
+// remembering last, the final byte read, for UnreadByte.
+func (er *ElasticRing) releaseDrained(last byte) {
+	if er.ring.IsEmpty() {
+		er.returnIfEmpty()
+		er.lastByte, er.hasLast = last, true
 	}
 }
 
@@ -42,7 +57,16 @@ func (er *ElasticRing) Done() {
 		return
 	}
 	er.ring.Reset()
-	ringBufferPool.Put(er.ring)
+	er.putRing()
+	er.hasLast = false
+}
+
+// putRing returns the ring to the pool, unless it is over caller memory
+// (see NewElasticFromRing), which must not reach another buffer.
+func (er *ElasticRing) putRing() {
+	if !er.ring.external {
+		ringBufferPool.Put(er.ring)
+	}
 	er.ring = nil
 }
 
@@ -71,8 +95,11 @@ func (er *ElasticRing) Read(p []byte) (int, error) {
 	if er.ring == nil {
 		return 0, ErrRingEmpty
 	}
-	defer er.returnIfEmpty()
-	return er.ring.Read(p)
+	n, err := er.ring.Read(p)
+	if n > 0 {
+		er.releaseDrained(p[n-1])
+	}
+	return n, err
 }
 
 // ReadByte reads and returns the next byte from the buffer.
@@ -80,8 +107,40 @@ func (er *ElasticRing) ReadByte() (byte, error) {
 	if er.ring == nil {
 		return 0, ErrRingEmpty
 	}
-	defer er.returnIfEmpty()
-	return er.ring.ReadByte()
+	b, err := er.ring.ReadByte()
+	if err == nil {
+		er.releaseDrained(b)
+	}
+	return b, err
+}
+
+// UnreadByte unreads the last byte read. It works even when that read
+// emptied the ring and returned it to the pool.
+func (er *ElasticRing) UnreadByte() error {
+	if er.ring != nil {
+		return er.ring.UnreadByte()
+	}
+	if !er.hasLast {
+		return ErrInvalidUnread
+	}
+	er.hasLast = false
+	return er.getOrCreate().WriteByte(er.lastByte)
+}
+
+// UnreadN unreads the last n bytes read, as RingBuffer.UnreadN. Once a read
+// has emptied the ring its storage is back in the pool, so only UnreadN(1)
+// is possible then.
+func (er *ElasticRing) UnreadN(n int) error {
+	if er.ring != nil {
+		return er.ring.UnreadN(n)
+	}
+	switch n {
+	case 0:
+		return nil
+	case 1:
+		return er.UnreadByte()
+	}
+	return ErrInvalidUnread
 }
 
 // Write implements io.Writer.
@@ -138,6 +197,15 @@ func (er *ElasticRing) Cap() int {
 	return er.ring.Cap()
 }
 
+// EnsureCapacity makes room for n more bytes with at most one grow,
+// taking a ring from the pool if there is none yet.
+func (er *ElasticRing) EnsureCapacity(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	return er.getOrCreate().EnsureCapacity(n)
+}
+
 // Bytes returns a copy of all buffered data.
 func (er *ElasticRing) Bytes() []byte {
 	if er.ring == nil {
@@ -152,6 +220,13 @@ func (er *ElasticRing) ReadFrom(r io.Reader) (int64, error) {
 	return er.getOrCreate().ReadFrom(r)
 }
 
+// ReadFromOnce calls r.Read exactly once, as RingBuffer.ReadFromOnce.
+// The ring goes back to the pool if the read left it empty.
+func (er *ElasticRing) ReadFromOnce(r io.Reader) (int, error) {
+	defer er.returnIfEmpty()
+	return er.getOrCreate().ReadFromOnce(r)
+}
+
 // WriteTo implements io.WriterTo.
 // Writes all buffered data to w.
 func (er *ElasticRing) WriteTo(w io.Writer) (int64, error) {
@@ -162,6 +237,16 @@ func (er *ElasticRing) WriteTo(w io.Writer) (int64, error) {
 	return er.ring.WriteTo(w)
 }
 
+// WriteToN writes at most max buffered bytes to w and keeps the rest.
+// Intended for event loops draining up to a socket's send window per turn.
+func (er *ElasticRing) WriteToN(w io.Writer, max int) (int64, error) {
+	if er.ring == nil {
+		return 0, nil
+	}
+	defer er.returnIfEmpty()
+	return er.ring.WriteToN(w, max)
+}
+
 // IsFull returns true if the buffer is full.
 func (er *ElasticRing) IsFull() bool {
 	if er.ring == nil {
@@ -6,6 +6,8 @@ import (
 	"io"
 	"strings"
 	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/testutil/iotest"
 )
 
 // =============================================================================
@@ -393,6 +395,56 @@ func TestElasticRing_ReadByte_PoolReturn(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// Method: UnreadByte() / UnreadN()
+// =============================================================================
+
+func TestElasticRing_UnreadByte_AfterPoolReturn(t *testing.T) {
+	er := &ElasticRing{}
+	defer er.Done()
+	er.Write([]byte("AB"))
+	er.ReadByte()
+	er.ReadByte()
+	if er.ring != nil {
+		t.Fatal("ring should be back in the pool after the last byte")
+	}
+
+	if err := er.UnreadByte(); err != nil {
+		t.Fatalf("UnreadByte() error = %v", err)
+	}
+	if b, err := er.ReadByte(); err != nil || b != 'B' {
+		t.Errorf("ReadByte() = %c, %v; want B, nil", b, err)
+	}
+	if err := er.UnreadN(2); !errors.Is(err, ErrInvalidUnread) {
+		t.Errorf("UnreadN(2) after pool return = %v; want ErrInvalidUnread", err)
+	}
+}
+
+func TestElasticRing_UnreadN(t *testing.T) {
+	er := &ElasticRing{}
+	defer er.Done()
+	er.Write([]byte("hello"))
+
+	p := make([]byte, 3)
+	er.Read(p)
+	if err := er.UnreadN(3); err != nil {
+		t.Fatalf("UnreadN(3) error = %v", err)
+	}
+	if got := string(er.Bytes()); got != "hello" {
+		t.Errorf("Bytes() = %q; want hello", got)
+	}
+}
+
+func TestElasticRing_UnreadNotLeakedThroughPool(t *testing.T) {
+	er := &ElasticRing{}
+	er.Write([]byte("secret"))
+	er.WriteTo(io.Discard) // drains and returns the ring to the pool
+
+	if err := er.UnreadByte(); !errors.Is(err, ErrInvalidUnread) {
+		t.Errorf("UnreadByte() after WriteTo = %v; want ErrInvalidUnread", err)
+	}
+}
+
 // =============================================================================
 // Method: Write()
 // =============================================================================
@@ -656,6 +708,30 @@ func TestElasticRing_Cap(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// Method: EnsureCapacity()
+// =============================================================================
+
+func TestElasticRing_EnsureCapacity(t *testing.T) {
+	er := &ElasticRing{}
+	defer er.Done()
+	if err := er.EnsureCapacity(0); err != nil || er.Cap() != 0 {
+		t.Errorf("EnsureCapacity(0) = %v, Cap() = %d; want nil, 0", err, er.Cap())
+	}
+
+	if err := er.EnsureCapacity(5000); err != nil {
+		t.Fatal(err)
+	}
+	if er.Available() < 5000 {
+		t.Fatalf("Available() = %d, want >= 5000", er.Available())
+	}
+	grown := er.Cap()
+	er.Write(bytes.Repeat([]byte("x"), 5000))
+	if er.Cap() != grown || er.Buffered() != 5000 {
+		t.Errorf("Cap() = %d, Buffered() = %d; want %d, 5000", er.Cap(), er.Buffered(), grown)
+	}
+}
+
 // =============================================================================
 // Method: Bytes()
 // =============================================================================
@@ -752,15 +828,9 @@ func TestElasticRing_ReadFrom_Empty(t *testing.T) {
 	}
 }
 
-type errReader struct{}
-
-func (errReader) Read(p []byte) (int, error) {
-	return 0, errors.New("read error")
-}
-
 func TestElasticRing_ReadFrom_Error(t *testing.T) {
 	er := &ElasticRing{}
-	_, err := er.ReadFrom(errReader{})
+	_, err := er.ReadFrom(iotest.ErrReader(nil))
 	if err == nil {
 		t.Error("expected error from ReadFrom")
 	}
@@ -824,17 +894,11 @@ func TestElasticRing_WriteTo_NilRing(t *testing.T) {
 	}
 }
 
-type errWriter struct{}
-
-func (errWriter) Write(p []byte) (int, error) {
-	return 0, errors.New("write error")
-}
-
 func TestElasticRing_WriteTo_Error(t *testing.T) {
 	er := &ElasticRing{}
 	er.Write([]byte("data"))
 
-	_, err := er.WriteTo(errWriter{})
+	_, err := er.WriteTo(iotest.ErrWriter(nil))
 	if err == nil {
 		t.Error("expected error from WriteTo")
 	}
@@ -853,6 +917,83 @@ func TestElasticRing_WriteTo_PoolReturn(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// Method: WriteToN()
+// =============================================================================
+
+func TestElasticRing_WriteToN(t *testing.T) {
+	er := &ElasticRing{}
+	er.Write([]byte("hello world"))
+
+	var dst bytes.Buffer
+	n, err := er.WriteToN(&dst, 5)
+	if err != nil {
+		t.Fatalf("WriteToN error: %v", err)
+	}
+	if n != 5 || dst.String() != "hello" {
+		t.Errorf("WriteToN = %d, dst = %q, want 5, hello", n, dst.String())
+	}
+	if er.Buffered() != 6 {
+		t.Errorf("Buffered() = %d, want 6", er.Buffered())
+	}
+
+	// Draining the rest returns the ring to the pool.
+	er.WriteToN(&dst, 100)
+	if dst.String() != "hello world" {
+		t.Errorf("dst = %q, want hello world", dst.String())
+	}
+	if er.ring != nil {
+		t.Error("ring should be nil after draining all data")
+	}
+}
+
+func TestElasticRing_WriteToN_WrapAround(t *testing.T) {
+	er := &ElasticRing{ring: NewRing(8)}
+	er.Write([]byte("xxxxab"))
+	er.Discard(4)
+	er.Write([]byte("cdefg")) // wraps around the end of the ring
+
+	var dst bytes.Buffer
+	n, err := er.WriteToN(&dst, 5)
+	if err != nil || n != 5 || dst.String() != "abcde" {
+		t.Fatalf("WriteToN = %d, %v, dst = %q, want 5, nil, abcde", n, err, dst.String())
+	}
+
+	n, _ = er.WriteToN(&dst, 5)
+	if n != 2 || dst.String() != "abcdefg" {
+		t.Errorf("WriteToN = %d, dst = %q, want 2, abcdefg", n, dst.String())
+	}
+}
+
+func TestElasticRing_WriteToN_ShortWrite(t *testing.T) {
+	er := &ElasticRing{}
+	er.Write([]byte("data"))
+
+	n, err := er.WriteToN(iotest.NewWriter(nil, iotest.WithMaxChunk(1)), 4)
+	if err != nil {
+		t.Errorf("err = %v, want nil on short write", err)
+	}
+	if n != 1 {
+		t.Errorf("n = %d, want 1", n)
+	}
+	if er.Buffered() != 3 {
+		t.Errorf("Buffered() = %d, want 3", er.Buffered())
+	}
+}
+
+func TestElasticRing_WriteToN_NonPositive(t *testing.T) {
+	er := &ElasticRing{}
+	er.Write([]byte("data"))
+
+	var dst bytes.Buffer
+	if n, err := er.WriteToN(&dst, 0); n != 0 || err != nil {
+		t.Errorf("WriteToN(0) = %d, %v, want 0, nil", n, err)
+	}
+	if er.Buffered() != 4 {
+		t.Errorf("Buffered() = %d, want 4", er.Buffered())
+	}
+}
+
 // =============================================================================
 // Method: IsFull()
 // =============================================================================
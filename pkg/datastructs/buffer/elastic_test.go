@@ -5,6 +5,8 @@ import (
 	"errors"
 	"io"
 	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/testutil/iotest"
 )
 
 // =============================================================================
@@ -15,6 +17,9 @@ var _ io.Reader = (*ElasticBuffer)(nil)
 var _ io.Writer = (*ElasticBuffer)(nil)
 var _ io.ReaderFrom = (*ElasticBuffer)(nil)
 var _ io.WriterTo = (*ElasticBuffer)(nil)
+var _ io.ByteReader = (*ElasticBuffer)(nil)
+var _ io.ByteWriter = (*ElasticBuffer)(nil)
+var _ io.StringWriter = (*ElasticBuffer)(nil)
 
 // =============================================================================
 // Method: NewElastic()
@@ -50,6 +55,60 @@ func TestElastic_NewElastic(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// Method: ReadByte() / WriteByte() / WriteString()
+// =============================================================================
+
+func TestElastic_ByteAndStringIO(t *testing.T) {
+	eb, _ := NewElastic(4)
+	defer eb.Release()
+
+	// Bytes and strings cross the ring/list boundary in write order.
+	for _, c := range []byte("abc") {
+		if err := eb.WriteByte(c); err != nil {
+			t.Fatalf("WriteByte(%q) error = %v", c, err)
+		}
+	}
+	if n, err := eb.WriteString("defg"); n != 4 || err != nil {
+		t.Fatalf("WriteString() = %d, %v; want 4, nil", n, err)
+	}
+	_ = eb.WriteByte('h')
+	if eb.list.IsEmpty() {
+		t.Fatal("expected data to overflow into the list")
+	}
+
+	// Reading part of the ring must not let new writes jump the list.
+	if c, err := eb.ReadByte(); c != 'a' || err != nil {
+		t.Fatalf("ReadByte() = %q, %v; want 'a', nil", c, err)
+	}
+	_ = eb.WriteByte('i')
+
+	var got []byte
+	for {
+		c, err := eb.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadByte() error = %v", err)
+		}
+		got = append(got, c)
+	}
+	if string(got) != "bcdefghi" {
+		t.Errorf("ReadByte sequence = %q; want %q", got, "bcdefghi")
+	}
+	if !eb.IsEmpty() {
+		t.Error("IsEmpty() after draining = false; want true")
+	}
+}
+
+func TestElastic_ReadByteEmpty(t *testing.T) {
+	eb, _ := NewElastic(16)
+	if _, err := eb.ReadByte(); err != io.EOF {
+		t.Errorf("ReadByte() on empty buffer error = %v; want io.EOF", err)
+	}
+}
+
 // =============================================================================
 // Method: Read()
 // =============================================================================
@@ -562,7 +621,7 @@ func TestElastic_ReadFrom(t *testing.T) {
 
 	t.Run("error_reader", func(t *testing.T) {
 		eb, _ := NewElastic(100)
-		reader := errorReader{}
+		reader := iotest.ErrReader(nil)
 
 		_, err := eb.ReadFrom(reader)
 		if err == nil {
@@ -588,6 +647,55 @@ func TestElastic_ReadFrom(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Method: ReadFromOnce()
+// =============================================================================
+
+func TestElastic_ReadFromOnce(t *testing.T) {
+	t.Run("ring", func(t *testing.T) {
+		eb, _ := NewElastic(1024)
+		r := iotest.NewReader(bytes.NewReader([]byte("hello")), iotest.WithMaxChunk(2))
+
+		n, err := eb.ReadFromOnce(r)
+		if n != 2 || err != nil {
+			t.Fatalf("ReadFromOnce = %d, %v; want 2, nil", n, err)
+		}
+		if r.Calls() != 1 {
+			t.Errorf("Read calls = %d, want 1", r.Calls())
+		}
+		if eb.list.Buffered() != 0 {
+			t.Errorf("list holds %d bytes, want 0", eb.list.Buffered())
+		}
+	})
+
+	t.Run("overflow_to_list", func(t *testing.T) {
+		eb, _ := NewElastic(10)
+		_, _ = eb.Write(make([]byte, 10))
+		_, _ = eb.Write([]byte("list"))
+
+		n, err := eb.ReadFromOnce(lastReader("tail"))
+		if n != 4 || err != io.EOF {
+			t.Fatalf("ReadFromOnce = %d, %v; want 4, io.EOF", n, err)
+		}
+		if eb.list.Buffered() != 8 {
+			t.Errorf("list holds %d bytes, want 8", eb.list.Buffered())
+		}
+		if eb.Buffered() != 18 {
+			t.Errorf("Buffered = %d, want 18", eb.Buffered())
+		}
+	})
+
+	t.Run("eof_empty", func(t *testing.T) {
+		eb, _ := NewElastic(100)
+		if n, err := eb.ReadFromOnce(bytes.NewReader(nil)); n != 0 || err != io.EOF {
+			t.Errorf("ReadFromOnce = %d, %v; want 0, io.EOF", n, err)
+		}
+		if !eb.IsEmpty() {
+			t.Error("IsEmpty = false after empty read")
+		}
+	})
+}
+
 // =============================================================================
 // Method: WriteTo()
 // =============================================================================
@@ -643,7 +751,7 @@ func TestElastic_WriteTo(t *testing.T) {
 		eb, _ := NewElastic(100)
 		_, _ = eb.Write([]byte("data"))
 
-		writer := errorWriter{}
+		writer := iotest.ErrWriter(nil)
 
 		_, err := eb.WriteTo(writer)
 		if err == nil {
@@ -856,6 +964,81 @@ func TestElastic_Release(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Method: SetWatermarks()
+// =============================================================================
+
+func TestElastic_SetWatermarks(t *testing.T) {
+	eb, err := NewElastic(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eb.Release()
+
+	var highs, lows int
+	eb.SetWatermarks(32, 8, func() { highs++ }, func() { lows++ })
+
+	eb.Write(make([]byte, 20))
+	if highs != 0 {
+		t.Fatalf("onHigh fired below the high watermark")
+	}
+	eb.Write(make([]byte, 20)) // 40 buffered, spills to the list
+	eb.WriteByte('x')
+	if highs != 1 {
+		t.Fatalf("onHigh fired %d times, want 1", highs)
+	}
+
+	eb.Discard(20) // 21 buffered, above low
+	if lows != 0 {
+		t.Fatalf("onLow fired above the low watermark")
+	}
+	io.CopyN(io.Discard, eb, 15) // 6 buffered
+	if lows != 1 {
+		t.Fatalf("onLow fired %d times, want 1", lows)
+	}
+	eb.ReadByte()
+	if lows != 1 {
+		t.Errorf("onLow fired again without a new high crossing")
+	}
+
+	eb.Writev([][]byte{make([]byte, 20), make([]byte, 20)})
+	eb.WriteTo(io.Discard)
+	if highs != 2 || lows != 2 {
+		t.Errorf("second crossing: highs=%d lows=%d, want 2 and 2", highs, lows)
+	}
+}
+
+func TestElastic_SetWatermarks_Immediate(t *testing.T) {
+	eb, _ := NewElastic(16)
+	defer eb.Release()
+	eb.Write(make([]byte, 64))
+
+	high := false
+	eb.SetWatermarks(32, 0, func() { high = true }, nil)
+	if !high {
+		t.Error("onHigh did not fire for a buffer already above high")
+	}
+
+	low := false
+	eb.SetWatermarks(32, 0, nil, func() { low = true })
+	eb.Reset(0)
+	if !low {
+		t.Error("onLow did not fire on Reset")
+	}
+}
+
+func TestElastic_SetWatermarks_Invalid(t *testing.T) {
+	eb, _ := NewElastic(16)
+	defer eb.Release()
+
+	fired := false
+	eb.SetWatermarks(8, 8, func() { fired = true }, nil)
+	eb.Write(make([]byte, 64))
+	if fired {
+		t.Error("watermarks with low == high should be disabled")
+	}
+}
+
 // =============================================================================
 // Sequence Tests
 // =============================================================================
@@ -928,5 +1111,3 @@ func TestElastic_Workflow_OverflowMode(t *testing.T) {
 		t.Fatal("IsEmpty() after WriteTo = false; want true")
 	}
 }
-
-// Test helpers (errorReader and errorWriter) are defined in buffer_test.go
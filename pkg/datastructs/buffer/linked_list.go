@@ -1,18 +1,38 @@
 package buffer
 
 import (
+	"cmp"
 	"io"
 	"math"
+	"slices"
 
 	"github.com/huynhanx03/go-common/pkg/pool/byteslice"
+	"github.com/huynhanx03/go-common/pkg/utils/bytesx"
 )
 
 const minReadChunkSize = 512
 
+// maxNodeSize caps the nodes Write allocates, so one large Write does not
+// pin a single huge pooled slice.
+const maxNodeSize = 64 << 10
+
 // node represents a single node in the linked list buffer.
 type node struct {
 	data []byte
 	next *node
+
+	// owned marks data allocated by the buffer from the pool, whose spare
+	// capacity Write may fill. Append'ed slices belong to the caller.
+	owned bool
+
+	// mem is the whole slice behind data, which reads may have advanced
+	// past the start of: the pooled slice of owned nodes, the appended one
+	// of others.
+	mem []byte
+
+	// release, if set, gives data back to the buffer it was taken over
+	// from (see Buffer.ToLinkedList) instead of to the pool.
+	release func()
 }
 
 // length returns the byte length of this node's data.
@@ -27,6 +47,158 @@ type LinkedListBuffer struct {
 	tail      *node
 	nodeCount int
 	byteCount int
+
+	coalesceBelow int // see SetCoalesceThreshold; 0 disables
+	readChunk     int // see SetReadChunkSize; 0 = minReadChunkSize
+
+	// Reserved nodes, see Preallocate: zeroed, empty, at least spareSize
+	// bytes each. spareBytes sums their capacity.
+	spare      []*node
+	spareNodes int // nodes the reservation is topped up to
+	spareSize  int
+	spareBytes int
+	appends    uint64
+	coalesced  uint64
+}
+
+// LinkedListStats reports how appends turned into nodes. Appends is the
+// node count they would cost without coalescing, Appends-Coalesced what
+// they cost with it.
+type LinkedListStats struct {
+	Nodes     int    // nodes currently held
+	Appends   uint64 // Append and PushBack calls and ReadFrom reads with data
+	Coalesced uint64 // of those, copied into the tail node's spare capacity
+}
+
+// SetCoalesceThreshold turns on coalescing: Append, PushBack and ReadFrom
+// reads of fewer than threshold bytes are copied into the tail node's spare
+// capacity when they fit, and otherwise start a pooled node with room for
+// more, instead of costing a node each. Many tiny appends then make few
+// nodes, so Peek returns fewer slices and the pool sees less churn. Append
+// is no longer zero-copy below the threshold, and node boundaries no longer
+// follow the appends. threshold <= 0 disables it.
+func (ll *LinkedListBuffer) SetCoalesceThreshold(threshold int) {
+	ll.coalesceBelow = max(threshold, 0)
+}
+
+// SetReadChunkSize sets the size of the pooled node each read of ReadFrom
+// and ReadFromOnce fills, at most 64 KiB. Match it to the reads expected,
+// e.g. a socket's receive size, so one read takes one node. size <= 0
+// restores the default of 512 bytes.
+func (ll *LinkedListBuffer) SetReadChunkSize(size int) {
+	ll.readChunk = min(max(size, 0), maxNodeSize)
+}
+
+// Preallocate reserves totalBytes of zeroed pooled nodes of nodeSize bytes
+// up front, so that a latency-sensitive phase does not allocate: Write,
+// PushBack, PushFront, coalescing and ReadFrom take a reserved node when
+// they need a node of at most nodeSize, and nodes the buffer frees go back
+// to the reservation until it is full again. nodeSize <= 0 means the read
+// chunk size; it is capped at 64 KiB. It replaces any earlier reservation,
+// and totalBytes <= 0 returns the reservation to the pool.
+func (ll *LinkedListBuffer) Preallocate(totalBytes, nodeSize int) {
+	for _, n := range ll.spare {
+		byteslice.Put(n.mem)
+	}
+	clear(ll.spare)
+	ll.spare = ll.spare[:0]
+	ll.spareNodes, ll.spareSize, ll.spareBytes = 0, 0, 0
+	if totalBytes <= 0 {
+		return
+	}
+
+	if nodeSize <= 0 {
+		nodeSize = cmp.Or(ll.readChunk, minReadChunkSize)
+	}
+	ll.spareSize = min(nodeSize, maxNodeSize)
+	ll.spareNodes = (totalBytes + ll.spareSize - 1) / ll.spareSize
+	ll.spare = slices.Grow(ll.spare, ll.spareNodes)
+	for range ll.spareNodes {
+		mem := byteslice.GetZeroed(ll.spareSize)
+		ll.spare = append(ll.spare, &node{data: mem[:0], owned: true, mem: mem})
+		ll.spareBytes += cap(mem)
+	}
+}
+
+// Reserved returns the bytes held in reserved nodes, ready for use.
+func (ll *LinkedListBuffer) Reserved() int {
+	return ll.spareBytes
+}
+
+// newNode returns an owned node of length n with room for at least size
+// bytes: a reserved one if size fits, else one from the pool, zeroed if
+// zeroed is set. Reserved nodes are always zeroed.
+func (ll *LinkedListBuffer) newNode(n, size int, zeroed bool) *node {
+	if k := len(ll.spare); k > 0 && size <= ll.spareSize {
+		nd := ll.spare[k-1]
+		ll.spare[k-1] = nil
+		ll.spare = ll.spare[:k-1]
+		ll.spareBytes -= cap(nd.mem)
+		nd.data = nd.mem[:n]
+		return nd
+	}
+	var buf []byte
+	if zeroed {
+		buf = byteslice.GetZeroed(size)
+	} else {
+		buf = byteslice.Get(size)
+	}
+	return &node{data: buf[:n], owned: true, mem: buf}
+}
+
+// free gives the memory of a node taken off the list back: to the
+// reservation while it is short of nodes, else to the pool.
+func (ll *LinkedListBuffer) free(n *node) {
+	if n.release != nil {
+		n.release()
+		return
+	}
+	if !n.owned {
+		byteslice.Put(n.data)
+		return
+	}
+	if len(ll.spare) < ll.spareNodes && cap(n.mem) >= ll.spareSize {
+		mem := n.mem[:cap(n.mem)]
+		clear(mem)
+		n.data = mem[:0]
+		ll.spare = append(ll.spare, n)
+		ll.spareBytes += cap(mem)
+		return
+	}
+	byteslice.Put(n.mem)
+}
+
+// Stats returns node usage counters, kept across Reset.
+func (ll *LinkedListBuffer) Stats() LinkedListStats {
+	return LinkedListStats{Nodes: ll.nodeCount, Appends: ll.appends, Coalesced: ll.coalesced}
+}
+
+// coalesce appends a copy of p to the buffer without a node of its own if
+// coalescing applies to it: into the tail's spare capacity, or into a new
+// pooled node sized for the appends that follow. It reports whether it did.
+func (ll *LinkedListBuffer) coalesce(p []byte) bool {
+	if len(p) >= ll.coalesceBelow {
+		return false
+	}
+	if ll.fitTail(p) {
+		return true
+	}
+	n := ll.newNode(len(p), max(len(p), minReadChunkSize), false)
+	copy(n.data, p)
+	ll.pushBack(n)
+	return true
+}
+
+// fitTail copies p into the tail node's spare capacity if it fits.
+func (ll *LinkedListBuffer) fitTail(p []byte) bool {
+	t := ll.tail
+	if t == nil || !t.owned || min(cap(t.data), maxNodeSize)-len(t.data) < len(p) {
+		return false
+	}
+	t.data = append(t.data, p...)
+	ll.byteCount += len(p)
+	ll.coalesced++
+	return true
 }
 
 // Read implements io.Reader.
@@ -46,7 +218,7 @@ func (ll *LinkedListBuffer) Read(p []byte) (int, error) {
 			n.data = n.data[copied:]
 			ll.pushFront(n)
 		} else {
-			byteslice.Put(n.data)
+			ll.free(n)
 		}
 
 		if totalRead == len(p) {
@@ -60,9 +232,9 @@ func (ll *LinkedListBuffer) Read(p []byte) (int, error) {
 	return totalRead, nil
 }
 
-// AllocNode allocates a []byte from the pool.
+// AllocNode allocates a zeroed []byte from the pool.
 func (ll *LinkedListBuffer) AllocNode(size int) []byte {
-	return byteslice.Get(size)
+	return byteslice.GetZeroed(size)
 }
 
 // FreeNode returns a []byte to the pool.
@@ -76,16 +248,28 @@ func (ll *LinkedListBuffer) Append(p []byte) {
 	if len(p) == 0 {
 		return
 	}
-	ll.pushBack(&node{data: p})
+	ll.appends++
+	if ll.coalesce(p) {
+		return
+	}
+	ll.pushBack(&node{data: p, mem: p})
 }
 
 // Pop removes and returns the head buffer.
-// Caller is responsible for returning the buffer to the pool.
+// Caller is responsible for returning the buffer to the pool. A node taken
+// over from a Buffer is not the pool's to receive: Pop returns a pooled copy
+// of it and releases the Buffer.
 func (ll *LinkedListBuffer) Pop() []byte {
 	n := ll.popFront()
 	if n == nil {
 		return nil
 	}
+	if n.release != nil {
+		p := byteslice.Get(len(n.data))[:len(n.data)]
+		copy(p, n.data)
+		n.release()
+		return p
+	}
 	return n.data
 }
 
@@ -96,9 +280,38 @@ func (ll *LinkedListBuffer) PushFront(p []byte) {
 		return
 	}
 
-	buf := byteslice.Get(dataLen)
-	copy(buf, p)
-	ll.pushFront(&node{data: buf})
+	n := ll.newNode(dataLen, dataLen, false)
+	copy(n.data, p)
+	ll.pushFront(n)
+}
+
+// Write implements io.Writer by copying p to the tail. It never fails.
+// Small writes fill the spare capacity of the tail node before a new one is
+// taken from the pool, and large ones are split into nodes of at most 64 KiB,
+// or of the Preallocate node size while reserved nodes last.
+func (ll *LinkedListBuffer) Write(p []byte) (int, error) {
+	total := len(p)
+
+	if t := ll.tail; t != nil && t.owned {
+		if room := min(cap(t.data), maxNodeSize) - len(t.data); room > 0 {
+			n := min(room, len(p))
+			t.data = append(t.data, p[:n]...)
+			ll.byteCount += n
+			p = p[n:]
+		}
+	}
+
+	for len(p) > 0 {
+		n := min(len(p), maxNodeSize)
+		if len(ll.spare) > 0 {
+			n = min(n, ll.spareSize) // fill reserved nodes first
+		}
+		nd := ll.newNode(n, max(n, minReadChunkSize), false)
+		copy(nd.data, p)
+		ll.pushBack(nd)
+		p = p[n:]
+	}
+	return total, nil
 }
 
 // PushBack copies p and adds it to the tail.
@@ -107,10 +320,14 @@ func (ll *LinkedListBuffer) PushBack(p []byte) {
 	if dataLen == 0 {
 		return
 	}
+	ll.appends++
+	if ll.coalesce(p) {
+		return
+	}
 
-	buf := byteslice.Get(dataLen)
-	copy(buf, p)
-	ll.pushBack(&node{data: buf})
+	n := ll.newNode(dataLen, dataLen, false)
+	copy(n.data, p)
+	ll.pushBack(n)
 }
 
 // Peek returns up to maxBytes as [][]byte without advancing the read position.
@@ -186,9 +403,47 @@ func (ll *LinkedListBuffer) collectBytes(maxBytes int, existing [][]byte) [][]by
 	return result
 }
 
+// IndexByte returns the offset of the first c in the buffered data, or -1.
+func (ll *LinkedListBuffer) IndexByte(c byte) int {
+	offset := 0
+	for n := ll.head; n != nil; n = n.next {
+		if i := bytesx.IndexByte(n.data, c); i >= 0 {
+			return offset + i
+		}
+		offset += n.length()
+	}
+	return -1
+}
+
+// CountByte returns the number of c in the buffered data.
+func (ll *LinkedListBuffer) CountByte(c byte) int {
+	count := 0
+	for n := ll.head; n != nil; n = n.next {
+		count += bytesx.CountByte(n.data, c)
+	}
+	return count
+}
+
 // Discard skips n bytes from the buffer.
 // Returns the number of bytes actually discarded.
 func (ll *LinkedListBuffer) Discard(n int) (int, error) {
+	return ll.DiscardWith(n, nil)
+}
+
+// DiscardWith skips n bytes like Discard, but hands each node it drops
+// whole to recycle instead of the pool, so a caller that appended slices
+// it owns, e.g. io_uring registered buffers, gets them back. recycle
+// receives the slice as appended, or the pooled slice behind a node the
+// buffer allocated, whatever was read from it; it then owns the slice. A
+// node taken over from a Buffer is released to it as usual. A nil recycle
+// is Discard.
+//
+// Only nodes DiscardWith drops come back. Slices Append copied under the
+// coalesce threshold never reach recycle: the caller keeps them as soon as
+// Append returns. Nodes that Read, Discard or Pop consume whole leave the
+// buffer as those methods say, pooled for Read and Discard, so consume a
+// buffer holding such slices with Peek and DiscardWith.
+func (ll *LinkedListBuffer) DiscardWith(n int, recycle func([]byte)) (int, error) {
 	if n <= 0 {
 		return 0, nil
 	}
@@ -214,7 +469,11 @@ func (ll *LinkedListBuffer) Discard(n int) (int, error) {
 		// Full discard of this node
 		remaining -= nodeLen
 		discarded += nodeLen
-		byteslice.Put(current.data)
+		if recycle != nil && current.release == nil {
+			recycle(current.mem)
+		} else {
+			ll.free(current)
+		}
 	}
 
 	return discarded, nil
@@ -224,28 +483,47 @@ func (ll *LinkedListBuffer) Discard(n int) (int, error) {
 // Reads data from r until EOF and appends it to the buffer.
 func (ll *LinkedListBuffer) ReadFrom(r io.Reader) (int64, error) {
 	var total int64
-
 	for {
-		buf := byteslice.Get(minReadChunkSize)
-		bytesRead, err := r.Read(buf)
-		if bytesRead < 0 {
-			panic("linkedlist: reader returned negative count")
-		}
-
-		total += int64(bytesRead)
-		buf = buf[:bytesRead]
-
+		n, err := ll.ReadFromOnce(r)
+		total += int64(n)
 		if err == io.EOF {
-			byteslice.Put(buf)
 			return total, nil
 		}
 		if err != nil {
-			byteslice.Put(buf)
 			return total, err
 		}
+	}
+}
 
-		ll.pushBack(&node{data: buf})
+// ReadFromOnce calls r.Read exactly once, straight into a pooled node of
+// the read chunk size, and buffers what it returns. n and err are r's,
+// io.EOF included, so an event loop reading a socket decides when to read
+// again instead of the buffer looping to EOF. A short read is coalesced
+// like an Append when SetCoalesceThreshold covers it.
+func (ll *LinkedListBuffer) ReadFromOnce(r io.Reader) (int, error) {
+	size := ll.readChunk
+	if size == 0 {
+		size = minReadChunkSize
+	}
+	// Zeroed: r sees the slice, and must not see earlier pool users' data.
+	nd := ll.newNode(size, size, true)
+	n, err := r.Read(nd.data)
+	if n < 0 {
+		panic("linkedlist: reader returned negative count")
+	}
+	if n == 0 {
+		ll.free(nd)
+		return 0, err
+	}
+
+	ll.appends++
+	if n < ll.coalesceBelow && ll.fitTail(nd.data[:n]) {
+		ll.free(nd)
+	} else {
+		nd.data = nd.data[:n]
+		ll.pushBack(nd)
 	}
+	return n, err
 }
 
 // WriteTo implements io.WriterTo.
@@ -268,7 +546,7 @@ func (ll *LinkedListBuffer) WriteTo(w io.Writer) (int64, error) {
 			return total, io.ErrShortWrite
 		}
 
-		byteslice.Put(current.data)
+		ll.free(current)
 	}
 
 	return total, nil
@@ -289,10 +567,11 @@ func (ll *LinkedListBuffer) IsEmpty() bool {
 	return ll.head == nil
 }
 
-// Reset clears the buffer and returns all memory to the pool.
+// Reset clears the buffer and returns its memory to the pool, but for
+// what refills the Preallocate reservation.
 func (ll *LinkedListBuffer) Reset() {
 	for current := ll.popFront(); current != nil; current = ll.popFront() {
-		byteslice.Put(current.data)
+		ll.free(current)
 	}
 	ll.head = nil
 	ll.tail = nil
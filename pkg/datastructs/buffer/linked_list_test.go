@@ -2,10 +2,11 @@ package buffer
 
 import (
 	"bytes"
-	"errors"
 	"io"
 	"math"
 	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/testutil/iotest"
 )
 
 // =============================================================================
@@ -15,41 +16,7 @@ import (
 var _ io.Reader = (*LinkedListBuffer)(nil)
 var _ io.ReaderFrom = (*LinkedListBuffer)(nil)
 var _ io.WriterTo = (*LinkedListBuffer)(nil)
-
-// =============================================================================
-// Test Helpers
-// =============================================================================
-
-// llErrorReader returns an error on Read.
-type llErrorReader struct{}
-
-func (r llErrorReader) Read(p []byte) (int, error) {
-	return 0, errors.New("read error")
-}
-
-// negativeReader returns negative count (should cause panic).
-type negativeReader struct{}
-
-func (r negativeReader) Read(p []byte) (int, error) {
-	return -1, nil
-}
-
-// errorWriter returns an error on Write.
-type llErrorWriter struct{}
-
-func (w llErrorWriter) Write(p []byte) (int, error) {
-	return 0, errors.New("write error")
-}
-
-// shortWriter writes less than provided.
-type shortWriter struct{}
-
-func (w shortWriter) Write(p []byte) (int, error) {
-	if len(p) > 1 {
-		return 1, nil // Always write only 1 byte
-	}
-	return len(p), nil
-}
+var _ io.Writer = (*LinkedListBuffer)(nil)
 
 // =============================================================================
 // Method: Read()
@@ -622,6 +589,35 @@ func TestLinkedListBuffer_PeekWithBytes(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Method: IndexByte() / CountByte()
+// =============================================================================
+
+func TestLinkedListBuffer_IndexByte(t *testing.T) {
+	ll := &LinkedListBuffer{}
+	defer ll.Reset()
+	if got := ll.IndexByte(','); got != -1 {
+		t.Errorf("IndexByte(empty) = %d, want -1", got)
+	}
+
+	ll.PushBack([]byte("abc"))
+	ll.PushBack([]byte("de,f"))
+	ll.PushBack([]byte(",g"))
+
+	if got := ll.IndexByte(','); got != 5 {
+		t.Errorf("IndexByte(',') = %d, want 5", got)
+	}
+	if got := ll.IndexByte('g'); got != 8 {
+		t.Errorf("IndexByte('g') = %d, want 8", got)
+	}
+	if got := ll.IndexByte('z'); got != -1 {
+		t.Errorf("IndexByte('z') = %d, want -1", got)
+	}
+	if got := ll.CountByte(','); got != 2 {
+		t.Errorf("CountByte(',') = %d, want 2", got)
+	}
+}
+
 // =============================================================================
 // Method: Discard()
 // =============================================================================
@@ -732,6 +728,77 @@ func TestLinkedListBuffer_Discard(t *testing.T) {
 	})
 }
 
+func TestLinkedListBuffer_DiscardWith(t *testing.T) {
+	a, b := []byte("aaaa"), []byte("bbbb")
+	ll := &LinkedListBuffer{}
+	ll.Append(a)
+	ll.Append(b)
+	ll.Write([]byte("cccc"))
+	ll.Read(make([]byte, 2)) // a is recycled whole all the same
+
+	var recycled [][]byte
+	n, err := ll.DiscardWith(8, func(p []byte) { recycled = append(recycled, p) })
+	if err != nil || n != 8 {
+		t.Fatalf("DiscardWith = %d, %v, want 8", n, err)
+	}
+	if len(recycled) != 2 || &recycled[0][0] != &a[0] || len(recycled[0]) != 4 || &recycled[1][0] != &b[0] {
+		t.Fatalf("recycled %q, want the appended slices", recycled)
+	}
+	if got, _ := io.ReadAll(ll); string(got) != "cc" {
+		t.Errorf("remaining = %q, want %q", got, "cc")
+	}
+
+	// Pooled nodes are handed over too; a taken-over Buffer is released.
+	ll.Write([]byte("dd"))
+	src := New(0)
+	src.WriteString("ee")
+	released := false
+	src.ReleaseFn = func() { released = true }
+	taken := src.ToLinkedList()
+	recycled = nil
+	ll.DiscardWith(2, func(p []byte) { recycled = append(recycled, p) })
+	taken.DiscardWith(2, func(p []byte) { recycled = append(recycled, p) })
+	if len(recycled) != 1 || string(recycled[0][:2]) != "dd" {
+		t.Errorf("recycled %q, want the pooled node", recycled)
+	}
+	if !released {
+		t.Error("taken-over Buffer not released")
+	}
+}
+
+func TestLinkedListBuffer_DiscardWithCoalesced(t *testing.T) {
+	ll := &LinkedListBuffer{}
+	ll.SetCoalesceThreshold(64)
+	small := []byte("tiny")
+	ll.Append(small)
+	copy(small, "XXXX") // copied on Append: the caller already has it back
+
+	var recycled [][]byte
+	ll.DiscardWith(4, func(p []byte) { recycled = append(recycled, p) })
+	for _, p := range recycled {
+		if &p[:1][0] == &small[0] {
+			t.Fatal("coalesced slice handed to recycle")
+		}
+	}
+	if len(recycled) != 1 || string(recycled[0][:4]) != "tiny" {
+		t.Errorf("recycled %q, want only the pooled node holding the copy", recycled)
+	}
+}
+
+func TestLinkedListBuffer_DiscardWithAfterRead(t *testing.T) {
+	a, b := []byte("aaaa"), []byte("bbbb")
+	ll := &LinkedListBuffer{}
+	ll.Append(a)
+	ll.Append(b)
+	ll.Read(make([]byte, 4)) // consumes a whole: it leaves by Read's rules
+
+	var recycled [][]byte
+	ll.DiscardWith(4, func(p []byte) { recycled = append(recycled, p) })
+	if len(recycled) != 1 || &recycled[0][0] != &b[0] {
+		t.Errorf("recycled %q, want only b", recycled)
+	}
+}
+
 // =============================================================================
 // Method: ReadFrom()
 // =============================================================================
@@ -768,7 +835,7 @@ func TestLinkedListBuffer_ReadFrom(t *testing.T) {
 
 	t.Run("error_reader", func(t *testing.T) {
 		ll := &LinkedListBuffer{}
-		_, err := ll.ReadFrom(llErrorReader{})
+		_, err := ll.ReadFrom(iotest.ErrReader(nil))
 		if err == nil {
 			t.Error("expected error")
 		}
@@ -797,7 +864,152 @@ func TestLinkedListBuffer_ReadFrom_PanicNegativeRead(t *testing.T) {
 	}()
 
 	ll := &LinkedListBuffer{}
-	ll.ReadFrom(negativeReader{})
+	ll.ReadFrom(iotest.NegativeReader())
+}
+
+// =============================================================================
+// Method: ReadFromOnce()
+// =============================================================================
+
+// lastReader returns its data together with io.EOF, as a connection can
+// on its final read.
+type lastReader []byte
+
+func (r lastReader) Read(p []byte) (int, error) {
+	return copy(p, r), io.EOF
+}
+
+func TestLinkedListBuffer_ReadFromOnce(t *testing.T) {
+	t.Run("one_read", func(t *testing.T) {
+		ll := &LinkedListBuffer{}
+		ll.SetReadChunkSize(4)
+		r := iotest.NewReader(bytes.NewReader([]byte("hello world")))
+
+		n, err := ll.ReadFromOnce(r)
+		if n != 4 || err != nil {
+			t.Fatalf("ReadFromOnce = %d, %v; want 4, nil", n, err)
+		}
+		if r.Calls() != 1 {
+			t.Errorf("Read calls = %d, want 1", r.Calls())
+		}
+		if got, _ := io.ReadAll(ll); string(got) != "hell" {
+			t.Errorf("Bytes = %q, want %q", got, "hell")
+		}
+	})
+
+	t.Run("eof_unmodified", func(t *testing.T) {
+		ll := &LinkedListBuffer{}
+		n, err := ll.ReadFromOnce(bytes.NewReader(nil))
+		if n != 0 || err != io.EOF {
+			t.Errorf("ReadFromOnce = %d, %v; want 0, io.EOF", n, err)
+		}
+		if ll.Stats().Nodes != 0 {
+			t.Errorf("Nodes = %d, want 0", ll.Stats().Nodes)
+		}
+	})
+
+	t.Run("data_with_eof", func(t *testing.T) {
+		ll := &LinkedListBuffer{}
+		n, err := ll.ReadFromOnce(lastReader("bye"))
+		if n != 3 || err != io.EOF {
+			t.Fatalf("ReadFromOnce = %d, %v; want 3, io.EOF", n, err)
+		}
+		if got, _ := io.ReadAll(ll); string(got) != "bye" {
+			t.Errorf("Bytes = %q, want %q", got, "bye")
+		}
+
+		// ReadFrom keeps the final chunk too.
+		ll.Reset()
+		if n, err := ll.ReadFrom(lastReader("bye")); n != 3 || err != nil {
+			t.Errorf("ReadFrom = %d, %v; want 3, nil", n, err)
+		}
+	})
+
+	t.Run("coalesces", func(t *testing.T) {
+		ll := &LinkedListBuffer{}
+		ll.SetCoalesceThreshold(64)
+		ll.Write(make([]byte, 10))
+		ll.ReadFromOnce(lastReader("abc"))
+		if st := ll.Stats(); st.Nodes != 1 {
+			t.Errorf("Nodes = %d, want 1 (short read coalesced)", st.Nodes)
+		}
+	})
+
+	t.Run("chunk_size_bounds", func(t *testing.T) {
+		ll := &LinkedListBuffer{}
+		ll.SetReadChunkSize(1 << 30)
+		n, _ := ll.ReadFromOnce(bytes.NewReader(make([]byte, 1<<17)))
+		if n != maxNodeSize {
+			t.Errorf("ReadFromOnce = %d, want %d (capped)", n, maxNodeSize)
+		}
+		ll.SetReadChunkSize(-1)
+		n, _ = ll.ReadFromOnce(bytes.NewReader(make([]byte, 1<<17)))
+		if n != minReadChunkSize {
+			t.Errorf("ReadFromOnce = %d, want %d (default)", n, minReadChunkSize)
+		}
+	})
+}
+
+// =============================================================================
+// Method: Write()
+// =============================================================================
+
+func TestLinkedListBuffer_Write(t *testing.T) {
+	t.Run("coalesces_small_writes", func(t *testing.T) {
+		ll := &LinkedListBuffer{}
+		defer ll.Reset()
+
+		var want bytes.Buffer
+		for i := range 1000 {
+			b := []byte{byte(i)}
+			if n, err := ll.Write(b); n != 1 || err != nil {
+				t.Fatalf("Write = %d, %v; want 1, nil", n, err)
+			}
+			want.Write(b)
+		}
+		if ll.Len() > 2 {
+			t.Errorf("Len = %d after 1000 one-byte writes, want <= 2", ll.Len())
+		}
+		if got, _ := io.ReadAll(ll); !bytes.Equal(got, want.Bytes()) {
+			t.Error("data mismatch after coalesced writes")
+		}
+	})
+
+	t.Run("splits_large_writes", func(t *testing.T) {
+		ll := &LinkedListBuffer{}
+		defer ll.Reset()
+
+		data := bytes.Repeat([]byte("0123456789"), 20000)
+		if n, err := ll.Write(data); n != len(data) || err != nil {
+			t.Fatalf("Write = %d, %v", n, err)
+		}
+		if want := (len(data) + maxNodeSize - 1) / maxNodeSize; ll.Len() != want {
+			t.Errorf("Len = %d, want %d", ll.Len(), want)
+		}
+		bufs, _ := ll.Peek(0)
+		for _, b := range bufs {
+			if len(b) > maxNodeSize {
+				t.Errorf("node of %d bytes exceeds maxNodeSize", len(b))
+			}
+		}
+		if got, _ := io.ReadAll(ll); !bytes.Equal(got, data) {
+			t.Error("data mismatch after split write")
+		}
+	})
+
+	t.Run("never_writes_into_appended_slices", func(t *testing.T) {
+		ll := &LinkedListBuffer{}
+		backing := []byte("abc_____")
+		ll.Append(backing[:3])
+		ll.Write([]byte("def"))
+
+		if string(backing) != "abc_____" {
+			t.Errorf("Write modified an appended slice: %q", backing)
+		}
+		if got, _ := io.ReadAll(ll); string(got) != "abcdef" {
+			t.Errorf("ReadAll = %q, want abcdef", got)
+		}
+	})
 }
 
 // =============================================================================
@@ -841,7 +1053,7 @@ func TestLinkedListBuffer_WriteTo(t *testing.T) {
 		ll := &LinkedListBuffer{}
 		ll.PushBack([]byte("data"))
 
-		_, err := ll.WriteTo(llErrorWriter{})
+		_, err := ll.WriteTo(iotest.ErrWriter(nil))
 		if err == nil {
 			t.Error("expected error")
 		}
@@ -851,7 +1063,7 @@ func TestLinkedListBuffer_WriteTo(t *testing.T) {
 		ll := &LinkedListBuffer{}
 		ll.PushBack([]byte("hello"))
 
-		_, err := ll.WriteTo(shortWriter{})
+		_, err := ll.WriteTo(iotest.NewWriter(nil, iotest.WithMaxChunk(1)))
 		if err != io.ErrShortWrite {
 			t.Errorf("err = %v, want ErrShortWrite", err)
 		}
@@ -1126,3 +1338,159 @@ func TestLinkedListBuffer_Workflow_ProducerConsumer(t *testing.T) {
 		t.Errorf("after reuse, Buffered = %d, want 6", ll.Buffered())
 	}
 }
+
+// =============================================================================
+// Coalescing
+// =============================================================================
+
+func TestLinkedListBuffer_Coalesce(t *testing.T) {
+	var plain, merged LinkedListBuffer
+	merged.SetCoalesceThreshold(64)
+	defer plain.Reset()
+	defer merged.Reset()
+
+	var want []byte
+	for i := range 100 {
+		p := []byte{byte(i), byte(i + 1), byte(i + 2)}
+		want = append(want, p...)
+		plain.PushBack(p)
+		merged.PushBack(p)
+	}
+
+	if plain.Len() != 100 {
+		t.Fatalf("plain Len = %d, want 100", plain.Len())
+	}
+	s := merged.Stats()
+	if s.Appends != 100 || s.Nodes != merged.Len() || s.Nodes > 2 {
+		t.Errorf("coalesced Stats = %+v", s)
+	}
+	if s.Appends-s.Coalesced != uint64(s.Nodes) {
+		t.Errorf("Stats = %+v: Appends-Coalesced should count the nodes taken", s)
+	}
+
+	vecs, _ := merged.Peek(0)
+	if got := bytes.Join(vecs, nil); !bytes.Equal(got, want) {
+		t.Errorf("coalesced data = %v, want %v", got, want)
+	}
+}
+
+func TestLinkedListBuffer_CoalesceLargeAndAppend(t *testing.T) {
+	var ll LinkedListBuffer
+	ll.SetCoalesceThreshold(16)
+	defer ll.Reset()
+
+	big := bytes.Repeat([]byte("b"), 100)
+	ll.PushBack([]byte("a"))
+	ll.Append(big) // at the threshold: zero-copy node of its own
+	ll.Append([]byte("c"))
+
+	if ll.Len() != 3 {
+		t.Errorf("Len = %d, want 3 (caller slice stays its own node)", ll.Len())
+	}
+	if s := ll.Stats(); s.Coalesced != 0 {
+		t.Errorf("Coalesced = %d, want 0: the tail was caller-owned", s.Coalesced)
+	}
+	ll.PushBack([]byte("d"))
+	if s := ll.Stats(); s.Coalesced != 1 || ll.Len() != 3 {
+		t.Errorf("Stats = %+v, Len = %d", s, ll.Len())
+	}
+
+	got, _ := io.ReadAll(&ll)
+	if want := "a" + string(big) + "cd"; string(got) != want {
+		t.Errorf("data = %q, want %q", got, want)
+	}
+}
+
+func TestLinkedListBuffer_CoalesceReadFrom(t *testing.T) {
+	var ll LinkedListBuffer
+	ll.SetCoalesceThreshold(minReadChunkSize)
+	defer ll.Reset()
+
+	data := bytes.Repeat([]byte("xyz"), 100)
+	if _, err := ll.ReadFrom(iotest.NewReader(bytes.NewReader(data), iotest.WithMaxChunk(7))); err != nil {
+		t.Fatal(err)
+	}
+	if ll.Len() > 2 {
+		t.Errorf("Len = %d after 7-byte reads, want them coalesced", ll.Len())
+	}
+	got, _ := io.ReadAll(&ll)
+	if !bytes.Equal(got, data) {
+		t.Error("data mismatch after coalesced ReadFrom")
+	}
+}
+
+// =============================================================================
+// Preallocation
+// =============================================================================
+
+func TestLinkedListBuffer_Preallocate(t *testing.T) {
+	var ll LinkedListBuffer
+	defer ll.Preallocate(0, 0)
+	ll.Preallocate(10_000, 4096)
+	if got := ll.Reserved(); got < 12_288 {
+		t.Fatalf("Reserved = %d, want 3 nodes of 4 KiB", got)
+	}
+	full := ll.Reserved()
+
+	ll.Write(bytes.Repeat([]byte("a"), 5000))
+	if ll.Len() != 2 || ll.Reserved() >= full {
+		t.Errorf("Len = %d, Reserved = %d: Write should take reserved nodes", ll.Len(), ll.Reserved())
+	}
+
+	got, _ := io.ReadAll(&ll)
+	if len(got) != 5000 || ll.Reserved() != full {
+		t.Errorf("read %d bytes, Reserved = %d; want 5000, %d", len(got), ll.Reserved(), full)
+	}
+
+	// Reused nodes are handed to readers zeroed.
+	ll.Write([]byte("secret"))
+	ll.Discard(6)
+	n, _ := ll.ReadFromOnce(bytes.NewReader(nil))
+	if n != 0 || ll.Reserved() != full {
+		t.Errorf("empty read: n = %d, Reserved = %d", n, ll.Reserved())
+	}
+	var seen []byte
+	ll.ReadFromOnce(readerFunc(func(p []byte) (int, error) {
+		seen = append(seen, p...)
+		return 0, io.EOF
+	}))
+	if bytes.Contains(seen, []byte("secret")) {
+		t.Error("a reserved node reached a reader with earlier data")
+	}
+
+	ll.Write([]byte("x"))
+	ll.Reset()
+	if ll.Reserved() != full {
+		t.Errorf("Reserved after Reset = %d, want %d", ll.Reserved(), full)
+	}
+	ll.Preallocate(0, 0)
+	if ll.Reserved() != 0 {
+		t.Errorf("Reserved after release = %d, want 0", ll.Reserved())
+	}
+}
+
+func TestLinkedListBuffer_PreallocateZeroAlloc(t *testing.T) {
+	var ll LinkedListBuffer
+	defer ll.Preallocate(0, 0)
+	ll.Preallocate(64<<10, 16<<10)
+
+	msg := bytes.Repeat([]byte("m"), 20<<10)
+	out := make([]byte, 32<<10)
+	r := bytes.NewReader(nil)
+	allocs := testing.AllocsPerRun(100, func() {
+		ll.Write(msg)
+		r.Reset(msg)
+		ll.ReadFromOnce(r)
+		for !ll.IsEmpty() {
+			ll.Read(out)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per cycle = %v, want 0 within the reservation", allocs)
+	}
+}
+
+// readerFunc adapts a function to io.Reader.
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
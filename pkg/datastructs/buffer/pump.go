@@ -0,0 +1,204 @@
+package buffer
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+)
+
+// ErrPumpClosed is returned by writes to a closed FlushPump.
+var ErrPumpClosed = errors.New("flush pump is closed")
+
+// Drainable is a buffer a FlushPump can fill and drain, such as
+// *LinkedListBuffer or *ElasticBuffer.
+type Drainable interface {
+	io.Writer
+	io.WriterTo
+	Buffered() int
+}
+
+// PumpOption configures a FlushPump.
+type PumpOption func(*pumpConfig)
+
+type pumpConfig struct {
+	highWater int
+	lowWater  int
+	onError   func(error)
+	meter     metrics.Meter
+}
+
+// WithWatermarks enables backpressure: Write blocks once high bytes are
+// buffered and resumes when the pump has drained below low.
+func WithWatermarks(high, low int) PumpOption {
+	return func(c *pumpConfig) {
+		if high > 0 && low >= 0 && low < high {
+			c.highWater, c.lowWater = high, low
+		}
+	}
+}
+
+// WithErrorHandler sets the callback invoked once when draining fails.
+func WithErrorHandler(fn func(error)) PumpOption {
+	return func(c *pumpConfig) { c.onError = fn }
+}
+
+// WithMeter reports the pump's activity to m: "buffer.pump.drained_bytes",
+// "buffer.pump.drain_errors", "buffer.pump.stalls" for writes held back by
+// the high watermark, and the bytes waiting after each write or drain as
+// "buffer.pump.buffered".
+func WithMeter(m metrics.Meter) PumpOption {
+	return func(c *pumpConfig) { c.meter = m }
+}
+
+// pumpMetrics are the instruments of a FlushPump.
+type pumpMetrics struct {
+	drained     metrics.Counter
+	drainErrors metrics.Counter
+	stalls      metrics.Counter
+	buffered    metrics.Gauge
+}
+
+// FlushPump drains a buffer into an io.Writer from a background goroutine.
+//
+// Writes go through the pump, which appends them to the buffer and wakes the
+// drain loop. The first drain error stops the pump: it is reported to the
+// error handler and returned by every later Write, Flush and Close.
+// Unlike the buffers it wraps, a FlushPump is safe for concurrent use; the
+// buffer must not be touched directly while the pump is running.
+type FlushPump struct {
+	buf    Drainable
+	w      io.Writer
+	config pumpConfig
+
+	mu      sync.Mutex
+	cond    *sync.Cond // signals new data, drained data and shutdown
+	closed  bool
+	err     error
+	pausing bool // above high watermark, waiting for low
+	done    chan struct{}
+	metrics pumpMetrics
+}
+
+// NewFlushPump starts a pump draining buf into w.
+func NewFlushPump(buf Drainable, w io.Writer, opts ...PumpOption) *FlushPump {
+	var cfg pumpConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	p := &FlushPump{
+		buf:    buf,
+		w:      w,
+		config: cfg,
+		done:   make(chan struct{}),
+	}
+	m := metrics.OrNoop(cfg.meter)
+	p.metrics = pumpMetrics{
+		drained:     m.Counter("buffer.pump.drained_bytes", "Bytes written to the destination"),
+		drainErrors: m.Counter("buffer.pump.drain_errors", "Drains that failed and stopped the pump"),
+		stalls:      m.Counter("buffer.pump.stalls", "Writes that waited for the pump to drain"),
+		buffered:    m.Gauge("buffer.pump.buffered", "Bytes waiting to be drained"),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	go p.run()
+	return p
+}
+
+// Write appends data to the buffer, blocking while the pump is above its
+// high watermark.
+func (p *FlushPump) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pausing {
+		p.metrics.stalls.Add(1)
+	}
+	for p.err == nil && !p.closed && p.pausing {
+		p.cond.Wait()
+	}
+	if p.err != nil {
+		return 0, p.err
+	}
+	if p.closed {
+		return 0, ErrPumpClosed
+	}
+
+	n, err := p.buf.Write(data)
+	buffered := p.buf.Buffered()
+	p.metrics.buffered.Set(float64(buffered))
+	if p.config.highWater > 0 && buffered >= p.config.highWater {
+		p.pausing = true
+	}
+	p.cond.Broadcast()
+	return n, err
+}
+
+// Buffered returns the number of bytes waiting to be drained.
+func (p *FlushPump) Buffered() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.buf.Buffered()
+}
+
+// Flush blocks until everything written so far has been drained.
+func (p *FlushPump) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.err == nil && p.buf.Buffered() > 0 {
+		p.cond.Wait()
+	}
+	return p.err
+}
+
+// Close stops accepting writes, drains what is buffered and stops the pump.
+func (p *FlushPump) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	<-p.done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// run is the drain loop. The buffer is only drained with p.mu held, so
+// writers wait while an underlying Write to w is in progress.
+func (p *FlushPump) run() {
+	defer close(p.done)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		for p.buf.Buffered() == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if p.buf.Buffered() == 0 {
+			return // closed and drained
+		}
+
+		n, err := p.buf.WriteTo(p.w)
+		p.metrics.drained.Add(n)
+		buffered := p.buf.Buffered()
+		p.metrics.buffered.Set(float64(buffered))
+		if buffered <= p.config.lowWater {
+			p.pausing = false
+		}
+		if err != nil {
+			p.metrics.drainErrors.Add(1)
+			p.err = err
+			p.cond.Broadcast()
+			if p.config.onError != nil {
+				p.config.onError(err)
+			}
+			return
+		}
+		p.cond.Broadcast()
+	}
+}
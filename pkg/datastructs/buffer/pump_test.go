@@ -0,0 +1,229 @@
+package buffer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+	"github.com/huynhanx03/go-common/pkg/testutil/iotest"
+)
+
+// =============================================================================
+// Interface Compliance (compile-time)
+// =============================================================================
+
+var _ Drainable = (*LinkedListBuffer)(nil)
+var _ Drainable = (*ElasticBuffer)(nil)
+var _ io.WriteCloser = (*FlushPump)(nil)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+// syncWriter is a bytes.Buffer safe to inspect while the pump writes to it.
+type syncWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *syncWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// gateWriter blocks every Write until the gate is opened.
+type gateWriter struct {
+	gate chan struct{}
+	syncWriter
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return w.syncWriter.Write(p)
+}
+
+// =============================================================================
+// Method: Write() / Flush() / Close()
+// =============================================================================
+
+func TestFlushPump_Drain(t *testing.T) {
+	eb, err := NewElastic(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eb.Release()
+
+	tests := []struct {
+		name string
+		buf  Drainable
+	}{
+		{"LinkedList", &LinkedListBuffer{}},
+		{"Elastic", eb},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &syncWriter{}
+			p := NewFlushPump(tt.buf, w)
+
+			var want bytes.Buffer
+			for i := range 100 {
+				chunk := bytes.Repeat([]byte{byte('a' + i%26)}, i+1)
+				want.Write(chunk)
+				if _, err := p.Write(chunk); err != nil {
+					t.Fatalf("Write: %v", err)
+				}
+			}
+			if err := p.Flush(); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+			if p.Buffered() != 0 {
+				t.Errorf("Buffered() = %d after Flush, want 0", p.Buffered())
+			}
+			if w.String() != want.String() {
+				t.Errorf("drained %d bytes, want %d", len(w.String()), want.Len())
+			}
+			if err := p.Close(); err != nil {
+				t.Errorf("Close: %v", err)
+			}
+		})
+	}
+}
+
+func TestFlushPump_CloseDrainsRemainder(t *testing.T) {
+	w := &gateWriter{gate: make(chan struct{})}
+	p := NewFlushPump(&LinkedListBuffer{}, w)
+
+	p.Write([]byte("hello "))
+	p.Write([]byte("world"))
+
+	closed := make(chan error)
+	go func() { closed <- p.Close() }()
+	close(w.gate)
+
+	if err := <-closed; err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := w.String(); got != "hello world" {
+		t.Errorf("drained %q, want %q", got, "hello world")
+	}
+	if _, err := p.Write([]byte("x")); !errors.Is(err, ErrPumpClosed) {
+		t.Errorf("Write after Close = %v, want ErrPumpClosed", err)
+	}
+}
+
+func TestFlushPump_Backpressure(t *testing.T) {
+	w := &gateWriter{gate: make(chan struct{})}
+	p := NewFlushPump(&LinkedListBuffer{}, w, WithWatermarks(8, 0))
+	defer p.Close()
+
+	// Reaches the high watermark; the pump is stuck on the gate.
+	if _, err := p.Write(make([]byte, 8)); err != nil {
+		t.Fatal(err)
+	}
+
+	wrote := make(chan struct{})
+	go func() {
+		p.Write([]byte("x"))
+		close(wrote)
+	}()
+
+	select {
+	case <-wrote:
+		t.Fatal("Write did not block above the high watermark")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(w.gate)
+	select {
+	case <-wrote:
+	case <-time.After(time.Second):
+		t.Fatal("Write still blocked after the pump drained")
+	}
+}
+
+func TestFlushPump_Error(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		reported []error
+	)
+	p := NewFlushPump(&LinkedListBuffer{}, iotest.ErrWriter(nil), WithErrorHandler(func(err error) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+	}))
+
+	p.Write([]byte("data"))
+	if err := p.Flush(); err == nil {
+		t.Fatal("Flush succeeded on a failing writer")
+	}
+	if _, err := p.Write([]byte("more")); err == nil {
+		t.Error("Write succeeded after a drain error")
+	}
+	if err := p.Close(); err == nil {
+		t.Error("Close did not return the drain error")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 {
+		t.Errorf("error handler called %d times, want 1", len(reported))
+	}
+}
+
+func TestFlushPump_Meter(t *testing.T) {
+	m := metrics.NewMemory()
+	p := NewFlushPump(&LinkedListBuffer{}, &syncWriter{}, WithMeter(m))
+	p.Write([]byte("hello "))
+	p.Write([]byte("world"))
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.CounterValue("buffer.pump.drained_bytes"); got != 11 {
+		t.Errorf("drained_bytes = %d, want 11", got)
+	}
+	if got := m.GaugeValue("buffer.pump.buffered"); got != 0 {
+		t.Errorf("buffered = %v after Close, want 0", got)
+	}
+
+	m = metrics.NewMemory()
+	p = NewFlushPump(&LinkedListBuffer{}, iotest.ErrWriter(nil), WithMeter(m))
+	p.Write([]byte("data"))
+	p.Close()
+	if got := m.CounterValue("buffer.pump.drain_errors"); got != 1 {
+		t.Errorf("drain_errors = %d, want 1", got)
+	}
+}
+
+func TestFlushPump_ConcurrentWriters(t *testing.T) {
+	w := &syncWriter{}
+	p := NewFlushPump(&LinkedListBuffer{}, w, WithWatermarks(64, 16))
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 100 {
+				p.Write([]byte("0123456789"))
+			}
+		})
+	}
+	wg.Wait()
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(w.String()); got != 8*100*10 {
+		t.Errorf("drained %d bytes, want %d", got, 8*100*10)
+	}
+}
@@ -0,0 +1,117 @@
+package buffer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+)
+
+var (
+	// ErrCorruptRecord is reported by RecordIterator at the first record
+	// whose frame is truncated or fails its checksum.
+	ErrCorruptRecord = errors.New("buffer: corrupt record")
+
+	// ErrRecordTooLarge is returned by WriteRecord for payloads whose length
+	// does not fit the 32-bit frame header.
+	ErrRecordTooLarge = errors.New("buffer: record too large")
+)
+
+// recordHeaderSize is the frame header of a record: payload length and
+// CRC-32C, both big-endian uint32. It is the diskqueue segment framing, so
+// a flushed log can be replayed by either.
+const recordHeaderSize = 8
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WriteRecord appends p as a checksummed record, turning the buffer into an
+// in-memory log segment: [length (4)] [crc32c (4)] [payload]. Records are
+// read back with RecordIterator. Do not mix them with length-prefixed
+// slices in the same buffer.
+func (b *Buffer) WriteRecord(p []byte) error {
+	if uint64(len(p)) > math.MaxUint32 {
+		return ErrRecordTooLarge
+	}
+	dst := b.Allocate(recordHeaderSize + len(p))
+	binary.BigEndian.PutUint32(dst, uint32(len(p)))
+	binary.BigEndian.PutUint32(dst[4:], crc32.Checksum(p, crc32cTable))
+	copy(dst[recordHeaderSize:], p)
+	return nil
+}
+
+// RecordIterator replays the records of a Buffer in write order. It stops
+// at the end of the data or at the first corrupt record, whichever comes
+// first; nothing past a corrupt record is trusted.
+type RecordIterator struct {
+	b      *Buffer
+	pos    int // start of the next record
+	record []byte
+	err    error
+}
+
+// RecordIterator returns an iterator over the records written by
+// WriteRecord. Writing to the buffer while iterating is not supported.
+func (b *Buffer) RecordIterator() *RecordIterator {
+	return &RecordIterator{b: b, pos: b.StartOffset()}
+}
+
+// Next advances to the next record and reports whether there is one.
+func (it *RecordIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.record = nil
+
+	end := it.b.Len()
+	if it.pos == end {
+		return false
+	}
+	data := it.b.data[it.pos:end]
+	if len(data) < recordHeaderSize {
+		return it.corrupt("truncated header")
+	}
+	n := uint64(binary.BigEndian.Uint32(data))
+	if n > uint64(len(data)-recordHeaderSize) {
+		return it.corrupt("truncated payload")
+	}
+	payload := data[recordHeaderSize : recordHeaderSize+n : recordHeaderSize+n]
+	if crc32.Checksum(payload, crc32cTable) != binary.BigEndian.Uint32(data[4:]) {
+		return it.corrupt("checksum mismatch")
+	}
+
+	it.record = payload
+	it.pos += recordHeaderSize + int(n)
+	return true
+}
+
+func (it *RecordIterator) corrupt(reason string) bool {
+	it.err = fmt.Errorf("%w at offset %d: %s", ErrCorruptRecord, it.pos, reason)
+	return false
+}
+
+// Record returns the payload of the current record. It aliases the buffer
+// and stays valid until the buffer is written to or reset.
+func (it *RecordIterator) Record() []byte {
+	return it.record
+}
+
+// Err returns the error that stopped the iteration, wrapping
+// ErrCorruptRecord, or nil if it reached the end of the data.
+func (it *RecordIterator) Err() error {
+	return it.err
+}
+
+// Offset returns the buffer offset just past the last good record: the end
+// of the valid prefix once the iteration has stopped.
+func (it *RecordIterator) Offset() int {
+	return it.pos
+}
+
+// Truncate drops everything from the first record not yet returned by Next,
+// typically the corrupt tail after Next returned false, so new records
+// are appended to the valid prefix.
+func (it *RecordIterator) Truncate() {
+	it.b.offset = uint64(it.pos)
+	it.err = nil
+}
@@ -0,0 +1,129 @@
+package buffer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// writeRecords writes n records "record-<i>" and returns them.
+func writeRecords(t *testing.T, b *Buffer, n int) [][]byte {
+	t.Helper()
+	var want [][]byte
+	for i := range n {
+		p := []byte(fmt.Sprintf("record-%d", i))
+		if err := b.WriteRecord(p); err != nil {
+			t.Fatalf("WriteRecord: %v", err)
+		}
+		want = append(want, p)
+	}
+	return want
+}
+
+// readRecords replays b and returns copies of the records and the error.
+func readRecords(b *Buffer) ([][]byte, error) {
+	var got [][]byte
+	it := b.RecordIterator()
+	for it.Next() {
+		got = append(got, bytes.Clone(it.Record()))
+	}
+	return got, it.Err()
+}
+
+// =============================================================================
+// Method: WriteRecord() / RecordIterator()
+// =============================================================================
+
+func TestRecord_RoundTrip(t *testing.T) {
+	b := New(64)
+	want := writeRecords(t, b, 50)
+	if err := b.WriteRecord(nil); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, nil)
+
+	got, err := readRecords(b)
+	if err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("read %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("record %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRecord_Empty(t *testing.T) {
+	got, err := readRecords(New(64))
+	if len(got) != 0 || err != nil {
+		t.Errorf("empty buffer = (%d records, %v), want (0, nil)", len(got), err)
+	}
+}
+
+func TestRecord_Corruption(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(b *Buffer, second int)
+	}{
+		{"flipped_payload_bit", func(b *Buffer, second int) {
+			b.data[second+recordHeaderSize] ^= 0x01
+		}},
+		{"bad_length", func(b *Buffer, second int) {
+			b.data[second] = 0xFF
+		}},
+		{"torn_header", func(b *Buffer, second int) {
+			b.offset = uint64(second + 3)
+		}},
+		{"torn_payload", func(b *Buffer, second int) {
+			b.offset = uint64(second + recordHeaderSize + 2)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(64)
+			want := writeRecords(t, b, 1)
+			second := b.Len()
+			writeRecords(t, b, 3)
+			tt.corrupt(b, second)
+
+			got, err := readRecords(b)
+			if !errors.Is(err, ErrCorruptRecord) {
+				t.Fatalf("Err() = %v, want ErrCorruptRecord", err)
+			}
+			if len(got) != 1 || !bytes.Equal(got[0], want[0]) {
+				t.Errorf("records before corruption = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRecord_Truncate(t *testing.T) {
+	b := New(64)
+	writeRecords(t, b, 2)
+	valid := b.Len()
+	writeRecords(t, b, 1)
+	b.data[valid+recordHeaderSize] ^= 0xFF
+
+	it := b.RecordIterator()
+	for it.Next() {
+	}
+	if it.Offset() != valid {
+		t.Fatalf("Offset() = %d, want %d", it.Offset(), valid)
+	}
+	it.Truncate()
+	if b.Len() != valid {
+		t.Fatalf("Len() after Truncate = %d, want %d", b.Len(), valid)
+	}
+
+	if err := b.WriteRecord([]byte("after")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readRecords(b)
+	if err != nil || len(got) != 3 || string(got[2]) != "after" {
+		t.Errorf("after Truncate = (%q, %v), want 3 clean records", got, err)
+	}
+}
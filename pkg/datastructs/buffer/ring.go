@@ -6,6 +6,7 @@ import (
 
 	"github.com/huynhanx03/go-common/pkg/pool/byteslice"
 	"github.com/huynhanx03/go-common/pkg/utils"
+	"github.com/huynhanx03/go-common/pkg/utils/bytesx"
 )
 
 const (
@@ -14,8 +15,17 @@ const (
 	ringGrowThreshold = 4 * 1024 // 4KB
 )
 
-// ErrRingEmpty is returned when trying to read from an empty ring buffer.
-var ErrRingEmpty = errors.New("ring buffer is empty")
+var (
+	// ErrRingEmpty is returned when trying to read from an empty ring buffer.
+	ErrRingEmpty = errors.New("ring buffer is empty")
+
+	// ErrRingFull is returned when writing to a fixed-size ring buffer that has no space left.
+	ErrRingFull = errors.New("ring buffer is full")
+
+	// ErrInvalidUnread is returned when unreading more than the last read
+	// returned, or after another operation has followed it.
+	ErrInvalidUnread = errors.New("ring buffer: invalid unread")
+)
 
 // RingBuffer is a circular buffer implementing io.ReadWriter.
 // It supports auto-grow when write exceeds capacity.
@@ -25,6 +35,15 @@ type RingBuffer struct {
 	readPos  int // next position to read from
 	writePos int // next position to write to
 	empty    bool
+	fixed    bool // grow is not permitted
+	external bool // buf is caller-owned and never returned to the pool
+
+	// lastRead is how many bytes UnreadN may restore: the count returned by
+	// the last Read or ReadByte, minus what was unread since. Any other
+	// mutation clears it. lastEnd is the read position right after that
+	// read, kept because draining the ring resets readPos to 0.
+	lastRead int
+	lastEnd  int
 }
 
 // NewRing creates a new RingBuffer with the given initial capacity.
@@ -35,9 +54,25 @@ func NewRing(capacity int) *RingBuffer {
 	}
 	capacity = utils.CeilToPowerOfTwo(capacity)
 	return &RingBuffer{
-		buf:      byteslice.Get(capacity),
+		buf:      byteslice.GetZeroed(capacity), // free space reaches ReadFrom's reader
+		capacity: capacity,
+		empty:    true,
+	}
+}
+
+// NewRingFrom creates a RingBuffer that uses buf as its storage without copying.
+// Only the largest power-of-two prefix of buf is used. The caller keeps
+// ownership of buf: it is never returned to the byteslice pool.
+// If growable is false, writes that do not fit fail with ErrRingFull;
+// otherwise the ring moves to pooled storage when it outgrows buf.
+func NewRingFrom(buf []byte, growable bool) *RingBuffer {
+	capacity := utils.FloorToPowerOfTwo(len(buf))
+	return &RingBuffer{
+		buf:      buf[:capacity],
 		capacity: capacity,
 		empty:    true,
+		fixed:    !growable,
+		external: true,
 	}
 }
 
@@ -94,6 +129,19 @@ func (rb *RingBuffer) peekAll() (head, tail []byte) {
 	return head, tail
 }
 
+// IndexByte returns the offset of the first c in the buffered data, or -1.
+// The scan spans the wrap point without copying.
+func (rb *RingBuffer) IndexByte(c byte) int {
+	head, tail := rb.peekAll()
+	return bytesx.IndexByte2(head, tail, c)
+}
+
+// CountByte returns the number of c in the buffered data.
+func (rb *RingBuffer) CountByte(c byte) int {
+	head, tail := rb.peekAll()
+	return bytesx.CountByte2(head, tail, c)
+}
+
 // Discard skips n bytes by advancing the read pointer.
 // Returns the number of bytes actually discarded.
 func (rb *RingBuffer) Discard(n int) (int, error) {
@@ -101,6 +149,7 @@ func (rb *RingBuffer) Discard(n int) (int, error) {
 		return 0, nil
 	}
 
+	rb.lastRead = 0
 	buffered := rb.Buffered()
 	if n < buffered {
 		rb.readPos = rb.wrapIndex(rb.readPos + n)
@@ -129,10 +178,7 @@ func (rb *RingBuffer) Read(p []byte) (int, error) {
 	// Simple case: no wrap-around
 	if rb.writePos > rb.readPos {
 		copy(p, rb.buf[rb.readPos:rb.readPos+toRead])
-		rb.readPos += toRead
-		if rb.readPos == rb.writePos {
-			rb.Reset()
-		}
+		rb.advanceRead(toRead)
 		return toRead, nil
 	}
 
@@ -146,11 +192,20 @@ func (rb *RingBuffer) Read(p []byte) (int, error) {
 		copy(p[headLen:], rb.buf[:tailLen])
 	}
 
-	rb.readPos = rb.wrapIndex(rb.readPos + toRead)
-	if rb.readPos == rb.writePos {
+	rb.advanceRead(toRead)
+	return toRead, nil
+}
+
+// advanceRead consumes n bytes read by Read or ReadByte and records them
+// for UnreadN.
+func (rb *RingBuffer) advanceRead(n int) {
+	end := rb.wrapIndex(rb.readPos + n)
+	rb.readPos = end
+	if end == rb.writePos {
 		rb.Reset()
 	}
-	return toRead, nil
+	rb.lastRead = n
+	rb.lastEnd = end
 }
 
 // ReadByte reads and returns the next byte from the buffer.
@@ -160,14 +215,37 @@ func (rb *RingBuffer) ReadByte() (byte, error) {
 	}
 
 	b := rb.buf[rb.readPos]
-	rb.readPos++
-	if rb.readPos == rb.capacity {
-		rb.readPos = 0
+	rb.advanceRead(1)
+	return b, nil
+}
+
+// UnreadByte unreads the last byte read, like bufio.Reader.UnreadByte.
+func (rb *RingBuffer) UnreadByte() error {
+	return rb.UnreadN(1)
+}
+
+// UnreadN moves the read position back n bytes so they are read again.
+// n may not exceed what the last Read or ReadByte returned, less anything
+// already unread, and any other operation in between (a write, Discard,
+// WriteTo, Reset) makes the unread fail with ErrInvalidUnread.
+func (rb *RingBuffer) UnreadN(n int) error {
+	if n < 0 || n > rb.lastRead {
+		return ErrInvalidUnread
 	}
-	if rb.readPos == rb.writePos {
-		rb.Reset()
+	if n == 0 {
+		return nil
 	}
-	return b, nil
+
+	if rb.empty {
+		// The read drained the ring and reset its positions; the bytes are
+		// still in place behind lastEnd.
+		rb.writePos = rb.lastEnd
+		rb.empty = false
+	}
+	rb.readPos = rb.wrapIndex(rb.lastEnd - n)
+	rb.lastEnd = rb.readPos
+	rb.lastRead -= n
+	return nil
 }
 
 // Write implements io.Writer.
@@ -177,10 +255,15 @@ func (rb *RingBuffer) Write(p []byte) (int, error) {
 	if dataLen == 0 {
 		return 0, nil
 	}
+	rb.lastRead = 0
 
 	// Grow buffer if needed
 	freeSpace := rb.Available()
 	if dataLen > freeSpace {
+		if rb.fixed {
+			n, _ := rb.Write(p[:freeSpace])
+			return n, ErrRingFull
+		}
 		rb.grow(rb.capacity + dataLen - freeSpace)
 	}
 
@@ -210,7 +293,11 @@ func (rb *RingBuffer) Write(p []byte) (int, error) {
 
 // WriteByte writes a single byte to the buffer.
 func (rb *RingBuffer) WriteByte(c byte) error {
+	rb.lastRead = 0
 	if rb.Available() < 1 {
+		if rb.fixed {
+			return ErrRingFull
+		}
 		rb.grow(1)
 	}
 
@@ -266,6 +353,20 @@ func (rb *RingBuffer) Cap() int {
 	return rb.capacity
 }
 
+// EnsureCapacity makes room for n more bytes with at most one grow, so a
+// write of known size, e.g. a length-prefixed message, does not grow and
+// copy repeatedly. A fixed ring returns ErrRingFull if n bytes do not fit.
+func (rb *RingBuffer) EnsureCapacity(n int) error {
+	if n <= rb.Available() {
+		return nil
+	}
+	if rb.fixed {
+		return ErrRingFull
+	}
+	rb.grow(rb.Buffered() + n)
+	return nil
+}
+
 // Bytes returns a copy of all buffered data.
 func (rb *RingBuffer) Bytes() []byte {
 	if rb.empty {
@@ -295,10 +396,16 @@ func (rb *RingBuffer) ReadFrom(r io.Reader) (int64, error) {
 	for {
 		// Ensure minimum read space
 		if rb.Available() < minReadSize {
-			rb.grow(rb.Buffered() + minReadSize)
+			if rb.fixed {
+				if rb.Available() == 0 {
+					return total, ErrRingFull
+				}
+			} else {
+				rb.grow(rb.Buffered() + minReadSize)
+			}
 		}
 
-		bytesRead, err := rb.readFromOnce(r)
+		bytesRead, err := rb.readIntoFree(r)
 		total += bytesRead
 		if err == io.EOF {
 			return total, nil
@@ -309,9 +416,12 @@ func (rb *RingBuffer) ReadFrom(r io.Reader) (int64, error) {
 	}
 }
 
-// readFromOnce reads once from the reader into available buffer space.
-func (rb *RingBuffer) readFromOnce(r io.Reader) (int64, error) {
+// readIntoFree reads from the reader into available buffer space, once
+// into the space after writePos and, if that fills without error, once
+// more into the space that wraps around.
+func (rb *RingBuffer) readIntoFree(r io.Reader) (int64, error) {
 	var total int64
+	rb.lastRead = 0
 
 	if rb.writePos >= rb.readPos {
 		// Read into tail space
@@ -347,38 +457,62 @@ func (rb *RingBuffer) readFromOnce(r io.Reader) (int64, error) {
 	return total, err
 }
 
+// ReadFromOnce calls r.Read exactly once, into the contiguous free space
+// at the write position, and returns r's n and err unmodified, io.EOF
+// included. A growable ring first grows if less than 512 bytes are free;
+// a full fixed ring returns ErrRingFull without reading.
+func (rb *RingBuffer) ReadFromOnce(r io.Reader) (int, error) {
+	if avail := rb.Available(); avail < minReadSize {
+		if !rb.fixed {
+			rb.grow(rb.Buffered() + minReadSize)
+		} else if avail == 0 {
+			return 0, ErrRingFull
+		}
+	}
+	rb.lastRead = 0
+
+	end := rb.capacity
+	if rb.writePos < rb.readPos {
+		end = rb.readPos
+	}
+	n, err := r.Read(rb.buf[rb.writePos:end])
+	if n < 0 {
+		panic("ring: reader returned negative count")
+	}
+	if n > 0 {
+		rb.empty = false
+		rb.writePos = rb.wrapIndex(rb.writePos + n)
+	}
+	return n, err
+}
+
 // WriteTo implements io.WriterTo.
 // Writes all buffered data to w.
 func (rb *RingBuffer) WriteTo(w io.Writer) (int64, error) {
-	if rb.empty {
+	return rb.WriteToN(w, rb.Buffered())
+}
+
+// WriteToN writes at most max buffered bytes to w and keeps the rest.
+// A short write without error is treated as backpressure: WriteToN stops,
+// leaves the unwritten bytes buffered and returns a nil error.
+func (rb *RingBuffer) WriteToN(w io.Writer, max int) (int64, error) {
+	if rb.empty || max <= 0 {
 		return 0, nil
 	}
+	rb.lastRead = 0
 
-	// Simple case: no wrap-around
-	if rb.writePos > rb.readPos {
-		written, err := w.Write(rb.buf[rb.readPos:rb.writePos])
-		rb.readPos += written
-		if rb.readPos == rb.writePos {
-			rb.Reset()
-		}
-		return int64(written), err
-	}
+	head, tail := rb.Peek(max)
 
-	// Wrap-around case: write tail first, then head
 	var total int64
-
-	headLen := rb.capacity - rb.readPos
-	written, err := w.Write(rb.buf[rb.readPos:])
+	written, err := w.Write(head)
 	rb.readPos = rb.wrapIndex(rb.readPos + written)
 	total += int64(written)
-	if err != nil || written < headLen {
-		return total, err
+	if err == nil && written == len(head) && len(tail) > 0 {
+		written, err = w.Write(tail)
+		rb.readPos = rb.wrapIndex(rb.readPos + written)
+		total += int64(written)
 	}
 
-	tailLen := rb.writePos
-	written, err = w.Write(rb.buf[:tailLen])
-	rb.readPos = written
-	total += int64(written)
 	if rb.readPos == rb.writePos {
 		rb.Reset()
 	}
@@ -400,6 +534,7 @@ func (rb *RingBuffer) Reset() {
 	rb.empty = true
 	rb.readPos = 0
 	rb.writePos = 0
+	rb.lastRead = 0
 }
 
 // wrapIndex returns the index wrapped within buffer capacity.
@@ -411,15 +546,19 @@ func (rb *RingBuffer) wrapIndex(idx int) int {
 func (rb *RingBuffer) grow(minCap int) {
 	newCap := rb.calculateGrowth(minCap)
 
-	newBuf := byteslice.Get(newCap)
+	newBuf := byteslice.GetZeroed(newCap)
 	bufferedLen := rb.Buffered()
 	_, _ = rb.Read(newBuf)
-	byteslice.Put(rb.buf)
+	if !rb.external {
+		byteslice.Put(rb.buf)
+	}
+	rb.external = false
 
 	rb.buf = newBuf
 	rb.readPos = 0
 	rb.writePos = bufferedLen
 	rb.capacity = newCap
+	rb.lastRead = 0
 	if rb.writePos > 0 {
 		rb.empty = false
 	}
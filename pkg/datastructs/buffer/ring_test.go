@@ -56,6 +56,161 @@ func TestRing_NewRing(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// Method: NewRingFrom()
+// =============================================================================
+
+func TestRing_NewRingFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantCap int
+	}{
+		{"power_of_two", 64, 64},
+		{"uses_power_of_two_prefix", 100, 64},
+		{"empty", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := NewRingFrom(make([]byte, tt.size), false)
+			if rb.Cap() != tt.wantCap {
+				t.Errorf("Cap() = %d, want %d", rb.Cap(), tt.wantCap)
+			}
+			if !rb.IsEmpty() {
+				t.Error("new ring should be empty")
+			}
+		})
+	}
+}
+
+func TestRing_NewRingFrom_NoCopy(t *testing.T) {
+	buf := make([]byte, 8)
+	rb := NewRingFrom(buf, false)
+	rb.Write([]byte("abc"))
+
+	if string(buf[:3]) != "abc" {
+		t.Errorf("buf = %q, want writes to land in the caller's slice", buf[:3])
+	}
+}
+
+func TestRing_NewRingFrom_Fixed(t *testing.T) {
+	rb := NewRingFrom(make([]byte, 8), false)
+
+	n, err := rb.Write([]byte("0123456789"))
+	if err != ErrRingFull || n != 8 {
+		t.Fatalf("Write = %d, %v, want 8, ErrRingFull", n, err)
+	}
+	if err := rb.WriteByte('x'); err != ErrRingFull {
+		t.Errorf("WriteByte err = %v, want ErrRingFull", err)
+	}
+	if rb.Cap() != 8 {
+		t.Errorf("Cap() = %d, want 8 (no grow)", rb.Cap())
+	}
+
+	rb.Discard(8)
+	if _, err := rb.ReadFrom(strings.NewReader(strings.Repeat("a", 20))); err != ErrRingFull {
+		t.Errorf("ReadFrom err = %v, want ErrRingFull", err)
+	}
+	if rb.Buffered() != 8 {
+		t.Errorf("Buffered() = %d, want 8", rb.Buffered())
+	}
+}
+
+func TestRing_NewRingFrom_Growable(t *testing.T) {
+	buf := make([]byte, 8)
+	rb := NewRingFrom(buf, true)
+
+	data := []byte("0123456789")
+	n, err := rb.Write(data)
+	if err != nil || n != len(data) {
+		t.Fatalf("Write = %d, %v, want %d, nil", n, err, len(data))
+	}
+	if rb.Cap() <= 8 {
+		t.Errorf("Cap() = %d, want > 8 after grow", rb.Cap())
+	}
+	if got := rb.Bytes(); !bytes.Equal(got, data) {
+		t.Errorf("Bytes() = %q, want %q", got, data)
+	}
+}
+
+// =============================================================================
+// Method: ReadFromOnce()
+// =============================================================================
+
+func TestRing_ReadFromOnce(t *testing.T) {
+	rb := NewRing(1024)
+	rb.Write(make([]byte, 1018))
+	rb.Discard(1016)
+
+	// Only the contiguous space after the write position: no second read
+	// into the space that wraps around.
+	n, err := rb.ReadFromOnce(strings.NewReader("abcdefghij"))
+	if n != 6 || err != nil {
+		t.Fatalf("ReadFromOnce = %d, %v; want 6, nil", n, err)
+	}
+	if got := string(rb.Bytes()); got != "\x00\x00abcdef" {
+		t.Errorf("Bytes = %q, want %q", got, "\x00\x00abcdef")
+	}
+	if rb.Cap() != 1024 {
+		t.Errorf("Cap = %d, want 1024 (no grow with 1022 bytes free)", rb.Cap())
+	}
+
+	if n, err := rb.ReadFromOnce(strings.NewReader("")); n != 0 || err != io.EOF {
+		t.Errorf("ReadFromOnce(empty) = %d, %v; want 0, io.EOF", n, err)
+	}
+
+	fixed := NewRingFrom(make([]byte, 4), false)
+	fixed.Write([]byte("full"))
+	if _, err := fixed.ReadFromOnce(strings.NewReader("x")); err != ErrRingFull {
+		t.Errorf("ReadFromOnce on full fixed ring = %v, want ErrRingFull", err)
+	}
+}
+
+// =============================================================================
+// Method: EnsureCapacity()
+// =============================================================================
+
+func TestRing_EnsureCapacity(t *testing.T) {
+	rb := NewRing(16)
+	rb.Write([]byte("0123456789"))
+	rb.Discard(6) // data wraps on the next write
+
+	if err := rb.EnsureCapacity(4); err != nil || rb.Cap() != 16 {
+		t.Fatalf("EnsureCapacity(4) = %v, Cap() = %d; want nil, 16 (fits)", err, rb.Cap())
+	}
+	if err := rb.EnsureCapacity(1000); err != nil {
+		t.Fatal(err)
+	}
+	if rb.Available() < 1000 {
+		t.Errorf("Available() = %d, want >= 1000", rb.Available())
+	}
+	grown := rb.Cap()
+
+	payload := bytes.Repeat([]byte("x"), 1000)
+	rb.Write(payload)
+	if rb.Cap() != grown {
+		t.Errorf("Cap() = %d after write, want %d (no further grow)", rb.Cap(), grown)
+	}
+	if got := rb.Bytes(); !bytes.Equal(got, append([]byte("6789"), payload...)) {
+		t.Errorf("Bytes() lost data across EnsureCapacity: %q", got[:8])
+	}
+}
+
+func TestRing_EnsureCapacity_Fixed(t *testing.T) {
+	rb := NewRingFrom(make([]byte, 8), false)
+	rb.Write([]byte("abc"))
+	if err := rb.EnsureCapacity(5); err != nil {
+		t.Errorf("EnsureCapacity(5) = %v, want nil", err)
+	}
+	if err := rb.EnsureCapacity(6); err != ErrRingFull {
+		t.Errorf("EnsureCapacity(6) = %v, want ErrRingFull", err)
+	}
+	if rb.Cap() != 8 {
+		t.Errorf("Cap() = %d, want 8", rb.Cap())
+	}
+}
+
 // =============================================================================
 // Method: Write()
 // =============================================================================
@@ -322,6 +477,104 @@ func TestRing_ReadByte(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Method: UnreadByte() / UnreadN()
+// =============================================================================
+
+func TestRing_UnreadByte(t *testing.T) {
+	rb := NewRing(8)
+	rb.Write([]byte("ab"))
+
+	b, _ := rb.ReadByte()
+	if err := rb.UnreadByte(); err != nil {
+		t.Fatalf("UnreadByte() error = %v", err)
+	}
+	if again, _ := rb.ReadByte(); again != b {
+		t.Errorf("ReadByte after unread = %c; want %c", again, b)
+	}
+
+	// Only the last read can be unread.
+	_ = rb.UnreadByte()
+	if err := rb.UnreadByte(); err != ErrInvalidUnread {
+		t.Errorf("second UnreadByte() error = %v; want ErrInvalidUnread", err)
+	}
+}
+
+func TestRing_UnreadN(t *testing.T) {
+	t.Run("after_drain", func(t *testing.T) {
+		rb := NewRing(8)
+		rb.Write([]byte("hello"))
+		p := make([]byte, 5)
+		rb.Read(p)
+		if !rb.IsEmpty() {
+			t.Fatal("ring not empty after reading everything")
+		}
+
+		if err := rb.UnreadN(3); err != nil {
+			t.Fatalf("UnreadN(3) error = %v", err)
+		}
+		if got := string(rb.Bytes()); got != "llo" {
+			t.Errorf("Bytes() after UnreadN(3) = %q; want llo", got)
+		}
+		if err := rb.UnreadN(2); err != nil {
+			t.Fatalf("UnreadN(2) error = %v", err)
+		}
+		if got := string(rb.Bytes()); got != "hello" {
+			t.Errorf("Bytes() after UnreadN(2) = %q; want hello", got)
+		}
+	})
+
+	t.Run("wrap_around", func(t *testing.T) {
+		rb := NewRing(8)
+		rb.Write([]byte("123456"))
+		rb.Discard(5)
+		rb.Write([]byte("abcd")) // wraps: "6abcd"
+
+		p := make([]byte, 4)
+		rb.Read(p)
+		if err := rb.UnreadN(4); err != nil {
+			t.Fatalf("UnreadN(4) error = %v", err)
+		}
+		if got := string(rb.Bytes()); got != "6abcd" {
+			t.Errorf("Bytes() = %q; want 6abcd", got)
+		}
+	})
+
+	t.Run("full_ring", func(t *testing.T) {
+		rb := NewRing(4)
+		rb.Write([]byte("wxyz"))
+		p := make([]byte, 4)
+		rb.Read(p)
+		if err := rb.UnreadN(4); err != nil {
+			t.Fatalf("UnreadN(4) error = %v", err)
+		}
+		if !rb.IsFull() || string(rb.Bytes()) != "wxyz" {
+			t.Errorf("after UnreadN: full = %v, Bytes() = %q", rb.IsFull(), rb.Bytes())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		rb := NewRing(8)
+		rb.Write([]byte("abc"))
+		p := make([]byte, 2)
+		rb.Read(p)
+
+		if err := rb.UnreadN(3); err != ErrInvalidUnread {
+			t.Errorf("UnreadN(3) error = %v; want ErrInvalidUnread", err)
+		}
+		if err := rb.UnreadN(-1); err != ErrInvalidUnread {
+			t.Errorf("UnreadN(-1) error = %v; want ErrInvalidUnread", err)
+		}
+		rb.WriteByte('d') // any write invalidates the unread
+		if err := rb.UnreadN(1); err != ErrInvalidUnread {
+			t.Errorf("UnreadN after write error = %v; want ErrInvalidUnread", err)
+		}
+		if err := rb.UnreadN(0); err != nil {
+			t.Errorf("UnreadN(0) error = %v; want nil", err)
+		}
+	})
+}
+
 // =============================================================================
 // Method: Peek()
 // =============================================================================
@@ -396,6 +649,36 @@ func TestRing_Peek(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Method: IndexByte() / CountByte()
+// =============================================================================
+
+func TestRing_IndexByte(t *testing.T) {
+	rb := NewRing(16)
+	if got := rb.IndexByte('\n'); got != -1 {
+		t.Errorf("IndexByte(empty) = %d; want -1", got)
+	}
+
+	// One byte left at index 12; the write wraps: "ab\n" at 13..15,
+	// "cd\ne" at 0..3.
+	_, _ = rb.Write(make([]byte, 13))
+	_, _ = rb.Read(make([]byte, 12))
+	_, _ = rb.WriteString("ab\ncd\ne")
+
+	if head, tail := rb.Peek(0); len(tail) == 0 {
+		t.Fatalf("setup did not wrap: head %q", head)
+	}
+	if got := rb.IndexByte('\n'); got != 3 {
+		t.Errorf("IndexByte('\\n') = %d; want 3", got)
+	}
+	if got := rb.IndexByte('e'); got != 7 {
+		t.Errorf("IndexByte('e') across wrap = %d; want 7", got)
+	}
+	if got := rb.CountByte('\n'); got != 2 {
+		t.Errorf("CountByte('\\n') = %d; want 2", got)
+	}
+}
+
 // =============================================================================
 // Method: Discard()
 // =============================================================================
@@ -2,6 +2,7 @@ package buffer
 
 import (
 	"encoding/binary"
+	"unsafe"
 )
 
 // NewSlice creates a Buffer wrapper around an existing byte slice.
@@ -27,12 +28,42 @@ func (b *Buffer) SliceAllocate(n int) []byte {
 	return b.Allocate(n)
 }
 
+// SliceAllocateWithMeta is SliceAllocate with a metadata word stored
+// alongside the block. Read it back with SliceMeta.
+func (b *Buffer) SliceAllocateWithMeta(n int, meta uint64) []byte {
+	b.Grow(headerSize + metaSize + n)
+	buf := b.Allocate(headerSize + metaSize)
+	binary.BigEndian.PutUint64(buf, uint64(n)|metaFlag)
+	binary.BigEndian.PutUint64(buf[headerSize:], meta)
+	return b.Allocate(n)
+}
+
 // WriteSlice writes a byte slice into the buffer as a length-prefixed block.
 func (b *Buffer) WriteSlice(p []byte) {
 	dst := b.SliceAllocate(len(p))
 	copy(dst, p)
 }
 
+// WriteSliceWithMeta writes p as a length-prefixed block carrying meta,
+// e.g. a record type or sequence number kept out of the payload.
+// Sorting and merging move the metadata together with its payload.
+func (b *Buffer) WriteSliceWithMeta(p []byte, meta uint64) {
+	dst := b.SliceAllocateWithMeta(len(p), meta)
+	copy(dst, p)
+}
+
+// SliceMeta returns the metadata word of the slice at the given offset.
+// The second result is false if the slice was written without metadata.
+func (b *Buffer) SliceMeta(offset int) (uint64, bool) {
+	if offset >= int(b.offset) {
+		return 0, false
+	}
+	if binary.BigEndian.Uint64(b.data[offset:])&metaFlag == 0 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(b.data[offset+headerSize:]), true
+}
+
 // Slice returns the byte slice stored at the given offset.
 // It also returns the offset of the next slice, or -1 if end reached.
 func (b *Buffer) Slice(offset int) ([]byte, int) {
@@ -42,6 +73,10 @@ func (b *Buffer) Slice(offset int) ([]byte, int) {
 
 	blockLen := binary.BigEndian.Uint64(b.data[offset:])
 	payloadStart := offset + headerSize
+	if blockLen&metaFlag != 0 {
+		blockLen &^= metaFlag
+		payloadStart += metaSize
+	}
 	nextOffset := payloadStart + int(blockLen)
 
 	payload := b.data[payloadStart:nextOffset]
@@ -51,3 +86,42 @@ func (b *Buffer) Slice(offset int) ([]byte, int) {
 	}
 	return payload, nextOffset
 }
+
+// Scalar is the set of element types a slice payload can be viewed as.
+type Scalar interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~int |
+		~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uint |
+		~float32 | ~float64
+}
+
+// WriteSliceOf writes vals as a length-prefixed block in native byte order.
+func WriteSliceOf[T Scalar](b *Buffer, vals []T) {
+	size := len(vals) * int(unsafe.Sizeof(*new(T)))
+	if size == 0 {
+		b.SliceAllocate(0)
+		return
+	}
+	dst := b.SliceAllocate(size)
+	copy(dst, unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(vals))), size))
+}
+
+// SliceOf returns the slice at the given offset viewed as []T, plus the
+// offset of the next slice (or -1). The view aliases the buffer when the
+// payload is suitably aligned and is a copy otherwise; trailing bytes that
+// do not fill a whole element are ignored.
+func SliceOf[T Scalar](b *Buffer, offset int) ([]T, int) {
+	p, next := b.Slice(offset)
+	elem := int(unsafe.Sizeof(*new(T)))
+	n := len(p) / elem
+	if n == 0 {
+		return nil, next
+	}
+
+	if uintptr(unsafe.Pointer(unsafe.SliceData(p)))%unsafe.Alignof(*new(T)) == 0 {
+		return unsafe.Slice((*T)(unsafe.Pointer(unsafe.SliceData(p))), n), next
+	}
+
+	out := make([]T, n)
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(out))), n*elem), p)
+	return out, next
+}
@@ -363,3 +363,77 @@ func TestWorkflow_NewSliceAndSlice(t *testing.T) {
 		t.Errorf("next = %d, want -1", next)
 	}
 }
+
+// =============================================================================
+// Method: WriteSliceWithMeta() / SliceMeta()
+// =============================================================================
+
+func TestWriteSliceWithMeta(t *testing.T) {
+	b := New(64)
+	b.WriteSlice([]byte("plain"))
+	b.WriteSliceWithMeta([]byte("tagged"), 42)
+
+	offsets := b.SliceOffsets()
+	if len(offsets) != 2 {
+		t.Fatalf("SliceOffsets() = %v, want 2 offsets", offsets)
+	}
+
+	if _, ok := b.SliceMeta(offsets[0]); ok {
+		t.Error("SliceMeta on plain slice reported metadata")
+	}
+	meta, ok := b.SliceMeta(offsets[1])
+	if !ok || meta != 42 {
+		t.Errorf("SliceMeta = %d, %v, want 42, true", meta, ok)
+	}
+
+	payload, next := b.Slice(offsets[1])
+	if string(payload) != "tagged" || next != -1 {
+		t.Errorf("Slice = %q, %d, want tagged, -1", payload, next)
+	}
+}
+
+func TestSortSlice_KeepsMeta(t *testing.T) {
+	b := New(64)
+	b.WriteSliceWithMeta([]byte("c"), 3)
+	b.WriteSlice([]byte("b"))
+	b.WriteSliceWithMeta([]byte("a"), 1)
+
+	b.SortSlice(ascendingLess)
+
+	want := []struct {
+		payload string
+		meta    uint64
+		hasMeta bool
+	}{{"a", 1, true}, {"b", 0, false}, {"c", 3, true}}
+
+	for i, off := range b.SliceOffsets() {
+		payload, _ := b.Slice(off)
+		meta, ok := b.SliceMeta(off)
+		if string(payload) != want[i].payload || meta != want[i].meta || ok != want[i].hasMeta {
+			t.Errorf("slice[%d] = %q, %d, %v, want %+v", i, payload, meta, ok, want[i])
+		}
+	}
+}
+
+// =============================================================================
+// Function: WriteSliceOf() / SliceOf()
+// =============================================================================
+
+func TestSliceOf(t *testing.T) {
+	b := New(64)
+	WriteSliceOf(b, []uint64{1, 2, 3})
+	WriteSliceOf(b, []int32{-1, 7})
+
+	u64, next := SliceOf[uint64](b, b.StartOffset())
+	if len(u64) != 3 || u64[0] != 1 || u64[2] != 3 {
+		t.Errorf("SliceOf[uint64] = %v, want [1 2 3]", u64)
+	}
+
+	i32, next := SliceOf[int32](b, next)
+	if len(i32) != 2 || i32[0] != -1 || i32[1] != 7 {
+		t.Errorf("SliceOf[int32] = %v, want [-1 7]", i32)
+	}
+	if next != -1 {
+		t.Errorf("next = %d, want -1", next)
+	}
+}
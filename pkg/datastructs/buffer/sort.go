@@ -142,7 +142,7 @@ func (s *sortHelper) merge(left, right []byte, start, end int) {
 		ls = rawSlice(left)
 		rs = rawSlice(right)
 
-		if s.less(ls[headerSize:], rs[headerSize:]) {
+		if s.less(slicePayload(ls), slicePayload(rs)) {
 			copyLeft()
 		} else {
 			copyRight()
@@ -150,7 +150,112 @@ func (s *sortHelper) merge(left, right []byte, start, end int) {
 	}
 }
 
+// rawSlice returns the whole block at the start of p: header, optional
+// metadata word and payload.
 func rawSlice(p []byte) []byte {
 	n := binary.BigEndian.Uint64(p)
+	if n&metaFlag != 0 {
+		return p[:headerSize+metaSize+int(n&^metaFlag)]
+	}
 	return p[:headerSize+int(n)]
 }
+
+// slicePayload returns the payload of a raw block.
+func slicePayload(raw []byte) []byte {
+	if binary.BigEndian.Uint64(raw)&metaFlag != 0 {
+		return raw[headerSize+metaSize:]
+	}
+	return raw[headerSize:]
+}
+
+// MergeSorted performs a k-way merge of already-sorted buffers into dst.
+// Each source must hold length-prefixed slices sorted by less; blocks are
+// copied verbatim, so metadata words travel with their payloads. Ties are
+// resolved in source order, which keeps the merge stable.
+func MergeSorted(dst *Buffer, less LessFunc, srcs ...*Buffer) {
+	h := &mergeHeap{less: less}
+	for i, src := range srcs {
+		if src == nil || src.IsEmpty() {
+			continue
+		}
+		h.push(mergeCursor{src: src, offset: src.StartOffset(), order: i})
+	}
+
+	for len(h.cursors) > 0 {
+		c := &h.cursors[0]
+		raw := rawSlice(c.src.data[c.offset:])
+		_, _ = dst.Write(raw)
+
+		_, next := c.src.Slice(c.offset)
+		if next < 0 {
+			h.pop()
+			continue
+		}
+		c.offset = next
+		h.down(0)
+	}
+}
+
+// mergeCursor tracks the current slice of one source during MergeSorted.
+type mergeCursor struct {
+	src    *Buffer
+	offset int
+	order  int
+}
+
+// mergeHeap is a min-heap of cursors keyed by their current slice.
+type mergeHeap struct {
+	cursors []mergeCursor
+	less    LessFunc
+}
+
+func (h *mergeHeap) lessAt(i, j int) bool {
+	a, _ := h.cursors[i].src.Slice(h.cursors[i].offset)
+	b, _ := h.cursors[j].src.Slice(h.cursors[j].offset)
+	if h.less(a, b) {
+		return true
+	}
+	if h.less(b, a) {
+		return false
+	}
+	return h.cursors[i].order < h.cursors[j].order
+}
+
+func (h *mergeHeap) push(c mergeCursor) {
+	h.cursors = append(h.cursors, c)
+	for i := len(h.cursors) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !h.lessAt(i, parent) {
+			break
+		}
+		h.cursors[i], h.cursors[parent] = h.cursors[parent], h.cursors[i]
+		i = parent
+	}
+}
+
+func (h *mergeHeap) pop() {
+	last := len(h.cursors) - 1
+	h.cursors[0] = h.cursors[last]
+	h.cursors = h.cursors[:last]
+	if last > 0 {
+		h.down(0)
+	}
+}
+
+func (h *mergeHeap) down(i int) {
+	n := len(h.cursors)
+	for {
+		smallest := i
+		if l := 2*i + 1; l < n && h.lessAt(l, smallest) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && h.lessAt(r, smallest) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		h.cursors[i], h.cursors[smallest] = h.cursors[smallest], h.cursors[i]
+		i = smallest
+	}
+}
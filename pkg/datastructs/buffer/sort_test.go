@@ -383,3 +383,74 @@ func TestWorkflow_SortVariableSizeSlices(t *testing.T) {
 		t.Errorf("variable size sort: %v, want %v", result, expected)
 	}
 }
+
+// =============================================================================
+// Function: MergeSorted()
+// =============================================================================
+
+func TestMergeSorted(t *testing.T) {
+	a, b, c := New(64), New(64), New(64)
+	writeTestSlices(a, [][]byte{[]byte("a"), []byte("d"), []byte("g")})
+	writeTestSlices(b, [][]byte{[]byte("b"), []byte("e")})
+	writeTestSlices(c, [][]byte{[]byte("c"), []byte("f"), []byte("h"), []byte("i")})
+
+	dst := New(64)
+	MergeSorted(dst, ascendingLess, a, b, New(64), c)
+
+	got := readAllSlices(dst)
+	want := [][]byte{
+		[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e"),
+		[]byte("f"), []byte("g"), []byte("h"), []byte("i"),
+	}
+	if !slicesEqual(got, want) {
+		t.Errorf("MergeSorted = %q, want %q", got, want)
+	}
+}
+
+func TestMergeSorted_StableWithMeta(t *testing.T) {
+	a, b := New(64), New(64)
+	a.WriteSliceWithMeta([]byte("k"), 1)
+	b.WriteSliceWithMeta([]byte("k"), 2)
+
+	dst := New(64)
+	MergeSorted(dst, ascendingLess, a, b)
+
+	var metas []uint64
+	for _, off := range dst.SliceOffsets() {
+		m, _ := dst.SliceMeta(off)
+		metas = append(metas, m)
+	}
+	if len(metas) != 2 || metas[0] != 1 || metas[1] != 2 {
+		t.Errorf("metas = %v, want [1 2]", metas)
+	}
+}
+
+func TestMergeSorted_LargeRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var srcs []*Buffer
+	total := 0
+	for i := 0; i < 5; i++ {
+		src := New(1024)
+		for j := 0; j < 200; j++ {
+			p := make([]byte, 1+rng.Intn(16))
+			rng.Read(p)
+			src.WriteSlice(p)
+		}
+		src.SortSlice(ascendingLess)
+		srcs = append(srcs, src)
+		total += 200
+	}
+
+	dst := New(1024)
+	MergeSorted(dst, ascendingLess, srcs...)
+
+	got := readAllSlices(dst)
+	if len(got) != total {
+		t.Fatalf("merged %d slices, want %d", len(got), total)
+	}
+	for i := 1; i < len(got); i++ {
+		if ascendingLess(got[i], got[i-1]) {
+			t.Fatalf("slice %d out of order", i)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cuckoo
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/hyperloglog"
+)
+
+// defaultProgressEvery is how many inserts apart BuildFrom reports progress
+// when WithProgress gives no interval.
+const defaultProgressEvery = 1 << 16
+
+// buildConfig holds the options of BuildFrom.
+type buildConfig struct {
+	estimate uint64
+	hll      *hyperloglog.HLL
+	every    uint64
+	progress func(added uint64)
+}
+
+// BuildOption configures BuildFrom.
+type BuildOption func(*buildConfig)
+
+// WithEstimate sizes the filter for n keys, skipping the counting pass.
+func WithEstimate(n uint64) BuildOption {
+	return func(c *buildConfig) { c.estimate = n }
+}
+
+// WithCardinality sizes the filter from a HyperLogLog already fed the
+// same keys, skipping the counting pass.
+func WithCardinality(h *hyperloglog.HLL) BuildOption {
+	return func(c *buildConfig) { c.hll = h }
+}
+
+// WithProgress calls fn with the number of keys inserted so far every
+// every inserts, 65536 if every is 0, and once more when the build ends.
+func WithProgress(every uint64, fn func(added uint64)) BuildOption {
+	return func(c *buildConfig) {
+		if every > 0 {
+			c.every = every
+		}
+		c.progress = fn
+	}
+}
+
+// capacity returns the number of slots to ask New for: the key count, from
+// an estimate or counted with a HyperLogLog, plus 1/8 so that inserts stop
+// short of the load where relocations start to fail.
+func (c *buildConfig) capacity(keys iter.Seq[uint64]) uint {
+	n := c.estimate
+	if n == 0 {
+		h := c.hll
+		if h == nil {
+			h = hyperloglog.New()
+			for key := range keys {
+				h.Add(key)
+			}
+		}
+		n = uint64(max(h.Count(), 1))
+	}
+	return uint(n + n/8)
+}
+
+// BuildFrom creates a filter holding every hash keys yields, added with
+// AddHash, for building filters over millions of keys exported from a
+// store. Keys should be distinct, as the filter stores a duplicate twice.
+// It is sized from WithEstimate or WithCardinality when given;
+// otherwise keys is iterated twice, once to count the distinct keys and
+// once to insert them, and must yield the same keys both times. It
+// returns ErrFull if the filter fills up anyway, after an estimate that
+// was too low.
+func BuildFrom(keys iter.Seq[uint64], opts ...BuildOption) (*Filter, error) {
+	cfg := buildConfig{every: defaultProgressEvery}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	f := New(cfg.capacity(keys))
+	var added uint64
+	for key := range keys {
+		if err := f.AddHash(key); err != nil {
+			return nil, fmt.Errorf("%w after %d keys", err, added)
+		}
+		if added++; cfg.progress != nil && added%cfg.every == 0 {
+			cfg.progress(added)
+		}
+	}
+	if cfg.progress != nil && (added == 0 || added%cfg.every != 0) {
+		cfg.progress(added)
+	}
+	return f, nil
+}
@@ -15,6 +15,9 @@ const (
 	maxKicks = 500
 )
 
+// ErrFull is returned by Add when no relocation frees a slot for the item.
+var ErrFull = errors.New("cuckoo: filter full")
+
 // bucket is a fixed-size array of fingerprints with an explicit length.
 // Using a fixed array avoids a heap allocation per bucket.
 type bucket struct {
@@ -94,19 +97,57 @@ func fingerprint(h uint64) uint16 {
 	return f
 }
 
+// altHash spreads a fingerprint over the bucket range, so that an item's
+// alternate bucket can be found from either bucket and its fingerprint.
+func altHash(fp uint16) uint64 {
+	return uint64(fp) * 0x5bd1e995
+}
+
 // indices computes the two candidate bucket indices for a given hash and fingerprint.
 func (f *Filter) indices(h uint64, finger uint16) (uint, uint) {
 	i1 := uint(h % uint64(f.m))
-	_, hf := hash.KeyToHash(uint64(finger))
-	i2 := i1 ^ uint(hf%uint64(f.m))
+	i2 := i1 ^ uint(altHash(finger)%uint64(f.m))
 	return i1, i2
 }
 
 // Add adds an item to the filter.
 func (f *Filter) Add(item string) error {
 	h1, h2 := hash.KeyToHash(item)
-	fp := fingerprint(h2)
-	i1, i2 := f.indices(h1, fp)
+	return f.add(h1, fingerprint(h2))
+}
+
+// Contains checks if the filter probably contains the item.
+func (f *Filter) Contains(item string) bool {
+	h1, h2 := hash.KeyToHash(item)
+	return f.contains(h1, fingerprint(h2))
+}
+
+// Delete removes an item from the filter.
+func (f *Filter) Delete(item string) bool {
+	h1, h2 := hash.KeyToHash(item)
+	return f.remove(h1, fingerprint(h2))
+}
+
+// AddHash adds an item by a 64-bit hash of it, for keys hashed elsewhere.
+// The bucket comes from the low bits and the fingerprint from the high
+// ones. Items added by hash must be looked up by the same hash.
+func (f *Filter) AddHash(h uint64) error {
+	return f.add(h, fingerprint(h>>32))
+}
+
+// ContainsHash checks if the filter probably contains the item hashed to h.
+func (f *Filter) ContainsHash(h uint64) bool {
+	return f.contains(h, fingerprint(h>>32))
+}
+
+// DeleteHash removes the item hashed to h.
+func (f *Filter) DeleteHash(h uint64) bool {
+	return f.remove(h, fingerprint(h>>32))
+}
+
+// add inserts fingerprint fp of an item whose index hash is h.
+func (f *Filter) add(h uint64, fp uint16) error {
+	i1, i2 := f.indices(h, fp)
 
 	if f.buckets[i1].add(fp) {
 		f.count++
@@ -127,8 +168,7 @@ func (f *Filter) Add(item string) error {
 	for k := 0; k < maxKicks; k++ {
 		fp = f.buckets[i].swap(f.rnd.Intn(bucketSize), fp)
 
-		_, hf := hash.KeyToHash(uint64(fp))
-		i = i ^ uint(hf%uint64(f.m))
+		i = i ^ uint(altHash(fp)%uint64(f.m))
 
 		if f.buckets[i].add(fp) {
 			f.count++
@@ -136,22 +176,16 @@ func (f *Filter) Add(item string) error {
 		}
 	}
 
-	return errors.New("filter full")
+	return ErrFull
 }
 
-// Contains checks if the filter probably contains the item.
-func (f *Filter) Contains(item string) bool {
-	h1, h2 := hash.KeyToHash(item)
-	fp := fingerprint(h2)
-	i1, i2 := f.indices(h1, fp)
+func (f *Filter) contains(h uint64, fp uint16) bool {
+	i1, i2 := f.indices(h, fp)
 	return f.buckets[i1].contains(fp) || f.buckets[i2].contains(fp)
 }
 
-// Delete removes an item from the filter.
-func (f *Filter) Delete(item string) bool {
-	h1, h2 := hash.KeyToHash(item)
-	fp := fingerprint(h2)
-	i1, i2 := f.indices(h1, fp)
+func (f *Filter) remove(h uint64, fp uint16) bool {
+	i1, i2 := f.indices(h, fp)
 
 	if f.buckets[i1].remove(fp) {
 		f.count--
@@ -0,0 +1,247 @@
+package cuckoo
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/hyperloglog"
+)
+
+// =============================================================================
+// Add / Contains / Delete Tests
+// =============================================================================
+
+func TestFilter_Strings(t *testing.T) {
+	f := New(1024)
+	if err := f.Add("alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if !f.Contains("alpha") || f.Contains("beta") {
+		t.Errorf("Contains(alpha), Contains(beta) = %v, %v; want true, false", f.Contains("alpha"), f.Contains("beta"))
+	}
+	if !f.Delete("alpha") || f.Delete("alpha") || f.Contains("alpha") {
+		t.Error("Delete should remove alpha exactly once")
+	}
+}
+
+func TestFilter_Hash(t *testing.T) {
+	f := New(1024)
+	const h = 0xdeadbeef_12345678
+	if err := f.AddHash(h); err != nil {
+		t.Fatal(err)
+	}
+	if !f.ContainsHash(h) || f.Count() != 1 {
+		t.Errorf("ContainsHash = %v, Count = %d; want true, 1", f.ContainsHash(h), f.Count())
+	}
+	if f.ContainsHash(h + 1) {
+		t.Error("ContainsHash of a hash never added = true")
+	}
+	if !f.DeleteHash(h) || f.DeleteHash(h) || f.ContainsHash(h) || f.Count() != 0 {
+		t.Error("DeleteHash should remove the hash exactly once")
+	}
+}
+
+func TestAdd_ErrFull(t *testing.T) {
+	f := New(bucketSize) // a single bucket
+	for i := range bucketSize {
+		if err := f.AddHash(uint64(i+1) << 32); err != nil {
+			t.Fatalf("AddHash %d: %v", i, err)
+		}
+	}
+	if err := f.AddHash(99 << 32); !errors.Is(err, ErrFull) {
+		t.Errorf("AddHash on a full filter = %v, want ErrFull", err)
+	}
+	if f.Count() != bucketSize {
+		t.Errorf("Count = %d after a failed add, want %d", f.Count(), bucketSize)
+	}
+}
+
+// =============================================================================
+// Alternate Bucket Tests
+// =============================================================================
+
+func TestIndices_Symmetric(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, capacity := range []uint{8, 64, 4096} {
+		f := New(capacity)
+		moved := 0
+		for range 10_000 {
+			h := rnd.Uint64()
+			fp := fingerprint(rnd.Uint64())
+			i1, i2 := f.indices(h, fp)
+			// Either bucket and the fingerprint lead to the other one.
+			if back := i2 ^ uint(altHash(fp)%uint64(f.m)); back != i1 {
+				t.Fatalf("m=%d: alternate of %d is %d, want %d", f.m, i2, back, i1)
+			}
+			if i1 != i2 {
+				moved++
+			}
+		}
+		if f.m > 2 && moved < 9_000 {
+			t.Errorf("m=%d: only %d of 10000 items have a distinct alternate bucket", f.m, moved)
+		}
+	}
+}
+
+// pinned returns the n-th fingerprint whose alternate bucket is its
+// primary one in a filter of m buckets.
+func pinned(n int, m uint) uint16 {
+	return uint16(n * int(m))
+}
+
+func TestAdd_Relocates(t *testing.T) {
+	f := New(16) // 4 buckets
+	if f.m != 4 {
+		t.Fatalf("m = %d, want 4", f.m)
+	}
+
+	// Bucket 0 holds three pinned items and one movable item whose
+	// alternate is bucket 1; bucket 1 has one free slot.
+	const movable = uint16(1)
+	for i := 1; i <= 3; i++ {
+		if err := f.add(0, pinned(i, f.m)); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.add(1, pinned(i+3, f.m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.add(0, movable); err != nil {
+		t.Fatal(err)
+	}
+	if _, i2 := f.indices(0, movable); i2 != 1 {
+		t.Fatalf("alternate bucket of the movable item = %d, want 1", i2)
+	}
+
+	// A new pinned item for bucket 0 fits only by kicking the movable
+	// item over to bucket 1.
+	newcomer := pinned(7, f.m)
+	if err := f.add(0, newcomer); err != nil {
+		t.Fatalf("add needing a relocation: %v", err)
+	}
+	if !f.buckets[1].contains(movable) || f.buckets[0].contains(movable) {
+		t.Fatal("movable item was not relocated to bucket 1")
+	}
+	if !f.contains(0, movable) || !f.contains(0, newcomer) || f.Count() != 8 {
+		t.Fatalf("after relocation: contains %v/%v, count %d; want true/true, 8",
+			f.contains(0, movable), f.contains(0, newcomer), f.Count())
+	}
+
+	// Delete finds the relocated item in its alternate bucket.
+	if !f.remove(0, movable) {
+		t.Fatal("remove of the relocated item failed")
+	}
+	if f.contains(0, movable) || f.Count() != 7 {
+		t.Errorf("after remove: contains %v, count %d; want false, 7", f.contains(0, movable), f.Count())
+	}
+}
+
+func TestAdd_HighLoad(t *testing.T) {
+	f := New(1 << 12)
+	slots := int(f.m) * bucketSize
+	n := slots * 9 / 10
+
+	rnd := rand.New(rand.NewSource(2))
+	keys := make([]uint64, n)
+	for i := range keys {
+		keys[i] = rnd.Uint64()
+		if err := f.AddHash(keys[i]); err != nil {
+			t.Fatalf("AddHash at %d of %d slots: %v", i, slots, err)
+		}
+	}
+	for _, h := range keys {
+		if !f.ContainsHash(h) {
+			t.Fatal("filter is missing an added hash")
+		}
+	}
+	for _, h := range keys {
+		if !f.DeleteHash(h) {
+			t.Fatal("DeleteHash of an added hash failed")
+		}
+	}
+	if f.Count() != 0 {
+		t.Errorf("Count = %d after deleting everything, want 0", f.Count())
+	}
+}
+
+// =============================================================================
+// BuildFrom Tests
+// =============================================================================
+
+// hashes yields n pseudo-random hashes and counts its passes.
+func hashes(n int, passes *int) func(yield func(uint64) bool) {
+	return func(yield func(uint64) bool) {
+		*passes++
+		rnd := rand.New(rand.NewSource(3))
+		for range n {
+			if !yield(rnd.Uint64()) {
+				return
+			}
+		}
+	}
+}
+
+func TestBuildFrom_CountsKeys(t *testing.T) {
+	passes := 0
+	var reports []uint64
+	f, err := BuildFrom(hashes(100_000, &passes), WithProgress(30_000, func(added uint64) {
+		reports = append(reports, added)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if passes != 2 {
+		t.Errorf("keys iterated %d times, want 2", passes)
+	}
+	// 100000 keys plus 1/8 headroom need 28125 buckets, rounded up.
+	if f.m != 1<<15 || f.Count() != 100_000 {
+		t.Errorf("m = %d, Count = %d; want %d, 100000", f.m, f.Count(), 1<<15)
+	}
+	hashes(100_000, &passes)(func(h uint64) bool {
+		if !f.ContainsHash(h) {
+			t.Fatal("built filter is missing a key")
+		}
+		return true
+	})
+	if want := []uint64{30_000, 60_000, 90_000, 100_000}; !slices.Equal(reports, want) {
+		t.Errorf("progress = %v, want %v", reports, want)
+	}
+}
+
+func TestBuildFrom_Sized(t *testing.T) {
+	passes := 0
+	f, err := BuildFrom(hashes(1000, &passes), WithEstimate(5000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes != 1 || f.m != 2048 {
+		t.Errorf("WithEstimate: %d passes, m %d; want 1, 2048", passes, f.m)
+	}
+
+	h := hyperloglog.New()
+	hashes(1000, &passes)(func(x uint64) bool { h.Add(x); return true })
+	passes = 0
+	f, err = BuildFrom(hashes(1000, &passes), WithCardinality(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes != 1 || f.m != 512 {
+		t.Errorf("WithCardinality: %d passes, m %d; want 1, 512", passes, f.m)
+	}
+
+	calls := 0
+	if _, err := BuildFrom(hashes(0, &passes), WithProgress(0, func(uint64) { calls++ })); err != nil || calls != 1 {
+		t.Errorf("empty build: err %v, %d progress calls; want nil, 1", err, calls)
+	}
+}
+
+func TestBuildFrom_EstimateTooLow(t *testing.T) {
+	passes := 0
+	_, err := BuildFrom(hashes(1000, &passes), WithEstimate(10))
+	if !errors.Is(err, ErrFull) {
+		t.Errorf("BuildFrom with a low estimate = %v, want ErrFull", err)
+	}
+}
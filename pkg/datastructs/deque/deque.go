@@ -0,0 +1,290 @@
+// Package deque provides a thread-safe double-ended queue.
+//
+// Unlike the work-stealing queue.Deque, which has one owner end and one
+// stealing end, every operation here is allowed at both ends from any
+// goroutine, under a single lock. That suits schedulers falling back to a
+// shared deque and LRU lists, where a mutex is cheap next to the rest of
+// the work.
+package deque
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by the Wait variants once the deque is closed: by
+// pushes right away, and by pops after the remaining items are drained.
+var ErrClosed = errors.New("deque: closed")
+
+// minSize is the smallest ring the deque allocates or shrinks to.
+const minSize = 16
+
+// Deque is a double-ended queue, bounded or unbounded. Push and Pop never
+// block; PushFrontWait, PushBackWait, PopFrontWait and PopBackWait wait for
+// room or an item until their context is done, which gives a timeout with
+// context.WithTimeout. It is safe for concurrent use.
+type Deque[T any] struct {
+	mu       sync.Mutex
+	buf      []T // ring; the items are buf[head], ..., buf[head+size-1] mod len
+	head     int
+	size     int
+	capacity int // 0 when unbounded
+	closed   bool
+
+	// Waiters wait for these to be closed; the side that makes an item or
+	// a slot available closes and clears them. nil while no one waits.
+	nonEmpty chan struct{}
+	nonFull  chan struct{}
+}
+
+// New creates a deque holding up to capacity items, or any number with
+// capacity <= 0. Storage grows with the items and shrinks as they leave.
+func New[T any](capacity int) *Deque[T] {
+	return &Deque[T]{capacity: max(capacity, 0)}
+}
+
+// PushFront adds item at the front. It returns false if the deque is full
+// or closed.
+func (d *Deque[T]) PushFront(item T) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.push(item, true)
+}
+
+// PushBack adds item at the back. It returns false if the deque is full or
+// closed.
+func (d *Deque[T]) PushBack(item T) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.push(item, false)
+}
+
+// PopFront removes and returns the front item, false if the deque is empty.
+func (d *Deque[T]) PopFront() (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pop(true)
+}
+
+// PopBack removes and returns the back item, false if the deque is empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pop(false)
+}
+
+// PeekFront returns the front item without removing it.
+func (d *Deque[T]) PeekFront() (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.size == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+// PeekBack returns the back item without removing it.
+func (d *Deque[T]) PeekBack() (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.size == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.index(d.size-1)], true
+}
+
+// PushFrontWait adds item at the front, waiting for room while the deque
+// is full. It returns ErrClosed if the deque is or gets closed, or
+// ctx.Err() if ctx is done first.
+func (d *Deque[T]) PushFrontWait(ctx context.Context, item T) error {
+	return d.pushWait(ctx, item, true)
+}
+
+// PushBackWait adds item at the back, waiting as PushFrontWait does.
+func (d *Deque[T]) PushBackWait(ctx context.Context, item T) error {
+	return d.pushWait(ctx, item, false)
+}
+
+// PopFrontWait removes and returns the front item, waiting for one while
+// the deque is empty. It returns ErrClosed once the deque is closed and
+// drained, or ctx.Err() if ctx is done first.
+func (d *Deque[T]) PopFrontWait(ctx context.Context) (T, error) {
+	return d.popWait(ctx, true)
+}
+
+// PopBackWait removes and returns the back item, waiting as PopFrontWait
+// does.
+func (d *Deque[T]) PopBackWait(ctx context.Context) (T, error) {
+	return d.popWait(ctx, false)
+}
+
+// Close stops the deque accepting items and wakes every waiter. Items
+// already in it can still be popped. Closing twice is a no-op.
+func (d *Deque[T]) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	wake(&d.nonEmpty)
+	wake(&d.nonFull)
+}
+
+// IsClosed reports whether Close has been called.
+func (d *Deque[T]) IsClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// Len returns the number of items in the deque.
+func (d *Deque[T]) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.size
+}
+
+// Cap returns the most items the deque holds, 0 if unbounded.
+func (d *Deque[T]) Cap() int {
+	return d.capacity
+}
+
+// Clear removes every item. It does not reopen a closed deque.
+func (d *Deque[T]) Clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.buf, d.head, d.size = nil, 0, 0
+	wake(&d.nonFull)
+}
+
+func (d *Deque[T]) pushWait(ctx context.Context, item T, front bool) error {
+	for {
+		d.mu.Lock()
+		if d.closed {
+			d.mu.Unlock()
+			return ErrClosed
+		}
+		if d.push(item, front) {
+			d.mu.Unlock()
+			return nil
+		}
+		ch := waitOn(&d.nonFull)
+		d.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (d *Deque[T]) popWait(ctx context.Context, front bool) (T, error) {
+	for {
+		d.mu.Lock()
+		if item, ok := d.pop(front); ok {
+			d.mu.Unlock()
+			return item, nil
+		}
+		if d.closed {
+			d.mu.Unlock()
+			var zero T
+			return zero, ErrClosed
+		}
+		ch := waitOn(&d.nonEmpty)
+		d.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// push adds item at one end. Caller holds d.mu.
+func (d *Deque[T]) push(item T, front bool) bool {
+	if d.closed || d.capacity > 0 && d.size == d.capacity {
+		return false
+	}
+	if d.size == len(d.buf) {
+		d.resize(d.grownSize())
+	}
+	if front {
+		d.head = d.index(len(d.buf) - 1)
+		d.buf[d.head] = item
+	} else {
+		d.buf[d.index(d.size)] = item
+	}
+	d.size++
+	wake(&d.nonEmpty)
+	return true
+}
+
+// pop removes the item at one end. Caller holds d.mu.
+func (d *Deque[T]) pop(front bool) (T, bool) {
+	var zero T
+	if d.size == 0 {
+		return zero, false
+	}
+	i := d.index(d.size - 1)
+	if front {
+		i = d.head
+		d.head = d.index(1)
+	}
+	item := d.buf[i]
+	d.buf[i] = zero // drop the reference
+	d.size--
+	if d.size == 0 {
+		d.head = 0
+	}
+	if len(d.buf) > minSize && d.size <= len(d.buf)/4 {
+		d.resize(len(d.buf) / 2)
+	}
+	wake(&d.nonFull)
+	return item, true
+}
+
+// index returns the position in buf of the i-th item from the front.
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+// grownSize returns the ring size for one more item: doubled, within the
+// capacity of a bounded deque.
+func (d *Deque[T]) grownSize() int {
+	n := max(2*len(d.buf), minSize)
+	if d.capacity > 0 {
+		n = min(n, d.capacity)
+	}
+	return n
+}
+
+// resize moves the items to a ring of n slots, front first.
+func (d *Deque[T]) resize(n int) {
+	buf := make([]T, n)
+	if d.size > 0 {
+		k := copy(buf, d.buf[d.head:min(d.head+d.size, len(d.buf))])
+		copy(buf[k:], d.buf[:d.size-k])
+	}
+	d.buf, d.head = buf, 0
+}
+
+// waitOn returns the channel to wait on for *ch, creating it if no one
+// waits yet. Caller holds the deque lock.
+func waitOn(ch *chan struct{}) chan struct{} {
+	if *ch == nil {
+		*ch = make(chan struct{})
+	}
+	return *ch
+}
+
+// wake releases the waiters on *ch, if any. Caller holds the deque lock.
+func wake(ch *chan struct{}) {
+	if *ch != nil {
+		close(*ch)
+		*ch = nil
+	}
+}
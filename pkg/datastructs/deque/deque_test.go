@@ -0,0 +1,240 @@
+package deque
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// Non-blocking Operations
+// =============================================================================
+
+func TestBothEnds(t *testing.T) {
+	d := New[int](0)
+	d.PushBack(2)
+	d.PushBack(3)
+	d.PushFront(1)
+	d.PushFront(0)
+
+	if f, _ := d.PeekFront(); f != 0 {
+		t.Errorf("PeekFront = %d, want 0", f)
+	}
+	if b, _ := d.PeekBack(); b != 3 {
+		t.Errorf("PeekBack = %d, want 3", b)
+	}
+	if d.Len() != 4 {
+		t.Fatalf("Len = %d, want 4", d.Len())
+	}
+
+	for _, want := range []struct {
+		front bool
+		v     int
+	}{{true, 0}, {false, 3}, {true, 1}, {false, 2}} {
+		pop := d.PopBack
+		if want.front {
+			pop = d.PopFront
+		}
+		if v, ok := pop(); !ok || v != want.v {
+			t.Fatalf("pop (front %v) = %d, %v, want %d", want.front, v, ok, want.v)
+		}
+	}
+	if _, ok := d.PopFront(); ok {
+		t.Error("PopFront on empty deque succeeded")
+	}
+	if _, ok := d.PeekBack(); ok {
+		t.Error("PeekBack on empty deque succeeded")
+	}
+}
+
+func TestGrowAndShrink(t *testing.T) {
+	d := New[int](0)
+	const n = 1000
+	for i := range n {
+		if i%2 == 0 {
+			d.PushBack(i)
+		} else {
+			d.PushFront(i)
+		}
+	}
+	if len(d.buf) < n {
+		t.Fatalf("ring of %d slots for %d items", len(d.buf), n)
+	}
+
+	// Odd items were pushed to the front, so they come out descending.
+	for i := n - 1; i >= 1; i -= 2 {
+		if v, _ := d.PopFront(); v != i {
+			t.Fatalf("PopFront = %d, want %d", v, i)
+		}
+	}
+	for i := n - 2; i >= 0; i -= 2 {
+		if v, _ := d.PopBack(); v != i {
+			t.Fatalf("PopBack = %d, want %d", v, i)
+		}
+	}
+	if len(d.buf) > minSize {
+		t.Errorf("ring of %d slots once empty, want at most %d", len(d.buf), minSize)
+	}
+}
+
+func TestBounded(t *testing.T) {
+	d := New[int](20)
+	for i := range 20 {
+		if !d.PushBack(i) {
+			t.Fatalf("PushBack(%d) failed below capacity", i)
+		}
+	}
+	if d.PushFront(-1) || d.PushBack(20) {
+		t.Error("push into a full deque succeeded")
+	}
+	if len(d.buf) != 20 {
+		t.Errorf("ring of %d slots, want capacity 20", len(d.buf))
+	}
+	if d.Cap() != 20 || New[int](-1).Cap() != 0 {
+		t.Errorf("Cap = %d, want 20, and 0 when unbounded", d.Cap())
+	}
+
+	d.PopFront()
+	if !d.PushFront(-1) {
+		t.Error("PushFront failed after a pop made room")
+	}
+}
+
+func TestClose(t *testing.T) {
+	d := New[int](0)
+	d.PushBack(1)
+	d.Close()
+	d.Close()
+
+	if !d.IsClosed() || d.PushBack(2) {
+		t.Fatal("closed deque accepted an item")
+	}
+	if v, ok := d.PopFront(); !ok || v != 1 {
+		t.Errorf("PopFront after Close = %d, %v, want 1, true", v, ok)
+	}
+	if err := d.PushFrontWait(context.Background(), 3); err != ErrClosed {
+		t.Errorf("PushFrontWait after Close = %v, want ErrClosed", err)
+	}
+	if _, err := d.PopBackWait(context.Background()); err != ErrClosed {
+		t.Errorf("PopBackWait on a drained closed deque = %v, want ErrClosed", err)
+	}
+}
+
+// =============================================================================
+// Blocking Operations
+// =============================================================================
+
+func TestPopWait(t *testing.T) {
+	d := New[int](0)
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		time.Sleep(10 * time.Millisecond)
+		d.PushFront(7)
+	})
+
+	v, err := d.PopBackWait(context.Background())
+	wg.Wait()
+	if err != nil || v != 7 {
+		t.Fatalf("PopBackWait = %d, %v, want 7, nil", v, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := d.PopFrontWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PopFrontWait on empty deque = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestPushWait(t *testing.T) {
+	d := New[int](1)
+	d.PushBack(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := d.PushBackWait(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("PushBackWait on full deque = %v, want DeadlineExceeded", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		time.Sleep(10 * time.Millisecond)
+		d.PopFront()
+	})
+	if err := d.PushFrontWait(context.Background(), 3); err != nil {
+		t.Fatalf("PushFrontWait = %v", err)
+	}
+	wg.Wait()
+	if v, _ := d.PeekFront(); v != 3 {
+		t.Errorf("front = %d, want 3", v)
+	}
+}
+
+func TestCloseWakesWaiters(t *testing.T) {
+	d := New[int](0)
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, err := d.PopFrontWait(context.Background())
+			errs <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	d.Close()
+	for range 3 {
+		if err := <-errs; err != ErrClosed {
+			t.Errorf("waiter got %v, want ErrClosed", err)
+		}
+	}
+}
+
+func TestConcurrentProducersConsumers(t *testing.T) {
+	d := New[int](8)
+	const producers, perProducer = 4, 500
+
+	var prod, cons sync.WaitGroup
+	for p := range producers {
+		prod.Go(func() {
+			for i := range perProducer {
+				push := d.PushBackWait
+				if i%2 == 0 {
+					push = d.PushFrontWait
+				}
+				if err := push(context.Background(), p*perProducer+i); err != nil {
+					t.Errorf("push: %v", err)
+					return
+				}
+			}
+		})
+	}
+
+	seen := make([]bool, producers*perProducer)
+	var mu sync.Mutex
+	for c := range 2 {
+		cons.Go(func() {
+			pop := d.PopFrontWait
+			if c == 1 {
+				pop = d.PopBackWait
+			}
+			for {
+				v, err := pop(context.Background())
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				seen[v] = true
+				mu.Unlock()
+			}
+		})
+	}
+
+	prod.Wait()
+	d.Close()
+	cons.Wait()
+	for v, ok := range seen {
+		if !ok {
+			t.Fatalf("item %d lost", v)
+		}
+	}
+}
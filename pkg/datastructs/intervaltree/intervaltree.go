@@ -0,0 +1,230 @@
+// Package intervaltree implements an interval tree: a set of closed
+// intervals [lo, hi] that answers "which intervals contain this point" and
+// "which intervals overlap this range" in O(log n + matches).
+package intervaltree
+
+import (
+	"errors"
+	"math/rand/v2"
+
+	"github.com/huynhanx03/go-common/pkg/constraints"
+)
+
+// ErrInvalidInterval is returned when an interval has lo > hi.
+var ErrInvalidInterval = errors.New("intervaltree: lo greater than hi")
+
+// Interval is a stored interval and the handle used to delete it.
+// Its fields must not be modified while it is in a tree.
+type Interval[K constraints.Ordered, V any] struct {
+	Lo, Hi K
+	Value  V
+
+	seq         uint64 // insertion order, breaks ties between equal bounds
+	prio        uint64 // treap heap priority
+	maxHi       K      // largest Hi in this subtree
+	left, right *Interval[K, V]
+}
+
+// Tree is an interval tree, implemented as a treap ordered by (Lo, Hi) and
+// augmented with the maximum Hi of each subtree. Intervals with equal bounds
+// are kept apart. It is not safe for concurrent use.
+type Tree[K constraints.Ordered, V any] struct {
+	root *Interval[K, V]
+	size int
+	seq  uint64
+}
+
+// New creates an empty tree.
+func New[K constraints.Ordered, V any]() *Tree[K, V] {
+	return &Tree[K, V]{}
+}
+
+// Len returns the number of intervals.
+func (t *Tree[K, V]) Len() int {
+	return t.size
+}
+
+// Insert adds the closed interval [lo, hi] with value and returns its handle.
+func (t *Tree[K, V]) Insert(lo, hi K, value V) (*Interval[K, V], error) {
+	if lo > hi {
+		return nil, ErrInvalidInterval
+	}
+	t.seq++
+	iv := &Interval[K, V]{
+		Lo:    lo,
+		Hi:    hi,
+		Value: value,
+		seq:   t.seq,
+		prio:  rand.Uint64(),
+		maxHi: hi,
+	}
+	t.root = insert(t.root, iv)
+	t.size++
+	return iv, nil
+}
+
+// Delete removes the interval returned by Insert. It reports whether iv was
+// in the tree.
+func (t *Tree[K, V]) Delete(iv *Interval[K, V]) bool {
+	if iv == nil {
+		return false
+	}
+	var ok bool
+	t.root, ok = remove(t.root, iv)
+	if ok {
+		t.size--
+		iv.left, iv.right = nil, nil
+	}
+	return ok
+}
+
+// Stab returns the intervals containing point, ordered by (Lo, Hi).
+func (t *Tree[K, V]) Stab(point K) []*Interval[K, V] {
+	return t.Overlaps(point, point)
+}
+
+// Overlaps returns the intervals intersecting [lo, hi], ordered by (Lo, Hi).
+func (t *Tree[K, V]) Overlaps(lo, hi K) []*Interval[K, V] {
+	var out []*Interval[K, V]
+	t.VisitOverlaps(lo, hi, func(iv *Interval[K, V]) bool {
+		out = append(out, iv)
+		return true
+	})
+	return out
+}
+
+// VisitOverlaps calls fn for each interval intersecting [lo, hi], ordered by
+// (Lo, Hi), until fn returns false. fn must not modify the tree.
+func (t *Tree[K, V]) VisitOverlaps(lo, hi K, fn func(iv *Interval[K, V]) bool) {
+	if lo > hi {
+		return
+	}
+	visit(t.root, lo, hi, fn)
+}
+
+// Iterate calls fn for every interval ordered by (Lo, Hi) until fn returns
+// false. fn must not modify the tree.
+func (t *Tree[K, V]) Iterate(fn func(iv *Interval[K, V]) bool) {
+	walk(t.root, fn)
+}
+
+// visit returns false once fn has asked to stop.
+func visit[K constraints.Ordered, V any](n *Interval[K, V], lo, hi K, fn func(*Interval[K, V]) bool) bool {
+	if n == nil || n.maxHi < lo {
+		return true // nothing in this subtree reaches lo
+	}
+	if !visit(n.left, lo, hi, fn) {
+		return false
+	}
+	if n.Lo > hi {
+		return true // n and its right subtree start after hi
+	}
+	if n.Hi >= lo && !fn(n) {
+		return false
+	}
+	return visit(n.right, lo, hi, fn)
+}
+
+func walk[K constraints.Ordered, V any](n *Interval[K, V], fn func(*Interval[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	return walk(n.left, fn) && fn(n) && walk(n.right, fn)
+}
+
+// less orders intervals by Lo, then Hi, then insertion.
+func less[K constraints.Ordered, V any](a, b *Interval[K, V]) bool {
+	if a.Lo != b.Lo {
+		return a.Lo < b.Lo
+	}
+	if a.Hi != b.Hi {
+		return a.Hi < b.Hi
+	}
+	return a.seq < b.seq
+}
+
+// update recomputes the subtree maximum of n from its children.
+func update[K constraints.Ordered, V any](n *Interval[K, V]) {
+	n.maxHi = n.Hi
+	if n.left != nil && n.left.maxHi > n.maxHi {
+		n.maxHi = n.left.maxHi
+	}
+	if n.right != nil && n.right.maxHi > n.maxHi {
+		n.maxHi = n.right.maxHi
+	}
+}
+
+func insert[K constraints.Ordered, V any](n, iv *Interval[K, V]) *Interval[K, V] {
+	if n == nil {
+		return iv
+	}
+	if less(iv, n) {
+		n.left = insert(n.left, iv)
+		if n.left.prio > n.prio {
+			n = rotateRight(n)
+		}
+	} else {
+		n.right = insert(n.right, iv)
+		if n.right.prio > n.prio {
+			n = rotateLeft(n)
+		}
+	}
+	update(n)
+	return n
+}
+
+func remove[K constraints.Ordered, V any](n, iv *Interval[K, V]) (*Interval[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+	if n == iv {
+		return merge(n.left, n.right), true
+	}
+
+	var ok bool
+	if less(iv, n) {
+		n.left, ok = remove(n.left, iv)
+	} else {
+		n.right, ok = remove(n.right, iv)
+	}
+	if ok {
+		update(n)
+	}
+	return n, ok
+}
+
+// merge joins two treaps where every interval of a orders before those of b.
+func merge[K constraints.Ordered, V any](a, b *Interval[K, V]) *Interval[K, V] {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.prio > b.prio:
+		a.right = merge(a.right, b)
+		update(a)
+		return a
+	default:
+		b.left = merge(a, b.left)
+		update(b)
+		return b
+	}
+}
+
+func rotateRight[K constraints.Ordered, V any](n *Interval[K, V]) *Interval[K, V] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	update(n)
+	update(l)
+	return l
+}
+
+func rotateLeft[K constraints.Ordered, V any](n *Interval[K, V]) *Interval[K, V] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	update(n)
+	update(r)
+	return r
+}
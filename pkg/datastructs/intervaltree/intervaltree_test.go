@@ -0,0 +1,189 @@
+package intervaltree
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+// values returns the values of ivs.
+func values(ivs []*Interval[int, string]) []string {
+	out := make([]string, len(ivs))
+	for i, iv := range ivs {
+		out[i] = iv.Value
+	}
+	return out
+}
+
+// checkInvariants verifies the subtree maxima and treap ordering.
+func checkInvariants(t *testing.T, n *Interval[int, int]) (size, maxHi int) {
+	t.Helper()
+	if n == nil {
+		return 0, -1 << 62
+	}
+	ls, lm := checkInvariants(t, n.left)
+	rs, rm := checkInvariants(t, n.right)
+	if n.left != nil && (n.left.prio > n.prio || less(n, n.left)) {
+		t.Fatal("left child violates treap order")
+	}
+	if n.right != nil && (n.right.prio > n.prio || less(n.right, n)) {
+		t.Fatal("right child violates treap order")
+	}
+	want := max(n.Hi, lm, rm)
+	if n.maxHi != want {
+		t.Fatalf("maxHi = %d, want %d", n.maxHi, want)
+	}
+	return ls + rs + 1, want
+}
+
+// =============================================================================
+// Method: Insert() / Stab() / Overlaps()
+// =============================================================================
+
+func TestStabAndOverlaps(t *testing.T) {
+	tree := New[int, string]()
+	for _, iv := range []struct {
+		lo, hi int
+		v      string
+	}{
+		{0, 10, "a"}, {5, 15, "b"}, {20, 30, "c"}, {12, 12, "d"}, {5, 15, "e"},
+	} {
+		if _, err := tree.Insert(iv.lo, iv.hi, iv.v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		lo, hi int
+		want   []string
+	}{
+		{"stab_inside_two", 7, 7, []string{"a", "b", "e"}},
+		{"stab_on_bound", 10, 10, []string{"a", "b", "e"}},
+		{"stab_point_interval", 12, 12, []string{"b", "e", "d"}},
+		{"stab_gap", 17, 17, nil},
+		{"range_spanning", 11, 20, []string{"b", "e", "d", "c"}},
+		{"range_before", -5, -1, nil},
+		{"range_after", 31, 40, nil},
+		{"range_all", -100, 100, []string{"a", "b", "e", "d", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if tt.lo == tt.hi {
+				got = values(tree.Stab(tt.lo))
+			} else {
+				got = values(tree.Overlaps(tt.lo, tt.hi))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("query [%d, %d] = %v, want %v", tt.lo, tt.hi, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsert_Invalid(t *testing.T) {
+	tree := New[int, string]()
+	if _, err := tree.Insert(5, 4, "x"); !errors.Is(err, ErrInvalidInterval) {
+		t.Errorf("Insert(5, 4) error = %v, want ErrInvalidInterval", err)
+	}
+	if tree.Len() != 0 {
+		t.Errorf("Len() = %d after a rejected insert", tree.Len())
+	}
+}
+
+// =============================================================================
+// Method: Delete()
+// =============================================================================
+
+func TestDelete(t *testing.T) {
+	tree := New[int, string]()
+	a, _ := tree.Insert(0, 10, "a")
+	b, _ := tree.Insert(0, 10, "b") // same bounds, distinct interval
+	tree.Insert(5, 6, "c")
+
+	if !tree.Delete(a) {
+		t.Fatal("Delete(a) = false")
+	}
+	if tree.Delete(a) {
+		t.Error("second Delete(a) = true")
+	}
+	if got := values(tree.Stab(5)); !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("Stab(5) after delete = %v", got)
+	}
+
+	other := New[int, string]()
+	if other.Delete(b) {
+		t.Error("Delete of an interval from another tree = true")
+	}
+	if tree.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", tree.Len())
+	}
+}
+
+func TestRandomAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 9))
+	tree := New[int, int]()
+	var live []*Interval[int, int]
+
+	for i := range 5000 {
+		if len(live) > 0 && rng.IntN(3) == 0 {
+			j := rng.IntN(len(live))
+			if !tree.Delete(live[j]) {
+				t.Fatal("Delete of a live interval failed")
+			}
+			live = slices.Delete(live, j, j+1)
+		} else {
+			lo := rng.IntN(1000)
+			iv, _ := tree.Insert(lo, lo+rng.IntN(50), i)
+			live = append(live, iv)
+		}
+
+		if i%250 != 0 {
+			continue
+		}
+		if size, _ := checkInvariants(t, tree.root); size != len(live) || tree.Len() != len(live) {
+			t.Fatalf("size = %d, Len() = %d, want %d", size, tree.Len(), len(live))
+		}
+		lo := rng.IntN(1000)
+		hi := lo + rng.IntN(100)
+		want := 0
+		for _, iv := range live {
+			if iv.Lo <= hi && iv.Hi >= lo {
+				want++
+			}
+		}
+		got := tree.Overlaps(lo, hi)
+		if len(got) != want {
+			t.Fatalf("Overlaps(%d, %d) = %d intervals, want %d", lo, hi, len(got), want)
+		}
+		if !slices.IsSortedFunc(got, func(a, b *Interval[int, int]) int {
+			if less(a, b) {
+				return -1
+			}
+			return 1
+		}) {
+			t.Fatal("Overlaps result not ordered")
+		}
+	}
+}
+
+func TestVisitOverlaps_StopsEarly(t *testing.T) {
+	tree := New[int, string]()
+	for i := range 10 {
+		tree.Insert(i, i+100, "x")
+	}
+	calls := 0
+	tree.VisitOverlaps(0, 1000, func(*Interval[int, string]) bool {
+		calls++
+		return calls < 3
+	})
+	if calls != 3 {
+		t.Errorf("VisitOverlaps made %d calls, want 3", calls)
+	}
+}
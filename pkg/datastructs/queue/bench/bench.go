@@ -0,0 +1,171 @@
+// Package bench is a benchmark harness for queue.Queue implementations. It
+// runs standard contention scenarios (1P1C, NPNC, bursty producers) over any
+// implementation and reports enqueue-to-dequeue latency quantiles as
+// benchmark metrics next to ns/op, so queue changes are compared on the same
+// workloads:
+//
+//	go test -bench . ./pkg/datastructs/queue/bench
+package bench
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/queue"
+)
+
+// Impl is a queue implementation under test. Items are enqueue timestamps.
+type Impl struct {
+	Name string
+	New  func(capacity int) queue.Queue[int64]
+
+	// MaxProducers and MaxConsumers restrict the scenarios an
+	// implementation runs, e.g. 1 and 1 for an SPSC queue. 0 = unlimited.
+	MaxProducers int
+	MaxConsumers int
+}
+
+// supports reports whether impl may run s.
+func (impl Impl) supports(s Scenario) bool {
+	return (impl.MaxProducers == 0 || s.Producers <= impl.MaxProducers) &&
+		(impl.MaxConsumers == 0 || s.Consumers <= impl.MaxConsumers)
+}
+
+// Implementations are the queues compared by the package benchmarks.
+// Add new implementations here when they are created, e.g. an SPSC queue
+// with MaxProducers and MaxConsumers set to 1.
+var Implementations = []Impl{
+	{Name: "MPMC", New: func(capacity int) queue.Queue[int64] { return queue.NewMPMC[int64](capacity) }},
+	{Name: "Chan", New: func(capacity int) queue.Queue[int64] { return NewChan[int64](capacity) }},
+}
+
+// Scenario is a contention pattern.
+type Scenario struct {
+	Name      string
+	Producers int
+	Consumers int
+
+	// Burst, if positive, makes producers enqueue Burst items and then
+	// pause for Pause, modelling bursty arrivals instead of a steady stream.
+	Burst int
+	Pause time.Duration
+}
+
+// Scenarios are the standard contention patterns.
+var Scenarios = []Scenario{
+	{Name: "1P1C", Producers: 1, Consumers: 1},
+	{Name: "4P4C", Producers: 4, Consumers: 4},
+	{Name: "NPNC", Producers: runtime.GOMAXPROCS(0), Consumers: runtime.GOMAXPROCS(0)},
+	{Name: "8P1C", Producers: 8, Consumers: 1},
+	{Name: "Bursty4P4C", Producers: 4, Consumers: 4, Burst: 256, Pause: 20 * time.Microsecond},
+}
+
+// Run benchmarks impl under s: b.N items flow from the producers through a
+// queue of the given capacity to the consumers. Producers spin (yielding)
+// while the queue is full and consumers while it is empty. It reports the
+// p50, p99 and p99.9 enqueue-to-dequeue latencies in nanoseconds.
+func Run(b *testing.B, impl Impl, s Scenario, capacity int) {
+	if !impl.supports(s) {
+		b.Skipf("%s does not support %d producers / %d consumers", impl.Name, s.Producers, s.Consumers)
+	}
+
+	b.ResetTimer()
+	h := measure(impl, s, capacity, int64(b.N))
+	b.StopTimer()
+
+	b.ReportMetric(float64(h.Quantile(0.50)), "p50-ns")
+	b.ReportMetric(float64(h.Quantile(0.99)), "p99-ns")
+	b.ReportMetric(float64(h.Quantile(0.999)), "p99.9-ns")
+}
+
+// measure moves total items through a fresh queue and returns the merged
+// latency histogram of the consumers.
+func measure(impl Impl, s Scenario, capacity int, total int64) *Histogram {
+	q := impl.New(capacity)
+	hists := make([]Histogram, s.Consumers)
+	base := time.Now()
+
+	var (
+		consumed atomic.Int64
+		wg       sync.WaitGroup
+	)
+	for c := range s.Consumers {
+		wg.Go(func() {
+			h := &hists[c]
+			for consumed.Load() < total {
+				ts, ok := q.Dequeue()
+				if !ok {
+					runtime.Gosched()
+					continue
+				}
+				h.Record(int64(time.Since(base)) - ts)
+				consumed.Add(1)
+			}
+		})
+	}
+	for p := range s.Producers {
+		n := total / int64(s.Producers)
+		if int64(p) < total%int64(s.Producers) {
+			n++
+		}
+		wg.Go(func() {
+			for i := int64(0); i < n; i++ {
+				for !q.Enqueue(int64(time.Since(base))) {
+					runtime.Gosched()
+				}
+				if s.Burst > 0 && (i+1)%int64(s.Burst) == 0 {
+					time.Sleep(s.Pause)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	all := new(Histogram)
+	for i := range hists {
+		all.Merge(&hists[i])
+	}
+	return all
+}
+
+// Chan adapts a buffered channel to queue.Queue with non-blocking
+// operations, the baseline the lock-free queues are measured against.
+type Chan[T any] struct {
+	ch chan T
+}
+
+// NewChan creates a channel-backed queue.
+func NewChan[T any](capacity int) *Chan[T] {
+	return &Chan[T]{ch: make(chan T, capacity)}
+}
+
+// Enqueue adds item unless the channel is full.
+func (c *Chan[T]) Enqueue(item T) bool {
+	select {
+	case c.ch <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+// Dequeue removes an item unless the channel is empty.
+func (c *Chan[T]) Dequeue() (T, bool) {
+	select {
+	case item := <-c.ch:
+		return item, true
+	default:
+		var zero T
+		return zero, false
+	}
+}
+
+// Capacity returns the channel capacity.
+func (c *Chan[T]) Capacity() uint64 {
+	return uint64(cap(c.ch))
+}
+
+var _ queue.Queue[int] = (*Chan[int])(nil)
@@ -0,0 +1,87 @@
+package bench
+
+import (
+	"testing"
+)
+
+// ===========================================================================
+// Benchmarks
+// ===========================================================================
+
+// BenchmarkQueues runs every implementation through every scenario.
+func BenchmarkQueues(b *testing.B) {
+	const capacity = 1024
+	for _, impl := range Implementations {
+		for _, s := range Scenarios {
+			b.Run(impl.Name+"/"+s.Name, func(b *testing.B) {
+				Run(b, impl, s, capacity)
+			})
+		}
+	}
+}
+
+// ===========================================================================
+// Histogram
+// ===========================================================================
+
+func TestHistogram_Quantiles(t *testing.T) {
+	var h Histogram
+	for i := int64(1); i <= 10000; i++ {
+		h.Record(i)
+	}
+	for _, tc := range []struct {
+		q    float64
+		want int64
+	}{{0.5, 5000}, {0.99, 9900}, {1, 10000}} {
+		got := h.Quantile(tc.q)
+		if lo := tc.want * (subBuckets - 1) / subBuckets; got < lo || got > tc.want {
+			t.Errorf("Quantile(%v) = %d, want within [%d, %d]", tc.q, got, lo, tc.want)
+		}
+	}
+	if h.Count() != 10000 || h.Max() != 10000 {
+		t.Errorf("Count, Max = %d, %d", h.Count(), h.Max())
+	}
+}
+
+func TestHistogram_BucketsRoundTrip(t *testing.T) {
+	for _, v := range []uint64{0, 1, 31, 32, 33, 63, 64, 1000, 1 << 40, 1<<63 - 1} {
+		i := bucket(v)
+		if lo := bucketLow(i); lo > v || (i+1 < 64*subBuckets && bucketLow(i+1) <= v) {
+			t.Errorf("value %d in bucket %d [%d, %d)", v, i, lo, bucketLow(i+1))
+		}
+	}
+}
+
+func TestHistogram_MergeAndEmpty(t *testing.T) {
+	var a, b Histogram
+	if a.Quantile(0.5) != 0 {
+		t.Error("empty Quantile != 0")
+	}
+	a.Record(10)
+	b.Record(-5)
+	b.Record(20)
+	a.Merge(&b)
+	if a.Count() != 3 || a.Max() != 20 || a.Quantile(0) != 0 {
+		t.Errorf("merged Count=%d Max=%d p0=%d", a.Count(), a.Max(), a.Quantile(0))
+	}
+}
+
+func TestMeasure_EveryItemDelivered(t *testing.T) {
+	const items = 5000
+	for _, impl := range Implementations {
+		for _, s := range Scenarios {
+			h := measure(impl, s, 64, items)
+			if h.Count() != items {
+				t.Errorf("%s/%s: recorded %d latencies, want %d", impl.Name, s.Name, h.Count(), items)
+			}
+		}
+	}
+}
+
+func TestRun_SkipsUnsupported(t *testing.T) {
+	spsc := Implementations[0]
+	spsc.MaxProducers, spsc.MaxConsumers = 1, 1
+	if spsc.supports(Scenarios[1]) || !spsc.supports(Scenarios[0]) {
+		t.Error("supports ignores MaxProducers/MaxConsumers")
+	}
+}
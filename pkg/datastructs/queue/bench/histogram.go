@@ -0,0 +1,82 @@
+package bench
+
+import "math/bits"
+
+// subBucketBits sets the histogram precision: each power-of-two range is
+// split into 2^subBucketBits linear buckets, bounding the relative error of
+// a quantile to 1/2^subBucketBits (about 3%).
+const subBucketBits = 5
+
+const subBuckets = 1 << subBucketBits
+
+// Histogram is a log-linear latency histogram in nanoseconds, in the style
+// of HdrHistogram: fixed memory, O(1) Record, bounded relative error.
+// It is not safe for concurrent use; record per goroutine and Merge.
+type Histogram struct {
+	counts [64 * subBuckets]uint64
+	total  uint64
+	max    int64
+}
+
+// bucket returns the index of v's bucket.
+func bucket(v uint64) int {
+	if v < subBuckets {
+		return int(v)
+	}
+	exp := bits.Len64(v) - subBucketBits // >= 1
+	return exp<<subBucketBits | int(v>>(exp-1)&(subBuckets-1))
+}
+
+// bucketLow returns the smallest value of bucket i.
+func bucketLow(i int) uint64 {
+	exp, sub := i>>subBucketBits, uint64(i&(subBuckets-1))
+	if exp == 0 {
+		return sub
+	}
+	return (subBuckets | sub) << (exp - 1)
+}
+
+// Record adds a sample. Negative samples count as 0.
+func (h *Histogram) Record(ns int64) {
+	if ns < 0 {
+		ns = 0
+	}
+	h.counts[bucket(uint64(ns))]++
+	h.total++
+	h.max = max(h.max, ns)
+}
+
+// Merge adds the samples of o.
+func (h *Histogram) Merge(o *Histogram) {
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	h.total += o.total
+	h.max = max(h.max, o.max)
+}
+
+// Count returns the number of samples.
+func (h *Histogram) Count() uint64 {
+	return h.total
+}
+
+// Max returns the largest sample.
+func (h *Histogram) Max() int64 {
+	return h.max
+}
+
+// Quantile returns the sample at quantile q in [0, 1], as the lower bound
+// of its bucket. It returns 0 for an empty histogram.
+func (h *Histogram) Quantile(q float64) int64 {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(h.total-1)) + 1
+	var seen uint64
+	for i, c := range h.counts {
+		if seen += c; seen >= rank {
+			return min(int64(bucketLow(i)), h.max)
+		}
+	}
+	return h.max
+}
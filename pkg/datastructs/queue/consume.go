@@ -0,0 +1,202 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+)
+
+// ConsumeOption configures ConsumeLoop.
+type ConsumeOption func(*consumeConfig)
+
+type consumeConfig struct {
+	minBatch      int
+	maxBatch      int
+	targetLatency time.Duration
+	idleWait      time.Duration
+	meter         metrics.Meter
+}
+
+func defaultConsumeConfig() consumeConfig {
+	return consumeConfig{
+		minBatch:      1,
+		maxBatch:      256,
+		targetLatency: time.Millisecond,
+		idleWait:      time.Millisecond,
+	}
+}
+
+// WithBatchLimits bounds the batches handed to fn to [min, max] items.
+// A batch is smaller than min only when the target latency runs out first.
+// Values < 1, or min > max, are ignored.
+func WithBatchLimits(min, max int) ConsumeOption {
+	return func(c *consumeConfig) {
+		if min >= 1 && max >= min {
+			c.minBatch, c.maxBatch = min, max
+		}
+	}
+}
+
+// WithTargetLatency sets how long the first item of a batch may wait for
+// the batch to fill before it is handed over anyway. It also sizes the
+// batches: the loop aims for as many items as arrive in d. d <= 0 is
+// ignored.
+func WithTargetLatency(d time.Duration) ConsumeOption {
+	return func(c *consumeConfig) {
+		if d > 0 {
+			c.targetLatency = d
+		}
+	}
+}
+
+// WithIdleWait caps the sleep between polls of an empty queue. Shorter
+// waits pick up the first item of a burst sooner at the cost of CPU while
+// idle. d <= 0 is ignored.
+func WithIdleWait(d time.Duration) ConsumeOption {
+	return func(c *consumeConfig) {
+		if d > 0 {
+			c.idleWait = d
+		}
+	}
+}
+
+// WithConsumeMeter reports to m the size of every batch as
+// "queue.consume.batch_size" and the time its first item waited for the
+// rest as "queue.consume.fill_seconds".
+func WithConsumeMeter(m metrics.Meter) ConsumeOption {
+	return func(c *consumeConfig) { c.meter = m }
+}
+
+// rateWeight is the weight of the newest sample in the arrival rate's
+// moving average.
+const rateWeight = 0.25
+
+// spinPolls is how many times an empty queue is polled with a yield before
+// the loop starts sleeping.
+const spinPolls = 64
+
+// ConsumeLoop dequeues items from q and hands them to fn in batches sized
+// to the arrival rate: about as many items as arrive within the target
+// latency. Under light load that is one item, delivered as soon as it is
+// dequeued; as load grows batches grow toward the maximum, trading a wait
+// bounded by the target latency for fewer calls to fn. A batch that fills
+// without waiting means a backlog, and the next one may be twice as large.
+//
+// fn must not keep the slice after it returns; it is reused for the next
+// batch. fn runs on the calling goroutine, one batch at a time.
+//
+// ConsumeLoop returns nil once a Closable q is closed and drained, or
+// ctx.Err() when ctx is done; items already dequeued are handed to fn
+// first. A plain Queue is only stopped by ctx.
+func ConsumeLoop[T any](ctx context.Context, q Queue[T], fn func([]T), opts ...ConsumeOption) error {
+	cfg := defaultConsumeConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	meter := metrics.OrNoop(cfg.meter)
+	sizeHist := meter.Histogram("queue.consume.batch_size", "Items per batch handed to the consumer")
+	fillHist := meter.Histogram("queue.consume.fill_seconds", "Time the first item of a batch waited for the rest")
+
+	c := consumer[T]{q: q}
+	c.closable, _ = q.(Closable[T])
+
+	batch := make([]T, 0, cfg.maxBatch)
+	target := cfg.minBatch
+	var rate float64 // items per second, moving average
+	last := time.Now()
+
+	for {
+		// Wait for the first item, backing off while the queue is idle.
+		item, err := c.next()
+		for polls := 0; err != nil; polls++ {
+			if errors.Is(err, ErrClosed) {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			pause(polls, cfg.idleWait)
+			item, err = c.next()
+		}
+
+		first := time.Now()
+		deadline := first.Add(cfg.targetLatency)
+		batch = append(batch[:0], item)
+		waited, closed := false, false
+		for polls := 0; len(batch) < target; {
+			item, err := c.next()
+			if err == nil {
+				batch = append(batch, item)
+				continue
+			}
+			if errors.Is(err, ErrClosed) {
+				closed = true
+				break
+			}
+			if ctx.Err() != nil || !time.Now().Before(deadline) {
+				break
+			}
+			waited = true
+			pause(polls, min(cfg.idleWait, time.Until(deadline)))
+			polls++
+		}
+
+		filled := time.Since(first)
+		fn(batch)
+		sizeHist.Record(float64(len(batch)))
+		fillHist.Record(filled.Seconds())
+
+		now := time.Now()
+		if elapsed := now.Sub(last).Seconds(); elapsed > 0 {
+			rate += rateWeight * (float64(len(batch))/elapsed - rate)
+		}
+		last = now
+		full := !waited && len(batch) == target
+		target = max(cfg.minBatch, min(cfg.maxBatch, int(rate*cfg.targetLatency.Seconds())))
+		if full {
+			target = max(target, min(cfg.maxBatch, 2*len(batch)))
+		}
+		clear(batch)
+
+		if closed {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// consumer dequeues from a queue, through TryDequeue when the queue is
+// Closable so that closing ends the loop.
+type consumer[T any] struct {
+	q        Queue[T]
+	closable Closable[T]
+}
+
+// next returns the next item, ErrEmpty when there is none yet, or
+// ErrClosed once a Closable queue is drained.
+func (c *consumer[T]) next() (T, error) {
+	if c.closable != nil {
+		return c.closable.TryDequeue()
+	}
+	if item, ok := c.q.Dequeue(); ok {
+		return item, nil
+	}
+	var zero T
+	return zero, ErrEmpty
+}
+
+// pause waits before the next poll of an empty queue: it yields for the
+// first spinPolls polls, then sleeps for doubling spans up to limit.
+func pause(polls int, limit time.Duration) {
+	if polls < spinPolls {
+		runtime.Gosched()
+		return
+	}
+	d := time.Microsecond << min(polls-spinPolls, 20)
+	time.Sleep(min(d, limit))
+}
@@ -0,0 +1,155 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// plainQueue hides every method of a queue but those of Queue.
+type plainQueue[T any] struct{ Queue[T] }
+
+// =============================================================================
+// ConsumeLoop Tests
+// =============================================================================
+
+func TestConsumeLoop_DrainsClosedQueue(t *testing.T) {
+	q := NewMPMC[int](4096)
+	for i := range 3000 {
+		q.Enqueue(i)
+	}
+	q.Close()
+
+	var got []int
+	largest := 0
+	err := ConsumeLoop(context.Background(), q, func(batch []int) {
+		got = append(got, batch...)
+		largest = max(largest, len(batch))
+	}, WithBatchLimits(1, 64))
+	if err != nil {
+		t.Fatalf("ConsumeLoop = %v, want nil", err)
+	}
+
+	if len(got) != 3000 {
+		t.Fatalf("consumed %d items, want 3000", len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("got[%d] = %d, want FIFO order", i, v)
+		}
+	}
+	// A backlog doubles the batch until the limit.
+	if largest != 64 {
+		t.Errorf("largest batch = %d, want 64", largest)
+	}
+}
+
+func TestConsumeLoop_LightLoad(t *testing.T) {
+	q := NewMPMC[int](64)
+	go func() {
+		for i := range 20 {
+			q.Enqueue(i)
+			time.Sleep(2 * time.Millisecond)
+		}
+		q.Close()
+	}()
+
+	var sizes []int
+	err := ConsumeLoop(context.Background(), q, func(batch []int) {
+		sizes = append(sizes, len(batch))
+	}, WithTargetLatency(100*time.Microsecond), WithIdleWait(100*time.Microsecond))
+	if err != nil {
+		t.Fatalf("ConsumeLoop = %v, want nil", err)
+	}
+
+	// Items arriving slower than the target latency go out one by one.
+	ones := 0
+	for _, n := range sizes {
+		if n == 1 {
+			ones++
+		}
+	}
+	if ones < len(sizes)/2 {
+		t.Errorf("batch sizes %v, want mostly single items", sizes)
+	}
+}
+
+func TestConsumeLoop_TargetLatency(t *testing.T) {
+	q := NewMPMC[int](1024)
+	for i := range 512 {
+		q.Enqueue(i)
+	}
+
+	// After the backlog the loop wants big batches; a lone straggler must
+	// still go out within about the target latency.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var delivered time.Time
+	done := make(chan error, 1)
+	go func() {
+		done <- ConsumeLoop(ctx, q, func(batch []int) {
+			if batch[len(batch)-1] == -1 {
+				delivered = time.Now()
+				q.Close()
+			}
+		}, WithTargetLatency(5*time.Millisecond))
+	}()
+
+	for !q.IsEmpty() {
+		time.Sleep(time.Millisecond)
+	}
+	sent := time.Now()
+	q.Enqueue(-1)
+
+	if err := <-done; err != nil {
+		t.Fatalf("ConsumeLoop = %v, want nil", err)
+	}
+	if wait := delivered.Sub(sent); wait > 200*time.Millisecond {
+		t.Errorf("straggler waited %v for a batch to fill", wait)
+	}
+}
+
+func TestConsumeLoop_ContextCancel(t *testing.T) {
+	q := plainQueue[int]{NewMPMC[int](16)}
+	q.Enqueue(1)
+	q.Enqueue(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []int
+	done := make(chan error, 1)
+	go func() {
+		done <- ConsumeLoop(ctx, Queue[int](q), func(batch []int) {
+			got = append(got, batch...)
+		})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ConsumeLoop = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ConsumeLoop did not return after cancel")
+	}
+	if len(got) != 2 {
+		t.Errorf("consumed %v, want [1 2]", got)
+	}
+}
+
+func TestConsumeOptions_IgnoreInvalid(t *testing.T) {
+	cfg := defaultConsumeConfig()
+	for _, opt := range []ConsumeOption{
+		WithBatchLimits(0, 10),
+		WithBatchLimits(8, 4),
+		WithTargetLatency(0),
+		WithIdleWait(-time.Second),
+	} {
+		opt(&cfg)
+	}
+	if cfg != defaultConsumeConfig() {
+		t.Errorf("invalid options changed the config: %+v", cfg)
+	}
+}
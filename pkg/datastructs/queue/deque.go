@@ -0,0 +1,165 @@
+package queue
+
+import (
+	"sync/atomic"
+
+	"github.com/huynhanx03/go-common/pkg/utils"
+)
+
+const defaultDequeCapacity = 32
+
+// dequeRing is the circular backing array of a Deque.
+// Slots hold pointers so that a thief reading a slot concurrently with the
+// owner writing it never observes a torn value.
+type dequeRing[T any] struct {
+	mask  int64
+	slots []atomic.Pointer[T]
+}
+
+func newDequeRing[T any](capacity int) *dequeRing[T] {
+	return &dequeRing[T]{
+		mask:  int64(capacity - 1),
+		slots: make([]atomic.Pointer[T], capacity),
+	}
+}
+
+func (r *dequeRing[T]) size() int64         { return r.mask + 1 }
+func (r *dequeRing[T]) load(i int64) *T     { return r.slots[i&r.mask].Load() }
+func (r *dequeRing[T]) store(i int64, v *T) { r.slots[i&r.mask].Store(v) }
+func (r *dequeRing[T]) clear(i int64)       { r.slots[i&r.mask].Store(nil) }
+
+// grow returns a ring twice as large holding the live range [top, bottom).
+func (r *dequeRing[T]) grow(top, bottom int64) *dequeRing[T] {
+	next := newDequeRing[T](int(r.size() * 2))
+	for i := top; i < bottom; i++ {
+		next.store(i, r.load(i))
+	}
+	return next
+}
+
+// Deque is an unbounded Chase-Lev work-stealing deque.
+//
+// A single owner goroutine pushes and pops at the bottom (LIFO), while any
+// number of thieves steal from the top (FIFO). PushBottom and PopBottom must
+// only be called by the owner; Steal is safe from any goroutine.
+type Deque[T any] struct {
+	top atomic.Int64 // Next index to steal from
+
+	_ [cacheLineSize]byte // Padding to prevent false sharing
+
+	bottom atomic.Int64 // Next index to push to
+
+	_ [cacheLineSize]byte // Padding to prevent false sharing
+
+	ring atomic.Pointer[dequeRing[T]] // Current backing array
+}
+
+// NewDeque creates a deque with initial capacity rounded up to power of 2.
+// The deque grows automatically when the owner pushes beyond capacity.
+func NewDeque[T any](capacity int) *Deque[T] {
+	if capacity < 2 {
+		capacity = defaultDequeCapacity
+	}
+	capacity = utils.CeilToPowerOfTwo(capacity)
+
+	d := &Deque[T]{}
+	d.ring.Store(newDequeRing[T](capacity))
+	return d
+}
+
+// PushBottom adds an item at the owner's end. Owner only.
+func (d *Deque[T]) PushBottom(item T) {
+	b := d.bottom.Load()
+	t := d.top.Load()
+	r := d.ring.Load()
+
+	if b-t >= r.size()-1 {
+		r = r.grow(t, b)
+		d.ring.Store(r)
+	}
+
+	r.store(b, &item)
+	d.bottom.Store(b + 1)
+}
+
+// PopBottom removes the most recently pushed item. Owner only.
+// Returns (zero, false) if the deque is empty or the last item was stolen.
+func (d *Deque[T]) PopBottom() (T, bool) {
+	var zero T
+
+	b := d.bottom.Load() - 1
+	r := d.ring.Load()
+	d.bottom.Store(b)
+	t := d.top.Load()
+
+	if t > b {
+		// Empty: restore bottom.
+		d.bottom.Store(b + 1)
+		return zero, false
+	}
+
+	item := r.load(b)
+	if t < b {
+		// More than one item left, no thief can reach slot b.
+		r.clear(b)
+		return *item, true
+	}
+
+	// Last item: race against thieves for it.
+	won := d.top.CompareAndSwap(t, t+1)
+	d.bottom.Store(b + 1)
+	if !won {
+		return zero, false
+	}
+	return *item, true
+}
+
+// Steal removes the oldest item from the top. Safe for concurrent use.
+// Returns (zero, false) if the deque is empty.
+func (d *Deque[T]) Steal() (T, bool) {
+	var zero T
+
+	for {
+		t := d.top.Load()
+		b := d.bottom.Load()
+		if t >= b {
+			return zero, false
+		}
+
+		r := d.ring.Load()
+		item := r.load(t)
+		if d.top.CompareAndSwap(t, t+1) {
+			return *item, true
+		}
+		// Lost the race to another thief or the owner; retry.
+	}
+}
+
+// StealBatch steals up to len(out) items into out. Returns count stolen.
+func (d *Deque[T]) StealBatch(out []T) int {
+	count := 0
+	for i := range out {
+		item, ok := d.Steal()
+		if !ok {
+			break
+		}
+		out[i] = item
+		count++
+	}
+	return count
+}
+
+// Size returns approximate item count.
+func (d *Deque[T]) Size() int64 {
+	size := d.bottom.Load() - d.top.Load()
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+// IsEmpty returns true if deque appears empty.
+func (d *Deque[T]) IsEmpty() bool { return d.Size() == 0 }
+
+// Capacity returns the current size of the backing array.
+func (d *Deque[T]) Capacity() uint64 { return uint64(d.ring.Load().size()) }
@@ -0,0 +1,191 @@
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// =============================================================================
+// Constructor Tests
+// =============================================================================
+
+func TestNewDeque(t *testing.T) {
+	tests := []struct {
+		name         string
+		capacity     int
+		wantCapacity uint64
+	}{
+		{"power_of_two", 16, 16},
+		{"non_power_of_two_rounds_up", 100, 128},
+		{"zero_uses_default", 0, defaultDequeCapacity},
+		{"negative_uses_default", -5, defaultDequeCapacity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDeque[int](tt.capacity)
+			if got := d.Capacity(); got != tt.wantCapacity {
+				t.Errorf("Capacity() = %d, want %d", got, tt.wantCapacity)
+			}
+			if !d.IsEmpty() {
+				t.Error("new deque should be empty")
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Owner Tests
+// =============================================================================
+
+func TestDeque_PushPopLIFO(t *testing.T) {
+	d := NewDeque[int](4)
+	for i := 1; i <= 3; i++ {
+		d.PushBottom(i)
+	}
+
+	for want := 3; want >= 1; want-- {
+		got, ok := d.PopBottom()
+		if !ok || got != want {
+			t.Fatalf("PopBottom() = (%d, %v), want (%d, true)", got, ok, want)
+		}
+	}
+
+	if _, ok := d.PopBottom(); ok {
+		t.Error("PopBottom() on empty deque should return false")
+	}
+	if s := d.Size(); s != 0 {
+		t.Errorf("Size() = %d, want 0", s)
+	}
+}
+
+func TestDeque_StealFIFO(t *testing.T) {
+	d := NewDeque[int](4)
+	for i := 1; i <= 3; i++ {
+		d.PushBottom(i)
+	}
+
+	for want := 1; want <= 3; want++ {
+		got, ok := d.Steal()
+		if !ok || got != want {
+			t.Fatalf("Steal() = (%d, %v), want (%d, true)", got, ok, want)
+		}
+	}
+
+	if _, ok := d.Steal(); ok {
+		t.Error("Steal() on empty deque should return false")
+	}
+}
+
+func TestDeque_Grow(t *testing.T) {
+	d := NewDeque[int](2)
+	const n = 100
+	for i := 0; i < n; i++ {
+		d.PushBottom(i)
+	}
+
+	if s := d.Size(); s != n {
+		t.Fatalf("Size() = %d, want %d", s, n)
+	}
+	if c := d.Capacity(); c < n {
+		t.Fatalf("Capacity() = %d, want >= %d", c, n)
+	}
+
+	// Mix both ends to verify the live range survived the copies.
+	if v, _ := d.Steal(); v != 0 {
+		t.Errorf("Steal() = %d, want 0", v)
+	}
+	if v, _ := d.PopBottom(); v != n-1 {
+		t.Errorf("PopBottom() = %d, want %d", v, n-1)
+	}
+}
+
+func TestDeque_StealBatch(t *testing.T) {
+	d := NewDeque[int](8)
+	for i := 0; i < 5; i++ {
+		d.PushBottom(i)
+	}
+
+	out := make([]int, 3)
+	if n := d.StealBatch(out); n != 3 {
+		t.Fatalf("StealBatch() = %d, want 3", n)
+	}
+	for i, v := range out {
+		if v != i {
+			t.Errorf("out[%d] = %d, want %d", i, v, i)
+		}
+	}
+
+	out = make([]int, 10)
+	if n := d.StealBatch(out); n != 2 {
+		t.Errorf("StealBatch() = %d, want 2", n)
+	}
+}
+
+// =============================================================================
+// Concurrency Tests
+// =============================================================================
+
+func TestDeque_ConcurrentSteal(t *testing.T) {
+	const (
+		total   = 20000
+		thieves = 4
+	)
+
+	d := NewDeque[int](16)
+	seen := make([]atomic.Int32, total)
+	var taken atomic.Int64
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	wg.Add(thieves)
+	for i := 0; i < thieves; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				if v, ok := d.Steal(); ok {
+					seen[v].Add(1)
+					taken.Add(1)
+					continue
+				}
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}()
+	}
+
+	// Owner pushes everything, popping every few items.
+	for i := 0; i < total; i++ {
+		d.PushBottom(i)
+		if i%3 == 0 {
+			if v, ok := d.PopBottom(); ok {
+				seen[v].Add(1)
+				taken.Add(1)
+			}
+		}
+	}
+	for {
+		v, ok := d.PopBottom()
+		if !ok {
+			break
+		}
+		seen[v].Add(1)
+		taken.Add(1)
+	}
+
+	close(done)
+	wg.Wait()
+
+	if got := taken.Load(); got != total {
+		t.Fatalf("took %d items, want %d", got, total)
+	}
+	for i := range seen {
+		if c := seen[i].Load(); c != 1 {
+			t.Fatalf("item %d taken %d times, want 1", i, c)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package queue
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+)
+
+// ErrDuplicateQueue is returned when a queue name is registered twice.
+var ErrDuplicateQueue = errors.New("queue: duplicate dispatcher queue name")
+
+// sizer is implemented by queues that can report their depth (e.g. MPMC).
+type sizer interface {
+	Size() int64
+}
+
+// dispatchQueue is one member queue of a Dispatcher and its scheduling state.
+type dispatchQueue[T any] struct {
+	name       string
+	q          Queue[T]
+	weight     int
+	credits    int    // dequeues left in the current round
+	lastServed uint64 // dispatcher tick of the last dequeue or empty probe
+	dequeued   uint64
+
+	// Instruments labelled with the queue name.
+	dequeuedCounter metrics.Counter
+	starvedCounter  metrics.Counter
+	depthGauge      metrics.Gauge
+}
+
+// DispatcherStats reports per-queue metrics.
+type DispatcherStats struct {
+	Name     string
+	Weight   int
+	Depth    int64 // current depth, -1 if the queue cannot report it
+	Dequeued uint64
+}
+
+// DispatcherOption configures a Dispatcher.
+type DispatcherOption func(*dispatcherConfig)
+
+type dispatcherConfig struct {
+	maxSkip uint64
+	meter   metrics.Meter
+}
+
+// WithMaxSkip sets starvation protection: a queue not served for n dispatcher
+// dequeues is tried first on the next one, whatever the weights say.
+// 0 disables the guard and leaves fairness to weighted round robin.
+func WithMaxSkip(n uint64) DispatcherOption {
+	return func(c *dispatcherConfig) { c.maxSkip = n }
+}
+
+// WithMeter reports per-queue activity to m, labelled queue=<name>:
+// "queue.dispatcher.dequeued", "queue.dispatcher.starved" for dequeues
+// forced by the starvation guard, and the depth after each dequeue as
+// "queue.dispatcher.depth" for queues that can report it.
+func WithMeter(m metrics.Meter) DispatcherOption {
+	return func(c *dispatcherConfig) { c.meter = m }
+}
+
+// Dispatcher multiplexes several queues (e.g. one per tenant) behind a single
+// Dequeue using weighted round robin: each queue may hand out up to weight
+// items per round before the next queue gets its turn, and empty queues are
+// skipped. Producers keep enqueueing into their own queue directly.
+// It is safe for concurrent use.
+type Dispatcher[T any] struct {
+	mu      sync.Mutex
+	queues  []*dispatchQueue[T]
+	next    int    // index of the queue whose turn it is
+	tick    uint64 // successful dequeues so far
+	maxSkip uint64
+	meter   metrics.Meter
+}
+
+// NewDispatcher creates an empty dispatcher.
+func NewDispatcher[T any](opts ...DispatcherOption) *Dispatcher[T] {
+	var cfg dispatcherConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Dispatcher[T]{maxSkip: cfg.maxSkip, meter: metrics.OrNoop(cfg.meter)}
+}
+
+// Add registers q under name with the given weight (minimum 1).
+func (d *Dispatcher[T]) Add(name string, q Queue[T], weight int) error {
+	if weight < 1 {
+		weight = 1
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, dq := range d.queues {
+		if dq.name == name {
+			return ErrDuplicateQueue
+		}
+	}
+	label := metrics.L("queue", name)
+	d.queues = append(d.queues, &dispatchQueue[T]{
+		name:       name,
+		q:          q,
+		weight:     weight,
+		credits:    weight,
+		lastServed: d.tick,
+
+		dequeuedCounter: d.meter.Counter("queue.dispatcher.dequeued", "Items dequeued through the dispatcher", label),
+		starvedCounter:  d.meter.Counter("queue.dispatcher.starved", "Dequeues forced by the starvation guard", label),
+		depthGauge:      d.meter.Gauge("queue.dispatcher.depth", "Items left in the queue", label),
+	})
+	return nil
+}
+
+// Remove unregisters the queue named name. Items left in it are not drained.
+func (d *Dispatcher[T]) Remove(name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, dq := range d.queues {
+		if dq.name != name {
+			continue
+		}
+		d.queues = append(d.queues[:i], d.queues[i+1:]...)
+		if d.next > i {
+			d.next--
+		}
+		if d.next >= len(d.queues) {
+			d.next = 0
+		}
+		return true
+	}
+	return false
+}
+
+// Dequeue returns the next item according to the fairness policy.
+// Returns (zero, false) if every queue is empty.
+func (d *Dispatcher[T]) Dequeue() (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if item, ok := d.dequeueStarved(); ok {
+		return item, true
+	}
+
+	// At most one full pass: every queue is tried once before giving up.
+	for range d.queues {
+		dq := d.queues[d.next]
+		if item, ok := dq.q.Dequeue(); ok {
+			d.served(dq)
+			dq.credits--
+			if dq.credits == 0 {
+				d.advance()
+			}
+			return item, true
+		}
+		dq.lastServed = d.tick
+		d.advance()
+	}
+
+	var zero T
+	return zero, false
+}
+
+// dequeueStarved serves the longest-waiting queue past the maxSkip limit.
+// Caller holds d.mu.
+func (d *Dispatcher[T]) dequeueStarved() (T, bool) {
+	var zero T
+	if d.maxSkip == 0 {
+		return zero, false
+	}
+
+	for {
+		var starved *dispatchQueue[T]
+		for _, dq := range d.queues {
+			if d.tick-dq.lastServed >= d.maxSkip && (starved == nil || dq.lastServed < starved.lastServed) {
+				starved = dq
+			}
+		}
+		if starved == nil {
+			return zero, false
+		}
+		if item, ok := starved.q.Dequeue(); ok {
+			d.served(starved)
+			starved.starvedCounter.Add(1)
+			return item, true
+		}
+		// Empty: nothing to protect. Reset its clock and look again.
+		starved.lastServed = d.tick
+	}
+}
+
+// served records a dequeue from dq. Caller holds d.mu.
+func (d *Dispatcher[T]) served(dq *dispatchQueue[T]) {
+	d.tick++
+	dq.lastServed = d.tick
+	dq.dequeued++
+
+	dq.dequeuedCounter.Add(1)
+	if s, ok := dq.q.(sizer); ok {
+		dq.depthGauge.Set(float64(s.Size()))
+	}
+}
+
+// advance hands the turn to the next queue with a fresh round of credits.
+// Caller holds d.mu.
+func (d *Dispatcher[T]) advance() {
+	d.queues[d.next].credits = d.queues[d.next].weight
+	d.next++
+	if d.next == len(d.queues) {
+		d.next = 0
+	}
+}
+
+// Len returns the number of registered queues.
+func (d *Dispatcher[T]) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queues)
+}
+
+// Stats returns per-queue metrics in registration order.
+func (d *Dispatcher[T]) Stats() []DispatcherStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := make([]DispatcherStats, len(d.queues))
+	for i, dq := range d.queues {
+		depth := int64(-1)
+		if s, ok := dq.q.(sizer); ok {
+			depth = s.Size()
+		}
+		stats[i] = DispatcherStats{
+			Name:     dq.name,
+			Weight:   dq.weight,
+			Depth:    depth,
+			Dequeued: dq.dequeued,
+		}
+	}
+	return stats
+}
@@ -0,0 +1,183 @@
+package queue
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+)
+
+// drain dequeues n items and returns them in order.
+func drain(t *testing.T, d *Dispatcher[string], n int) []string {
+	t.Helper()
+	out := make([]string, 0, n)
+	for range n {
+		item, ok := d.Dequeue()
+		if !ok {
+			t.Fatalf("Dequeue returned empty after %d items", len(out))
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func fill(q Queue[string], items ...string) {
+	for _, it := range items {
+		q.Enqueue(it)
+	}
+}
+
+// =============================================================================
+// Dispatcher Tests
+// =============================================================================
+
+func TestDispatcher_RoundRobin(t *testing.T) {
+	d := NewDispatcher[string]()
+	a, b := NewMPMC[string](8), NewMPMC[string](8)
+	d.Add("a", a, 1)
+	d.Add("b", b, 1)
+
+	fill(a, "a1", "a2", "a3")
+	fill(b, "b1")
+
+	got := drain(t, d, 4)
+	want := []string{"a1", "b1", "a2", "a3"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+	if _, ok := d.Dequeue(); ok {
+		t.Error("Dequeue on empty queues returned ok")
+	}
+}
+
+func TestDispatcher_Weighted(t *testing.T) {
+	d := NewDispatcher[string]()
+	heavy, light := NewMPMC[string](16), NewMPMC[string](16)
+	d.Add("heavy", heavy, 3)
+	d.Add("light", light, 1)
+
+	fill(heavy, "h", "h", "h", "h", "h", "h")
+	fill(light, "l", "l", "l")
+
+	got := drain(t, d, 8)
+	counts := map[string]int{}
+	for _, it := range got {
+		counts[it]++
+	}
+	if counts["h"] != 6 || counts["l"] != 2 {
+		t.Errorf("first 8 items = %v, want 6 heavy and 2 light", got)
+	}
+}
+
+func TestDispatcher_StarvationGuard(t *testing.T) {
+	d := NewDispatcher[string](WithMaxSkip(2))
+	hog, small := NewMPMC[string](64), NewMPMC[string](8)
+	d.Add("hog", hog, 100)
+	d.Add("small", small, 1)
+
+	for range 20 {
+		hog.Enqueue("h")
+	}
+	small.Enqueue("s")
+
+	got := drain(t, d, 4)
+	found := false
+	for _, it := range got {
+		if it == "s" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("small queue starved: %v", got)
+	}
+}
+
+func TestDispatcher_AddRemoveStats(t *testing.T) {
+	d := NewDispatcher[string]()
+	a := NewMPMC[string](8)
+	if err := d.Add("a", a, 0); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := d.Add("a", a, 1); !errors.Is(err, ErrDuplicateQueue) {
+		t.Errorf("duplicate Add err = %v, want ErrDuplicateQueue", err)
+	}
+
+	fill(a, "x", "y")
+	d.Dequeue()
+
+	stats := d.Stats()
+	if len(stats) != 1 || stats[0].Weight != 1 || stats[0].Depth != 1 || stats[0].Dequeued != 1 {
+		t.Errorf("Stats = %+v", stats)
+	}
+
+	if !d.Remove("a") || d.Remove("a") {
+		t.Error("Remove should succeed once")
+	}
+	if d.Len() != 0 {
+		t.Errorf("Len = %d, want 0", d.Len())
+	}
+	if _, ok := d.Dequeue(); ok {
+		t.Error("Dequeue with no queues returned ok")
+	}
+}
+
+func TestDispatcher_Meter(t *testing.T) {
+	m := metrics.NewMemory()
+	d := NewDispatcher[string](WithMeter(m))
+	a, b := NewMPMC[string](8), NewMPMC[string](8)
+	d.Add("a", a, 1)
+	d.Add("b", b, 1)
+	fill(a, "a1", "a2", "a3")
+	fill(b, "b1")
+
+	drain(t, d, 3)
+
+	if got := m.CounterValue("queue.dispatcher.dequeued", metrics.L("queue", "a")); got != 2 {
+		t.Errorf("dequeued{a} = %d, want 2", got)
+	}
+	if got := m.CounterValue("queue.dispatcher.dequeued", metrics.L("queue", "b")); got != 1 {
+		t.Errorf("dequeued{b} = %d, want 1", got)
+	}
+	if got := m.GaugeValue("queue.dispatcher.depth", metrics.L("queue", "a")); got != 1 {
+		t.Errorf("depth{a} = %v, want 1", got)
+	}
+}
+
+func TestDispatcher_Concurrent(t *testing.T) {
+	d := NewDispatcher[int](WithMaxSkip(8))
+	const perQueue = 1000
+	queues := make([]*MPMC[int], 4)
+	for i := range queues {
+		queues[i] = NewMPMC[int](2048)
+		d.Add(string(rune('a'+i)), queues[i], i+1)
+		for j := range perQueue {
+			queues[i].Enqueue(j)
+		}
+	}
+
+	var mu sync.Mutex
+	total := 0
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if _, ok := d.Dequeue(); !ok {
+					return
+				}
+				mu.Lock()
+				total++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if total != 4*perQueue {
+		t.Errorf("dequeued %d items, want %d", total, 4*perQueue)
+	}
+}
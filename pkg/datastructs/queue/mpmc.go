@@ -3,13 +3,15 @@ package queue
 import (
 	"math/bits"
 	"runtime"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	pkgRuntime "github.com/huynhanx03/go-common/pkg/runtime"
 	"github.com/huynhanx03/go-common/pkg/utils"
 )
 
-var _ Queue[int] = (*MPMC[int])(nil)
+var _ Closable[int] = (*MPMC[int])(nil)
 
 const (
 	cacheLineSize = 64
@@ -19,6 +21,16 @@ const (
 	// Passive spin: yield to scheduler.
 	activeSpinCycles = 4  // Number of PAUSE cycles per active spin iteration
 	activeSpinTries  = 30 // Max active spin iterations before yielding
+
+	// consumeChunk is the most items ConsumeBatch hands to its callback at once.
+	consumeChunk = 64
+
+	// batchWaitPoll caps each sleep of DequeueBatchWait between polls.
+	batchWaitPoll = time.Millisecond
+
+	// closedBit marks the head closed. Setting it makes every later head
+	// CAS fail, so no producer can claim a slot after Close.
+	closedBit = 1 << 63
 )
 
 type slot[T any] struct {
@@ -36,13 +48,20 @@ type MPMC[T any] struct {
 
 	_ [cacheLineSize]byte // Padding to prevent false sharing
 
-	head atomic.Uint64 // Head position
+	head atomic.Uint64 // Head position, with closedBit once closed
 
 	_ [cacheLineSize]byte // Padding to prevent false sharing
 
 	tail atomic.Uint64 // Tail position
 
+	// cleared is the generation boundary set by Clear: items at positions
+	// below it were enqueued before a Clear and are discarded, not
+	// returned, by whichever consumer claims them.
+	cleared atomic.Uint64
+
 	// _ [cacheLineSize]byte // Padding to prevent false sharing
+
+	scratch sync.Pool // *[]T chunks reused by ConsumeBatch
 }
 
 // NewMPMC creates a queue with capacity rounded up to power of 2.
@@ -69,10 +88,18 @@ func NewMPMC[T any](capacity int) *MPMC[T] {
 func (q *MPMC[T]) idx(pos uint64) uint64  { return pos & q.mask }
 func (q *MPMC[T]) turn(pos uint64) uint64 { return pos >> q.capacityLog2 }
 
-// Enqueue adds an item. Returns false if queue is full.
+// Enqueue adds an item. Returns false if queue is full or closed.
 func (q *MPMC[T]) Enqueue(item T) bool {
+	return q.TryEnqueue(item) == nil
+}
+
+// TryEnqueue adds an item. Returns ErrFull or ErrClosed on failure.
+func (q *MPMC[T]) TryEnqueue(item T) error {
 	for spin := 0; ; spin++ {
 		head := q.head.Load()
+		if head&closedBit != 0 {
+			return ErrClosed
+		}
 		idx := q.idx(head)
 		expectedTurn := q.turn(head) * 2
 
@@ -80,11 +107,11 @@ func (q *MPMC[T]) Enqueue(item T) bool {
 			if q.head.CompareAndSwap(head, head+1) {
 				q.slots[idx].data = item
 				q.slots[idx].turn.Store(expectedTurn + 1)
-				return true
+				return nil
 			}
 		} else {
 			if head == q.head.Load() {
-				return false
+				return ErrFull
 			}
 		}
 
@@ -108,6 +135,9 @@ func (q *MPMC[T]) Dequeue() (T, bool) {
 
 		if q.slots[idx].turn.Load() == expectedTurn {
 			if q.tail.CompareAndSwap(tail, tail+1) {
+				if q.discardCleared(tail, 1) > 0 {
+					continue
+				}
 				data := q.slots[idx].data
 				q.slots[idx].data = zero
 				q.slots[idx].turn.Store(expectedTurn + 1)
@@ -129,6 +159,30 @@ func (q *MPMC[T]) Dequeue() (T, bool) {
 	}
 }
 
+// TryDequeue removes and returns an item. Returns ErrEmpty if the queue is
+// empty, or ErrClosed if it is also closed and every item claimed before
+// Close has been dequeued.
+func (q *MPMC[T]) TryDequeue() (T, error) {
+	// Read the head first: once closed it is final, so a failed Dequeue
+	// with the tail caught up to it means nothing is left.
+	head := q.head.Load()
+	if item, ok := q.Dequeue(); ok {
+		return item, nil
+	}
+	var zero T
+	if head&closedBit != 0 && q.tail.Load() >= head&^closedBit {
+		return zero, ErrClosed
+	}
+	return zero, ErrEmpty
+}
+
+// Close stops the queue accepting items; Enqueue fails from then on while
+// Dequeue keeps returning what is left. Safe to call more than once.
+func (q *MPMC[T]) Close() { q.head.Or(closedBit) }
+
+// IsClosed reports whether Close has been called.
+func (q *MPMC[T]) IsClosed() bool { return q.head.Load()&closedBit != 0 }
+
 // EnqueueBatch adds multiple items. Returns count of items enqueued.
 func (q *MPMC[T]) EnqueueBatch(items []T) int {
 	count := 0
@@ -155,9 +209,130 @@ func (q *MPMC[T]) DequeueBatch(out []T) int {
 	return count
 }
 
+// DequeueBatchWait removes items into out until out is full or maxWait has
+// passed, whichever comes first, and returns the count dequeued. It is the
+// "up to N items or T time" collect of batching consumers: items already
+// queued are taken at once, and while the queue is empty it polls, backing
+// off like ConsumeLoop, until more arrive or the deadline passes. It returns
+// early once the queue is closed and drained. A maxWait <= 0 does not wait,
+// like DequeueBatch.
+func (q *MPMC[T]) DequeueBatchWait(out []T, maxWait time.Duration) int {
+	count := q.DequeueBatch(out)
+	if count == len(out) || maxWait <= 0 {
+		return count
+	}
+
+	deadline := time.Now().Add(maxWait)
+	for polls := 0; count < len(out); {
+		item, err := q.TryDequeue()
+		if err == nil {
+			out[count] = item
+			count++
+			polls = 0
+			continue
+		}
+		if err == ErrClosed {
+			break
+		}
+		left := time.Until(deadline)
+		if left <= 0 {
+			break
+		}
+		pause(polls, min(batchWaitPoll, left))
+		polls++
+	}
+	return count
+}
+
+// ConsumeBatch dequeues up to max items and passes them to fn in order, in
+// chunks of at most 64. The whole run is claimed with a single CAS on the
+// tail, instead of one per item as with DequeueBatch, and the chunks come
+// from a reused scratch buffer, so draining allocates nothing.
+//
+// Slots are padded to a cache line each, so items are not contiguous in the
+// ring: each is copied once into the chunk and its slot is released for
+// producers right away. The slice passed to fn is only valid during the call.
+// Returns the number of items consumed.
+func (q *MPMC[T]) ConsumeBatch(max int, fn func([]T)) int {
+	if max <= 0 {
+		return 0
+	}
+	var start, n uint64
+	for {
+		start, n = q.claim(uint64(max))
+		if n == 0 {
+			return 0
+		}
+		skip := q.discardCleared(start, n)
+		if start, n = start+skip, n-skip; n > 0 {
+			break
+		}
+	}
+
+	// The pool holds the *[]T itself, so handing it back costs nothing.
+	p, ok := q.scratch.Get().(*[]T)
+	if !ok {
+		buf := make([]T, 0, consumeChunk)
+		p = &buf
+	}
+	chunk := (*p)[:0]
+
+	var zero T
+	for pos := start; pos < start+n; pos++ {
+		s := &q.slots[q.idx(pos)]
+		chunk = append(chunk, s.data)
+		s.data = zero
+		s.turn.Store(q.turn(pos)*2 + 2)
+
+		if len(chunk) == consumeChunk {
+			fn(chunk)
+			chunk = chunk[:0]
+		}
+	}
+	if len(chunk) > 0 {
+		fn(chunk)
+	}
+
+	clear(chunk[:cap(chunk)]) // drop references held by the scratch buffer
+	*p = chunk
+	q.scratch.Put(p)
+	return int(n)
+}
+
+// claim reserves the run of up to max published items at the tail.
+// Returns the first position and the run length, 0 if the queue is empty.
+func (q *MPMC[T]) claim(max uint64) (start, n uint64) {
+	for spin := 0; ; spin++ {
+		tail := q.tail.Load()
+
+		// A published slot stays published until the consumer that owns
+		// its position releases it, so the run cannot shrink before the CAS
+		// unless the tail moves, which fails the CAS.
+		n = 0
+		for n < max && n < q.capacity && q.slots[q.idx(tail+n)].turn.Load() == q.turn(tail+n)*2+1 {
+			n++
+		}
+
+		if n > 0 {
+			if q.tail.CompareAndSwap(tail, tail+n) {
+				return tail, n
+			}
+		} else if tail == q.tail.Load() {
+			return 0, 0
+		}
+
+		if spin < activeSpinTries {
+			pkgRuntime.Procyield(activeSpinCycles)
+		} else {
+			runtime.Gosched()
+			spin = 0
+		}
+	}
+}
+
 // Size returns approximate item count (may be negative during concurrent access).
 func (q *MPMC[T]) Size() int64 {
-	return int64(q.head.Load()) - int64(q.tail.Load())
+	return int64(q.head.Load()&^closedBit) - int64(q.tail.Load())
 }
 
 // IsEmpty returns true if queue appears empty.
@@ -169,11 +344,61 @@ func (q *MPMC[T]) IsFull() bool { return q.Size() >= int64(q.capacity) }
 // Capacity returns maximum queue size.
 func (q *MPMC[T]) Capacity() uint64 { return q.capacity }
 
-// Clear drains all items from the queue.
+// Clear discards every item enqueued before it, and only those: items
+// enqueued after Clear starts stay queued, in order. It is safe to call
+// while producers and consumers run. Items enqueued concurrently with Clear
+// may fall on either side; a Dequeue racing Clear may still return an item
+// from before it, as though it ran first.
+//
+// Clear records the head as a generation boundary, so consumers drop any
+// item below it instead of returning it, then drains up to the boundary,
+// waiting for producers that claimed a slot before it to publish. When it
+// returns, no item from before it is left.
 func (q *MPMC[T]) Clear() {
+	mark := q.head.Load() &^ closedBit
 	for {
-		if _, ok := q.Dequeue(); !ok {
+		cur := q.cleared.Load()
+		if cur >= mark || q.cleared.CompareAndSwap(cur, mark) {
 			break
 		}
 	}
+
+	for spin := 0; ; spin++ {
+		tail := q.tail.Load()
+		if tail >= mark {
+			return
+		}
+		// Claim one position at a time so the drain never passes the mark.
+		if q.slots[q.idx(tail)].turn.Load() == q.turn(tail)*2+1 {
+			if q.tail.CompareAndSwap(tail, tail+1) {
+				q.discardCleared(tail, 1)
+				continue
+			}
+		}
+
+		if spin < activeSpinTries {
+			pkgRuntime.Procyield(activeSpinCycles)
+		} else {
+			runtime.Gosched()
+			spin = 0
+		}
+	}
+}
+
+// discardCleared releases the claimed run of n items at start that fall
+// below the Clear boundary, dropping their data. Returns how many it
+// released; they always form a prefix of the run.
+func (q *MPMC[T]) discardCleared(start, n uint64) uint64 {
+	mark := q.cleared.Load()
+	if start >= mark {
+		return 0
+	}
+	n = min(n, mark-start)
+	var zero T
+	for pos := start; pos < start+n; pos++ {
+		s := &q.slots[q.idx(pos)]
+		s.data = zero
+		s.turn.Store(q.turn(pos)*2 + 2)
+	}
+	return n
 }
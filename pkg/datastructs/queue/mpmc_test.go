@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -380,6 +381,216 @@ func TestDequeueBatch_FIFOOrder(t *testing.T) {
 	}
 }
 
+func TestDequeueBatchWait(t *testing.T) {
+	t.Run("full_returns_at_once", func(t *testing.T) {
+		q := NewMPMC[int](8)
+		q.EnqueueBatch([]int{1, 2, 3, 4})
+
+		out := make([]int, 3)
+		start := time.Now()
+		if got := q.DequeueBatchWait(out, time.Second); got != 3 {
+			t.Fatalf("DequeueBatchWait() = %d, want 3", got)
+		}
+		if d := time.Since(start); d > 100*time.Millisecond {
+			t.Errorf("full batch took %v", d)
+		}
+		if out[0] != 1 || out[2] != 3 {
+			t.Errorf("out = %v, want [1 2 3]", out)
+		}
+	})
+
+	t.Run("deadline_returns_partial", func(t *testing.T) {
+		q := NewMPMC[int](8)
+		q.Enqueue(1)
+
+		out := make([]int, 4)
+		start := time.Now()
+		if got := q.DequeueBatchWait(out, 20*time.Millisecond); got != 1 {
+			t.Fatalf("DequeueBatchWait() = %d, want 1", got)
+		}
+		if d := time.Since(start); d < 20*time.Millisecond {
+			t.Errorf("returned after %v, before maxWait", d)
+		}
+	})
+
+	t.Run("collects_late_items", func(t *testing.T) {
+		q := NewMPMC[int](8)
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			for i := range 4 {
+				time.Sleep(2 * time.Millisecond)
+				q.Enqueue(i)
+			}
+		})
+
+		out := make([]int, 4)
+		got := q.DequeueBatchWait(out, 5*time.Second)
+		wg.Wait()
+		if got != 4 {
+			t.Fatalf("DequeueBatchWait() = %d, want 4", got)
+		}
+		for i, v := range out {
+			if v != i {
+				t.Errorf("out[%d] = %d, want %d", i, v, i)
+			}
+		}
+	})
+
+	t.Run("closed_returns_early", func(t *testing.T) {
+		q := NewMPMC[int](8)
+		q.Enqueue(1)
+		q.Close()
+
+		start := time.Now()
+		if got := q.DequeueBatchWait(make([]int, 4), 5*time.Second); got != 1 {
+			t.Fatalf("DequeueBatchWait() = %d, want 1", got)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("closed queue waited %v", d)
+		}
+	})
+
+	t.Run("no_wait", func(t *testing.T) {
+		q := NewMPMC[int](8)
+		if got := q.DequeueBatchWait(make([]int, 4), 0); got != 0 {
+			t.Errorf("DequeueBatchWait(0) = %d, want 0", got)
+		}
+		if got := q.DequeueBatchWait(nil, time.Second); got != 0 {
+			t.Errorf("DequeueBatchWait(nil) = %d, want 0", got)
+		}
+	})
+}
+
+// =============================================================================
+// ConsumeBatch Tests
+// =============================================================================
+
+func TestConsumeBatch(t *testing.T) {
+	q := NewMPMC[int](256)
+	for i := range 200 {
+		q.Enqueue(i)
+	}
+
+	var got []int
+	var chunks []int
+	n := q.ConsumeBatch(150, func(items []int) {
+		chunks = append(chunks, len(items))
+		got = append(got, items...)
+	})
+	if n != 150 {
+		t.Fatalf("ConsumeBatch(150) = %d, want 150", n)
+	}
+	if len(chunks) != 3 || chunks[0] != 64 || chunks[1] != 64 || chunks[2] != 22 {
+		t.Errorf("chunk sizes = %v, want [64 64 22]", chunks)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("item %d = %d, want FIFO order", i, v)
+		}
+	}
+
+	// The rest, then an empty queue.
+	if n := q.ConsumeBatch(1000, func([]int) {}); n != 50 {
+		t.Errorf("ConsumeBatch(rest) = %d, want 50", n)
+	}
+	if n := q.ConsumeBatch(10, func([]int) { t.Error("fn called on empty queue") }); n != 0 {
+		t.Errorf("ConsumeBatch(empty) = %d, want 0", n)
+	}
+	if n := q.ConsumeBatch(0, func([]int) {}); n != 0 {
+		t.Errorf("ConsumeBatch(0) = %d, want 0", n)
+	}
+}
+
+func TestConsumeBatch_ReleasesSlots(t *testing.T) {
+	q := NewMPMC[*int](4)
+	for range 4 {
+		v := 1
+		q.Enqueue(&v)
+	}
+	q.ConsumeBatch(4, func([]*int) {})
+
+	// Every slot is writable again and no longer references the items.
+	for i := range q.slots {
+		if q.slots[i].data != nil {
+			t.Errorf("slot %d still holds its item", i)
+		}
+	}
+	if got := q.EnqueueBatch(make([]*int, 4)); got != 4 {
+		t.Errorf("EnqueueBatch after ConsumeBatch = %d, want 4", got)
+	}
+}
+
+func TestConsumeBatch_NoAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector drops sync.Pool items at random")
+	}
+	q := NewMPMC[int](256)
+	q.ConsumeBatch(1, func([]int) {}) // warm the scratch pool
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := range 100 {
+			q.Enqueue(i)
+		}
+		q.ConsumeBatch(100, func([]int) {})
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per drain = %v, want 0", allocs)
+	}
+}
+
+func TestConsumeBatch_Concurrent(t *testing.T) {
+	const producers, perProducer = 4, 5000
+	q := NewMPMC[int](128)
+
+	var sum, count atomic.Int64
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	for range 3 {
+		wg.Go(func() {
+			for {
+				n := q.ConsumeBatch(32, func(items []int) {
+					for _, v := range items {
+						sum.Add(int64(v))
+					}
+				})
+				count.Add(int64(n))
+				if n == 0 {
+					select {
+					case <-done:
+						return
+					default:
+					}
+				}
+			}
+		})
+	}
+
+	var pg sync.WaitGroup
+	for p := range producers {
+		pg.Go(func() {
+			for i := range perProducer {
+				for !q.Enqueue(p*perProducer + i) {
+				}
+			}
+		})
+	}
+	pg.Wait()
+	for !q.IsEmpty() {
+		time.Sleep(time.Millisecond)
+	}
+	close(done)
+	wg.Wait()
+
+	total := producers * perProducer
+	if count.Load() != int64(total) {
+		t.Fatalf("consumed %d items, want %d", count.Load(), total)
+	}
+	if want := int64(total) * int64(total-1) / 2; sum.Load() != want {
+		t.Errorf("sum = %d, want %d", sum.Load(), want)
+	}
+}
+
 // =============================================================================
 // Size Tests
 // =============================================================================
@@ -533,6 +744,130 @@ func TestClear(t *testing.T) {
 	})
 }
 
+func TestClear_Concurrent(t *testing.T) {
+	q := NewMPMC[int](64)
+	const n = 20_000
+
+	var enqueued atomic.Int64 // highest value whose Enqueue returned
+	var clearedUpTo atomic.Int64
+	enqueued.Store(-1)
+	clearedUpTo.Store(-1)
+	var producing atomic.Bool
+	producing.Store(true)
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		defer producing.Store(false)
+		for i := range n {
+			for !q.Enqueue(i) {
+				runtime.Gosched()
+			}
+			enqueued.Store(int64(i))
+		}
+	})
+	wg.Go(func() {
+		for producing.Load() {
+			before := enqueued.Load()
+			q.Clear()
+			clearedUpTo.Store(before)
+			runtime.Gosched()
+		}
+	})
+
+	last := -1
+	for producing.Load() || !q.IsEmpty() {
+		floor := clearedUpTo.Load()
+		v, ok := q.Dequeue()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		if int64(v) <= floor {
+			t.Fatalf("dequeued %d, enqueued before a Clear that discarded up to %d", v, floor)
+		}
+		if v <= last {
+			t.Fatalf("dequeued %d after %d: order broken", v, last)
+		}
+		last = v
+	}
+	wg.Wait()
+
+	q.Clear()
+	if s := q.Size(); s != 0 {
+		t.Errorf("Size() after final Clear = %d, want 0", s)
+	}
+}
+
+func TestClear_ConsumeBatchSkipsCleared(t *testing.T) {
+	q := NewMPMC[int](16)
+	for i := range 4 {
+		q.Enqueue(i)
+	}
+	// Mark the first four cleared without draining them, as a Clear
+	// racing this consumer would.
+	q.cleared.Store(4)
+	q.Enqueue(100)
+
+	var got []int
+	n := q.ConsumeBatch(10, func(items []int) { got = append(got, items...) })
+	if n != 1 || len(got) != 1 || got[0] != 100 {
+		t.Errorf("ConsumeBatch = %d, %v; want 1, [100]", n, got)
+	}
+	if !q.IsEmpty() {
+		t.Errorf("Size() = %d, want 0", q.Size())
+	}
+}
+
+// =============================================================================
+// Close Tests
+// =============================================================================
+
+func TestClose(t *testing.T) {
+	q := NewMPMC[int](4)
+	q.Enqueue(1)
+	q.Enqueue(2)
+	q.Close()
+	q.Close() // no-op
+
+	if !q.IsClosed() {
+		t.Fatal("IsClosed() = false after Close")
+	}
+	if q.Enqueue(3) {
+		t.Error("Enqueue after Close should fail")
+	}
+	if err := q.TryEnqueue(3); err != ErrClosed {
+		t.Errorf("TryEnqueue after Close = %v, want ErrClosed", err)
+	}
+	if s := q.Size(); s != 2 {
+		t.Errorf("Size() = %d, want 2", s)
+	}
+
+	for want := 1; want <= 2; want++ {
+		v, err := q.TryDequeue()
+		if err != nil || v != want {
+			t.Fatalf("TryDequeue() = (%d, %v), want (%d, nil)", v, err, want)
+		}
+	}
+	if _, err := q.TryDequeue(); err != ErrClosed {
+		t.Errorf("TryDequeue on drained queue = %v, want ErrClosed", err)
+	}
+}
+
+func TestTryEnqueueDequeue_Open(t *testing.T) {
+	q := NewMPMC[int](2)
+	if _, err := q.TryDequeue(); err != ErrEmpty {
+		t.Errorf("TryDequeue on empty queue = %v, want ErrEmpty", err)
+	}
+	for i := range 2 {
+		if err := q.TryEnqueue(i); err != nil {
+			t.Fatalf("TryEnqueue(%d) = %v", i, err)
+		}
+	}
+	if err := q.TryEnqueue(2); err != ErrFull {
+		t.Errorf("TryEnqueue on full queue = %v, want ErrFull", err)
+	}
+}
+
 // =============================================================================
 // Concurrency Tests
 // =============================================================================
@@ -682,6 +1017,89 @@ func TestConcurrency_MixedProducerConsumer(t *testing.T) {
 	}
 }
 
+func TestConcurrency_CloseDrain(t *testing.T) {
+	q := NewMPMC[int](64)
+
+	const producers, consumers, itemsPerProducer = 4, 4, 2000
+	var produced, consumed atomic.Int64
+
+	var pwg, cwg sync.WaitGroup
+	for range producers {
+		pwg.Go(func() {
+			for i := range itemsPerProducer {
+				for q.TryEnqueue(i) == ErrFull {
+					runtime.Gosched()
+				}
+				produced.Add(1)
+			}
+		})
+	}
+	for range consumers {
+		cwg.Go(func() {
+			for {
+				_, err := q.TryDequeue()
+				switch err {
+				case nil:
+					consumed.Add(1)
+				case ErrEmpty:
+					runtime.Gosched()
+				case ErrClosed:
+					return
+				}
+			}
+		})
+	}
+
+	pwg.Wait()
+	q.Close()
+	cwg.Wait()
+
+	if got, want := consumed.Load(), produced.Load(); got != want {
+		t.Errorf("consumed %d, produced %d", got, want)
+	}
+	if want := int64(producers * itemsPerProducer); produced.Load() != want {
+		t.Errorf("produced %d, want %d", produced.Load(), want)
+	}
+}
+
+func TestConcurrency_CloseWhileProducing(t *testing.T) {
+	q := NewMPMC[int](64)
+
+	var produced, consumed atomic.Int64
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for {
+				switch q.TryEnqueue(1) {
+				case nil:
+					produced.Add(1)
+				case ErrClosed:
+					return
+				}
+			}
+		})
+		wg.Go(func() {
+			for {
+				_, err := q.TryDequeue()
+				if err == nil {
+					consumed.Add(1)
+				} else if err == ErrClosed {
+					return
+				}
+			}
+		})
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	q.Close()
+	wg.Wait()
+
+	// Every item that made it in before Close must come out.
+	if got, want := consumed.Load(), produced.Load(); got != want {
+		t.Errorf("consumed %d, produced %d", got, want)
+	}
+}
+
 // =============================================================================
 // Generic Type Tests
 // =============================================================================
@@ -0,0 +1,229 @@
+package queue
+
+import (
+	"sync"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+)
+
+// PriorityOption configures a Priority queue.
+type PriorityOption func(*priorityConfig)
+
+type priorityConfig struct {
+	slope    float64
+	maxBoost float64
+	meter    metrics.Meter
+}
+
+// WithAging raises an item's effective priority by slope levels per second
+// it waits, so low-priority items eventually outrank a steady stream of
+// fresh high-priority ones: with n levels, an item at level 0 is served
+// ahead of new top-level items after waiting (n-1)/slope seconds.
+// maxBoost caps the rise, keeping aged items below levels more than
+// maxBoost above their own; 0 leaves it unbounded. slope <= 0 disables
+// aging.
+func WithAging(slope, maxBoost float64) PriorityOption {
+	return func(c *priorityConfig) {
+		if slope > 0 {
+			c.slope = slope
+		}
+		if maxBoost > 0 {
+			c.maxBoost = maxBoost
+		}
+	}
+}
+
+// WithPriorityMeter reports to m: the wait of every dequeued item as
+// "queue.priority.wait_seconds", the longest wait observed so far as
+// "queue.priority.max_wait_seconds", and "queue.priority.aged" for
+// dequeues where aging put an item ahead of a higher level.
+func WithPriorityMeter(m metrics.Meter) PriorityOption {
+	return func(c *priorityConfig) { c.meter = m }
+}
+
+// PriorityStats reports Priority queue metrics.
+type PriorityStats struct {
+	Depth    int64
+	Dequeued uint64
+	Aged     uint64        // dequeues won by aging over a higher level
+	MaxWait  time.Duration // longest wait of a dequeued item
+}
+
+// prioritized is a queued item and when it was enqueued.
+type prioritized[T any] struct {
+	item T
+	at   time.Time
+}
+
+// fifo is one priority level: a slice consumed from head, compacted once
+// half of it is spent.
+type fifo[T any] struct {
+	items []prioritized[T]
+	head  int
+}
+
+func (f *fifo[T]) len() int { return len(f.items) - f.head }
+
+func (f *fifo[T]) push(p prioritized[T]) { f.items = append(f.items, p) }
+
+func (f *fifo[T]) peek() *prioritized[T] { return &f.items[f.head] }
+
+func (f *fifo[T]) pop() prioritized[T] {
+	p := f.items[f.head]
+	f.items[f.head] = prioritized[T]{}
+	f.head++
+	if f.head == len(f.items) {
+		f.items, f.head = f.items[:0], 0
+	} else if f.head >= len(f.items)/2 {
+		n := copy(f.items, f.items[f.head:])
+		clear(f.items[n:])
+		f.items, f.head = f.items[:n], 0
+	}
+	return p
+}
+
+// Priority is a bounded queue with integer priority levels; higher levels
+// are dequeued first and items of one level in FIFO order. With WithAging,
+// waiting raises an item's effective priority so lower levels are not
+// starved. Dequeue compares the head of every level, so it costs O(levels).
+// It is safe for concurrent use.
+type Priority[T any] struct {
+	mu       sync.Mutex
+	levels   []fifo[T]
+	size     int
+	capacity int
+	slope    float64
+	maxBoost float64
+	now      func() time.Time
+
+	dequeued uint64
+	aged     uint64
+	maxWait  time.Duration
+
+	waitHist     metrics.Histogram
+	maxWaitGauge metrics.Gauge
+	agedCounter  metrics.Counter
+}
+
+var _ Queue[int] = (*Priority[int])(nil)
+
+// NewPriority creates a queue of up to capacity items over levels priority
+// levels, 0 the lowest. Both are at least 1.
+func NewPriority[T any](levels, capacity int, opts ...PriorityOption) *Priority[T] {
+	var cfg priorityConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	m := metrics.OrNoop(cfg.meter)
+	return &Priority[T]{
+		levels:   make([]fifo[T], max(levels, 1)),
+		capacity: max(capacity, 1),
+		slope:    cfg.slope,
+		maxBoost: cfg.maxBoost,
+		now:      time.Now,
+
+		waitHist:     m.Histogram("queue.priority.wait_seconds", "Time items spent queued"),
+		maxWaitGauge: m.Gauge("queue.priority.max_wait_seconds", "Longest time an item spent queued"),
+		agedCounter:  m.Counter("queue.priority.aged", "Dequeues won by aging over a higher level"),
+	}
+}
+
+// Enqueue adds item at the lowest priority, so a Priority queue can stand
+// in for any Queue. Returns false if the queue is full.
+func (q *Priority[T]) Enqueue(item T) bool {
+	return q.Push(item, 0)
+}
+
+// Push adds item at the given priority, clamped to the configured levels.
+// Returns false if the queue is full.
+func (q *Priority[T]) Push(item T, priority int) bool {
+	priority = min(max(priority, 0), len(q.levels)-1)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.size == q.capacity {
+		return false
+	}
+	q.levels[priority].push(prioritized[T]{item: item, at: q.now()})
+	q.size++
+	return true
+}
+
+// Dequeue removes the item with the highest effective priority, preferring
+// the higher level on ties. Returns (zero, false) if the queue is empty.
+func (q *Priority[T]) Dequeue() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var zero T
+	if q.size == 0 {
+		return zero, false
+	}
+
+	now := q.now()
+	best, top := -1, -1
+	var bestScore float64
+	for level := len(q.levels) - 1; level >= 0; level-- {
+		if q.levels[level].len() == 0 {
+			continue
+		}
+		if top < 0 {
+			top = level
+		}
+		score := float64(level) + q.boost(now.Sub(q.levels[level].peek().at))
+		if best < 0 || score > bestScore {
+			best, bestScore = level, score
+		}
+		if q.slope == 0 {
+			break // no aging: the highest non-empty level wins
+		}
+	}
+
+	p := q.levels[best].pop()
+	q.size--
+	q.dequeued++
+	if best != top {
+		q.aged++
+		q.agedCounter.Add(1)
+	}
+
+	wait := now.Sub(p.at)
+	q.waitHist.Record(wait.Seconds())
+	if wait > q.maxWait {
+		q.maxWait = wait
+		q.maxWaitGauge.Set(wait.Seconds())
+	}
+	return p.item, true
+}
+
+// boost is the priority an item gains by waiting wait.
+func (q *Priority[T]) boost(wait time.Duration) float64 {
+	b := q.slope * wait.Seconds()
+	if q.maxBoost > 0 && b > q.maxBoost {
+		return q.maxBoost
+	}
+	return b
+}
+
+// Size returns the number of queued items.
+func (q *Priority[T]) Size() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(q.size)
+}
+
+// Capacity returns the maximum number of queued items.
+func (q *Priority[T]) Capacity() uint64 { return uint64(q.capacity) }
+
+// Stats returns the queue metrics.
+func (q *Priority[T]) Stats() PriorityStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return PriorityStats{
+		Depth:    int64(q.size),
+		Dequeued: q.dequeued,
+		Aged:     q.aged,
+		MaxWait:  q.maxWait,
+	}
+}
@@ -0,0 +1,159 @@
+package queue
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+)
+
+// fakeClock is a manually advanced clock for aging tests.
+type fakeClock struct{ t time.Time }
+
+func newFakeClock() *fakeClock { return &fakeClock{t: time.Unix(0, 0)} }
+
+func (c *fakeClock) now() time.Time      { return c.t }
+func (c *fakeClock) add(d time.Duration) { c.t = c.t.Add(d) }
+
+// popAll dequeues until the queue is empty and returns the items in order.
+func popAll(t *testing.T, q *Priority[string]) []string {
+	t.Helper()
+	var out []string
+	for {
+		item, ok := q.Dequeue()
+		if !ok {
+			return out
+		}
+		out = append(out, item)
+	}
+}
+
+func TestPriority_OrderWithoutAging(t *testing.T) {
+	q := NewPriority[string](3, 16)
+	q.Push("low1", 0)
+	q.Push("high1", 2)
+	q.Push("mid", 1)
+	q.Push("high2", 2)
+	q.Enqueue("low2")
+	q.Push("clamped", 99)
+
+	want := []string{"high1", "high2", "clamped", "mid", "low1", "low2"}
+	if got := popAll(t, q); !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestPriority_Capacity(t *testing.T) {
+	q := NewPriority[int](2, 2)
+	if !q.Push(1, 0) || !q.Push(2, 1) {
+		t.Fatal("Push failed below capacity")
+	}
+	if q.Push(3, 1) {
+		t.Error("Push succeeded on a full queue")
+	}
+	if q.Size() != 2 || q.Capacity() != 2 {
+		t.Errorf("Size, Capacity = %d, %d", q.Size(), q.Capacity())
+	}
+	q.Dequeue()
+	if !q.Push(3, 1) {
+		t.Error("Push failed after Dequeue freed a slot")
+	}
+}
+
+func TestPriority_AgingPreventsStarvation(t *testing.T) {
+	clock := newFakeClock()
+	q := NewPriority[string](3, 64, WithAging(1, 0)) // one level per second
+	q.now = clock.now
+
+	q.Push("old-low", 0)
+	clock.add(1500 * time.Millisecond)
+	q.Push("fresh-high", 2)
+
+	// old-low scores 0+1.5 against fresh-high's 2: not yet.
+	if item, _ := q.Dequeue(); item != "fresh-high" {
+		t.Fatalf("Dequeue = %q, want fresh-high", item)
+	}
+
+	clock.add(time.Second)
+	q.Push("fresh-high", 2)
+	// old-low now scores 2.5.
+	if item, _ := q.Dequeue(); item != "old-low" {
+		t.Fatalf("Dequeue = %q, want old-low", item)
+	}
+
+	s := q.Stats()
+	if s.Dequeued != 2 || s.Aged != 1 || s.Depth != 1 {
+		t.Errorf("Stats = %+v", s)
+	}
+	if s.MaxWait != 2500*time.Millisecond {
+		t.Errorf("MaxWait = %v, want 2.5s", s.MaxWait)
+	}
+}
+
+func TestPriority_MaxBoost(t *testing.T) {
+	clock := newFakeClock()
+	q := NewPriority[string](4, 64, WithAging(10, 1.5))
+	q.now = clock.now
+
+	q.Push("low", 0)
+	clock.add(time.Hour)
+	q.Push("mid", 1)
+	q.Push("top", 3)
+
+	// low is capped at 1.5: above a fresh mid, below top.
+	want := []string{"top", "low", "mid"}
+	if got := popAll(t, q); !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestPriority_Meter(t *testing.T) {
+	clock := newFakeClock()
+	m := metrics.NewMemory()
+	q := NewPriority[string](2, 8, WithAging(1, 0), WithPriorityMeter(m))
+	q.now = clock.now
+
+	q.Push("a", 0)
+	clock.add(3 * time.Second)
+	q.Push("b", 1)
+	q.Dequeue()
+	q.Dequeue()
+
+	if n, sum := m.HistogramValue("queue.priority.wait_seconds"); n != 2 || sum != 3 {
+		t.Errorf("wait_seconds = %d, %v, want 2, 3", n, sum)
+	}
+	if got := m.GaugeValue("queue.priority.max_wait_seconds"); got != 3 {
+		t.Errorf("max_wait_seconds = %v, want 3", got)
+	}
+	if got := m.CounterValue("queue.priority.aged"); got != 1 {
+		t.Errorf("aged = %d, want 1", got)
+	}
+}
+
+func TestPriority_Concurrent(t *testing.T) {
+	const producers, perProducer = 4, 1000
+	q := NewPriority[int](4, producers*perProducer, WithAging(100, 0))
+
+	var wg sync.WaitGroup
+	for p := range producers {
+		wg.Go(func() {
+			for i := range perProducer {
+				q.Push(i, p)
+			}
+		})
+	}
+	wg.Wait()
+
+	n := 0
+	for {
+		if _, ok := q.Dequeue(); !ok {
+			break
+		}
+		n++
+	}
+	if n != producers*perProducer {
+		t.Errorf("dequeued %d items, want %d", n, producers*perProducer)
+	}
+}
@@ -1,5 +1,19 @@
 package queue
 
+import "errors"
+
+var (
+	// ErrClosed is returned once a queue is closed: by TryEnqueue right
+	// away, and by TryDequeue after the remaining items are drained.
+	ErrClosed = errors.New("queue: closed")
+
+	// ErrFull is returned by TryEnqueue when the queue has no free slot.
+	ErrFull = errors.New("queue: full")
+
+	// ErrEmpty is returned by TryDequeue when an open queue has no item.
+	ErrEmpty = errors.New("queue: empty")
+)
+
 // Queue is a generic interface for FIFO queues.
 type Queue[T any] interface {
 	// Enqueue adds an item to the queue.
@@ -13,3 +27,20 @@ type Queue[T any] interface {
 	// Capacity returns the total capacity of the queue.
 	Capacity() uint64
 }
+
+// Closable is a Queue that producers can close, so consumers terminate
+// once it is drained without a separate done channel.
+type Closable[T any] interface {
+	Queue[T]
+
+	// Close stops the queue accepting items. Items already enqueued can
+	// still be dequeued. Closing twice is a no-op.
+	Close()
+
+	// TryEnqueue adds an item. Returns ErrFull or ErrClosed on failure.
+	TryEnqueue(item T) error
+
+	// TryDequeue removes an item. Returns ErrEmpty while the queue is open
+	// and has none, ErrClosed once it is closed and drained.
+	TryDequeue() (T, error)
+}
@@ -0,0 +1,228 @@
+package radix
+
+// Node kinds by child capacity. A node grows to the next kind when full and
+// shrinks back when sparse, so memory tracks fan-out (the "adaptive" in ART).
+const (
+	node4 uint8 = iota
+	node16
+	node48
+	node256
+)
+
+// Shrink thresholds. Below them a node moves to the next smaller kind; they
+// sit under the smaller kind's capacity so a node does not flap at the edge.
+const (
+	shrink256 = 40
+	shrink48  = 12
+	shrink16  = 3
+)
+
+// leaf holds a stored key and its value.
+type leaf[V any] struct {
+	key   []byte
+	value V
+}
+
+// node is a path-compressed inner node. Its children hang off single edge
+// bytes; prefix holds the bytes shared by every key below it, after the edge
+// byte that leads here. leaf is set when a key ends exactly at this node, so
+// keys may be prefixes of other keys without a terminator byte.
+//
+// Child storage depends on kind:
+//
+//   - node4, node16: keys and children are parallel, sorted by edge byte;
+//   - node48: keys is a 256-entry index holding slot+1 (0 = absent) into
+//     48 children slots;
+//   - node256: children is indexed directly by edge byte.
+type node[V any] struct {
+	prefix   []byte
+	leaf     *leaf[V]
+	kind     uint8
+	n        int // number of children
+	keys     []byte
+	children []*node[V]
+}
+
+func newLeafNode[V any](prefix []byte, l *leaf[V]) *node[V] {
+	return &node[V]{prefix: prefix, leaf: l}
+}
+
+// childRef returns the slot holding the child for edge b, or nil.
+func (n *node[V]) childRef(b byte) **node[V] {
+	switch n.kind {
+	case node4, node16:
+		for i := 0; i < n.n; i++ {
+			if n.keys[i] == b {
+				return &n.children[i]
+			}
+		}
+	case node48:
+		if slot := n.keys[b]; slot != 0 {
+			return &n.children[slot-1]
+		}
+	case node256:
+		if n.children[b] != nil {
+			return &n.children[b]
+		}
+	}
+	return nil
+}
+
+// child returns the child for edge b, or nil.
+func (n *node[V]) child(b byte) *node[V] {
+	if ref := n.childRef(b); ref != nil {
+		return *ref
+	}
+	return nil
+}
+
+// addChild adds c under edge b, which must not be present.
+func (n *node[V]) addChild(b byte, c *node[V]) {
+	if n.children == nil {
+		// Nodes start as leaves; storage is allocated on the first child.
+		n.keys = make([]byte, 0, 4)
+		n.children = make([]*node[V], 0, 4)
+	}
+
+	switch n.kind {
+	case node4, node16:
+		if n.n == cap(n.keys) {
+			n.grow()
+			n.addChild(b, c)
+			return
+		}
+		i := 0
+		for i < n.n && n.keys[i] < b {
+			i++
+		}
+		n.keys = n.keys[:n.n+1]
+		n.children = n.children[:n.n+1]
+		copy(n.keys[i+1:], n.keys[i:n.n])
+		copy(n.children[i+1:], n.children[i:n.n])
+		n.keys[i] = b
+		n.children[i] = c
+	case node48:
+		if n.n == 48 {
+			n.grow()
+			n.addChild(b, c)
+			return
+		}
+		slot := 0
+		for n.children[slot] != nil {
+			slot++
+		}
+		n.children[slot] = c
+		n.keys[b] = byte(slot + 1)
+	case node256:
+		n.children[b] = c
+	}
+	n.n++
+}
+
+// removeChild removes the child under edge b, which must be present.
+func (n *node[V]) removeChild(b byte) {
+	switch n.kind {
+	case node4, node16:
+		i := 0
+		for n.keys[i] != b {
+			i++
+		}
+		copy(n.keys[i:], n.keys[i+1:n.n])
+		copy(n.children[i:], n.children[i+1:n.n])
+		n.children[n.n-1] = nil
+		n.keys = n.keys[:n.n-1]
+		n.children = n.children[:n.n-1]
+	case node48:
+		n.children[n.keys[b]-1] = nil
+		n.keys[b] = 0
+	case node256:
+		n.children[b] = nil
+	}
+	n.n--
+	n.shrink()
+}
+
+// grow moves the children of a full node to the next larger kind.
+func (n *node[V]) grow() {
+	switch n.kind {
+	case node4:
+		n.resize(node16)
+	case node16:
+		n.resize(node48)
+	case node48:
+		n.resize(node256)
+	}
+}
+
+// shrink moves the children of a sparse node to the next smaller kind.
+func (n *node[V]) shrink() {
+	switch {
+	case n.kind == node256 && n.n < shrink256:
+		n.resize(node48)
+	case n.kind == node48 && n.n < shrink48:
+		n.resize(node16)
+	case n.kind == node16 && n.n < shrink16:
+		n.resize(node4)
+	}
+}
+
+// resize rebuilds the child storage as kind, keeping edge order.
+func (n *node[V]) resize(kind uint8) {
+	old := *n
+	n.kind = kind
+	n.n = 0
+	switch kind {
+	case node4:
+		n.keys = make([]byte, 0, 4)
+		n.children = make([]*node[V], 0, 4)
+	case node16:
+		n.keys = make([]byte, 0, 16)
+		n.children = make([]*node[V], 0, 16)
+	case node48:
+		n.keys = make([]byte, 256)
+		n.children = make([]*node[V], 48)
+	case node256:
+		n.keys = nil
+		n.children = make([]*node[V], 256)
+	}
+	old.each(func(b byte, c *node[V]) bool {
+		n.addChild(b, c)
+		return true
+	})
+}
+
+// each calls fn for every child in edge order until fn returns false.
+func (n *node[V]) each(fn func(b byte, c *node[V]) bool) bool {
+	switch n.kind {
+	case node4, node16:
+		for i := 0; i < n.n; i++ {
+			if !fn(n.keys[i], n.children[i]) {
+				return false
+			}
+		}
+	case node48:
+		for b, slot := range n.keys {
+			if slot != 0 && !fn(byte(b), n.children[slot-1]) {
+				return false
+			}
+		}
+	case node256:
+		for b, c := range n.children {
+			if c != nil && !fn(byte(b), c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// onlyChild returns the single child of a node with n == 1.
+func (n *node[V]) onlyChild() (byte, *node[V]) {
+	var edge byte
+	var only *node[V]
+	n.each(func(b byte, c *node[V]) bool {
+		edge, only = b, c
+		return false
+	})
+	return edge, only
+}
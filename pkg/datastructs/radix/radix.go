@@ -0,0 +1,233 @@
+// Package radix implements an adaptive radix tree (ART) over byte-string
+// keys. Lookups cost O(key length) independent of the number of keys, keys
+// are kept in byte order, and prefix scans and longest-prefix matches visit
+// only the matching subtree, which makes it suitable for routing tables and
+// key-prefix queries.
+package radix
+
+import "bytes"
+
+// Tree is an adaptive radix tree mapping []byte keys to values of type V.
+// Keys are copied on insert; the empty key is a valid key.
+// It is not safe for concurrent use.
+type Tree[V any] struct {
+	root *node[V]
+	size int
+}
+
+// New creates an empty tree.
+func New[V any]() *Tree[V] {
+	return &Tree[V]{}
+}
+
+// Len returns the number of keys.
+func (t *Tree[V]) Len() int {
+	return t.size
+}
+
+// Get returns the value stored under key.
+func (t *Tree[V]) Get(key []byte) (V, bool) {
+	n := t.root
+	for n != nil {
+		if !bytes.HasPrefix(key, n.prefix) {
+			break
+		}
+		key = key[len(n.prefix):]
+		if len(key) == 0 {
+			if n.leaf != nil {
+				return n.leaf.value, true
+			}
+			break
+		}
+		n = n.child(key[0])
+		key = key[1:]
+	}
+	var zero V
+	return zero, false
+}
+
+// Insert stores value under key. It reports whether the key is new; an
+// existing key has its value replaced.
+func (t *Tree[V]) Insert(key []byte, value V) bool {
+	k := bytes.Clone(key)
+	if k == nil {
+		k = []byte{}
+	}
+	l := &leaf[V]{key: k, value: value}
+
+	if t.root == nil {
+		t.root = newLeafNode(k, l)
+		t.size++
+		return true
+	}
+	added := insert(&t.root, k, l)
+	if added {
+		t.size++
+	}
+	return added
+}
+
+// insert adds l below *ref, where key is the part of l.key not yet consumed.
+// Prefixes are subslices of stored keys, which are never modified.
+func insert[V any](ref **node[V], key []byte, l *leaf[V]) bool {
+	for {
+		n := *ref
+		p := commonPrefix(n.prefix, key)
+
+		if p < len(n.prefix) {
+			// The key diverges inside the compressed path: split it.
+			parent := &node[V]{prefix: n.prefix[:p:p]}
+			edge := n.prefix[p]
+			n.prefix = n.prefix[p+1:]
+			parent.addChild(edge, n)
+			if p == len(key) {
+				parent.leaf = l
+			} else {
+				parent.addChild(key[p], newLeafNode(key[p+1:], l))
+			}
+			*ref = parent
+			return true
+		}
+
+		key = key[p:]
+		if len(key) == 0 {
+			if n.leaf != nil {
+				n.leaf.value = l.value
+				return false
+			}
+			n.leaf = l
+			return true
+		}
+
+		next := n.childRef(key[0])
+		if next == nil {
+			n.addChild(key[0], newLeafNode(key[1:], l))
+			return true
+		}
+		ref, key = next, key[1:]
+	}
+}
+
+// Delete removes key. It reports whether the key was present.
+func (t *Tree[V]) Delete(key []byte) bool {
+	if t.root == nil || !remove(&t.root, key) {
+		return false
+	}
+	t.size--
+	if t.root.leaf == nil && t.root.n == 0 {
+		t.root = nil
+	}
+	return true
+}
+
+// remove deletes key below *ref and compacts the path on the way back up:
+// empty children are dropped and a node left with a single child and no
+// value is merged into it.
+func remove[V any](ref **node[V], key []byte) bool {
+	n := *ref
+	if !bytes.HasPrefix(key, n.prefix) {
+		return false
+	}
+	key = key[len(n.prefix):]
+
+	if len(key) == 0 {
+		if n.leaf == nil {
+			return false
+		}
+		n.leaf = nil
+	} else {
+		next := n.childRef(key[0])
+		if next == nil || !remove(next, key[1:]) {
+			return false
+		}
+		if c := *next; c.leaf == nil && c.n == 0 {
+			n.removeChild(key[0])
+		}
+	}
+
+	if n.leaf == nil && n.n == 1 {
+		edge, c := n.onlyChild()
+		merged := make([]byte, 0, len(n.prefix)+1+len(c.prefix))
+		merged = append(merged, n.prefix...)
+		merged = append(merged, edge)
+		c.prefix = append(merged, c.prefix...)
+		*ref = c
+	}
+	return true
+}
+
+// LongestPrefix returns the longest stored key that is a prefix of key,
+// e.g. the most specific route for a path.
+func (t *Tree[V]) LongestPrefix(key []byte) ([]byte, V, bool) {
+	var best *leaf[V]
+	n := t.root
+	for n != nil {
+		if !bytes.HasPrefix(key, n.prefix) {
+			break
+		}
+		key = key[len(n.prefix):]
+		if n.leaf != nil {
+			best = n.leaf
+		}
+		if len(key) == 0 {
+			break
+		}
+		n = n.child(key[0])
+		key = key[1:]
+	}
+
+	if best == nil {
+		var zero V
+		return nil, zero, false
+	}
+	return best.key, best.value, true
+}
+
+// Iterate calls fn for every key in byte order until fn returns false.
+// fn must not modify the key or the tree.
+func (t *Tree[V]) Iterate(fn func(key []byte, value V) bool) {
+	if t.root != nil {
+		walk(t.root, fn)
+	}
+}
+
+// IteratePrefix calls fn for every key starting with prefix, in byte order,
+// until fn returns false. fn must not modify the key or the tree.
+func (t *Tree[V]) IteratePrefix(prefix []byte, fn func(key []byte, value V) bool) {
+	n := t.root
+	for n != nil {
+		if len(prefix) <= len(n.prefix) {
+			if bytes.HasPrefix(n.prefix, prefix) {
+				walk(n, fn)
+			}
+			return
+		}
+		if !bytes.HasPrefix(prefix, n.prefix) {
+			return
+		}
+		prefix = prefix[len(n.prefix):]
+		n = n.child(prefix[0])
+		prefix = prefix[1:]
+	}
+}
+
+// walk visits the subtree of n in key order: a key ending at n sorts before
+// every longer key below it.
+func walk[V any](n *node[V], fn func(key []byte, value V) bool) bool {
+	if n.leaf != nil && !fn(n.leaf.key, n.leaf.value) {
+		return false
+	}
+	return n.each(func(_ byte, c *node[V]) bool {
+		return walk(c, fn)
+	})
+}
+
+func commonPrefix(a, b []byte) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
@@ -0,0 +1,255 @@
+package radix
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+// collect returns the keys yielded by iterate, as strings.
+func collect(iterate func(fn func(key []byte, value int) bool)) []string {
+	var keys []string
+	iterate(func(key []byte, _ int) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	return keys
+}
+
+// checkAgainst verifies tree holds exactly the entries of want, in order.
+func checkAgainst(t *testing.T, tree *Tree[int], want map[string]int) {
+	t.Helper()
+	if tree.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", tree.Len(), len(want))
+	}
+	for k, v := range want {
+		if got, ok := tree.Get([]byte(k)); !ok || got != v {
+			t.Fatalf("Get(%q) = %d, %v; want %d", k, got, ok, v)
+		}
+	}
+	keys := make([]string, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	if got := collect(tree.Iterate); !slices.Equal(got, keys) {
+		t.Fatalf("Iterate order = %q, want %q", got, keys)
+	}
+}
+
+// =============================================================================
+// Method: Insert() / Get() / Delete()
+// =============================================================================
+
+func TestInsertGet(t *testing.T) {
+	tree := New[int]()
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "romane", "romanus", "romulus", "rubens", "ruber"}
+	want := make(map[string]int)
+	for i, k := range keys {
+		if !tree.Insert([]byte(k), i) {
+			t.Fatalf("Insert(%q) reported an existing key", k)
+		}
+		want[k] = i
+	}
+	checkAgainst(t, tree, want)
+
+	if tree.Insert([]byte("ab"), 100) {
+		t.Error("Insert of an existing key reported a new key")
+	}
+	want["ab"] = 100
+	checkAgainst(t, tree, want)
+
+	for _, k := range []string{"abe", "r", "roman", "zzz"} {
+		if _, ok := tree.Get([]byte(k)); ok {
+			t.Errorf("Get(%q) found a missing key", k)
+		}
+	}
+}
+
+func TestInsertCopiesKey(t *testing.T) {
+	tree := New[int]()
+	key := []byte("key")
+	tree.Insert(key, 1)
+	key[0] = 'x'
+	if _, ok := tree.Get([]byte("key")); !ok {
+		t.Error("tree aliases the caller's key")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tree := New[int]()
+	want := map[string]int{"": 0, "a": 1, "ab": 2, "abc": 3, "abd": 4, "b": 5}
+	for k, v := range want {
+		tree.Insert([]byte(k), v)
+	}
+
+	for _, k := range []string{"ab", "", "abc", "zz", "abx"} {
+		_, present := want[k]
+		if got := tree.Delete([]byte(k)); got != present {
+			t.Errorf("Delete(%q) = %v, want %v", k, got, present)
+		}
+		delete(want, k)
+		checkAgainst(t, tree, want)
+	}
+
+	for k := range want {
+		tree.Delete([]byte(k))
+	}
+	if tree.Len() != 0 || tree.root != nil {
+		t.Errorf("tree not empty after deleting every key: len %d", tree.Len())
+	}
+}
+
+func TestNodeGrowAndShrink(t *testing.T) {
+	tree := New[int]()
+	want := make(map[string]int)
+	for b := range 256 {
+		k := string([]byte{'p', byte(b)})
+		tree.Insert([]byte(k), b)
+		want[k] = b
+	}
+	if n := tree.root; n.kind != node256 || n.n != 256 {
+		t.Fatalf("root kind %d with %d children, want node256 with 256", n.kind, n.n)
+	}
+	checkAgainst(t, tree, want)
+
+	for b := 255; b >= 2; b-- {
+		k := string([]byte{'p', byte(b)})
+		tree.Delete([]byte(k))
+		delete(want, k)
+	}
+	if n := tree.root; n.kind != node4 || n.n != 2 {
+		t.Fatalf("root kind %d with %d children, want node4 with 2", n.kind, n.n)
+	}
+	checkAgainst(t, tree, want)
+}
+
+func TestRandomAgainstMap(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	tree := New[int]()
+	want := make(map[string]int)
+
+	randKey := func() string {
+		// Small alphabet and short keys force shared prefixes and splits.
+		b := make([]byte, rng.IntN(6))
+		for i := range b {
+			b[i] = "abc\x00\xff"[rng.IntN(5)]
+		}
+		return string(b)
+	}
+
+	for i := range 20000 {
+		k := randKey()
+		if rng.IntN(3) == 0 {
+			_, present := want[k]
+			if got := tree.Delete([]byte(k)); got != present {
+				t.Fatalf("Delete(%q) = %v, want %v", k, got, present)
+			}
+			delete(want, k)
+		} else {
+			_, present := want[k]
+			if got := tree.Insert([]byte(k), i); got == present {
+				t.Fatalf("Insert(%q) = %v, want %v", k, got, !present)
+			}
+			want[k] = i
+		}
+		if i%1000 == 0 {
+			checkAgainst(t, tree, want)
+		}
+	}
+	checkAgainst(t, tree, want)
+}
+
+// =============================================================================
+// Method: LongestPrefix()
+// =============================================================================
+
+func TestLongestPrefix(t *testing.T) {
+	tree := New[int]()
+	for i, route := range []string{"/", "/api", "/api/v1/", "/api/v1/users", "/static/"} {
+		tree.Insert([]byte(route), i)
+	}
+
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"/api/v1/users/42", "/api/v1/users", true},
+		{"/api/v1/orders", "/api/v1/", true},
+		{"/api/v2", "/api", true},
+		{"/apix", "/api", true},
+		{"/static/app.js", "/static/", true},
+		{"/stat", "/", true},
+		{"", "", false},
+		{"api", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			key, _, ok := tree.LongestPrefix([]byte(tt.path))
+			if ok != tt.ok || string(key) != tt.want {
+				t.Errorf("LongestPrefix(%q) = %q, %v; want %q, %v", tt.path, key, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Method: IteratePrefix()
+// =============================================================================
+
+func TestIteratePrefix(t *testing.T) {
+	tree := New[int]()
+	keys := []string{"user:1", "user:10", "user:2", "users", "usr", "u", "order:1"}
+	for i, k := range keys {
+		tree.Insert([]byte(k), i)
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"user:", []string{"user:1", "user:10", "user:2"}},
+		{"user", []string{"user:1", "user:10", "user:2", "users"}},
+		{"us", []string{"user:1", "user:10", "user:2", "users", "usr"}},
+		{"u", []string{"u", "user:1", "user:10", "user:2", "users", "usr"}},
+		{"user:10", []string{"user:10"}},
+		{"x", nil},
+		{"user:100", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got := collect(func(fn func([]byte, int) bool) { tree.IteratePrefix([]byte(tt.prefix), fn) })
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("IteratePrefix(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		})
+	}
+
+	all := collect(func(fn func([]byte, int) bool) { tree.IteratePrefix(nil, fn) })
+	if len(all) != len(keys) {
+		t.Errorf("IteratePrefix(nil) = %d keys, want %d", len(all), len(keys))
+	}
+}
+
+func TestIterateStopsEarly(t *testing.T) {
+	tree := New[int]()
+	for i := range 100 {
+		tree.Insert([]byte(strings.Repeat("k", i+1)), i)
+	}
+
+	var seen [][]byte
+	tree.Iterate(func(key []byte, _ int) bool {
+		seen = append(seen, key)
+		return len(seen) < 5
+	})
+	if len(seen) != 5 || !bytes.Equal(seen[4], []byte("kkkkk")) {
+		t.Errorf("Iterate yielded %d keys before stopping, want 5", len(seen))
+	}
+}
@@ -0,0 +1,239 @@
+package shardedmap
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/utils"
+)
+
+// Defaults for NewCounterMap.
+const (
+	defaultCounterWindow = time.Minute
+	defaultCounterSlots  = 10
+)
+
+// CounterMap counts events per key over a sliding window, for per-key rate
+// limits and abuse detection without a stats pipeline. Each key keeps a
+// ring of slots covering the window; counts age out one slot at a time, and
+// keys with nothing left in the window are dropped. It is safe for
+// concurrent use.
+type CounterMap[K comparable] struct {
+	shards  []*counterShard[K]
+	mask    uint64
+	hasher  func(K) uint64
+	slots   int64
+	slotDur int64 // nanoseconds
+	now     func() time.Time
+}
+
+type counterShard[K comparable] struct {
+	sync.RWMutex
+	data    map[K]*ringCounter
+	sweepAt int64 // slot from which the next Incr sweeps idle keys
+
+	pad [64]byte // see lockedShard
+}
+
+// ringCounter is one key's counts: counts[s%len(counts)] holds slot s, for
+// the slots up to newest still inside the window.
+type ringCounter struct {
+	counts []int64
+	newest int64
+	total  int64
+}
+
+// CounterEntry is a key and its count in the window.
+type CounterEntry[K comparable] struct {
+	Key   K
+	Count int64
+}
+
+// NewCounterMap creates a CounterMap counting over window, split into
+// slots slots: counts leave the window window/slots at a time. More slots
+// age counts more smoothly at the cost of memory per key. window <= 0
+// means one minute and slots <= 0 means 10. shards and hashFn are as for
+// New.
+func NewCounterMap[K comparable](shards int, hashFn func(K) uint64, window time.Duration, slots int) *CounterMap[K] {
+	if shards <= 0 {
+		shards = 256
+	}
+	if window <= 0 {
+		window = defaultCounterWindow
+	}
+	if slots <= 0 {
+		slots = defaultCounterSlots
+	}
+	numShards := utils.CeilToPowerOfTwo(shards)
+	m := &CounterMap[K]{
+		shards:  make([]*counterShard[K], numShards),
+		mask:    uint64(numShards - 1),
+		hasher:  hashFn,
+		slots:   int64(slots),
+		slotDur: max(int64(window)/int64(slots), 1),
+		now:     time.Now,
+	}
+	for i := range m.shards {
+		m.shards[i] = &counterShard[K]{data: make(map[K]*ringCounter)}
+	}
+	return m
+}
+
+// SetClock replaces time.Now as the map's clock, to replay recorded traffic
+// or simulate time. Call it before the map is shared.
+func (m *CounterMap[K]) SetClock(now func() time.Time) {
+	m.now = now
+}
+
+// slot returns the current slot number.
+func (m *CounterMap[K]) slot() int64 {
+	return m.now().UnixNano() / m.slotDur
+}
+
+// window returns r's count in the window ending at slot, without aging r.
+func (m *CounterMap[K]) window(r *ringCounter, slot int64) int64 {
+	if slot-r.newest >= m.slots {
+		return 0
+	}
+	total := r.total
+	for s := r.newest + 1; s <= slot; s++ {
+		total -= r.counts[s%m.slots]
+	}
+	return total
+}
+
+// advance ages r to slot, zeroing the slots that left the window.
+func (m *CounterMap[K]) advance(r *ringCounter, slot int64) {
+	if slot <= r.newest {
+		return
+	}
+	if slot-r.newest >= m.slots {
+		clear(r.counts)
+		r.total = 0
+	} else {
+		for s := r.newest + 1; s <= slot; s++ {
+			r.total -= r.counts[s%m.slots]
+			r.counts[s%m.slots] = 0
+		}
+	}
+	r.newest = slot
+}
+
+// Incr adds delta to key's count and returns its count in the window.
+// Once per window it also drops the shard's keys whose window is empty.
+func (m *CounterMap[K]) Incr(key K, delta int64) int64 {
+	shard := m.shards[m.hasher(key)&m.mask]
+	slot := m.slot()
+
+	shard.Lock()
+	defer shard.Unlock()
+
+	if slot >= shard.sweepAt {
+		for k, r := range shard.data {
+			if m.window(r, slot) == 0 {
+				delete(shard.data, k)
+			}
+		}
+		shard.sweepAt = slot + m.slots
+	}
+
+	r, ok := shard.data[key]
+	if !ok {
+		r = &ringCounter{counts: make([]int64, m.slots), newest: slot}
+		shard.data[key] = r
+	}
+	m.advance(r, slot)
+	r.counts[slot%m.slots] += delta
+	r.total += delta
+	return r.total
+}
+
+// Get returns key's count in the window, 0 for a key not seen in it.
+func (m *CounterMap[K]) Get(key K) int64 {
+	shard := m.shards[m.hasher(key)&m.mask]
+	slot := m.slot()
+
+	shard.RLock()
+	defer shard.RUnlock()
+	if r, ok := shard.data[key]; ok {
+		return m.window(r, slot)
+	}
+	return 0
+}
+
+// Del forgets key's count.
+func (m *CounterMap[K]) Del(key K) {
+	shard := m.shards[m.hasher(key)&m.mask]
+	shard.Lock()
+	delete(shard.data, key)
+	shard.Unlock()
+}
+
+// Len returns the number of keys tracked, including keys whose window
+// emptied since their shard was last swept.
+func (m *CounterMap[K]) Len() int {
+	total := 0
+	for _, shard := range m.shards {
+		shard.RLock()
+		total += len(shard.data)
+		shard.RUnlock()
+	}
+	return total
+}
+
+// Clear forgets every count.
+func (m *CounterMap[K]) Clear() {
+	for _, shard := range m.shards {
+		shard.Lock()
+		shard.data = make(map[K]*ringCounter)
+		shard.Unlock()
+	}
+}
+
+// TopN returns up to n keys with the highest counts in the window, highest
+// first; keys with no count in the window are left out. It reads one shard
+// at a time, so concurrent increments may or may not be seen.
+func (m *CounterMap[K]) TopN(n int) []CounterEntry[K] {
+	if n <= 0 {
+		return nil
+	}
+	slot := m.slot()
+	top := make(counterHeap[K], 0, n)
+	for _, shard := range m.shards {
+		shard.RLock()
+		for k, r := range shard.data {
+			count := m.window(r, slot)
+			switch {
+			case count <= 0:
+			case len(top) < n:
+				heap.Push(&top, CounterEntry[K]{Key: k, Count: count})
+			case count > top[0].Count:
+				top[0] = CounterEntry[K]{Key: k, Count: count}
+				heap.Fix(&top, 0)
+			}
+		}
+		shard.RUnlock()
+	}
+
+	out := make([]CounterEntry[K], len(top))
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(&top).(CounterEntry[K])
+	}
+	return out
+}
+
+// counterHeap is a min-heap of entries by count, the smallest at the root
+// for TopN to replace.
+type counterHeap[K comparable] []CounterEntry[K]
+
+func (h counterHeap[K]) Len() int           { return len(h) }
+func (h counterHeap[K]) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h counterHeap[K]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *counterHeap[K]) Push(x any)        { *h = append(*h, x.(CounterEntry[K])) }
+func (h *counterHeap[K]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,75 @@
+package shardedmap
+
+import (
+	"unsafe"
+)
+
+// Approximate layout of a Go map: slots come in groups of 8 behind an
+// 8-byte control word, tables grow to stay at most 7/8 full, and every map
+// has a fixed header.
+const (
+	mapGroupSlots  = 8
+	mapGroupCtrl   = 8
+	mapHeaderBytes = 48
+)
+
+// SetSizeFunc sets the function ApproxMemoryUsage uses to size the memory a
+// value references beyond its own slot, e.g. the backing array of a []byte.
+// nil counts slots only. Call it before the map is shared.
+func (m *Map[K, V]) SetSizeFunc(fn func(V) int64) {
+	m.sizeFn = fn
+}
+
+// ApproxMemoryUsage estimates the bytes held by the map: shard structs, map
+// tables sized for the current entry counts, and whatever SetSizeFunc
+// reports per value. Memory referenced by keys (string contents) is not
+// counted, and tables that have shrunk through Del are sized as if freshly
+// built, so the figure is a lower bound. With a size function it visits
+// every value, one shard lock at a time.
+func (m *Map[K, V]) ApproxMemoryUsage() int64 {
+	var (
+		k    K
+		v    V
+		slot = int64(unsafe.Sizeof(k) + unsafe.Sizeof(v))
+	)
+	total := int64(len(m.shards)) * int64(unsafe.Sizeof(lockedShard[K, V]{})+unsafe.Sizeof(uintptr(0)))
+
+	for _, shard := range m.shards {
+		var (
+			n     int
+			extra int64
+		)
+		if m.readMostly {
+			data := *shard.snap.Load()
+			n, extra = len(data), m.valueBytes(data)
+		} else {
+			shard.RLock()
+			n, extra = len(shard.data), m.valueBytes(shard.data)
+			shard.RUnlock()
+		}
+		total += mapBytes(n, slot) + extra
+	}
+	return total
+}
+
+// valueBytes sums sizeFn over data, 0 without one.
+func (m *Map[K, V]) valueBytes(data map[K]V) int64 {
+	if m.sizeFn == nil {
+		return 0
+	}
+	var sum int64
+	for _, v := range data {
+		sum += m.sizeFn(v)
+	}
+	return sum
+}
+
+// mapBytes estimates a Go map holding n entries of slot bytes each.
+func mapBytes(n int, slot int64) int64 {
+	if n == 0 {
+		return mapHeaderBytes
+	}
+	slots := (int64(n)*8 + 6) / 7 // keep load at most 7/8
+	groups := (slots + mapGroupSlots - 1) / mapGroupSlots
+	return mapHeaderBytes + groups*(mapGroupCtrl+mapGroupSlots*slot)
+}
@@ -0,0 +1,149 @@
+package shardedmap
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// entry is a key-value pair copied out of a shard.
+type entry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+// forEachShard calls fn(i) for every shard index in [0, n) from up to
+// workers goroutines, GOMAXPROCS if workers <= 0, and waits for them.
+func forEachShard(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, n)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := int(next.Add(1) - 1); i < n; i = int(next.Add(1) - 1) {
+				fn(i)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// entries copies the contents of shard i.
+func (m *Map[K, V]) entries(i int) []entry[K, V] {
+	shard := m.shards[i]
+	if m.readMostly {
+		snap := *shard.snap.Load()
+		out := make([]entry[K, V], 0, len(snap))
+		for k, v := range snap {
+			out = append(out, entry[K, V]{k, v})
+		}
+		return out
+	}
+
+	shard.RLock()
+	out := make([]entry[K, V], 0, len(shard.data))
+	for k, v := range shard.data {
+		out = append(out, entry[K, V]{k, v})
+	}
+	shard.RUnlock()
+	return out
+}
+
+// MergeFrom copies every entry of other into m. For a key present in both,
+// the stored value is conflict(key, old, new) with old from m and new from
+// other; a nil conflict lets other win.
+//
+// Shards of other are read in parallel, one at a time each, and the entries
+// bound for one shard of m are applied under a single lock (one clone in
+// read-mostly mode). conflict runs under that lock and must not call into m.
+// The merge is not atomic: readers may observe it half applied.
+func (m *Map[K, V]) MergeFrom(other *Map[K, V], conflict func(key K, old, new V) V) {
+	forEachShard(len(other.shards), 0, func(i int) {
+		src := other.entries(i)
+		if len(src) == 0 {
+			return
+		}
+
+		buckets := make(map[uint64][]entry[K, V])
+		for _, e := range src {
+			idx := m.hasher(e.key) & m.mask
+			buckets[idx] = append(buckets[idx], e)
+		}
+		for idx, bucket := range buckets {
+			m.shards[idx].merge(m.readMostly, bucket, conflict)
+		}
+	})
+}
+
+// merge applies bucket to the shard.
+func (s *lockedShard[K, V]) merge(readMostly bool, bucket []entry[K, V], conflict func(K, V, V) V) {
+	apply := func(data map[K]V) {
+		for _, e := range bucket {
+			if old, ok := data[e.key]; ok && conflict != nil {
+				data[e.key] = conflict(e.key, old, e.val)
+				continue
+			}
+			data[e.key] = e.val
+		}
+	}
+
+	s.Lock()
+	if readMostly {
+		s.update(apply)
+	} else {
+		apply(s.data)
+	}
+	s.Unlock()
+}
+
+// DiffFunc compares m with other, using equal to compare values: added holds
+// the keys only in other, removed the keys only in m, and changed the keys in
+// both whose values differ. Keys come back in no particular order.
+//
+// Shards are scanned in parallel. Neither map is frozen, so concurrent
+// writes may or may not be reflected.
+func (m *Map[K, V]) DiffFunc(other *Map[K, V], equal func(a, b V) bool) (added, removed, changed []K) {
+	var mu sync.Mutex
+
+	forEachShard(len(m.shards), 0, func(i int) {
+		var rem, chg []K
+		for _, e := range m.entries(i) {
+			v, ok := other.Get(e.key)
+			switch {
+			case !ok:
+				rem = append(rem, e.key)
+			case !equal(e.val, v):
+				chg = append(chg, e.key)
+			}
+		}
+		if len(rem)+len(chg) > 0 {
+			mu.Lock()
+			removed = append(removed, rem...)
+			changed = append(changed, chg...)
+			mu.Unlock()
+		}
+	})
+
+	forEachShard(len(other.shards), 0, func(i int) {
+		var add []K
+		for _, e := range other.entries(i) {
+			if _, ok := m.Get(e.key); !ok {
+				add = append(add, e.key)
+			}
+		}
+		if len(add) > 0 {
+			mu.Lock()
+			added = append(added, add...)
+			mu.Unlock()
+		}
+	})
+	return added, removed, changed
+}
+
+// Diff is DiffFunc for comparable values, compared with ==.
+func Diff[K comparable, V comparable](m, other *Map[K, V]) (added, removed, changed []K) {
+	return m.DiffFunc(other, func(a, b V) bool { return a == b })
+}
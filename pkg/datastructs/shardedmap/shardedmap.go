@@ -1,7 +1,10 @@
 package shardedmap
 
 import (
+	"context"
+	"maps"
 	"sync"
+	"sync/atomic"
 
 	"github.com/huynhanx03/go-common/pkg/utils"
 )
@@ -9,18 +12,25 @@ import (
 // Map is a thread-safe map that uses sharding to minimize lock contention.
 // It supports any comparable key type K and any value type V.
 type Map[K comparable, V any] struct {
-	shards []*lockedShard[K, V]
-	mask   uint64
-	hasher func(K) uint64
+	shards     []*lockedShard[K, V]
+	mask       uint64
+	hasher     func(K) uint64
+	readMostly bool
+	sizeFn     func(V) int64 // see SetSizeFunc
 }
 
 type lockedShard[K comparable, V any] struct {
 	sync.RWMutex
 	data map[K]V
 
+	// snap is the copy-on-write snapshot used instead of data in read-mostly
+	// mode. Readers load it without locking; writers hold the lock, clone it
+	// and swap it. A published snapshot is never modified.
+	snap atomic.Pointer[map[K]V]
+
 	// Padding prevents false sharing by ensuring each shard struct is large enough
 	// to occupy its own cache line (typically 64 bytes).
-	// RWMutex (24) + Map (8) = 32 bytes.
+	// RWMutex (24) + Map (8) + snapshot (8) = 40 bytes.
 	// We add 40 bytes padding to reach > 64 bytes, ensuring independent allocation blocks.
 	// Using [64]byte is simpler and safer to guarantee separation.
 	pad [64]byte
@@ -48,11 +58,39 @@ func New[K comparable, V any](shards int, hashFn func(K) uint64) *Map[K, V] {
 	return m
 }
 
+// NewReadMostly creates a Sharded Map tuned for rarely updated, heavily read
+// data such as config or route tables. Reads are lock-free loads of a
+// per-shard snapshot; every write clones its shard, so writes cost O(shard
+// size) and should be infrequent.
+func NewReadMostly[K comparable, V any](shards int, hashFn func(K) uint64) *Map[K, V] {
+	m := New[K, V](shards, hashFn)
+	m.readMostly = true
+	for _, shard := range m.shards {
+		empty := shard.data
+		shard.data = nil
+		shard.snap.Store(&empty)
+	}
+	return m
+}
+
+// update applies fn to a private copy of the shard's snapshot and publishes
+// it. Caller holds the shard lock.
+func (s *lockedShard[K, V]) update(fn func(map[K]V)) {
+	next := maps.Clone(*s.snap.Load())
+	fn(next)
+	s.snap.Store(&next)
+}
+
 // Get retrieves a value from the map.
 func (m *Map[K, V]) Get(key K) (V, bool) {
 	hash := m.hasher(key)
 	shard := m.shards[hash&m.mask]
 
+	if m.readMostly {
+		val, ok := (*shard.snap.Load())[key]
+		return val, ok
+	}
+
 	shard.RLock()
 	val, ok := shard.data[key]
 	shard.RUnlock()
@@ -65,7 +103,11 @@ func (m *Map[K, V]) Set(key K, value V) {
 	shard := m.shards[hash&m.mask]
 
 	shard.Lock()
-	shard.data[key] = value
+	if m.readMostly {
+		shard.update(func(data map[K]V) { data[key] = value })
+	} else {
+		shard.data[key] = value
+	}
 	shard.Unlock()
 }
 
@@ -75,15 +117,58 @@ func (m *Map[K, V]) Del(key K) {
 	shard := m.shards[hash&m.mask]
 
 	shard.Lock()
-	delete(shard.data, key)
+	if m.readMostly {
+		if _, ok := (*shard.snap.Load())[key]; ok {
+			shard.update(func(data map[K]V) { delete(data, key) })
+		}
+	} else {
+		delete(shard.data, key)
+	}
 	shard.Unlock()
 }
 
+// Compute atomically replaces the value of key with fn(old, loaded), where
+// loaded reports whether key was present. If fn returns keep == false the key
+// is removed. fn runs under the shard lock and must not call back into m.
+func (m *Map[K, V]) Compute(key K, fn func(old V, loaded bool) (value V, keep bool)) {
+	hash := m.hasher(key)
+	shard := m.shards[hash&m.mask]
+
+	shard.Lock()
+	defer shard.Unlock()
+
+	if m.readMostly {
+		old, loaded := (*shard.snap.Load())[key]
+		value, keep := fn(old, loaded)
+		if keep || loaded {
+			shard.update(func(data map[K]V) {
+				if keep {
+					data[key] = value
+				} else {
+					delete(data, key)
+				}
+			})
+		}
+		return
+	}
+
+	old, loaded := shard.data[key]
+	if value, keep := fn(old, loaded); keep {
+		shard.data[key] = value
+	} else if loaded {
+		delete(shard.data, key)
+	}
+}
+
 // Len returns the total number of items in the map.
 // Note: This iterates over all shards and locks them individually, so it's not atomic across the whole map.
 func (m *Map[K, V]) Len() int {
 	total := 0
 	for _, shard := range m.shards {
+		if m.readMostly {
+			total += len(*shard.snap.Load())
+			continue
+		}
 		shard.RLock()
 		total += len(shard.data)
 		shard.RUnlock()
@@ -95,19 +180,60 @@ func (m *Map[K, V]) Len() int {
 func (m *Map[K, V]) Clear() {
 	for _, shard := range m.shards {
 		shard.Lock()
-		shard.data = make(map[K]V)
+		if m.readMostly {
+			empty := make(map[K]V)
+			shard.snap.Store(&empty)
+		} else {
+			shard.data = make(map[K]V)
+		}
 		shard.Unlock()
 	}
 }
 
 // Do iterates over all items in the map and executes fn.
-// It locks one shard at a time.
+// It locks one shard at a time; in read-mostly mode it walks each shard's
+// snapshot without locking.
 func (m *Map[K, V]) Do(fn func(K, V)) {
-	for _, shard := range m.shards {
-		shard.RLock()
-		for k, v := range shard.data {
+	for i := range m.shards {
+		m.doShard(i, fn)
+	}
+}
+
+// DoParallel is Do with up to workers shards walked concurrently,
+// GOMAXPROCS if workers <= 0, for full-map passes such as exports and
+// audits over large maps. Calls for one shard are sequential but fn runs
+// on several goroutines at once and must be safe for that.
+func (m *Map[K, V]) DoParallel(workers int, fn func(K, V)) {
+	forEachShard(len(m.shards), workers, func(i int) {
+		m.doShard(i, fn)
+	})
+}
+
+// DoParallelContext is DoParallel that stops starting shards once ctx is
+// done and returns ctx.Err(); shards already being walked are finished.
+// It returns nil if every shard was walked.
+func (m *Map[K, V]) DoParallelContext(ctx context.Context, workers int, fn func(K, V)) error {
+	forEachShard(len(m.shards), workers, func(i int) {
+		if ctx.Err() == nil {
+			m.doShard(i, fn)
+		}
+	})
+	return ctx.Err()
+}
+
+// doShard calls fn for every item of shard i, holding its read lock in
+// normal mode.
+func (m *Map[K, V]) doShard(i int, fn func(K, V)) {
+	shard := m.shards[i]
+	if m.readMostly {
+		for k, v := range *shard.snap.Load() {
 			fn(k, v)
 		}
-		shard.RUnlock()
+		return
 	}
+	shard.RLock()
+	for k, v := range shard.data {
+		fn(k, v)
+	}
+	shard.RUnlock()
 }
@@ -1,7 +1,14 @@
 package shardedmap_test
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/huynhanx03/go-common/pkg/datastructs/shardedmap"
 )
@@ -486,6 +493,119 @@ func TestDo(t *testing.T) {
 	})
 }
 
+func TestDoParallel(t *testing.T) {
+	for _, mk := range []struct {
+		name string
+		new  func() *shardedmap.Map[int, int]
+	}{
+		{"normal", func() *shardedmap.Map[int, int] { return shardedmap.New[int, int](16, intHash) }},
+		{"read_mostly", func() *shardedmap.Map[int, int] { return shardedmap.NewReadMostly[int, int](16, intHash) }},
+	} {
+		t.Run(mk.name, func(t *testing.T) {
+			m := mk.new()
+			for i := range 1000 {
+				m.Set(i, i*2)
+			}
+
+			for _, workers := range []int{0, 1, 4, 64} {
+				var count, sum atomic.Int64
+				m.DoParallel(workers, func(k, v int) {
+					if v != k*2 {
+						t.Errorf("key %d: got value %d", k, v)
+					}
+					count.Add(1)
+					sum.Add(int64(k))
+				})
+				if count.Load() != 1000 || sum.Load() != 999*1000/2 {
+					t.Errorf("workers=%d: visited %d items, key sum %d", workers, count.Load(), sum.Load())
+				}
+			}
+		})
+	}
+}
+
+func TestDoParallelContext(t *testing.T) {
+	m := shardedmap.New[int, int](16, intHash)
+	for i := range 1000 {
+		m.Set(i, i)
+	}
+
+	var count atomic.Int64
+	if err := m.DoParallelContext(context.Background(), 2, func(int, int) { count.Add(1) }); err != nil {
+		t.Fatalf("DoParallelContext = %v", err)
+	}
+	if count.Load() != 1000 {
+		t.Errorf("visited %d items, want 1000", count.Load())
+	}
+
+	// Cancelled from inside fn: the walk stops after the shards in flight.
+	ctx, cancel := context.WithCancel(context.Background())
+	count.Store(0)
+	err := m.DoParallelContext(ctx, 1, func(int, int) {
+		count.Add(1)
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("DoParallelContext after cancel = %v, want context.Canceled", err)
+	}
+	if n := count.Load(); n == 0 || n >= 1000 {
+		t.Errorf("visited %d items after cancel, want one shard's worth", n)
+	}
+}
+
+// =============================================================================
+// Compute Tests
+// =============================================================================
+
+func TestCompute(t *testing.T) {
+	for _, m := range []*shardedmap.Map[string, int]{
+		shardedmap.New[string, int](4, simpleHash),
+		shardedmap.NewReadMostly[string, int](4, simpleHash),
+	} {
+		incr := func(old int, _ bool) (int, bool) { return old + 1, true }
+		m.Compute("a", incr)
+		m.Compute("a", incr)
+		if v, ok := m.Get("a"); !ok || v != 2 {
+			t.Errorf("Get(a) = %d, %v; want 2, true", v, ok)
+		}
+
+		m.Compute("a", func(old int, loaded bool) (int, bool) {
+			if !loaded || old != 2 {
+				t.Errorf("Compute saw %d, %v; want 2, true", old, loaded)
+			}
+			return 0, false
+		})
+		if _, ok := m.Get("a"); ok {
+			t.Error("Get(a) after Compute removed it reported ok")
+		}
+
+		m.Compute("missing", func(int, bool) (int, bool) { return 0, false })
+		if m.Len() != 0 {
+			t.Errorf("Len() = %d, want 0", m.Len())
+		}
+	}
+}
+
+func TestCompute_Concurrent(t *testing.T) {
+	m := shardedmap.New[int, int](4, intHash)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				m.Compute(i%10, func(old int, _ bool) (int, bool) { return old + 1, true })
+			}
+		})
+	}
+	wg.Wait()
+
+	for k := range 10 {
+		if v, _ := m.Get(k); v != 800 {
+			t.Errorf("Get(%d) = %d, want 800", k, v)
+		}
+	}
+}
+
 // =============================================================================
 // Panic Tests
 // =============================================================================
@@ -583,3 +703,392 @@ func TestWorkflow_LargeDataSet(t *testing.T) {
 		t.Errorf("Len() after Clear = %d, want 0", m.Len())
 	}
 }
+
+// =============================================================================
+// Read-Mostly Mode Tests
+// =============================================================================
+
+func TestReadMostly_Operations(t *testing.T) {
+	m := shardedmap.NewReadMostly[string, int](4, simpleHash)
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("a", 10)
+	if v, ok := m.Get("a"); !ok || v != 10 {
+		t.Errorf("Get(a) = %d, %v; want 10, true", v, ok)
+	}
+	if m.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", m.Len())
+	}
+
+	m.Del("b")
+	m.Del("missing")
+	if _, ok := m.Get("b"); ok {
+		t.Error("Get(b) after Del reported ok")
+	}
+
+	sum := 0
+	m.Do(func(_ string, v int) { sum += v })
+	if sum != 10 {
+		t.Errorf("Do sum = %d, want 10", sum)
+	}
+
+	m.Clear()
+	if m.Len() != 0 {
+		t.Errorf("Len() after Clear = %d, want 0", m.Len())
+	}
+}
+
+func TestReadMostly_ConcurrentReadersAndWriter(t *testing.T) {
+	m := shardedmap.NewReadMostly[int, int](8, intHash)
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				for i := 1; i < 100; i++ {
+					if v, ok := m.Get(i); !ok || v%i != 0 {
+						t.Errorf("Get(%d) = %d, want a multiple of %d", i, v, i)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for round := 1; round <= 50; round++ {
+		for i := 0; i < 100; i++ {
+			m.Set(i, i*round)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if v, _ := m.Get(7); v != 7*50 {
+		t.Errorf("Get(7) = %d, want %d", v, 7*50)
+	}
+}
+
+// =============================================================================
+// Merge and Diff Tests
+// =============================================================================
+
+func TestMergeFrom(t *testing.T) {
+	for _, readMostly := range []bool{false, true} {
+		newMap := shardedmap.New[int, int]
+		if readMostly {
+			newMap = shardedmap.NewReadMostly[int, int]
+		}
+		dst := newMap(8, intHash)
+		src := newMap(4, intHash) // different shard count
+		for i := 0; i < 100; i++ {
+			dst.Set(i, i)
+		}
+		for i := 50; i < 150; i++ {
+			src.Set(i, 1000)
+		}
+
+		dst.MergeFrom(src, func(k, old, new int) int { return old + new })
+
+		if dst.Len() != 150 {
+			t.Fatalf("readMostly=%v: Len() = %d, want 150", readMostly, dst.Len())
+		}
+		for _, tc := range []struct{ k, want int }{{10, 10}, {60, 1060}, {120, 1000}} {
+			if v, _ := dst.Get(tc.k); v != tc.want {
+				t.Errorf("readMostly=%v: Get(%d) = %d, want %d", readMostly, tc.k, v, tc.want)
+			}
+		}
+	}
+}
+
+func TestMergeFrom_NilConflictOtherWins(t *testing.T) {
+	dst := shardedmap.New[string, int](4, simpleHash)
+	src := shardedmap.New[string, int](4, simpleHash)
+	dst.Set("a", 1)
+	src.Set("a", 2)
+
+	dst.MergeFrom(src, nil)
+	if v, _ := dst.Get("a"); v != 2 {
+		t.Errorf("Get(a) = %d, want 2", v)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldMap := shardedmap.New[int, string](8, intHash)
+	newMap := shardedmap.NewReadMostly[int, string](16, intHash)
+	for i := 0; i < 10; i++ {
+		oldMap.Set(i, "v1")
+	}
+	for i := 5; i < 15; i++ {
+		newMap.Set(i, "v1")
+	}
+	newMap.Set(7, "v2")
+	newMap.Set(8, "v2")
+
+	added, removed, changed := shardedmap.Diff(oldMap, newMap)
+	sort.Ints(added)
+	sort.Ints(removed)
+	sort.Ints(changed)
+
+	if want := []int{10, 11, 12, 13, 14}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %v, want %v", added, want)
+	}
+	if want := []int{0, 1, 2, 3, 4}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	if want := []int{7, 8}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+}
+
+func TestDiffFunc_Identical(t *testing.T) {
+	a := shardedmap.New[string, []int](4, simpleHash)
+	b := shardedmap.New[string, []int](4, simpleHash)
+	a.Set("x", []int{1, 2})
+	b.Set("x", []int{1, 2})
+
+	added, removed, changed := a.DiffFunc(b, func(x, y []int) bool { return reflect.DeepEqual(x, y) })
+	if len(added)+len(removed)+len(changed) != 0 {
+		t.Errorf("Diff of equal maps = %v, %v, %v", added, removed, changed)
+	}
+}
+
+// =============================================================================
+// Memory Estimation Tests
+// =============================================================================
+
+func TestApproxMemoryUsage(t *testing.T) {
+	m := shardedmap.New[int, []byte](16, intHash)
+	empty := m.ApproxMemoryUsage()
+	if empty <= 0 {
+		t.Fatalf("empty map estimate = %d, want > 0", empty)
+	}
+
+	for i := range 10_000 {
+		m.Set(i, make([]byte, 100))
+	}
+	slots := m.ApproxMemoryUsage()
+	// Each entry holds at least an int key and a slice header.
+	if floor := empty + 10_000*(8+24); slots < floor {
+		t.Errorf("estimate = %d, want >= %d", slots, floor)
+	}
+
+	m.SetSizeFunc(func(v []byte) int64 { return int64(cap(v)) })
+	if got, want := m.ApproxMemoryUsage(), slots+10_000*100; got != want {
+		t.Errorf("estimate with sizes = %d, want %d", got, want)
+	}
+}
+
+func TestApproxMemoryUsage_ReadMostly(t *testing.T) {
+	normal := shardedmap.New[int, int](8, intHash)
+	readMostly := shardedmap.NewReadMostly[int, int](8, intHash)
+	for i := range 1000 {
+		normal.Set(i, i)
+		readMostly.Set(i, i)
+	}
+	if a, b := normal.ApproxMemoryUsage(), readMostly.ApproxMemoryUsage(); a != b {
+		t.Errorf("estimates differ: normal %d, read-mostly %d", a, b)
+	}
+}
+
+// =============================================================================
+// Counter Map Tests
+// =============================================================================
+
+// fakeClock is a settable clock for CounterMap.SetClock.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time      { return c.t }
+func (c *fakeClock) add(d time.Duration) { c.t = c.t.Add(d) }
+func newFakeClock() *fakeClock           { return &fakeClock{t: time.Unix(1000, 0)} }
+
+func TestCounterMap_Window(t *testing.T) {
+	clock := newFakeClock()
+	m := shardedmap.NewCounterMap[string](4, simpleHash, 10*time.Second, 10)
+	m.SetClock(clock.now)
+
+	if got := m.Incr("ip", 3); got != 3 {
+		t.Fatalf("Incr = %d, want 3", got)
+	}
+	clock.add(5 * time.Second)
+	if got := m.Incr("ip", 2); got != 5 {
+		t.Fatalf("Incr = %d, want 5", got)
+	}
+
+	// The first increment leaves the window, the second is still in it.
+	clock.add(5 * time.Second)
+	if got := m.Get("ip"); got != 2 {
+		t.Errorf("Get after 10s = %d, want 2", got)
+	}
+	clock.add(5 * time.Second)
+	if got := m.Get("ip"); got != 0 {
+		t.Errorf("Get after 15s = %d, want 0", got)
+	}
+	if got := m.Get("unknown"); got != 0 {
+		t.Errorf("Get of unknown key = %d, want 0", got)
+	}
+
+	m.Incr("ip", 1)
+	m.Del("ip")
+	if got := m.Get("ip"); got != 0 {
+		t.Errorf("Get after Del = %d, want 0", got)
+	}
+}
+
+func TestCounterMap_ExpiresIdleKeys(t *testing.T) {
+	clock := newFakeClock()
+	m := shardedmap.NewCounterMap[int](2, intHash, time.Second, 4)
+	m.SetClock(clock.now)
+
+	for i := range 100 {
+		m.Incr(i, 1)
+	}
+	if got := m.Len(); got != 100 {
+		t.Fatalf("Len = %d, want 100", got)
+	}
+
+	clock.add(2 * time.Second)
+	m.Incr(1000, 1) // sweeps both shards
+	m.Incr(1001, 1)
+	if got := m.Len(); got != 2 {
+		t.Errorf("Len after the window = %d, want 2", got)
+	}
+
+	m.Clear()
+	if got := m.Len(); got != 0 {
+		t.Errorf("Len after Clear = %d, want 0", got)
+	}
+}
+
+func TestCounterMap_TopN(t *testing.T) {
+	clock := newFakeClock()
+	m := shardedmap.NewCounterMap[int](8, intHash, time.Minute, 6)
+	m.SetClock(clock.now)
+
+	for i := 1; i <= 50; i++ {
+		m.Incr(i, int64(i))
+	}
+	top := m.TopN(3)
+	want := []shardedmap.CounterEntry[int]{{Key: 50, Count: 50}, {Key: 49, Count: 49}, {Key: 48, Count: 48}}
+	if !reflect.DeepEqual(top, want) {
+		t.Errorf("TopN(3) = %v, want %v", top, want)
+	}
+
+	// Old heavy hitters age out.
+	clock.add(time.Minute)
+	m.Incr(7, 1)
+	if top := m.TopN(3); len(top) != 1 || top[0] != (shardedmap.CounterEntry[int]{Key: 7, Count: 1}) {
+		t.Errorf("TopN after the window = %v, want [{7 1}]", top)
+	}
+	if top := m.TopN(0); top != nil {
+		t.Errorf("TopN(0) = %v, want nil", top)
+	}
+}
+
+func TestCounterMap_Concurrent(t *testing.T) {
+	m := shardedmap.NewCounterMap[int](16, intHash, time.Hour, 4)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				m.Incr(i%10, 1)
+			}
+		})
+	}
+	wg.Wait()
+	for k := range 10 {
+		if got := m.Get(k); got != 800 {
+			t.Errorf("Get(%d) = %d, want 800", k, got)
+		}
+	}
+}
+
+// =============================================================================
+// VersionedMap Tests
+// =============================================================================
+
+func TestVersionedMap_SetIfVersion(t *testing.T) {
+	m := shardedmap.NewVersioned[string, int](4, simpleHash)
+
+	// Version 0 creates only if absent.
+	ok, v1 := m.SetIfVersion("k", 1, 0)
+	if !ok || v1 == 0 {
+		t.Fatalf("create = %v, %d", ok, v1)
+	}
+	if ok, cur := m.SetIfVersion("k", 9, 0); ok || cur != v1 {
+		t.Fatalf("second create = %v, %d; want false, %d", ok, cur, v1)
+	}
+
+	val, ver, found := m.GetWithVersion("k")
+	if !found || val != 1 || ver != v1 {
+		t.Fatalf("GetWithVersion = %d, %d, %v", val, ver, found)
+	}
+
+	// A concurrent writer bumps the version: the stale write fails.
+	v2 := m.Set("k", 2)
+	if v2 <= v1 {
+		t.Fatalf("Set version %d not above %d", v2, v1)
+	}
+	if ok, cur := m.SetIfVersion("k", 10, v1); ok || cur != v2 {
+		t.Fatalf("stale SetIfVersion = %v, %d; want false, %d", ok, cur, v2)
+	}
+	if ok, v3 := m.SetIfVersion("k", 3, v2); !ok || v3 <= v2 {
+		t.Fatalf("SetIfVersion = %v, %d", ok, v3)
+	}
+	if v, _ := m.Get("k"); v != 3 {
+		t.Errorf("Get = %d, want 3", v)
+	}
+
+	// A key deleted and set again does not reuse a version.
+	_, cur, _ := m.GetWithVersion("k")
+	if m.DelIfVersion("k", v1) {
+		t.Fatal("DelIfVersion with a stale version deleted")
+	}
+	if !m.DelIfVersion("k", cur) || m.Len() != 0 {
+		t.Fatal("DelIfVersion with the current version did not delete")
+	}
+	if ok, _ := m.SetIfVersion("k", 4, cur); ok {
+		t.Error("SetIfVersion matched a deleted entry's version")
+	}
+	if _, ver, found := m.GetWithVersion("k"); found || ver != 0 {
+		t.Errorf("GetWithVersion after delete = %d, %v", ver, found)
+	}
+}
+
+func TestVersionedMap_ConcurrentIncrements(t *testing.T) {
+	m := shardedmap.NewVersioned[string, int](4, simpleHash)
+	m.Set("n", 0)
+
+	var wg sync.WaitGroup
+	var conflicts atomic.Int64
+	for range 8 {
+		wg.Go(func() {
+			for range 500 {
+				for {
+					v, ver, _ := m.GetWithVersion("n")
+					if ok, _ := m.SetIfVersion("n", v+1, ver); ok {
+						break
+					}
+					conflicts.Add(1)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	// Read-modify-write through SetIfVersion loses no increment.
+	if v, _ := m.Get("n"); v != 4000 {
+		t.Errorf("counter = %d, want 4000 (%d conflicts)", v, conflicts.Load())
+	}
+}
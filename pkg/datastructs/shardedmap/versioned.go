@@ -0,0 +1,137 @@
+package shardedmap
+
+import (
+	"sync"
+
+	"github.com/huynhanx03/go-common/pkg/utils"
+)
+
+// VersionedMap is a sharded map whose entries carry a version, for
+// optimistic concurrency: read a value and its version with GetWithVersion,
+// work on it without holding any lock (e.g. across an RPC), then write the
+// result back with SetIfVersion, which fails instead of overwriting a
+// change made in between. It is safe for concurrent use.
+//
+// Every write gives the entry a new version taken from a counter per
+// shard, so versions only grow, and a key that is deleted and set again
+// never gets an old version back. Version 0 stands for an absent key.
+type VersionedMap[K comparable, V any] struct {
+	shards []*versionedShard[K, V]
+	mask   uint64
+	hasher func(K) uint64
+}
+
+type versionedShard[K comparable, V any] struct {
+	sync.RWMutex
+	data map[K]versioned[V]
+	seq  uint64 // last version handed out
+
+	pad [64]byte // see lockedShard
+}
+
+// versioned is a value and the version of its last write.
+type versioned[V any] struct {
+	value   V
+	version uint64
+}
+
+// NewVersioned creates a VersionedMap. shards and hashFn are as for New.
+func NewVersioned[K comparable, V any](shards int, hashFn func(K) uint64) *VersionedMap[K, V] {
+	if shards <= 0 {
+		shards = 256
+	}
+	numShards := utils.CeilToPowerOfTwo(shards)
+	m := &VersionedMap[K, V]{
+		shards: make([]*versionedShard[K, V], numShards),
+		mask:   uint64(numShards - 1),
+		hasher: hashFn,
+	}
+	for i := range m.shards {
+		m.shards[i] = &versionedShard[K, V]{data: make(map[K]versioned[V])}
+	}
+	return m
+}
+
+func (m *VersionedMap[K, V]) shard(key K) *versionedShard[K, V] {
+	return m.shards[m.hasher(key)&m.mask]
+}
+
+// Get retrieves a value from the map.
+func (m *VersionedMap[K, V]) Get(key K) (V, bool) {
+	v, _, ok := m.GetWithVersion(key)
+	return v, ok
+}
+
+// GetWithVersion retrieves a value and its version, 0 if key is absent.
+func (m *VersionedMap[K, V]) GetWithVersion(key K) (V, uint64, bool) {
+	shard := m.shard(key)
+	shard.RLock()
+	e, ok := shard.data[key]
+	shard.RUnlock()
+	return e.value, e.version, ok
+}
+
+// Set adds or updates a value unconditionally and returns its new version.
+func (m *VersionedMap[K, V]) Set(key K, value V) uint64 {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	return shard.set(key, value)
+}
+
+// set stores value under a new version. Caller holds the shard lock.
+func (s *versionedShard[K, V]) set(key K, value V) uint64 {
+	s.seq++
+	s.data[key] = versioned[V]{value: value, version: s.seq}
+	return s.seq
+}
+
+// SetIfVersion stores value only if key is still at expectedVersion, 0
+// meaning that key must be absent. It returns true and the new version on
+// success; otherwise false and the current version, 0 if key is absent, to
+// retry from without another read.
+func (m *VersionedMap[K, V]) SetIfVersion(key K, value V, expectedVersion uint64) (ok bool, newVersion uint64) {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	if cur := shard.data[key].version; cur != expectedVersion {
+		return false, cur
+	}
+	return true, shard.set(key, value)
+}
+
+// Del removes a value from the map.
+func (m *VersionedMap[K, V]) Del(key K) {
+	shard := m.shard(key)
+	shard.Lock()
+	delete(shard.data, key)
+	shard.Unlock()
+}
+
+// DelIfVersion removes key only if it is at expectedVersion, and reports
+// whether it did.
+func (m *VersionedMap[K, V]) DelIfVersion(key K, expectedVersion uint64) bool {
+	shard := m.shard(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	e, ok := shard.data[key]
+	if !ok || e.version != expectedVersion {
+		return false
+	}
+	delete(shard.data, key)
+	return true
+}
+
+// Len returns the total number of items in the map. Like Map.Len, it is not
+// atomic across shards.
+func (m *VersionedMap[K, V]) Len() int {
+	total := 0
+	for _, shard := range m.shards {
+		shard.RLock()
+		total += len(shard.data)
+		shard.RUnlock()
+	}
+	return total
+}
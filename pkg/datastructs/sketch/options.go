@@ -0,0 +1,50 @@
+package sketch
+
+import "github.com/huynhanx03/go-common/pkg/common/metrics"
+
+// config holds the aging policy of a Sketch.
+type config struct {
+	halveAfter   uint64        // increments between automatic decays, 0 = manual only
+	doorkeeperFP float64       // false-positive rate of the doorkeeper, 0 = none
+	meter        metrics.Meter // nil = uninstrumented
+	seed         int64         // row hash seeds, 0 = random
+	seeded       bool
+}
+
+// Option configures a Sketch.
+type Option func(*config)
+
+// WithHalvingInterval halves every counter after n increments, as in
+// TinyLFU, so frequencies track recent traffic. 0 leaves aging to Decay.
+// A common choice is 10x the number of cache entries.
+func WithHalvingInterval(n uint64) Option {
+	return func(c *config) { c.halveAfter = n }
+}
+
+// WithDoorkeeper puts a Bloom filter sized to the counter count in front of
+// the sketch. The first increment of a key only marks the doorkeeper, so
+// one-hit wonders never occupy counters. The doorkeeper is cleared on every
+// decay. fpRate must be in (0, 1); other values disable it.
+func WithDoorkeeper(fpRate float64) Option {
+	return func(c *config) {
+		if fpRate > 0 && fpRate < 1 {
+			c.doorkeeperFP = fpRate
+		}
+	}
+}
+
+// WithMeter reports increments, doorkeeper hits and decays to m as
+// "sketch.increments", "sketch.doorkeeper_filtered" and "sketch.decays".
+func WithMeter(m metrics.Meter) Option {
+	return func(c *config) { c.meter = m }
+}
+
+// WithSeed derives the row hash seeds from seed instead of the clock, so
+// two sketches fed the same keys give the same estimates, collisions
+// included. Simulations and tests use it for reproducible results.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+		c.seeded = true
+	}
+}
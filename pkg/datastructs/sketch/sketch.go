@@ -4,6 +4,8 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+	"github.com/huynhanx03/go-common/pkg/datastructs/bloom"
 	"github.com/huynhanx03/go-common/pkg/utils"
 )
 
@@ -13,10 +15,23 @@ type Sketch struct {
 	rows [cmDepth]cmRow
 	seed [cmDepth]uint64
 	mask uint64
+
+	doorkeeper *bloom.Bloom // nil unless WithDoorkeeper
+	halveAfter uint64
+	increments uint64 // since the last decay
+
+	metrics *sketchMetrics // nil unless WithMeter
+}
+
+// sketchMetrics are the instruments of a Sketch created WithMeter.
+type sketchMetrics struct {
+	increments metrics.Counter
+	filtered   metrics.Counter
+	decays     metrics.Counter
 }
 
 // New creates a new Count-Min sketch.
-func New(numCounters int64) *Sketch {
+func New(numCounters int64, opts ...Option) *Sketch {
 	if numCounters <= 0 {
 		numCounters = 1
 	}
@@ -25,16 +40,56 @@ func New(numCounters int64) *Sketch {
 		mask: uint64(n - 1),
 	}
 
-	source := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	seed := time.Now().UnixNano()
+	if cfg.seeded {
+		seed = cfg.seed
+	}
+	source := rand.New(rand.NewSource(seed))
 	for i := 0; i < cmDepth; i++ {
 		s.seed[i] = source.Uint64()
 		s.rows[i] = newCmRow(int64(n))
 	}
+	s.halveAfter = cfg.halveAfter
+	if cfg.doorkeeperFP > 0 {
+		// Cannot fail: capacity is positive and the rate was validated.
+		s.doorkeeper, _ = bloom.New(uint64(n), cfg.doorkeeperFP)
+	}
+	if m := cfg.meter; m != nil {
+		s.metrics = &sketchMetrics{
+			increments: m.Counter("sketch.increments", "Keys counted by the sketch"),
+			filtered:   m.Counter("sketch.doorkeeper_filtered", "First sightings absorbed by the doorkeeper"),
+			decays:     m.Counter("sketch.decays", "Aging passes that halved the counters"),
+		}
+	}
 	return s
 }
 
 // Increment increments the counter for the given hash.
 func (s *Sketch) Increment(hash uint64) {
+	s.increment(hash)
+	if s.halveAfter > 0 {
+		s.increments++
+		if s.increments >= s.halveAfter {
+			s.Decay()
+		}
+	}
+}
+
+func (s *Sketch) increment(hash uint64) {
+	if s.metrics != nil {
+		s.metrics.increments.Add(1)
+	}
+	if s.doorkeeper != nil && !s.doorkeeper.AddIfNotHas(hash) {
+		if s.metrics != nil {
+			s.metrics.filtered.Add(1)
+		}
+		return // first sighting since the last decay
+	}
 	for i := range s.rows {
 		idx := (hash ^ s.seed[i]) & s.mask
 		s.rows[i].increment(idx)
@@ -51,19 +106,40 @@ func (s *Sketch) Estimate(hash uint64) int64 {
 			min = val
 		}
 	}
-	return int64(min)
+	est := int64(min)
+	if s.doorkeeper != nil && s.doorkeeper.Has(hash) {
+		est++
+	}
+	return est
 }
 
-// Reset halves all counter values.
-func (s *Sketch) Reset() {
+// Decay forces an aging pass: halves all counters and clears the doorkeeper.
+// It also restarts the halving interval.
+func (s *Sketch) Decay() {
 	for _, r := range s.rows {
 		r.reset()
 	}
+	if s.doorkeeper != nil {
+		s.doorkeeper.Clear()
+	}
+	s.increments = 0
+	if s.metrics != nil {
+		s.metrics.decays.Add(1)
+	}
+}
+
+// Reset halves all counter values. It is the same as Decay.
+func (s *Sketch) Reset() {
+	s.Decay()
 }
 
-// Clear zeroes all counters.
+// Clear zeroes all counters and the doorkeeper.
 func (s *Sketch) Clear() {
 	for _, r := range s.rows {
 		r.clear()
 	}
+	if s.doorkeeper != nil {
+		s.doorkeeper.Clear()
+	}
+	s.increments = 0
 }
@@ -3,6 +3,8 @@ package sketch
 import (
 	"math"
 	"testing"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
 )
 
 // =============================================================================
@@ -238,6 +240,105 @@ func TestClear(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Aging Policy Tests
+// =============================================================================
+
+func TestHalvingInterval(t *testing.T) {
+	s := New(1000, WithHalvingInterval(10))
+	for i := 0; i < 9; i++ {
+		s.Increment(7)
+	}
+	if got := s.Estimate(7); got != 9 {
+		t.Fatalf("Estimate() before interval = %d, want 9", got)
+	}
+
+	s.Increment(7) // 10th increment triggers the decay: 10 -> 5
+	if got := s.Estimate(7); got != 5 {
+		t.Errorf("Estimate() after interval = %d, want 5", got)
+	}
+}
+
+func TestDoorkeeper(t *testing.T) {
+	t.Run("one_hit_wonder_stays_out", func(t *testing.T) {
+		s := New(1000, WithDoorkeeper(0.01))
+		s.Increment(11)
+		if got := s.Estimate(11); got != 1 {
+			t.Errorf("Estimate() after one hit = %d, want 1", got)
+		}
+		for i := range s.rows {
+			if s.rows[i].get((11^s.seed[i])&s.mask) != 0 {
+				t.Fatal("first increment reached the sketch")
+			}
+		}
+	})
+
+	t.Run("repeat_counts", func(t *testing.T) {
+		s := New(1000, WithDoorkeeper(0.01))
+		for i := 0; i < 5; i++ {
+			s.Increment(22)
+		}
+		if got := s.Estimate(22); got != 5 {
+			t.Errorf("Estimate() = %d, want 5", got)
+		}
+	})
+
+	t.Run("decay_clears_doorkeeper", func(t *testing.T) {
+		s := New(1000, WithDoorkeeper(0.01))
+		s.Increment(33)
+		s.Decay()
+		if got := s.Estimate(33); got != 0 {
+			t.Errorf("Estimate() after Decay = %d, want 0", got)
+		}
+	})
+
+	t.Run("invalid_rate_disables", func(t *testing.T) {
+		s := New(1000, WithDoorkeeper(1))
+		if s.doorkeeper != nil {
+			t.Error("doorkeeper enabled with fpRate 1")
+		}
+	})
+}
+
+func TestWithSeed(t *testing.T) {
+	a, b := New(16, WithSeed(7)), New(16, WithSeed(7))
+	if a.seed != b.seed {
+		t.Fatalf("seeds differ: %v vs %v", a.seed, b.seed)
+	}
+	// 16 counters for 200 keys collide heavily: equal estimates show the
+	// collisions are the same too.
+	for k := range uint64(200) {
+		a.Increment(k * 0x9e3779b97f4a7c15)
+		b.Increment(k * 0x9e3779b97f4a7c15)
+	}
+	for k := range uint64(200) {
+		if ea, eb := a.Estimate(k), b.Estimate(k); ea != eb {
+			t.Fatalf("Estimate(%d) = %d and %d with the same seed", k, ea, eb)
+		}
+	}
+	if c := New(16, WithSeed(8)); c.seed == a.seed {
+		t.Error("different seeds gave the same row seeds")
+	}
+}
+
+func TestWithMeter(t *testing.T) {
+	m := metrics.NewMemory()
+	s := New(1000, WithDoorkeeper(0.01), WithHalvingInterval(4), WithMeter(m))
+	for range 4 {
+		s.Increment(7)
+	}
+
+	if got := m.CounterValue("sketch.increments"); got != 4 {
+		t.Errorf("increments = %d, want 4", got)
+	}
+	if got := m.CounterValue("sketch.doorkeeper_filtered"); got != 1 {
+		t.Errorf("doorkeeper_filtered = %d, want 1", got)
+	}
+	if got := m.CounterValue("sketch.decays"); got != 1 {
+		t.Errorf("decays = %d, want 1", got)
+	}
+}
+
 // =============================================================================
 // Integration Tests
 // =============================================================================
@@ -0,0 +1,199 @@
+package topk
+
+import (
+	"cmp"
+	"container/heap"
+	"slices"
+
+	"github.com/huynhanx03/go-common/pkg/hash"
+)
+
+// SpaceSaving tracks the heaviest keys of a stream with the Space-Saving
+// algorithm: it keeps a fixed number of counters, and a key without one
+// takes over the smallest, inheriting its count as error. Unlike
+// HeavyKeepers it is deterministic and every count comes with a bound:
+// a key's true weight lies in [Count-Error, Count], and any key heavier
+// than Total/counters is guaranteed to be tracked.
+//
+// Keys are indexed by hash.Sum64; two keys with the same hash share a
+// counter, which at 64 bits is negligible for any realistic number of
+// counters. It is not safe for concurrent use: give each goroutine its own
+// and combine them with Merge.
+type SpaceSaving struct {
+	counters int
+	total    uint64
+	heap     ssHeap
+	index    map[uint64]*ssEntry
+}
+
+// Item is a tracked key with its estimated weight.
+type Item struct {
+	Key   string
+	Count uint64 // upper bound of the key's weight
+	Error uint64 // most Count may overestimate by
+}
+
+// Lower returns the weight the key is guaranteed to have.
+func (it Item) Lower() uint64 {
+	return it.Count - it.Error
+}
+
+type ssEntry struct {
+	Item
+	hash  uint64
+	index int // position in the heap
+}
+
+// ssHeap is a min-heap of entries by count.
+type ssHeap []*ssEntry
+
+func (h ssHeap) Len() int           { return len(h) }
+func (h ssHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h ssHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *ssHeap) Push(x any) {
+	e := x.(*ssEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *ssHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
+
+// NewSpaceSaving creates a SpaceSaving with the given number of counters,
+// at least 1. More counters than the number of keys wanted tighten the
+// error bounds: with m counters no Error exceeds Total/m.
+func NewSpaceSaving(counters int) *SpaceSaving {
+	counters = max(counters, 1)
+	return &SpaceSaving{
+		counters: counters,
+		heap:     make(ssHeap, 0, counters),
+		index:    make(map[uint64]*ssEntry, counters),
+	}
+}
+
+// Add adds weight to key. A zero weight is ignored.
+func (s *SpaceSaving) Add(key string, weight uint64) {
+	if weight == 0 {
+		return
+	}
+	s.total += weight
+	h := hash.Sum64(key)
+
+	if e, ok := s.index[h]; ok {
+		e.Count += weight
+		heap.Fix(&s.heap, e.index)
+		return
+	}
+	if len(s.heap) < s.counters {
+		e := &ssEntry{Item: Item{Key: key, Count: weight}, hash: h}
+		heap.Push(&s.heap, e)
+		s.index[h] = e
+		return
+	}
+
+	// Take over the smallest counter.
+	e := s.heap[0]
+	delete(s.index, e.hash)
+	e.Key, e.hash = key, h
+	e.Error = e.Count
+	e.Count += weight
+	s.index[h] = e
+	heap.Fix(&s.heap, 0)
+}
+
+// Estimate returns the tracked item for key, false if key has no counter:
+// its weight is then at most MinCount.
+func (s *SpaceSaving) Estimate(key string) (Item, bool) {
+	e, ok := s.index[hash.Sum64(key)]
+	if !ok {
+		return Item{}, false
+	}
+	return e.Item, true
+}
+
+// Top returns the n heaviest items by Count, heaviest first; every tracked
+// item if n <= 0 or more than are tracked.
+func (s *SpaceSaving) Top(n int) []Item {
+	items := make([]Item, len(s.heap))
+	for i, e := range s.heap {
+		items[i] = e.Item
+	}
+	slices.SortFunc(items, func(a, b Item) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Error, b.Error))
+	})
+	if n > 0 && n < len(items) {
+		items = items[:n]
+	}
+	return items
+}
+
+// Total returns the sum of all weights added, merged ones included.
+func (s *SpaceSaving) Total() uint64 {
+	return s.total
+}
+
+// MinCount returns the smallest tracked count once every counter is in
+// use, else 0: the most weight an untracked key can have.
+func (s *SpaceSaving) MinCount() uint64 {
+	if len(s.heap) < s.counters {
+		return 0
+	}
+	return s.heap[0].Count
+}
+
+// Merge adds the stream summarized by other to s, so per-goroutine or
+// per-node summaries combine into one. A key tracked by only one side is
+// charged the other side's MinCount, as both count and error, to keep the
+// bounds valid; the heaviest keys then keep the counters of s.
+func (s *SpaceSaving) Merge(other *SpaceSaving) {
+	minS, minO := s.MinCount(), other.MinCount()
+
+	merged := make(map[uint64]*ssEntry, len(s.heap)+len(other.heap))
+	for _, e := range s.heap {
+		e.Count += minO
+		e.Error += minO
+		merged[e.hash] = e
+	}
+	for _, o := range other.heap {
+		if e, ok := merged[o.hash]; ok {
+			// Both sides track it: undo the charge and add the real count.
+			e.Count += o.Count - minO
+			e.Error += o.Error - minO
+			continue
+		}
+		merged[o.hash] = &ssEntry{
+			Item: Item{Key: o.Key, Count: o.Count + minS, Error: o.Error + minS},
+			hash: o.hash,
+		}
+	}
+
+	entries := make(ssHeap, 0, len(merged))
+	for _, e := range merged {
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b *ssEntry) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.hash, b.hash))
+	})
+	if len(entries) > s.counters {
+		clear(entries[s.counters:])
+		entries = entries[:s.counters]
+	}
+
+	clear(s.index)
+	for i, e := range entries {
+		e.index = i
+		s.index[e.hash] = e
+	}
+	s.heap = entries
+	heap.Init(&s.heap)
+	s.total += other.total
+}
@@ -0,0 +1,121 @@
+package topk
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+// zipfStream returns n keys drawn from a Zipf distribution over 1000 keys
+// and their true weights.
+func zipfStream(seed uint64, n int) ([]string, map[string]uint64) {
+	z := rand.NewZipf(rand.New(rand.NewPCG(seed, seed)), 1.2, 1, 999)
+	keys := make([]string, n)
+	truth := make(map[string]uint64)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", z.Uint64())
+		truth[keys[i]]++
+	}
+	return keys, truth
+}
+
+// checkBounds fails if a tracked key's true weight is outside its bounds.
+func checkBounds(t *testing.T, s *SpaceSaving, truth map[string]uint64) {
+	t.Helper()
+	for _, it := range s.Top(0) {
+		if w := truth[it.Key]; w < it.Lower() || w > it.Count {
+			t.Errorf("%s: weight %d outside [%d, %d]", it.Key, w, it.Lower(), it.Count)
+		}
+	}
+}
+
+func TestSpaceSaving_Exact(t *testing.T) {
+	s := NewSpaceSaving(4)
+	s.Add("a", 5)
+	s.Add("b", 2)
+	s.Add("a", 1)
+	s.Add("c", 0) // ignored
+
+	top := s.Top(0)
+	if len(top) != 2 || top[0] != (Item{Key: "a", Count: 6}) || top[1] != (Item{Key: "b", Count: 2}) {
+		t.Fatalf("Top = %+v", top)
+	}
+	if s.Total() != 8 || s.MinCount() != 0 {
+		t.Errorf("Total, MinCount = %d, %d, want 8, 0", s.Total(), s.MinCount())
+	}
+	if _, ok := s.Estimate("c"); ok {
+		t.Error("zero-weight key tracked")
+	}
+}
+
+func TestSpaceSaving_Eviction(t *testing.T) {
+	s := NewSpaceSaving(2)
+	s.Add("a", 10)
+	s.Add("b", 3)
+	s.Add("c", 1) // takes over b's counter
+
+	if _, ok := s.Estimate("b"); ok {
+		t.Error("b kept its counter")
+	}
+	it, ok := s.Estimate("c")
+	if !ok || it.Count != 4 || it.Error != 3 || it.Lower() != 1 {
+		t.Errorf("Estimate(c) = %+v, %v, want count 4, error 3", it, ok)
+	}
+	if s.MinCount() != 4 {
+		t.Errorf("MinCount = %d, want 4", s.MinCount())
+	}
+}
+
+func TestSpaceSaving_HeavyHitters(t *testing.T) {
+	const counters = 50
+	keys, truth := zipfStream(1, 100_000)
+	s := NewSpaceSaving(counters)
+	for _, k := range keys {
+		s.Add(k, 1)
+	}
+
+	checkBounds(t, s, truth)
+	// Every key heavier than Total/counters must be tracked.
+	for k, w := range truth {
+		if w > s.Total()/counters {
+			if _, ok := s.Estimate(k); !ok {
+				t.Errorf("heavy key %s (weight %d) not tracked", k, w)
+			}
+		}
+	}
+	if top := s.Top(3); len(top) != 3 || top[0].Key != "key-0" || top[0].Count < top[1].Count {
+		t.Errorf("Top(3) = %+v", top)
+	}
+}
+
+func TestSpaceSaving_Merge(t *testing.T) {
+	a, b := NewSpaceSaving(40), NewSpaceSaving(40)
+	keysA, truth := zipfStream(2, 50_000)
+	keysB, truthB := zipfStream(3, 50_000)
+	for _, k := range keysA {
+		a.Add(k, 1)
+	}
+	for _, k := range keysB {
+		b.Add(k, 1)
+	}
+	for k, w := range truthB {
+		truth[k] += w
+	}
+
+	a.Merge(b)
+	if a.Total() != 100_000 {
+		t.Errorf("Total = %d, want 100000", a.Total())
+	}
+	if n := len(a.Top(0)); n != 40 {
+		t.Errorf("tracked %d keys after Merge, want 40", n)
+	}
+	checkBounds(t, a, truth)
+	if top := a.Top(1); top[0].Key != "key-0" {
+		t.Errorf("heaviest after Merge = %+v, want key-0", top[0])
+	}
+
+	// The merged summary keeps working.
+	a.Add("key-0", 10)
+	truth["key-0"] += 10
+	checkBounds(t, a, truth)
+}
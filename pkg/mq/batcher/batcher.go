@@ -2,6 +2,8 @@ package batcher
 
 import (
 	"sync"
+
+	bufferpool "github.com/huynhanx03/go-common/pkg/pool/buffer"
 )
 
 // StripedBatcher is a high-performance, concurrent batcher using striped buffers.
@@ -14,37 +16,130 @@ import (
 //   - This is a "Lossy" design regarding graceful shutdown: items pending in stripes
 //     inside the pool are NOT guaranteed to be flushed on shutdown unless Consumer
 //     handles tracking. Use this for metrics, logs, or cache events where speed > absolute precision.
+//   - With Config.IdleTimeout, a reclaimer flushes stripes left idle after a burst
+//     and Close flushes all of them, at the cost of an uncontended lock per Push.
 type StripedBatcher[T any] struct {
-	pool *sync.Pool
+	pool      *sync.Pool
+	reclaimer *reclaimer[T] // nil unless Config.IdleTimeout
+	closeOnce sync.Once
 }
 
 // New creates a new StripedBatcher for type T.
 func New[T any](cons Consumer[T], cfg Config) *StripedBatcher[T] {
+	return newBatcher(cons, cfg, false)
+}
+
+// NewEncoding creates a StripedBatcher that serializes every full stripe
+// with enc into a pooled Buffer and hands it to cons. Since the batch slice
+// is consumed before the flush returns, stripes reuse their backing slice
+// instead of allocating a new one per flush.
+func NewEncoding[T any](enc Encoder[T], cons BufferConsumer, cfg Config) *StripedBatcher[T] {
+	return newBatcher[T](&encodingConsumer[T]{enc: enc, cons: cons}, cfg, true)
+}
+
+func newBatcher[T any](cons Consumer[T], cfg Config, reuse bool) *StripedBatcher[T] {
 	// Default config
 	if cfg.StripeSize <= 0 {
 		cfg.StripeSize = 512
 	}
 
-	return &StripedBatcher[T]{
+	m := newBatchMetrics(cfg.Meter)
+	b := &StripedBatcher[T]{
 		pool: &sync.Pool{
 			New: func() any {
-				return newStripe[T](cons, cfg.StripeSize)
+				s := newStripe[T](cons, cfg.StripeSize, m)
+				s.reuse = reuse
+				return s
 			},
 		},
 	}
+	if cfg.IdleTimeout > 0 {
+		b.reclaimer = newReclaimer[T](cfg.IdleTimeout, m)
+	}
+	return b
+}
+
+// encodingConsumer adapts an Encoder + BufferConsumer to Consumer.
+type encodingConsumer[T any] struct {
+	enc  Encoder[T]
+	cons BufferConsumer
+}
+
+// Consume encodes batch into a pooled Buffer and passes it on.
+func (e *encodingConsumer[T]) Consume(batch []T) error {
+	buf := bufferpool.Get()
+	buf.ReleaseFn = func() {
+		bufferpool.Put(buf)
+	}
+
+	if err := e.enc(batch, buf); err != nil {
+		_ = buf.Release()
+		return err
+	}
+	return e.cons.ConsumeBuffer(buf)
 }
 
+var _ Consumer[int] = (*encodingConsumer[int])(nil)
+
 // Push adds an item to the batcher.
 // It may trigger a flush to Consumer if the underlying stripe becomes full.
 func (b *StripedBatcher[T]) Push(item T) {
+	b.push(item, nil)
+}
+
+// PushWithAck adds an item like Push and calls ack once the batch holding
+// it has been consumed, with the error the Consumer returned: nil means the
+// batch was processed, e.g. that offsets up to item may be committed. With
+// NewRetryingConsumer that error comes after the retries, so it is the
+// batch's final outcome; a dead-lettered batch acks nil when the
+// dead-letter consumer takes it.
+//
+// ack runs on the flushing goroutine right after Consume returns, once per
+// item, so it should be quick. An item is only acked when its stripe is
+// flushed: without Config.IdleTimeout a partial stripe may never be, and
+// its acks never fire. Set IdleTimeout and Close the batcher for every ack
+// to fire. A nil ack makes PushWithAck the same as Push.
+func (b *StripedBatcher[T]) PushWithAck(item T, ack func(err error)) {
+	b.push(item, ack)
+}
+
+func (b *StripedBatcher[T]) push(item T, ack func(err error)) {
 	// 1. Get a local stripe from the pool.
 	//    This effectively picks a buffer associated with the current P (goroutine),
 	//    minimizing contention.
 	s := b.pool.Get().(*stripe[T])
 
 	// 2. Push item to the stripe (not thread-safe, but we own it right now).
-	s.Push(item)
+	//    With a reclaimer, the stripe lock keeps it out while we push.
+	if r := b.reclaimer; r != nil {
+		s.mu.Lock()
+		r.push(s)
+		s.Push(item, ack)
+		s.mu.Unlock()
+	} else {
+		s.Push(item, ack)
+	}
 
 	// 3. Return stripe to the pool.
 	b.pool.Put(s)
 }
+
+// Stats returns the idle reclaimer's counters; zero without
+// Config.IdleTimeout. Config.Meter reports the same and more.
+func (b *StripedBatcher[T]) Stats() Stats {
+	if b.reclaimer == nil {
+		return Stats{}
+	}
+	return b.reclaimer.stats()
+}
+
+// Close stops the idle reclaimer and flushes the items pending in every
+// stripe it tracks, which makes shutdown lossless for Pushes that returned
+// before Close. Without Config.IdleTimeout stripes are not tracked and Close
+// does nothing. Push must not be called after Close.
+func (b *StripedBatcher[T]) Close() {
+	if b.reclaimer == nil {
+		return
+	}
+	b.closeOnce.Do(b.reclaimer.close)
+}
@@ -1,9 +1,17 @@
 package batcher
 
 import (
+	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/algorithm"
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+	"github.com/huynhanx03/go-common/pkg/datastructs/bloom"
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
 )
 
 // mockConsumer is a test Consumer that tracks received batches.
@@ -290,6 +298,46 @@ func TestFlush_ConsumerErrorIgnored(t *testing.T) {
 	}
 }
 
+func TestFlush_Meter(t *testing.T) {
+	t.Run("full_stripes", func(t *testing.T) {
+		m := metrics.NewMemory()
+		b := New[int](&mockConsumer[int]{err: errTest}, Config{StripeSize: 1, Meter: m})
+		for i := range 4 {
+			b.Push(i)
+		}
+
+		if got := m.CounterValue("batcher.flushes"); got != 4 {
+			t.Errorf("flushes = %d, want 4", got)
+		}
+		if got := m.CounterValue("batcher.flush_errors"); got != 4 {
+			t.Errorf("flush_errors = %d, want 4", got)
+		}
+		if n, sum := m.HistogramValue("batcher.batch_size"); n != 4 || sum != 4 {
+			t.Errorf("batch_size = (%d, %v), want (4, 4)", n, sum)
+		}
+	})
+
+	t.Run("idle_stripes", func(t *testing.T) {
+		m := metrics.NewMemory()
+		b := New[int](&mockConsumer[int]{}, Config{StripeSize: 100, IdleTimeout: time.Hour, Meter: m})
+		for i := range 10 {
+			b.Push(i)
+		}
+		b.Close()
+
+		// sync.Pool may have spread the items over several stripes.
+		if got := m.CounterValue("batcher.idle_flushed"); got != 10 {
+			t.Errorf("idle_flushed = %d, want 10", got)
+		}
+		if _, sum := m.HistogramValue("batcher.batch_size"); sum != 10 {
+			t.Errorf("batch_size sum = %v, want 10", sum)
+		}
+		if got := m.CounterValue("batcher.reclaimed"); got < 1 {
+			t.Errorf("reclaimed = %d, want at least 1", got)
+		}
+	})
+}
+
 var errTest = &testError{}
 
 type testError struct{}
@@ -446,3 +494,378 @@ func TestGeneric_StructType(t *testing.T) {
 		t.Errorf("unexpected batch content: %v", cons.batches[0])
 	}
 }
+
+// --- Encoding Tests ---
+
+// bufConsumer records the payload of each encoded batch and releases it.
+type bufConsumer struct {
+	mu       sync.Mutex
+	payloads []string
+}
+
+func (c *bufConsumer) ConsumeBuffer(buf *buffer.Buffer) error {
+	c.mu.Lock()
+	c.payloads = append(c.payloads, string(buf.Bytes()))
+	c.mu.Unlock()
+	return buf.Release()
+}
+
+func joinEncoder(batch []string, buf *buffer.Buffer) error {
+	for _, s := range batch {
+		if _, err := buf.Write([]byte(s + ";")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestNewEncoding(t *testing.T) {
+	cons := &bufConsumer{}
+	b := NewEncoding[string](joinEncoder, cons, Config{StripeSize: 2})
+
+	b.Push("a")
+	b.Push("b")
+
+	if len(cons.payloads) != 1 || cons.payloads[0] != "a;b;" {
+		t.Fatalf("payloads = %q, want [\"a;b;\"]", cons.payloads)
+	}
+}
+
+func TestNewEncoding_EncoderError(t *testing.T) {
+	cons := &bufConsumer{}
+	var calls atomic.Int32
+	b := NewEncoding[int](func([]int, *buffer.Buffer) error {
+		calls.Add(1)
+		return errors.New("encode failed")
+	}, cons, Config{StripeSize: 1})
+
+	b.Push(1)
+
+	if calls.Load() != 1 {
+		t.Errorf("encoder calls = %d, want 1", calls.Load())
+	}
+	if len(cons.payloads) != 0 {
+		t.Errorf("consumer received %d payloads after encoder error", len(cons.payloads))
+	}
+}
+
+// --- Retry Tests ---
+
+// flakyConsumer fails with errs in order, then succeeds.
+type flakyConsumer struct {
+	mockConsumer[int]
+	errs []error
+}
+
+func (f *flakyConsumer) Consume(batch []int) error {
+	_ = f.mockConsumer.Consume(batch)
+	if n := int(f.calls.Load()); n <= len(f.errs) {
+		return f.errs[n-1]
+	}
+	return nil
+}
+
+var noDelay = algorithm.NewConstantBackoff(time.Nanosecond)
+
+func TestIsRetryable(t *testing.T) {
+	base := errors.New("boom")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unclassified", base, true},
+		{"permanent", Permanent(base), false},
+		{"wrapped_permanent", fmt.Errorf("consume: %w", Permanent(base)), false},
+		{"retryable_over_permanent", Retryable(Permanent(base)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+	if !errors.Is(Permanent(base), base) {
+		t.Error("Permanent does not unwrap to the original error")
+	}
+	if Permanent(nil) != nil || Retryable(nil) != nil {
+		t.Error("classifying nil returned a non-nil error")
+	}
+}
+
+func TestRetryingConsumer_RetriesTransient(t *testing.T) {
+	transient := errors.New("timeout")
+	cons := &flakyConsumer{errs: []error{transient, transient}}
+	dead := &mockConsumer[int]{}
+	b := New[int](NewRetryingConsumer[int](cons, dead, RetryPolicy{MaxRetries: 3, Backoff: noDelay}), Config{StripeSize: 2})
+
+	b.Push(1)
+	b.Push(2)
+
+	if got := cons.calls.Load(); got != 3 {
+		t.Errorf("consumer calls = %d, want 3", got)
+	}
+	if got := dead.calls.Load(); got != 0 {
+		t.Errorf("dead-letter calls = %d, want 0", got)
+	}
+}
+
+func TestRetryingConsumer_PermanentGoesStraightToDeadLetter(t *testing.T) {
+	cons := &flakyConsumer{errs: []error{Permanent(errors.New("invalid item"))}}
+	dead := &mockConsumer[int]{}
+	rc := NewRetryingConsumer[int](cons, dead, RetryPolicy{Backoff: noDelay})
+
+	if err := rc.Consume([]int{7, 8}); err != nil {
+		t.Fatalf("Consume = %v, want nil after dead-lettering", err)
+	}
+	if got := cons.calls.Load(); got != 1 {
+		t.Errorf("consumer calls = %d, want 1 (no retries)", got)
+	}
+	if dead.totalItems() != 2 {
+		t.Errorf("dead-letter items = %d, want 2", dead.totalItems())
+	}
+}
+
+func TestRetryingConsumer_ExhaustedRetries(t *testing.T) {
+	transient := errors.New("unavailable")
+	cons := &mockConsumer[int]{err: transient}
+	rc := NewRetryingConsumer[int](cons, nil, RetryPolicy{MaxRetries: 2, Backoff: noDelay})
+
+	if err := rc.Consume([]int{1}); !errors.Is(err, transient) {
+		t.Errorf("Consume = %v, want the consumer error without a dead-letter consumer", err)
+	}
+	if got := cons.calls.Load(); got != 3 {
+		t.Errorf("consumer calls = %d, want 3 (1 + 2 retries)", got)
+	}
+
+	deadErr := errors.New("dlq down")
+	rc = NewRetryingConsumer[int](&mockConsumer[int]{err: Permanent(transient)}, &mockConsumer[int]{err: deadErr}, RetryPolicy{Backoff: noDelay})
+	if err := rc.Consume([]int{1}); !errors.Is(err, transient) || !errors.Is(err, deadErr) {
+		t.Errorf("Consume = %v, want both the consumer and dead-letter errors", err)
+	}
+}
+
+// --- Ack Tests ---
+
+// ackRecorder collects the errors passed to its acks.
+type ackRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *ackRecorder) ack(err error) {
+	r.mu.Lock()
+	r.errs = append(r.errs, err)
+	r.mu.Unlock()
+}
+
+func (r *ackRecorder) acked() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+func TestPushWithAck_AfterConsume(t *testing.T) {
+	cons := &mockConsumer[int]{}
+	b := New[int](cons, Config{StripeSize: 3})
+	rec := &ackRecorder{}
+
+	b.PushWithAck(1, func(err error) {
+		if cons.totalItems() != 3 {
+			t.Errorf("ack ran before the batch was consumed")
+		}
+		rec.ack(err)
+	})
+	b.Push(2)
+	if len(rec.acked()) != 0 {
+		t.Fatal("ack fired before the stripe flushed")
+	}
+	b.PushWithAck(3, rec.ack)
+
+	if errs := rec.acked(); len(errs) != 2 || errs[0] != nil || errs[1] != nil {
+		t.Fatalf("acks = %v, want two nil", errs)
+	}
+
+	// Acks belong to one batch: the next flush does not fire them again.
+	b.Push(4)
+	b.Push(5)
+	b.PushWithAck(6, nil)
+	if got := len(rec.acked()); got != 2 {
+		t.Errorf("acks after a second flush = %d, want 2", got)
+	}
+}
+
+func TestPushWithAck_Failure(t *testing.T) {
+	failed := errors.New("sink down")
+	rc := NewRetryingConsumer[int](&mockConsumer[int]{err: failed}, nil, RetryPolicy{MaxRetries: 1, Backoff: noDelay})
+	b := New[int](rc, Config{StripeSize: 2})
+	rec := &ackRecorder{}
+
+	b.PushWithAck(1, rec.ack)
+	b.PushWithAck(2, rec.ack)
+
+	errs := rec.acked()
+	if len(errs) != 2 {
+		t.Fatalf("acks = %d, want 2", len(errs))
+	}
+	for _, err := range errs {
+		if !errors.Is(err, failed) {
+			t.Errorf("ack err = %v, want %v after retries", err, failed)
+		}
+	}
+}
+
+func TestPushWithAck_CloseFlushes(t *testing.T) {
+	b := New[int](&mockConsumer[int]{}, Config{StripeSize: 100, IdleTimeout: time.Hour})
+	rec := &ackRecorder{}
+
+	for i := range 5 {
+		b.PushWithAck(i, rec.ack)
+	}
+	if len(rec.acked()) != 0 {
+		t.Fatal("ack fired before Close")
+	}
+	b.Close()
+	if got := len(rec.acked()); got != 5 {
+		t.Errorf("acks after Close = %d, want 5", got)
+	}
+}
+
+// --- Dedup Tests ---
+
+func identityKey(v int) uint64 { return uint64(v) }
+
+func TestDeduper_DropsDuplicates(t *testing.T) {
+	seen, err := bloom.NewAging(1000, 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cons := &mockConsumer[int]{}
+	d := NewDeduper[int](cons, identityKey, seen)
+
+	first := []int{1, 2, 3}
+	if err := d.Consume(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Consume([]int{3, 4, 4, 5, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Consume([]int{2, 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cons.calls.Load(); got != 2 {
+		t.Errorf("consumer calls = %d, want 2 (all-duplicate batch skipped)", got)
+	}
+	if got := fmt.Sprint(cons.batches); got != "[[1 2 3] [4 5]]" {
+		t.Errorf("batches = %s, want [[1 2 3] [4 5]]", got)
+	}
+	if d.Passed() != 5 || d.Dropped() != 5 {
+		t.Errorf("Passed, Dropped = %d, %d, want 5, 5", d.Passed(), d.Dropped())
+	}
+}
+
+func TestDeduper_PassesUniqueBatchUnchanged(t *testing.T) {
+	seen, _ := bloom.New(100, 0.001)
+	var got []int
+	d := NewDeduper[int](consumerFunc(func(b []int) error { got = b; return nil }), identityKey, seen)
+
+	batch := []int{7, 8, 9}
+	d.Consume(batch)
+	if &got[0] != &batch[0] {
+		t.Error("unique batch was copied")
+	}
+}
+
+// consumerFunc adapts a function to Consumer.
+type consumerFunc func([]int) error
+
+func (f consumerFunc) Consume(b []int) error { return f(b) }
+
+// --- Tee Tests ---
+
+func TestTee_DeliversToAll(t *testing.T) {
+	auditErr := errors.New("audit down")
+	var first, second, last []int
+	tee := NewTee[int](
+		consumerFunc(func(b []int) error { first = b; return nil }),
+		consumerFunc(func(b []int) error { second = b; return auditErr }),
+		consumerFunc(func(b []int) error { last = b; return nil }),
+	)
+
+	batch := []int{1, 2, 3}
+	if err := tee.Consume(batch); !errors.Is(err, auditErr) {
+		t.Fatalf("Consume err = %v, want the failing consumer's error", err)
+	}
+	if len(first) != 3 || len(second) != 3 || len(last) != 3 {
+		t.Fatal("a consumer missed the batch")
+	}
+	if &first[0] == &batch[0] || &second[0] == &batch[0] {
+		t.Error("non-final consumer shares the batch slice")
+	}
+	if &last[0] != &batch[0] {
+		t.Error("final consumer got a copy")
+	}
+	if tee.Failures(0) != 0 || tee.Failures(1) != 1 {
+		t.Errorf("Failures = %d, %d, want 0, 1", tee.Failures(0), tee.Failures(1))
+	}
+}
+
+// --- Idle Reclaim Tests ---
+
+func TestIdleReclaim_FlushesAndReleasesIdleStripes(t *testing.T) {
+	cons := &mockConsumer[int]{}
+	b := New[int](cons, Config{StripeSize: 100, IdleTimeout: 20 * time.Millisecond})
+	defer b.Close()
+
+	b.Push(1)
+	b.Push(2)
+	b.Push(3)
+	if st := b.Stats(); st.Stripes == 0 {
+		t.Fatalf("Stats().Stripes = 0 after Push, want tracked stripes")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for cons.totalItems() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("idle stripe not flushed, got %d items", cons.totalItems())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	st := b.Stats()
+	if st.Stripes != 0 || st.Reclaimed == 0 || st.IdleFlushed != 3 {
+		t.Errorf("Stats() = %+v, want 0 stripes, >0 reclaimed, 3 idle-flushed", st)
+	}
+
+	// A reclaimed stripe is usable again.
+	b.Push(4)
+	if st := b.Stats(); st.Stripes == 0 {
+		t.Error("stripe not tracked again after reuse")
+	}
+}
+
+func TestIdleReclaim_CloseFlushesPending(t *testing.T) {
+	cons := &mockConsumer[int]{}
+	b := New[int](cons, Config{StripeSize: 100, IdleTimeout: time.Hour})
+
+	for i := range 10 {
+		b.Push(i)
+	}
+	b.Close()
+	b.Close() // idempotent
+
+	if got := cons.totalItems(); got != 10 {
+		t.Errorf("items after Close = %d, want 10", got)
+	}
+}
+
+func TestIdleReclaim_Disabled(t *testing.T) {
+	b := New[int](&mockConsumer[int]{}, Config{StripeSize: 10})
+	b.Push(1)
+	b.Close()
+	if st := b.Stats(); st != (Stats{}) {
+		t.Errorf("Stats() = %+v without IdleTimeout, want zero", st)
+	}
+}
@@ -0,0 +1,82 @@
+package batcher
+
+import "sync/atomic"
+
+// SeenSet remembers item keys for a Deduper. AddIfNotHas records hash and
+// reports whether it was (probably) recorded before. *bloom.Bloom and
+// *bloom.AgingBloom implement it; the aging filter forgets old keys, which
+// suits unbounded streams.
+type SeenSet interface {
+	AddIfNotHas(hash uint64) bool
+}
+
+// KeyFunc returns the deduplication key of an item as a 64-bit hash, e.g.
+// hash.Sum64(msg.ID).
+type KeyFunc[T any] func(item T) uint64
+
+// Deduper is a Consumer that drops items whose key was already seen and
+// passes the rest to the wrapped consumer.
+//
+// Deduplication is probabilistic when seen is a Bloom filter: a false
+// positive drops a new item, at the filter's false-positive rate. Keys are
+// recorded before the wrapped consumer runs, so a batch that fails is not
+// let through again when retried; wrap the retrying consumer, not the
+// reverse: NewDeduper(NewRetryingConsumer(cons, dlq, policy), key, seen).
+type Deduper[T any] struct {
+	cons Consumer[T]
+	key  KeyFunc[T]
+	seen SeenSet
+
+	passed  atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// NewDeduper wraps cons. seen must be safe for concurrent use if the
+// Deduper is shared by several stripes, as the bloom types are.
+func NewDeduper[T any](cons Consumer[T], key KeyFunc[T], seen SeenSet) *Deduper[T] {
+	return &Deduper[T]{cons: cons, key: key, seen: seen}
+}
+
+// Consume drops the duplicates in batch, including repeats within it, and
+// hands the remaining items to the wrapped consumer. A batch with no
+// duplicates is passed through unchanged; otherwise the consumer gets a new
+// slice, so it may retain it as with an unwrapped batcher. A batch made only
+// of duplicates is not delivered.
+func (d *Deduper[T]) Consume(batch []T) error {
+	var kept []T
+	for i, item := range batch {
+		if !d.seen.AddIfNotHas(d.key(item)) {
+			if kept != nil {
+				kept = append(kept, item)
+			}
+			continue
+		}
+		if kept == nil {
+			kept = make([]T, i, len(batch)-1)
+			copy(kept, batch[:i])
+		}
+	}
+
+	if kept == nil {
+		kept = batch
+	}
+	d.passed.Add(uint64(len(kept)))
+	d.dropped.Add(uint64(len(batch) - len(kept)))
+
+	if len(kept) == 0 {
+		return nil
+	}
+	return d.cons.Consume(kept)
+}
+
+// Passed returns the number of items handed to the wrapped consumer.
+func (d *Deduper[T]) Passed() uint64 {
+	return d.passed.Load()
+}
+
+// Dropped returns the number of duplicates dropped.
+func (d *Deduper[T]) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+var _ Consumer[int] = (*Deduper[int])(nil)
@@ -1,5 +1,12 @@
 package batcher
 
+import (
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
 // Consumer is the interface that must be implemented by users of the Batcher.
 // It is responsible for processing a batch of items.
 type Consumer[T any] interface {
@@ -13,4 +20,26 @@ type Config struct {
 	// StripeSize is the capacity of a single stripe buffer.
 	// When a stripe reaches this size, it will be flushed to the Consumer.
 	StripeSize int
+
+	// IdleTimeout, if positive, starts a reclaimer that flushes the pending
+	// items of stripes without a Push for about this long and releases their
+	// memory, so a burst does not pin peak-sized stripes. Stop it with Close.
+	IdleTimeout time.Duration
+
+	// Meter, if set, receives "batcher.flushes", "batcher.flush_errors",
+	// "batcher.batch_size" and "batcher.flush_seconds" for every flush, and
+	// "batcher.reclaimed" and "batcher.idle_flushed" from the reclaimer.
+	Meter metrics.Meter
+}
+
+// Encoder serializes a batch into buf, producing a wire-ready payload.
+type Encoder[T any] func(batch []T, buf *buffer.Buffer) error
+
+// BufferConsumer receives batches already encoded into pooled Buffers.
+// It owns buf and must call buf.Release() once done with it, which returns
+// the Buffer to the pool.
+type BufferConsumer interface {
+	// ConsumeBuffer processes an encoded batch.
+	// Returns an error if processing fails.
+	ConsumeBuffer(buf *buffer.Buffer) error
 }
@@ -0,0 +1,126 @@
+package batcher
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// idleTicks is how many reclaimer ticks a stripe must go without a Push to
+// count as idle. The reclaimer ticks every IdleTimeout/idleTicks.
+const idleTicks = 2
+
+// Stats reports the reclaimer's activity. It is zero unless
+// Config.IdleTimeout is set.
+type Stats struct {
+	Stripes     int    // stripes currently tracked (in use or holding memory)
+	Reclaimed   uint64 // idle stripes whose memory was released
+	IdleFlushed uint64 // items flushed from idle stripes before release
+}
+
+// reclaimer releases the memory of stripes that went idle after a burst.
+// sync.Pool alone keeps them at peak size until the GC drops them, and then
+// loses whatever items they still held.
+type reclaimer[T any] struct {
+	tick atomic.Uint64
+
+	mu      sync.Mutex
+	stripes map[*stripe[T]]struct{}
+
+	reclaimed   atomic.Uint64
+	idleFlushed atomic.Uint64
+	metrics     *batchMetrics
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newReclaimer[T any](idle time.Duration, m *batchMetrics) *reclaimer[T] {
+	r := &reclaimer[T]{
+		stripes: make(map[*stripe[T]]struct{}),
+		metrics: m,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go r.run(max(idle/idleTicks, time.Millisecond))
+	return r
+}
+
+func (r *reclaimer[T]) run(interval time.Duration) {
+	defer close(r.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.tick.Add(1)
+			r.sweep(false)
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// push records a Push to s. Caller holds s.mu.
+func (r *reclaimer[T]) push(s *stripe[T]) {
+	s.lastTick = r.tick.Load()
+	if !s.tracked {
+		s.tracked = true
+		r.mu.Lock()
+		r.stripes[s] = struct{}{}
+		r.mu.Unlock()
+	}
+}
+
+// sweep releases idle stripes, or every stripe when all is set. Stripes in
+// the middle of a Push are skipped unless all is set.
+func (r *reclaimer[T]) sweep(all bool) {
+	r.mu.Lock()
+	stripes := make([]*stripe[T], 0, len(r.stripes))
+	for s := range r.stripes {
+		stripes = append(stripes, s)
+	}
+	r.mu.Unlock()
+
+	now := r.tick.Load()
+	for _, s := range stripes {
+		if all {
+			s.mu.Lock()
+		} else if !s.mu.TryLock() {
+			continue
+		}
+		if all || now-s.lastTick >= idleTicks {
+			n := s.release()
+			r.idleFlushed.Add(uint64(n))
+			r.reclaimed.Add(1)
+			r.metrics.idleFlushed.Add(int64(n))
+			r.metrics.reclaimed.Add(1)
+			// Untracked, the stripe is garbage once sync.Pool drops it; if
+			// the pool hands it out again, the next Push tracks it again.
+			s.tracked = false
+			r.mu.Lock()
+			delete(r.stripes, s)
+			r.mu.Unlock()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// close stops the reclaimer and releases every stripe.
+func (r *reclaimer[T]) close() {
+	close(r.stop)
+	<-r.done
+	r.sweep(true)
+}
+
+func (r *reclaimer[T]) stats() Stats {
+	r.mu.Lock()
+	n := len(r.stripes)
+	r.mu.Unlock()
+	return Stats{
+		Stripes:     n,
+		Reclaimed:   r.reclaimed.Load(),
+		IdleFlushed: r.idleFlushed.Load(),
+	}
+}
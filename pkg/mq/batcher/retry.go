@@ -0,0 +1,120 @@
+package batcher
+
+import (
+	"errors"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/algorithm"
+)
+
+// defaultMaxRetries is used when RetryPolicy.MaxRetries is not set.
+const defaultMaxRetries = 3
+
+// RetryableError is implemented by errors that know whether the failed batch
+// is worth retrying. Errors that do not implement it are treated as
+// retryable.
+type RetryableError interface {
+	error
+	Retryable() bool
+}
+
+// classifiedError attaches a retry decision to an error.
+type classifiedError struct {
+	err       error
+	retryable bool
+}
+
+func (e *classifiedError) Error() string   { return e.err.Error() }
+func (e *classifiedError) Unwrap() error   { return e.err }
+func (e *classifiedError) Retryable() bool { return e.retryable }
+
+// Permanent marks err as not retryable, e.g. a validation failure: the batch
+// goes to the dead-letter consumer without further attempts.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, retryable: false}
+}
+
+// Retryable marks err as transient, overriding a permanent classification
+// further down the chain.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, retryable: true}
+}
+
+// IsRetryable reports whether err should be retried. The outermost
+// RetryableError in the chain decides; unclassified errors are retryable.
+func IsRetryable(err error) bool {
+	var re RetryableError
+	if errors.As(err, &re) {
+		return re.Retryable()
+	}
+	return true
+}
+
+// RetryPolicy configures NewRetryingConsumer.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a batch after its first
+	// attempt. Defaults to 3.
+	MaxRetries int
+
+	// Backoff computes the delay before each retry.
+	// Defaults to exponential backoff with jitter.
+	Backoff algorithm.Backoff
+}
+
+// retryingConsumer re-delivers failed batches and dead-letters the rest.
+type retryingConsumer[T any] struct {
+	cons   Consumer[T]
+	dead   Consumer[T]
+	policy RetryPolicy
+}
+
+// NewRetryingConsumer wraps cons with policy. A batch failing with a
+// retryable error is consumed again after a backoff delay; one failing with a
+// permanent error, or still failing after MaxRetries, is handed to dead
+// right away. dead may be nil, in which case such batches are dropped and the
+// error is returned.
+//
+// Retries run on the goroutine that triggered the flush, so a Push that fills
+// a stripe blocks through the backoff delays.
+func NewRetryingConsumer[T any](cons Consumer[T], dead Consumer[T], policy RetryPolicy) Consumer[T] {
+	if policy.MaxRetries <= 0 {
+		policy.MaxRetries = defaultMaxRetries
+	}
+	if policy.Backoff == nil {
+		policy.Backoff = algorithm.DefaultExponentialBackoff()
+	}
+	return &retryingConsumer[T]{cons: cons, dead: dead, policy: policy}
+}
+
+// Consume delivers batch, retrying as the policy allows. It returns nil once
+// the batch is consumed or accepted by the dead-letter consumer.
+func (r *retryingConsumer[T]) Consume(batch []T) error {
+	for attempt := 0; ; attempt++ {
+		err := r.cons.Consume(batch)
+		if err == nil {
+			return nil
+		}
+		if !IsRetryable(err) || attempt == r.policy.MaxRetries {
+			return r.deadLetter(batch, err)
+		}
+		time.Sleep(r.policy.Backoff.Delay(attempt))
+	}
+}
+
+func (r *retryingConsumer[T]) deadLetter(batch []T, err error) error {
+	if r.dead == nil {
+		return err
+	}
+	if derr := r.dead.Consume(batch); derr != nil {
+		return errors.Join(err, derr)
+	}
+	return nil
+}
+
+var _ Consumer[int] = (*retryingConsumer[int])(nil)
@@ -1,36 +1,120 @@
 package batcher
 
+import (
+	"sync"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+)
+
+// batchMetrics are the instruments shared by a batcher's stripes and
+// reclaimer.
+type batchMetrics struct {
+	flushes      metrics.Counter
+	flushErrors  metrics.Counter
+	batchSize    metrics.Histogram
+	flushSeconds metrics.Histogram
+	reclaimed    metrics.Counter
+	idleFlushed  metrics.Counter
+}
+
+func newBatchMetrics(m metrics.Meter) *batchMetrics {
+	m = metrics.OrNoop(m)
+	return &batchMetrics{
+		flushes:      m.Counter("batcher.flushes", "Batches handed to the consumer"),
+		flushErrors:  m.Counter("batcher.flush_errors", "Batches the consumer failed"),
+		batchSize:    m.Histogram("batcher.batch_size", "Items per flushed batch"),
+		flushSeconds: m.Histogram("batcher.flush_seconds", "Time spent in the consumer per batch"),
+		reclaimed:    m.Counter("batcher.reclaimed", "Idle stripes whose memory was released"),
+		idleFlushed:  m.Counter("batcher.idle_flushed", "Items flushed from idle stripes"),
+	}
+}
+
 // stripe represents a single buffer stripe.
 // It is NOT thread-safe and is intended to be used via sync.Pool.
 type stripe[T any] struct {
-	cons Consumer[T]
-	data []T
-	cap  int
+	cons    Consumer[T]
+	data    []T
+	acks    []func(error) // PushWithAck callbacks for the items in data
+	cap     int
+	reuse   bool // consumer never retains the batch; recycle data
+	metrics *batchMetrics
+
+	// Reclaimer state, used only when Config.IdleTimeout is set: mu is held
+	// by Push and by the reclaimer, lastTick is the reclaimer tick of the
+	// last Push, tracked is set while the reclaimer knows the stripe.
+	mu       sync.Mutex
+	lastTick uint64
+	tracked  bool
 }
 
 // newStripe creates a new stripe with the given consumer and capacity.
-func newStripe[T any](cons Consumer[T], capacity int) *stripe[T] {
+func newStripe[T any](cons Consumer[T], capacity int, m *batchMetrics) *stripe[T] {
 	return &stripe[T]{
-		cons: cons,
-		data: make([]T, 0, capacity),
-		cap:  capacity,
+		cons:    cons,
+		cap:     capacity,
+		metrics: m,
 	}
 }
 
-// Push appends an item to the stripe.
+// Push appends an item to the stripe, and ack, if not nil, to the
+// callbacks of the pending batch.
 // If the stripe becomes full, it flushes data to the consumer.
-func (s *stripe[T]) Push(item T) {
+func (s *stripe[T]) Push(item T, ack func(error)) {
+	if s.data == nil {
+		s.data = make([]T, 0, s.cap)
+	}
 	s.data = append(s.data, item)
+	if ack != nil {
+		s.acks = append(s.acks, ack)
+	}
 
 	if len(s.data) >= s.cap {
-		// Flush to consumer
-		// Note: We ignore error here as this is a fire-and-forget pattern typically.
-		// Real error handling should be done inside the Consumer implementation.
-		_ = s.cons.Consume(s.data)
-
-		// Allocation strategy:
-		// We allocate a new slice to ensure the Consumer owns the passed data safely.
-		// This matches Ristretto's safety guarantee.
-		s.data = make([]T, 0, s.cap)
+		s.flush()
+	}
+}
+
+// flush hands the pending items to the consumer.
+func (s *stripe[T]) flush() {
+	// Note: We ignore error here as this is a fire-and-forget pattern typically.
+	// Real error handling should be done inside the Consumer implementation
+	// (see NewRetryingConsumer); it is only counted here.
+	s.metrics.batchSize.Record(float64(len(s.data)))
+	start := time.Now()
+	err := s.cons.Consume(s.data)
+	s.metrics.flushSeconds.Record(time.Since(start).Seconds())
+	s.metrics.flushes.Add(1)
+	if err != nil {
+		s.metrics.flushErrors.Add(1)
+	}
+
+	// Every item of a batch shares its outcome, so the acks need no order.
+	for _, ack := range s.acks {
+		ack(err)
+	}
+	clear(s.acks)
+	s.acks = s.acks[:0]
+
+	// Allocation strategy:
+	// The Consumer owns the passed slice, so the next Push allocates a new
+	// one. This matches Ristretto's safety guarantee. Encoding batchers copy
+	// the batch into a Buffer before Consume returns, so they recycle it.
+	if s.reuse {
+		clear(s.data)
+		s.data = s.data[:0]
+	} else {
+		s.data = nil
+	}
+}
+
+// release flushes any pending items and drops the backing slice, so an idle
+// stripe costs only its header.
+func (s *stripe[T]) release() int {
+	n := len(s.data)
+	if n > 0 {
+		s.flush()
 	}
+	s.data = nil
+	s.acks = nil
+	return n
 }
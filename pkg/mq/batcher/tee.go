@@ -0,0 +1,53 @@
+package batcher
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+)
+
+// Tee is a Consumer that delivers every batch to several consumers, so one
+// batcher can feed a primary sink and, say, an audit or metrics sink
+// without batching the items twice.
+//
+// Consumers run in order on the flushing goroutine and each gets the batch
+// whatever the others return: a failing sink does not starve the rest.
+// Give each its own error handling by wrapping it, e.g. with
+// NewRetryingConsumer and a dead-letter consumer; wrapping the Tee instead
+// would redeliver a batch to the consumers that already took it.
+type Tee[T any] struct {
+	cons     []Consumer[T]
+	failures []atomic.Uint64
+}
+
+// NewTee returns a Tee over cons.
+func NewTee[T any](cons ...Consumer[T]) *Tee[T] {
+	return &Tee[T]{cons: cons, failures: make([]atomic.Uint64, len(cons))}
+}
+
+// Consume hands batch to every consumer. A consumer owns the batch it gets,
+// as with an unwrapped batcher, so all but the last get their own copy.
+// The errors of the consumers that failed are joined, each naming its
+// consumer's position.
+func (t *Tee[T]) Consume(batch []T) error {
+	var errs []error
+	for i, c := range t.cons {
+		b := batch
+		if i < len(t.cons)-1 {
+			b = slices.Clone(batch)
+		}
+		if err := c.Consume(b); err != nil {
+			t.failures[i].Add(1)
+			errs = append(errs, fmt.Errorf("batcher: tee consumer %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Failures returns how many batches consumer i, in NewTee order, failed.
+func (t *Tee[T]) Failures(i int) uint64 {
+	return t.failures[i].Load()
+}
+
+var _ Consumer[int] = (*Tee[int])(nil)
@@ -0,0 +1,460 @@
+package diskqueue
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+// Queue is a durable FIFO backed by segment files.
+//
+// Enqueue appends CRC-framed records to the active segment. Dequeue reads
+// through an in-memory read-ahead and advances a volatile read cursor; Ack
+// persists that cursor and deletes fully consumed segments. Records that
+// were dequeued but not acked are redelivered after a restart
+// (at-least-once). It is safe for concurrent use.
+type Queue struct {
+	mu     sync.Mutex
+	dir    string
+	config Config
+	closed bool
+
+	segments   []segment // ascending; the last one is being written
+	totalBytes int64
+	writeFile  *os.File
+	frame      []byte // reusable frame encoding buffer
+	sinceSync  int
+
+	readSeq     uint64
+	readPos     int64 // position of the next frame in readSeq
+	readFilePos int64 // bytes of readSeq already pulled into readAhead
+	readFile    *os.File
+	readAhead   buffer.LinkedListBuffer
+
+	ackSeq uint64
+	ackPos int64
+}
+
+// Open opens or creates a queue in dir, truncating any torn tail left by a
+// crash and resuming from the last acked position. Corrupt frames before
+// the tail are left in place; Dequeue skips them.
+func Open(dir string, opts ...Option) (*Queue, error) {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("diskqueue: mkdir %s: %w", dir, err)
+	}
+
+	segs, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(segs) == 0 {
+		segs = []segment{{seq: 0}}
+	}
+
+	q := &Queue{dir: dir, config: cfg, segments: segs}
+	if err := q.openWriteSegment(); err != nil {
+		return nil, err
+	}
+	if err := syncDir(dir); err != nil {
+		q.writeFile.Close()
+		return nil, err
+	}
+	for _, s := range q.segments {
+		q.totalBytes += s.size
+	}
+
+	if err := q.loadCursor(); err != nil {
+		q.writeFile.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// openWriteSegment opens the last segment for appending and cuts any torn tail.
+func (q *Queue) openWriteSegment() error {
+	active := &q.segments[len(q.segments)-1]
+	f, err := os.OpenFile(segmentPath(q.dir, active.seq), os.O_CREATE|os.O_RDWR|os.O_APPEND, filePerm)
+	if err != nil {
+		return err
+	}
+
+	valid, err := validLength(f, active.size)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if valid < active.size {
+		if err := f.Truncate(valid); err != nil {
+			f.Close()
+			return err
+		}
+		active.size = valid
+	}
+
+	q.writeFile = f
+	return nil
+}
+
+// loadCursor restores the ack cursor and clamps it to the existing segments.
+func (q *Queue) loadCursor() error {
+	seq, pos, ok, err := readMeta(q.dir)
+	if err != nil {
+		return err
+	}
+
+	first, last := q.segments[0], q.segments[len(q.segments)-1]
+	switch {
+	case !ok || seq < first.seq:
+		seq, pos = first.seq, 0
+	case seq > last.seq:
+		seq, pos = last.seq, last.size
+	}
+	if size := q.segment(seq).size; pos > size {
+		pos = size
+	}
+
+	q.ackSeq, q.ackPos = seq, pos
+	q.readSeq, q.readPos, q.readFilePos = seq, pos, pos
+	return nil
+}
+
+// segment returns the segment with the given sequence number.
+func (q *Queue) segment(seq uint64) *segment {
+	i := sort.Search(len(q.segments), func(i int) bool { return q.segments[i].seq >= seq })
+	return &q.segments[i]
+}
+
+// Enqueue appends p to the queue. The write reaches the OS page cache at
+// once but is fsynced only every SyncEvery enqueues (and when a segment
+// rolls or the queue closes); with the default of 0 a crash can lose
+// records the OS had not yet flushed. Use WithSyncEvery(1) for per-record
+// durability.
+func (q *Queue) Enqueue(p []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+
+	frameLen := int64(frameHeaderSize + len(p))
+	if frameLen > q.config.MaxSegmentBytes {
+		return ErrRecordTooLarge
+	}
+
+	active := &q.segments[len(q.segments)-1]
+	if active.size > 0 && active.size+frameLen > q.config.MaxSegmentBytes {
+		if err := q.roll(); err != nil {
+			return err
+		}
+		active = &q.segments[len(q.segments)-1]
+	}
+
+	q.frame = appendFrame(q.frame[:0], p)
+	if _, err := q.writeFile.Write(q.frame); err != nil {
+		// Drop the partial frame so readers never see it.
+		_ = q.writeFile.Truncate(active.size)
+		return fmt.Errorf("diskqueue: write: %w", err)
+	}
+	active.size += frameLen
+	q.totalBytes += frameLen
+
+	q.sinceSync++
+	if q.config.SyncEvery > 0 && q.sinceSync >= q.config.SyncEvery {
+		if err := q.writeFile.Sync(); err != nil {
+			return err
+		}
+		q.sinceSync = 0
+	}
+
+	return q.enforceRetention()
+}
+
+// roll seals the active segment and starts a new one.
+func (q *Queue) roll() error {
+	if err := q.writeFile.Sync(); err != nil {
+		return err
+	}
+	if err := q.writeFile.Close(); err != nil {
+		return err
+	}
+
+	seq := q.segments[len(q.segments)-1].seq + 1
+	f, err := os.OpenFile(segmentPath(q.dir, seq), os.O_CREATE|os.O_RDWR|os.O_APPEND, filePerm)
+	if err != nil {
+		return err
+	}
+	if err := syncDir(q.dir); err != nil {
+		f.Close()
+		return err
+	}
+	q.writeFile = f
+	q.sinceSync = 0
+	q.segments = append(q.segments, segment{seq: seq})
+	return nil
+}
+
+// enforceRetention drops the oldest sealed segments while over MaxBytes.
+func (q *Queue) enforceRetention() error {
+	if q.config.MaxBytes <= 0 {
+		return nil
+	}
+
+	dropped := false
+	for q.totalBytes > q.config.MaxBytes && len(q.segments) > 1 {
+		oldest := q.segments[0]
+		if err := q.removeOldest(); err != nil {
+			return err
+		}
+		if q.readSeq == oldest.seq {
+			q.resetReader(oldest.seq + 1)
+		}
+		if q.ackSeq == oldest.seq {
+			q.ackSeq, q.ackPos = oldest.seq+1, 0
+			dropped = true
+		}
+		if q.config.OnDrop != nil {
+			q.config.OnDrop(oldest.size)
+		}
+	}
+
+	if dropped {
+		return writeMeta(q.dir, q.ackSeq, q.ackPos)
+	}
+	return nil
+}
+
+// removeOldest deletes the first segment file.
+func (q *Queue) removeOldest() error {
+	oldest := q.segments[0]
+	if err := os.Remove(segmentPath(q.dir, oldest.seq)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	q.totalBytes -= oldest.size
+	q.segments = q.segments[1:]
+	return nil
+}
+
+// Dequeue returns the next record, or ErrEmpty if none is available.
+// The record is redelivered after a restart unless Ack is called.
+func (q *Queue) Dequeue() ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, ErrClosed
+	}
+
+	for {
+		p, err := q.readFrame()
+		if err != ErrEmpty {
+			return p, err
+		}
+		if q.readSeq == q.segments[len(q.segments)-1].seq {
+			return nil, ErrEmpty
+		}
+		// Current segment exhausted: move to the next one.
+		q.resetReader(q.readSeq + 1)
+	}
+}
+
+// readFrame decodes the next frame of the current read segment.
+//
+// The checksum is verified before anything leaves the read-ahead. A corrupt
+// frame is skipped as a whole, reported through OnDrop and returned as
+// ErrCorrupt, so the read cursor, Pending and a later Ack all agree on what
+// was consumed.
+func (q *Queue) readFrame() ([]byte, error) {
+	ok, err := q.fill(frameHeaderSize)
+	if err != nil || !ok {
+		if err == nil {
+			err = ErrEmpty
+		}
+		return nil, err
+	}
+
+	var hdr [frameHeaderSize]byte
+	views, _ := q.readAhead.Peek(frameHeaderSize)
+	off := 0
+	for _, v := range views {
+		off += copy(hdr[off:], v)
+	}
+	n := int(binary.BigEndian.Uint32(hdr[0:]))
+
+	ok, err = q.fill(frameHeaderSize + n)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// The length runs past the segment: nothing after it can be framed.
+		q.skip(q.segment(q.readSeq).size - q.readPos)
+		return nil, fmt.Errorf("%w: truncated frame in segment %d", ErrCorrupt, q.readSeq)
+	}
+
+	if frameChecksum(&q.readAhead, n) != binary.BigEndian.Uint32(hdr[4:]) {
+		q.skip(int64(frameHeaderSize + n))
+		return nil, fmt.Errorf("%w: checksum mismatch in segment %d", ErrCorrupt, q.readSeq)
+	}
+
+	_, _ = q.readAhead.Discard(frameHeaderSize)
+	p := make([]byte, n)
+	_, _ = q.readAhead.Read(p)
+	q.readPos += int64(frameHeaderSize + n)
+	return p, nil
+}
+
+// frameChecksum computes the CRC of the n-byte payload that follows the
+// frame header at the front of ra, without consuming it.
+func frameChecksum(ra *buffer.LinkedListBuffer, n int) uint32 {
+	views, _ := ra.Peek(frameHeaderSize + n)
+	var crc uint32
+	skip := frameHeaderSize
+	for _, v := range views {
+		if skip >= len(v) {
+			skip -= len(v)
+			continue
+		}
+		crc = crc32.Update(crc, crc32cTable, v[skip:])
+		skip = 0
+	}
+	return crc
+}
+
+// skip drops n unreadable bytes at the read cursor and reports them.
+// Bytes not yet pulled into the read-ahead are skipped on disk.
+func (q *Queue) skip(n int64) {
+	buffered := min(n, int64(q.readAhead.Buffered()))
+	_, _ = q.readAhead.Discard(int(buffered))
+	q.readFilePos += n - buffered
+	q.readPos += n
+	if q.config.OnDrop != nil {
+		q.config.OnDrop(n)
+	}
+}
+
+// fill pulls data from the read segment until at least n bytes are buffered.
+// Returns false if the segment does not hold that many more bytes.
+func (q *Queue) fill(n int) (bool, error) {
+	for q.readAhead.Buffered() < n {
+		remain := q.segment(q.readSeq).size - q.readFilePos
+		if remain <= 0 {
+			return false, nil
+		}
+
+		if q.readFile == nil {
+			f, err := os.Open(segmentPath(q.dir, q.readSeq))
+			if err != nil {
+				return false, err
+			}
+			q.readFile = f
+		}
+
+		chunk := int64(max(q.config.ReadAheadBytes, n-q.readAhead.Buffered()))
+		chunk = min(chunk, remain)
+
+		buf := q.readAhead.AllocNode(int(chunk))
+		got, err := q.readFile.ReadAt(buf, q.readFilePos)
+		if got == 0 {
+			q.readAhead.FreeNode(buf)
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return false, fmt.Errorf("diskqueue: read segment %d: %w", q.readSeq, err)
+		}
+		q.readAhead.Append(buf[:got])
+		q.readFilePos += int64(got)
+	}
+	return true, nil
+}
+
+// resetReader positions the read cursor at the start of segment seq.
+func (q *Queue) resetReader(seq uint64) {
+	if q.readFile != nil {
+		q.readFile.Close()
+		q.readFile = nil
+	}
+	q.readAhead.Reset()
+	q.readSeq, q.readPos, q.readFilePos = seq, 0, 0
+}
+
+// Ack persists the read cursor: every record returned by Dequeue so far is
+// consumed for good. Segments that are entirely consumed are deleted.
+func (q *Queue) Ack() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+	if q.ackSeq == q.readSeq && q.ackPos == q.readPos {
+		return nil
+	}
+
+	if err := writeMeta(q.dir, q.readSeq, q.readPos); err != nil {
+		return err
+	}
+	q.ackSeq, q.ackPos = q.readSeq, q.readPos
+
+	for q.segments[0].seq < q.ackSeq {
+		if err := q.removeOldest(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Size returns the total on-disk size of all segments in bytes.
+func (q *Queue) Size() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.totalBytes
+}
+
+// Pending returns the number of bytes (frames included) not yet dequeued.
+func (q *Queue) Pending() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var pending int64
+	for _, s := range q.segments {
+		if s.seq >= q.readSeq {
+			pending += s.size
+		}
+	}
+	return pending - q.readPos
+}
+
+// Close syncs the active segment and releases all files.
+// Records dequeued since the last Ack will be redelivered on reopen.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+
+	if q.readFile != nil {
+		q.readFile.Close()
+		q.readFile = nil
+	}
+	q.readAhead.Reset()
+
+	syncErr := q.writeFile.Sync()
+	if err := q.writeFile.Close(); err != nil && syncErr == nil {
+		syncErr = err
+	}
+	return syncErr
+}
@@ -0,0 +1,326 @@
+package diskqueue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func openQueue(t *testing.T, dir string, opts ...Option) *Queue {
+	t.Helper()
+	q, err := Open(dir, opts...)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return q
+}
+
+func enqueueN(t *testing.T, q *Queue, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		if err := q.Enqueue([]byte(fmt.Sprintf("msg-%03d", i))); err != nil {
+			t.Fatalf("Enqueue %d: %v", i, err)
+		}
+	}
+}
+
+func expectNext(t *testing.T, q *Queue, i int) {
+	t.Helper()
+	p, err := q.Dequeue()
+	if err != nil {
+		t.Fatalf("Dequeue %d: %v", i, err)
+	}
+	if want := fmt.Sprintf("msg-%03d", i); string(p) != want {
+		t.Fatalf("Dequeue = %q, want %q", p, want)
+	}
+}
+
+func expectEmpty(t *testing.T, q *Queue) {
+	t.Helper()
+	if _, err := q.Dequeue(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Dequeue err = %v, want ErrEmpty", err)
+	}
+}
+
+// =============================================================================
+// FIFO
+// =============================================================================
+
+func TestQueueFIFO(t *testing.T) {
+	q := openQueue(t, t.TempDir(), WithReadAheadBytes(16))
+	defer q.Close()
+
+	expectEmpty(t, q)
+	enqueueN(t, q, 0, 50)
+	for i := 0; i < 50; i++ {
+		expectNext(t, q, i)
+	}
+	expectEmpty(t, q)
+
+	// Interleaved writes after the reader caught up.
+	enqueueN(t, q, 50, 52)
+	expectNext(t, q, 50)
+	expectNext(t, q, 51)
+	if q.Pending() != 0 {
+		t.Errorf("Pending = %d, want 0", q.Pending())
+	}
+}
+
+func TestQueueEmptyRecord(t *testing.T) {
+	q := openQueue(t, t.TempDir())
+	defer q.Close()
+
+	if err := q.Enqueue(nil); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	p, err := q.Dequeue()
+	if err != nil || len(p) != 0 {
+		t.Fatalf("Dequeue = %q, %v; want empty record", p, err)
+	}
+}
+
+func TestQueueRecordTooLarge(t *testing.T) {
+	q := openQueue(t, t.TempDir(), WithMaxSegmentBytes(32))
+	defer q.Close()
+
+	if err := q.Enqueue(make([]byte, 64)); !errors.Is(err, ErrRecordTooLarge) {
+		t.Fatalf("Enqueue err = %v, want ErrRecordTooLarge", err)
+	}
+}
+
+// =============================================================================
+// Ack & Recovery
+// =============================================================================
+
+func TestQueueRedeliversUnacked(t *testing.T) {
+	dir := t.TempDir()
+
+	q := openQueue(t, dir)
+	enqueueN(t, q, 0, 10)
+	for i := 0; i < 4; i++ {
+		expectNext(t, q, i)
+	}
+	if err := q.Ack(); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	// Dequeued but never acked.
+	expectNext(t, q, 4)
+	expectNext(t, q, 5)
+	q.Close()
+
+	q = openQueue(t, dir)
+	defer q.Close()
+	for i := 4; i < 10; i++ {
+		expectNext(t, q, i)
+	}
+	expectEmpty(t, q)
+}
+
+func TestQueueSegmentsDeletedAfterAck(t *testing.T) {
+	dir := t.TempDir()
+	// Each frame is 15 bytes, so every segment holds two records.
+	q := openQueue(t, dir, WithMaxSegmentBytes(30))
+	defer q.Close()
+
+	enqueueN(t, q, 0, 10)
+	if segs, _ := listSegments(dir); len(segs) != 5 {
+		t.Fatalf("segments = %d, want 5", len(segs))
+	}
+
+	for i := 0; i < 7; i++ {
+		expectNext(t, q, i)
+	}
+	if err := q.Ack(); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	segs, _ := listSegments(dir)
+	if len(segs) != 2 || segs[0].seq != 3 {
+		t.Fatalf("segments after ack = %+v, want [3 4]", segs)
+	}
+	if q.Size() != 4*15 {
+		t.Errorf("Size = %d, want %d", q.Size(), 4*15)
+	}
+	for i := 7; i < 10; i++ {
+		expectNext(t, q, i)
+	}
+}
+
+func TestQueueTornTail(t *testing.T) {
+	dir := t.TempDir()
+
+	q := openQueue(t, dir)
+	enqueueN(t, q, 0, 3)
+	q.Close()
+
+	// Simulate a crash halfway through a fourth frame.
+	f, err := os.OpenFile(segmentPath(dir, 0), os.O_WRONLY|os.O_APPEND, filePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(appendFrame(nil, []byte("msg-003"))[:10])
+	f.Close()
+
+	q = openQueue(t, dir)
+	defer q.Close()
+	if q.Size() != 3*15 {
+		t.Fatalf("Size after recovery = %d, want %d", q.Size(), 3*15)
+	}
+
+	enqueueN(t, q, 3, 4)
+	for i := 0; i < 4; i++ {
+		expectNext(t, q, i)
+	}
+	expectEmpty(t, q)
+}
+
+func TestQueueCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	q := openQueue(t, dir, WithMaxSegmentBytes(30))
+	enqueueN(t, q, 0, 4)
+	q.Close()
+
+	// Flip a payload byte in a sealed segment.
+	path := segmentPath(dir, 0)
+	data, _ := os.ReadFile(path)
+	data[frameHeaderSize] ^= 0xff
+	os.WriteFile(path, data, filePerm)
+
+	q = openQueue(t, dir)
+	defer q.Close()
+	if _, err := q.Dequeue(); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Dequeue err = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestQueueCorruptRecordSkippedOnce(t *testing.T) {
+	dir := t.TempDir()
+
+	// Four 15-byte records per segment.
+	q := openQueue(t, dir, WithMaxSegmentBytes(60))
+	enqueueN(t, q, 0, 6)
+	q.Close()
+
+	// Flip a payload byte of msg-001 in the sealed segment.
+	path := segmentPath(dir, 0)
+	data, _ := os.ReadFile(path)
+	data[15+frameHeaderSize] ^= 0xff
+	os.WriteFile(path, data, filePerm)
+
+	var dropped int64
+	q = openQueue(t, dir, WithMaxSegmentBytes(60), WithOnDrop(func(n int64) { dropped += n }))
+	expectNext(t, q, 0)
+	if _, err := q.Dequeue(); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Dequeue err = %v, want ErrCorrupt", err)
+	}
+	if dropped != 15 {
+		t.Errorf("dropped = %d, want 15", dropped)
+	}
+	if q.Pending() != 4*15 {
+		t.Errorf("Pending = %d, want %d", q.Pending(), 4*15)
+	}
+	expectNext(t, q, 2)
+	if q.Pending() != 3*15 {
+		t.Errorf("Pending = %d, want %d", q.Pending(), 3*15)
+	}
+	if err := q.Ack(); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	q.Close()
+
+	// Nothing before the ack cursor comes back.
+	q = openQueue(t, dir, WithMaxSegmentBytes(60))
+	defer q.Close()
+	for i := 3; i < 6; i++ {
+		expectNext(t, q, i)
+	}
+	expectEmpty(t, q)
+}
+
+func TestQueueCorruptActiveSegmentKeepsLaterRecords(t *testing.T) {
+	dir := t.TempDir()
+
+	q := openQueue(t, dir)
+	enqueueN(t, q, 0, 4)
+	q.Close()
+
+	// Flip a payload byte of the first frame in the active segment.
+	path := segmentPath(dir, 0)
+	data, _ := os.ReadFile(path)
+	data[frameHeaderSize] ^= 0xff
+	os.WriteFile(path, data, filePerm)
+
+	var dropped int64
+	q = openQueue(t, dir, WithOnDrop(func(n int64) { dropped += n }))
+	defer q.Close()
+	if q.Size() != 4*15 {
+		t.Fatalf("Size after reopen = %d, want %d", q.Size(), 4*15)
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Dequeue err = %v, want ErrCorrupt", err)
+	}
+	for i := 1; i < 4; i++ {
+		expectNext(t, q, i)
+	}
+	expectEmpty(t, q)
+	if dropped != 15 {
+		t.Errorf("dropped = %d, want 15", dropped)
+	}
+}
+
+func TestQueueCorruptFinalFrameIsTornTail(t *testing.T) {
+	dir := t.TempDir()
+
+	q := openQueue(t, dir)
+	enqueueN(t, q, 0, 3)
+	q.Close()
+
+	// A crash can leave the last frame's bytes unwritten.
+	path := segmentPath(dir, 0)
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(path, data, filePerm)
+
+	q = openQueue(t, dir)
+	defer q.Close()
+	if q.Size() != 2*15 {
+		t.Fatalf("Size after recovery = %d, want %d", q.Size(), 2*15)
+	}
+	expectNext(t, q, 0)
+	expectNext(t, q, 1)
+	expectEmpty(t, q)
+}
+
+// =============================================================================
+// Retention
+// =============================================================================
+
+func TestQueueRetentionDropsOldest(t *testing.T) {
+	dir := t.TempDir()
+
+	var dropped int64
+	q := openQueue(t, dir,
+		WithMaxSegmentBytes(30),
+		WithMaxBytes(60),
+		WithOnDrop(func(n int64) { dropped += n }),
+	)
+
+	enqueueN(t, q, 0, 10)
+	if q.Size() > 60 {
+		t.Errorf("Size = %d, want <= 60", q.Size())
+	}
+	if dropped != 6*15 {
+		t.Errorf("dropped = %d, want %d", dropped, 6*15)
+	}
+	for i := 6; i < 10; i++ {
+		expectNext(t, q, i)
+	}
+	q.Close()
+
+	// The ack cursor moved past the dropped segments.
+	q = openQueue(t, dir)
+	defer q.Close()
+	expectNext(t, q, 6)
+}
@@ -0,0 +1,11 @@
+package diskqueue
+
+import "errors"
+
+// Sentinel errors for the disk queue.
+var (
+	ErrEmpty          = errors.New("diskqueue: queue is empty")
+	ErrClosed         = errors.New("diskqueue: queue is closed")
+	ErrCorrupt        = errors.New("diskqueue: corrupt record")
+	ErrRecordTooLarge = errors.New("diskqueue: record exceeds max segment size")
+)
@@ -0,0 +1,82 @@
+package diskqueue
+
+// File system permissions and naming.
+const (
+	dirPerm    = 0755
+	filePerm   = 0644
+	extSegment = ".dq"
+	metaFile   = "meta"
+	metaTmp    = "meta.tmp"
+)
+
+// Record framing: [length (4)] [crc32c (4)] [payload].
+const (
+	frameHeaderSize = 8
+	metaSize        = 16 // ack segment (8) + ack position (8)
+)
+
+// Defaults.
+const (
+	DefaultMaxSegmentBytes = 64 << 20 // 64 MB
+	DefaultMaxBytes        = 1 << 30  // 1 GB
+	DefaultReadAheadBytes  = 64 << 10 // 64 KB
+)
+
+// Config holds all configuration for the queue.
+type Config struct {
+	MaxSegmentBytes int64             // segment file size before rolling
+	MaxBytes        int64             // total on-disk bytes before the oldest segments are dropped, 0 = unlimited
+	ReadAheadBytes  int               // bytes pulled from disk per read-ahead fill
+	SyncEvery       int               // fsync every N enqueues, 0 = let OS decide
+	OnDrop          func(bytes int64) // optional callback when retention drops a segment or a corrupt frame is skipped
+}
+
+// Option configures the queue.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{
+		MaxSegmentBytes: DefaultMaxSegmentBytes,
+		MaxBytes:        DefaultMaxBytes,
+		ReadAheadBytes:  DefaultReadAheadBytes,
+	}
+}
+
+// WithMaxSegmentBytes sets the max segment file size before rolling.
+func WithMaxSegmentBytes(n int64) Option {
+	return func(c *Config) {
+		if n > frameHeaderSize {
+			c.MaxSegmentBytes = n
+		}
+	}
+}
+
+// WithMaxBytes sets the on-disk size limit (0 = unlimited).
+// When exceeded, whole segments are dropped oldest first, acked or not.
+func WithMaxBytes(n int64) Option {
+	return func(c *Config) {
+		if n >= 0 {
+			c.MaxBytes = n
+		}
+	}
+}
+
+// WithReadAheadBytes sets how many bytes each read-ahead fill pulls from disk.
+func WithReadAheadBytes(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.ReadAheadBytes = n
+		}
+	}
+}
+
+// WithSyncEvery sets how often to fsync (0 = OS decides, 1 = every enqueue).
+func WithSyncEvery(n int) Option {
+	return func(c *Config) { c.SyncEvery = n }
+}
+
+// WithOnDrop sets a callback invoked when retention drops a segment or
+// Dequeue skips a corrupt frame.
+func WithOnDrop(fn func(bytes int64)) Option {
+	return func(c *Config) { c.OnDrop = fn }
+}
@@ -0,0 +1,156 @@
+package diskqueue
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// segment describes one on-disk segment file.
+type segment struct {
+	seq  uint64
+	size int64
+}
+
+func segmentPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", seq, extSegment))
+}
+
+// listSegments discovers existing segment files in ascending order.
+func listSegments(dir string) ([]segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segs []segment
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), extSegment) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), extSegment), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, segment{seq: seq, size: info.Size()})
+	}
+
+	sort.Slice(segs, func(i, j int) bool { return segs[i].seq < segs[j].seq })
+	return segs, nil
+}
+
+// appendFrame encodes p as a frame into dst.
+func appendFrame(dst, p []byte) []byte {
+	var hdr [frameHeaderSize]byte
+	binary.BigEndian.PutUint32(hdr[0:], uint32(len(p)))
+	binary.BigEndian.PutUint32(hdr[4:], crc32.Checksum(p, crc32cTable))
+	dst = append(dst, hdr[:]...)
+	return append(dst, p...)
+}
+
+// validLength scans the frames of f and returns the length to keep.
+//
+// Only a torn tail is cut: a frame that runs past the end of the file, or
+// a final frame whose checksum fails. A bad frame with data after it is
+// corruption, not a torn write, so it is kept for Dequeue to skip and
+// report rather than taking every later record with it.
+func validLength(f *os.File, size int64) (int64, error) {
+	var hdr [frameHeaderSize]byte
+	var payload []byte
+	var pos int64
+
+	for pos+frameHeaderSize <= size {
+		if _, err := f.ReadAt(hdr[:], pos); err != nil {
+			return pos, err
+		}
+		n := int64(binary.BigEndian.Uint32(hdr[0:]))
+		end := pos + frameHeaderSize + n
+		if end > size {
+			break
+		}
+		if int64(cap(payload)) < n {
+			payload = make([]byte, n)
+		}
+		payload = payload[:n]
+		if _, err := f.ReadAt(payload, pos+frameHeaderSize); err != nil && err != io.EOF {
+			return pos, err
+		}
+		if end == size && crc32.Checksum(payload, crc32cTable) != binary.BigEndian.Uint32(hdr[4:]) {
+			break
+		}
+		pos = end
+	}
+	return pos, nil
+}
+
+// writeMeta persists the ack cursor using write-to-temp + fsync + rename,
+// then fsyncs the directory so the rename itself is durable.
+func writeMeta(dir string, seq uint64, pos int64) error {
+	var buf [metaSize]byte
+	binary.BigEndian.PutUint64(buf[0:], seq)
+	binary.BigEndian.PutUint64(buf[8:], uint64(pos))
+
+	tmp := filepath.Join(dir, metaTmp)
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePerm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf[:]); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	f.Close()
+	if err := os.Rename(tmp, filepath.Join(dir, metaFile)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir fsyncs dir so file creations and renames inside it survive a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("diskqueue: sync %s: %w", dir, err)
+	}
+	return nil
+}
+
+// readMeta loads the ack cursor. Returns ok=false if none was written yet.
+func readMeta(dir string) (seq uint64, pos int64, ok bool, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, metaFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, false, nil
+		}
+		return 0, 0, false, err
+	}
+	if len(data) < metaSize {
+		return 0, 0, false, nil
+	}
+	return binary.BigEndian.Uint64(data[0:]), int64(binary.BigEndian.Uint64(data[8:])), true, nil
+}
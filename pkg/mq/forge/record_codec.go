@@ -1,6 +1,10 @@
 package forge
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+
+	"github.com/huynhanx03/go-common/pkg/codec/wire"
+)
 
 // appendRecord encodes a single Record and appends to dst.
 func appendRecord(dst []byte, r *Record) []byte {
@@ -95,9 +99,7 @@ func decodeRecord(data []byte) (Record, int, error) {
 // --- varint helpers ---
 
 func appendVarint(dst []byte, v int64) []byte {
-	var buf [binary.MaxVarintLen64]byte
-	n := binary.PutVarint(buf[:], v)
-	return append(dst, buf[:n]...)
+	return wire.AppendVarint(dst, v)
 }
 
 func appendVarintBytes(dst []byte, b []byte) []byte {
@@ -135,16 +137,7 @@ func readVarintBytes(data []byte, off int) ([]byte, int, bool) {
 }
 
 func varIntSize(v int64) int {
-	ux := uint64(v) << 1
-	if v < 0 {
-		ux = ^ux
-	}
-	size := 1
-	for ux >= 0x80 {
-		ux >>= 7
-		size++
-	}
-	return size
+	return wire.VarintSize(v)
 }
 
 func varintBytesSize(b []byte) int {
@@ -0,0 +1,19 @@
+package pipeline
+
+import "errors"
+
+var (
+	// ErrUnconsumed is returned by Run when a stage other than a sink has
+	// no stage reading its output, which would fill up and stall it.
+	ErrUnconsumed = errors.New("pipeline: stage output is not consumed")
+
+	// ErrConsumedTwice is returned by Run when two stages read the output
+	// of one. Items are not broadcast: each would see part of them.
+	ErrConsumedTwice = errors.New("pipeline: stage output is already consumed")
+
+	// ErrStarted is returned by Run on a pipeline that has already run.
+	ErrStarted = errors.New("pipeline: already run")
+
+	// ErrPanic wraps a panic recovered from a stage function.
+	ErrPanic = errors.New("pipeline: stage panicked")
+)
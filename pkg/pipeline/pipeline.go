@@ -0,0 +1,281 @@
+// Package pipeline assembles the library's concurrency primitives into
+// typed processing pipelines:
+//
+//	p := pipeline.New()
+//	lines := pipeline.Source(p, "read", readLines)
+//	events := pipeline.Transform(lines, "parse", parse, pipeline.WithWorkers(8))
+//	batches := pipeline.Batch(events, "batch", 500, 100*time.Millisecond)
+//	pipeline.Sink(batches, "write", write)
+//	err := p.Run(ctx)
+//
+// Stages hand items over through bounded queue.MPMC queues, so a slow stage
+// slows the ones before it down instead of letting items pile up. Transform
+// and Sink run their function on a workerpool pool, Batch groups items with
+// an mq/batcher StripedBatcher. Shutdown follows the data: once a source
+// returns, each stage drains its input, finishes its work in flight and
+// closes its output, so Run returns nil only after the sinks have seen
+// every item. The first error, from any stage, cancels the rest.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/metrics"
+	"github.com/huynhanx03/go-common/pkg/datastructs/queue"
+)
+
+// Defaults for stages.
+const (
+	defaultBuffer  = 1024
+	defaultWorkers = 1
+)
+
+// spinPolls is how many times emit retries a full queue with a yield before
+// it starts sleeping.
+const spinPolls = 64
+
+// maxPause caps emit's sleep between retries of a full queue.
+const maxPause = time.Millisecond
+
+// Option configures a Pipeline.
+type Option func(*Pipeline)
+
+// WithMeter reports every stage's activity to m, labelled stage=<name>:
+// "pipeline.stage.in" and "pipeline.stage.out" for items taken and passed
+// on, "pipeline.stage.errors" for failed calls of the stage function,
+// "pipeline.stage.seconds" for the time of each call, and the depth of
+// the stage's output queue as "pipeline.stage.depth". Batch stages also
+// report their batcher's instruments.
+func WithMeter(m metrics.Meter) Option {
+	return func(p *Pipeline) { p.meter = m }
+}
+
+// StageOption configures a stage.
+type StageOption func(*stageConfig)
+
+type stageConfig struct {
+	workers int
+	buffer  int
+}
+
+// WithWorkers runs a Transform or Sink function on n workers at once.
+// With more than one, items leave the stage out of order. n < 1 is
+// ignored; the default is 1.
+func WithWorkers(n int) StageOption {
+	return func(c *stageConfig) {
+		if n >= 1 {
+			c.workers = n
+		}
+	}
+}
+
+// WithBuffer sets the capacity of the queue between the stage and the
+// next, rounded up to a power of two; the stage waits while it is full.
+// n < 1 is ignored; the default is 1024.
+func WithBuffer(n int) StageOption {
+	return func(c *stageConfig) {
+		if n >= 1 {
+			c.buffer = n
+		}
+	}
+}
+
+func newStageConfig(opts []StageOption) stageConfig {
+	cfg := stageConfig{workers: defaultWorkers, buffer: defaultBuffer}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// StageStats reports one stage's activity.
+type StageStats struct {
+	Name   string
+	In     uint64 // items taken from the previous stage
+	Out    uint64 // items passed to the next stage
+	Errors uint64 // failed calls of the stage function
+	Depth  int64  // items in the output queue, 0 for a sink
+}
+
+// Pipeline is a set of connected stages, built with Source, Transform,
+// Batch and Sink and started with Run. Building is not safe for concurrent
+// use; Stats is, once Run has started.
+type Pipeline struct {
+	stages []*stage
+	meter  metrics.Meter
+	err    error // first wiring error, returned by Run
+	ran    bool
+
+	failOnce sync.Once
+	failErr  error
+	cancel   context.CancelFunc
+}
+
+// New creates an empty pipeline.
+func New(opts ...Option) *Pipeline {
+	p := &Pipeline{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// stage is the untyped part of a stage: its runner, wiring and counters.
+type stage struct {
+	name      string
+	run       func(ctx context.Context) error
+	depth     func() int64 // output queue depth, nil for sinks
+	consumers int
+
+	in, out, errs atomic.Uint64
+
+	inCounter  metrics.Counter
+	outCounter metrics.Counter
+	errCounter metrics.Counter
+	seconds    metrics.Histogram
+	depthGauge metrics.Gauge
+}
+
+// Stage is the output of a stage, to which the next stage is attached.
+type Stage[T any] struct {
+	p   *Pipeline
+	s   *stage
+	out *queue.MPMC[T]
+}
+
+// add registers a stage named name.
+func (p *Pipeline) add(name string) *stage {
+	m := metrics.OrNoop(p.meter)
+	l := metrics.L("stage", name)
+	s := &stage{
+		name:       name,
+		inCounter:  m.Counter("pipeline.stage.in", "Items taken from the previous stage", l),
+		outCounter: m.Counter("pipeline.stage.out", "Items passed to the next stage", l),
+		errCounter: m.Counter("pipeline.stage.errors", "Failed calls of the stage function", l),
+		seconds:    m.Histogram("pipeline.stage.seconds", "Time per call of the stage function", l),
+		depthGauge: m.Gauge("pipeline.stage.depth", "Items in the stage's output queue", l),
+	}
+	p.stages = append(p.stages, s)
+	return s
+}
+
+// attach records a stage reading in's output.
+func attach[T any](in *Stage[T]) {
+	if in.s.consumers++; in.s.consumers > 1 && in.p.err == nil {
+		in.p.err = fmt.Errorf("%w: %q", ErrConsumedTwice, in.s.name)
+	}
+}
+
+// Run starts every stage and waits for them to finish: after the sources
+// return and everything they emitted has been through the sinks, or after
+// the first error, which cancels the rest and is returned. Cancelling ctx
+// stops the pipeline with ctx's error, dropping items in flight. A
+// pipeline runs once.
+func (p *Pipeline) Run(ctx context.Context) error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.ran {
+		return ErrStarted
+	}
+	for _, s := range p.stages {
+		if s.depth != nil && s.consumers == 0 {
+			return fmt.Errorf("%w: %q", ErrUnconsumed, s.name)
+		}
+	}
+	p.ran = true
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	defer p.cancel()
+
+	var wg sync.WaitGroup
+	for _, s := range p.stages {
+		wg.Go(func() {
+			if err := s.run(ctx); err != nil {
+				p.fail(err)
+			}
+		})
+	}
+	wg.Wait()
+	return p.failErr
+}
+
+// fail records the pipeline's first error and cancels it.
+func (p *Pipeline) fail(err error) {
+	p.failOnce.Do(func() {
+		p.failErr = err
+		p.cancel()
+	})
+}
+
+// Stats returns every stage's counters, in the order the stages were
+// added.
+func (p *Pipeline) Stats() []StageStats {
+	out := make([]StageStats, len(p.stages))
+	for i, s := range p.stages {
+		out[i] = StageStats{
+			Name:   s.name,
+			In:     s.in.Load(),
+			Out:    s.out.Load(),
+			Errors: s.errs.Load(),
+		}
+		if s.depth != nil {
+			out[i].Depth = s.depth()
+		}
+	}
+	return out
+}
+
+// call runs fn for stage s, timing it, counting a failure and turning a
+// panic into an error.
+func (s *stage) call(fn func() error) (err error) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %q: %v", ErrPanic, s.name, r)
+		}
+		s.seconds.Record(time.Since(start).Seconds())
+		if err != nil {
+			s.errs.Add(1)
+			s.errCounter.Add(1)
+		}
+	}()
+	return fn()
+}
+
+// taken counts an item taken from the previous stage.
+func (s *stage) taken() {
+	s.in.Add(1)
+	s.inCounter.Add(1)
+}
+
+// emit passes item on to out, waiting while out is full: the backpressure
+// that holds a stage to the pace of the next. It fails only once ctx is
+// done.
+func emit[T any](ctx context.Context, s *stage, out *queue.MPMC[T], item T) error {
+	for polls := 0; ; polls++ {
+		err := out.TryEnqueue(item)
+		if err == nil {
+			s.out.Add(1)
+			s.outCounter.Add(1)
+			s.depthGauge.Set(float64(out.Size()))
+			return nil
+		}
+		if err == queue.ErrClosed {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if polls < spinPolls {
+			runtime.Gosched()
+		} else {
+			time.Sleep(min(time.Microsecond<<min(polls-spinPolls, 20), maxPause))
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// count returns a source function emitting 0..n-1.
+func count(n int) func(context.Context, func(int) error) error {
+	return func(_ context.Context, emit func(int) error) error {
+		for i := range n {
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// =============================================================================
+// Run Tests
+// =============================================================================
+
+func TestRun_SourceTransformSink(t *testing.T) {
+	p := New()
+	nums := Source(p, "count", count(1000), WithBuffer(16))
+	doubled := Transform(nums, "double", func(_ context.Context, v int) (int, error) {
+		return v * 2, nil
+	}, WithBuffer(16))
+
+	var got []int
+	Sink(doubled, "collect", func(_ context.Context, v int) error {
+		got = append(got, v)
+		return nil
+	})
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run = %v, want nil", err)
+	}
+	if len(got) != 1000 {
+		t.Fatalf("sink saw %d items, want 1000", len(got))
+	}
+	// One worker per stage keeps the order.
+	for i, v := range got {
+		if v != i*2 {
+			t.Fatalf("got[%d] = %d, want %d", i, v, i*2)
+		}
+	}
+
+	stats := p.Stats()
+	want := []StageStats{
+		{Name: "count", Out: 1000},
+		{Name: "double", In: 1000, Out: 1000},
+		{Name: "collect", In: 1000},
+	}
+	if !slices.Equal(stats, want) {
+		t.Errorf("Stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestRun_Workers(t *testing.T) {
+	p := New()
+	nums := Source(p, "count", count(500))
+	var sum atomic.Int64
+	Sink(nums, "sum", func(_ context.Context, v int) error {
+		sum.Add(int64(v))
+		return nil
+	}, WithWorkers(4))
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run = %v, want nil", err)
+	}
+	if got := sum.Load(); got != 499*500/2 {
+		t.Errorf("sum = %d, want %d", got, 499*500/2)
+	}
+}
+
+func TestRun_Batch(t *testing.T) {
+	p := New()
+	nums := Source(p, "count", count(1050))
+	batches := Batch(nums, "batch", 100, 10*time.Millisecond)
+
+	var mu sync.Mutex
+	var got []int
+	Sink(batches, "collect", func(_ context.Context, batch []int) error {
+		if len(batch) > 100 {
+			t.Errorf("batch of %d items, want at most 100", len(batch))
+		}
+		mu.Lock()
+		got = append(got, batch...)
+		mu.Unlock()
+		return nil
+	})
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run = %v, want nil", err)
+	}
+	// The partial batch is flushed on shutdown.
+	slices.Sort(got)
+	if len(got) != 1050 || got[0] != 0 || got[1049] != 1049 {
+		t.Fatalf("sink saw %d items, want 0..1049", len(got))
+	}
+}
+
+func TestRun_Backpressure(t *testing.T) {
+	p := New()
+	var emitted atomic.Int64
+	nums := Source(p, "count", func(_ context.Context, emit func(int) error) error {
+		for i := range 200 {
+			if err := emit(i); err != nil {
+				return err
+			}
+			emitted.Add(1)
+		}
+		return nil
+	}, WithBuffer(8))
+
+	release := make(chan struct{})
+	var maxAhead int64
+	Sink(nums, "slow", func(_ context.Context, v int) error {
+		if v == 0 {
+			<-release
+		}
+		maxAhead = max(maxAhead, emitted.Load()-int64(v))
+		return nil
+	})
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run = %v, want nil", err)
+	}
+	// The source stalls on the full queue while the sink is blocked: it gets
+	// at most the queue and the items the sink has taken ahead.
+	if maxAhead > 64 {
+		t.Errorf("source ran %d items ahead of the sink, want it held back", maxAhead)
+	}
+}
+
+func TestRun_ErrorStopsPipeline(t *testing.T) {
+	boom := errors.New("boom")
+	p := New()
+	nums := Source(p, "endless", func(ctx context.Context, emit func(int) error) error {
+		for i := 0; ; i++ {
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+	})
+	Sink(nums, "fail", func(_ context.Context, v int) error {
+		if v == 10 {
+			return boom
+		}
+		return nil
+	})
+
+	if err := p.Run(context.Background()); !errors.Is(err, boom) {
+		t.Fatalf("Run = %v, want %v", err, boom)
+	}
+	if errs := p.Stats()[1].Errors; errs != 1 {
+		t.Errorf("sink errors = %d, want 1", errs)
+	}
+}
+
+func TestRun_Panic(t *testing.T) {
+	p := New()
+	nums := Source(p, "count", count(10))
+	Sink(nums, "panic", func(_ context.Context, v int) error {
+		panic("bad item")
+	})
+
+	if err := p.Run(context.Background()); !errors.Is(err, ErrPanic) {
+		t.Fatalf("Run = %v, want ErrPanic", err)
+	}
+}
+
+func TestRun_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New()
+	nums := Source(p, "endless", func(ctx context.Context, emit func(int) error) error {
+		for {
+			if err := emit(1); err != nil {
+				return err
+			}
+		}
+	})
+	Sink(nums, "cancel", func(_ context.Context, _ int) error {
+		cancel()
+		return nil
+	})
+
+	if err := p.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+}
+
+// =============================================================================
+// Wiring Tests
+// =============================================================================
+
+func TestRun_Wiring(t *testing.T) {
+	p := New()
+	Source(p, "orphan", count(1))
+	if err := p.Run(context.Background()); !errors.Is(err, ErrUnconsumed) {
+		t.Errorf("Run with unread output = %v, want ErrUnconsumed", err)
+	}
+
+	p = New()
+	nums := Source(p, "count", count(1))
+	Sink(nums, "a", func(context.Context, int) error { return nil })
+	Sink(nums, "b", func(context.Context, int) error { return nil })
+	if err := p.Run(context.Background()); !errors.Is(err, ErrConsumedTwice) {
+		t.Errorf("Run with two readers = %v, want ErrConsumedTwice", err)
+	}
+
+	p = New()
+	nums = Source(p, "count", count(1))
+	Sink(nums, "sink", func(context.Context, int) error { return nil })
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run = %v, want nil", err)
+	}
+	if err := p.Run(context.Background()); !errors.Is(err, ErrStarted) {
+		t.Errorf("second Run = %v, want ErrStarted", err)
+	}
+}
@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/common/workerpool"
+	"github.com/huynhanx03/go-common/pkg/datastructs/queue"
+	"github.com/huynhanx03/go-common/pkg/mq/batcher"
+)
+
+// defaultBatchIdle is how long a partial batch waits for more items when
+// Batch is given no idle time.
+const defaultBatchIdle = 100 * time.Millisecond
+
+// newStage registers a stage whose output is a queue of T.
+func newStage[T any](p *Pipeline, name string, cfg stageConfig) *Stage[T] {
+	s := p.add(name)
+	out := queue.NewMPMC[T](cfg.buffer)
+	s.depth = out.Size
+	return &Stage[T]{p: p, s: s, out: out}
+}
+
+// Source adds a stage producing items: fn emits them one by one and
+// returns when done, which starts the pipeline's shutdown once the items
+// have passed through. emit waits while the next stage is behind, and
+// fails once the pipeline is stopping; fn should then return. An error
+// from fn stops the pipeline.
+func Source[T any](p *Pipeline, name string, fn func(ctx context.Context, emit func(T) error) error, opts ...StageOption) *Stage[T] {
+	next := newStage[T](p, name, newStageConfig(opts))
+	s, out := next.s, next.out
+	s.run = func(ctx context.Context) error {
+		defer out.Close()
+		return s.call(func() error {
+			return fn(ctx, func(item T) error {
+				return emit(ctx, s, out, item)
+			})
+		})
+	}
+	return next
+}
+
+// Transform adds a stage mapping every item of in through fn, on
+// WithWorkers workers. An error from fn stops the pipeline.
+func Transform[In, Out any](in *Stage[In], name string, fn func(context.Context, In) (Out, error), opts ...StageOption) *Stage[Out] {
+	cfg := newStageConfig(opts)
+	next := newStage[Out](in.p, name, cfg)
+	s, out := next.s, next.out
+	attach(in)
+	s.run = func(ctx context.Context) error {
+		defer out.Close()
+		return work(ctx, in.p, s, in.out, cfg.workers, func(item In) error {
+			var v Out
+			err := s.call(func() (err error) {
+				v, err = fn(ctx, item)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return emit(ctx, s, out, v)
+		})
+	}
+	return next
+}
+
+// Sink adds a final stage calling fn for every item of in, on WithWorkers
+// workers. An error from fn stops the pipeline.
+func Sink[T any](in *Stage[T], name string, fn func(context.Context, T) error, opts ...StageOption) {
+	cfg := newStageConfig(opts)
+	s := in.p.add(name)
+	attach(in)
+	s.run = func(ctx context.Context) error {
+		return work(ctx, in.p, s, in.out, cfg.workers, func(item T) error {
+			return s.call(func() error { return fn(ctx, item) })
+		})
+	}
+}
+
+// Batch adds a stage grouping the items of in into batches of size, with
+// an mq/batcher StripedBatcher. A partial batch is passed on once no item
+// has arrived for about idle, 100ms if idle <= 0, and when in is drained.
+func Batch[T any](in *Stage[T], name string, size int, idle time.Duration, opts ...StageOption) *Stage[[]T] {
+	if idle <= 0 {
+		idle = defaultBatchIdle
+	}
+	next := newStage[[]T](in.p, name, newStageConfig(opts))
+	s, out := next.s, next.out
+	attach(in)
+	s.run = func(ctx context.Context) error {
+		defer out.Close()
+		b := batcher.New[T](batchConsumer[T](func(batch []T) error {
+			return emit(ctx, s, out, batch)
+		}), batcher.Config{StripeSize: size, IdleTimeout: idle, Meter: in.p.meter})
+		err := queue.ConsumeLoop(ctx, queue.Queue[T](in.out), func(items []T) {
+			for _, item := range items {
+				s.taken()
+				b.Push(item)
+			}
+		})
+		b.Close() // flushes the partial batches
+		return err
+	}
+	return next
+}
+
+// batchConsumer adapts a function to batcher.Consumer.
+type batchConsumer[T any] func([]T) error
+
+func (f batchConsumer[T]) Consume(batch []T) error { return f(batch) }
+
+// work feeds the items of in to fn on a pool of workers until in is
+// closed and drained, then waits for the calls in flight. A failed call
+// stops the pipeline.
+func work[T any](ctx context.Context, p *Pipeline, s *stage, in *queue.MPMC[T], workers int, fn func(T) error) error {
+	var wg sync.WaitGroup
+	pool, err := workerpool.NewGenericPool(workers, func(item T) {
+		defer wg.Done()
+		if err := fn(item); err != nil {
+			p.fail(err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer pool.Release()
+
+	// Taking at most one item per worker keeps the stage from holding more
+	// than its workers can start, so a full pool holds the previous stage
+	// back through the queue.
+	err = queue.ConsumeLoop(ctx, queue.Queue[T](in), func(items []T) {
+		for _, item := range items {
+			s.taken()
+			wg.Add(1)
+			if err := pool.Invoke(item); err != nil {
+				wg.Done()
+				p.fail(err)
+			}
+		}
+	}, queue.WithBatchLimits(1, workers))
+	wg.Wait()
+	return err
+}
@@ -1,6 +1,8 @@
 package byteslice
 
 import (
+	"unsafe"
+
 	"github.com/huynhanx03/go-common/pkg/pool/internal/calibrated"
 )
 
@@ -25,6 +27,49 @@ func Get(size int) []byte {
 	return b[:size]
 }
 
+// GetZeroed is Get with the whole capacity zeroed, for slices handed to
+// code that must not see what earlier users of the pool wrote, such as a
+// caller's io.Reader.
+func GetZeroed(size int) []byte {
+	b := Get(size)
+	clear(b[:cap(b)])
+	return b
+}
+
+// GetAligned returns a byte slice of the given size whose first byte sits
+// at a multiple of align, a power of two, for direct I/O and SIMD loads.
+// Put accepts it like any other slice. It panics if align is not a power
+// of two.
+func GetAligned(size, align int) []byte {
+	if align <= 0 || align&(align-1) != 0 {
+		panic("byteslice: alignment must be a power of two")
+	}
+	b := Get(size)
+	if misalign(b, align) == 0 {
+		return b
+	}
+	Put(b)
+
+	b = Get(size + align - 1)
+	off := misalign(b, align)
+	if off != 0 {
+		off = align - off
+	}
+	return b[off : off+size]
+}
+
+// misalign returns the address of b's first byte modulo align.
+func misalign(b []byte, align int) int {
+	return int(uintptr(unsafe.Pointer(unsafe.SliceData(b))) & uintptr(align-1))
+}
+
+// SetMaxRetained caps how many slices the size class serving size keeps
+// for reuse; slices Put beyond it are left to the GC. n <= 0 removes the
+// cap. Useful to bound memory pinned by rare large classes.
+func SetMaxRetained(size, n int) {
+	defaultPool.SetMaxRetained(calibrated.SizeToIndex(size), n)
+}
+
 // Put returns a byte slice to the pool.
 func Put(b []byte) {
 	if len(b) == 0 {
@@ -0,0 +1,68 @@
+package byteslice
+
+import (
+	"testing"
+)
+
+func TestGetZeroed(t *testing.T) {
+	for range 10 {
+		b := Get(100)
+		for i := range b[:cap(b)] {
+			b[:cap(b)][i] = 0xff
+		}
+		Put(b)
+	}
+
+	b := GetZeroed(100)
+	if len(b) != 100 {
+		t.Fatalf("len = %d, want 100", len(b))
+	}
+	for i, c := range b[:cap(b)] {
+		if c != 0 {
+			t.Fatalf("byte %d = %#x, want 0", i, c)
+		}
+	}
+}
+
+func TestGetAligned(t *testing.T) {
+	for _, align := range []int{1, 8, 64, 512, 4096} {
+		for _, size := range []int{1, 100, 4096, 10000} {
+			b := GetAligned(size, align)
+			if len(b) != size {
+				t.Errorf("GetAligned(%d, %d): len = %d", size, align, len(b))
+			}
+			if m := misalign(b, align); m != 0 {
+				t.Errorf("GetAligned(%d, %d): misaligned by %d", size, align, m)
+			}
+			Put(b)
+		}
+	}
+}
+
+func TestGetAligned_PanicsOnBadAlign(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("GetAligned(8, 3) did not panic")
+		}
+	}()
+	GetAligned(8, 3)
+}
+
+func TestSetMaxRetained(t *testing.T) {
+	const size = 1 << 20
+	SetMaxRetained(size, 1)
+	defer SetMaxRetained(size, 0)
+
+	a, b := Get(size), Get(size)
+	Put(a)
+	Put(b) // over the cap: dropped
+
+	x := Get(size)
+	if &x[0] != &a[0] && &x[0] != &b[0] {
+		t.Skip("pooled slice reclaimed by GC")
+	}
+	y := Get(size)
+	if &y[0] == &a[0] || &y[0] == &b[0] {
+		t.Error("pool kept more slices than its cap")
+	}
+}
@@ -24,6 +24,8 @@ type Pool[T any] struct {
 	defaultSize uint64
 	maxSize     uint64
 	buckets     [Steps]sync.Pool
+	limits      [Steps]atomic.Int64 // max retained per bucket, 0 = unlimited
+	retained    [Steps]atomic.Int64 // approximate items held per bucket
 	newFunc     func(size int) T
 	sizeFunc    func(T) int
 	resetFunc   func(T)
@@ -36,12 +38,6 @@ func New[T any](newFunc func(size int) T, sizeFunc func(T) int, resetFunc func(T
 		sizeFunc:  sizeFunc,
 		resetFunc: resetFunc,
 	}
-	for i := range p.buckets {
-		size := MinSize << i
-		p.buckets[i].New = func() any {
-			return newFunc(size)
-		}
-	}
 	return p
 }
 
@@ -56,7 +52,16 @@ func (p *Pool[T]) Get(size int) T {
 		return p.newFunc(size)
 	}
 
-	return p.buckets[idx].Get().(T)
+	v := p.buckets[idx].Get()
+	if v == nil {
+		// The bucket is empty: whatever the GC dropped no longer counts.
+		p.retained[idx].Store(0)
+		return p.newFunc(MinSize << idx)
+	}
+	if p.limits[idx].Load() > 0 && p.retained[idx].Add(-1) < 0 {
+		p.retained[idx].Store(0)
+	}
+	return v.(T)
 }
 
 // Put returns an item to the pool.
@@ -70,6 +75,14 @@ func (p *Pool[T]) Put(item T) {
 	if idx >= Steps {
 		return
 	}
+	// Items smaller than their bucket (e.g. re-sliced from the front) go one
+	// bucket down so Get never hands out less than it was asked for.
+	if size < MinSize<<idx {
+		if idx == 0 {
+			return
+		}
+		idx--
+	}
 
 	if atomic.AddUint64(&p.calls[idx], 1) > CalibrateThreshold {
 		p.calibrate()
@@ -80,12 +93,28 @@ func (p *Pool[T]) Put(item T) {
 		return
 	}
 
+	if limit := p.limits[idx].Load(); limit > 0 && p.retained[idx].Add(1) > limit {
+		p.retained[idx].Add(-1)
+		return
+	}
 	if p.resetFunc != nil {
 		p.resetFunc(item)
 	}
 	p.buckets[idx].Put(item)
 }
 
+// SetMaxRetained caps the items bucket idx keeps for reuse; Puts beyond it
+// are left to the GC. n <= 0 removes the cap. The count is approximate:
+// items the GC reclaims from the bucket are forgotten once a Get finds it
+// empty.
+func (p *Pool[T]) SetMaxRetained(idx, n int) {
+	if idx < 0 || idx >= Steps {
+		return
+	}
+	p.retained[idx].Store(0)
+	p.limits[idx].Store(int64(max(n, 0)))
+}
+
 // calibrate analyzes usage patterns and adjusts default/max sizes.
 func (p *Pool[T]) calibrate() {
 	if !atomic.CompareAndSwapUint64(&p.calibrating, 0, 1) {
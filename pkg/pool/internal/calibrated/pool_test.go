@@ -0,0 +1,76 @@
+package calibrated
+
+import "testing"
+
+func newBytePool() *Pool[[]byte] {
+	return New(
+		func(size int) []byte { return make([]byte, size) },
+		func(b []byte) int { return cap(b) },
+		nil,
+	)
+}
+
+func TestSizeToIndex(t *testing.T) {
+	tests := []struct {
+		size, want int
+	}{
+		{1, 0},
+		{MinSize, 0},
+		{MinSize + 1, 1},
+		{2 * MinSize, 1},
+		{MaxSize, Steps - 1},
+		{MaxSize + 1, Steps},
+	}
+	for _, tt := range tests {
+		if got := SizeToIndex(tt.size); got != tt.want {
+			t.Errorf("SizeToIndex(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestGet_AtLeastSize(t *testing.T) {
+	p := newBytePool()
+	for _, size := range []int{0, 1, 64, 100, 4096, MaxSize + 1} {
+		if b := p.Get(size); cap(b) < size {
+			t.Errorf("Get(%d): cap = %d", size, cap(b))
+		}
+	}
+}
+
+func TestPut_UndersizedGoesBucketDown(t *testing.T) {
+	p := newBytePool()
+
+	// A 100-byte slice lands in the 64-byte bucket, never the 128-byte one.
+	for range 100 {
+		p.Put(make([]byte, 100))
+	}
+	for range 100 {
+		if b := p.Get(128); cap(b) < 128 {
+			t.Fatalf("Get(128) returned an undersized slice: cap %d", cap(b))
+		}
+	}
+	if stats := p.GetStats(); stats[0] != 100 || stats[1] != 0 {
+		t.Errorf("Put calls per bucket = %v, want 100 in bucket 0", stats[:2])
+	}
+
+	// Below the smallest bucket there is nowhere to go: the slice is dropped.
+	for range 100 {
+		p.Put(make([]byte, MinSize-1))
+	}
+	for range 100 {
+		if b := p.Get(MinSize); cap(b) < MinSize {
+			t.Fatalf("Get(%d) returned an undersized slice: cap %d", MinSize, cap(b))
+		}
+	}
+}
+
+func TestSetMaxRetained(t *testing.T) {
+	p := newBytePool()
+	p.SetMaxRetained(0, 2)
+	for range 10 {
+		p.Put(make([]byte, MinSize))
+	}
+	if got := p.retained[0].Load(); got != 2 {
+		t.Errorf("retained = %d, want 2", got)
+	}
+}
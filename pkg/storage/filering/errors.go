@@ -0,0 +1,11 @@
+package filering
+
+import "errors"
+
+// Sentinel errors for the file ring.
+var (
+	ErrClosed      = errors.New("filering: ring is closed")
+	ErrCorrupt     = errors.New("filering: corrupt header")
+	ErrOverwritten = errors.New("filering: offset overwritten")
+	ErrCapacity    = errors.New("filering: invalid capacity")
+)
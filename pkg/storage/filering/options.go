@@ -0,0 +1,46 @@
+package filering
+
+// File layout.
+const (
+	filePerm   = 0644
+	headerSize = 64 // magic, version, capacity, head, tail, CRC32, padding
+	version    = 1
+)
+
+// magic starts every ring file.
+var magic = [4]byte{'F', 'R', 'N', 'G'}
+
+// Defaults.
+const (
+	DefaultStageSize = 64 << 10
+)
+
+// Config holds all configuration for the ring.
+type Config struct {
+	StageSize int  // bytes buffered in memory before a flush to the file
+	SyncFlush bool // fsync the file after every flush
+}
+
+// Option configures the ring.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{StageSize: DefaultStageSize}
+}
+
+// WithStageSize sets how many bytes Write buffers in memory before writing
+// them to the file. It is rounded down to a power of two and capped at the
+// ring's capacity.
+func WithStageSize(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.StageSize = n
+		}
+	}
+}
+
+// WithSyncFlush fsyncs the file after every flush, so that a crash loses
+// at most the bytes still staged in memory.
+func WithSyncFlush() Option {
+	return func(c *Config) { c.SyncFlush = true }
+}
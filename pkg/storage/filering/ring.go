@@ -0,0 +1,303 @@
+// Package filering implements a persistent circular log: a fixed-size file
+// that keeps the most recent bytes written to it, overwriting the oldest
+// once full. It suits flight-recorder style capture of diagnostics, where
+// only the last few megabytes before an incident matter and must survive a
+// restart.
+//
+// Bytes are addressed by logical offsets that grow forever: Tail is the
+// offset the next write lands at, Head the oldest offset still held, at
+// most Cap bytes behind Tail. Writes are staged in a fixed RingBuffer and
+// written to the file when it fills, on Flush and on Close; the header
+// records Head and Tail after every flush.
+package filering
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/buffer"
+)
+
+// Ring is a file-backed circular log. It is safe for concurrent use.
+type Ring struct {
+	file     *os.File
+	config   Config
+	capacity int64 // bytes of log data in the file, after the header
+
+	mu     sync.Mutex
+	head   int64 // oldest offset held in the file
+	tail   int64 // offset after the last byte in the file
+	stage  *buffer.RingBuffer
+	closed bool
+}
+
+// Open opens the ring file at path, creating it with room for capacity
+// bytes of log data if it does not exist. An existing file keeps the
+// capacity it was created with, and its Head and Tail as of its last flush.
+func Open(path string, capacity int64, opts ...Option) (*Ring, error) {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, filePerm)
+	if err != nil {
+		return nil, fmt.Errorf("filering: open %s: %w", path, err)
+	}
+
+	r := &Ring{file: f, config: cfg}
+	if err := r.load(capacity); err != nil {
+		f.Close()
+		return nil, err
+	}
+	stageSize := int(min(int64(cfg.StageSize), r.capacity))
+	r.stage = buffer.NewRingFrom(make([]byte, stageSize), false)
+	return r, nil
+}
+
+// load reads the header of an existing file, or sizes and initialises an
+// empty one for capacity bytes.
+func (r *Ring) load(capacity int64) error {
+	info, err := r.file.Stat()
+	if err != nil {
+		return fmt.Errorf("filering: stat: %w", err)
+	}
+	if info.Size() == 0 {
+		if capacity <= 0 {
+			return fmt.Errorf("%w: %d", ErrCapacity, capacity)
+		}
+		r.capacity = capacity
+		if err := r.file.Truncate(headerSize + capacity); err != nil {
+			return fmt.Errorf("filering: truncate: %w", err)
+		}
+		return r.writeHeader(0, 0)
+	}
+
+	var hdr [headerSize]byte
+	if _, err := r.file.ReadAt(hdr[:], 0); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if [4]byte(hdr[:4]) != magic || binary.BigEndian.Uint32(hdr[4:]) != version ||
+		binary.BigEndian.Uint32(hdr[32:]) != crc32.ChecksumIEEE(hdr[:32]) {
+		return ErrCorrupt
+	}
+	r.capacity = int64(binary.BigEndian.Uint64(hdr[8:]))
+	r.head = int64(binary.BigEndian.Uint64(hdr[16:]))
+	r.tail = int64(binary.BigEndian.Uint64(hdr[24:]))
+	if r.capacity <= 0 || r.head < 0 || r.head > r.tail || r.tail-r.head > r.capacity ||
+		info.Size() < headerSize+r.capacity {
+		return ErrCorrupt
+	}
+	return nil
+}
+
+// writeHeader records head and tail in the file header.
+func (r *Ring) writeHeader(head, tail int64) error {
+	var hdr [headerSize]byte
+	copy(hdr[:], magic[:])
+	binary.BigEndian.PutUint32(hdr[4:], version)
+	binary.BigEndian.PutUint64(hdr[8:], uint64(r.capacity))
+	binary.BigEndian.PutUint64(hdr[16:], uint64(head))
+	binary.BigEndian.PutUint64(hdr[24:], uint64(tail))
+	binary.BigEndian.PutUint32(hdr[32:], crc32.ChecksumIEEE(hdr[:32]))
+	if _, err := r.file.WriteAt(hdr[:], 0); err != nil {
+		return fmt.Errorf("filering: write header: %w", err)
+	}
+	return nil
+}
+
+// Write appends p to the log. It is staged in memory and reaches the file
+// once the stage fills or on Flush; a p larger than the ring leaves only
+// its last Cap bytes.
+func (r *Ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, ErrClosed
+	}
+
+	written := 0
+	for written < len(p) {
+		n, _ := r.stage.Write(p[written:]) // ErrRingFull once the stage fills
+		written += n
+		if r.stage.IsFull() {
+			if err := r.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Flush writes the staged bytes to the file and records the new Head and
+// Tail in its header.
+func (r *Ring) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	return r.flush()
+}
+
+// flush writes the stage to the file. The header moves Head past the bytes
+// about to be overwritten before they are, so a crash halfway leaves a
+// readable log that is only missing the staged bytes.
+func (r *Ring) flush() error {
+	n := int64(r.stage.Buffered())
+	if n == 0 {
+		return nil
+	}
+	tail := r.tail + n
+	head := max(r.head, tail-r.capacity)
+	if head > r.head {
+		if err := r.writeHeader(head, r.tail); err != nil {
+			return err
+		}
+		r.head = head
+	}
+
+	first, second := r.stage.Peek(0)
+	at := r.tail
+	for _, chunk := range [][]byte{first, second} {
+		if err := r.writeData(at, chunk); err != nil {
+			return err
+		}
+		at += int64(len(chunk))
+	}
+	r.stage.Reset()
+
+	if err := r.writeHeader(head, tail); err != nil {
+		return err
+	}
+	r.tail = tail
+	if r.config.SyncFlush {
+		return r.sync()
+	}
+	return nil
+}
+
+// writeData writes p at logical offset off, wrapping at the end of the
+// file.
+func (r *Ring) writeData(off int64, p []byte) error {
+	for len(p) > 0 {
+		pos := off % r.capacity
+		n := min(int64(len(p)), r.capacity-pos)
+		if _, err := r.file.WriteAt(p[:n], headerSize+pos); err != nil {
+			return fmt.Errorf("filering: write: %w", err)
+		}
+		p, off = p[n:], off+n
+	}
+	return nil
+}
+
+// ReadAt reads len(p) bytes of the log starting at logical offset off,
+// staged bytes included. It returns ErrOverwritten if off is before Head,
+// and io.EOF if the log ends before p is full. Use it with
+// io.NewSectionReader to stream the log from an offset.
+func (r *Ring) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, ErrClosed
+	}
+
+	staged := int64(r.stage.Buffered())
+	end := r.tail + staged
+	if off < max(r.head, end-r.capacity) {
+		return 0, fmt.Errorf("%w: %d is before head %d", ErrOverwritten, off, r.head)
+	}
+	if off >= end {
+		return 0, io.EOF
+	}
+
+	n := 0
+	want := int(min(int64(len(p)), end-off))
+	for n < want && off < r.tail {
+		pos := off % r.capacity
+		chunk := min(int64(want-n), r.capacity-pos, r.tail-off)
+		if _, err := r.file.ReadAt(p[n:n+int(chunk)], headerSize+pos); err != nil {
+			return n, fmt.Errorf("filering: read: %w", err)
+		}
+		n += int(chunk)
+		off += chunk
+	}
+	if n < want {
+		skip := int(off - r.tail)
+		first, second := r.stage.Peek(0)
+		for _, chunk := range [][]byte{first, second} {
+			if skip >= len(chunk) {
+				skip -= len(chunk)
+				continue
+			}
+			n += copy(p[n:want], chunk[skip:])
+			skip = 0
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Head returns the oldest offset ReadAt can read.
+func (r *Ring) Head() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return max(r.head, r.tail+int64(r.stage.Buffered())-r.capacity)
+}
+
+// Tail returns the offset the next byte written lands at.
+func (r *Ring) Tail() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tail + int64(r.stage.Buffered())
+}
+
+// Cap returns the number of bytes the ring holds.
+func (r *Ring) Cap() int64 {
+	return r.capacity
+}
+
+// Sync flushes the staged bytes and fsyncs the file.
+func (r *Ring) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	if err := r.flush(); err != nil {
+		return err
+	}
+	return r.sync()
+}
+
+func (r *Ring) sync() error {
+	if err := r.file.Sync(); err != nil {
+		return fmt.Errorf("filering: sync: %w", err)
+	}
+	return nil
+}
+
+// Close flushes the staged bytes, fsyncs and closes the file.
+func (r *Ring) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	r.closed = true
+
+	err := r.flush()
+	if err == nil {
+		err = r.sync()
+	}
+	if cerr := r.file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("filering: close: %w", cerr)
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package filering
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readAll reads the ring from Head to Tail.
+func readAll(t *testing.T, r *Ring) []byte {
+	t.Helper()
+	head, tail := r.Head(), r.Tail()
+	p := make([]byte, tail-head)
+	if n, err := r.ReadAt(p, head); err != nil || n != len(p) {
+		t.Fatalf("ReadAt(%d) = %d, %v", head, n, err)
+	}
+	return p
+}
+
+func TestWriteRead(t *testing.T) {
+	r, err := Open(filepath.Join(t.TempDir(), "ring"), 1024)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+
+	r.Write([]byte("hello "))
+	r.Write([]byte("world"))
+	if got := readAll(t, r); string(got) != "hello world" {
+		t.Fatalf("staged log = %q, want %q", got, "hello world")
+	}
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	r.Write([]byte("!"))
+
+	// Reads span the file and the stage.
+	p := make([]byte, 6)
+	if n, err := r.ReadAt(p, 6); n != 6 || err != nil || string(p) != "world!" {
+		t.Fatalf("ReadAt(6) = %d, %v, %q", n, err, p)
+	}
+	if n, err := r.ReadAt(p, 9); n != 3 || err != io.EOF {
+		t.Fatalf("ReadAt past tail = %d, %v, want 3, EOF", n, err)
+	}
+	if _, err := r.ReadAt(p, 12); err != io.EOF {
+		t.Fatalf("ReadAt at tail = %v, want EOF", err)
+	}
+}
+
+func TestWrapAround(t *testing.T) {
+	r, err := Open(filepath.Join(t.TempDir(), "ring"), 64, WithStageSize(16))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+
+	var all []byte
+	for i := range 50 {
+		line := []byte{'a' + byte(i%26), 'b', 'c'}
+		all = append(all, line...)
+		if _, err := r.Write(line); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	if head, tail := r.Head(), r.Tail(); tail != 150 || head != 86 {
+		t.Fatalf("Head, Tail = %d, %d, want 86, 150", head, tail)
+	}
+	if got := readAll(t, r); !bytes.Equal(got, all[86:]) {
+		t.Fatalf("log = %q, want %q", got, all[86:])
+	}
+	if _, err := r.ReadAt(make([]byte, 1), 10); !errors.Is(err, ErrOverwritten) {
+		t.Fatalf("ReadAt before head = %v, want ErrOverwritten", err)
+	}
+
+	// A write larger than the ring keeps its end.
+	big := bytes.Repeat([]byte("0123456789"), 20)
+	if n, err := r.Write(big); n != len(big) || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if got := readAll(t, r); !bytes.Equal(got, big[len(big)-64:]) {
+		t.Fatalf("log = %q, want %q", got, big[len(big)-64:])
+	}
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	r, err := Open(path, 32, WithStageSize(8))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	r.Write(bytes.Repeat([]byte("x"), 20))
+	r.Write([]byte("0123456789abcdef"))
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := r.Write([]byte("late")); err != ErrClosed {
+		t.Fatalf("Write after Close = %v, want ErrClosed", err)
+	}
+
+	// The file keeps its capacity whatever Open asks for.
+	r, err = Open(path, 4096)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer r.Close()
+	if r.Cap() != 32 || r.Head() != 4 || r.Tail() != 36 {
+		t.Fatalf("Cap, Head, Tail = %d, %d, %d, want 32, 4, 36", r.Cap(), r.Head(), r.Tail())
+	}
+	want := "xxxxxxxxxxxxxxxx0123456789abcdef"
+	if got := readAll(t, r); string(got) != want {
+		t.Fatalf("log = %q, want %q", got, want)
+	}
+
+	r.Write([]byte("tail"))
+	if got := readAll(t, r); string(got) != want[4:]+"tail" {
+		t.Fatalf("log = %q, want %q", got, want[4:]+"tail")
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Open(filepath.Join(dir, "zero"), 0); !errors.Is(err, ErrCapacity) {
+		t.Errorf("Open with capacity 0 = %v, want ErrCapacity", err)
+	}
+
+	path := filepath.Join(dir, "bad")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{1}, 128), filePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, 64); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Open of a corrupt file = %v, want ErrCorrupt", err)
+	}
+}
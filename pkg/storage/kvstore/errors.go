@@ -0,0 +1,10 @@
+package kvstore
+
+import "errors"
+
+// Sentinel errors for the key-value store.
+var (
+	ErrClosed          = errors.New("kvstore: store is closed")
+	ErrCorruptSnapshot = errors.New("kvstore: corrupt snapshot")
+	ErrFull            = errors.New("kvstore: store is full")
+)
@@ -0,0 +1,322 @@
+package kvstore
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/datastructs/shardedmap"
+	"github.com/huynhanx03/go-common/pkg/hash"
+	"github.com/huynhanx03/go-common/pkg/mq/forge"
+)
+
+// opDelete marks a WAL record as a tombstone.
+var opDelete = []byte("del")
+
+// op is a single write waiting to be committed.
+type op struct {
+	rec  forge.Record
+	err  error // ErrFull when admit turned it away
+	done chan error
+}
+
+// Store is a durable in-memory key-value store.
+//
+// Reads are served from a shardedmap. Every write is appended to a forge
+// CommitLog acting as a write-ahead log and becomes visible only once it is
+// durable. With WithMaxKeys or WithMaxBytes, writes that would grow the
+// store past the bound fail with ErrFull. Open recovers state by loading the latest snapshot and replaying
+// the WAL written after it.
+type Store struct {
+	dir    string
+	config Config
+	data   *shardedmap.Map[string, []byte]
+	wal    *forge.CommitLog
+	size   int64 // key plus value bytes in data, guarded by commitMu
+
+	commitMu sync.Mutex // serializes WAL append + apply against snapshot copies
+	snapMu   sync.Mutex // serializes Snapshot calls
+
+	mu     sync.RWMutex // guards closed and sends on ops
+	closed bool
+
+	ops  chan *op      // group-commit queue
+	done chan struct{} // closed when the committer exits
+}
+
+// Open opens or creates a store in dir and recovers its contents.
+func Open(dir string, opts ...Option) (*Store, error) {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("kvstore: mkdir %s: %w", dir, err)
+	}
+
+	// Clip so the append never writes into the caller's backing array.
+	walOpts := append(slices.Clip(cfg.WALOptions), forge.WithFsyncEvery(1))
+	wal, err := forge.NewCommitLog(filepath.Join(dir, walDir), walOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		dir:    dir,
+		config: cfg,
+		data:   shardedmap.New[string, []byte](cfg.Shards, hash.Sum64[string]),
+		wal:    wal,
+	}
+
+	if err := s.recover(); err != nil {
+		wal.Close()
+		return nil, err
+	}
+
+	if cfg.Durability == DurabilityGroupCommit {
+		s.ops = make(chan *op, cfg.GroupCommitSize)
+		s.done = make(chan struct{})
+		go s.committer()
+	}
+	return s, nil
+}
+
+// Get returns the value stored under key.
+// The returned slice is owned by the store and must not be modified.
+func (s *Store) Get(key string) ([]byte, bool) {
+	return s.data.Get(key)
+}
+
+// Len returns the number of keys in the store.
+func (s *Store) Len() int {
+	return s.data.Len()
+}
+
+// Put durably stores a copy of value under key.
+func (s *Store) Put(key string, value []byte) error {
+	return s.write(forge.Record{Key: []byte(key), Value: bytes.Clone(value)})
+}
+
+// Delete durably removes key.
+func (s *Store) Delete(key string) error {
+	return s.write(forge.Record{
+		Key:     []byte(key),
+		Headers: []forge.Header{{Key: opDelete}},
+	})
+}
+
+// write commits a single record according to the durability mode.
+func (s *Store) write(rec forge.Record) error {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return ErrClosed
+	}
+
+	if s.ops == nil {
+		s.mu.RUnlock()
+		o := &op{rec: rec}
+		if err := s.commit([]*op{o}); err != nil {
+			return err
+		}
+		return o.err
+	}
+
+	o := &op{rec: rec, done: make(chan error, 1)}
+	s.ops <- o
+	s.mu.RUnlock()
+	return <-o.done
+}
+
+// committer drains the group-commit queue until it is closed.
+func (s *Store) committer() {
+	defer close(s.done)
+
+	batch := make([]*op, 0, s.config.GroupCommitSize)
+	timer := time.NewTimer(s.config.GroupCommitLinger)
+	timer.Stop()
+
+	for first := range s.ops {
+		batch = append(batch[:0], first)
+		timer.Reset(s.config.GroupCommitLinger)
+
+	collect:
+		for len(batch) < s.config.GroupCommitSize {
+			select {
+			case o, ok := <-s.ops:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, o)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		err := s.commit(batch)
+		for _, o := range batch {
+			o.done <- cmp.Or(o.err, err)
+		}
+	}
+}
+
+// commit appends the ops admit accepts as one WAL batch and applies them
+// to the map.
+func (s *Store) commit(ops []*op) error {
+	s.commitMu.Lock()
+	defer s.commitMu.Unlock()
+
+	ops = s.admit(ops)
+	if len(ops) == 0 {
+		return nil
+	}
+	recs := make([]forge.Record, len(ops))
+	for i, o := range ops {
+		recs[i] = o.rec
+	}
+
+	if _, err := s.wal.Append(&forge.RecordBatch{Records: recs}); err != nil {
+		return err
+	}
+	for i := range recs {
+		s.apply(&recs[i])
+	}
+	return nil
+}
+
+// admit returns the ops that keep the store within MaxKeys and MaxBytes,
+// counting the ops before them, and fails the others with ErrFull. Writes
+// that do not grow the store are always admitted.
+func (s *Store) admit(ops []*op) []*op {
+	if s.config.MaxKeys == 0 && s.config.MaxBytes == 0 {
+		return ops
+	}
+
+	keys, size := s.data.Len(), s.size
+	staged := make(map[string]int, len(ops)) // entry size after the ops so far, -1 if absent
+	kept := make([]*op, 0, len(ops))
+	for _, o := range ops {
+		key := string(o.rec.Key)
+		cur, ok := staged[key]
+		if !ok {
+			cur = -1
+			if v, found := s.data.Get(key); found {
+				cur = entrySize(key, v)
+			}
+		}
+		next := -1
+		if !isDelete(&o.rec) {
+			next = entrySize(key, o.rec.Value)
+		}
+
+		dKeys, dSize := 0, int64(max(next, 0)-max(cur, 0))
+		switch {
+		case cur < 0 && next >= 0:
+			dKeys = 1
+		case cur >= 0 && next < 0:
+			dKeys = -1
+		}
+		if (dKeys > 0 && s.config.MaxKeys > 0 && keys+dKeys > s.config.MaxKeys) ||
+			(dSize > 0 && s.config.MaxBytes > 0 && size+dSize > s.config.MaxBytes) {
+			o.err = ErrFull
+			continue
+		}
+		staged[key] = next
+		keys += dKeys
+		size += dSize
+		kept = append(kept, o)
+	}
+	return kept
+}
+
+// apply executes a committed record against the map.
+func (s *Store) apply(rec *forge.Record) {
+	key := string(rec.Key)
+	if old, ok := s.data.Get(key); ok {
+		s.size -= int64(entrySize(key, old))
+	}
+	if isDelete(rec) {
+		s.data.Del(key)
+		return
+	}
+	s.data.Set(key, rec.Value)
+	s.size += int64(entrySize(key, rec.Value))
+}
+
+// entrySize is what an entry counts against MaxBytes.
+func entrySize(key string, value []byte) int {
+	return len(key) + len(value)
+}
+
+func isDelete(rec *forge.Record) bool {
+	for _, h := range rec.Headers {
+		if bytes.Equal(h.Key, opDelete) {
+			return true
+		}
+	}
+	return false
+}
+
+// recover loads the latest snapshot and replays the WAL written after it.
+func (s *Store) recover() error {
+	from, err := s.loadSnapshot()
+	if err != nil {
+		return err
+	}
+
+	readBytes := replayReadBytes
+	for off := from; off < s.wal.NewestOffset(); {
+		batches, err := s.wal.Read(off, readBytes)
+		if err != nil {
+			return fmt.Errorf("kvstore: replay at %d: %w", off, err)
+		}
+		if len(batches) == 0 {
+			// The next batch may be larger than the read window.
+			if readBytes >= maxReplayReadBytes {
+				return fmt.Errorf("kvstore: replay stalled at %d", off)
+			}
+			readBytes *= 2
+			continue
+		}
+		for _, b := range batches {
+			for i := range b.Records {
+				rec := &b.Records[i]
+				if b.BaseOffset+uint64(rec.OffsetDelta) < from {
+					continue
+				}
+				// WAL reads may alias mmap'd segments; keep our own copy.
+				rec.Value = bytes.Clone(rec.Value)
+				s.apply(rec)
+			}
+		}
+		last := batches[len(batches)-1]
+		off = last.BaseOffset + uint64(last.RecordCount)
+	}
+	return nil
+}
+
+// Close flushes pending group commits and closes the WAL.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	if s.ops != nil {
+		close(s.ops)
+	}
+	s.mu.Unlock()
+
+	if s.done != nil {
+		<-s.done
+	}
+	return s.wal.Close()
+}
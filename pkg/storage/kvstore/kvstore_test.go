@@ -0,0 +1,235 @@
+package kvstore
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/mq/forge"
+)
+
+func TestPutGetDelete(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Put("a", []byte("1")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if v, ok := s.Get("a"); !ok || string(v) != "1" {
+		t.Fatalf("Get = %q, %v", v, ok)
+	}
+	if err := s.Delete("a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := s.Get("a"); ok {
+		t.Fatal("key still present after Delete")
+	}
+}
+
+func TestRecoverFromWAL(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		s.Put(fmt.Sprintf("k%d", i), []byte(fmt.Sprintf("v%d", i)))
+	}
+	s.Delete("k7")
+	s.Put("k8", []byte("updated"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+
+	if n := s.Len(); n != 99 {
+		t.Errorf("Len = %d, want 99", n)
+	}
+	if _, ok := s.Get("k7"); ok {
+		t.Error("deleted key k7 resurrected")
+	}
+	if v, _ := s.Get("k8"); string(v) != "updated" {
+		t.Errorf("k8 = %q, want updated", v)
+	}
+}
+
+func TestRecoverFromSnapshotAndWAL(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	s.Put("before", []byte("1"))
+	s.Put("gone", []byte("x"))
+	if err := s.Snapshot(); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	s.Put("after", []byte("2"))
+	s.Delete("gone")
+	s.Close()
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+
+	if v, _ := s.Get("before"); string(v) != "1" {
+		t.Errorf("before = %q, want 1", v)
+	}
+	if v, _ := s.Get("after"); string(v) != "2" {
+		t.Errorf("after = %q, want 2", v)
+	}
+	if _, ok := s.Get("gone"); ok {
+		t.Error("key deleted after snapshot resurrected")
+	}
+}
+
+func TestGroupCommit(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, WithGroupCommit(64, 2*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if err := s.Put(fmt.Sprintf("%d-%d", w, i), []byte("v")); err != nil {
+					t.Errorf("Put: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	s.Close()
+
+	if err := s.Put("late", nil); err != ErrClosed {
+		t.Errorf("Put after Close = %v, want ErrClosed", err)
+	}
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	if n := s.Len(); n != 400 {
+		t.Errorf("Len = %d, want 400", n)
+	}
+}
+
+func TestMaxKeys(t *testing.T) {
+	s, err := Open(t.TempDir(), WithMaxKeys(2))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	s.Put("a", []byte("1"))
+	s.Put("b", []byte("2"))
+	if err := s.Put("c", []byte("3")); err != ErrFull {
+		t.Fatalf("Put past MaxKeys = %v, want ErrFull", err)
+	}
+	if _, ok := s.Get("c"); ok {
+		t.Fatal("rejected key is visible")
+	}
+	if err := s.Put("a", []byte("overwrite")); err != nil {
+		t.Errorf("overwrite at MaxKeys = %v, want nil", err)
+	}
+	s.Delete("a")
+	if err := s.Put("c", []byte("3")); err != nil {
+		t.Errorf("Put after a Delete = %v, want nil", err)
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, WithMaxBytes(10))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := s.Put("a", []byte("123456789")); err != nil { // 10 bytes
+		t.Fatalf("Put up to MaxBytes = %v", err)
+	}
+	if err := s.Put("b", []byte("1")); err != ErrFull {
+		t.Fatalf("Put past MaxBytes = %v, want ErrFull", err)
+	}
+	if err := s.Put("a", []byte("1234567")); err != nil {
+		t.Fatalf("shrinking overwrite = %v", err)
+	}
+	s.Close()
+
+	// The byte count is rebuilt on recovery.
+	s, err = Open(dir, WithMaxBytes(10))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	if err := s.Put("b", []byte("1")); err != nil {
+		t.Errorf("Put within MaxBytes after reopen = %v", err)
+	}
+	if err := s.Put("c", nil); err != ErrFull {
+		t.Errorf("Put past MaxBytes after reopen = %v, want ErrFull", err)
+	}
+}
+
+func TestMaxBytesGroupCommit(t *testing.T) {
+	s, err := Open(t.TempDir(), WithGroupCommit(64, 5*time.Millisecond), WithMaxBytes(10))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	// Twenty 2-byte entries, likely in one batch: exactly five fit.
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		full int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := s.Put(string(rune('a'+i)), []byte("v"))
+			mu.Lock()
+			defer mu.Unlock()
+			switch err {
+			case nil:
+			case ErrFull:
+				full++
+			default:
+				t.Errorf("Put: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if full != 15 || s.Len() != 5 {
+		t.Errorf("%d rejected, Len = %d; want 15, 5", full, s.Len())
+	}
+}
+
+func TestOpenLeavesWALOptionsAlone(t *testing.T) {
+	walOpts := make([]forge.Option, 0, 2)
+	walOpts = append(walOpts, forge.WithFsyncEvery(0))
+	s, err := Open(t.TempDir(), func(c *Config) { c.WALOptions = walOpts })
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	if walOpts[:2][1] != nil {
+		t.Error("Open appended into the caller's WALOptions")
+	}
+}
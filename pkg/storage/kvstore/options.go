@@ -0,0 +1,109 @@
+package kvstore
+
+import (
+	"time"
+
+	"github.com/huynhanx03/go-common/pkg/mq/forge"
+)
+
+// Durability selects when a write is acknowledged.
+type Durability uint8
+
+const (
+	// DurabilitySync appends and fsyncs the WAL on every write.
+	DurabilitySync Durability = iota
+
+	// DurabilityGroupCommit collects concurrent writes into one WAL batch and
+	// fsyncs once per batch. Each write still returns only after its batch is
+	// durable.
+	DurabilityGroupCommit
+)
+
+// File system layout.
+const (
+	dirPerm      = 0755
+	walDir       = "wal"
+	snapshotFile = "snapshot"
+	snapshotTmp  = "snapshot.tmp"
+)
+
+// Defaults.
+const (
+	DefaultShards            = 256
+	DefaultGroupCommitSize   = 256
+	DefaultGroupCommitLinger = time.Millisecond
+
+	maxRecordsPerBatch   = 65535   // forge RecordCount is uint16
+	snapshotBatchRecords = 4096    // records per encoded snapshot batch
+	replayReadBytes      = 4 << 20 // WAL bytes read per replay step
+	maxReplayReadBytes   = 1 << 30 // replay window cap for oversized batches
+	snapshotHeaderSize   = 8       // covered WAL offset, big-endian
+)
+
+// Config holds all configuration for the store.
+type Config struct {
+	Durability        Durability
+	GroupCommitSize   int           // max writes per WAL batch in group-commit mode
+	GroupCommitLinger time.Duration // max wait for a group-commit batch to fill
+	Shards            int           // shardedmap shard count
+	MaxKeys           int           // max keys held, 0 = unbounded
+	MaxBytes          int64         // max key plus value bytes held, 0 = unbounded
+	WALOptions        []forge.Option
+}
+
+// Option configures the store.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{
+		Durability:        DurabilitySync,
+		GroupCommitSize:   DefaultGroupCommitSize,
+		GroupCommitLinger: DefaultGroupCommitLinger,
+		Shards:            DefaultShards,
+	}
+}
+
+// WithDurability sets the write acknowledgement mode.
+func WithDurability(d Durability) Option {
+	return func(c *Config) { c.Durability = d }
+}
+
+// WithGroupCommit enables group commit with the given batch size and linger.
+func WithGroupCommit(size int, linger time.Duration) Option {
+	return func(c *Config) {
+		c.Durability = DurabilityGroupCommit
+		if size > 0 {
+			c.GroupCommitSize = min(size, maxRecordsPerBatch)
+		}
+		if linger > 0 {
+			c.GroupCommitLinger = linger
+		}
+	}
+}
+
+// WithShards sets the number of in-memory map shards.
+func WithShards(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.Shards = n
+		}
+	}
+}
+
+// WithMaxKeys bounds the number of keys. A Put adding a key beyond it fails
+// with ErrFull; overwrites and deletes are always accepted.
+func WithMaxKeys(n int) Option {
+	return func(c *Config) { c.MaxKeys = max(n, 0) }
+}
+
+// WithMaxBytes bounds the key plus value bytes held. A Put growing the total
+// beyond it fails with ErrFull; writes that shrink it are always accepted.
+func WithMaxBytes(n int64) Option {
+	return func(c *Config) { c.MaxBytes = max(n, 0) }
+}
+
+// WithWALOptions passes options through to the underlying forge.CommitLog.
+// Fsync is always forced per batch; WithFsyncEvery is overridden.
+func WithWALOptions(opts ...forge.Option) Option {
+	return func(c *Config) { c.WALOptions = append(c.WALOptions, opts...) }
+}
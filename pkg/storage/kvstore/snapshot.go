@@ -0,0 +1,156 @@
+package kvstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/huynhanx03/go-common/pkg/mq/forge"
+)
+
+// Snapshot file layout:
+//
+//	[covered WAL offset (8)] [forge batch] [forge batch] ...
+//
+// Each batch is encoded with forge.EncodeBatch, so every chunk of the
+// snapshot carries its own CRC32C.
+
+// Snapshot writes a point-in-time image of the store and truncates the WAL
+// segments it covers. Writes are blocked while the image is copied, not while
+// it is written to disk.
+func (s *Store) Snapshot() error {
+	s.mu.RLock()
+	closed := s.closed
+	s.mu.RUnlock()
+	if closed {
+		return ErrClosed
+	}
+
+	s.snapMu.Lock()
+	defer s.snapMu.Unlock()
+
+	s.commitMu.Lock()
+	covered := s.wal.NewestOffset()
+	recs := make([]forge.Record, 0, s.data.Len())
+	s.data.Do(func(k string, v []byte) {
+		recs = append(recs, forge.Record{Key: []byte(k), Value: v})
+	})
+	s.commitMu.Unlock()
+
+	if err := s.writeSnapshot(covered, recs); err != nil {
+		return err
+	}
+	return s.wal.DeleteBefore(covered)
+}
+
+// writeSnapshot persists recs using write-to-temp + fsync + rename.
+func (s *Store) writeSnapshot(covered uint64, recs []forge.Record) error {
+	tmp := filepath.Join(s.dir, snapshotTmp)
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("kvstore: create snapshot: %w", err)
+	}
+
+	if err := encodeSnapshot(f, covered, recs); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	f.Close()
+	if err := os.Rename(tmp, filepath.Join(s.dir, snapshotFile)); err != nil {
+		return err
+	}
+	return syncDir(s.dir)
+}
+
+// syncDir fsyncs dir so that a rename into it survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("kvstore: sync %s: %w", dir, err)
+	}
+	return nil
+}
+
+func encodeSnapshot(f *os.File, covered uint64, recs []forge.Record) error {
+	w := bufio.NewWriter(f)
+
+	var header [snapshotHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:], covered)
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+
+	var dst []byte
+	for len(recs) > 0 {
+		n := min(len(recs), snapshotBatchRecords)
+		chunk := recs[:n]
+		for i := range chunk {
+			chunk[i].OffsetDelta = int64(i)
+		}
+
+		var err error
+		dst, err = forge.EncodeBatch(&forge.RecordBatch{
+			BaseOffset:  covered,
+			RecordCount: uint16(n),
+			Records:     chunk,
+		}, dst[:0])
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(dst); err != nil {
+			return err
+		}
+		recs = recs[n:]
+	}
+	return w.Flush()
+}
+
+// loadSnapshot restores the map from the snapshot file, if any, and returns
+// the WAL offset from which replay must continue.
+func (s *Store) loadSnapshot() (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, snapshotFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if len(data) < snapshotHeaderSize {
+		return 0, ErrCorruptSnapshot
+	}
+
+	covered := binary.BigEndian.Uint64(data)
+	for off := snapshotHeaderSize; off < len(data); {
+		size, err := forge.BatchSize(data[off:])
+		if err != nil {
+			return 0, ErrCorruptSnapshot
+		}
+		batch, err := forge.DecodeBatch(data[off : off+size])
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+		}
+		for i := range batch.Records {
+			rec := &batch.Records[i]
+			rec.Value = bytes.Clone(rec.Value)
+			s.apply(rec)
+		}
+		off += size
+	}
+	return covered, nil
+}
@@ -0,0 +1,223 @@
+// Package iotest provides faulty io.Readers and io.Writers for tests: errors
+// after a byte budget, short reads and writes, latency, and the broken
+// results (negative counts, no progress) that readers are supposed to
+// guard against. It complements the standard testing/iotest, whose helpers
+// cover fewer failure modes and are not configurable.
+package iotest
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrInjected is the default error returned by the faulty readers and
+// writers.
+var ErrInjected = errors.New("iotest: injected error")
+
+// Config describes the faults injected by a Reader or Writer.
+type Config struct {
+	// MaxChunk caps the bytes moved per call, producing partial reads and
+	// short writes. 0 means no cap.
+	MaxChunk int
+
+	// FailAfter is the number of bytes that pass before every call fails
+	// with Err. Negative means never.
+	FailAfter int64
+
+	// Err is the injected error; ErrInjected if nil.
+	Err error
+
+	// ShortWriteErr is returned by a Writer alongside a write shortened by
+	// MaxChunk. nil mimics a writer that breaks the io.Writer contract by
+	// reporting a short write without an error.
+	ShortWriteErr error
+
+	// Latency is slept at the start of every call.
+	Latency time.Duration
+}
+
+// Option configures a Reader or Writer.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{FailAfter: -1}
+}
+
+// WithMaxChunk sets Config.MaxChunk. Non-positive values are ignored.
+func WithMaxChunk(n int) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.MaxChunk = n
+		}
+	}
+}
+
+// WithFailAfter sets Config.FailAfter and Config.Err.
+func WithFailAfter(n int64, err error) Option {
+	return func(c *Config) {
+		c.FailAfter = n
+		c.Err = err
+	}
+}
+
+// WithShortWriteErr sets Config.ShortWriteErr, e.g. io.ErrShortWrite.
+func WithShortWriteErr(err error) Option {
+	return func(c *Config) {
+		c.ShortWriteErr = err
+	}
+}
+
+// WithLatency sets Config.Latency. Non-positive values are ignored.
+func WithLatency(d time.Duration) Option {
+	return func(c *Config) {
+		if d > 0 {
+			c.Latency = d
+		}
+	}
+}
+
+// fault tracks a Config's budget across calls.
+type fault struct {
+	cfg  Config
+	n    int64 // bytes passed so far
+	hits int   // calls so far
+}
+
+func newFault(opts []Option) fault {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.Err == nil {
+		cfg.Err = ErrInjected
+	}
+	return fault{cfg: cfg}
+}
+
+// limit starts a call: it sleeps, then returns how many of want bytes may
+// pass, or the injected error once the budget is spent.
+func (f *fault) limit(want int) (int, error) {
+	f.hits++
+	if f.cfg.Latency > 0 {
+		time.Sleep(f.cfg.Latency)
+	}
+	if f.cfg.FailAfter >= 0 {
+		left := f.cfg.FailAfter - f.n
+		if left <= 0 && want > 0 {
+			return 0, f.cfg.Err
+		}
+		want = int(min(int64(want), left))
+	}
+	if f.cfg.MaxChunk > 0 {
+		want = min(want, f.cfg.MaxChunk)
+	}
+	return want, nil
+}
+
+// Reader wraps an io.Reader with the faults of its Config.
+// It is not safe for concurrent use.
+type Reader struct {
+	r io.Reader
+	f fault
+}
+
+// NewReader returns a Reader over r. A nil r reads zeros endlessly.
+func NewReader(r io.Reader, opts ...Option) *Reader {
+	if r == nil {
+		r = zeros{}
+	}
+	return &Reader{r: r, f: newFault(opts)}
+}
+
+// Read implements io.Reader.
+func (r *Reader) Read(p []byte) (int, error) {
+	n, err := r.f.limit(len(p))
+	if err != nil {
+		return 0, err
+	}
+	n, err = r.r.Read(p[:n])
+	r.f.n += int64(n)
+	return n, err
+}
+
+// N returns the number of bytes read so far.
+func (r *Reader) N() int64 { return r.f.n }
+
+// Calls returns the number of Read calls so far.
+func (r *Reader) Calls() int { return r.f.hits }
+
+// Writer wraps an io.Writer with the faults of its Config.
+// It is not safe for concurrent use.
+type Writer struct {
+	w io.Writer
+	f fault
+}
+
+// NewWriter returns a Writer over w. A nil w discards.
+func NewWriter(w io.Writer, opts ...Option) *Writer {
+	if w == nil {
+		w = io.Discard
+	}
+	return &Writer{w: w, f: newFault(opts)}
+}
+
+// Write implements io.Writer. A write cut by FailAfter returns the bytes
+// that fit with the injected error; one cut by MaxChunk returns them with
+// Config.ShortWriteErr.
+func (w *Writer) Write(p []byte) (int, error) {
+	n, err := w.f.limit(len(p))
+	if err != nil {
+		return 0, err
+	}
+	n, err = w.w.Write(p[:n])
+	w.f.n += int64(n)
+	if err != nil || n == len(p) {
+		return n, err
+	}
+	if w.f.cfg.FailAfter >= 0 && w.f.n >= w.f.cfg.FailAfter {
+		return n, w.f.cfg.Err
+	}
+	return n, w.f.cfg.ShortWriteErr
+}
+
+// N returns the number of bytes written so far.
+func (w *Writer) N() int64 { return w.f.n }
+
+// Calls returns the number of Write calls so far.
+func (w *Writer) Calls() int { return w.f.hits }
+
+// ErrReader returns a reader whose every Read fails with err, or
+// ErrInjected if err is nil.
+func ErrReader(err error) io.Reader {
+	return NewReader(nil, WithFailAfter(0, err))
+}
+
+// ErrWriter returns a writer whose every Write fails with err, or
+// ErrInjected if err is nil.
+func ErrWriter(err error) io.Writer {
+	return NewWriter(nil, WithFailAfter(0, err))
+}
+
+// NegativeReader returns a reader that reports a negative count, which
+// io.Reader forbids; callers are expected to panic or fail.
+func NegativeReader() io.Reader { return negative{} }
+
+// NoProgressReader returns a reader whose every Read returns 0, nil,
+// the case io.ErrNoProgress exists for.
+func NoProgressReader() io.Reader { return noProgress{} }
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+type negative struct{}
+
+func (negative) Read([]byte) (int, error) { return -1, nil }
+
+type noProgress struct{}
+
+func (noProgress) Read([]byte) (int, error) { return 0, nil }
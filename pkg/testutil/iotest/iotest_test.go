@@ -0,0 +1,113 @@
+package iotest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errBoom = errors.New("boom")
+
+// =============================================================================
+// Reader Tests
+// =============================================================================
+
+func TestReader_MaxChunk(t *testing.T) {
+	r := NewReader(strings.NewReader("hello world"), WithMaxChunk(4))
+
+	buf := make([]byte, 16)
+	n, err := r.Read(buf)
+	if n != 4 || err != nil {
+		t.Fatalf("Read = %d, %v; want 4, nil", n, err)
+	}
+
+	rest, err := io.ReadAll(r)
+	if err != nil || string(buf[:n])+string(rest) != "hello world" {
+		t.Fatalf("ReadAll = %q, %v", rest, err)
+	}
+	if r.N() != 11 || r.Calls() < 4 {
+		t.Errorf("N, Calls = %d, %d", r.N(), r.Calls())
+	}
+}
+
+func TestReader_FailAfter(t *testing.T) {
+	r := NewReader(strings.NewReader("hello world"), WithFailAfter(5, errBoom))
+
+	got, err := io.ReadAll(r)
+	if !errors.Is(err, errBoom) || string(got) != "hello" {
+		t.Fatalf("ReadAll = %q, %v; want hello, boom", got, err)
+	}
+	if _, err := r.Read(make([]byte, 1)); !errors.Is(err, errBoom) {
+		t.Errorf("Read after failure = %v, want boom", err)
+	}
+}
+
+func TestReader_NilSourceAndLatency(t *testing.T) {
+	r := NewReader(nil, WithLatency(10*time.Millisecond))
+
+	buf := []byte{1, 2, 3}
+	start := time.Now()
+	if n, err := r.Read(buf); n != 3 || err != nil || !bytes.Equal(buf, []byte{0, 0, 0}) {
+		t.Fatalf("Read = %d, %v, %v", n, err, buf)
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Error("Read did not wait for the latency")
+	}
+}
+
+func TestErrNegativeNoProgressReaders(t *testing.T) {
+	if _, err := ErrReader(nil).Read(make([]byte, 1)); !errors.Is(err, ErrInjected) {
+		t.Errorf("ErrReader(nil) = %v, want ErrInjected", err)
+	}
+	if n, _ := NegativeReader().Read(make([]byte, 1)); n >= 0 {
+		t.Errorf("NegativeReader n = %d", n)
+	}
+	if n, err := NoProgressReader().Read(make([]byte, 1)); n != 0 || err != nil {
+		t.Errorf("NoProgressReader = %d, %v", n, err)
+	}
+}
+
+// =============================================================================
+// Writer Tests
+// =============================================================================
+
+func TestWriter_ShortWrites(t *testing.T) {
+	var dst bytes.Buffer
+	w := NewWriter(&dst, WithMaxChunk(3))
+	if n, err := w.Write([]byte("hello")); n != 3 || err != nil {
+		t.Fatalf("Write = %d, %v; want 3, nil", n, err)
+	}
+
+	w = NewWriter(&dst, WithMaxChunk(3), WithShortWriteErr(io.ErrShortWrite))
+	if n, err := w.Write([]byte("hello")); n != 3 || !errors.Is(err, io.ErrShortWrite) {
+		t.Fatalf("Write = %d, %v; want 3, ErrShortWrite", n, err)
+	}
+	if dst.String() != "helhel" {
+		t.Errorf("dst = %q", dst.String())
+	}
+}
+
+func TestWriter_FailAfter(t *testing.T) {
+	var dst bytes.Buffer
+	w := NewWriter(&dst, WithFailAfter(4, nil))
+
+	if n, err := w.Write([]byte("ab")); n != 2 || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if n, err := w.Write([]byte("cdef")); n != 2 || !errors.Is(err, ErrInjected) {
+		t.Fatalf("Write = %d, %v; want 2, ErrInjected", n, err)
+	}
+	if _, err := w.Write([]byte("g")); !errors.Is(err, ErrInjected) {
+		t.Errorf("Write after failure = %v", err)
+	}
+	if dst.String() != "abcd" || w.N() != 4 || w.Calls() != 3 {
+		t.Errorf("dst, N, Calls = %q, %d, %d", dst.String(), w.N(), w.Calls())
+	}
+
+	if _, err := ErrWriter(errBoom).Write([]byte("x")); !errors.Is(err, errBoom) {
+		t.Errorf("ErrWriter = %v, want boom", err)
+	}
+}
@@ -0,0 +1,60 @@
+package timer
+
+import (
+	"sync"
+	"time"
+)
+
+// shared holds the CachedTimers handed out by Shared, one per step, with
+// the number of handles still using each.
+var shared = struct {
+	sync.Mutex
+	timers map[time.Duration]*sharedEntry
+}{timers: make(map[time.Duration]*sharedEntry)}
+
+type sharedEntry struct {
+	timer *CachedTimer
+	refs  int
+}
+
+// SharedTimer is a handle on a CachedTimer shared by every caller of Shared
+// with the same step.
+type SharedTimer struct {
+	entry *sharedEntry
+	step  time.Duration
+	once  sync.Once
+}
+
+// Shared returns a handle on the process-wide CachedTimer refreshed every
+// step, starting it on first use. However many components ask for the same
+// step, one goroutine keeps the time, so e.g. every cache instance of a
+// process reads the clock from one atomic. Stop releases the handle; the
+// timer stops with the last one and a later Shared starts a new one.
+func Shared(step time.Duration) *SharedTimer {
+	shared.Lock()
+	defer shared.Unlock()
+	e := shared.timers[step]
+	if e == nil {
+		e = &sharedEntry{timer: NewCachedTimer(step)}
+		shared.timers[step] = e
+	}
+	e.refs++
+	return &SharedTimer{entry: e, step: step}
+}
+
+// Now returns the shared cached time as unix nanoseconds.
+func (t *SharedTimer) Now() int64 {
+	return t.entry.timer.Now()
+}
+
+// Stop releases the handle. Calling it more than once is a no-op.
+func (t *SharedTimer) Stop() {
+	t.once.Do(func() {
+		shared.Lock()
+		defer shared.Unlock()
+		if t.entry.refs--; t.entry.refs == 0 {
+			delete(shared.timers, t.step)
+			t.entry.timer.Stop()
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+// sharedRefs returns the handles on the shared timer for step, 0 if there
+// is none.
+func sharedRefs(step time.Duration) int {
+	shared.Lock()
+	defer shared.Unlock()
+	if e := shared.timers[step]; e != nil {
+		return e.refs
+	}
+	return 0
+}
+
+// stopped reports whether ct's refresh goroutine was told to exit.
+func stopped(ct *CachedTimer) bool {
+	select {
+	case <-ct.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestShared_SameStepShares(t *testing.T) {
+	const step = 3 * time.Millisecond
+	a, b := Shared(step), Shared(step)
+	defer a.Stop()
+	defer b.Stop()
+
+	if a.entry.timer != b.entry.timer {
+		t.Error("handles with the same step got different timers")
+	}
+	if n := sharedRefs(step); n != 2 {
+		t.Errorf("refs = %d, want 2", n)
+	}
+	if d := time.Since(time.Unix(0, a.Now())); d < 0 || d > time.Second {
+		t.Errorf("Now is %v away from the wall clock", d)
+	}
+
+	other := Shared(step + time.Millisecond)
+	defer other.Stop()
+	if other.entry.timer == a.entry.timer {
+		t.Error("handles with different steps share a timer")
+	}
+}
+
+func TestShared_LastStopStops(t *testing.T) {
+	const step = 5 * time.Millisecond
+	a, b := Shared(step), Shared(step)
+	ct := a.entry.timer
+
+	a.Stop()
+	a.Stop() // releases only once
+	if n := sharedRefs(step); n != 1 {
+		t.Fatalf("refs = %d after releasing one of two handles twice, want 1", n)
+	}
+	if stopped(ct) {
+		t.Fatal("timer stopped while a handle still uses it")
+	}
+
+	b.Stop()
+	if n := sharedRefs(step); n != 0 {
+		t.Errorf("refs = %d after the last release, want 0", n)
+	}
+	if !stopped(ct) {
+		t.Error("timer still running after the last release")
+	}
+
+	// A later Shared starts a fresh timer.
+	c := Shared(step)
+	defer c.Stop()
+	if c.entry.timer == ct || stopped(c.entry.timer) {
+		t.Error("Shared after the last release reused the stopped timer")
+	}
+}
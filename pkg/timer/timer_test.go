@@ -0,0 +1,44 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCachedTimer_Refreshes(t *testing.T) {
+	ct := NewCachedTimer(time.Millisecond)
+	defer ct.Stop()
+
+	first := ct.Now()
+	if d := time.Since(time.Unix(0, first)); d < 0 || d > time.Second {
+		t.Fatalf("Now is %v away from the wall clock", d)
+	}
+	deadline := time.Now().Add(time.Second)
+	for ct.Now() == first {
+		if time.Now().After(deadline) {
+			t.Fatal("cached time never refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCachedTimer_StopHaltsRefresh(t *testing.T) {
+	ct := NewCachedTimer(time.Millisecond)
+	ct.Stop()
+
+	frozen := ct.Now()
+	time.Sleep(5 * time.Millisecond)
+	if ct.Now() != frozen {
+		t.Error("cached time changed after Stop")
+	}
+}
+
+func TestSystemTimer(t *testing.T) {
+	var st Timer = SystemTimer{}
+	before := time.Now().UnixNano()
+	now := st.Now()
+	if now < before || now > time.Now().UnixNano() {
+		t.Errorf("Now = %d, want between %d and the current time", now, before)
+	}
+	st.Stop() // no-op
+}
@@ -0,0 +1,65 @@
+// Package bytesx provides byte scanning helpers for the buffer types.
+//
+// IndexByte and CountByte use the standard library's per-architecture SIMD
+// kernels (internal/bytealg) and add the two-segment forms ring buffers need
+// across their wrap point. EqualFoldASCII compares a word at a time on 64-bit
+// little-endian targets and falls back to a byte loop elsewhere.
+package bytesx
+
+import "bytes"
+
+// IndexByte returns the index of the first c in b, or -1.
+func IndexByte(b []byte, c byte) int {
+	return bytes.IndexByte(b, c)
+}
+
+// IndexByte2 returns the index of the first c in head followed by tail,
+// as if they were one slice, or -1. It scans a ring buffer across its wrap
+// point without copying.
+func IndexByte2(head, tail []byte, c byte) int {
+	if i := bytes.IndexByte(head, c); i >= 0 {
+		return i
+	}
+	if i := bytes.IndexByte(tail, c); i >= 0 {
+		return len(head) + i
+	}
+	return -1
+}
+
+// CountByte returns the number of c in b.
+func CountByte(b []byte, c byte) int {
+	return bytes.Count(b, []byte{c})
+}
+
+// CountByte2 returns the number of c in head and tail.
+func CountByte2(head, tail []byte, c byte) int {
+	return CountByte(head, c) + CountByte(tail, c)
+}
+
+// EqualFoldASCII reports whether a and b are equal under ASCII case folding.
+// Unlike bytes.EqualFold, bytes >= 0x80 are compared exactly, which is what
+// protocol tokens (HTTP header names, SMTP verbs) call for.
+func EqualFoldASCII(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return equalFoldASCII(a, b)
+}
+
+// lowerASCII folds one byte.
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// equalFoldBytes is the byte-at-a-time comparison of equal-length slices.
+func equalFoldBytes(a, b []byte) bool {
+	for i := range a {
+		if a[i] != b[i] && lowerASCII(a[i]) != lowerASCII(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package bytesx
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+)
+
+// =============================================================================
+// IndexByte / IndexByte2
+// =============================================================================
+
+func TestIndexByte2(t *testing.T) {
+	tests := []struct {
+		name       string
+		head, tail string
+		c          byte
+		want       int
+	}{
+		{"in_head", "abc\n", "def", '\n', 3},
+		{"in_tail", "abc", "de\nf", '\n', 5},
+		{"first_wins", "a\n", "\n", '\n', 1},
+		{"absent", "abc", "def", '\n', -1},
+		{"empty_head", "", "x\n", '\n', 1},
+		{"both_empty", "", "", 'x', -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IndexByte2([]byte(tt.head), []byte(tt.tail), tt.c); got != tt.want {
+				t.Errorf("IndexByte2(%q, %q) = %d, want %d", tt.head, tt.tail, got, tt.want)
+			}
+			if got := IndexByte([]byte(tt.head+tt.tail), tt.c); got != tt.want {
+				t.Errorf("IndexByte(%q) = %d, want %d", tt.head+tt.tail, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountByte(t *testing.T) {
+	if got := CountByte([]byte("a,b,,c"), ','); got != 3 {
+		t.Errorf("CountByte = %d, want 3", got)
+	}
+	if got := CountByte2([]byte("a,b"), []byte(",,c"), ','); got != 3 {
+		t.Errorf("CountByte2 = %d, want 3", got)
+	}
+}
+
+// =============================================================================
+// EqualFoldASCII
+// =============================================================================
+
+func TestEqualFoldASCII(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "", true},
+		{"Content-Type", "content-type", true},
+		{"CONTENT-LENGTH-AND-MORE", "content-length-and-more", true},
+		{"Content-Type", "content-typf", false},
+		{"abc", "abcd", false},
+		{"@[`{", "`{@[", false}, // neighbours of the letter ranges do not fold
+		{"Ä", "ä", false},       // non-ASCII compared exactly
+		{"\xc0ABCDEFGH", "\xc0abcdefgh", true},
+		{"\xc1", "\xe1", false}, // high bytes whose low 7 bits look like letters
+	}
+	for _, tt := range tests {
+		if got := EqualFoldASCII([]byte(tt.a), []byte(tt.b)); got != tt.want {
+			t.Errorf("EqualFoldASCII(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestEqualFoldASCII_MatchesByteLoop(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	for range 20000 {
+		a := make([]byte, rng.IntN(40))
+		for i := range a {
+			a[i] = byte(rng.IntN(256))
+		}
+		b := bytes.Clone(a)
+		for i := range b {
+			switch rng.IntN(4) {
+			case 0:
+				b[i] ^= 0x20 // flip case bit
+			case 1:
+				b[i] = byte(rng.IntN(256))
+			}
+		}
+		if got, want := EqualFoldASCII(a, b), equalFoldBytes(a, b); got != want {
+			t.Fatalf("EqualFoldASCII(%q, %q) = %v, byte loop says %v", a, b, got, want)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !(amd64 || arm64 || ppc64le || riscv64 || loong64)
+
+package bytesx
+
+// equalFoldASCII is the portable fallback.
+func equalFoldASCII(a, b []byte) bool {
+	return equalFoldBytes(a, b)
+}
@@ -0,0 +1,35 @@
+//go:build amd64 || arm64 || ppc64le || riscv64 || loong64
+
+package bytesx
+
+import "encoding/binary"
+
+const (
+	lo7  = 0x7f7f7f7f7f7f7f7f
+	hi1  = 0x8080808080808080
+	geA  = 0x3f3f3f3f3f3f3f3f // 0x80 - 'A': sets bit 7 where low7 >= 'A'
+	gtZ  = 0x2525252525252525 // 0x80 - ('Z'+1): sets bit 7 where low7 > 'Z'
+	word = 8
+)
+
+// lowerWord folds the ASCII upper-case letters of eight bytes at once.
+// The additions are on 7-bit lanes, so no carry crosses a byte.
+func lowerWord(x uint64) uint64 {
+	low := x & lo7
+	upper := (low + geA) &^ (low + gtZ) &^ x & hi1
+	return x | upper>>2 // 0x80 >> 2 == 0x20, the case bit
+}
+
+// equalFoldASCII compares eight bytes per step. The loads compile to single
+// unaligned moves on these targets.
+func equalFoldASCII(a, b []byte) bool {
+	i := 0
+	for ; i+word <= len(a); i += word {
+		x := binary.LittleEndian.Uint64(a[i:])
+		y := binary.LittleEndian.Uint64(b[i:])
+		if x != y && lowerWord(x) != lowerWord(y) {
+			return false
+		}
+	}
+	return equalFoldBytes(a[i:], b[i:])
+}
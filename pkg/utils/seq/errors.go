@@ -0,0 +1,15 @@
+package seq
+
+import "errors"
+
+var (
+	// ErrExhausted is returned once the sequence would pass MaxUint64.
+	ErrExhausted = errors.New("seq: sequence exhausted")
+
+	// ErrPersist wraps the error of a failed checkpoint. Nothing is handed
+	// out from the block it tried to reserve.
+	ErrPersist = errors.New("seq: checkpoint failed")
+
+	// ErrInvalidCount is returned by NextN for n == 0.
+	ErrInvalidCount = errors.New("seq: count must be positive")
+)
@@ -0,0 +1,27 @@
+package seq
+
+// DefaultBlockSize is how many values a checkpoint reserves by default.
+const DefaultBlockSize = 1024
+
+// Config holds the allocator settings.
+type Config struct {
+	// BlockSize is how many values each checkpoint reserves. Larger blocks
+	// checkpoint less often and leave a larger gap after a restart.
+	BlockSize uint64
+}
+
+// Option configures a Sequence.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{BlockSize: DefaultBlockSize}
+}
+
+// WithBlockSize sets Config.BlockSize. Zero is ignored.
+func WithBlockSize(n uint64) Option {
+	return func(c *Config) {
+		if n > 0 {
+			c.BlockSize = n
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package seq allocates monotonic uint64 sequence numbers that survive
+// restarts without reuse, without a clock or node ID.
+//
+// A Sequence reserves values in blocks: before handing out the first value
+// of a block it checkpoints the block's end, the high-water mark, through a
+// callback that persists it (to a WAL record, a kvstore key, a file).
+// Every value handed out is below the last checkpoint, so a process that
+// restarts from that checkpoint never repeats one; it skips what was left
+// of the block, leaving a gap of at most BlockSize values.
+package seq
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// PersistFunc checkpoints the high-water mark: every value below it may
+// have been handed out. It must return only once the mark is durable, and
+// the value to restart from is the last one it accepted.
+type PersistFunc func(highWater uint64) error
+
+// Sequence is a block-allocated monotonic sequence.
+// It is safe for concurrent use.
+type Sequence struct {
+	persist PersistFunc
+	config  Config
+
+	mu    sync.Mutex
+	next  uint64 // next value to hand out
+	limit uint64 // last checkpoint; values below it are reserved
+}
+
+// New creates a sequence whose first value is start, typically the
+// high-water mark last passed to persist, or 0 on first use. persist may be
+// nil for a sequence that does not need to survive restarts.
+func New(start uint64, persist PersistFunc, opts ...Option) *Sequence {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &Sequence{persist: persist, config: cfg, next: start, limit: start}
+}
+
+// Next returns the next value, checkpointing a new block first when the
+// current one is used up.
+func (s *Sequence) Next() (uint64, error) {
+	return s.NextN(1)
+}
+
+// NextN reserves n consecutive values and returns the first; the range is
+// [first, first+n). A range larger than the remainder of the block extends
+// the checkpoint to cover it, so it is never split.
+func (s *Sequence) NextN(n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, ErrInvalidCount
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n > math.MaxUint64-s.next {
+		return 0, ErrExhausted
+	}
+	first := s.next
+	if first+n > s.limit {
+		if err := s.reserve(first + n); err != nil {
+			return 0, err
+		}
+	}
+	s.next = first + n
+	return first, nil
+}
+
+// reserve checkpoints a new limit of at least need, rounded up to whole
+// blocks. Caller holds s.mu.
+func (s *Sequence) reserve(need uint64) error {
+	bs := s.config.BlockSize
+	limit := uint64(math.MaxUint64) // the tail of the space is one short block
+	if blocks := (need-s.limit-1)/bs + 1; blocks <= (math.MaxUint64-s.limit)/bs {
+		limit = s.limit + blocks*bs
+	}
+
+	if s.persist != nil {
+		if err := s.persist(limit); err != nil {
+			return fmt.Errorf("%w: %w", ErrPersist, err)
+		}
+	}
+	s.limit = limit
+	return nil
+}
+
+// HighWater returns the last checkpointed high-water mark.
+func (s *Sequence) HighWater() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limit
+}
+
+// Peek returns the value the next call to Next would return, without
+// reserving it.
+func (s *Sequence) Peek() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next
+}
@@ -0,0 +1,157 @@
+package seq
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// store records checkpoints like a durable key would.
+type store struct {
+	mu     sync.Mutex
+	marks  []uint64
+	failOn int // fail the nth checkpoint (1-based), 0 = never
+}
+
+func (s *store) persist(hw uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failOn == len(s.marks)+1 {
+		s.failOn = 0
+		return errors.New("disk full")
+	}
+	s.marks = append(s.marks, hw)
+	return nil
+}
+
+func (s *store) last() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.marks) == 0 {
+		return 0
+	}
+	return s.marks[len(s.marks)-1]
+}
+
+// =============================================================================
+// Next / NextN Tests
+// =============================================================================
+
+func TestNext_Blocks(t *testing.T) {
+	st := &store{}
+	s := New(1, st.persist, WithBlockSize(10))
+
+	for want := uint64(1); want <= 25; want++ {
+		got, err := s.Next()
+		if err != nil || got != want {
+			t.Fatalf("Next() = (%d, %v), want (%d, nil)", got, err, want)
+		}
+	}
+	if want := []uint64{11, 21, 31}; !slices.Equal(st.marks, want) {
+		t.Errorf("checkpoints = %v, want %v", st.marks, want)
+	}
+	if s.HighWater() != 31 || s.Peek() != 26 {
+		t.Errorf("HighWater() = %d, Peek() = %d; want 31, 26", s.HighWater(), s.Peek())
+	}
+}
+
+func TestNextN(t *testing.T) {
+	st := &store{}
+	s := New(0, st.persist, WithBlockSize(10))
+
+	first, err := s.NextN(4)
+	if err != nil || first != 0 {
+		t.Fatalf("NextN(4) = (%d, %v), want (0, nil)", first, err)
+	}
+	// 6 left in the block; 25 more extends the checkpoint by whole blocks.
+	first, err = s.NextN(25)
+	if err != nil || first != 4 {
+		t.Fatalf("NextN(25) = (%d, %v), want (4, nil)", first, err)
+	}
+	if hw := s.HighWater(); hw != 30 {
+		t.Errorf("HighWater() = %d, want 30", hw)
+	}
+	if _, err := s.NextN(0); !errors.Is(err, ErrInvalidCount) {
+		t.Errorf("NextN(0) err = %v, want ErrInvalidCount", err)
+	}
+}
+
+func TestNext_Restart(t *testing.T) {
+	st := &store{}
+	s := New(0, st.persist, WithBlockSize(100))
+	var last uint64
+	for range 150 {
+		last, _ = s.Next()
+	}
+
+	// Crash: restart from the last checkpoint.
+	s = New(st.last(), st.persist, WithBlockSize(100))
+	got, err := s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got <= last {
+		t.Errorf("after restart Next() = %d, reuses a value <= %d", got, last)
+	}
+}
+
+func TestNext_PersistError(t *testing.T) {
+	st := &store{failOn: 2}
+	s := New(0, st.persist, WithBlockSize(2))
+	s.Next()
+	s.Next()
+
+	if _, err := s.Next(); !errors.Is(err, ErrPersist) {
+		t.Fatalf("Next() err = %v, want ErrPersist", err)
+	}
+	// Nothing was handed out from the failed block; the retry succeeds.
+	got, err := s.Next()
+	if err != nil || got != 2 {
+		t.Errorf("Next() after failure = (%d, %v), want (2, nil)", got, err)
+	}
+}
+
+func TestNext_Exhausted(t *testing.T) {
+	s := New(math.MaxUint64-3, nil, WithBlockSize(10))
+	first, err := s.NextN(3)
+	if err != nil || first != math.MaxUint64-3 {
+		t.Fatalf("NextN(3) = (%d, %v)", first, err)
+	}
+	if s.HighWater() != math.MaxUint64 {
+		t.Errorf("HighWater() = %d, want MaxUint64", s.HighWater())
+	}
+	if _, err := s.NextN(1); !errors.Is(err, ErrExhausted) {
+		t.Errorf("NextN past the end err = %v, want ErrExhausted", err)
+	}
+}
+
+func TestNext_Concurrent(t *testing.T) {
+	s := New(0, (&store{}).persist, WithBlockSize(7))
+
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 500 {
+				v, err := s.Next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[v] {
+					t.Errorf("value %d handed out twice", v)
+				}
+				seen[v] = true
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	if len(seen) != 4000 {
+		t.Errorf("handed out %d values, want 4000", len(seen))
+	}
+}
@@ -0,0 +1,287 @@
+// Package sizeof estimates how much memory a value holds: its own bytes
+// plus everything reachable from it through pointers, slices, strings, maps
+// and interfaces. DeepSize has the shape of a cache cost function, so
+//
+//	ristretto.WithCost(sizeof.DeepSize)
+//
+// charges entries by their footprint rather than one unit each.
+//
+// Sizes are estimates: allocator size classes and padding are not counted,
+// map storage is modelled rather than measured, and values a channel
+// buffers are not walked.
+package sizeof
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+const (
+	// mapHeader approximates a map's header and directory.
+	mapHeader = 48
+	// mapGroupSlots is the number of slots in a map group, each group
+	// carrying one 8-byte control word.
+	mapGroupSlots = 8
+	// chanHeader approximates the runtime's channel header.
+	chanHeader = 96
+)
+
+var (
+	registry   sync.Map // reflect.Type -> func(any) int64
+	registered atomic.Bool
+
+	pointerFree sync.Map // reflect.Type -> bool
+
+	timeType = reflect.TypeFor[time.Time]()
+)
+
+// Register makes DeepSize price values of type T with fn instead of
+// walking them, wherever they appear in a graph. fn returns the full size
+// of the value, its own bytes included, as DeepSize would. Registering
+// the hot types of a cache skips reflection for them entirely.
+// Registering T again replaces fn. The built-in types DeepSize prices
+// without reflection, such as string and []byte, cannot be overridden at
+// the top level.
+func Register[T any](fn func(T) int64) {
+	registry.Store(reflect.TypeFor[T](), func(v any) int64 { return fn(v.(T)) })
+	registered.Store(true)
+	pointerFree.Clear() // composite types holding T now need walking
+}
+
+// lookup returns the function registered for t.
+func lookup(t reflect.Type) (func(any) int64, bool) {
+	if !registered.Load() {
+		return nil, false
+	}
+	fn, ok := registry.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return fn.(func(any) int64), true
+}
+
+// DeepSize returns the estimated bytes held by v: the size of its dynamic
+// value and of everything reachable from it. Memory reachable twice, such
+// as a pointer cycle or two slices of one array, is counted once. A nil v
+// has size 0.
+func DeepSize(v any) int64 {
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(unsafe.Sizeof(x)) + int64(len(x))
+	case []byte:
+		return int64(unsafe.Sizeof(x)) + int64(cap(x))
+	case bool, int8, uint8:
+		return 1
+	case int16, uint16:
+		return 2
+	case int32, uint32, float32:
+		return 4
+	case int, uint, int64, uint64, uintptr, float64, complex64:
+		return 8
+	case complex128:
+		return 16
+	case time.Time:
+		return int64(unsafe.Sizeof(x))
+	case []string:
+		n := int64(unsafe.Sizeof(x)) + int64(cap(x))*int64(unsafe.Sizeof(""))
+		for _, s := range x {
+			n += int64(len(s))
+		}
+		return n
+	}
+
+	t := reflect.TypeOf(v)
+	if fn, ok := lookup(t); ok {
+		return fn(v)
+	}
+	var w walker
+	return int64(t.Size()) + w.indirect(reflect.ValueOf(v))
+}
+
+// visit identifies a block of memory already counted.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// walker sums the memory a value references. It is used for one DeepSize
+// call.
+type walker struct {
+	seen map[visit]struct{}
+}
+
+// first records ptr as counted and reports whether it was not already.
+func (w *walker) first(ptr uintptr, t reflect.Type) bool {
+	if w.seen == nil {
+		w.seen = make(map[visit]struct{})
+	}
+	k := visit{ptr, t}
+	if _, ok := w.seen[k]; ok {
+		return false
+	}
+	w.seen[k] = struct{}{}
+	return true
+}
+
+// indirect returns the bytes v references, excluding its own inline bytes,
+// which the caller has counted.
+func (w *walker) indirect(v reflect.Value) int64 {
+	t := v.Type()
+	if fn, ok := lookup(t); ok {
+		if x, ok := valueOf(v); ok {
+			return fn(x) - int64(t.Size())
+		}
+	}
+	if t == timeType {
+		return 0 // its Location is shared, not owned
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 || !w.first(uintptr(unsafe.Pointer(unsafe.StringData(v.String()))), t) {
+			return 0
+		}
+		return int64(v.Len())
+
+	case reflect.Pointer:
+		if v.IsNil() || !w.first(v.Pointer(), t) {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + w.indirect(elem)
+
+	case reflect.Slice:
+		if v.IsNil() || !w.first(v.Pointer(), t) {
+			return 0
+		}
+		elemType := t.Elem()
+		n := int64(v.Cap()) * int64(elemType.Size())
+		if !isPointerFree(elemType) {
+			for i := range v.Len() {
+				n += w.indirect(v.Index(i))
+			}
+		}
+		return n
+
+	case reflect.Array:
+		if isPointerFree(t) {
+			return 0
+		}
+		var n int64
+		for i := range v.Len() {
+			n += w.indirect(v.Index(i))
+		}
+		return n
+
+	case reflect.Struct:
+		if isPointerFree(t) {
+			return 0
+		}
+		var n int64
+		for i := range v.NumField() {
+			n += w.indirect(v.Field(i))
+		}
+		return n
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		if isPointerShaped(elem.Kind()) {
+			return w.indirect(elem)
+		}
+		// Other dynamic values are boxed on the heap.
+		return int64(elem.Type().Size()) + w.indirect(elem)
+
+	case reflect.Map:
+		if v.IsNil() || !w.first(v.Pointer(), t) {
+			return 0
+		}
+		n := mapStorage(v.Len(), t)
+		if !isPointerFree(t.Key()) || !isPointerFree(t.Elem()) {
+			iter := v.MapRange()
+			for iter.Next() {
+				n += w.indirect(iter.Key()) + w.indirect(iter.Value())
+			}
+		}
+		return n
+
+	case reflect.Chan:
+		if v.IsNil() || !w.first(v.Pointer(), t) {
+			return 0
+		}
+		return chanHeader + int64(v.Cap())*int64(t.Elem().Size())
+	}
+	// Scalars, funcs and unsafe pointers reference nothing counted.
+	return 0
+}
+
+// mapStorage estimates the memory of a map of t holding n entries: groups
+// of eight slots kept at most 7/8 full.
+func mapStorage(n int, t reflect.Type) int64 {
+	slots := (n*8/7 + mapGroupSlots - 1) / mapGroupSlots * mapGroupSlots
+	slot := int64(t.Key().Size() + t.Elem().Size())
+	return mapHeader + int64(slots)*slot + int64(slots/mapGroupSlots)*8
+}
+
+// isPointerShaped reports whether an interface stores values of kind k
+// directly rather than boxing them.
+func isPointerShaped(k reflect.Kind) bool {
+	switch k {
+	case reflect.Pointer, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// isPointerFree reports whether values of t reference no memory, so their
+// contents need no walk. Registered types count as referencing memory.
+func isPointerFree(t reflect.Type) bool {
+	if v, ok := pointerFree.Load(t); ok {
+		return v.(bool)
+	}
+	free := computePointerFree(t)
+	pointerFree.Store(t, free)
+	return free
+}
+
+func computePointerFree(t reflect.Type) bool {
+	if _, ok := lookup(t); ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.Func, reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return t.Len() == 0 || isPointerFree(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if !isPointerFree(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// valueOf returns v as an interface, reaching unexported fields through
+// their address. It fails for unexported values that are not addressable.
+func valueOf(v reflect.Value) (any, bool) {
+	if v.CanInterface() {
+		return v.Interface(), true
+	}
+	if v.CanAddr() {
+		return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem().Interface(), true
+	}
+	return nil, false
+}
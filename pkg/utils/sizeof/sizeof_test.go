@@ -0,0 +1,152 @@
+package sizeof
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// =============================================================================
+// Fast Path Tests
+// =============================================================================
+
+func TestDeepSize_FastPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want int64
+	}{
+		{"nil", nil, 0},
+		{"string", "hello", 16 + 5},
+		{"bytes", make([]byte, 3, 10), 24 + 10},
+		{"int", 42, 8},
+		{"int32", int32(1), 4},
+		{"bool", true, 1},
+		{"time", time.Now(), 24},
+		{"strings", []string{"ab", "cde"}, 24 + 2*16 + 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeepSize(tt.v); got != tt.want {
+				t.Errorf("DeepSize = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Reflection Tests
+// =============================================================================
+
+type record struct {
+	ID   int64
+	Name string
+	Tags []string
+	Meta map[string]int
+	next *record
+	At   time.Time
+}
+
+func TestDeepSize_Struct(t *testing.T) {
+	r := &record{ID: 1, Name: "abcd", Tags: []string{"x", "yz"}}
+	// pointer + struct + name bytes + tags array + tag bytes
+	want := int64(8) + recordSize() + 4 + 2*16 + 3
+	if got := DeepSize(r); got != want {
+		t.Errorf("DeepSize = %d, want %d", got, want)
+	}
+
+	withMap := &record{Meta: map[string]int{"a": 1, "b": 2}}
+	if got := DeepSize(withMap); got <= 8+recordSize()+mapHeader {
+		t.Errorf("DeepSize with map = %d, want map storage counted", got)
+	}
+}
+
+func recordSize() int64 {
+	return int64(unsafe.Sizeof(record{}))
+}
+
+func TestDeepSize_Cycle(t *testing.T) {
+	a := &record{Name: "a"}
+	b := &record{Name: "b", next: a}
+	a.next = b
+	want := 2*recordSize() + 8 + 2
+	if got := DeepSize(a); got != want {
+		t.Errorf("DeepSize of a cycle = %d, want %d", got, want)
+	}
+}
+
+func TestDeepSize_SharedMemory(t *testing.T) {
+	backing := make([]int64, 100)
+	type pair struct{ A, B []int64 }
+	if got, want := DeepSize(&pair{backing, backing[:10]}), int64(8+48+800); got != want {
+		t.Errorf("DeepSize of shared slices = %d, want %d", got, want)
+	}
+}
+
+func TestDeepSize_Interface(t *testing.T) {
+	type holder struct{ V any }
+	// holder + boxed int64
+	if got := DeepSize(holder{V: int64(7)}); got != 16+8 {
+		t.Errorf("boxed int = %d, want 24", got)
+	}
+	// holder + pointer-shaped value stored directly: the int64 it points to
+	n := int64(7)
+	if got := DeepSize(holder{V: &n}); got != 16+8 {
+		t.Errorf("pointer in interface = %d, want 24", got)
+	}
+}
+
+// =============================================================================
+// Registration Tests
+// =============================================================================
+
+type blob struct {
+	data []byte
+	_    [4]int64
+}
+
+func TestRegister(t *testing.T) {
+	calls := 0
+	Register(func(b blob) int64 {
+		calls++
+		return 1000
+	})
+
+	if got := DeepSize(blob{}); got != 1000 {
+		t.Errorf("DeepSize(blob) = %d, want 1000", got)
+	}
+
+	// Inside a graph, including an unexported field: the registered size
+	// replaces the inline bytes of the blob.
+	type wrapper struct {
+		b  blob
+		bs []blob
+	}
+	w := &wrapper{bs: make([]blob, 2)}
+	var blobSize, wrapperSize int64 = 56, 56 + 24
+	want := 8 + wrapperSize + (1000 - blobSize) + 2*1000
+	if got := DeepSize(w); got != want {
+		t.Errorf("DeepSize(wrapper) = %d, want %d", got, want)
+	}
+	if calls != 4 {
+		t.Errorf("registered func called %d times, want 4", calls)
+	}
+}
+
+// =============================================================================
+// Benchmarks
+// =============================================================================
+
+func BenchmarkDeepSize_String(b *testing.B) {
+	s := "a cached value of modest length"
+	for b.Loop() {
+		DeepSize(s)
+	}
+}
+
+func BenchmarkDeepSize_Struct(b *testing.B) {
+	r := &record{Name: "name", Tags: []string{"a", "b", "c"}, Meta: map[string]int{"x": 1}}
+	for b.Loop() {
+		DeepSize(r)
+	}
+}